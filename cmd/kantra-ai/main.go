@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,42 +15,110 @@ import (
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
+	"github.com/tsanders/kantra-ai/pkg/capabilities"
 	"github.com/tsanders/kantra-ai/pkg/confidence"
 	"github.com/tsanders/kantra-ai/pkg/config"
 	"github.com/tsanders/kantra-ai/pkg/executor"
 	"github.com/tsanders/kantra-ai/pkg/fixer"
 	"github.com/tsanders/kantra-ai/pkg/gitutil"
+	"github.com/tsanders/kantra-ai/pkg/logging"
+	"github.com/tsanders/kantra-ai/pkg/notify"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/planner"
 	"github.com/tsanders/kantra-ai/pkg/prompt"
 	"github.com/tsanders/kantra-ai/pkg/provider"
 	"github.com/tsanders/kantra-ai/pkg/provider/claude"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"github.com/tsanders/kantra-ai/pkg/provider/gemini"
 	"github.com/tsanders/kantra-ai/pkg/provider/openai"
+	"github.com/tsanders/kantra-ai/pkg/redact"
 	"github.com/tsanders/kantra-ai/pkg/report"
+	"github.com/tsanders/kantra-ai/pkg/runid"
 	"github.com/tsanders/kantra-ai/pkg/ux"
 	"github.com/tsanders/kantra-ai/pkg/verifier"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 	"github.com/tsanders/kantra-ai/pkg/web"
 )
 
+// --on-budget-exceeded actions
+const (
+	budgetStop           = "stop"
+	budgetPausePrompt    = "pause-prompt"
+	budgetDeferRemaining = "defer-remaining"
+
+	// defaultDeferredOutput is where defer-remaining writes unprocessed violations
+	defaultDeferredOutput = ".kantra-ai-deferred.yaml"
+
+	// defaultRemediateState is remediate's state file with --resume and no --state
+	defaultRemediateState = ".kantra-ai-remediate-state.yaml"
+)
+
 var (
 	analysisPath        string
 	inputPath           string
 	providerName        string
 	violationIDs        string
 	categories          string
+	includePaths        string
+	excludePaths        string
+	maxPathDepth        int
+	filesFrom           string
+	sinceRef            string
 	maxEffort           int
 	maxCost             float64
+	onBudgetExceeded    string
+	deferredOutputPath  string
+	remediateStatePath  string
+	remediateResume     bool
 	dryRun              bool
 	model               string
+	responseFormat      string
+	providerConfigPath  string
+	providerFileConfig  *provider.FileConfig // Loaded from --provider-config
 	gitCommitStrategy   string
 	createPR            bool
 	prStrategy          string
 	prCommentThreshold  float64
+	prDiffFormat        string
+	prDelay             time.Duration
+	prCountPreview      bool
+	outputFormat        string
 	branchName          string
+	baseBranch          string
+	squashCommits       bool
+	commitTemplate      string
+	categoryCommits     map[string]string
+	noVerifyCommit      bool
 	verify              string
 	verifyStrategy      string
 	verifyCommand       string
+	verifyLangCommands  map[string]string
+	verifyWarnCodes     []int
+	verifyWarnPattern   string
+	verifyScope         string
+	verifyAffectedCmd   string
+	verifyOffline       bool
+	coverageReport      string
+	coverageBaseline    string
+	coverageTolerance   float64
 	verifyFailFast      bool
+	verifyOnDryRun      bool
+	fixAssert           string
+	validateSyntax      bool
+	runID               string
+	cacheDir            string
+	dumpResponses       string
+	traceDir            string
+	promptDir           string
+	replayFile          string
+	replayLenient       bool
+	cacheKey            string
+	cacheTTL            time.Duration
+	explainFixes        bool
+	noCache             bool
+	providerFallback    string
+	tpmLimit            int
+	compareProvider     string
 
 	// Plan command flags
 	planOutputPath      string
@@ -54,23 +126,87 @@ var (
 	planRiskTolerance   string
 	planInteractive     bool
 	planInteractiveWeb  bool
+	planReviewTimeout   time.Duration
+	planApproveOnly     bool
+	planWebAddr         string
+	planWebToken        string
+
+	planMaxPhaseViolations int
+	planCostHistory        string
+	planGroupBy            string
+	planMergeInto          string
+	planEstimate           bool
+	planBatchSize          int
+	planBatchDelay         time.Duration
 
 	// Execute command flags
 	executePlanPath     string
 	executeStatePath    string
 	executePhaseID      string
 	executeResume       bool
+	executeForce        bool
+	executeMaxPhases    int
+	executeMaxAttempts  int
+
+	// Report command flags
+	reportPlanPath      string
+	reportStatePath     string
+	reportOutputPath    string
+	reportResults       bool
+
+	// Capabilities command flags
+	capabilitiesJSON bool
+
+	// Providers command flags
+	providersJSON bool
+
+	// Validate-plan command flags
+	validatePlanPath string
+	recomputeRisk    bool
+	recomputePlan    string
+
+	// Report merge flags
+	mergeReportJSON bool
+
+	// Config init flags
+	configInitPath           string
+	configInitNonInteractive bool
+	configInitForce          bool
 
 	// Confidence threshold flags
 	confidenceEnabled   bool
 	minConfidence       float64
 	onLowConfidence     string
 	complexityThreshold string // format: "level=threshold,level=threshold"
+	categoryConfidence  string // format: "category=threshold,category=threshold"
+	reviewDir           string
+	interactiveFixes    bool
 
 	// Batch configuration flags
 	maxBatchSize        int
 	maxBatchTokens      int
+	secondPassThreshold float64
+	reuseIdenticalFixes bool
 	batchParallelism    int
+
+	// Outbound HTTP
+	userAgent string
+
+	// Diagnostic logging
+	logLevel  string
+	logRedact bool
+
+	// Completion notification (remediate, execute)
+	notifyWebhook string
+	notifyFormat  string
+
+	// The summary and pull requests of the finished run, for --notify-webhook
+	runSummary *report.Summary
+	runPRURLs  []string
+
+	// Token pricing overrides, in USD per million tokens
+	inputPrice  float64
+	outputPrice float64
 )
 
 func main() {
@@ -81,36 +217,96 @@ func main() {
 
 This is an MVP focused on validation: proving that AI can successfully fix
 Konveyor violations at reasonable cost and quality.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setupLogging()
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Diagnostic log level on stderr: debug (adds full prompts and raw model responses), info, warn, error (default: info)")
+	rootCmd.PersistentFlags().BoolVar(&logRedact, "log-redact", true, "Redact secrets from logged prompts and responses, using the logging.redaction settings")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "User-Agent sent to AI providers, the GitHub API and --notify-webhook (default: kantra-ai/<version>)")
+	rootCmd.PersistentFlags().Float64Var(&inputPrice, "input-price", 0, "Price of input tokens in USD per million, for cost estimates and the cost summary (with --output-price; default: the provider's or preset's pricing)")
+	rootCmd.PersistentFlags().Float64Var(&outputPrice, "output-price", 0, "Price of output tokens in USD per million (with --input-price)")
 
 	remediateCmd := &cobra.Command{
 		Use:   "remediate",
 		Short: "Remediate violations using AI",
-		RunE:  runRemediate,
+		RunE:  notifyOnCompletion("remediate", runRemediate),
 	}
 
-	remediateCmd.Flags().StringVar(&analysisPath, "analysis", "", "Path to Konveyor analysis output.yaml (required)")
+	remediateCmd.Flags().StringVar(&analysisPath, "analysis", "", "Path to Konveyor analysis output.yaml or a SARIF log; comma-separate multiple files to merge, or - for stdin (required)")
 	remediateCmd.Flags().StringVar(&inputPath, "input", "", "Path to application source code (required)")
-	remediateCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude, openai")
+	remediateCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude, openai, gemini")
 	remediateCmd.Flags().StringVar(&violationIDs, "violation-ids", "", "Comma-separated violation IDs to fix")
 	remediateCmd.Flags().StringVar(&categories, "categories", "", "Comma-separated categories: mandatory, optional, potential")
+	remediateCmd.Flags().StringVar(&includePaths, "include-paths", "", "Comma-separated globs (relative to --input); only fix incidents in matching files, e.g. 'src/main/java/**'")
+	remediateCmd.Flags().StringVar(&excludePaths, "exclude-paths", "", "Comma-separated globs (relative to --input); skip incidents in matching files, e.g. '**/generated/**'")
+	remediateCmd.Flags().IntVar(&maxPathDepth, "max-path-depth", 0, "Skip incidents in files nested deeper than this below --input, counting like find -maxdepth (0 = no limit)")
+	remediateCmd.Flags().StringVar(&filesFrom, "files-from", "", "Only fix incidents in files listed (one per line, relative to --input) in this file, or - for stdin, e.g. from 'git diff --name-only'")
+	remediateCmd.Flags().StringVar(&sinceRef, "since", "", "Only fix incidents in files under --input changed between this git ref and HEAD, e.g. main or HEAD~5")
 	remediateCmd.Flags().IntVar(&maxEffort, "max-effort", 0, "Maximum effort level (0 = no limit)")
 	remediateCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Maximum cost in USD (0 = no limit)")
+	remediateCmd.Flags().StringVar(&onBudgetExceeded, "on-budget-exceeded", "stop", "Action when --max-cost is reached: stop, pause-prompt (ask to raise the budget), defer-remaining (write unprocessed violations to --deferred-output)")
+	remediateCmd.Flags().StringVar(&deferredOutputPath, "deferred-output", defaultDeferredOutput, "With --on-budget-exceeded=defer-remaining, analysis file for the unprocessed violations (resume with --analysis)")
+	remediateCmd.Flags().StringVar(&remediateStatePath, "state", "", "Record each incident's outcome in this state file as it's fixed, so an interrupted run can be resumed (default with --resume: "+defaultRemediateState+")")
+	remediateCmd.Flags().BoolVar(&remediateResume, "resume", false, "Skip incidents the state file records as fixed by a previous run, and keep recording to it")
 	remediateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be done without making changes")
+	remediateCmd.Flags().BoolVar(&interactiveFixes, "interactive-fixes", false, "Show each proposed fix as a diff and prompt [a]pply / [s]kip / [e]dit ($EDITOR) / [q]uit before writing it")
 	remediateCmd.Flags().StringVar(&model, "model", "", "AI model to use (provider-specific)")
+	remediateCmd.Flags().BoolVar(&explainFixes, "explain", false, "Print the model's explanation of each applied fix (and include it per incident with --output-format=json); low-confidence fixes are always explained")
+	remediateCmd.Flags().StringVar(&providerConfigPath, "provider-config", "", "JSON file with provider settings (name, model, base_url, temperature, max_tokens, headers, retries); flags override")
+	remediateCmd.Flags().StringVar(&dumpResponses, "dump-responses", "", "Record every provider response to this file, for replaying the run with --provider replay")
+	remediateCmd.Flags().StringVar(&traceDir, "trace-dir", "", "Write a JSON trace of each provider request to this directory: the exact prompt sent, the raw response, the parsed confidence and the timing (bodies redacted)")
+	remediateCmd.Flags().StringVar(&replayFile, "replay-file", "", "Responses file recorded with --dump-responses, for --provider replay")
+	remediateCmd.Flags().BoolVar(&replayLenient, "replay-lenient", false, "With --provider replay, treat a request with no recorded response as a failed fix instead of an error")
+	remediateCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Cache successful fixes in this directory and reuse them on identical requests at no cost (default: no cache)")
+	remediateCmd.Flags().StringVar(&cacheKey, "cache-key", "", "With --cache-dir, what identifies a cached fix: prompt (the full request) or content (file content, violation ID and model; ignores prompt metadata)")
+	remediateCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "With --cache-dir, ask again for fixes cached longer ago than this, e.g. 168h (default: cached fixes never expire)")
+	remediateCmd.Flags().BoolVar(&noCache, "no-cache", false, "Don't read or write the response cache, even if cache-dir is configured")
+	remediateCmd.Flags().StringVar(&providerFallback, "provider-fallback", "", "Providers to retry an incident with, in order, when --provider fails with an error, e.g. claude,openai,groq (optionally provider:model)")
+	remediateCmd.Flags().StringVar(&compareProvider, "compare-provider", "", "Also ask this provider (optionally provider:model) for every fix and merge both line by line: agreed changes are applied, divergent regions take the more confident fix and are flagged for review")
+	remediateCmd.Flags().IntVar(&tpmLimit, "tpm-limit", 0, "Tokens-per-minute ceiling; pause between requests to stay under it instead of hitting the provider's rate limit (0 = no limit)")
+	remediateCmd.Flags().StringVar(&responseFormat, "response-format", "", "Fix response format: full (entire file) or diff (unified diff, falls back to full if it doesn't apply)")
+	remediateCmd.Flags().StringVar(&promptDir, "prompt-dir", "", "Directory of prompt templates overriding the built-in ones by name: single-fix.tmpl, batch-fix.tmpl, single-fix-<language>.tmpl, batch-fix-<language>.tmpl (default: prompts.dir)")
 	remediateCmd.Flags().StringVar(&gitCommitStrategy, "git-commit", "", "Git commit strategy: per-violation, per-incident, at-end")
+	remediateCmd.Flags().BoolVar(&squashCommits, "squash", false, "With --git-commit per-incident, collapse each violation's incident fixes into one commit listing every file and line changed")
+	remediateCmd.Flags().StringVar(&commitTemplate, "commit-template", "", "Go text/template (inline or a file path) for commit messages, e.g. 'fix(deps): {{.ViolationID}} {{.Description}}'")
+	remediateCmd.Flags().StringToStringVar(&categoryCommits, "category-commit-strategy", nil, "Commit strategy per violation category, overriding --git-commit for its fixes, e.g. mandatory=per-violation,optional=at-end")
+	remediateCmd.Flags().BoolVar(&noVerifyCommit, "no-verify-commit", false, "Skip the repository's pre-commit and commit-msg hooks when committing fixes (git commit --no-verify)")
 	remediateCmd.Flags().BoolVar(&createPR, "create-pr", false, "Create GitHub pull request(s) after remediation (requires --git-commit)")
 	remediateCmd.Flags().StringVar(&prStrategy, "pr-strategy", "", "PR creation strategy: per-violation, per-incident, per-phase, at-end (default: follows --git-commit)")
 	remediateCmd.Flags().Float64Var(&prCommentThreshold, "pr-comment-threshold", 0.0, "Add inline PR comments for fixes with confidence below this threshold (0.0-1.0, 0 = disabled)")
+	remediateCmd.Flags().StringVar(&prDiffFormat, "pr-diff-format", "", "How code changes appear in PR descriptions: unified, side-by-side, none (default: unified)")
+	remediateCmd.Flags().DurationVar(&prDelay, "pr-delay", time.Second, "Minimum delay between GitHub branch pushes and PR operations, to avoid secondary rate limits (0 = no delay)")
+	remediateCmd.Flags().BoolVar(&prCountPreview, "pr-count-preview", false, "Show how many PRs each --pr-strategy would create for the applied fixes (works with --dry-run)")
+	remediateCmd.Flags().StringVar(&outputFormat, "output-format", "text", "Summary output format: text, json (json writes only the summary to stdout)")
 	remediateCmd.Flags().StringVar(&branchName, "branch", "", "Branch name for PR (default: kantra-ai/remediation-TIMESTAMP)")
-	remediateCmd.Flags().StringVar(&verify, "verify", "", "Verification type: build, test (runs after fixes to ensure they don't break build/tests)")
+	remediateCmd.Flags().StringVar(&baseBranch, "base-branch", "", "Branch PRs target; must exist on the remote (default: auto-detect the repository's default branch)")
+	remediateCmd.Flags().StringVar(&runID, "run-id", "", "Namespace generated artifacts and branch names with this run ID (default: a timestamp; with --resume, the latest run)")
+	remediateCmd.Flags().StringVar(&verify, "verify", "", "Verification type: build, test, coverage (runs after fixes to ensure they don't break build/tests or reduce test coverage)")
 	remediateCmd.Flags().StringVar(&verifyStrategy, "verify-strategy", "at-end", "When to verify: per-fix, per-violation, at-end")
 	remediateCmd.Flags().StringVar(&verifyCommand, "verify-command", "", "Custom verification command (overrides auto-detection)")
+	remediateCmd.Flags().StringToStringVar(&verifyLangCommands, "verify-language-command", nil, "Verification command per language of the changed files, run from their module, e.g. java='mvn verify',go='go build ./...' (other files use --verify-command or auto-detection)")
+	remediateCmd.Flags().IntSliceVar(&verifyWarnCodes, "verify-warning-exit-codes", nil, "Non-zero verification exit codes treated as success with a warning (the fix is kept but flagged)")
+	remediateCmd.Flags().StringVar(&verifyWarnPattern, "verify-warning-pattern", "", "Regex; a failed verification whose output matches it is treated as success with a warning")
+	remediateCmd.Flags().StringVar(&verifyScope, "verify-scope", "all", "Tests run by --verify=test: all, affected (only tests for the changed files' packages)")
+	remediateCmd.Flags().StringVar(&verifyAffectedCmd, "verify-affected-command", "", "Command template for --verify-scope=affected when the tests can't be inferred, e.g. 'make test FILES={{.ChangedFiles}}'")
+	remediateCmd.Flags().BoolVar(&verifyOffline, "verify-offline", false, "Run Maven (-o) and Gradle (--offline) verification offline, using only cached dependencies")
+	remediateCmd.Flags().StringVar(&coverageReport, "coverage-report", "", "Coverage report written by --verify=coverage (Go cover profile, JaCoCo/Cobertura XML or LCOV; default: per project type)")
+	remediateCmd.Flags().StringVar(&coverageBaseline, "coverage-baseline", "", "Coverage report to compare fixes against with --verify=coverage (default: measured before the first fix)")
+	remediateCmd.Flags().Float64Var(&coverageTolerance, "coverage-tolerance", 0, "Coverage drop in percentage points allowed by --verify=coverage")
 	remediateCmd.Flags().BoolVar(&verifyFailFast, "verify-fail-fast", true, "Stop on first verification failure")
+	remediateCmd.Flags().BoolVar(&verifyOnDryRun, "verify-on-dry-run", false, "Run verification even with --dry-run (verifies the unchanged working tree)")
+	remediateCmd.Flags().BoolVar(&reuseIdenticalFixes, "reuse-identical-fixes", false, fmt.Sprintf("Apply the change of a fix with confidence ≥ %.2f to later incidents of the violation in identical code (%d lines around the incident), without API calls", fixer.ReuseMinConfidence, fixer.ReuseContextLines))
+	remediateCmd.Flags().BoolVar(&validateSyntax, "validate-syntax", false, "Check that fixed content parses before writing it ("+strings.Join(fixer.SyntaxLanguages(), ", ")+"); invalid fixes are rejected")
+	remediateCmd.Flags().StringVar(&fixAssert, "fix-assert", "", "Shell command run per fixed file; the fix only counts if it exits 0 (placeholders: {file}, {abs_file}, {line}, {violation})")
 	remediateCmd.Flags().BoolVar(&confidenceEnabled, "enable-confidence", false, "Enable confidence threshold filtering")
 	remediateCmd.Flags().Float64Var(&minConfidence, "min-confidence", 0.0, "Global minimum confidence threshold (0.0-1.0, overrides complexity thresholds)")
 	remediateCmd.Flags().StringVar(&onLowConfidence, "on-low-confidence", "skip", "Action on low confidence: skip, warn-and-apply, manual-review-file")
 	remediateCmd.Flags().StringVar(&complexityThreshold, "complexity-threshold", "", "Override thresholds: trivial=0.7,low=0.75,medium=0.8,high=0.9,expert=0.95")
+	remediateCmd.Flags().StringVar(&categoryConfidence, "category-confidence", "", "Minimum confidence per violation category on top of the complexity threshold, e.g. mandatory=0.95,optional=0.8")
+	remediateCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST a summary of the run (fix counts, cost, duration, PR URLs) to this URL when it finishes or fails")
+	remediateCmd.Flags().StringVar(&notifyFormat, "notify-format", "json", "Payload of --notify-webhook: json, or slack for a Slack-compatible message")
+	remediateCmd.Flags().StringVar(&reviewDir, "review-dir", "", "Directory, relative to --input, for the patches and manifest of manual-review-file fixes (default: .kantra-ai-review)")
 
 	// MarkFlagRequired only errors if flag doesn't exist, which can't happen here
 	_ = remediateCmd.MarkFlagRequired("analysis")
@@ -122,25 +318,57 @@ Konveyor violations at reasonable cost and quality.`,
 		Long: `Generate an AI-powered phased migration plan from Konveyor violations.
 
 The plan command analyzes violations and creates a structured plan with multiple
-phases that can be reviewed, edited, and executed incrementally.`,
+phases that can be reviewed, edited, and executed incrementally.
+
+With --recompute-risk, no plan is generated: the phase risks of the existing
+plan given by --plan (default: the latest run's) are recalculated after
+violations were moved between phases by hand. A violation's risk is low up to
+effort 3, medium up to 6 and high above, and at least medium if it needs
+manual review or its category is potential; a phase gets the highest risk of
+its violations. Phases with risk_locked: true keep their risk.`,
 		RunE: runPlan,
 	}
 
-	planCmd.Flags().StringVar(&analysisPath, "analysis", "", "Path to Konveyor analysis output.yaml (required)")
+	planCmd.Flags().StringVar(&analysisPath, "analysis", "", "Path to Konveyor analysis output.yaml or a SARIF log; comma-separate multiple files to merge, or - for stdin (required)")
 	planCmd.Flags().StringVar(&inputPath, "input", "", "Path to application source code (required)")
-	planCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude (openai not yet supported for planning)")
+	planCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude, gemini (openai not yet supported for planning)")
 	planCmd.Flags().StringVar(&planOutputPath, "output", ".kantra-ai-plan", "Output directory for plan files (plan.yaml and plan.html)")
+	planCmd.Flags().StringVar(&runID, "run-id", "", "Namespace generated artifacts with this run ID (default: a timestamp)")
 	planCmd.Flags().IntVar(&planMaxPhases, "max-phases", 0, "Maximum number of phases (0 = auto, typically 3-5)")
+	planCmd.Flags().IntVar(&planMaxPhaseViolations, "max-phase-violations", 0, "Split phases with more violations than this into sequential sub-phases (0 = no limit)")
+	planCmd.Flags().StringVar(&planGroupBy, "group-by", "", "Regroup phases: file-overlap keeps violations that touch the same files in the same phase (default: phases as proposed by the AI)")
+	planCmd.Flags().StringVar(&planMergeInto, "merge-into", "", "Add violations that aren't in this existing plan file to it, keeping its phases, order and approvals, instead of generating a new plan (no AI call)")
+	planCmd.Flags().IntVar(&planBatchSize, "plan-batch-size", 0, "Violations per planning request when batching large analyses (0 = provider default, 15 for Claude)")
+	planCmd.Flags().DurationVar(&planBatchDelay, "plan-batch-delay", 0, "Delay between planning batches, e.g. 5s; 0 disables it for high-TPM endpoints (default: 20-30s, adapted to tokens used)")
+	planCmd.Flags().BoolVar(&planEstimate, "estimate", false, "Group violations into phases heuristically by category and effort, with cost estimates, without calling the model (free, no explanations)")
+	planCmd.Flags().StringVar(&planCostHistory, "cost-history", "", "Comma-separated execution state files whose per-incident costs replace model estimates")
 	planCmd.Flags().StringVar(&planRiskTolerance, "risk-tolerance", "balanced", "Risk tolerance: conservative, balanced, aggressive")
 	planCmd.Flags().StringVar(&violationIDs, "violation-ids", "", "Comma-separated violation IDs to include")
 	planCmd.Flags().StringVar(&categories, "categories", "", "Comma-separated categories: mandatory, optional, potential")
+	planCmd.Flags().StringVar(&includePaths, "include-paths", "", "Comma-separated globs (relative to --input); only fix incidents in matching files, e.g. 'src/main/java/**'")
+	planCmd.Flags().StringVar(&excludePaths, "exclude-paths", "", "Comma-separated globs (relative to --input); skip incidents in matching files, e.g. '**/generated/**'")
+	planCmd.Flags().IntVar(&maxPathDepth, "max-path-depth", 0, "Skip incidents in files nested deeper than this below --input, counting like find -maxdepth (0 = no limit)")
+	planCmd.Flags().StringVar(&filesFrom, "files-from", "", "Only fix incidents in files listed (one per line, relative to --input) in this file, or - for stdin, e.g. from 'git diff --name-only'")
+	planCmd.Flags().StringVar(&sinceRef, "since", "", "Only fix incidents in files under --input changed between this git ref and HEAD, e.g. main or HEAD~5")
 	planCmd.Flags().IntVar(&maxEffort, "max-effort", 0, "Maximum effort level (0 = no limit)")
 	planCmd.Flags().StringVar(&model, "model", "", "AI model to use (provider-specific)")
+	planCmd.Flags().StringVar(&providerConfigPath, "provider-config", "", "JSON file with provider settings (name, model, base_url, temperature, max_tokens, headers, retries); flags override")
+	planCmd.Flags().StringVar(&dumpResponses, "dump-responses", "", "Record every provider response to this file, for replaying the run with --provider replay")
+	planCmd.Flags().StringVar(&traceDir, "trace-dir", "", "Write a JSON trace of each provider request to this directory: the exact prompt sent, the raw response, the parsed confidence and the timing (bodies redacted)")
+	planCmd.Flags().StringVar(&replayFile, "replay-file", "", "Responses file recorded with --dump-responses, for --provider replay")
+	planCmd.Flags().BoolVar(&replayLenient, "replay-lenient", false, "With --provider replay, treat a request with no recorded response as a failed fix instead of an error")
 	planCmd.Flags().BoolVar(&planInteractive, "interactive", false, "Enable interactive phase approval (CLI)")
 	planCmd.Flags().BoolVar(&planInteractiveWeb, "interactive-web", false, "Enable web-based interactive phase approval")
+	planCmd.Flags().StringVar(&planWebAddr, "web-addr", web.DefaultAddr, "With --interactive-web, host:port to listen on (an ephemeral port is used if it's busy)")
+	planCmd.Flags().StringVar(&planWebToken, "web-token", "", "With --interactive-web, access token required by the web API (default: randomly generated and printed in the launch URL)")
+	planCmd.Flags().BoolVar(&planApproveOnly, "approve-only", false, "With --interactive-web, only record approve/defer decisions in the plan file; execution from the UI is disabled (run 'kantra-ai execute' to apply the plan)")
+	planCmd.Flags().DurationVar(&planReviewTimeout, "plan-review-timeout", 0, "With --interactive-web, execute the approved phases automatically if execution isn't started within this time (0 = wait indefinitely)")
+	planCmd.Flags().StringVar(&baseBranch, "base-branch", "", "With --interactive-web, branch PRs created from the UI target (default: auto-detect the repository's default branch)")
+	planCmd.Flags().StringVar(&prDiffFormat, "pr-diff-format", "", "With --interactive-web, how code changes appear in PR descriptions: unified, side-by-side, none (default: unified)")
+	planCmd.Flags().DurationVar(&prDelay, "pr-delay", time.Second, "With --interactive-web, minimum delay between GitHub branch pushes and PR operations (0 = no delay)")
 
-	_ = planCmd.MarkFlagRequired("analysis")
-	_ = planCmd.MarkFlagRequired("input")
+	planCmd.Flags().BoolVar(&recomputeRisk, "recompute-risk", false, "Recalculate the phase risks of the plan given by --plan from their violations' effort and category and save it, instead of generating a plan (phases with risk_locked: true are kept)")
+	planCmd.Flags().StringVar(&recomputePlan, "plan", ".kantra-ai-plan/plan.yaml", "With --recompute-risk, plan file to update (default: the latest run's plan)")
 
 	executeCmd := &cobra.Command{
 		Use:   "execute",
@@ -149,39 +377,197 @@ phases that can be reviewed, edited, and executed incrementally.`,
 
 The execute command loads a plan file and executes the phases, tracking progress
 in a state file. Supports resuming from failures and executing specific phases.`,
-		RunE: runExecute,
+		RunE: notifyOnCompletion("execute", runExecute),
 	}
 
 	executeCmd.Flags().StringVar(&executePlanPath, "plan", ".kantra-ai-plan.yaml", "Path to plan file")
 	executeCmd.Flags().StringVar(&executeStatePath, "state", ".kantra-ai-state.yaml", "Path to state file")
+	executeCmd.Flags().StringVar(&runID, "run-id", "", "Run to execute, whose artifacts and branch names carry this ID (default: the latest run)")
 	executeCmd.Flags().StringVar(&inputPath, "input", "", "Path to application source code (required)")
-	executeCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude, openai")
+	executeCmd.Flags().StringVar(&providerName, "provider", "claude", "AI provider: claude, openai, gemini")
 	executeCmd.Flags().StringVar(&model, "model", "", "AI model to use (provider-specific)")
+	executeCmd.Flags().StringVar(&promptDir, "prompt-dir", "", "Directory of prompt templates overriding the built-in ones by name: single-fix.tmpl, batch-fix.tmpl, single-fix-<language>.tmpl, batch-fix-<language>.tmpl (default: prompts.dir)")
+	executeCmd.Flags().StringVar(&providerConfigPath, "provider-config", "", "JSON file with provider settings (name, model, base_url, temperature, max_tokens, headers, retries); flags override")
+	executeCmd.Flags().StringVar(&dumpResponses, "dump-responses", "", "Record every provider response to this file, for replaying the run with --provider replay")
+	executeCmd.Flags().StringVar(&traceDir, "trace-dir", "", "Write a JSON trace of each provider request to this directory: the exact prompt sent, the raw response, the parsed confidence and the timing (bodies redacted)")
+	executeCmd.Flags().StringVar(&replayFile, "replay-file", "", "Responses file recorded with --dump-responses, for --provider replay")
+	executeCmd.Flags().BoolVar(&replayLenient, "replay-lenient", false, "With --provider replay, treat a request with no recorded response as a failed fix instead of an error")
+	executeCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Cache successful fixes in this directory and reuse them on identical requests at no cost (default: no cache)")
+	executeCmd.Flags().StringVar(&cacheKey, "cache-key", "", "With --cache-dir, what identifies a cached fix: prompt (the full request) or content (file content, violation ID and model; ignores prompt metadata)")
+	executeCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "With --cache-dir, ask again for fixes cached longer ago than this, e.g. 168h (default: cached fixes never expire)")
+	executeCmd.Flags().BoolVar(&noCache, "no-cache", false, "Don't read or write the response cache, even if cache-dir is configured")
+	executeCmd.Flags().StringVar(&providerFallback, "provider-fallback", "", "Providers to retry an incident with, in order, when --provider fails with an error, e.g. claude,openai,groq (optionally provider:model)")
 	executeCmd.Flags().StringVar(&executePhaseID, "phase", "", "Execute specific phase (e.g., phase-1)")
 	executeCmd.Flags().BoolVar(&executeResume, "resume", false, "Resume from last failure")
+	executeCmd.Flags().IntVar(&executeMaxAttempts, "max-incident-attempts", 0, "Fix attempts per incident across runs; an incident that fails this many times is marked permanently failed and skipped on resume (0 = no limit)")
+	executeCmd.Flags().IntVar(&executeMaxPhases, "max-phases-per-run", 0, "Execute at most N not-yet-completed phases, leaving the rest for the next run (0 = no limit)")
+	executeCmd.Flags().BoolVar(&executeForce, "force", false, "Reconcile the state file with a plan that was edited since the last run (completed incidents are skipped)")
 	executeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview without applying changes")
 	executeCmd.Flags().StringVar(&gitCommitStrategy, "git-commit", "", "Git commit strategy: per-violation, per-incident, at-end")
+	executeCmd.Flags().BoolVar(&squashCommits, "squash", false, "With --git-commit per-incident, collapse each violation's incident fixes into one commit listing every file and line changed")
+	executeCmd.Flags().StringVar(&commitTemplate, "commit-template", "", "Go text/template (inline or a file path) for commit messages, e.g. 'fix(deps): {{.ViolationID}} {{.Description}}'")
+	executeCmd.Flags().StringToStringVar(&categoryCommits, "category-commit-strategy", nil, "Commit strategy per violation category, overriding --git-commit for its fixes, e.g. mandatory=per-violation,optional=at-end")
+	executeCmd.Flags().BoolVar(&noVerifyCommit, "no-verify-commit", false, "Skip the repository's pre-commit and commit-msg hooks when committing fixes (git commit --no-verify)")
 	executeCmd.Flags().BoolVar(&createPR, "create-pr", false, "Create GitHub pull request(s)")
 	executeCmd.Flags().StringVar(&prStrategy, "pr-strategy", "", "PR creation strategy: per-violation, per-incident, per-phase, at-end (default: follows --git-commit)")
 	executeCmd.Flags().Float64Var(&prCommentThreshold, "pr-comment-threshold", 0.0, "Add inline PR comments for fixes with confidence below this threshold (0.0-1.0, 0 = disabled)")
+	executeCmd.Flags().StringVar(&prDiffFormat, "pr-diff-format", "", "How code changes appear in PR descriptions: unified, side-by-side, none (default: unified)")
+	executeCmd.Flags().DurationVar(&prDelay, "pr-delay", time.Second, "Minimum delay between GitHub branch pushes and PR operations, to avoid secondary rate limits (0 = no delay)")
+	executeCmd.Flags().BoolVar(&prCountPreview, "pr-count-preview", false, "Show how many PRs each --pr-strategy would create for the applied fixes (works with --dry-run)")
+	executeCmd.Flags().StringVar(&outputFormat, "output-format", "text", "Summary output format: text, json (json writes only the summary to stdout)")
 	executeCmd.Flags().StringVar(&branchName, "branch", "", "Branch name for PR")
-	executeCmd.Flags().StringVar(&verify, "verify", "", "Verification type: build, test")
+	executeCmd.Flags().StringVar(&baseBranch, "base-branch", "", "Branch PRs target; must exist on the remote (default: auto-detect the repository's default branch)")
+	executeCmd.Flags().StringVar(&verify, "verify", "", "Verification type: build, test, coverage")
 	executeCmd.Flags().StringVar(&verifyStrategy, "verify-strategy", "at-end", "When to verify: per-fix, per-violation, at-end")
 	executeCmd.Flags().StringVar(&verifyCommand, "verify-command", "", "Custom verification command")
+	executeCmd.Flags().StringToStringVar(&verifyLangCommands, "verify-language-command", nil, "Verification command per language of the changed files, run from their module, e.g. java='mvn verify',go='go build ./...' (other files use --verify-command or auto-detection)")
+	executeCmd.Flags().IntSliceVar(&verifyWarnCodes, "verify-warning-exit-codes", nil, "Non-zero verification exit codes treated as success with a warning (the fix is kept but flagged)")
+	executeCmd.Flags().StringVar(&verifyWarnPattern, "verify-warning-pattern", "", "Regex; a failed verification whose output matches it is treated as success with a warning")
+	executeCmd.Flags().StringVar(&verifyScope, "verify-scope", "all", "Tests run by --verify=test: all, affected (only tests for the changed files' packages)")
+	executeCmd.Flags().StringVar(&verifyAffectedCmd, "verify-affected-command", "", "Command template for --verify-scope=affected when the tests can't be inferred, e.g. 'make test FILES={{.ChangedFiles}}'")
+	executeCmd.Flags().BoolVar(&verifyOffline, "verify-offline", false, "Run Maven (-o) and Gradle (--offline) verification offline, using only cached dependencies")
+	executeCmd.Flags().StringVar(&coverageReport, "coverage-report", "", "Coverage report written by --verify=coverage (Go cover profile, JaCoCo/Cobertura XML or LCOV; default: per project type)")
+	executeCmd.Flags().StringVar(&coverageBaseline, "coverage-baseline", "", "Coverage report to compare fixes against with --verify=coverage (default: measured before the first fix)")
+	executeCmd.Flags().Float64Var(&coverageTolerance, "coverage-tolerance", 0, "Coverage drop in percentage points allowed by --verify=coverage")
 	executeCmd.Flags().BoolVar(&verifyFailFast, "verify-fail-fast", true, "Stop on first verification failure")
+	executeCmd.Flags().BoolVar(&verifyOnDryRun, "verify-on-dry-run", false, "Run verification even with --dry-run (verifies the unchanged working tree)")
+	executeCmd.Flags().BoolVar(&validateSyntax, "validate-syntax", false, "Check that fixed content parses before writing it ("+strings.Join(fixer.SyntaxLanguages(), ", ")+"); invalid fixes are rejected")
+	executeCmd.Flags().StringVar(&fixAssert, "fix-assert", "", "Shell command run per fixed file; the fix only counts if it exits 0 (placeholders: {file}, {abs_file}, {line}, {violation})")
 	executeCmd.Flags().BoolVar(&confidenceEnabled, "enable-confidence", false, "Enable confidence threshold filtering")
 	executeCmd.Flags().Float64Var(&minConfidence, "min-confidence", 0.0, "Global minimum confidence threshold (0.0-1.0, overrides complexity thresholds)")
 	executeCmd.Flags().StringVar(&onLowConfidence, "on-low-confidence", "skip", "Action on low confidence: skip, warn-and-apply, manual-review-file")
 	executeCmd.Flags().StringVar(&complexityThreshold, "complexity-threshold", "", "Override thresholds: trivial=0.7,low=0.75,medium=0.8,high=0.9,expert=0.95")
+	executeCmd.Flags().StringVar(&categoryConfidence, "category-confidence", "", "Minimum confidence per violation category on top of the complexity threshold, e.g. mandatory=0.95,optional=0.8")
+	executeCmd.Flags().StringVar(&reviewDir, "review-dir", "", "Directory, relative to --input, for the patches and manifest of manual-review-file fixes (default: .kantra-ai-review)")
 	executeCmd.Flags().IntVar(&maxBatchSize, "max-batch-size", 10, "Maximum incidents per batch (0=use default)")
 	executeCmd.Flags().IntVar(&maxBatchTokens, "max-batch-tokens", 0, "Maximum estimated tokens per batch (0=disabled, recommended: 50000)")
-	executeCmd.Flags().IntVar(&batchParallelism, "batch-parallelism", 8, "Number of concurrent batches (0=use default)")
+	executeCmd.Flags().BoolVar(&reuseIdenticalFixes, "reuse-identical-fixes", false, fmt.Sprintf("Fix one incident per group with identical code (%d lines around the incident), then apply its change to the rest of the group without API calls if its confidence is ≥ %.2f", fixer.ReuseContextLines, fixer.ReuseMinConfidence))
+	executeCmd.Flags().Float64Var(&secondPassThreshold, "second-pass-threshold", 0, "Re-queue batched fixes below this confidence and fix their incidents individually after the batches (0=disabled)")
+	executeCmd.Flags().IntVar(&batchParallelism, "parallelism", 0, "Number of concurrent batches, capped by the provider's rate limits (0 = auto: the provider's limit)")
+	executeCmd.Flags().IntVar(&batchParallelism, "batch-parallelism", 0, "Number of concurrent batches")
+	_ = executeCmd.Flags().MarkDeprecated("batch-parallelism", "use --parallelism instead")
+	executeCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST a summary of the run (fix counts, cost, duration, PR URLs) to this URL when it finishes or fails")
+	executeCmd.Flags().StringVar(&notifyFormat, "notify-format", "json", "Payload of --notify-webhook: json, or slack for a Slack-compatible message")
+	executeCmd.Flags().IntVar(&tpmLimit, "tpm-limit", 0, "Tokens-per-minute ceiling; pause between batches to stay under it instead of hitting the provider's rate limit (0 = no limit)")
 
 	_ = executeCmd.MarkFlagRequired("input")
 
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate an HTML report for a migration plan",
+		Long: `Generate the HTML migration report from a plan file.
+
+With --state, actual execution results (completed phases, real costs) are
+shown alongside the plan's estimates. With --results, a post-execution report
+is generated instead, showing the diffs actually applied for each violation
+(read from the recorded git commits), verification outcomes and final cost.`,
+		RunE: runReport,
+	}
+
+	reportCmd.Flags().StringVar(&reportPlanPath, "plan", ".kantra-ai-plan.yaml", "Path to plan file")
+	reportCmd.Flags().StringVar(&reportStatePath, "state", "", "Path to state file whose execution results are overlaid on the report (optional)")
+	reportCmd.Flags().StringVar(&reportOutputPath, "output", "plan.html", "Path to write the HTML report (default with --results: results.html)")
+	reportCmd.Flags().BoolVar(&reportResults, "results", false, "Generate the post-execution results report with applied diffs (requires --state)")
+	reportCmd.Flags().StringVar(&inputPath, "input", ".", "With --results, path to the application source (git repository) to read applied diffs from")
+	reportCmd.Flags().StringVar(&runID, "run-id", "", "Run to report on, whose artifacts carry this ID (default: the latest run)")
+
+	reportMergeCmd := &cobra.Command{
+		Use:   "merge STATE_FILE...",
+		Short: "Combine the state files of several runs into one report",
+		Long: `Combine the state files of several execute runs, e.g. over a migration
+spanning weeks, into a consolidated report: violations resolved, incidents
+fixed and failed, cumulative cost and tokens, and a timeline of the runs.
+
+An incident recorded by several runs counts once, with the outcome of its
+latest attempt.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runReportMerge,
+	}
+	reportMergeCmd.Flags().BoolVar(&mergeReportJSON, "json", false, "Print the consolidated report as JSON")
+	reportCmd.AddCommand(reportMergeCmd)
+
+	capabilitiesCmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Show the providers, strategies and file format versions this build supports",
+		Long: `List what this build of kantra-ai supports: providers and presets, commit
+strategies, verification types and strategies, and the config, plan and state
+file format versions. Use --json for tooling.`,
+		Args: cobra.NoArgs,
+		RunE: runCapabilities,
+	}
+
+	capabilitiesCmd.Flags().BoolVar(&capabilitiesJSON, "json", false, "Print the capabilities as JSON")
+
+	providersCmd := &cobra.Command{
+		Use:   "providers",
+		Short: "List the AI providers and presets with their models and API keys",
+		Long: `List every built-in provider and OpenAI-compatible preset accepted by
+--provider: its description, default and known models, base URL, and the
+environment variable holding its API key, with whether it is currently set.
+Use --json for scripting.`,
+		Args: cobra.NoArgs,
+		RunE: runProviders,
+	}
+	providersCmd.Flags().BoolVar(&providersJSON, "json", false, "Print the providers as JSON")
+
+	validatePlanCmd := &cobra.Command{
+		Use:   "validate-plan",
+		Short: "Check a hand-edited migration plan before executing it",
+		Long: `Check a plan file, e.g. after editing it by hand, against the analysis it
+was generated from: every violation ID exists in the analysis, every phase has
+a valid risk and category, phase IDs and orders are unique, and every
+incident's file resolves under --input.
+
+Every problem is reported with its line in the plan file. Exits non-zero if
+any are errors; warnings alone don't fail.
+
+With --recompute-risk, a valid plan's phase risks are recalculated from their
+violations after moving violations between phases: a violation's risk is low
+up to effort 3, medium up to 6 and high above, and at least medium if it needs
+manual review or its category is potential; a phase gets the highest risk of
+its violations. Phases with risk_locked: true keep their risk.`,
+		Args: cobra.NoArgs,
+		RunE: runValidatePlan,
+	}
+
+	validatePlanCmd.Flags().StringVar(&validatePlanPath, "plan", ".kantra-ai-plan.yaml", "Path to plan file")
+	validatePlanCmd.Flags().StringVar(&analysisPath, "analysis", "", "Path to Konveyor analysis output.yaml or a SARIF log the plan was generated from; comma-separate multiple files to merge (required)")
+	validatePlanCmd.Flags().StringVar(&inputPath, "input", "", "Path to application source code (required)")
+	validatePlanCmd.Flags().BoolVar(&recomputeRisk, "recompute-risk", false, "Recalculate each phase's risk from its violations' effort and category and save the plan (phases with risk_locked: true are kept)")
+	_ = validatePlanCmd.MarkFlagRequired("analysis")
+	_ = validatePlanCmd.MarkFlagRequired("input")
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the kantra-ai config file",
+		Args:  cobra.NoArgs,
+	}
+
+	configInitCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented config file, asking for the main settings",
+		Long: `Ask for the AI provider and model, the default analysis and source paths,
+the git commit strategy and confidence filtering, then write a commented
+.kantra-ai.yaml. Press Enter to keep the suggested answer.
+
+With --non-interactive, the defaults are written without asking. An existing
+config file is only overwritten with --force.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigInit,
+	}
+	configInitCmd.Flags().StringVar(&configInitPath, "output", ".kantra-ai.yaml", "Config file to write")
+	configInitCmd.Flags().BoolVar(&configInitNonInteractive, "non-interactive", false, "Write the default settings without asking")
+	configInitCmd.Flags().BoolVar(&configInitForce, "force", false, "Overwrite an existing config file")
+	configCmd.AddCommand(configInitCmd)
+
 	rootCmd.AddCommand(remediateCmd)
 	rootCmd.AddCommand(planCmd)
 	rootCmd.AddCommand(executeCmd)
+	rootCmd.AddCommand(validatePlanCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(capabilitiesCmd)
+	rootCmd.AddCommand(providersCmd)
+	rootCmd.AddCommand(configCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -189,8 +575,16 @@ in a state file. Supports resuming from failures and executing specific phases.`
 }
 
 func runRemediate(cmd *cobra.Command, args []string) error {
+	jsonOut, err := setupOutputFormat()
+	if err != nil {
+		return err
+	}
+
 	// Load configuration from file (if exists)
 	cfg := config.LoadOrDefault()
+	if err := loadProviderConfigFile(cmd); err != nil {
+		return err
+	}
 
 	// Apply config file values for flags that weren't explicitly set
 	// CLI flags take precedence over config file values
@@ -214,24 +608,37 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 		}
 		inputPath = absInputPath
 	}
-	if providerName == "claude" && cfg.Provider.Name != "" { // "claude" is the flag default
+	// --provider-config takes precedence over the config file
+	if providerName == "claude" && cfg.Provider.Name != "" && (providerFileConfig == nil || providerFileConfig.Name == "") { // "claude" is the flag default
 		providerName = cfg.Provider.Name
 	}
 	if model == "" && cfg.Provider.Model != "" {
 		model = cfg.Provider.Model
 	}
+	if responseFormat == "" && cfg.Provider.ResponseFormat != "" {
+		responseFormat = cfg.Provider.ResponseFormat
+	}
 	if violationIDs == "" && len(cfg.Filters.ViolationIDs) > 0 {
 		violationIDs = strings.Join(cfg.Filters.ViolationIDs, ",")
 	}
 	if categories == "" && len(cfg.Filters.Categories) > 0 {
 		categories = strings.Join(cfg.Filters.Categories, ",")
 	}
+	applyPathFilterConfig(cfg)
 	if maxEffort == 0 && cfg.Limits.MaxEffort > 0 {
 		maxEffort = cfg.Limits.MaxEffort
 	}
 	if maxCost == 0 && cfg.Limits.MaxCost > 0 {
 		maxCost = cfg.Limits.MaxCost
 	}
+	if onBudgetExceeded == "stop" && cfg.Limits.OnBudgetExceeded != "" { // "stop" is the flag default
+		onBudgetExceeded = cfg.Limits.OnBudgetExceeded
+	}
+	switch onBudgetExceeded {
+	case budgetStop, budgetPausePrompt, budgetDeferRemaining:
+	default:
+		return fmt.Errorf("invalid --on-budget-exceeded value: %s (must be: stop, pause-prompt, defer-remaining)", onBudgetExceeded)
+	}
 	if gitCommitStrategy == "" && cfg.Git.CommitStrategy != "" {
 		gitCommitStrategy = cfg.Git.CommitStrategy
 	}
@@ -257,8 +664,28 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 	if !dryRun && cfg.DryRun {
 		dryRun = cfg.DryRun
 	}
+	if remediateResume && runID == "" && remediateStatePath == "" {
+		// Resume the latest run
+		if id, ok := runid.Latest(defaultRemediateState, ""); ok {
+			runID = id
+			if id == "" {
+				remediateStatePath = defaultRemediateState
+			}
+		}
+	}
+	if err := resolveRunID(true); err != nil {
+		return err
+	}
+	deferredOutputPath = runid.Path(deferredOutputPath, runID)
+	progress, err := loadRemediateProgress()
+	if err != nil {
+		return err
+	}
 
 	ux.PrintHeader("kantra-ai remediate")
+	if runID != "" {
+		fmt.Printf("🏷  Run ID: %s\n\n", runID)
+	}
 
 	// Load violations
 	spinner := ux.NewSpinner(fmt.Sprintf("Loading analysis from %s...", analysisPath))
@@ -273,6 +700,11 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 	spinner.StopWithSuccess(fmt.Sprintf("Loaded %d violations", len(analysis.Violations)))
 	fmt.Println()
 
+	fixAssertion, err := newFixAssertion()
+	if err != nil {
+		return err
+	}
+
 	// Initialize git tracker if requested
 	var commitTracker *gitutil.CommitTracker
 	var verifiedTracker *gitutil.VerifiedCommitTracker
@@ -280,14 +712,17 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 		if !gitutil.IsGitInstalled() {
 			return fmt.Errorf("--git-commit requires git to be installed")
 		}
-		if !gitutil.IsGitRepository(inputPath) {
-			return fmt.Errorf("--git-commit requires input directory to be a git repository")
+		if err := gitutil.ValidateRepository(inputPath); err != nil {
+			return fmt.Errorf("--git-commit cannot be used with this input directory: %w", err)
 		}
 
 		strategy, err := gitutil.ParseStrategy(gitCommitStrategy)
 		if err != nil {
 			return err
 		}
+		if squashCommits && strategy != gitutil.StrategyPerIncident {
+			return fmt.Errorf("--squash requires --git-commit per-incident")
+		}
 
 		// Check if verification is requested
 		if verify != "" {
@@ -302,13 +737,31 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			applyVerifyWarningConfig(cfg)
+			applyCoverageConfig(cfg)
+			verifyScp, err := resolveVerifyScope(cfg)
+			if err != nil {
+				return err
+			}
 			verifyConfig := verifier.Config{
-				Type:          verifyType,
-				Strategy:      verifyStrat,
-				WorkingDir:    inputPath,
-				CustomCommand: verifyCommand,
-				FailFast:      verifyFailFast,
-				SkipOnDryRun:  dryRun,
+				Type:           verifyType,
+				Strategy:       verifyStrat,
+				WorkingDir:     inputPath,
+				CustomCommand:  verifyCommand,
+				FailFast:       verifyFailFast,
+				DryRun:         dryRun,
+				VerifyOnDryRun: verifyOnDryRun,
+
+				LanguageCommands: resolveLanguageCommands(cfg),
+				WarningExitCodes: verifyWarnCodes,
+				WarningPattern:   verifyWarnPattern,
+				Scope:            verifyScp,
+				AffectedCommand:  verifyAffectedCmd,
+				Offline:          verifyOffline,
+
+				CoverageReport:    coverageReport,
+				CoverageBaseline:  coverageBaseline,
+				CoverageTolerance: coverageTolerance,
 			}
 
 			verifiedTracker, err = gitutil.NewVerifiedCommitTracker(strategy, inputPath, providerName, verifyConfig)
@@ -317,13 +770,40 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 			}
 			commitTracker = verifiedTracker.GetCommitTracker()
 			ux.PrintSuccess("Git commits enabled (%s strategy)", gitCommitStrategy)
-			ux.PrintSuccess("Verification enabled (%s, %s strategy)", verify, verifyStrategy)
+			if reason := verifyConfig.SkipReason(); reason != "" {
+				ux.PrintWarning("Verification skipped: %s", reason)
+			} else {
+				ux.PrintSuccess("Verification enabled (%s, %s strategy)", verify, verifyStrategy)
+			}
+			if baseline := verifiedTracker.BaselineCoverage(); baseline != nil {
+				ux.PrintInfo("Baseline coverage: %s", baseline)
+			}
 			fmt.Println()
 		} else {
 			commitTracker = gitutil.NewCommitTracker(strategy, inputPath, providerName)
 			ux.PrintSuccess("Git commits enabled (%s strategy)", gitCommitStrategy)
 			fmt.Println()
 		}
+		commitTracker.SetSquash(squashCommits)
+		if commitTemplate == "" {
+			commitTemplate = cfg.Git.CommitTemplate
+		}
+		if commitTemplate != "" {
+			tmpl, err := gitutil.LoadCommitTemplate(commitTemplate)
+			if err != nil {
+				return fmt.Errorf("invalid --commit-template: %w", err)
+			}
+			commitTracker.SetMessageTemplate(tmpl)
+		}
+		categoryStrategies, err := resolveCategoryStrategies(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid --category-commit-strategy: %w", err)
+		}
+		commitTracker.SetCategoryStrategies(categoryStrategies)
+		if noVerifyCommit {
+			commitTracker.SetNoVerify(true)
+			ux.PrintWarning("Git hooks will be skipped for kantra-ai commits (--no-verify-commit)")
+		}
 	}
 
 	// Initialize PR tracker if requested
@@ -360,18 +840,40 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		// Generate branch name if not provided
+		// Generate branch name if not provided; with a run ID the ID
+		// replaces the timestamp
 		if branchName == "" {
-			branchName = fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
+			if runID != "" {
+				branchName = "kantra-ai/remediation"
+			} else {
+				branchName = fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
+			}
+		}
+		branchName = runid.Branch(branchName, runID)
+
+		if prDiffFormat == "" {
+			prDiffFormat = cfg.Git.PRDiffFormat
+		}
+		if baseBranch == "" {
+			baseBranch = cfg.Git.BaseBranch
+		}
+		diffFormat, err := gitutil.ParseDiffFormat(prDiffFormat)
+		if err != nil {
+			return err
 		}
 
 		// Initialize PR tracker
 		prConfig := gitutil.PRConfig{
 			Strategy:         parsedPRStrategy,
 			BranchPrefix:     branchName,
+			BaseBranch:       baseBranch,
 			GitHubToken:      githubToken,
+			UserAgent:        userAgent,
 			DryRun:           dryRun,
 			CommentThreshold: prCommentThreshold,
+			OperationDelay:   prDelay,
+			MaxBranchLength:  cfg.Git.MaxBranchLength,
+			DiffFormat:       diffFormat,
 		}
 
 		progress := &gitutil.StdoutProgressWriter{}
@@ -397,10 +899,18 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 
 	// Apply filters
 	filtered := analysis.FilterViolations(idFilter, catFilter, maxEffort)
+	pathFilter, err := newPathFilter()
+	if err != nil {
+		return err
+	}
+	filtered = pathFilter.Apply(filtered)
 	fmt.Printf("After filtering: %d violations\n", len(filtered))
 
 	if len(filtered) == 0 {
 		fmt.Println("No violations to fix.")
+		if jsonOut != nil {
+			return report.BuildSummary("remediate", dryRun, nil, 0).WriteJSON(jsonOut)
+		}
 		return nil
 	}
 
@@ -414,6 +924,15 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create provider: %w", err)
 	}
 
+	// Second provider whose fixes are merged with the primary's
+	var candidate provider.Provider
+	if compareProvider != "" {
+		if candidate, err = createCandidateProvider(cfg); err != nil {
+			provSpinner.StopWithError(fmt.Sprintf("Failed to initialize provider: %v", err))
+			return fmt.Errorf("failed to create compare provider: %w", err)
+		}
+	}
+
 	provSpinner.StopWithSuccess(fmt.Sprintf("%s provider ready", providerName))
 	fmt.Println()
 
@@ -422,29 +941,45 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid confidence configuration: %w", err)
 	}
+	confidenceConf.ReviewFile = runid.Path(fixer.ReviewFileName, runID)
+	if confidenceConf.ReviewDir == "" {
+		confidenceConf.ReviewDir = runid.Path(fixer.ReviewDirName, runID)
+	}
 
 	// Estimate cost
 	if !dryRun {
 		totalEstimate := 0.0
 		for _, v := range filtered {
 			for _, incident := range v.Incidents {
-				req := provider.FixRequest{
-					Violation: v,
-					Incident:  incident,
-				}
+				req := fixer.EstimateRequest(inputPath, v, incident)
 				cost, _ := prov.EstimateCost(req)
 				totalEstimate += cost
+				if candidate != nil {
+					cost, _ = candidate.EstimateCost(req)
+					totalEstimate += cost
+				}
 			}
 		}
 		fmt.Printf("Estimated cost: $%.2f\n", totalEstimate)
 		if maxCost > 0 && totalEstimate > maxCost {
-			return fmt.Errorf("estimated cost ($%.2f) exceeds max-cost ($%.2f)", totalEstimate, maxCost)
+			if onBudgetExceeded == budgetStop {
+				return fmt.Errorf("estimated cost ($%.2f) exceeds max-cost ($%.2f)", totalEstimate, maxCost)
+			}
+			ux.PrintWarning("Estimated cost ($%.2f) exceeds max-cost ($%.2f); --on-budget-exceeded=%s applies when it's reached", totalEstimate, maxCost, onBudgetExceeded)
 		}
 		fmt.Println()
 	}
 
 	// Create fixer with confidence configuration
 	fix := fixer.NewWithConfidence(prov, inputPath, dryRun, confidenceConf)
+	rateTracker := fixer.NewTPMTracker(resolveTPMLimit(cfg))
+	fix.SetRateTracker(rateTracker)
+	fix.SetSyntaxCheck(validateSyntax || cfg.Verification.SyntaxCheck)
+	fix.SetReuseIdenticalFixes(reuseIdenticalFixes)
+	fix.SetCandidateProvider(candidate)
+	if interactiveFixes {
+		fix.SetApprover(fixer.NewInteractiveApprover(os.Stdin, os.Stdout))
+	}
 
 	// Fix violations
 	ux.PrintSection("Fixing violations")
@@ -454,8 +989,15 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 	totalTokens := 0
 	successCount := 0
 	failCount := 0
+	ignoredCount := 0
+	reviewSkippedCount := 0
+	resumedCount := 0
 	startTime := time.Now()
 
+	// Record every attempted fix for the JSON summary and PR count preview
+	// (tracked even in dry-run)
+	var fixRecords []gitutil.FixRecord
+
 	// Create stats tracker for confidence filtering
 	var confidenceStats *confidence.Stats
 	if confidenceConf.Enabled {
@@ -469,8 +1011,9 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create progress bar
+	// (not with interactive review, whose prompts it would garble)
 	var bar *progressbar.ProgressBar
-	if ux.IsTerminal() && !dryRun {
+	if ux.IsTerminal() && !dryRun && !interactiveFixes {
 		bar = ux.NewProgressBar(totalIncidents, "Progress")
 	}
 
@@ -494,13 +1037,55 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s [%d/%d] %s:%d\n",
 				ux.Dim("•"), j+1, len(v.Incidents), filePath, incident.LineNumber)
 
+			if progress.Done(v.ID, incident) {
+				fmt.Printf("    %s\n", ux.Dim("⏭  Already fixed (resumed)"))
+				resumedCount++
+				if bar != nil {
+					if err := bar.Add(1); err != nil {
+						ux.PrintWarning("Progress bar update failed: %v", err)
+					}
+				}
+				continue
+			}
+
 			result, err := fix.FixIncident(ctx, v, incident)
+			fmt.Printf("    %s %s\n", ux.Dim("Token rate:"), rateTracker)
 			if bar != nil {
 				if err := bar.Add(1); err != nil {
 					ux.PrintWarning("Progress bar update failed: %v", err)
 				}
 			}
 
+			// Fixes rejected during interactive review are skipped, not failed
+			if result != nil && result.SkippedByUser && (err == nil || errors.Is(err, fixer.ErrReviewQuit)) {
+				reviewSkippedCount++
+				recordProgress(progress, v, incident, result, err)
+				fixRecords = append(fixRecords, gitutil.FixRecord{
+					Violation: v,
+					Incident:  incident,
+					Result:    *result,
+					Timestamp: time.Now(),
+				})
+				if err != nil {
+					ux.PrintWarning("\nStopped during interactive review")
+					goto summary
+				}
+				continue
+			}
+
+			// Files in .kantra-ai-ignore are skipped, not failed
+			if err == nil && result.SkippedIgnored {
+				ignoredCount++
+				recordProgress(progress, v, incident, result, nil)
+				fixRecords = append(fixRecords, gitutil.FixRecord{
+					Violation: v,
+					Incident:  incident,
+					Result:    *result,
+					Timestamp: time.Now(),
+				})
+				continue
+			}
+
 			// Track confidence filtering stats
 			if confidenceStats != nil {
 				applied := result != nil && result.Success && !result.SkippedLowConfidence
@@ -510,9 +1095,44 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 			if err != nil {
 				ux.PrintError("    Failed: %v", err)
 				failCount++
+				recordProgress(progress, v, incident, result, err)
+				fixRecords = append(fixRecords, gitutil.FixRecord{
+					Violation: v,
+					Incident:  incident,
+					Result:    fixer.FixResult{ViolationID: v.ID, IncidentURI: incident.URI, Error: err},
+					Timestamp: time.Now(),
+				})
 				continue
 			}
 
+			// Check the per-fix assertion before counting or committing the fix
+			if result.Success && fixAssertion != nil && !dryRun {
+				assertResult, assertErr := fixAssertion.Check(ctx, verifier.AssertTarget{
+					File:        result.FilePath,
+					Line:        incident.LineNumber,
+					ViolationID: v.ID,
+				})
+				if assertErr == nil && !assertResult.Success {
+					assertErr = assertResult.Error
+				}
+				if assertErr != nil {
+					result.Success = false
+					result.Error = assertErr
+					if undoErr := gitutil.UndoFix(inputPath, result); undoErr != nil {
+						ux.PrintWarning("Could not undo the rejected fix: %v", undoErr)
+					}
+				}
+			}
+			printExplanation(result)
+			recordProgress(progress, v, incident, result, nil)
+
+			fixRecords = append(fixRecords, gitutil.FixRecord{
+				Violation: v,
+				Incident:  incident,
+				Result:    *result,
+				Timestamp: time.Now(),
+			})
+
 			if result.Success {
 				successCount++
 				totalCost += result.Cost
@@ -522,7 +1142,18 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 				if commitTracker != nil && !dryRun {
 					// Use verified tracker if verification is enabled
 					if verifiedTracker != nil {
-						if err := verifiedTracker.TrackFix(v, incident, result); err != nil {
+						err := verifiedTracker.TrackFix(v, incident, result)
+						if errors.Is(err, gitutil.ErrFixReverted) {
+							// The fix broke verification and was undone, so it
+							// counts as failed and --resume retries it
+							ux.PrintWarning("    %v, left for manual handling", err)
+							result.Success = false
+							result.Error = err
+							successCount--
+							failCount++
+							recordProgress(progress, v, incident, result, nil)
+							fixRecords[len(fixRecords)-1].Result = *result
+						} else if err != nil {
 							ux.PrintWarning("    Git commit/verification failed: %v", err)
 						}
 					} else {
@@ -533,7 +1164,7 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 				}
 
 				// Track for PR if enabled
-				if prTracker != nil && !dryRun {
+				if prTracker != nil && !dryRun && result.Success {
 					if err := prTracker.TrackForPR(v, incident, result); err != nil {
 						ux.PrintWarning("    PR tracking failed: %v", err)
 					}
@@ -541,7 +1172,35 @@ func runRemediate(cmd *cobra.Command, args []string) error {
 
 				// Check if we've exceeded max cost
 				if maxCost > 0 && totalCost >= maxCost {
-					ux.PrintWarning("\nMax cost ($%.2f) reached. Stopping.", maxCost)
+					remaining := violation.RemainingViolations(filtered, i, j+1)
+					if len(remaining) == 0 {
+						continue
+					}
+
+					switch onBudgetExceeded {
+					case budgetPausePrompt:
+						ux.PrintWarning("\nMax cost ($%.2f) reached with %d violation(s) left.", maxCost, len(remaining))
+						if newBudget, ok := promptBudget(totalCost); ok {
+							maxCost = newBudget
+							ux.PrintSuccess("Budget raised to $%.2f, continuing", maxCost)
+							continue
+						}
+						ux.PrintWarning("Stopping.")
+					case budgetDeferRemaining:
+						ux.PrintWarning("\nMax cost ($%.2f) reached. Deferring the remaining work.", maxCost)
+						if err := violation.SaveAnalysis(deferredOutputPath, remaining); err != nil {
+							ux.PrintError("Failed to write deferred violations: %v", err)
+						} else {
+							deferredCount := 0
+							for _, rv := range remaining {
+								deferredCount += len(rv.Incidents)
+							}
+							ux.PrintSuccess("Deferred %d violation(s) (%d incidents) to %s", len(remaining), deferredCount, deferredOutputPath)
+							fmt.Printf("  Resume with: kantra-ai remediate --analysis %s --input %s\n", deferredOutputPath, inputPath)
+						}
+					default:
+						ux.PrintWarning("\nMax cost ($%.2f) reached. Stopping.", maxCost)
+					}
 					goto summary
 				}
 			} else {
@@ -573,11 +1232,19 @@ summary:
 				ux.PrintSection("Verification Summary")
 				fmt.Printf("  Total verifications: %s\n", ux.Bold(fmt.Sprintf("%d", stats.TotalVerifications)))
 				fmt.Printf("  %s Passed: %s\n", ux.Success("✓"), ux.Success(fmt.Sprintf("%d", stats.PassedVerifications)))
+				if stats.WarningVerifications > 0 {
+					fmt.Printf("  %s Passed with warnings: %s\n", ux.Warning("⚠"), ux.Warning(fmt.Sprintf("%d", stats.WarningVerifications)))
+				}
 				if stats.FailedVerifications > 0 {
 					fmt.Printf("  %s Failed: %s\n", ux.Error("✗"), ux.Error(fmt.Sprintf("%d", stats.FailedVerifications)))
 					fmt.Printf("  %s Fixes skipped due to failures: %s\n",
 						ux.Warning("⚠"), ux.Warning(fmt.Sprintf("%d", stats.SkippedFixes)))
 				}
+				if stats.RevertedFixes > 0 {
+					fmt.Printf("  %s Fixes reverted: %s\n", ux.Warning("↩"), ux.Warning(fmt.Sprintf("%d", stats.RevertedFixes)))
+				}
+				printVerificationFailures(verifiedTracker.Failures())
+				printRevertedIncidents(verifiedTracker.RevertedIncidents())
 				fmt.Println()
 			}
 		} else {
@@ -585,6 +1252,18 @@ summary:
 				ux.PrintWarning("\nFinal git commit failed: %v", err)
 			}
 		}
+	} else if verifiedTracker != nil && dryRun && verifiedTracker.SkipReason() == "" {
+		// --verify-on-dry-run: check the unchanged working tree, nothing to commit or revert
+		result, err := verifiedTracker.VerifyDryRun()
+		if err != nil {
+			ux.PrintWarning("Verification failed to run: %v", err)
+		} else if result.Warning {
+			ux.PrintWarning("Verification passed with warnings (%s): %s", result.Command, result.WarningReason)
+		} else if result.Success {
+			ux.PrintSuccess("Verification passed (%s)", result.Command)
+		} else {
+			ux.PrintWarning("Verification failed (%s): %v", result.Command, result.Error)
+		}
 	}
 
 	// Create pull requests if enabled
@@ -620,6 +1299,7 @@ summary:
 			prs := prTracker.GetCreatedPRs()
 			ux.PrintSuccess("\nCreated %d pull request(s):", len(prs))
 			for _, pr := range prs {
+				runPRURLs = append(runPRURLs, pr.URL)
 				if pr.ViolationID != "" {
 					fmt.Printf("  %s PR #%d (%s): %s\n",
 						ux.Success("→"), pr.Number, ux.Info(pr.ViolationID), ux.Dim(pr.URL))
@@ -639,11 +1319,19 @@ summary:
 	rows := [][]string{
 		{ux.Success("✓") + " Successful fixes:", ux.Success(fmt.Sprintf("%d", successCount))},
 		{ux.Error("✗") + " Failed fixes:", ux.Error(fmt.Sprintf("%d", failCount))},
+		{"🚫 Ignored fixes:", ux.Info(fmt.Sprintf("%d (%s)", ignoredCount, fixer.IgnoreReason))},
 		{"💰 Total cost:", ux.FormatCost(totalCost)},
 		{"🎫 Total tokens:", ux.FormatTokens(totalTokens)},
 		{"⏱  Duration:", ux.FormatDuration(duration)},
 	}
 
+	if interactiveFixes {
+		rows = append(rows, []string{"⏭  Skipped in review:", ux.Info(fmt.Sprintf("%d", reviewSkippedCount))})
+	}
+	if remediateResume {
+		rows = append(rows, []string{"⏭  Fixed by a previous run:", ux.Info(fmt.Sprintf("%d", resumedCount))})
+	}
+
 	if successCount > 0 {
 		avgCost := totalCost / float64(successCount)
 		avgTokens := totalTokens / successCount
@@ -652,6 +1340,10 @@ summary:
 			fmt.Sprintf("%s (%s tokens)", ux.FormatCost(avgCost), ux.FormatTokens(avgTokens)),
 		})
 	}
+	if row := cacheStatsRow(prov); row != nil {
+		rows = append(rows, row)
+	}
+	rows = append(rows, providerSpendRows(prov)...)
 
 	ux.PrintSummaryTable(rows)
 
@@ -662,21 +1354,50 @@ summary:
 		fmt.Printf("  %s\n", confidenceStats.Summary())
 	}
 
+	if prCountPreview {
+		printPRCountPreview(gitutil.EstimatePRCounts(gitutil.SuccessfulFixes(fixRecords)), false)
+	}
+
 	if dryRun {
 		fmt.Println()
 		ux.PrintWarning("DRY-RUN mode - no changes were made")
 	}
 
+	runSummary = buildRunSummary("remediate", fixRecords, duration, verifiedTracker, prov)
+	if jsonOut != nil {
+		return runSummary.WriteJSON(jsonOut)
+	}
+
 	return nil
 }
 
 func runPlan(cmd *cobra.Command, args []string) error {
 	startTime := time.Now()
 
-	ux.PrintHeader("Generating Migration Plan")
+	// Recomputing risk updates an existing plan, outside any new run
+	if recomputeRisk {
+		if err := resolveRunID(false); err != nil {
+			return err
+		}
+		return recomputePlanRisk(runPlanPath(cmd, recomputePlan, config.LoadOrDefault()))
+	}
+	if err := requireFlags(cmd, "analysis", "input"); err != nil {
+		return err
+	}
+
+	if planMergeInto != "" {
+		ux.PrintHeader("Merging New Violations into Plan")
+	} else if planEstimate {
+		ux.PrintHeader("Estimating Migration Plan (heuristic, no AI)")
+	} else {
+		ux.PrintHeader("Generating Migration Plan")
+	}
 
 	// Load configuration from file (if exists)
 	cfg := config.LoadOrDefault()
+	if err := loadProviderConfigFile(cmd); err != nil {
+		return err
+	}
 
 	// Normalize inputPath to absolute path to prevent path resolution issues
 	if inputPath != "" {
@@ -693,22 +1414,60 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		inputPath = absInputPath
 	}
 
-	// Create provider
-	prov, err := createProvider(providerName, model, cfg)
+	if planApproveOnly && !planInteractiveWeb {
+		return fmt.Errorf("--approve-only requires --interactive-web")
+	}
+	if planMergeInto != "" && planInteractive {
+		return fmt.Errorf("--interactive can't be combined with --merge-into; review the merged plan with --interactive-web")
+	}
+	if planMergeInto != "" && planEstimate {
+		return fmt.Errorf("--estimate can't be combined with --merge-into")
+	}
+	if err := planner.ValidateGroupBy(planGroupBy); err != nil {
+		return err
+	}
+	batchSize, batchDelay, err := resolvePlanBatching(cmd, cfg)
 	if err != nil {
 		return err
 	}
 
+	// Merging updates an existing plan in place, outside any new run
+	if err := resolveRunID(planMergeInto == ""); err != nil {
+		return err
+	}
+	planFileName := "plan.yaml"
+	if !cmd.Flags().Changed("output") && cfg.Paths.Plan != "" {
+		planOutputPath, planFileName = filepath.Split(cfg.Paths.Plan)
+		planOutputPath = filepath.Clean(planOutputPath)
+	}
+	planOutputPath = runid.Path(planOutputPath, runID)
+
+	// Create provider; merging doesn't need one unless the web UI executes the plan
+	var prov provider.Provider
+	if planMergeInto == "" || planInteractiveWeb {
+		var err error
+		if prov, err = createProvider(providerName, model, cfg); err != nil {
+			return err
+		}
+	}
+
+	if runID != "" {
+		fmt.Printf("🏷  Run ID: %s\n", runID)
+	}
 	fmt.Printf("📋 Analysis: %s\n", analysisPath)
 	fmt.Printf("📂 Input: %s\n", inputPath)
-	fmt.Printf("🤖 Provider: %s\n", prov.Name())
-	fmt.Printf("📁 Output directory: %s\n", planOutputPath)
-	fmt.Println()
-
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(planOutputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if planMergeInto != "" {
+		fmt.Printf("📝 Merging into: %s\n", planMergeInto)
+	} else {
+		fmt.Printf("🤖 Provider: %s\n", prov.Name())
+		fmt.Printf("📁 Output directory: %s\n", planOutputPath)
+
+		// Create output directory if it doesn't exist
+		if err := os.MkdirAll(planOutputPath, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
 	}
+	fmt.Println()
 
 	// Parse filters
 	var violationIDList []string
@@ -721,25 +1480,55 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		categoryList = strings.Split(categories, ",")
 	}
 
+	applyPathFilterConfig(cfg)
+	pathFilter, err := newPathFilter()
+	if err != nil {
+		return err
+	}
+
+	var costHistory *planner.CostHistory
+	if planCostHistory != "" {
+		costHistory, err = planner.LoadCostHistory(strings.Split(planCostHistory, ","))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create planner
 	plannerConfig := planner.Config{
 		AnalysisPath:  analysisPath,
 		InputPath:     inputPath,
 		Provider:      prov,
 		OutputPath:    planOutputPath,
+		PlanFileName:  planFileName,
 		MaxPhases:     planMaxPhases,
 		RiskTolerance: planRiskTolerance,
 		Categories:    categoryList,
 		ViolationIDs:  violationIDList,
 		MaxEffort:     maxEffort,
 		Interactive:   planInteractive,
+		PathFilter:    pathFilter,
+
+		GroupBy:            planGroupBy,
+		MaxPhaseViolations: planMaxPhaseViolations,
+		CostHistory:        costHistory,
+		BatchSize:          batchSize,
+		BatchDelay:         batchDelay,
+
+		Estimate:  planEstimate,
+		MergeInto: planMergeInto,
 	}
 
 	p := planner.New(plannerConfig)
 
 	// Generate plan
-	fmt.Println("Analyzing violations and generating plan...")
-	fmt.Println()
+	if planEstimate {
+		fmt.Println("Grouping violations by category and effort (no AI call)...")
+		fmt.Println()
+	} else if planMergeInto == "" {
+		fmt.Println("Analyzing violations and generating plan...")
+		fmt.Println()
+	}
 
 	ctx := context.Background()
 	result, err := p.Generate(ctx)
@@ -765,6 +1554,37 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 		// Create web server
 		server := web.NewPlanServer(result.Plan, result.PlanPath, inputPath, prov)
+		server.SetAddr(planWebAddr)
+		server.SetToken(planWebToken)
+		server.SetReviewTimeout(planReviewTimeout)
+		server.SetApproveOnly(planApproveOnly)
+		if planApproveOnly {
+			fmt.Println("Approve-only mode: save your decisions in the UI, then apply them with 'kantra-ai execute'")
+			if planReviewTimeout > 0 {
+				ux.PrintWarning("--plan-review-timeout is ignored with --approve-only")
+			}
+			fmt.Println()
+		}
+		server.SetStatePath(runStatePath(cfg))
+
+		// PRs created from the UI use the same settings as execute's
+		if baseBranch == "" {
+			baseBranch = cfg.Git.BaseBranch
+		}
+		if prDiffFormat == "" {
+			prDiffFormat = cfg.Git.PRDiffFormat
+		}
+		diffFormat, err := gitutil.ParseDiffFormat(prDiffFormat)
+		if err != nil {
+			return err
+		}
+		server.SetPRConfig(gitutil.PRConfig{
+			BaseBranch:      baseBranch,
+			UserAgent:       userAgent,
+			OperationDelay:  prDelay,
+			MaxBranchLength: cfg.Git.MaxBranchLength,
+			DiffFormat:      diffFormat,
+		})
 
 		// Start server (blocks until interrupted)
 		if err := server.Start(ctx, true); err != nil {
@@ -774,8 +1594,20 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if planMergeInto != "" {
+		printPlanMerge(result, htmlPath, duration)
+		return nil
+	}
+
 	// Print success message
-	ux.PrintHeader("Plan Generated Successfully")
+	if result.Heuristic {
+		ux.PrintHeader("Heuristic Plan Generated")
+		fmt.Println("Phases were grouped by category and effort without calling the model;")
+		fmt.Println("costs are estimates. Regenerate without --estimate for AI grouping and explanations.")
+		fmt.Println()
+	} else {
+		ux.PrintHeader("Plan Generated Successfully")
+	}
 
 	rows := [][]string{
 		{"📝 Plan file:", ux.Success(result.PlanPath)},
@@ -799,18 +1631,61 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  • View report:    open %s\n", htmlPath)
 	}
 	fmt.Printf("  • Edit if needed: vim %s\n", result.PlanPath)
-	fmt.Println("  • Execute:        kantra-ai execute")
+	fmt.Printf("  • Check edits:    kantra-ai validate-plan --plan %s --analysis %s --input %s\n", result.PlanPath, analysisPath, inputPath)
+	fmt.Printf("  • Execute:        kantra-ai execute --run-id=%s\n", runID)
 
 	return nil
 }
 
+// printPlanMerge prints the outcome of plan --merge-into
+func printPlanMerge(result *planner.Result, htmlPath string, duration time.Duration) {
+	if result.NewViolations == 0 {
+		ux.PrintSuccess("No new violations: %s is unchanged", result.PlanPath)
+		return
+	}
+
+	ux.PrintHeader("Plan Merged Successfully")
+
+	rows := [][]string{
+		{"📝 Plan file:", ux.Success(result.PlanPath)},
+		{"🆕 New violations:", ux.Success(fmt.Sprintf("%d", result.NewViolations))},
+		{"➕ New phases:", ux.Info(fmt.Sprintf("%d", result.NewPhases))},
+		{"📊 Total phases:", ux.Success(fmt.Sprintf("%d", result.TotalPhases))},
+		{"💰 Estimated total cost:", ux.FormatCost(result.TotalCost)},
+		{"⏱  Duration:", ux.FormatDuration(duration)},
+	}
+	if htmlPath != "" {
+		rows = append(rows, []string{"📄 HTML report:", ux.Success(htmlPath)})
+	}
+	ux.PrintSummaryTable(rows)
+
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Printf("  • Review plan:    cat %s\n", result.PlanPath)
+	fmt.Printf("  • Check edits:    kantra-ai validate-plan --plan %s --analysis %s --input %s\n", result.PlanPath, analysisPath, inputPath)
+	// The plan no longer matches an existing state file
+	fmt.Printf("  • Execute:        kantra-ai execute --plan %s --force\n", result.PlanPath)
+}
+
 func runExecute(cmd *cobra.Command, args []string) error {
 	startTime := time.Now()
 
+	jsonOut, err := setupOutputFormat()
+	if err != nil {
+		return err
+	}
+
 	ux.PrintHeader("Executing Migration Plan")
 
 	// Load configuration from file (if exists)
 	cfg := config.LoadOrDefault()
+	if err := loadProviderConfigFile(cmd); err != nil {
+		return err
+	}
+	// Plans are executed in batches, which always return full file content
+	if cfg.Provider.ResponseFormat == string(provider.ResponseFormatDiff) {
+		ux.PrintWarning("provider.response-format: diff only applies to remediate; execute fixes incidents in batches, which return full file content")
+	}
 
 	// Normalize inputPath to absolute path to prevent path resolution issues
 	if inputPath != "" {
@@ -827,12 +1702,24 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		inputPath = absInputPath
 	}
 
+	if err := resolveRunID(false); err != nil {
+		return err
+	}
+	executePlanPath = runPlanPath(cmd, executePlanPath, cfg)
+	if !cmd.Flags().Changed("state") && cfg.Paths.State != "" {
+		executeStatePath = cfg.Paths.State
+	}
+	executeStatePath = runid.Path(executeStatePath, runID)
+
 	// Create provider
 	prov, err := createProvider(providerName, model, cfg)
 	if err != nil {
 		return err
 	}
 
+	if runID != "" {
+		fmt.Printf("🏷  Run ID: %s\n", runID)
+	}
 	fmt.Printf("📋 Plan: %s\n", executePlanPath)
 	fmt.Printf("📊 State: %s\n", executeStatePath)
 	fmt.Printf("📂 Input: %s\n", inputPath)
@@ -846,14 +1733,17 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		if !gitutil.IsGitInstalled() {
 			return fmt.Errorf("--git-commit requires git to be installed")
 		}
-		if !gitutil.IsGitRepository(inputPath) {
-			return fmt.Errorf("--git-commit requires input directory to be a git repository")
+		if err := gitutil.ValidateRepository(inputPath); err != nil {
+			return fmt.Errorf("--git-commit cannot be used with this input directory: %w", err)
 		}
 
 		strategy, err := gitutil.ParseStrategy(gitCommitStrategy)
 		if err != nil {
 			return err
 		}
+		if squashCommits && strategy != gitutil.StrategyPerIncident {
+			return fmt.Errorf("--squash requires --git-commit per-incident")
+		}
 
 		// Check if verification is requested
 		if verify != "" {
@@ -868,13 +1758,31 @@ func runExecute(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			applyVerifyWarningConfig(cfg)
+			applyCoverageConfig(cfg)
+			verifyScp, err := resolveVerifyScope(cfg)
+			if err != nil {
+				return err
+			}
 			verifyConfig := verifier.Config{
-				Type:          verifyType,
-				Strategy:      verifyStrat,
-				WorkingDir:    inputPath,
-				CustomCommand: verifyCommand,
-				FailFast:      verifyFailFast,
-				SkipOnDryRun:  dryRun,
+				Type:           verifyType,
+				Strategy:       verifyStrat,
+				WorkingDir:     inputPath,
+				CustomCommand:  verifyCommand,
+				FailFast:       verifyFailFast,
+				DryRun:         dryRun,
+				VerifyOnDryRun: verifyOnDryRun,
+
+				LanguageCommands: resolveLanguageCommands(cfg),
+				WarningExitCodes: verifyWarnCodes,
+				WarningPattern:   verifyWarnPattern,
+				Scope:            verifyScp,
+				AffectedCommand:  verifyAffectedCmd,
+				Offline:          verifyOffline,
+
+				CoverageReport:    coverageReport,
+				CoverageBaseline:  coverageBaseline,
+				CoverageTolerance: coverageTolerance,
 			}
 
 			verifiedTracker, err = gitutil.NewVerifiedCommitTracker(strategy, inputPath, providerName, verifyConfig)
@@ -883,13 +1791,40 @@ func runExecute(cmd *cobra.Command, args []string) error {
 			}
 			commitTracker = verifiedTracker.GetCommitTracker()
 			ux.PrintSuccess("Git commits enabled (%s strategy)", gitCommitStrategy)
-			ux.PrintSuccess("Verification enabled (%s, %s strategy)", verify, verifyStrategy)
+			if reason := verifyConfig.SkipReason(); reason != "" {
+				ux.PrintWarning("Verification skipped: %s", reason)
+			} else {
+				ux.PrintSuccess("Verification enabled (%s, %s strategy)", verify, verifyStrategy)
+			}
+			if baseline := verifiedTracker.BaselineCoverage(); baseline != nil {
+				ux.PrintInfo("Baseline coverage: %s", baseline)
+			}
 			fmt.Println()
 		} else {
 			commitTracker = gitutil.NewCommitTracker(strategy, inputPath, providerName)
 			ux.PrintSuccess("Git commits enabled (%s strategy)", gitCommitStrategy)
 			fmt.Println()
 		}
+		commitTracker.SetSquash(squashCommits)
+		if commitTemplate == "" {
+			commitTemplate = cfg.Git.CommitTemplate
+		}
+		if commitTemplate != "" {
+			tmpl, err := gitutil.LoadCommitTemplate(commitTemplate)
+			if err != nil {
+				return fmt.Errorf("invalid --commit-template: %w", err)
+			}
+			commitTracker.SetMessageTemplate(tmpl)
+		}
+		categoryStrategies, err := resolveCategoryStrategies(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid --category-commit-strategy: %w", err)
+		}
+		commitTracker.SetCategoryStrategies(categoryStrategies)
+		if noVerifyCommit {
+			commitTracker.SetNoVerify(true)
+			ux.PrintWarning("Git hooks will be skipped for kantra-ai commits (--no-verify-commit)")
+		}
 	}
 
 	// Initialize PR tracker if requested
@@ -926,18 +1861,40 @@ func runExecute(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		// Generate branch name if not provided
+		// Generate branch name if not provided; with a run ID the ID
+		// replaces the timestamp
 		if branchName == "" {
-			branchName = fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
+			if runID != "" {
+				branchName = "kantra-ai/remediation"
+			} else {
+				branchName = fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
+			}
+		}
+		branchName = runid.Branch(branchName, runID)
+
+		if prDiffFormat == "" {
+			prDiffFormat = cfg.Git.PRDiffFormat
+		}
+		if baseBranch == "" {
+			baseBranch = cfg.Git.BaseBranch
+		}
+		diffFormat, err := gitutil.ParseDiffFormat(prDiffFormat)
+		if err != nil {
+			return err
 		}
 
 		// Initialize PR tracker
 		prConfig := gitutil.PRConfig{
 			Strategy:         parsedPRStrategy,
 			BranchPrefix:     branchName,
+			BaseBranch:       baseBranch,
 			GitHubToken:      githubToken,
+			UserAgent:        userAgent,
 			DryRun:           dryRun,
 			CommentThreshold: prCommentThreshold,
+			OperationDelay:   prDelay,
+			MaxBranchLength:  cfg.Git.MaxBranchLength,
+			DiffFormat:       diffFormat,
 		}
 
 		progress := &gitutil.StdoutProgressWriter{}
@@ -955,6 +1912,10 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid confidence configuration: %w", err)
 	}
+	confidenceConf.ReviewFile = runid.Path(fixer.ReviewFileName, runID)
+	if confidenceConf.ReviewDir == "" {
+		confidenceConf.ReviewDir = runid.Path(fixer.ReviewDirName, runID)
+	}
 
 	// Build batch configuration
 	batchConfig := fixer.DefaultBatchConfig()
@@ -964,30 +1925,49 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	if maxBatchTokens > 0 {
 		batchConfig.MaxTokensPerBatch = maxBatchTokens
 	}
-	if batchParallelism > 0 {
-		batchConfig.Parallelism = batchParallelism
+	if secondPassThreshold < 0 || secondPassThreshold > 1 {
+		return fmt.Errorf("invalid --second-pass-threshold %g: must be between 0.0 and 1.0", secondPassThreshold)
+	}
+	batchConfig.SecondPassThreshold = secondPassThreshold
+	batchConfig.ReuseIdenticalFixes = reuseIdenticalFixes
+	parallelism, err := resolveParallelism(providerName)
+	if err != nil {
+		return err
+	}
+	batchConfig.Parallelism = parallelism
+	batchConfig.RateTracker = fixer.NewTPMTracker(resolveTPMLimit(cfg))
+	batchConfig.SyntaxCheck = validateSyntax || cfg.Verification.SyntaxCheck
+
+	fixAssertion, err := newFixAssertion()
+	if err != nil {
+		return err
 	}
 
 	// Create executor config
 	executorConfig := executor.Config{
-		PlanPath:           executePlanPath,
-		StatePath:          executeStatePath,
-		InputPath:          inputPath,
-		Provider:           prov,
-		PhaseID:            executePhaseID,
-		DryRun:             dryRun,
-		GitCommit:          gitCommitStrategy,
-		CreatePR:           createPR,
-		PRStrategy:         prStrategy,
-		PRCommentThreshold: prCommentThreshold,
-		BranchName:         branchName,
-		Progress:           &ux.ConsoleProgressWriter{},
-		Resume:             executeResume,
-		BatchConfig:        batchConfig,
-		ConfidenceConfig:   confidenceConf,
-		CommitTracker:      commitTracker,
-		VerifiedTracker:    verifiedTracker,
-		PRTracker:          prTracker,
+		PlanPath:            executePlanPath,
+		StatePath:           executeStatePath,
+		InputPath:           inputPath,
+		Provider:            prov,
+		PhaseID:             executePhaseID,
+		DryRun:              dryRun,
+		GitCommit:           gitCommitStrategy,
+		CreatePR:            createPR,
+		PRStrategy:          prStrategy,
+		PRCommentThreshold:  prCommentThreshold,
+		BranchName:          branchName,
+		Progress:            &ux.ConsoleProgressWriter{},
+		Resume:              executeResume,
+		Force:               executeForce,
+		MaxPhases:           executeMaxPhases,
+		MaxIncidentAttempts: executeMaxAttempts,
+		BatchConfig:         batchConfig,
+		ConfidenceConfig:    confidenceConf,
+		CommitTracker:       commitTracker,
+		VerifiedTracker:     verifiedTracker,
+		PRTracker:           prTracker,
+		PRCountPreview:      prCountPreview,
+		FixAssertion:        fixAssertion,
 	}
 
 	// Create executor
@@ -1002,33 +1982,736 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		ux.PrintError("Execution failed: %v", err)
 		if result != nil {
-			printExecutionSummary(result, time.Since(startTime))
+			printExecutionSummary(result, time.Since(startTime), prov)
+			if verifiedTracker != nil {
+				printVerificationFailures(verifiedTracker.Failures())
+				printRevertedIncidents(verifiedTracker.RevertedIncidents())
+			}
+			runSummary = buildRunSummary("execute", result.Fixes, time.Since(startTime), verifiedTracker, prov)
+			runPRURLs = executedPRURLs(result)
+			if jsonOut != nil {
+				if jsonErr := runSummary.WriteJSON(jsonOut); jsonErr != nil {
+					ux.PrintWarning("Failed to write JSON summary: %v", jsonErr)
+				}
+			}
 		}
 		return err
 	}
 
 	duration := time.Since(startTime)
-	printExecutionSummary(result, duration)
+	printExecutionSummary(result, duration, prov)
+	if verifiedTracker != nil {
+		printVerificationFailures(verifiedTracker.Failures())
+		printRevertedIncidents(verifiedTracker.RevertedIncidents())
+	}
+
+	if len(result.RemainingPhases) > 0 {
+		fmt.Println()
+		ux.PrintInfo("%d phase(s) left for the next run: %s", len(result.RemainingPhases), strings.Join(result.RemainingPhases, ", "))
+		fmt.Printf("  Run %s again to continue\n", ux.Bold("kantra-ai execute"))
+	}
 
 	if dryRun {
 		fmt.Println()
 		ux.PrintWarning("DRY-RUN mode - no changes were made")
 	}
 
+	runSummary = buildRunSummary("execute", result.Fixes, duration, verifiedTracker, prov)
+	runPRURLs = executedPRURLs(result)
+	if jsonOut != nil {
+		return runSummary.WriteJSON(jsonOut)
+	}
+
 	return nil
 }
 
-func printExecutionSummary(result *executor.Result, duration time.Duration) {
-	ux.PrintHeader("Execution Summary")
-
-	rows := [][]string{
-		{"📊 Total phases:", ux.Success(fmt.Sprintf("%d", result.TotalPhases))},
+// applyPathFilterConfig applies config file path globs for flags that weren't set
+func runReport(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadOrDefault()
+	if err := resolveRunID(false); err != nil {
+		return err
+	}
+	reportPlanPath = runPlanPath(cmd, reportPlanPath, cfg)
+	if runID != "" && !cmd.Flags().Changed("state") {
+		// Overlay the run's results if it has been executed
+		statePath := runStatePath(cfg)
+		if _, err := os.Stat(statePath); err == nil {
+			reportStatePath = statePath
+		}
+	}
+	if !cmd.Flags().Changed("output") {
+		reportOutputPath = cfg.Paths.ReportPath(reportOutputPath)
+	}
+
+	plan, err := planfile.LoadPlan(reportPlanPath)
+	if err != nil {
+		return err
+	}
+
+	var state *planfile.ExecutionState
+	if reportStatePath != "" {
+		state, err = planfile.LoadState(reportStatePath)
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			return fmt.Errorf("state file not found: %s", reportStatePath)
+		}
+	}
+
+	if reportResults {
+		if state == nil {
+			return fmt.Errorf("--results requires --state")
+		}
+		if !cmd.Flags().Changed("output") {
+			reportOutputPath = cfg.Paths.ReportPath("results.html")
+		}
+		reportOutputPath = runid.Path(reportOutputPath, runID)
+		if !gitutil.IsGitRepository(inputPath) {
+			ux.PrintWarning("%s is not a git repository; applied diffs will not be included", inputPath)
+		}
+
+		data := report.BuildResults(plan, state, inputPath)
+		if err := report.WriteResultsHTML(data, reportOutputPath); err != nil {
+			return err
+		}
+
+		ux.PrintSuccess("Results report written to %s", reportOutputPath)
+		return nil
+	}
+
+	reportOutputPath = runid.Path(reportOutputPath, runID)
+	if err := report.WriteHTML(plan, state, reportOutputPath); err != nil {
+		return err
+	}
+
+	ux.PrintSuccess("HTML report written to %s", reportOutputPath)
+	return nil
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	// Refuse before asking anything
+	if _, err := os.Stat(configInitPath); err == nil && !configInitForce {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", configInitPath)
+	}
+
+	answers := config.DefaultInitAnswers()
+	if !configInitNonInteractive {
+		ux.PrintHeader("kantra-ai Configuration")
+		fmt.Println("Press Enter to keep the suggested answer in brackets.")
+		fmt.Println()
+
+		var err error
+		answers, err = config.NewInitPrompter(os.Stdin, os.Stdout).Ask(answers, gitutil.StrategyNames())
+		if err != nil {
+			return fmt.Errorf("%w (use --non-interactive to write the defaults)", err)
+		}
+		fmt.Println()
+	}
+
+	if err := config.WriteInitConfig(configInitPath, answers, configInitForce); err != nil {
+		return err
+	}
+	ux.PrintSuccess("Wrote %s", configInitPath)
+	return nil
+}
+
+func runReportMerge(cmd *cobra.Command, args []string) error {
+	states := make(map[string]*planfile.ExecutionState, len(args))
+	for _, path := range args {
+		state, err := planfile.LoadState(path)
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			return fmt.Errorf("state file not found: %s", path)
+		}
+		states[path] = state
+	}
+
+	consolidated := report.Consolidate(states)
+	if mergeReportJSON {
+		return consolidated.WriteJSON(os.Stdout)
+	}
+	return consolidated.WriteText(os.Stdout)
+}
+
+func runValidatePlan(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(validatePlanPath)
+	if err != nil {
+		return fmt.Errorf("failed to read plan file: %w", err)
+	}
+	analysis, err := violation.LoadAnalysis(analysisPath)
+	if err != nil {
+		return fmt.Errorf("failed to load analysis: %w", err)
+	}
+
+	resolveFile := func(path string) error {
+		relPath, err := fixer.ResolveFilePath(path, inputPath)
+		if err != nil {
+			// The first line; the rest is advice on --input
+			message, _, _ := strings.Cut(err.Error(), "\n")
+			return errors.New(message)
+		}
+		if _, err := os.Stat(filepath.Join(inputPath, relPath)); err != nil {
+			return fmt.Errorf("file not found under --input (%s)", relPath)
+		}
+		return nil
+	}
+
+	problems := planfile.CheckPlan(data, analysis, resolveFile)
+	errorCount := 0
+	for _, problem := range problems {
+		severity := ux.Warning(problem.Severity)
+		if problem.Severity == planfile.ProblemError {
+			severity = ux.Error(problem.Severity)
+			errorCount++
+		}
+		location := validatePlanPath
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", validatePlanPath, problem.Line)
+		}
+		if problem.Path != "" {
+			fmt.Printf("%s: %s: %s: %s\n", location, severity, problem.Path, problem.Message)
+		} else {
+			fmt.Printf("%s: %s: %s\n", location, severity, problem.Message)
+		}
+	}
+
+	warningCount := len(problems) - errorCount
+	if errorCount > 0 {
+		cmd.SilenceUsage = true // The problems are in the plan, not the command line
+		return fmt.Errorf("plan has %d error(s) and %d warning(s)", errorCount, warningCount)
+	}
+	if warningCount > 0 {
+		ux.PrintWarning("Plan is valid with %d warning(s)", warningCount)
+	} else {
+		ux.PrintSuccess("Plan is valid")
+	}
+
+	if recomputeRisk {
+		return recomputePlanRisk(validatePlanPath)
+	}
+	return nil
+}
+
+// recomputePlanRisk recalculates the risk of the phases of the plan at path
+// from their violations and saves it if any changed
+func recomputePlanRisk(path string) error {
+	plan, err := planfile.LoadPlan(path)
+	if err != nil {
+		return err
+	}
+
+	changes := planfile.RecomputeRisk(plan)
+	if len(changes) == 0 {
+		ux.PrintSuccess("Phase risks match their violations")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Printf("  • %s: %s → %s\n", change.PhaseID, change.From, change.To)
+	}
+	if err := planfile.SavePlan(plan, path); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+
+	ux.PrintSuccess("Recomputed the risk of %d phase(s) in %s", len(changes), path)
+	fmt.Println("Set risk_locked: true on a phase to keep a risk you chose.")
+	return nil
+}
+
+func runCapabilities(cmd *cobra.Command, args []string) error {
+	caps := capabilities.Get()
+	if capabilitiesJSON {
+		return caps.WriteJSON(os.Stdout)
+	}
+
+	fmt.Println("Providers:")
+	for _, p := range caps.Providers {
+		if p.Preset {
+			fmt.Printf("  %-12s %s (default model: %s)\n", p.Name, p.Description, p.DefaultModel)
+		} else {
+			fmt.Printf("  %s\n", p.Name)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Commit strategies:       %s\n", strings.Join(caps.CommitStrategies, ", "))
+	fmt.Printf("Verification types:      %s\n", strings.Join(caps.VerificationTypes, ", "))
+	fmt.Printf("Verification strategies: %s\n", strings.Join(caps.VerificationStrategies, ", "))
+	fmt.Printf("Verification scopes:     %s\n", strings.Join(caps.VerificationScopes, ", "))
+	fmt.Println()
+	fmt.Printf("Config schema version:   %s (top-level keys: %s)\n", caps.ConfigSchemaVersion, strings.Join(caps.ConfigKeys, ", "))
+	fmt.Printf("Plan file version:       %s\n", caps.PlanVersion)
+	fmt.Printf("State file version:      %s\n", caps.StateVersion)
+	return nil
+}
+
+func runProviders(cmd *cobra.Command, args []string) error {
+	catalog := provider.Catalog()
+	if providersJSON {
+		return provider.WriteCatalogJSON(os.Stdout, catalog)
+	}
+
+	for _, p := range catalog {
+		keyStatus := "not set"
+		if p.APIKeySet {
+			keyStatus = "set"
+		}
+		fmt.Printf("%s\n", p.Name)
+		fmt.Printf("  %s\n", p.Description)
+		fmt.Printf("  Default model: %s\n", p.DefaultModel)
+		if len(p.Models) > 1 {
+			fmt.Printf("  Other models:  %s\n", strings.Join(p.Models[1:], ", "))
+		} else {
+			fmt.Printf("  Other models:  any\n")
+		}
+		if p.BaseURL != "" {
+			fmt.Printf("  Base URL:      %s\n", p.BaseURL)
+		}
+		fmt.Printf("  API key:       %s (%s)\n", p.APIKeyEnv, keyStatus)
+		fmt.Println()
+	}
+	return nil
+}
+
+func applyPathFilterConfig(cfg *config.Config) {
+	if includePaths == "" && len(cfg.Filters.IncludePaths) > 0 {
+		includePaths = strings.Join(cfg.Filters.IncludePaths, ",")
+	}
+	if excludePaths == "" && len(cfg.Filters.ExcludePaths) > 0 {
+		excludePaths = strings.Join(cfg.Filters.ExcludePaths, ",")
+	}
+	if maxPathDepth == 0 && cfg.Filters.MaxPathDepth > 0 {
+		maxPathDepth = cfg.Filters.MaxPathDepth
+	}
+}
+
+// newPathFilter creates the --include-paths/--exclude-paths/--files-from/
+// --since/--max-path-depth incident filter, or returns nil if none is set.
+// With both --files-from and --since, only files in both lists are kept.
+func newPathFilter() (*violation.PathFilter, error) {
+	var include, exclude []string
+	if includePaths != "" {
+		include = strings.Split(includePaths, ",")
+	}
+	if excludePaths != "" {
+		exclude = strings.Split(excludePaths, ",")
+	}
+	files, err := readFilesFrom()
+	if err != nil {
+		return nil, err
+	}
+	if sinceRef != "" {
+		changed, err := gitutil.ChangedFilesSince(inputPath, sinceRef)
+		if err != nil {
+			return nil, fmt.Errorf("--since: %w", err)
+		}
+		files = violation.IntersectFileLists(inputPath, files, changed)
+	}
+	filter, err := violation.NewPathFilter(inputPath, include, exclude, files, maxPathDepth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --include-paths/--exclude-paths/--max-path-depth: %w", err)
+	}
+	return filter, nil
+}
+
+// readFilesFrom reads the --files-from list, or returns nil if it is not set
+func readFilesFrom() ([]string, error) {
+	if filesFrom == "" {
+		return nil, nil
+	}
+	if filesFrom == "-" {
+		if analysisPath == "-" {
+			return nil, fmt.Errorf("--files-from and --analysis cannot both read from stdin")
+		}
+		return violation.ReadFileList(os.Stdin)
+	}
+
+	f, err := os.Open(filesFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open --files-from list: %w", err)
+	}
+	defer f.Close()
+	return violation.ReadFileList(f)
+}
+
+// resolveTPMLimit returns --tpm-limit, falling back to the config file
+func resolveTPMLimit(cfg *config.Config) int {
+	if tpmLimit == 0 && cfg.Provider.TPMLimit > 0 {
+		return cfg.Provider.TPMLimit
+	}
+	return tpmLimit
+}
+
+// resolvePlanBatching returns --plan-batch-size and --plan-batch-delay,
+// falling back to the config file. A nil delay leaves it to the provider.
+func resolvePlanBatching(cmd *cobra.Command, cfg *config.Config) (int, *time.Duration, error) {
+	size := planBatchSize
+	if !cmd.Flags().Changed("plan-batch-size") {
+		size = cfg.Provider.PlanBatchSize
+	} else if size < 1 {
+		return 0, nil, fmt.Errorf("invalid --plan-batch-size %d: must be at least 1", size)
+	}
+	if size < 0 {
+		return 0, nil, fmt.Errorf("invalid provider.plan-batch-size %d: must be at least 1", size)
+	}
+
+	var delay *time.Duration
+	switch {
+	case cmd.Flags().Changed("plan-batch-delay"):
+		if planBatchDelay < 0 {
+			return 0, nil, fmt.Errorf("invalid --plan-batch-delay %s: must not be negative", planBatchDelay)
+		}
+		delay = &planBatchDelay
+	case cfg.Provider.PlanBatchDelay != "":
+		d, err := time.ParseDuration(cfg.Provider.PlanBatchDelay)
+		if err != nil || d < 0 {
+			return 0, nil, fmt.Errorf("invalid provider.plan-batch-delay %q: must be a non-negative duration such as 5s", cfg.Provider.PlanBatchDelay)
+		}
+		delay = &d
+	}
+	return size, delay, nil
+}
+
+// resolveParallelism returns the number of concurrent batches: --parallelism
+// capped by the provider's rate limits, or the provider's limit if it is 0
+func resolveParallelism(name string) (int, error) {
+	if batchParallelism < 0 {
+		return 0, fmt.Errorf("invalid --parallelism %d: must be at least 1 (or 0 for auto)", batchParallelism)
+	}
+	parallelism := provider.ClampParallelism(name, batchParallelism)
+	if parallelism < batchParallelism {
+		ux.PrintWarning("--parallelism %d capped at %d, the most concurrent requests %s handles without hitting rate limits", batchParallelism, parallelism, name)
+	}
+	return parallelism, nil
+}
+
+// newFixAssertion creates the --fix-assert predicate, or returns nil if it is not set
+func newFixAssertion() (*verifier.FixAssertion, error) {
+	if fixAssert == "" {
+		return nil, nil
+	}
+	assertion, err := verifier.NewFixAssertion(fixAssert, inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --fix-assert: %w", err)
+	}
+	return assertion, nil
+}
+
+// promptBudget asks whether to raise the budget after --max-cost was
+// reached. It returns the new budget, or false to stop; without a terminal
+// there is nobody to ask, so it stops.
+func promptBudget(spent float64) (float64, bool) {
+	if !ux.IsTerminal() {
+		ux.PrintWarning("Not running in a terminal, can't prompt for a new budget")
+		return 0, false
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Spent $%.2f. Enter a new max cost in USD to continue, or press Enter to stop: ", spent)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, false
+		}
+		input = strings.TrimPrefix(strings.TrimSpace(input), "$")
+		if input == "" {
+			return 0, false
+		}
+		budget, err := strconv.ParseFloat(input, 64)
+		if err != nil || budget <= spent {
+			fmt.Printf("Please enter an amount above $%.2f\n", spent)
+			continue
+		}
+		return budget, true
+	}
+}
+
+// requireFlags fails like a flag marked required when any of names wasn't set,
+// for flags that only some modes of a command require
+func requireFlags(cmd *cobra.Command, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if !cmd.Flags().Changed(name) {
+			missing = append(missing, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// resolveRunID validates --run-id. Commands that start a run (plan and
+// remediate) generate a timestamp run ID if none is given; commands that
+// read a run's artifacts look up the latest run instead (see runPlanPath).
+func resolveRunID(startsRun bool) error {
+	id, err := runid.Resolve(runID)
+	if err != nil {
+		return err
+	}
+	if id == "" && startsRun {
+		id = runid.Generate()
+	}
+	runID = id
+	return nil
+}
+
+// runPlanPath returns the plan to read for the run: a --plan left at its
+// default points at the plan written by "kantra-ai plan" with the same
+// paths.plan and --run-id (.kantra-ai-plan-<id>/plan.yaml), or without
+// --run-id at the latest plan written, whose run ID becomes the run's. An
+// explicit --plan is used as is.
+func runPlanPath(cmd *cobra.Command, planPath string, cfg *config.Config) string {
+	if cmd.Flags().Changed("plan") {
+		return planPath
+	}
+
+	planFile := cfg.Paths.Plan
+	if planFile == "" {
+		planFile = filepath.Join(".kantra-ai-plan", "plan.yaml")
+	}
+	dir, name := filepath.Split(planFile)
+	dir = filepath.Clean(dir)
+	if runID == "" {
+		id, ok := runid.Latest(dir, name)
+		if !ok && cfg.Paths.Plan == "" {
+			return planPath
+		}
+		runID = id
+	}
+	return filepath.Join(runid.Path(dir, runID), name)
+}
+
+// loadRemediateProgress returns the progress to record to with --state or
+// --resume, or nil without either. With --resume, the state file's fixed
+// incidents are skipped.
+func loadRemediateProgress() (*planfile.RemediateProgress, error) {
+	if remediateStatePath == "" && !remediateResume {
+		return nil, nil
+	}
+	path := remediateStatePath
+	if path == "" {
+		path = runid.Path(defaultRemediateState, runID)
+	}
+
+	progress, err := planfile.LoadRemediateProgress(path, analysisPath, remediateResume, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Resumed() {
+		fmt.Printf("📊 Resuming from %s\n", path)
+	} else if remediateResume {
+		ux.PrintWarning("No state file at %s; starting from the beginning", path)
+	}
+	return progress, nil
+}
+
+// recordProgress records a fix attempt in the state file: applied, skipped
+// with its reason (low confidence, ignored or skipped in review), or failed
+func recordProgress(progress *planfile.RemediateProgress, v violation.Violation, incident violation.Incident, result *fixer.FixResult, err error) {
+	var saveErr error
+	switch {
+	case result != nil && !result.Success && result.SkipReason != "":
+		saveErr = progress.RecordSkip(v.ID, incident, result.SkipReason)
+	case err == nil && result.Success:
+		saveErr = progress.RecordFix(v.ID, incident, result.Cost)
+	default:
+		if err == nil {
+			err = result.Error
+		}
+		msg := "fix failed"
+		if err != nil {
+			msg = err.Error()
+		}
+		saveErr = progress.RecordFailure(v.ID, incident, msg)
+	}
+	if saveErr != nil {
+		ux.PrintWarning("    Failed to save state: %v", saveErr)
+	}
+}
+
+// runStatePath returns the execution state file for the run, from
+// paths.state or the executor's default
+func runStatePath(cfg *config.Config) string {
+	statePath := cfg.Paths.State
+	if statePath == "" {
+		statePath = executor.DefaultStatePath
+	}
+	return runid.Path(statePath, runID)
+}
+
+// setupOutputFormat validates --output-format. In json mode all human-readable
+// output is redirected to stderr and spinners/progress bars are disabled, so
+// stdout carries only the JSON summary; the returned writer is the original
+// stdout. It returns nil in text mode.
+func setupOutputFormat() (io.Writer, error) {
+	switch outputFormat {
+	case "", "text":
+		return nil, nil
+	case "json":
+		jsonOut := os.Stdout
+		os.Stdout = os.Stderr
+		ux.SetQuiet(true)
+		return jsonOut, nil
+	default:
+		return nil, fmt.Errorf("invalid --output-format '%s' (must be text or json)", outputFormat)
+	}
+}
+
+// printPhaseCostTable renders estimated vs actual cost per phase with the variance
+func printPhaseCostTable(costs []executor.PhaseCost) {
+	rows := [][]string{{"  Phase", "Estimated", "Actual", "Tokens", "Variance"}}
+
+	var totalEstimated, totalActual float64
+	var totalTokens int
+	for _, c := range costs {
+		rows = append(rows, []string{
+			"  " + c.PhaseName,
+			ux.FormatCost(c.EstimatedCost),
+			ux.FormatCost(c.ActualCost),
+			ux.FormatTokens(c.ActualTokens),
+			formatVariance(c),
+		})
+		totalEstimated += c.EstimatedCost
+		totalActual += c.ActualCost
+		totalTokens += c.ActualTokens
+	}
+
+	if len(costs) > 1 {
+		total := executor.PhaseCost{EstimatedCost: totalEstimated, ActualCost: totalActual}
+		rows = append(rows, []string{
+			"  Total",
+			ux.FormatCost(totalEstimated),
+			ux.FormatCost(totalActual),
+			ux.FormatTokens(totalTokens),
+			formatVariance(total),
+		})
+	}
+
+	ux.PrintSummaryTable(rows)
+}
+
+// formatVariance formats a phase's cost variance, e.g. "+12.5%" or "n/a" without an estimate
+func formatVariance(c executor.PhaseCost) string {
+	variance, ok := c.VariancePercent()
+	if !ok {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", variance)
+}
+
+// printVerificationFailures prints the command, error and last lines of
+// output of each failed verification
+func printVerificationFailures(failures []gitutil.VerificationFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	ux.PrintSection("Verification Failures")
+	for i, failure := range failures {
+		fmt.Printf("  %s %s (%s)\n", ux.Error("✗"), ux.Bold(failure.Command), strings.Join(failure.ViolationIDs, ", "))
+		if failure.Error != "" {
+			fmt.Printf("    Error: %s\n", failure.Error)
+		}
+		fmt.Printf("    Output (last %d lines):\n", gitutil.FailureOutputLines)
+		for _, line := range strings.Split(failure.OutputTail(gitutil.FailureOutputLines), "\n") {
+			fmt.Printf("      %s\n", line)
+		}
+		if i < len(failures)-1 {
+			fmt.Println()
+		}
+	}
+}
+
+// printRevertedIncidents lists the incidents whose fixes were reverted after
+// failing per-fix verification, which need to be fixed by hand
+func printRevertedIncidents(reverted []gitutil.RevertedIncident) {
+	if len(reverted) == 0 {
+		return
+	}
+	ux.PrintSection("Reverted Fixes (manual handling needed)")
+	for _, r := range reverted {
+		fmt.Printf("  %s %s:%d (%s)\n", ux.Warning("↩"), r.FilePath, r.Incident.LineNumber, r.ViolationID)
+	}
+}
+
+// printExplanation prints the model's explanation of an applied fix with
+// --explain, and of a low-confidence fix always
+func printExplanation(result *fixer.FixResult) {
+	explanation := strings.TrimSpace(result.Explanation)
+	if explanation == "" || !(result.LowConfidence || (explainFixes && result.Success)) {
+		return
+	}
+	for _, line := range strings.Split(explanation, "\n") {
+		fmt.Printf("    %s\n", ux.Dim(line))
+	}
+}
+
+// buildRunSummary builds the --output-format=json summary of a run, with
+// the complete logs of failed verifications if verification was enabled
+func buildRunSummary(command string, fixes []gitutil.FixRecord, duration time.Duration, verifiedTracker *gitutil.VerifiedCommitTracker, prov provider.Provider) *report.Summary {
+	summary := report.BuildSummary(command, dryRun, fixes, duration)
+	if chain := fallbackChain(prov); chain != nil {
+		_, summary.CostByProvider = chain.Spend()
+	}
+	if !explainFixes {
+		summary.OmitExplanations()
+	}
+	if verifiedTracker != nil {
+		summary.Verification = report.BuildVerificationSummary(verifiedTracker.GetStats(), verifiedTracker.Failures())
+	}
+	return summary
+}
+
+// executedPRURLs returns the URLs of the pull requests an execute run created
+func executedPRURLs(result *executor.Result) []string {
+	var urls []string
+	for _, pr := range result.PRs {
+		urls = append(urls, pr.URL)
+	}
+	return urls
+}
+
+// notifyOnCompletion wraps a command's run function to POST a summary of the
+// run to --notify-webhook when it finishes or fails. A failed notification
+// is only a warning and doesn't change the run's outcome.
+func notifyOnCompletion(command string, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if notifyWebhook == "" {
+			return run(cmd, args)
+		}
+		format, err := notify.ParseFormat(notifyFormat)
+		if err != nil {
+			return err
+		}
+
+		startTime := time.Now()
+		runErr := run(cmd, args)
+
+		n := notify.Build(command, runSummary, runPRURLs, time.Since(startTime), runErr)
+		n.DryRun = dryRun
+		ctx, cancel := context.WithTimeout(context.Background(), notify.Timeout)
+		defer cancel()
+		if err := notify.NewWebhook(notifyWebhook, format, userAgent).Send(ctx, n); err != nil {
+			ux.PrintWarning("Failed to send the completion notification: %v", err)
+		}
+		return runErr
+	}
+}
+
+func printExecutionSummary(result *executor.Result, duration time.Duration, prov provider.Provider) {
+	ux.PrintHeader("Execution Summary")
+
+	rows := [][]string{
+		{"📊 Total phases:", ux.Success(fmt.Sprintf("%d", result.TotalPhases))},
 		{"✓ Completed phases:", ux.Success(fmt.Sprintf("%d", result.CompletedPhases))},
 		{"→ Executed phases:", ux.Info(fmt.Sprintf("%d", result.ExecutedPhases))},
 		{ux.Success("✓") + " Successful fixes:", ux.Success(fmt.Sprintf("%d", result.SuccessfulFixes))},
 		{ux.Error("✗") + " Failed fixes:", ux.Error(fmt.Sprintf("%d", result.FailedFixes))},
 		{"⏭️  Skipped fixes:", ux.Info(fmt.Sprintf("%d (already completed)", result.SkippedFixes))},
 		{"🔄 Duplicate fixes:", ux.Info(fmt.Sprintf("%d (skipped)", result.DuplicateFixes))},
+		{"⛔ Permanently failed:", ux.Info(fmt.Sprintf("%d (skipped, attempt limit reached)", result.PermanentlyFailedFixes))},
+		{"🚫 Ignored fixes:", ux.Info(fmt.Sprintf("%d (%s)", result.IgnoredFixes, fixer.IgnoreReason))},
 		{"💰 Total cost:", ux.FormatCost(result.TotalCost)},
 		{"🎫 Total tokens:", ux.FormatTokens(result.TotalTokens)},
 		{"⏱  Duration:", ux.FormatDuration(duration)},
@@ -1042,9 +2725,20 @@ func printExecutionSummary(result *executor.Result, duration time.Duration) {
 			fmt.Sprintf("%s (%s tokens)", ux.FormatCost(avgCost), ux.FormatTokens(avgTokens)),
 		})
 	}
+	if row := cacheStatsRow(prov); row != nil {
+		rows = append(rows, row)
+	}
+	rows = append(rows, providerSpendRows(prov)...)
 
 	ux.PrintSummaryTable(rows)
 
+	// Compare plan estimates with actual spend per phase
+	if len(result.PhaseCosts) > 0 {
+		fmt.Println()
+		ux.PrintSection("Cost: Estimated vs Actual")
+		printPhaseCostTable(result.PhaseCosts)
+	}
+
 	// Print confidence filtering stats if enabled
 	if result.ConfidenceStats != nil && result.ConfidenceStats.TotalFixes > 0 {
 		fmt.Println()
@@ -1105,48 +2799,459 @@ func printExecutionSummary(result *executor.Result, duration time.Duration) {
 		}
 	}
 
+	if result.PRCountPreview != nil {
+		printPRCountPreview(*result.PRCountPreview, true)
+	}
+
 	fmt.Println()
 	fmt.Printf("📊 State saved to: %s\n", result.StatePath)
 }
 
+// printPRCountPreview prints how many PRs each strategy would create.
+// Per-phase counts only apply when executing a plan (hasPhases).
+func printPRCountPreview(preview gitutil.PRCountPreview, hasPhases bool) {
+	fmt.Println()
+	ux.PrintSection("PR Count Preview")
+
+	perPhase := fmt.Sprintf("%d", preview.PerPhase)
+	if !hasPhases {
+		perPhase = "n/a (requires a plan)"
+	}
+
+	rows := [][]string{
+		{"per-violation:", ux.Info(fmt.Sprintf("%d", preview.PerViolation))},
+		{"per-incident:", ux.Info(fmt.Sprintf("%d", preview.PerIncident))},
+		{"per-phase:", ux.Info(perPhase)},
+		{"at-end:", ux.Info(fmt.Sprintf("%d", preview.AtEnd))},
+	}
+	ux.PrintSummaryTable(rows)
+}
+
+// loadProviderConfigFile loads --provider-config (if set) into providerFileConfig.
+// The file's provider name and model apply unless the corresponding flags were given.
+func loadProviderConfigFile(cmd *cobra.Command) error {
+	if providerConfigPath == "" {
+		return nil
+	}
+
+	fc, err := provider.LoadFileConfig(providerConfigPath)
+	if err != nil {
+		return err
+	}
+	providerFileConfig = fc
+
+	if !cmd.Flags().Changed("provider") && fc.Name != "" {
+		providerName = fc.Name
+	}
+	if !cmd.Flags().Changed("model") && fc.Model != "" {
+		model = fc.Model
+	}
+	return nil
+}
+
 func createProvider(name string, model string, cfg *config.Config) (provider.Provider, error) {
 	providerConfig := provider.Config{
-		Name:        name,
-		Model:       model,
-		Temperature: 0.2,
+		Name:      name,
+		Model:     model,
+		UserAgent: userAgent,
+	}
+
+	// Fill in anything not set by flags from --provider-config
+	providerFileConfig.Apply(&providerConfig)
+	if providerConfig.Temperature == 0 {
+		providerConfig.Temperature = 0.2
+	}
+
+	pricing, err := resolvePricing()
+	if err != nil {
+		return nil, err
+	}
+	providerConfig.Pricing = pricing
+
+	format, err := provider.ParseResponseFormat(responseFormat)
+	if err != nil {
+		return nil, err
+	}
+	providerConfig.ResponseFormat = format
+
+	// Rate-limit retry settings from config file
+	if providerConfig.MaxRetries == 0 {
+		providerConfig.MaxRetries = cfg.Provider.MaxRetries
+	}
+	if providerConfig.RetryBaseDelay == 0 && cfg.Provider.RetryBaseDelay != "" {
+		delay, err := time.ParseDuration(cfg.Provider.RetryBaseDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provider.retry-base-delay %q: %w", cfg.Provider.RetryBaseDelay, err)
+		}
+		providerConfig.RetryBaseDelay = delay
+	}
+	if len(providerConfig.ExtraFields) == 0 {
+		providerConfig.ExtraFields = cfg.Provider.ExtraFields
+	}
+	if providerConfig.MaxPromptTokens == 0 {
+		providerConfig.MaxPromptTokens = cfg.Provider.MaxPromptTokens
+	}
+
+	// Refuse providers and models the policy doesn't allow before any request
+	if err := cfg.Policy.ToPolicy().Check(name, providerConfig); err != nil {
+		return nil, err
+	}
+	if err := checkModel(name, providerConfig); err != nil {
+		return nil, err
 	}
 
-	// Load prompt templates if configured
-	if cfg.Prompts.SingleFixTemplate != "" || cfg.Prompts.BatchFixTemplate != "" || len(cfg.Prompts.LanguageTemplates) > 0 {
-		promptConfig := buildPromptConfig(name, cfg.Prompts)
-		templates, err := loadPromptTemplates(promptConfig)
+	if providerConfig.Templates, err = configuredTemplates(name, cfg); err != nil {
+		return nil, err
+	}
+
+	var prov provider.Provider
+	if name == provider.ReplayProviderName {
+		prov, err = provider.NewReplayProvider(replayFile, replayLenient)
+	} else {
+		prov, err = newProvider(name, &providerConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if providerFallback != "" {
+		if prov, err = createFallbackChain(prov, providerConfig, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Response cache
+	if cacheDir == "" {
+		cacheDir = cfg.Provider.CacheDir
+	}
+	if cacheKey == "" {
+		cacheKey = cfg.Provider.CacheKey
+	}
+	keyMode, err := provider.ParseCacheKeyMode(cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	if cacheTTL == 0 && cfg.Provider.CacheTTL != "" {
+		if cacheTTL, err = time.ParseDuration(cfg.Provider.CacheTTL); err != nil {
+			return nil, fmt.Errorf("invalid provider.cache-ttl %q: %w", cfg.Provider.CacheTTL, err)
+		}
+	}
+	if cacheTTL < 0 {
+		return nil, fmt.Errorf("--cache-ttl must not be negative")
+	}
+	if cacheDir != "" && !noCache {
+		cache, err := common.NewResponseCache(cacheDir)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+			return nil, err
 		}
-		providerConfig.Templates = templates
+		cache.SetTTL(cacheTTL)
+		prov = provider.NewCachingProvider(prov, cache, providerConfig, keyMode)
+	}
+
+	// Traces are redacted like the logs; recorded responses only in their
+	// explanations and errors, so the fixes replay unchanged
+	redactor, err := cfg.Logging.Redaction.ToRedactor()
+	if err != nil {
+		return nil, fmt.Errorf("invalid logging.redaction: %w", err)
 	}
 
+	// Record every response, including cache hits, for --provider replay
+	if dumpResponses != "" {
+		if prov, err = provider.NewRecordingProvider(prov, dumpResponses, redactor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Trace every request, including its retries and fallbacks
+	if traceDir != "" {
+		if prov, err = provider.NewTracingProvider(prov, traceDir, redactor); err != nil {
+			return nil, err
+		}
+	}
+	return prov, nil
+}
+
+// setupLogging routes diagnostics through a leveled logger on stderr, leaving
+// stdout to the progress output
+func setupLogging() error {
+	// The command itself reports a config file that fails to load
+	cfg := config.DefaultConfig()
+	if path := config.FindConfigFile(); path != "" {
+		if loaded, err := config.Load(path); err == nil {
+			cfg = loaded
+		}
+	}
+
+	if logLevel == "" {
+		logLevel = cfg.Logging.Level
+	}
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	var redactor *redact.Redactor
+	if logRedact {
+		if redactor, err = cfg.Logging.Redaction.ToRedactor(); err != nil {
+			return fmt.Errorf("invalid logging.redaction: %w", err)
+		}
+	}
+	slog.SetDefault(logging.New(os.Stderr, level, redactor))
+	return nil
+}
+
+// checkModel fails fast for a model the provider can't serve, and warns
+// about one it doesn't know
+func checkModel(name string, config provider.Config) error {
+	warning, err := provider.CheckModel(name, config)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		ux.PrintWarning("%s", warning)
+	}
+	return nil
+}
+
+// configuredTemplates loads the config file's and --prompt-dir's prompt
+// templates for a provider, or returns nil to use the defaults
+func configuredTemplates(name string, cfg *config.Config) (*prompt.Templates, error) {
+	prompts := cfg.Prompts
+	if promptDir != "" {
+		prompts.Dir = promptDir
+	}
+	if prompts.SingleFixTemplate == "" && prompts.BatchFixTemplate == "" && len(prompts.LanguageTemplates) == 0 && prompts.Dir == "" {
+		return nil, nil
+	}
+	templates, err := loadPromptTemplates(buildPromptConfig(name, prompts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+	return templates, nil
+}
+
+// createCandidateProvider creates the --compare-provider provider with the
+// same settings as --provider. Its responses can't be recorded alongside the
+// primary's, as a replay has only one provider to answer each request.
+func createCandidateProvider(cfg *config.Config) (provider.Provider, error) {
+	name, candidateModel, _ := strings.Cut(compareProvider, ":")
+	if name == provider.ReplayProviderName {
+		return nil, fmt.Errorf("the %s provider can't be compared", provider.ReplayProviderName)
+	}
+	if name == providerName && candidateModel == model {
+		return nil, fmt.Errorf("--compare-provider must differ from --provider")
+	}
+	if dumpResponses != "" {
+		return nil, fmt.Errorf("--compare-provider can't be combined with --dump-responses")
+	}
+	return createProvider(name, candidateModel, cfg)
+}
+
+// createFallbackChain wraps the primary provider with the --provider-fallback
+// providers. Fallbacks share the primary's generation and retry settings but
+// use their own endpoint, default model (unless given as provider:model) and
+// pricing, and must be allowed by the policy too.
+func createFallbackChain(primary provider.Provider, primaryConfig provider.Config, cfg *config.Config) (provider.Provider, error) {
+	specs, err := provider.ParseFallbackChain(providerFallback, primaryConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []provider.Provider{primary}
+	for _, spec := range specs {
+		fallbackConfig := provider.Config{
+			Name:            spec.Name,
+			Model:           spec.Model,
+			Temperature:     primaryConfig.Temperature,
+			ResponseFormat:  primaryConfig.ResponseFormat,
+			MaxRetries:      primaryConfig.MaxRetries,
+			RetryBaseDelay:  primaryConfig.RetryBaseDelay,
+			ExtraFields:     primaryConfig.ExtraFields,
+			MaxPromptTokens: primaryConfig.MaxPromptTokens,
+			UserAgent:       primaryConfig.UserAgent,
+		}
+		if err := cfg.Policy.ToPolicy().Check(spec.Name, fallbackConfig); err != nil {
+			return nil, fmt.Errorf("fallback provider %s: %w", spec.Name, err)
+		}
+		if err := checkModel(spec.Name, fallbackConfig); err != nil {
+			return nil, fmt.Errorf("fallback provider %s: %w", spec.Name, err)
+		}
+		if fallbackConfig.Templates, err = configuredTemplates(spec.Name, cfg); err != nil {
+			return nil, err
+		}
+		fallback, err := newProvider(spec.Name, &fallbackConfig)
+		if err != nil {
+			return nil, fmt.Errorf("fallback provider %s: %w", spec.Name, err)
+		}
+		chain = append(chain, fallback)
+	}
+	return provider.NewFallbackProvider(chain...)
+}
+
+// applyVerifyWarningConfig applies the config file's verification warning
+// settings for flags that weren't set
+func applyVerifyWarningConfig(cfg *config.Config) {
+	if len(verifyWarnCodes) == 0 {
+		verifyWarnCodes = cfg.Verification.WarningExitCodes
+	}
+	if verifyWarnPattern == "" {
+		verifyWarnPattern = cfg.Verification.WarningPattern
+	}
+}
+
+// applyCoverageConfig applies the config file's coverage verification
+// settings for flags that weren't set
+func applyCoverageConfig(cfg *config.Config) {
+	if coverageReport == "" {
+		coverageReport = cfg.Verification.CoverageReport
+	}
+	if coverageBaseline == "" {
+		coverageBaseline = cfg.Verification.CoverageBaseline
+	}
+	if coverageTolerance == 0 {
+		coverageTolerance = cfg.Verification.CoverageTolerance
+	}
+}
+
+// resolveVerifyScope parses the verification scope, applying the config
+// file's scope, affected command and offline setting for flags that weren't set
+func resolveVerifyScope(cfg *config.Config) (verifier.VerificationScope, error) {
+	if verifyScope == "all" && cfg.Verification.Scope != "" { // "all" is the flag default
+		verifyScope = cfg.Verification.Scope
+	}
+	if verifyAffectedCmd == "" {
+		verifyAffectedCmd = cfg.Verification.AffectedCommand
+	}
+	if !verifyOffline && cfg.Verification.Offline {
+		verifyOffline = cfg.Verification.Offline
+	}
+	return verifier.ParseVerificationScope(verifyScope)
+}
+
+// resolveLanguageCommands returns the per-language verification commands:
+// --verify-language-command entries override the config file's.
+func resolveLanguageCommands(cfg *config.Config) map[string]string {
+	if len(cfg.Verification.LanguageCommands) == 0 {
+		return verifyLangCommands
+	}
+	commands := make(map[string]string, len(cfg.Verification.LanguageCommands)+len(verifyLangCommands))
+	for language, command := range cfg.Verification.LanguageCommands {
+		commands[language] = command
+	}
+	for language, command := range verifyLangCommands {
+		commands[language] = command
+	}
+	return commands
+}
+
+// resolveCategoryStrategies merges git.category-strategies with
+// --category-commit-strategy, the flag taking precedence
+func resolveCategoryStrategies(cfg *config.Config) (map[string]gitutil.CommitStrategy, error) {
+	names := make(map[string]string, len(cfg.Git.CategoryStrategies)+len(categoryCommits))
+	for category, strategy := range cfg.Git.CategoryStrategies {
+		names[category] = strategy
+	}
+	for category, strategy := range categoryCommits {
+		names[category] = strategy
+	}
+	return gitutil.ParseCategoryStrategies(names)
+}
+
+// resolvePricing returns the token prices given with --input-price and
+// --output-price, or nil if neither was given
+func resolvePricing() (*provider.Pricing, error) {
+	inputSet := rootCmd.PersistentFlags().Changed("input-price")
+	outputSet := rootCmd.PersistentFlags().Changed("output-price")
+	if !inputSet && !outputSet {
+		return nil, nil
+	}
+	if inputSet != outputSet {
+		return nil, fmt.Errorf("--input-price and --output-price must be given together")
+	}
+	return provider.NewPricing(inputPrice, outputPrice)
+}
+
+// newProvider creates the provider for name. Presets fill in their base URL
+// and default model in providerConfig.
+func newProvider(name string, providerConfig *provider.Config) (provider.Provider, error) {
 	// Check if this is a provider preset (groq, ollama, etc.)
 	if preset, ok := provider.ProviderPresets[name]; ok {
-		// Use OpenAI provider with custom base URL
-		providerConfig.BaseURL = preset.BaseURL
+		// Use OpenAI provider with the preset's base URL unless one was configured
+		if providerConfig.BaseURL == "" {
+			providerConfig.BaseURL = preset.BaseURL
+		}
 
 		// Use preset's default model if no model specified
 		if providerConfig.Model == "" {
 			providerConfig.Model = preset.DefaultModel
 		}
 
-		return openai.New(providerConfig)
+		// Use the preset's pricing (free for local models) unless overridden
+		if providerConfig.Pricing == nil {
+			providerConfig.Pricing = preset.Pricing
+		}
+
+		return openai.New(*providerConfig)
 	}
 
 	switch name {
 	case "claude":
-		return claude.New(providerConfig)
+		return claude.New(*providerConfig)
 	case "openai":
-		return openai.New(providerConfig)
+		return openai.New(*providerConfig)
+	case "gemini":
+		return gemini.New(*providerConfig)
 	default:
-		return nil, fmt.Errorf("unknown provider: %s (available: claude, openai, groq, together, anyscale, perplexity, ollama, lmstudio, openrouter)", name)
+		return nil, fmt.Errorf("unknown provider: %s (available: %s)", name, strings.Join(provider.Names(), ", "))
+	}
+}
+
+// cacheStatsRow returns a summary row with the response cache hits, or nil
+// if the provider isn't cached
+func cacheStatsRow(prov provider.Provider) []string {
+	if tracing, ok := prov.(*provider.TracingProvider); ok {
+		prov = tracing.Provider
+	}
+	if recording, ok := prov.(*provider.RecordingProvider); ok {
+		prov = recording.Provider
 	}
+	cached, ok := prov.(*provider.CachingProvider)
+	if !ok {
+		return nil
+	}
+	hits, misses := cached.Stats()
+	return []string{"🗄  Cache hits:", fmt.Sprintf("%d of %d fixes", hits, hits+misses)}
+}
+
+// fallbackChain returns the provider's fallback chain, or nil if
+// --provider-fallback isn't set
+func fallbackChain(prov provider.Provider) *provider.FallbackProvider {
+	if tracing, ok := prov.(*provider.TracingProvider); ok {
+		prov = tracing.Provider
+	}
+	if recording, ok := prov.(*provider.RecordingProvider); ok {
+		prov = recording.Provider
+	}
+	if cached, ok := prov.(*provider.CachingProvider); ok {
+		prov = cached.Provider
+	}
+	chain, _ := prov.(*provider.FallbackProvider)
+	return chain
+}
+
+// providerSpendRows returns summary rows with the spend of each provider of a
+// fallback chain, including failed attempts, or nil without one
+func providerSpendRows(prov provider.Provider) [][]string {
+	chain := fallbackChain(prov)
+	if chain == nil {
+		return nil
+	}
+	names, costs := chain.Spend()
+	rows := [][]string{{"💰 Cost by provider:", ""}}
+	for _, name := range names {
+		rows = append(rows, []string{"   " + name + ":", ux.FormatCost(costs[name])})
+	}
+	return rows
 }
 
 // buildPromptConfig converts config.PromptsConfig to prompt.Config
@@ -1156,6 +3261,7 @@ func buildPromptConfig(providerName string, prompts config.PromptsConfig) prompt
 		SingleFixPath:     prompts.SingleFixTemplate,
 		BatchFixPath:      prompts.BatchFixTemplate,
 		LanguageTemplates: make(map[string]prompt.LanguagePaths),
+		Dir:               prompts.Dir,
 	}
 
 	// Convert language templates
@@ -1254,7 +3360,46 @@ func buildConfidenceConfig(cfg *config.Config) (confidence.Config, error) {
 		}
 	}
 
+	if flagChanged("review-dir") {
+		confidenceConf.ReviewDir = reviewDir
+	}
+
+	if flagChanged("category-confidence") {
+		thresholds, err := parseCategoryConfidence(categoryConfidence)
+		if err != nil {
+			return confidenceConf, err
+		}
+		if confidenceConf.CategoryThresholds == nil {
+			confidenceConf.CategoryThresholds = make(map[string]float64, len(thresholds))
+		}
+		for category, threshold := range thresholds {
+			confidenceConf.CategoryThresholds[category] = threshold
+		}
+	}
+
 	return confidenceConf, nil
 }
 
+// parseCategoryConfidence parses --category-confidence:
+// "mandatory=0.95,optional=0.8"
+func parseCategoryConfidence(value string) (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), "=")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid --category-confidence format: %s (expected: category=threshold)", pair)
+		}
+		category := strings.TrimSpace(parts[0])
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold value for %s: %s", category, parts[1])
+		}
+		if threshold < 0.0 || threshold > 1.0 {
+			return nil, fmt.Errorf("threshold for %s must be between 0.0 and 1.0", category)
+		}
+		thresholds[category] = threshold
+	}
+	return thresholds, nil
+}
+
 var rootCmd *cobra.Command
@@ -0,0 +1,86 @@
+// Package capabilities describes what this build of kantra-ai supports
+// (providers, commit and verification strategies, file format versions) in a
+// machine-readable form, so tooling can adapt to the installed version.
+package capabilities
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/config"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
+)
+
+// Capabilities is the output of 'kantra-ai capabilities --json'
+type Capabilities struct {
+	ConfigSchemaVersion    string     `json:"config_schema_version"`
+	ConfigKeys             []string   `json:"config_keys"`
+	PlanVersion            string     `json:"plan_version"`
+	StateVersion           string     `json:"state_version"`
+	Providers              []Provider `json:"providers"`
+	CommitStrategies       []string   `json:"commit_strategies"`
+	VerificationTypes      []string   `json:"verification_types"`
+	VerificationStrategies []string   `json:"verification_strategies"`
+	VerificationScopes     []string   `json:"verification_scopes"`
+}
+
+// Provider describes a value accepted by --provider
+type Provider struct {
+	Name         string `json:"name"`
+	Preset       bool   `json:"preset"` // OpenAI-compatible preset rather than a built-in API
+	BaseURL      string `json:"base_url,omitempty"`
+	DefaultModel string `json:"default_model,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Get returns the capabilities of this build
+func Get() Capabilities {
+	caps := Capabilities{
+		ConfigSchemaVersion:    config.SchemaVersion,
+		ConfigKeys:             configKeys(),
+		PlanVersion:            planfile.PlanVersion,
+		StateVersion:           planfile.StateVersion,
+		CommitStrategies:       gitutil.StrategyNames(),
+		VerificationTypes:      verifier.TypeNames(),
+		VerificationStrategies: verifier.StrategyNames(),
+		VerificationScopes:     verifier.ScopeNames(),
+	}
+
+	for _, name := range provider.Names() {
+		p := Provider{Name: name}
+		if preset, ok := provider.ProviderPresets[name]; ok {
+			p.Preset = true
+			p.BaseURL = preset.BaseURL
+			p.DefaultModel = preset.DefaultModel
+			p.Description = preset.Description
+		}
+		caps.Providers = append(caps.Providers, p)
+	}
+
+	return caps
+}
+
+// WriteJSON writes the capabilities as indented JSON
+func (c Capabilities) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// configKeys returns the top-level keys of the configuration file
+func configKeys() []string {
+	var sections []string
+	t := reflect.TypeOf(config.Config{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag != "" && tag != "-" {
+			sections = append(sections, tag)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,87 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/config"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
+)
+
+func TestGet(t *testing.T) {
+	caps := Get()
+
+	assert.Equal(t, config.SchemaVersion, caps.ConfigSchemaVersion)
+	assert.Equal(t, planfile.PlanVersion, caps.PlanVersion)
+	assert.Equal(t, planfile.StateVersion, caps.StateVersion)
+	assert.Contains(t, caps.ConfigKeys, "provider")
+	assert.Contains(t, caps.ConfigKeys, "git")
+	assert.Contains(t, caps.ConfigKeys, "verification")
+
+	t.Run("lists built-in providers, replay and every preset", func(t *testing.T) {
+		byName := make(map[string]Provider)
+		for _, p := range caps.Providers {
+			byName[p.Name] = p
+		}
+		assert.Len(t, caps.Providers, len(provider.BuiltinProviders)+1+len(provider.ProviderPresets))
+		require.Contains(t, byName, provider.ReplayProviderName)
+		assert.False(t, byName[provider.ReplayProviderName].Preset)
+
+		for _, name := range provider.BuiltinProviders {
+			require.Contains(t, byName, name)
+			assert.False(t, byName[name].Preset)
+		}
+		for name, preset := range provider.ProviderPresets {
+			require.Contains(t, byName, name)
+			assert.True(t, byName[name].Preset)
+			assert.Equal(t, preset.BaseURL, byName[name].BaseURL)
+			assert.Equal(t, preset.DefaultModel, byName[name].DefaultModel)
+		}
+	})
+
+	t.Run("strategies parse back to the real enums", func(t *testing.T) {
+		assert.Equal(t, []string{"per-violation", "per-incident", "at-end"}, caps.CommitStrategies)
+		for _, name := range caps.CommitStrategies {
+			strategy, err := gitutil.ParseStrategy(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, strategy.String())
+		}
+
+		assert.Equal(t, []string{"build", "test", "coverage"}, caps.VerificationTypes)
+		for _, name := range caps.VerificationTypes {
+			verifyType, err := verifier.ParseVerificationType(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, verifyType.String())
+		}
+
+		assert.Equal(t, []string{"per-fix", "per-violation", "at-end"}, caps.VerificationStrategies)
+		for _, name := range caps.VerificationStrategies {
+			strategy, err := verifier.ParseVerificationStrategy(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, strategy.String())
+		}
+
+		assert.Equal(t, []string{"all", "affected"}, caps.VerificationScopes)
+		for _, name := range caps.VerificationScopes {
+			scope, err := verifier.ParseVerificationScope(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, scope.String())
+		}
+	})
+
+	t.Run("json field names", func(t *testing.T) {
+		data, err := json.Marshal(caps)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		for _, key := range []string{"config_schema_version", "config_keys", "plan_version", "state_version", "providers", "commit_strategies", "verification_types", "verification_strategies", "verification_scopes"} {
+			assert.Contains(t, decoded, key)
+		}
+	})
+}
@@ -25,6 +25,8 @@
 //
 // Each complexity level has a configurable confidence threshold. The default
 // thresholds are tuned based on empirical success rates for AI-generated fixes.
+// Violation categories (mandatory, optional, potential) can additionally set a
+// confidence floor; a fix must meet the higher of the two.
 //
 // # Example Usage
 //
@@ -33,7 +35,7 @@
 //	config.OnLowConfidence = confidence.ActionSkip
 //
 //	// Check if a fix should be applied
-//	shouldApply, reason := config.ShouldApplyFix(0.75, "high", 8)
+//	shouldApply, reason := config.ShouldApplyFix(0.75, "mandatory", "high", 8)
 //	if !shouldApply {
 //	    fmt.Println("Skipped:", reason)
 //	}
@@ -79,11 +81,24 @@ type Config struct {
 	// Default threshold when no migration_complexity metadata
 	Default float64
 
+	// Minimum confidence per violation category (e.g. "mandatory": 0.95),
+	// applied on top of the complexity threshold. Categories without an
+	// entry only use the complexity threshold.
+	CategoryThresholds map[string]float64
+
 	// Use effort level as fallback for complexity
 	UseEffortFallback bool
 
 	// What to do with low-confidence fixes
 	OnLowConfidence Action
+
+	// Review file for manual-review-file, relative to the input directory
+	// (empty = .kantra-ai-review.yaml)
+	ReviewFile string
+
+	// Directory for the patches of manual-review-file fixes and their
+	// manifest, relative to the input directory (empty = .kantra-ai-review)
+	ReviewDir string
 }
 
 // DefaultConfig returns the default confidence configuration
@@ -146,8 +161,19 @@ func (c *Config) GetThreshold(complexity string) float64 {
 	return c.Default
 }
 
-// ShouldApplyFix determines whether a fix should be applied based on confidence
-func (c *Config) ShouldApplyFix(confidence float64, complexity string, effort int) (bool, string) {
+// ThresholdFor returns the confidence threshold for a violation: the higher
+// of its category's threshold, if one is configured, and the complexity's
+func (c *Config) ThresholdFor(category, complexity string) float64 {
+	threshold := c.GetThreshold(complexity)
+	if categoryThreshold, ok := c.CategoryThresholds[category]; ok && categoryThreshold > threshold {
+		return categoryThreshold
+	}
+	return threshold
+}
+
+// ShouldApplyFix determines whether a fix for a violation in category should
+// be applied based on confidence
+func (c *Config) ShouldApplyFix(confidence float64, category, complexity string, effort int) (bool, string) {
 	if !c.Enabled {
 		return true, "" // Confidence filtering disabled
 	}
@@ -166,7 +192,7 @@ func (c *Config) ShouldApplyFix(confidence float64, complexity string, effort in
 		effectiveComplexity = ComplexityMedium // Ultimate fallback
 	}
 
-	threshold := c.GetThreshold(effectiveComplexity)
+	threshold := c.ThresholdFor(category, effectiveComplexity)
 
 	if confidence >= threshold {
 		return true, ""
@@ -185,6 +211,10 @@ func (c *Config) ShouldApplyFix(confidence float64, complexity string, effort in
 
 	reason := fmt.Sprintf("confidence %.2f below threshold %.2f (complexity: %s, action: %s)",
 		confidence, threshold, effectiveComplexity, actionStr)
+	if threshold > c.GetThreshold(effectiveComplexity) {
+		reason = fmt.Sprintf("confidence %.2f below threshold %.2f (category: %s, complexity: %s, action: %s)",
+			confidence, threshold, category, effectiveComplexity, actionStr)
+	}
 
 	return false, reason
 }
@@ -144,7 +144,7 @@ func TestConfig_ShouldApplyFix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			shouldApply, reason := config.ShouldApplyFix(tt.confidence, tt.complexity, tt.effort)
+			shouldApply, reason := config.ShouldApplyFix(tt.confidence, "", tt.complexity, tt.effort)
 			assert.Equal(t, tt.shouldApply, shouldApply)
 			if tt.hasReason {
 				assert.NotEmpty(t, reason)
@@ -161,7 +161,7 @@ func TestConfig_ShouldApplyFix_Disabled(t *testing.T) {
 	config.Enabled = false
 
 	// When disabled, should always apply regardless of confidence
-	shouldApply, reason := config.ShouldApplyFix(0.10, ComplexityExpert, 10)
+	shouldApply, reason := config.ShouldApplyFix(0.10, "", ComplexityExpert, 10)
 	assert.True(t, shouldApply)
 	assert.Empty(t, reason)
 }
@@ -306,7 +306,7 @@ func TestStats_InvalidConfidence(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			shouldApply, reason := config.ShouldApplyFix(tt.confidence, ComplexityMedium, 5)
+			shouldApply, reason := config.ShouldApplyFix(tt.confidence, "", ComplexityMedium, 5)
 			if tt.shouldFail {
 				assert.False(t, shouldApply)
 				assert.Contains(t, reason, "invalid confidence")
@@ -333,7 +333,7 @@ func TestShouldApplyFix_ReasonIncludesAction(t *testing.T) {
 			config.OnLowConfidence = tt.action
 
 			// Use low confidence to trigger reason generation
-			shouldApply, reason := config.ShouldApplyFix(0.60, ComplexityMedium, 5)
+			shouldApply, reason := config.ShouldApplyFix(0.60, "", ComplexityMedium, 5)
 
 			assert.False(t, shouldApply)
 			assert.NotEmpty(t, reason)
@@ -348,19 +348,63 @@ func TestShouldApplyFix_EmptyComplexityNoFallback(t *testing.T) {
 	config.UseEffortFallback = false // Disable effort fallback
 
 	// With empty complexity and no fallback, should use default threshold
-	shouldApply, reason := config.ShouldApplyFix(0.82, "", 9)
+	shouldApply, reason := config.ShouldApplyFix(0.82, "", "", 9)
 
 	// Default is 0.80, so 0.82 should pass
 	assert.True(t, shouldApply)
 	assert.Empty(t, reason)
 
 	// Test below default threshold
-	shouldApply, reason = config.ShouldApplyFix(0.75, "", 9)
+	shouldApply, reason = config.ShouldApplyFix(0.75, "", "", 9)
 	assert.False(t, shouldApply)
 	assert.NotEmpty(t, reason)
 	assert.Contains(t, reason, "medium") // Should fall back to medium
 }
 
+func TestShouldApplyFix_CategoryThresholds(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = true
+	config.CategoryThresholds = map[string]float64{
+		"mandatory": 0.95,
+		"optional":  0.60,
+	}
+
+	tests := []struct {
+		name        string
+		confidence  float64
+		category    string
+		complexity  string
+		shouldApply bool
+	}{
+		{name: "mandatory floor above complexity threshold", confidence: 0.90, category: "mandatory", complexity: ComplexityTrivial, shouldApply: false},
+		{name: "mandatory floor met", confidence: 0.96, category: "mandatory", complexity: ComplexityTrivial, shouldApply: true},
+		{name: "complexity threshold above category floor", confidence: 0.70, category: "optional", complexity: ComplexityHigh, shouldApply: false},
+		{name: "both thresholds met", confidence: 0.91, category: "optional", complexity: ComplexityHigh, shouldApply: true},
+		{name: "category without a threshold", confidence: 0.76, category: "potential", complexity: ComplexityLow, shouldApply: true},
+		{name: "matching neither uses the default", confidence: 0.79, category: "potential", complexity: "custom", shouldApply: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shouldApply, reason := config.ShouldApplyFix(tt.confidence, tt.category, tt.complexity, 5)
+			assert.Equal(t, tt.shouldApply, shouldApply, reason)
+		})
+	}
+
+	assert.Equal(t, 0.95, config.ThresholdFor("mandatory", ComplexityLow))
+	assert.Equal(t, 0.90, config.ThresholdFor("optional", ComplexityHigh))
+	assert.Equal(t, config.Default, config.ThresholdFor("potential", "custom"))
+
+	t.Run("reason names the category when it set the threshold", func(t *testing.T) {
+		_, reason := config.ShouldApplyFix(0.90, "mandatory", ComplexityTrivial, 1)
+		assert.Contains(t, reason, "threshold 0.95")
+		assert.Contains(t, reason, "category: mandatory")
+
+		_, reason = config.ShouldApplyFix(0.70, "optional", ComplexityHigh, 8)
+		assert.NotContains(t, reason, "category")
+	})
+}
+
 func TestStats_ConcurrentAccess(t *testing.T) {
 	stats := NewStats()
 
@@ -15,6 +15,7 @@
 //   - Git Integration: Commit strategies and PR creation
 //   - Verification: Build/test commands to run after fixes
 //   - Cost Limits: Maximum spend controls
+//   - Policy: Approved providers and models
 //
 // # Configuration Loading
 //
@@ -37,6 +38,18 @@
 //	    high: 0.90
 //	    expert: 0.95
 //
+// # Extending a Base Config
+//
+// A config file can extend a shared base config with "extends: <path>"
+// (relative to the extending file): the base is loaded first and every
+// setting in the extending file overrides it. Bases can extend further
+// configs; cycles are an error.
+//
+// # Environment Variables
+//
+// String values can reference environment variables as ${NAME}; they are
+// expanded when the file is loaded, and an unset variable is an error.
+//
 // # Validation
 //
 // Configuration is validated during loading with clear error messages
@@ -45,15 +58,27 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/tsanders/kantra-ai/pkg/confidence"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/redact"
 	"gopkg.in/yaml.v3"
 )
 
+// SchemaVersion is the version of the configuration file format. It changes
+// when settings are renamed or removed, not when new settings are added.
+const SchemaVersion = "1"
+
 // Config represents the kantra-ai configuration
 type Config struct {
+	// Base config this file's settings override (relative to this file)
+	Extends string `yaml:"extends,omitempty"`
+
 	// Provider settings
 	Provider ProviderConfig `yaml:"provider"`
 
@@ -78,32 +103,68 @@ type Config struct {
 	// Prompt template settings
 	Prompts PromptsConfig `yaml:"prompts"`
 
+	// Logging settings (diagnostic log, audit log and dump files)
+	Logging LoggingConfig `yaml:"logging"`
+
+	// Restrictions on the providers and models that may be used
+	Policy PolicyConfig `yaml:"policy"`
+
 	// General settings
 	DryRun bool `yaml:"dry-run"`
 }
 
 // ProviderConfig holds AI provider settings
 type ProviderConfig struct {
-	Name  string `yaml:"name"`  // claude, openai
-	Model string `yaml:"model"` // optional, provider-specific model
+	Name           string `yaml:"name"`            // claude, openai
+	Model          string `yaml:"model"`           // optional, provider-specific model
+	ResponseFormat string `yaml:"response-format"` // full (default) or diff
+	MaxRetries     int    `yaml:"max-retries"`      // retries after a rate-limited request (0 = default, -1 = disabled)
+	RetryBaseDelay string `yaml:"retry-base-delay"` // delay before the first retry, e.g. "10s" (doubles each retry)
+	ExtraFields    []string `yaml:"extra-fields"`    // additional response fields passed through to FixResult.Extra
+	MaxPromptTokens int     `yaml:"max-prompt-tokens"` // prompt size limit checked before each request (0 = model's context window)
+	CacheDir        string  `yaml:"cache-dir"`         // directory for cached fixes (empty = no cache)
+	CacheKey        string  `yaml:"cache-key"`         // what identifies a cached fix: prompt (default) or content
+	CacheTTL        string  `yaml:"cache-ttl"`         // age after which cached fixes are asked for again, e.g. "168h" (empty = never)
+	TPMLimit        int     `yaml:"tpm-limit"`         // tokens-per-minute ceiling to pace requests under (0 = no limit)
+	PlanBatchSize   int     `yaml:"plan-batch-size"`   // violations per planning request (0 = provider default)
+	PlanBatchDelay  string  `yaml:"plan-batch-delay"`  // delay between planning batches, e.g. "5s" or "0s" (empty = provider default)
 }
 
 // PathsConfig holds input/output path settings
 type PathsConfig struct {
 	Analysis string `yaml:"analysis"` // Path to Konveyor output.yaml
 	Input    string `yaml:"input"`    // Path to source code directory
+
+	// Default locations of the files kantra-ai writes; flags override them
+	Plan    string `yaml:"plan"`    // Plan file written by plan, read by execute and report
+	State   string `yaml:"state"`   // Execution state file
+	Reports string `yaml:"reports"` // Directory for HTML reports
+}
+
+// ReportPath returns where to write the report named name: inside the
+// reports directory if one is configured, otherwise name itself
+func (p PathsConfig) ReportPath(name string) string {
+	if p.Reports == "" {
+		return name
+	}
+	return filepath.Join(p.Reports, name)
 }
 
 // LimitsConfig holds cost and effort limits
 type LimitsConfig struct {
 	MaxCost   float64 `yaml:"max-cost"`   // Maximum cost in USD
 	MaxEffort int     `yaml:"max-effort"` // Maximum effort level (0 = no limit)
+
+	OnBudgetExceeded string `yaml:"on-budget-exceeded"` // stop, pause-prompt, or defer-remaining
 }
 
 // FiltersConfig holds violation filtering options
 type FiltersConfig struct {
-	Categories   []string `yaml:"categories"`    // Filter by category (mandatory, optional, potential)
-	ViolationIDs []string `yaml:"violation-ids"` // Filter by specific violation IDs
+	Categories   []string `yaml:"categories"`     // Filter by category (mandatory, optional, potential)
+	ViolationIDs []string `yaml:"violation-ids"`  // Filter by specific violation IDs
+	IncludePaths []string `yaml:"include-paths"`  // Only fix incidents in files matching these globs (relative to input)
+	ExcludePaths []string `yaml:"exclude-paths"`  // Skip incidents in files matching these globs (relative to input)
+	MaxPathDepth int      `yaml:"max-path-depth"` // Skip incidents in files nested deeper than this below input (0 = no limit)
 }
 
 // GitConfig holds git integration settings
@@ -111,15 +172,41 @@ type GitConfig struct {
 	CommitStrategy string `yaml:"commit-strategy"` // per-violation, per-incident, at-end
 	CreatePR       bool   `yaml:"create-pr"`       // Automatically create pull requests
 	BranchPrefix   string `yaml:"branch-prefix"`   // Custom branch name prefix
+	BaseBranch     string `yaml:"base-branch"`     // Branch PRs target (empty = auto-detect the default branch)
+	MaxBranchLength int   `yaml:"max-branch-length"` // Maximum length of generated branch names (0 = 100)
+	PRDiffFormat   string `yaml:"pr-diff-format"`  // unified, side-by-side, none (empty = unified)
+	CommitTemplate string `yaml:"commit-template"` // Go text/template (inline or file path) for commit messages (empty = built-in format)
+
+	// Commit strategy per violation category (mandatory, optional, potential),
+	// overriding CommitStrategy for its fixes
+	CategoryStrategies map[string]string `yaml:"category-strategies"`
 }
 
 // VerificationConfig holds build/test verification settings
 type VerificationConfig struct {
 	Enabled  bool   `yaml:"enabled"`   // Enable verification
-	Type     string `yaml:"type"`      // build, test
+	Type     string `yaml:"type"`      // build, test, coverage
 	Strategy string `yaml:"strategy"`  // per-fix, per-violation, at-end
 	Command  string `yaml:"command"`   // Custom verification command
 	FailFast bool   `yaml:"fail-fast"` // Stop on first failure
+
+	// Command per language of the changed files (java, go, python, ...),
+	// run from the file's module; other files use Command or auto-detection
+	LanguageCommands map[string]string `yaml:"language-commands"`
+
+	WarningExitCodes []int  `yaml:"warning-exit-codes"` // Non-zero exit codes treated as success with a warning
+	WarningPattern   string `yaml:"warning-pattern"`    // Regex; failing output matching it is a warning, not a failure
+
+	Scope           string `yaml:"scope"`            // all (default) or affected: only tests for the changed files
+	AffectedCommand string `yaml:"affected-command"` // Template for the affected scope, e.g. "make test FILES={{.ChangedFiles}}"
+
+	Offline bool `yaml:"offline"` // Run Maven/Gradle offline, using only cached dependencies
+
+	SyntaxCheck bool `yaml:"syntax-check"` // Reject fixes whose content doesn't parse (go, json, xml, yaml)
+
+	CoverageReport    string  `yaml:"coverage-report"`    // Report written by coverage verification (empty = per project type)
+	CoverageBaseline  string  `yaml:"coverage-baseline"`  // Report to compare against (empty = measured before the first fix)
+	CoverageTolerance float64 `yaml:"coverage-tolerance"` // Coverage drop in percentage points allowed
 }
 
 // ConfidenceConfig holds confidence threshold settings
@@ -128,6 +215,8 @@ type ConfidenceConfig struct {
 	MinConfidence     float64            `yaml:"min-confidence"`      // Global minimum confidence (overrides complexity thresholds)
 	OnLowConfidence   string             `yaml:"on-low-confidence"`   // skip, warn-and-apply, manual-review-file
 	ComplexityThresholds map[string]float64 `yaml:"complexity-thresholds,omitempty"` // Override specific complexity thresholds
+	CategoryThresholds   map[string]float64 `yaml:"category-thresholds,omitempty"`   // Minimum confidence per violation category, on top of the complexity threshold
+	ReviewDir            string             `yaml:"review-dir"`                      // Directory for manual-review-file patches, relative to input (empty = .kantra-ai-review)
 }
 
 // PromptsConfig holds custom prompt template paths
@@ -135,6 +224,7 @@ type PromptsConfig struct {
 	SingleFixTemplate string `yaml:"single-fix-template"` // Path to custom single-fix prompt template (base/fallback)
 	BatchFixTemplate  string `yaml:"batch-fix-template"`  // Path to custom batch-fix prompt template (base/fallback)
 	LanguageTemplates map[string]LanguageTemplateConfig `yaml:"language-templates,omitempty"` // Language-specific template overrides
+	Dir               string `yaml:"dir"` // Directory of <template>.tmpl files overriding built-in templates by name (the paths above take precedence)
 }
 
 // LanguageTemplateConfig holds template paths for a specific language
@@ -143,6 +233,38 @@ type LanguageTemplateConfig struct {
 	BatchFix  string `yaml:"batch-fix"`  // Path to language-specific batch-fix template
 }
 
+// LoggingConfig holds settings for the diagnostic log and files that record
+// provider traffic
+type LoggingConfig struct {
+	Level     string          `yaml:"level"`     // debug, info, warn, error (default: info)
+	Redaction RedactionConfig `yaml:"redaction"` // Redaction applied before provider bodies are written
+}
+
+// RedactionConfig holds redaction settings for provider request/response bodies
+type RedactionConfig struct {
+	Patterns               []string `yaml:"patterns,omitempty"`       // Extra regex patterns to redact
+	HashCode               bool     `yaml:"hash-code"`                // Replace code bodies with a SHA-256 digest
+	DisableDefaultPatterns bool     `yaml:"disable-default-patterns"` // Skip built-in credential patterns
+}
+
+// PolicyConfig restricts the providers and models that may be used
+type PolicyConfig struct {
+	AllowedProviders []string `yaml:"allowed-providers"` // Provider names (empty = any)
+	AllowedModels    []string `yaml:"allowed-models"`    // Model names or glob patterns, e.g. "claude-sonnet-*" (empty = any)
+	MaxTemperature   *float64 `yaml:"max-temperature"`   // Highest sampling temperature (unset = no limit)
+	RequireOffline   bool     `yaml:"require-offline"`   // Only local providers (loopback base URL) or replay
+}
+
+// ToPolicy converts the policy settings to a provider.Policy
+func (c *PolicyConfig) ToPolicy() provider.Policy {
+	return provider.Policy{
+		AllowedProviders: c.AllowedProviders,
+		AllowedModels:    c.AllowedModels,
+		MaxTemperature:   c.MaxTemperature,
+		RequireOffline:   c.RequireOffline,
+	}
+}
+
 // DefaultConfig returns a config with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
@@ -168,23 +290,122 @@ func DefaultConfig() *Config {
 	}
 }
 
-// Load loads configuration from a YAML file
+// Load loads configuration from a YAML file, and the configs it extends
 func Load(path string) (*Config, error) {
+	return load(path, nil)
+}
+
+// load loads the config at path on top of the config it extends, if any;
+// chain holds the absolute paths of the configs extending it
+func load(path string, chain []string) (*Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file '%s': %w", path, err)
+	}
+	for i, extending := range chain {
+		if extending == absPath {
+			return nil, fmt.Errorf("config files extend each other in a cycle: %s -> %s",
+				strings.Join(chain[i:], " -> "), absPath)
+		}
+	}
+	chain = append(chain, absPath)
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file '%s': %w", path, err)
 	}
 
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, parseError(path, err)
+	}
+	if err := expandEnv(&root); err != nil {
+		return nil, fmt.Errorf("config file '%s': %w", path, err)
+	}
+	if root.Kind == 0 {
+		// Empty file
+		return DefaultConfig(), nil
+	}
+
+	// Find the base config before decoding this one over it
+	var header struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := root.Decode(&header); err != nil {
+		return nil, parseError(path, err)
+	}
+
 	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file '%s': %w\n\n"+
-			"Please check that the file is valid YAML and follows the expected format.\n"+
-			"See README.md for example configuration.", path, err)
+	if header.Extends != "" {
+		basePath := header.Extends
+		if !filepath.IsAbs(basePath) {
+			basePath = filepath.Join(filepath.Dir(absPath), basePath)
+		}
+		if config, err = load(basePath, chain); err != nil {
+			return nil, fmt.Errorf("config file '%s' extends '%s': %w", path, header.Extends, err)
+		}
+	}
+
+	if err := root.Decode(config); err != nil {
+		return nil, parseError(path, err)
 	}
 
 	return config, nil
 }
 
+// envReference matches ${NAME} references to environment variables, and the
+// $${ escape for a literal ${
+var envReference = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${NAME} in the string values under node with the
+// environment variable NAME, so secrets and machine-specific values don't
+// have to be committed. Referencing an unset variable is an error; $${NAME}
+// is kept as ${NAME}.
+func expandEnv(node *yaml.Node) error {
+	var unset []string
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.ShortTag() != "!!str" {
+				return
+			}
+			node.Value = envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+				if ref == "$${" {
+					return "${"
+				}
+				name := ref[2 : len(ref)-1]
+				value, ok := os.LookupEnv(name)
+				if !ok {
+					unset = append(unset, name)
+				}
+				return value
+			})
+		case yaml.MappingNode:
+			// Values only: keys are setting names
+			for i := 1; i < len(node.Content); i += 2 {
+				walk(node.Content[i])
+			}
+		default:
+			for _, child := range node.Content {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+
+	if len(unset) > 0 {
+		return fmt.Errorf("environment variable(s) referenced but not set: %s", strings.Join(unset, ", "))
+	}
+	return nil
+}
+
+func parseError(path string, err error) error {
+	return fmt.Errorf("failed to parse config file '%s': %w\n\n"+
+		"Please check that the file is valid YAML and follows the expected format.\n"+
+		"See README.md for example configuration.", path, err)
+}
+
 // FindConfigFile searches for a config file in common locations
 // Returns the path to the first config file found, or empty string if none found
 func FindConfigFile() string {
@@ -224,8 +445,7 @@ func LoadOrDefault() *Config {
 	config, err := Load(configPath)
 	if err != nil {
 		// Log the error but return defaults
-		fmt.Fprintf(os.Stderr, "Warning: Failed to load config from %s: %v\n", configPath, err)
-		fmt.Fprintf(os.Stderr, "Using default configuration.\n\n")
+		slog.Warn("failed to load config, using default configuration", "path", configPath, "error", err)
 		return DefaultConfig()
 	}
 
@@ -260,6 +480,14 @@ func (c *ConfidenceConfig) Validate() error {
 		}
 	}
 
+	// Validate category thresholds
+	for category, threshold := range c.CategoryThresholds {
+		if threshold < 0.0 || threshold > 1.0 {
+			return fmt.Errorf("threshold for category %s must be between 0.0 and 1.0, got %.2f",
+				category, threshold)
+		}
+	}
+
 	// Validate action
 	switch c.OnLowConfidence {
 	case "", "skip", "warn-and-apply", "manual-review-file":
@@ -301,6 +529,16 @@ func (c *ConfidenceConfig) ToConfidenceConfig() (confidence.Config, error) {
 		}
 	}
 
+	// Category floors apply on top of the complexity thresholds
+	if len(c.CategoryThresholds) > 0 {
+		conf.CategoryThresholds = make(map[string]float64, len(c.CategoryThresholds))
+		for category, threshold := range c.CategoryThresholds {
+			conf.CategoryThresholds[category] = threshold
+		}
+	}
+
+	conf.ReviewDir = c.ReviewDir
+
 	// Set action
 	switch c.OnLowConfidence {
 	case "skip", "":
@@ -313,3 +551,13 @@ func (c *ConfidenceConfig) ToConfidenceConfig() (confidence.Config, error) {
 
 	return conf, nil
 }
+
+// ToRedactor builds a redact.Redactor from the redaction settings
+// It returns an error if any pattern is not a valid regular expression
+func (c *RedactionConfig) ToRedactor() (*redact.Redactor, error) {
+	return redact.New(redact.Config{
+		Patterns:               c.Patterns,
+		HashCode:               c.HashCode,
+		DisableDefaultPatterns: c.DisableDefaultPatterns,
+	})
+}
@@ -36,6 +36,9 @@ provider:
 paths:
   analysis: ./analysis/output.yaml
   input: ./src
+  plan: .kantra-ai/plan.yaml
+  state: .kantra-ai/state.yaml
+  reports: .kantra-ai/reports
 
 limits:
   max-cost: 5.00
@@ -73,6 +76,9 @@ dry-run: true
 		assert.Equal(t, "gpt-4", config.Provider.Model)
 		assert.Equal(t, "./analysis/output.yaml", config.Paths.Analysis)
 		assert.Equal(t, "./src", config.Paths.Input)
+		assert.Equal(t, ".kantra-ai/plan.yaml", config.Paths.Plan)
+		assert.Equal(t, ".kantra-ai/state.yaml", config.Paths.State)
+		assert.Equal(t, ".kantra-ai/reports", config.Paths.Reports)
 		assert.Equal(t, 5.00, config.Limits.MaxCost)
 		assert.Equal(t, 3, config.Limits.MaxEffort)
 		assert.Equal(t, []string{"mandatory", "optional"}, config.Filters.Categories)
@@ -139,6 +145,109 @@ provider:
 	})
 }
 
+func TestLoad_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	write("shared/base.yaml", `
+provider:
+  name: openai
+  model: gpt-4o
+limits:
+  max-cost: 10
+git:
+  branch-prefix: migration/
+  category-strategies:
+    mandatory: per-violation
+    optional: at-end
+filters:
+  categories: [mandatory, optional]
+`)
+	write("shared/team.yaml", `
+extends: base.yaml
+limits:
+  max-cost: 20
+`)
+	servicePath := write("service/.kantra-ai.yaml", `
+extends: ../shared/team.yaml
+provider:
+  model: gpt-4o-mini
+git:
+  category-strategies:
+    optional: per-incident
+filters:
+  categories: [mandatory]
+`)
+
+	config, err := Load(servicePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "openai", config.Provider.Name, "inherited")
+	assert.Equal(t, "gpt-4o-mini", config.Provider.Model, "the extending file wins")
+	assert.Equal(t, 20.0, config.Limits.MaxCost, "from the middle of the chain")
+	assert.Equal(t, "migration/", config.Git.BranchPrefix)
+	assert.Equal(t, map[string]string{"mandatory": "per-violation", "optional": "per-incident"}, config.Git.CategoryStrategies, "maps are merged")
+	assert.Equal(t, []string{"mandatory"}, config.Filters.Categories, "lists are replaced")
+	assert.Equal(t, "test", config.Verification.Type, "defaults still apply")
+
+	t.Run("cycle", func(t *testing.T) {
+		a := write("cycle/a.yaml", "extends: b.yaml\n")
+		write("cycle/b.yaml", "extends: a.yaml\n")
+
+		_, err := Load(a)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+
+	t.Run("missing base", func(t *testing.T) {
+		_, err := Load(write("missing.yaml", "extends: nowhere.yaml\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "extends 'nowhere.yaml'")
+	})
+}
+
+func TestLoad_ExpandsEnv(t *testing.T) {
+	t.Setenv("KANTRA_TEST_PREFIX", "team-a/")
+	t.Setenv("KANTRA_TEST_MODULE", "services/orders")
+	configPath := filepath.Join(t.TempDir(), ".kantra-ai.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+git:
+  branch-prefix: ${KANTRA_TEST_PREFIX}migration
+verification:
+  command: mvn -pl ${KANTRA_TEST_MODULE} verify -Dbuild=$${BUILD_ID}
+  language-commands:
+    go: go test ./${KANTRA_TEST_MODULE}/...
+limits:
+  max-cost: 5
+`), 0644))
+
+	config, err := Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "team-a/migration", config.Git.BranchPrefix)
+	assert.Equal(t, "mvn -pl services/orders verify -Dbuild=${BUILD_ID}", config.Verification.Command, "$${ is a literal ${")
+	assert.Equal(t, "go test ./services/orders/...", config.Verification.LanguageCommands["go"])
+	assert.Equal(t, 5.0, config.Limits.MaxCost)
+
+	t.Run("unset variable", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".kantra-ai.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("git:\n  base-branch: ${KANTRA_TEST_UNSET_VAR}\n"), 0644))
+
+		_, err := Load(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "KANTRA_TEST_UNSET_VAR")
+	})
+}
+
+func TestPathsConfig_ReportPath(t *testing.T) {
+	assert.Equal(t, "results.html", PathsConfig{}.ReportPath("results.html"))
+	assert.Equal(t, filepath.Join(".kantra-ai", "reports", "results.html"), PathsConfig{Reports: ".kantra-ai/reports"}.ReportPath("results.html"))
+}
+
 func TestFindConfigFile(t *testing.T) {
 	t.Run("finds config in current directory", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -538,4 +647,77 @@ func TestConfidenceConfig_ToConfidenceConfig(t *testing.T) {
 		assert.Equal(t, 0.0, result.Thresholds["trivial"])
 		assert.Equal(t, 1.0, result.Thresholds["expert"])
 	})
+
+	t.Run("category thresholds", func(t *testing.T) {
+		config := ConfidenceConfig{
+			CategoryThresholds: map[string]float64{"mandatory": 0.95, "optional": 0.8},
+		}
+
+		result, err := config.ToConfidenceConfig()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{"mandatory": 0.95, "optional": 0.8}, result.CategoryThresholds)
+	})
+
+	t.Run("invalid category threshold", func(t *testing.T) {
+		config := ConfidenceConfig{
+			CategoryThresholds: map[string]float64{"mandatory": 1.5},
+		}
+
+		_, err := config.ToConfidenceConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "category mandatory")
+	})
+}
+
+func TestPolicyConfig_ToPolicy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".kantra-ai.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+policy:
+  allowed-providers: [claude, ollama]
+  allowed-models: ["claude-sonnet-*"]
+  max-temperature: 0
+  require-offline: true
+`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	policy := cfg.Policy.ToPolicy()
+	assert.Equal(t, []string{"claude", "ollama"}, policy.AllowedProviders)
+	assert.Equal(t, []string{"claude-sonnet-*"}, policy.AllowedModels)
+	require.NotNil(t, policy.MaxTemperature, "an explicit 0 is a limit")
+	assert.Equal(t, 0.0, *policy.MaxTemperature)
+	assert.True(t, policy.RequireOffline)
+
+	assert.True(t, DefaultConfig().Policy.ToPolicy().IsZero())
+}
+
+func TestRedactionConfig_ToRedactor(t *testing.T) {
+	t.Run("loads from config file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".kantra-ai.yaml")
+
+		configContent := `
+logging:
+  redaction:
+    hash-code: true
+    patterns:
+      - 'corp\.internal'
+`
+		require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+		config, err := Load(configPath)
+		require.NoError(t, err)
+		assert.True(t, config.Logging.Redaction.HashCode)
+		assert.Equal(t, []string{`corp\.internal`}, config.Logging.Redaction.Patterns)
+
+		redactor, err := config.Logging.Redaction.ToRedactor()
+		require.NoError(t, err)
+		assert.NotContains(t, redactor.Redact("host build.corp.internal"), "corp.internal")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		rc := RedactionConfig{Patterns: []string{"[unclosed"}}
+		_, err := rc.ToRedactor()
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// InitAnswers are the settings config init asks for
+type InitAnswers struct {
+	Provider          string
+	Model             string // Empty = the provider's default model
+	Analysis          string
+	Input             string
+	CommitStrategy    string // Empty = fixes aren't committed
+	ConfidenceEnabled bool
+	MinConfidence     float64 // 0 = thresholds by migration complexity
+	OnLowConfidence   string
+}
+
+// DefaultInitAnswers returns the answers config init --non-interactive writes
+func DefaultInitAnswers() InitAnswers {
+	defaults := DefaultConfig()
+	return InitAnswers{
+		Provider:        defaults.Provider.Name,
+		Analysis:        "output.yaml",
+		Input:           ".",
+		OnLowConfidence: defaults.Confidence.OnLowConfidence,
+	}
+}
+
+// lowConfidenceActions are the valid confidence.on-low-confidence values
+var lowConfidenceActions = []string{"skip", "warn-and-apply", "manual-review-file"}
+
+// noCommits is the commit strategy answer for not committing fixes
+const noCommits = "none"
+
+// InitPrompter asks for InitAnswers line by line, offering the current answer
+// as the default
+type InitPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewInitPrompter returns a prompter reading answers from in
+func NewInitPrompter(in io.Reader, out io.Writer) *InitPrompter {
+	return &InitPrompter{in: bufio.NewReader(in), out: out}
+}
+
+// Ask asks for every answer, starting from answers; commitStrategies are the
+// valid git commit strategies
+func (p *InitPrompter) Ask(answers InitAnswers, commitStrategies []string) (InitAnswers, error) {
+	var err error
+	if answers.Provider, err = p.choice("AI provider", answers.Provider, provider.Names()); err != nil {
+		return answers, err
+	}
+	if answers.Model, err = p.text("Model (empty = the provider's default)", answers.Model); err != nil {
+		return answers, err
+	}
+	if answers.Analysis, err = p.text("Konveyor analysis file", answers.Analysis); err != nil {
+		return answers, err
+	}
+	if answers.Input, err = p.text("Source code directory", answers.Input); err != nil {
+		return answers, err
+	}
+	strategy := answers.CommitStrategy
+	if strategy == "" {
+		strategy = noCommits
+	}
+	if strategy, err = p.choice("Git commit strategy", strategy, append([]string{noCommits}, commitStrategies...)); err != nil {
+		return answers, err
+	}
+	answers.CommitStrategy = strategy
+	if strategy == noCommits {
+		answers.CommitStrategy = ""
+	}
+	if answers.ConfidenceEnabled, err = p.confirm("Skip fixes the AI isn't confident about", answers.ConfidenceEnabled); err != nil {
+		return answers, err
+	}
+	if !answers.ConfidenceEnabled {
+		return answers, nil
+	}
+	if answers.MinConfidence, err = p.fraction("Minimum confidence (0 = thresholds by migration complexity)", answers.MinConfidence); err != nil {
+		return answers, err
+	}
+	answers.OnLowConfidence, err = p.choice("On low confidence", answers.OnLowConfidence, lowConfidenceActions)
+	return answers, err
+}
+
+// text asks question and returns the answer, or def for an empty answer
+func (p *InitPrompter) text(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New("input ended before every question was answered")
+		}
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choice asks question until the answer is one of options
+func (p *InitPrompter) choice(question, def string, options []string) (string, error) {
+	for {
+		answer, err := p.text(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		for _, option := range options {
+			if answer == option {
+				return answer, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  %q is not one of: %s\n", answer, strings.Join(options, ", "))
+	}
+}
+
+// confirm asks a yes/no question until answered with y or n
+func (p *InitPrompter) confirm(question string, def bool) (bool, error) {
+	defAnswer := "n"
+	if def {
+		defAnswer = "y"
+	}
+	for {
+		answer, err := p.text(question+" (y/n)", defAnswer)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "  Please answer y or n")
+	}
+}
+
+// fraction asks question until the answer is a number from 0 to 1
+func (p *InitPrompter) fraction(question string, def float64) (float64, error) {
+	for {
+		answer, err := p.text(question, strconv.FormatFloat(def, 'f', -1, 64))
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.ParseFloat(answer, 64)
+		if err == nil && value >= 0 && value <= 1 {
+			return value, nil
+		}
+		fmt.Fprintln(p.out, "  Please enter a number from 0 to 1, e.g. 0.8")
+	}
+}
+
+// initTemplate is the commented config file written by config init
+var initTemplate = template.Must(template.New("init").Parse(`# kantra-ai configuration, written by 'kantra-ai config init'
+# CLI flags override these settings. See .kantra-ai.example.yaml in the
+# kantra-ai repository for every setting.
+# String values can reference environment variables as ${NAME}.
+
+# AI provider (API keys are read from the environment, e.g. ANTHROPIC_API_KEY)
+provider:
+  name: {{.Provider}}
+  model: {{printf "%q" .Model}}  # empty = the provider's default model
+
+# Default --analysis and --input
+paths:
+  analysis: {{printf "%q" .Analysis}}
+  input: {{printf "%q" .Input}}
+
+# Git integration
+git:
+{{- if .CommitStrategy}}
+  commit-strategy: {{.CommitStrategy}}  # per-violation, per-incident or at-end
+{{- else}}
+  # commit-strategy: per-violation  # commit fixes: per-violation, per-incident or at-end
+{{- end}}
+  create-pr: false  # open GitHub pull requests (needs GITHUB_TOKEN)
+
+# Confidence filtering: fixes the AI is less confident about than the
+# threshold for their migration complexity are handled by on-low-confidence
+confidence:
+  enabled: {{.ConfidenceEnabled}}
+  min-confidence: {{.MinConfidence}}  # one threshold for every fix (0 = thresholds by complexity)
+  on-low-confidence: {{.OnLowConfidence}}  # skip, warn-and-apply or manual-review-file
+`))
+
+// RenderInitConfig returns the commented config file for answers, checked
+// to load as a valid config
+func RenderInitConfig(answers InitAnswers) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := initTemplate.Execute(&buf, answers); err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	if err := yaml.Unmarshal(buf.Bytes(), config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.Confidence.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteInitConfig writes the config file for answers to path, unless a file
+// already exists there and force isn't set
+func WriteInitConfig(path string, answers InitAnswers, force bool) error {
+	if fileExists(path) && !force {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	}
+	data, err := RenderInitConfig(answers)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
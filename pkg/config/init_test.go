@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCommitStrategies = []string{"per-violation", "per-incident", "at-end"}
+
+func TestInitPrompter_Ask(t *testing.T) {
+	// Invalid answers are asked again; empty answers keep the default
+	input := strings.Join([]string{"groq", "", "analysis/output.yaml", "", "weekly", "at-end", "maybe", "y", "2", "0.85", "manual-review-file"}, "\n") + "\n"
+	var out bytes.Buffer
+
+	answers, err := NewInitPrompter(strings.NewReader(input), &out).Ask(DefaultInitAnswers(), testCommitStrategies)
+	require.NoError(t, err)
+
+	assert.Equal(t, InitAnswers{
+		Provider:          "groq",
+		Analysis:          "analysis/output.yaml",
+		Input:             ".",
+		CommitStrategy:    "at-end",
+		ConfidenceEnabled: true,
+		MinConfidence:     0.85,
+		OnLowConfidence:   "manual-review-file",
+	}, answers)
+	assert.Contains(t, out.String(), `"weekly" is not one of`)
+	assert.Contains(t, out.String(), "Please answer y or n")
+	assert.Contains(t, out.String(), "Please enter a number from 0 to 1")
+}
+
+func TestInitPrompter_Ask_InputEnds(t *testing.T) {
+	_, err := NewInitPrompter(strings.NewReader("openai\n"), &bytes.Buffer{}).Ask(DefaultInitAnswers(), testCommitStrategies)
+	assert.ErrorContains(t, err, "input ended")
+}
+
+func TestWriteInitConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kantra-ai.yaml")
+	answers := DefaultInitAnswers()
+	answers.Provider = "openai"
+	answers.Model = "gpt-4o"
+	answers.Input = "./src"
+	answers.CommitStrategy = "per-incident"
+	answers.ConfidenceEnabled = true
+
+	require.NoError(t, WriteInitConfig(path, answers, false))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "openai", config.Provider.Name)
+	assert.Equal(t, "gpt-4o", config.Provider.Model)
+	assert.Equal(t, "./src", config.Paths.Input)
+	assert.Equal(t, "per-incident", config.Git.CommitStrategy)
+	assert.True(t, config.Confidence.Enabled)
+	assert.Equal(t, "skip", config.Confidence.OnLowConfidence)
+
+	err = WriteInitConfig(path, DefaultInitAnswers(), false)
+	assert.ErrorContains(t, err, "--force")
+
+	require.NoError(t, WriteInitConfig(path, DefaultInitAnswers(), true))
+	config, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "claude", config.Provider.Name)
+	assert.Empty(t, config.Git.CommitStrategy, "fixes aren't committed by default")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# commit-strategy:")
+}
+
+func TestRenderInitConfig_Invalid(t *testing.T) {
+	answers := DefaultInitAnswers()
+	answers.OnLowConfidence = "ignore"
+	_, err := RenderInitConfig(answers)
+	assert.Error(t, err)
+}
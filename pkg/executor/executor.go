@@ -2,13 +2,16 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/tsanders/kantra-ai/pkg/confidence"
 	"github.com/tsanders/kantra-ai/pkg/fixer"
 	"github.com/tsanders/kantra-ai/pkg/gitutil"
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -17,8 +20,17 @@ type Executor struct {
 	config Config
 	plan   *planfile.Plan
 	state  *planfile.ExecutionState
+
+	// fixes records every attempted fix (successful or not) for Result.Fixes
+	fixes []gitutil.FixRecord
+
+	// reconcile is set when the plan changed since the state was written (see Config.Force)
+	reconcile bool
 }
 
+// DefaultStatePath is where execution state is written unless Config.StatePath is set
+const DefaultStatePath = ".kantra-ai-state.yaml"
+
 // New creates a new Executor with the given configuration.
 // It sets default values for PlanPath, StatePath, Progress, and BatchConfig if not provided.
 func New(config Config) (*Executor, error) {
@@ -27,7 +39,7 @@ func New(config Config) (*Executor, error) {
 		config.PlanPath = ".kantra-ai-plan.yaml"
 	}
 	if config.StatePath == "" {
-		config.StatePath = ".kantra-ai-state.yaml"
+		config.StatePath = DefaultStatePath
 	}
 	if config.Progress == nil {
 		config.Progress = &ux.NoOpProgressWriter{}
@@ -60,10 +72,30 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
+	planHash, err := plan.Hash()
+	if err != nil {
+		return nil, err
+	}
+
 	if state == nil {
 		// Create new state
 		state = planfile.NewState(e.config.PlanPath, len(plan.Phases))
+	} else if state.PlanHash != "" && state.PlanHash != planHash {
+		// The plan was edited since the state was written. Phase and violation
+		// statuses may no longer describe the plan, so only reconcile on request.
+		if !e.config.Force {
+			return nil, fmt.Errorf("plan '%s' has changed since state file '%s' was written\n\n"+
+				"Either:\n"+
+				"  1. Re-run with --force to reconcile: incidents already fixed are skipped\n"+
+				"     and new or changed plan items are executed\n"+
+				"  2. Or delete the state file to execute the plan from scratch",
+				e.config.PlanPath, e.config.StatePath)
+		}
+		e.reconcile = true
+		state.ExecutionSummary.TotalPhases = len(plan.Phases)
+		e.config.Progress.Info("Plan changed since last run - reconciling with existing state")
 	}
+	state.PlanHash = planHash
 	e.state = state
 
 	// Check for resume
@@ -79,10 +111,15 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 	if len(phasesToExecute) == 0 {
 		return nil, fmt.Errorf("no phases to execute")
 	}
+	phasesToExecute, remainingPhases := e.limitPhases(phasesToExecute)
 
 	result := &Result{
-		TotalPhases: len(plan.Phases),
-		StatePath:   e.config.StatePath,
+		TotalPhases:     len(plan.Phases),
+		StatePath:       e.config.StatePath,
+		RemainingPhases: remainingPhases,
+	}
+	if len(remainingPhases) > 0 {
+		e.config.Progress.Info("Executing %d phase(s) this run; %d left for a later run", e.config.MaxPhases, len(remainingPhases))
 	}
 
 	// Initialize confidence stats if enabled
@@ -90,6 +127,14 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		result.ConfidenceStats = confidence.NewStats()
 	}
 
+	// Dry-run skips verification the same way no matter how the tracker was configured
+	if e.config.VerifiedTracker != nil {
+		e.config.VerifiedTracker.SetDryRun(e.config.DryRun)
+		if reason := e.config.VerifiedTracker.SkipReason(); reason != "" && e.config.DryRun {
+			e.config.Progress.Info("Verification skipped: %s", reason)
+		}
+	}
+
 	// Execute phases
 	for _, phase := range phasesToExecute {
 		// Check for context cancellation
@@ -100,6 +145,8 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		}
 
 		phaseResult := e.executePhase(ctx, &phase)
+		result.PhaseCosts = append(result.PhaseCosts, e.phaseCost(&phase, phaseResult))
+		result.Fixes = e.fixes
 
 		result.ExecutedPhases++
 		result.TotalFixes += phaseResult.SuccessfulFixes + phaseResult.FailedFixes
@@ -107,6 +154,8 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		result.FailedFixes += phaseResult.FailedFixes
 		result.SkippedFixes += phaseResult.SkippedFixes
 		result.DuplicateFixes += phaseResult.DuplicateFixes
+		result.PermanentlyFailedFixes += phaseResult.PermanentlyFailedFixes
+		result.IgnoredFixes += phaseResult.IgnoredFixes
 		result.TotalCost += phaseResult.Cost
 		result.TotalTokens += phaseResult.Tokens
 
@@ -167,6 +216,9 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		if err := e.config.CommitTracker.Finalize(); err != nil {
 			e.config.Progress.Error("Failed to finalize commits: %v", err)
 		}
+	} else if e.config.VerifiedTracker != nil && e.config.DryRun && e.config.VerifiedTracker.SkipReason() == "" {
+		// Verification was explicitly requested for this dry-run
+		e.verifyDryRun()
 	}
 
 	// Finalize PR creation if enabled
@@ -183,6 +235,20 @@ func (e *Executor) Execute(ctx context.Context) (*Result, error) {
 		result.Commits = e.config.CommitTracker.GetCommits()
 	}
 
+	// Record what was changed, committed and verified so a results report
+	// can be built from the state file later
+	if !e.config.DryRun && e.recordChanges(result.Commits) {
+		if err := planfile.SaveState(e.state, e.config.StatePath); err != nil {
+			e.config.Progress.Error("Failed to save state: %v", err)
+		}
+	}
+
+	// Estimate PR counts per strategy if requested
+	if e.config.PRCountPreview {
+		preview := gitutil.EstimatePRCounts(gitutil.SuccessfulFixes(e.fixes))
+		result.PRCountPreview = &preview
+	}
+
 	// Collect PR information from tracker
 	if e.config.PRTracker != nil {
 		createdPRs := e.config.PRTracker.GetCreatedPRs()
@@ -221,8 +287,8 @@ func (e *Executor) getPhasesToExecute() []planfile.Phase {
 			continue
 		}
 
-		// Skip already completed phases (unless resuming)
-		if !e.config.Resume {
+		// Skip already completed phases (unless resuming or reconciling)
+		if !e.revisitCompleted() {
 			phaseStatus := e.state.GetPhaseStatus(phase.ID)
 			if phaseStatus != nil && phaseStatus.Status == planfile.StatusCompleted {
 				continue
@@ -235,6 +301,141 @@ func (e *Executor) getPhasesToExecute() []planfile.Phase {
 	return phases
 }
 
+// limitPhases applies Config.MaxPhases: it keeps phases until MaxPhases
+// phases that aren't completed yet are included, and returns the IDs of
+// the pending phases left for a later run. Completed phases revisited on
+// resume don't count towards the limit.
+func (e *Executor) limitPhases(phases []planfile.Phase) ([]planfile.Phase, []string) {
+	if e.config.MaxPhases <= 0 {
+		return phases, nil
+	}
+
+	var selected []planfile.Phase
+	var remaining []string
+	pending := 0
+	for _, phase := range phases {
+		if e.phaseCompleted(phase.ID) {
+			if pending < e.config.MaxPhases {
+				selected = append(selected, phase)
+			}
+			continue
+		}
+		if pending < e.config.MaxPhases {
+			selected = append(selected, phase)
+		} else {
+			remaining = append(remaining, phase.ID)
+		}
+		pending++
+	}
+	return selected, remaining
+}
+
+// phaseCompleted reports whether the state records phaseID as completed
+func (e *Executor) phaseCompleted(phaseID string) bool {
+	phaseStatus := e.state.GetPhaseStatus(phaseID)
+	return phaseStatus != nil && phaseStatus.Status == planfile.StatusCompleted
+}
+
+// revisitCompleted reports whether completed phases and violations are visited
+// again (skipping completed incidents) instead of being skipped as a whole
+func (e *Executor) revisitCompleted() bool {
+	return e.config.Resume || e.reconcile
+}
+
+// recordFix records an attempted fix for Result.Fixes. File contents are
+// dropped so a long run doesn't hold a copy of every fixed file in memory.
+func (e *Executor) recordFix(v violation.Violation, incident violation.Incident, fixResult fixer.FixResult, phaseID string) {
+	fixResult.OriginalContent = ""
+	fixResult.FixedContent = ""
+	e.fixes = append(e.fixes, gitutil.FixRecord{
+		Violation: v,
+		Incident:  incident,
+		Result:    fixResult,
+		Timestamp: time.Now(),
+		PhaseID:   phaseID,
+	})
+}
+
+// recordChanges records in the state the files changed, the commits created
+// and the verification outcome for each violation fixed in this run. Commits
+// without a violation ID (at-end) contain every violation's fixes. Returns
+// true if anything was recorded.
+func (e *Executor) recordChanges(commits []gitutil.CommitInfo) bool {
+	filesByViolation := make(map[string][]string)
+	var order []string
+	for _, fix := range gitutil.SuccessfulFixes(e.fixes) {
+		if fix.Result.SkippedLowConfidence || fix.Result.FilePath == "" {
+			continue
+		}
+		id := fix.Violation.ID
+		if _, ok := filesByViolation[id]; !ok {
+			order = append(order, id)
+		}
+		filesByViolation[id] = append(filesByViolation[id], fix.Result.FilePath)
+	}
+	if len(order) == 0 {
+		return false
+	}
+
+	for _, id := range order {
+		var shas []string
+		for _, commit := range commits {
+			if commit.ViolationID == "" || commit.ViolationID == id {
+				shas = append(shas, commit.SHA)
+			}
+		}
+		e.state.RecordViolationChanges(id, filesByViolation[id], shas)
+	}
+
+	if e.config.VerifiedTracker != nil {
+		for id, passed := range e.config.VerifiedTracker.VerificationResults() {
+			e.state.RecordVerification(id, passed)
+		}
+	}
+
+	return true
+}
+
+// phaseCost builds the estimated vs actual cost comparison for a phase.
+// Actuals come from the state file when the phase completed, so they include
+// earlier runs; otherwise only this run's usage is known.
+func (e *Executor) phaseCost(phase *planfile.Phase, phaseResult PhaseResult) PhaseCost {
+	cost := PhaseCost{
+		PhaseID:       phase.ID,
+		PhaseName:     phase.Name,
+		EstimatedCost: phase.EstimatedCost,
+		ActualCost:    phaseResult.Cost,
+		ActualTokens:  phaseResult.Tokens,
+	}
+
+	if phaseResult.Error == nil {
+		if status := e.state.GetPhaseStatus(phase.ID); status != nil {
+			cost.ActualCost = status.Cost
+			cost.ActualTokens = status.Tokens
+		}
+	}
+
+	return cost
+}
+
+// verifyDryRun runs the verification forced for a dry-run. Nothing is
+// committed or reverted; the outcome is only reported.
+func (e *Executor) verifyDryRun() {
+	e.config.Progress.Info("Running verification on dry-run (working tree is unchanged)")
+	result, err := e.config.VerifiedTracker.VerifyDryRun()
+	if err != nil {
+		e.config.Progress.Error("Verification failed to run: %v", err)
+		return
+	}
+	if result.Warning {
+		e.config.Progress.Info("Verification passed with warnings (%s): %s", result.Command, result.WarningReason)
+	} else if result.Success {
+		e.config.Progress.Info("Verification passed (%s)", result.Command)
+	} else {
+		e.config.Progress.Error("Verification failed (%s): %v", result.Command, result.Error)
+	}
+}
+
 // executePhase executes a single phase by processing violations using batch processing
 // when enabled. It tracks successes and failures in the state file and returns detailed
 // metrics for the phase.
@@ -272,13 +473,14 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 		select {
 		case <-ctx.Done():
 			result.Error = ctx.Err()
+			e.recordPhaseActuals(phase, result)
 			return result
 		default:
 		}
 
 		// Check if we should skip this violation (already completed)
 		violationStatus, exists := e.state.Violations[plannedViolation.ViolationID]
-		if exists && violationStatus.Status == planfile.StatusCompleted && !e.config.Resume {
+		if exists && violationStatus.Status == planfile.StatusCompleted && !e.revisitCompleted() {
 			continue
 		}
 
@@ -286,16 +488,17 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 		incidentsToFix := make([]violation.Incident, 0, len(plannedViolation.Incidents))
 		skippedCount := 0
 		duplicateCount := 0
+		permanentlyFailedCount := 0
 		for _, incident := range plannedViolation.Incidents {
-			incidentURI := incident.URI
-
 			// Skip if already completed
-			if exists {
-				if incidentStatus, ok := violationStatus.Incidents[incidentURI]; ok {
-					if incidentStatus.Status == planfile.StatusCompleted {
-						skippedCount++
-						continue
-					}
+			if incidentStatus, ok := e.state.GetIncidentStatus(plannedViolation.ViolationID, incident); ok {
+				if incidentStatus.Status == planfile.StatusCompleted {
+					skippedCount++
+					continue
+				}
+				if e.attemptsExhausted(incidentStatus) {
+					permanentlyFailedCount++
+					continue
 				}
 			}
 
@@ -313,6 +516,11 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 		// Track skipped incidents
 		result.SkippedFixes += skippedCount
 		result.DuplicateFixes += duplicateCount
+		result.PermanentlyFailedFixes += permanentlyFailedCount
+		if permanentlyFailedCount > 0 {
+			e.config.Progress.Info("   ⛔ Skipped %d incident(s) for %s that failed %d times (permanently failed)",
+				permanentlyFailedCount, plannedViolation.ViolationID, e.config.MaxIncidentAttempts)
+		}
 
 		if len(incidentsToFix) == 0 {
 			// All incidents already fixed or duplicates - skip this violation
@@ -344,15 +552,33 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 			// If entire batch failed, mark all incidents as failed
 			for _, incident := range incidentsToFix {
 				result.FailedFixes++
-				e.state.RecordIncidentFailure(phase.ID, plannedViolation.ViolationID, incident.URI, err.Error())
+				e.recordFailure(phase.ID, plannedViolation.ViolationID, planfile.IncidentKey(incident), err.Error())
+				e.recordFix(v, incident, fixer.FixResult{ViolationID: v.ID, IncidentURI: incident.URI, Error: err}, phase.ID)
 			}
 			continue
 		}
 
-		// Process individual fix results
-		for i, fixResult := range fixResults {
-			incident := incidentsToFix[i]
-			incidentURI := incident.URI
+		// Process individual fix results. They aren't in incident order
+		// (batches finish in any order and second-pass or reused fixes come
+		// last), so each is matched to its incident by URI and line.
+		incidentsByKey := make(map[string]violation.Incident, len(incidentsToFix))
+		for _, incident := range incidentsToFix {
+			incidentsByKey[planfile.IncidentKey(incident)] = incident
+		}
+		for _, fixResult := range fixResults {
+			incident, ok := incidentsByKey[planfile.IncidentKey(violation.Incident{URI: fixResult.IncidentURI, LineNumber: fixResult.LineNumber})]
+			if !ok {
+				e.config.Progress.Error("Fix result for unknown incident %s:%d of %s ignored", fixResult.IncidentURI, fixResult.LineNumber, v.ID)
+				continue
+			}
+			incidentKey := planfile.IncidentKey(incident)
+
+			// Files in .kantra-ai-ignore are skipped, not failed; they stay pending
+			if fixResult.SkippedIgnored {
+				result.IgnoredFixes++
+				e.recordFix(v, incident, fixResult, phase.ID)
+				continue
+			}
 
 			// Track confidence filtering stats
 			if confidenceStats != nil {
@@ -360,29 +586,41 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 				confidenceStats.RecordFix(v.MigrationComplexity, applied)
 			}
 
+			if fixResult.Success {
+				e.assertFix(ctx, v, incident, &fixResult)
+			}
+
 			if !fixResult.Success {
 				result.FailedFixes++
 				errorMsg := ""
 				if fixResult.Error != nil {
 					errorMsg = fixResult.Error.Error()
 				}
-				e.state.RecordIncidentFailure(phase.ID, plannedViolation.ViolationID, incidentURI, errorMsg)
+				e.recordFailure(phase.ID, plannedViolation.ViolationID, incidentKey, errorMsg)
+				e.recordFix(v, incident, fixResult, phase.ID)
 				continue
 			}
 
-			// Record successful fix
-			result.SuccessfulFixes++
-			result.Cost += fixResult.Cost
-			result.Tokens += fixResult.TokensUsed
-
-			e.state.RecordIncidentFix(plannedViolation.ViolationID, incidentURI, fixResult.Cost)
-
 			// Create a copy to avoid pointer aliasing bug (all pointers would point to same loop variable)
 			fixResultCopy := fixResult
 
 			// Track for git commit if enabled
 			if e.config.VerifiedTracker != nil && !e.config.DryRun {
-				if err := e.config.VerifiedTracker.TrackFix(v, incident, &fixResultCopy); err != nil {
+				err := e.config.VerifiedTracker.TrackFix(v, incident, &fixResultCopy)
+				if errors.Is(err, gitutil.ErrFixReverted) {
+					// The fix broke verification and was undone; record it as
+					// failed so it's left for manual handling or a resume
+					e.config.Progress.Error("%v, left for manual handling", err)
+					fixResultCopy.Success = false
+					fixResultCopy.Error = err
+					result.FailedFixes++
+					result.Cost += fixResult.Cost // Paid for, even though reverted
+					result.Tokens += fixResult.TokensUsed
+					e.recordFailure(phase.ID, plannedViolation.ViolationID, incidentKey, err.Error())
+					e.recordFix(v, incident, fixResultCopy, phase.ID)
+					continue
+				}
+				if err != nil {
 					e.config.Progress.Error("Git commit/verification failed: %v", err)
 				}
 			} else if e.config.CommitTracker != nil && !e.config.DryRun {
@@ -391,25 +629,33 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 				}
 			}
 
+			// Record successful fix
+			result.SuccessfulFixes++
+			result.Cost += fixResult.Cost
+			result.Tokens += fixResult.TokensUsed
+
+			e.state.RecordIncidentFix(plannedViolation.ViolationID, incidentKey, fixResult.Cost)
+
 			// Track for PR if enabled
 			if e.config.PRTracker != nil && !e.config.DryRun {
 				if err := e.config.PRTracker.TrackForPR(v, incident, &fixResultCopy); err != nil {
 					e.config.Progress.Error("PR tracking failed: %v", err)
 				}
 			}
+
+			if reporter, ok := e.config.Progress.(IncidentReporter); ok {
+				reporter.ReportIncident(v, incident, &fixResultCopy)
+			}
+
+			// Record fix for the summary and PR count preview (including dry-run)
+			e.recordFix(v, incident, fixResultCopy, phase.ID)
 		}
 	}
 
 	// Mark phase as completed
 	e.state.MarkPhaseCompleted(phase.ID)
 
-	// Update phase status with results
-	phaseStatus := e.state.GetPhaseStatus(phase.ID)
-	if phaseStatus != nil {
-		phaseStatus.FixesApplied = result.SuccessfulFixes
-		phaseStatus.Cost = result.Cost
-		e.state.UpdatePhaseStatus(*phaseStatus)
-	}
+	e.recordPhaseActuals(phase, result)
 
 	e.config.Progress.EndPhase()
 
@@ -419,6 +665,66 @@ func (e *Executor) executePhase(ctx context.Context, phase *planfile.Phase) Phas
 	return result
 }
 
+// recordPhaseActuals adds the phase's applied fixes, cost and tokens to its
+// status. They accumulate so that actuals survive a resume (already completed
+// incidents are skipped and cost nothing), and are recorded for a phase that
+// stopped with an error too.
+func (e *Executor) recordPhaseActuals(phase *planfile.Phase, result PhaseResult) {
+	phaseStatus := e.state.GetPhaseStatus(phase.ID)
+	if phaseStatus == nil {
+		return
+	}
+	phaseStatus.FixesApplied += result.SuccessfulFixes
+	phaseStatus.Cost += result.Cost
+	phaseStatus.Tokens += result.Tokens
+	phaseStatus.EstimatedCost = phase.EstimatedCost
+	e.state.UpdatePhaseStatus(*phaseStatus)
+}
+
+// recordFailure records a failed fix attempt and marks the incident
+// permanently failed once it has used up Config.MaxIncidentAttempts
+func (e *Executor) recordFailure(phaseID, violationID, incidentKey, errorMsg string) {
+	attempts := e.state.RecordIncidentFailure(phaseID, violationID, incidentKey, errorMsg)
+	if e.config.MaxIncidentAttempts > 0 && attempts >= e.config.MaxIncidentAttempts {
+		e.state.MarkIncidentPermanentlyFailed(violationID, incidentKey)
+		e.config.Progress.Info("   ⛔ %s failed %d times, marked permanently failed", incidentKey, attempts)
+	}
+}
+
+// attemptsExhausted reports whether an unfinished incident has used up
+// Config.MaxIncidentAttempts. The limit is checked against the recorded
+// attempts rather than the status, so raising it retries the incident.
+func (e *Executor) attemptsExhausted(status planfile.IncidentStatus) bool {
+	return e.config.MaxIncidentAttempts > 0 && status.Attempts >= e.config.MaxIncidentAttempts
+}
+
+// assertFix runs the configured fix assertion for an applied fix and marks the
+// fix as failed, undoing its change, if the assertion does not pass.
+func (e *Executor) assertFix(ctx context.Context, v violation.Violation, incident violation.Incident, fixResult *fixer.FixResult) {
+	if e.config.FixAssertion == nil || e.config.DryRun {
+		return
+	}
+
+	assertResult, err := e.config.FixAssertion.Check(ctx, verifier.AssertTarget{
+		File:        fixResult.FilePath,
+		Line:        incident.LineNumber,
+		ViolationID: v.ID,
+	})
+	if err == nil && !assertResult.Success {
+		err = assertResult.Error
+	}
+	if err != nil {
+		fixResult.Success = false
+		fixResult.Error = err
+		e.config.Progress.Error("%v", err)
+
+		// Don't leave a rejected fix in the working tree
+		if undoErr := gitutil.UndoFix(e.config.InputPath, fixResult); undoErr != nil {
+			e.config.Progress.Error("Could not undo the rejected fix: %v", undoErr)
+		}
+	}
+}
+
 // buildViolation constructs a violation.Violation from a planfile.PlannedViolation.
 // This converts the plan's violation representation into the format expected by the fixer.
 func (e *Executor) buildViolation(pv planfile.PlannedViolation) violation.Violation {
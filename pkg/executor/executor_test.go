@@ -2,17 +2,23 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/provider"
 	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -834,3 +840,793 @@ func TestExecute_DeduplicationAcrossViolations(t *testing.T) {
 
 	mockProvider.AssertExpectations(t)
 }
+
+func TestExecute_PhaseCosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+
+	plan := createTestPlan()
+	plan.Phases[0].EstimatedCost = 0.08
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "fixed", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "fixed", Confidence: 0.9},
+			},
+			Success:    true,
+			TokensUsed: 200,
+			Cost:       0.10,
+		},
+		nil,
+	).Once()
+
+	config := Config{
+		PlanPath:  planPath,
+		StatePath: statePath,
+		InputPath: tmpDir,
+		Provider:  mockProvider,
+		Progress:  &ux.NoOpProgressWriter{},
+		DryRun:    true,
+	}
+
+	exec, err := New(config)
+	require.NoError(t, err)
+	result, err := exec.Execute(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.PhaseCosts, 1)
+	phaseCost := result.PhaseCosts[0]
+	assert.Equal(t, "phase-1", phaseCost.PhaseID)
+	assert.Equal(t, 0.08, phaseCost.EstimatedCost)
+	assert.InDelta(t, 0.10, phaseCost.ActualCost, 0.0001)
+	assert.Equal(t, 200, phaseCost.ActualTokens)
+	variance, ok := phaseCost.VariancePercent()
+	assert.True(t, ok)
+	assert.InDelta(t, 25.0, variance, 0.01)
+
+	// Actuals are persisted in the state file
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	status := state.GetPhaseStatus("phase-1")
+	require.NotNil(t, status)
+	assert.Equal(t, 2, status.FixesApplied)
+	assert.InDelta(t, 0.10, status.Cost, 0.0001)
+	assert.Equal(t, 200, status.Tokens)
+	assert.Equal(t, 0.08, status.EstimatedCost)
+
+	// Resuming skips completed incidents but keeps the earlier actuals
+	config.Resume = true
+	exec, err = New(config)
+	require.NoError(t, err)
+	result, err = exec.Execute(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.PhaseCosts, 1)
+	assert.InDelta(t, 0.10, result.PhaseCosts[0].ActualCost, 0.0001)
+	assert.Equal(t, 200, result.PhaseCosts[0].ActualTokens)
+	state, err = planfile.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.GetPhaseStatus("phase-1").FixesApplied)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestExecute_PhaseActualsSavedOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+
+	plan := createTestPlan()
+	second := plan.Phases[0].Violations[0]
+	second.ViolationID = "test-violation-2"
+	plan.Phases[0].Violations = append(plan.Phases[0].Violations, second)
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	// The run is cancelled while the phase's first violation is being fixed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Run(func(mock.Arguments) { cancel() }).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "fixed", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "fixed", Confidence: 0.9},
+			},
+			Success:    true,
+			TokensUsed: 200,
+			Cost:       0.10,
+		},
+		nil,
+	).Once()
+
+	exec, err := New(Config{
+		PlanPath:  planPath,
+		StatePath: statePath,
+		InputPath: tmpDir,
+		Provider:  mockProvider,
+		Progress:  &ux.NoOpProgressWriter{},
+		DryRun:    true,
+	})
+	require.NoError(t, err)
+	_, err = exec.Execute(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	status := state.GetPhaseStatus("phase-1")
+	require.NotNil(t, status)
+	assert.Equal(t, planfile.StatusInProgress, status.Status)
+	assert.Equal(t, 2, status.FixesApplied)
+	assert.InDelta(t, 0.10, status.Cost, 0.0001)
+	assert.Equal(t, 200, status.Tokens)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestExecute_SecondPassResultsMatchIncidents(t *testing.T) {
+	tmpDir := t.TempDir()
+	var incidents []violation.Incident
+	for _, name := range []string{"A.java", "B.java", "C.java"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("class Test {}"), 0644))
+		incidents = append(incidents, violation.Incident{URI: "file://" + path, LineNumber: 1, Message: "m"})
+	}
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+	plan := createTestPlan()
+	plan.Phases[0].Violations[0].Incidents = incidents
+	plan.Phases[0].Violations[0].IncidentCount = len(incidents)
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	// B is below the second-pass threshold and fixed again after the
+	// batch; C fails
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: incidents[0].URI, Success: true, FixedContent: "class A {}", Confidence: 0.95},
+				{IncidentURI: incidents[1].URI, Success: true, FixedContent: "class B {}", Confidence: 0.5},
+				{IncidentURI: incidents[2].URI, Success: false, Error: errors.New("no fix")},
+			},
+			Success:    true,
+			TokensUsed: 300,
+			Cost:       0.30,
+		},
+		nil,
+	).Once()
+	mockProvider.On("FixViolation", mock.Anything, mock.MatchedBy(func(req provider.FixRequest) bool {
+		return req.Incident.URI == incidents[1].URI
+	})).Return(&provider.FixResponse{Success: true, FixedContent: "class B {}", Confidence: 0.95, Cost: 0.05}, nil).Once()
+
+	batchConfig := fixer.DefaultBatchConfig()
+	batchConfig.GroupByFile = false
+	batchConfig.SecondPassThreshold = 0.8
+	exec, err := New(Config{
+		PlanPath:    planPath,
+		StatePath:   statePath,
+		InputPath:   tmpDir,
+		Provider:    mockProvider,
+		Progress:    &ux.NoOpProgressWriter{},
+		BatchConfig: batchConfig,
+	})
+	require.NoError(t, err)
+	result, err := exec.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessfulFixes)
+	assert.Equal(t, 1, result.FailedFixes)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	recorded := state.Violations["test-violation-1"].Incidents
+	assert.Equal(t, planfile.StatusCompleted, recorded[planfile.IncidentKey(incidents[0])].Status)
+	assert.Equal(t, planfile.StatusCompleted, recorded[planfile.IncidentKey(incidents[1])].Status)
+	assert.InDelta(t, 0.15, recorded[planfile.IncidentKey(incidents[1])].Cost, 1e-9, "second pass plus its share of the batch")
+	assert.Equal(t, planfile.StatusFailed, recorded[planfile.IncidentKey(incidents[2])].Status)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestPhaseCost_VariancePercent(t *testing.T) {
+	variance, ok := PhaseCost{EstimatedCost: 0.20, ActualCost: 0.15}.VariancePercent()
+	assert.True(t, ok)
+	assert.InDelta(t, -25.0, variance, 0.01)
+
+	_, ok = PhaseCost{EstimatedCost: 0, ActualCost: 0.15}.VariancePercent()
+	assert.False(t, ok, "no variance without an estimate")
+}
+
+func TestExecute_FixAssertion(t *testing.T) {
+	tests := []struct {
+		name           string
+		command        string
+		wantSuccessful int
+		wantFailed     int
+		wantContent    string
+	}{
+		{name: "passing assertion", command: "grep -q fixed {file}", wantSuccessful: 2, wantContent: "fixed"},
+		{name: "failing assertion undoes the fix", command: "grep -q jakarta {file}", wantFailed: 2, wantContent: "public class Test {}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}"), 0644))
+
+			planPath := filepath.Join(tmpDir, "plan.yaml")
+			require.NoError(t, planfile.SavePlan(createTestPlan(), planPath))
+
+			mockProvider := new(MockProvider)
+			mockProvider.On("Name").Return("test-provider").Maybe()
+			mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+				&provider.BatchResponse{
+					Fixes: []provider.IncidentFix{
+						{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "fixed", Confidence: 0.9},
+						{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "fixed", Confidence: 0.9},
+					},
+					Success: true,
+				},
+				nil,
+			).Once()
+
+			assertion, err := verifier.NewFixAssertion(tt.command, tmpDir)
+			require.NoError(t, err)
+
+			exec, err := New(Config{
+				PlanPath:     planPath,
+				StatePath:    filepath.Join(tmpDir, "state.yaml"),
+				InputPath:    tmpDir,
+				Provider:     mockProvider,
+				Progress:     &ux.NoOpProgressWriter{},
+				FixAssertion: assertion,
+			})
+			require.NoError(t, err)
+
+			result, _ := exec.Execute(context.Background())
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantSuccessful, result.SuccessfulFixes)
+			assert.Equal(t, tt.wantFailed, result.FailedFixes)
+			for _, fix := range result.Fixes {
+				assert.Equal(t, tt.wantFailed == 0, fix.Result.Success)
+			}
+			content, err := os.ReadFile(filepath.Join(tmpDir, "test.java"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+		})
+	}
+}
+
+func TestExecute_PlanChangedBetweenRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "other.java"), []byte("public class Other {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+
+	plan := createTestPlan()
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	// First run fixes both incidents of the original plan
+	firstProvider := new(MockProvider)
+	firstProvider.On("Name").Return("test-provider").Maybe()
+	firstProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "fixed", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "fixed", Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	).Once()
+
+	config := Config{
+		PlanPath:  planPath,
+		StatePath: statePath,
+		InputPath: tmpDir,
+		Provider:  firstProvider,
+		Progress:  &ux.NoOpProgressWriter{},
+		DryRun:    true,
+	}
+	exec, err := New(config)
+	require.NoError(t, err)
+	result, err := exec.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessfulFixes)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	planHash, err := plan.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, planHash, state.PlanHash)
+
+	// Edit the plan: a new incident in the same file and a new violation
+	plan.Phases[0].Violations[0].Incidents = append(plan.Phases[0].Violations[0].Incidents,
+		violation.Incident{URI: "file:///test.java", LineNumber: 30, Message: "Test incident 3"})
+	plan.Phases[0].Violations[0].IncidentCount = 3
+	plan.Phases[0].Violations = append(plan.Phases[0].Violations, planfile.PlannedViolation{
+		ViolationID:   "test-violation-2",
+		Description:   "Another violation",
+		Category:      "mandatory",
+		Effort:        1,
+		IncidentCount: 1,
+		Incidents: []violation.Incident{
+			{URI: "file:///other.java", LineNumber: 5, Message: "Other incident"},
+		},
+	})
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	t.Run("divergence requires force", func(t *testing.T) {
+		noCallProvider := new(MockProvider)
+		noCallProvider.On("Name").Return("test-provider").Maybe()
+
+		config.Provider = noCallProvider
+		exec, err := New(config)
+		require.NoError(t, err)
+		_, err = exec.Execute(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has changed")
+		assert.Contains(t, err.Error(), "--force")
+		noCallProvider.AssertNotCalled(t, "FixBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("force reconciles", func(t *testing.T) {
+		secondProvider := new(MockProvider)
+		secondProvider.On("Name").Return("test-provider").Maybe()
+		secondProvider.On("FixBatch", mock.Anything, mock.MatchedBy(func(req provider.BatchRequest) bool {
+			return req.Violation.ID == "test-violation-1" && len(req.Incidents) == 1 && req.Incidents[0].LineNumber == 30
+		})).Return(
+			&provider.BatchResponse{
+				Fixes:   []provider.IncidentFix{{IncidentURI: "file:///test.java:30", Success: true, FixedContent: "fixed", Confidence: 0.9}},
+				Success: true,
+			},
+			nil,
+		).Once()
+		secondProvider.On("FixBatch", mock.Anything, mock.MatchedBy(func(req provider.BatchRequest) bool {
+			return req.Violation.ID == "test-violation-2" && len(req.Incidents) == 1
+		})).Return(
+			&provider.BatchResponse{
+				Fixes:   []provider.IncidentFix{{IncidentURI: "file:///other.java:5", Success: true, FixedContent: "fixed", Confidence: 0.9}},
+				Success: true,
+			},
+			nil,
+		).Once()
+
+		config.Provider = secondProvider
+		config.Force = true
+		exec, err := New(config)
+		require.NoError(t, err)
+		result, err := exec.Execute(context.Background())
+		require.NoError(t, err)
+
+		// Only the new plan items were executed; completed incidents were skipped
+		assert.Equal(t, 2, result.SuccessfulFixes)
+		assert.Equal(t, 2, result.SkippedFixes)
+		secondProvider.AssertExpectations(t)
+
+		state, err := planfile.LoadState(statePath)
+		require.NoError(t, err)
+		newHash, err := plan.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, newHash, state.PlanHash)
+		assert.True(t, state.IsIncidentCompleted("test-violation-1", "file:///test.java:30"))
+		assert.True(t, state.IsIncidentCompleted("test-violation-2", "file:///other.java:5"))
+	})
+}
+
+// incidentRecorder is a progress writer that records reported incidents
+type incidentRecorder struct {
+	ux.NoOpProgressWriter
+	results []fixer.FixResult
+}
+
+func (r *incidentRecorder) ReportIncident(v violation.Violation, incident violation.Incident, result *fixer.FixResult) {
+	r.results = append(r.results, *result)
+}
+
+func TestExecute_ReportsIncidentsWithContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}\n"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	require.NoError(t, planfile.SavePlan(createTestPlan(), planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "public class Fixed {}\n", Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	).Once()
+
+	recorder := &incidentRecorder{}
+	exec, err := New(Config{
+		PlanPath:  planPath,
+		StatePath: filepath.Join(tmpDir, "state.yaml"),
+		InputPath: tmpDir,
+		Provider:  mockProvider,
+		Progress:  recorder,
+	})
+	require.NoError(t, err)
+
+	result, _ := exec.Execute(context.Background())
+	require.NotNil(t, result)
+
+	require.NotEmpty(t, recorder.results)
+	reported := recorder.results[0]
+	assert.Equal(t, "public class Test {}\n", reported.OriginalContent)
+	assert.Equal(t, "public class Fixed {}\n", reported.FixedContent)
+	assert.Contains(t, reported.Diff(fixer.DefaultDiffContext), "-public class Test {}\n+public class Fixed {}\n")
+
+	// Contents are not retained in the run result
+	for _, fix := range result.Fixes {
+		assert.Empty(t, fix.Result.OriginalContent)
+		assert.Empty(t, fix.Result.FixedContent)
+	}
+}
+
+// messageRecorder is a progress writer that records info and error messages
+type messageRecorder struct {
+	ux.NoOpProgressWriter
+	messages []string
+}
+
+func (r *messageRecorder) Info(format string, args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func (r *messageRecorder) Error(format string, args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func TestExecute_DryRunSkipsVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		verifyOnDryRun bool
+		wantVerified   bool
+		wantMessage    string
+	}{
+		{name: "skipped by default", verifyOnDryRun: false, wantVerified: false, wantMessage: "Verification skipped: dry-run"},
+		{name: "explicit override", verifyOnDryRun: true, wantVerified: true, wantMessage: "Verification passed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}\n"), 0644))
+
+			planPath := filepath.Join(tmpDir, "plan.yaml")
+			require.NoError(t, planfile.SavePlan(createTestPlan(), planPath))
+
+			mockProvider := new(MockProvider)
+			mockProvider.On("Name").Return("test-provider").Maybe()
+			mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+				&provider.BatchResponse{
+					Fixes: []provider.IncidentFix{
+						{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "public class Fixed {}\n", Confidence: 0.9},
+					},
+					Success: true,
+				},
+				nil,
+			).Maybe()
+
+			// The tracker is built without DryRun, as the web UI does; the executor must apply it
+			marker := filepath.Join(tmpDir, "verified")
+			tracker, err := gitutil.NewVerifiedCommitTracker(gitutil.StrategyAtEnd, tmpDir, "test-provider", verifier.Config{
+				Type:           verifier.VerificationBuild,
+				Strategy:       verifier.StrategyAtEnd,
+				WorkingDir:     tmpDir,
+				CustomCommand:  "touch " + marker,
+				VerifyOnDryRun: tt.verifyOnDryRun,
+			})
+			require.NoError(t, err)
+
+			recorder := &messageRecorder{}
+			exec, err := New(Config{
+				PlanPath:        planPath,
+				StatePath:       filepath.Join(tmpDir, "state.yaml"),
+				InputPath:       tmpDir,
+				Provider:        mockProvider,
+				Progress:        recorder,
+				DryRun:          true,
+				VerifiedTracker: tracker,
+			})
+			require.NoError(t, err)
+
+			_, err = exec.Execute(context.Background())
+			require.NoError(t, err)
+
+			if tt.wantVerified {
+				assert.FileExists(t, marker)
+			} else {
+				assert.NoFileExists(t, marker)
+			}
+			assert.Condition(t, func() bool {
+				for _, msg := range recorder.messages {
+					if strings.Contains(msg, tt.wantMessage) {
+						return true
+					}
+				}
+				return false
+			}, "expected a progress message containing %q, got %v", tt.wantMessage, recorder.messages)
+		})
+	}
+}
+
+func TestExecute_RecordsChangedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("public class Test {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+	require.NoError(t, planfile.SavePlan(createTestPlan(), planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "public class TestFixed {}", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "public class TestFixed {}", Confidence: 0.9},
+			},
+			Success: true,
+			Cost:    0.10,
+		},
+		nil,
+	)
+
+	exec, err := New(Config{
+		PlanPath:  planPath,
+		StatePath: statePath,
+		InputPath: tmpDir,
+		Provider:  mockProvider,
+		Progress:  &ux.NoOpProgressWriter{},
+	})
+	require.NoError(t, err)
+
+	_, err = exec.Execute(context.Background())
+	require.NoError(t, err)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	status := state.Violations["test-violation-1"]
+	assert.Equal(t, []string{"test.java"}, status.Files)
+	assert.Empty(t, status.Commits, "no commit tracker configured")
+	assert.Empty(t, status.Verification, "no verification configured")
+}
+
+func TestExecute_RevertsFixFailingVerification(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "test.java")
+	require.NoError(t, os.WriteFile(file, []byte("public class Test {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+	require.NoError(t, planfile.SavePlan(createTestPlan(), planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "public class Broken {", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "public class Broken {", Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	)
+
+	// Every fix fails per-fix verification; fail-fast is off, so the run continues
+	tracker, err := gitutil.NewVerifiedCommitTracker(gitutil.StrategyAtEnd, tmpDir, "test-provider", verifier.Config{
+		Type:          verifier.VerificationBuild,
+		Strategy:      verifier.StrategyPerFix,
+		WorkingDir:    tmpDir,
+		CustomCommand: "false",
+	})
+	require.NoError(t, err)
+
+	exec, err := New(Config{
+		PlanPath:        planPath,
+		StatePath:       statePath,
+		InputPath:       tmpDir,
+		Provider:        mockProvider,
+		Progress:        &ux.NoOpProgressWriter{},
+		VerifiedTracker: tracker,
+	})
+	require.NoError(t, err)
+
+	result, err := exec.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.SuccessfulFixes)
+	assert.Equal(t, 2, result.FailedFixes)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "public class Test {}", string(content), "the broken fix is reverted")
+
+	reverted := tracker.RevertedIncidents()
+	require.Len(t, reverted, 2)
+	assert.Equal(t, "test-violation-1", reverted[0].ViolationID)
+	assert.Equal(t, 1, tracker.GetStats().FailedVerifications, "the second fix was undone by the first revert")
+
+	// Reverted incidents are recorded as failed, so a resume retries them
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	for key, incident := range state.Violations["test-violation-1"].Incidents {
+		assert.Equal(t, planfile.StatusFailed, incident.Status, key)
+	}
+	require.NotNil(t, state.LastFailure)
+	assert.Contains(t, state.LastFailure.Error, "reverted")
+	assert.False(t, state.IsIncidentCompleted("test-violation-1", "file:///test.java:10"))
+}
+
+func TestExecute_MaxPhases(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"test1.java", "test2.java", "test3.java"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("class Test {}"), 0644))
+	}
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+
+	plan := createTestPlanMultiPhase()
+	phase3 := plan.Phases[1]
+	phase3.ID = "phase-3"
+	phase3.Name = "Phase 3"
+	phase3.Order = 3
+	phase3.Violations = []planfile.PlannedViolation{{
+		ViolationID:   "violation-3",
+		Description:   "Violation 3",
+		Category:      "optional",
+		Effort:        5,
+		IncidentCount: 1,
+		Incidents:     []violation.Incident{{URI: "file:///test3.java", LineNumber: 30}},
+	}}
+	plan.Phases = append(plan.Phases, phase3)
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+
+	var fixedViolations []string
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	for i := 1; i <= 3; i++ {
+		violationID := fmt.Sprintf("violation-%d", i)
+		mockProvider.On("FixBatch", mock.Anything, mock.MatchedBy(func(req provider.BatchRequest) bool {
+			return req.Violation.ID == violationID
+		})).Run(func(args mock.Arguments) {
+			fixedViolations = append(fixedViolations, violationID)
+		}).Return(
+			&provider.BatchResponse{
+				Fixes: []provider.IncidentFix{{
+					IncidentURI:  fmt.Sprintf("file:///test%d.java:%d", i, i*10),
+					Success:      true,
+					FixedContent: "class Fixed {}",
+					Confidence:   0.9,
+				}},
+				Success: true,
+			},
+			nil,
+		).Once()
+	}
+
+	run := func() *Result {
+		exec, err := New(Config{
+			PlanPath:  planPath,
+			StatePath: statePath,
+			InputPath: tmpDir,
+			Provider:  mockProvider,
+			MaxPhases: 2,
+			Progress:  &ux.NoOpProgressWriter{},
+			DryRun:    true,
+		})
+		require.NoError(t, err)
+		result, err := exec.Execute(context.Background())
+		require.NoError(t, err)
+		return result
+	}
+
+	// First run: exactly two phases
+	result := run()
+	assert.Equal(t, 2, result.ExecutedPhases)
+	assert.Equal(t, []string{"phase-1", "phase-2"}, []string{result.PhaseCosts[0].PhaseID, result.PhaseCosts[1].PhaseID})
+	assert.Equal(t, []string{"phase-3"}, result.RemainingPhases)
+	assert.Equal(t, []string{"violation-1", "violation-2"}, fixedViolations)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, planfile.StatusCompleted, state.GetPhaseStatus("phase-1").Status)
+	assert.Equal(t, planfile.StatusCompleted, state.GetPhaseStatus("phase-2").Status)
+	phase3Status := state.GetPhaseStatus("phase-3")
+	assert.True(t, phase3Status == nil || phase3Status.Status != planfile.StatusCompleted)
+
+	// Second run picks up the remaining phase
+	result = run()
+	assert.Equal(t, 1, result.ExecutedPhases)
+	assert.Empty(t, result.RemainingPhases)
+	assert.Equal(t, []string{"violation-1", "violation-2", "violation-3"}, fixedViolations)
+
+	state, err = planfile.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, planfile.StatusCompleted, state.GetPhaseStatus("phase-3").Status)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestExecute_MaxIncidentAttempts(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte("class Test {}"), 0644))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	statePath := filepath.Join(tmpDir, "state.yaml")
+
+	plan := createTestPlan()
+	require.NoError(t, planfile.SavePlan(plan, planPath))
+	completedKey := planfile.IncidentKey(plan.Phases[0].Violations[0].Incidents[0])
+	failingKey := planfile.IncidentKey(plan.Phases[0].Violations[0].Incidents[1])
+
+	// The first incident is fixed; the second has failed once
+	state := planfile.NewState(planPath, 1)
+	state.RecordIncidentFix("test-violation-1", completedKey, 0.05)
+	state.RecordIncidentFailure("phase-1", "test-violation-1", failingKey, "AI timeout")
+	require.NoError(t, planfile.SaveState(state, statePath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:20", Success: false, Error: assert.AnError},
+			},
+		},
+		nil,
+	).Once()
+
+	resume := func() *Result {
+		exec, err := New(Config{
+			PlanPath:            planPath,
+			StatePath:           statePath,
+			InputPath:           tmpDir,
+			Provider:            mockProvider,
+			Progress:            &ux.NoOpProgressWriter{},
+			Resume:              true,
+			DryRun:              true,
+			MaxIncidentAttempts: 2,
+		})
+		require.NoError(t, err)
+		result, err := exec.Execute(context.Background())
+		require.NoError(t, err)
+		return result
+	}
+
+	// Second attempt fails and uses up the limit
+	result := resume()
+	assert.Equal(t, 1, result.FailedFixes)
+	assert.Equal(t, 0, result.PermanentlyFailedFixes)
+
+	state, err := planfile.LoadState(statePath)
+	require.NoError(t, err)
+	incident := state.Violations["test-violation-1"].Incidents[failingKey]
+	assert.Equal(t, planfile.StatusPermanentlyFailed, incident.Status)
+	assert.Equal(t, 2, incident.Attempts)
+
+	// Resuming again skips it without asking the provider
+	result = resume()
+	assert.Equal(t, 0, result.FailedFixes)
+	assert.Equal(t, 1, result.PermanentlyFailedFixes)
+	assert.Equal(t, 1, result.SkippedFixes)
+
+	mockProvider.AssertExpectations(t)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/tsanders/kantra-ai/pkg/gitutil"
 	"github.com/tsanders/kantra-ai/pkg/provider"
 	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
+	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
 // Config holds configuration for plan execution.
@@ -20,6 +22,8 @@ type Config struct {
 	InputPath     string            // Path to source code directory (required)
 	Provider      provider.Provider // AI provider for fixes
 	PhaseID       string            // Specific phase to execute (empty = all)
+	MaxPhases     int               // Maximum not-yet-completed phases to execute per run (0 = no limit)
+	MaxIncidentAttempts int         // Fix attempts per incident across runs before it is skipped as permanently failed (0 = no limit)
 	DryRun        bool              // Preview without applying changes
 	GitCommit     string            // Git commit strategy (per-violation, per-incident, at-end, "")
 	CreatePR            bool              // Create GitHub pull requests
@@ -28,17 +32,28 @@ type Config struct {
 	BranchName          string            // Custom branch name prefix
 	Progress            ux.ProgressWriter       // Progress reporting
 	Resume              bool                    // Resume from last failure
+	Force               bool                    // Reconcile state with a plan that changed since the state was written
 	BatchConfig         fixer.BatchConfig       // Batch processing configuration
 	ConfidenceConfig    confidence.Config       // Confidence threshold configuration
 	CommitTracker       *gitutil.CommitTracker  // Git commit tracker (nil if disabled)
 	VerifiedTracker     *gitutil.VerifiedCommitTracker // Verified commit tracker (nil if disabled)
 	PRTracker           *gitutil.PRTracker      // PR tracker (nil if disabled)
+	PRCountPreview      bool                    // Estimate PR counts per strategy for the fixes (works in dry-run)
+	FixAssertion        *verifier.FixAssertion  // Per-fix success predicate (nil if disabled, skipped in dry-run)
+}
+
+// IncidentReporter is an optional extension of ux.ProgressWriter. If
+// Config.Progress implements it, it is called for every successfully applied
+// fix with the full result, including the original and fixed file content.
+type IncidentReporter interface {
+	ReportIncident(v violation.Violation, incident violation.Incident, result *fixer.FixResult)
 }
 
 // Result contains the result of plan execution with detailed metrics.
 type Result struct {
 	TotalPhases      int                 // Total phases in plan
 	ExecutedPhases   int                 // Phases that were executed
+	RemainingPhases  []string            // Phases left for a later run by Config.MaxPhases
 	CompletedPhases  int                 // Phases that completed successfully
 	FailedPhases     int                 // Phases that failed
 	TotalFixes       int                 // Total fixes attempted
@@ -46,12 +61,36 @@ type Result struct {
 	FailedFixes      int                 // Fixes that failed
 	SkippedFixes     int                 // Fixes skipped (already completed)
 	DuplicateFixes   int                 // Fixes skipped (duplicates)
+	PermanentlyFailedFixes int           // Fixes skipped after Config.MaxIncidentAttempts failed attempts
+	IgnoredFixes     int                 // Fixes skipped because the file matches .kantra-ai-ignore
 	TotalCost        float64             // Total cost incurred
 	TotalTokens      int                 // Total tokens used
 	StatePath        string              // Path to state file
 	ConfidenceStats  *confidence.Stats   // Confidence filtering statistics (nil if disabled)
 	Commits          []gitutil.CommitInfo // List of created git commits (nil if git commits disabled)
 	PRs              []gitutil.PRInfo     // List of created pull requests (nil if PRs disabled)
+	PRCountPreview   *gitutil.PRCountPreview // PR counts per strategy (nil unless Config.PRCountPreview is set)
+	PhaseCosts       []PhaseCost             // Estimated vs actual cost for each executed phase
+	Fixes            []gitutil.FixRecord     // Every attempted fix in execution order, successful or not
+}
+
+// PhaseCost compares a phase's planned cost estimate with what it actually cost.
+// Actual values include earlier runs of the phase recorded in the state file.
+type PhaseCost struct {
+	PhaseID       string
+	PhaseName     string
+	EstimatedCost float64 // From the plan
+	ActualCost    float64 // Total cost incurred
+	ActualTokens  int     // Total tokens used
+}
+
+// VariancePercent returns how far the actual cost deviates from the estimate,
+// as a percentage of the estimate. ok is false if there was no estimate.
+func (c PhaseCost) VariancePercent() (variance float64, ok bool) {
+	if c.EstimatedCost <= 0 {
+		return 0, false
+	}
+	return (c.ActualCost - c.EstimatedCost) / c.EstimatedCost * 100, true
 }
 
 // PhaseResult contains the result of executing a single phase.
@@ -62,6 +101,8 @@ type PhaseResult struct {
 	FailedFixes     int
 	SkippedFixes    int // Fixes skipped (already completed)
 	DuplicateFixes  int // Fixes skipped (duplicates)
+	PermanentlyFailedFixes int // Fixes skipped after Config.MaxIncidentAttempts failed attempts
+	IgnoredFixes    int // Fixes skipped because the file matches .kantra-ai-ignore
 	Cost            float64
 	Tokens          int
 	Error           error
@@ -20,7 +20,9 @@ type BatchConfig struct {
 	// Can be overridden if MaxTokensPerBatch is set and file sizes vary
 	MaxBatchSize int
 
-	// Parallelism is the number of concurrent batches to process
+	// Parallelism is the number of concurrent batches to process.
+	// Fixes to the same file from different batches are written one at a
+	// time, each merged into the file's current content.
 	// Default: 8
 	Parallelism int
 
@@ -40,6 +42,33 @@ type BatchConfig struct {
 	// Default: 0 (disabled)
 	// Recommended: 50000 tokens (leaves room in 200K context for prompt + output)
 	MaxTokensPerBatch int
+
+	// RateTracker tracks tokens per minute across batches and, if it has a
+	// limit, pauses before batches that would exceed it. The tracker is
+	// shared by every BatchFixer created from this config.
+	// Default: nil (no tracking)
+	RateTracker *TPMTracker
+
+	// SyntaxCheck rejects fixes whose content doesn't parse (see
+	// ValidateSyntax). Batched fixes are not requested again.
+	// Default: false
+	SyntaxCheck bool
+
+	// SecondPassThreshold holds back batched fixes below this confidence
+	// and re-queues their incidents for a second, more careful pass: once
+	// the batches are done, each is fixed on its own with the single-incident
+	// prompt. The batch's other fixes are applied as usual, so a few weak
+	// results don't hold back the strong ones.
+	// Default: 0 (disabled)
+	SecondPassThreshold float64
+
+	// ReuseIdenticalFixes batches one incident per group of incidents with
+	// identical surrounding code, then applies its fix's change to the rest
+	// of the group without API calls, if the fix's confidence is at least
+	// ReuseMinConfidence. Groups whose fix can't be reused are batched
+	// afterwards.
+	// Default: false
+	ReuseIdenticalFixes bool
 }
 
 // DefaultBatchConfig returns the recommended batch configuration
@@ -68,6 +97,8 @@ type BatchFixer struct {
 	dryRun         bool
 	config         BatchConfig
 	confidenceConf confidence.Config
+	ignore         *IgnoreList // Files that must never be written (.kantra-ai-ignore)
+	reuse          *reuseCache // Fixes reused for identical incidents (nil = disabled)
 }
 
 // NewBatchFixer creates a new batch fixer
@@ -78,6 +109,8 @@ func NewBatchFixer(p provider.Provider, inputDir string, dryRun bool, config Bat
 		dryRun:         dryRun,
 		config:         config,
 		confidenceConf: confidence.DefaultConfig(),
+		ignore:         loadIgnoreList(inputDir),
+		reuse:          newReuseCache(config.ReuseIdenticalFixes),
 	}
 }
 
@@ -89,6 +122,8 @@ func NewBatchFixerWithConfidence(p provider.Provider, inputDir string, dryRun bo
 		dryRun:         dryRun,
 		config:         config,
 		confidenceConf: confidenceConf,
+		ignore:         loadIgnoreList(inputDir),
+		reuse:          newReuseCache(config.ReuseIdenticalFixes),
 	}
 }
 
@@ -99,6 +134,34 @@ type batchJob struct {
 	batch     int // Batch number for this violation
 }
 
+// incidentFor returns the incident the job's fix at index with uri fixes:
+// its first incident for uri not in matched or, if the provider's uri
+// matches none, its incident at index, since fixes come back in incident
+// order. The incident is added to matched.
+func (job batchJob) incidentFor(uri string, index int, matched map[int]bool) (violation.Incident, bool) {
+	for i, incident := range job.incidents {
+		if incident.URI == uri && !matched[i] {
+			matched[i] = true
+			return incident, true
+		}
+	}
+	if index < len(job.incidents) && !matched[index] {
+		matched[index] = true
+		return job.incidents[index], true
+	}
+	return violation.Incident{}, false
+}
+
+// requeuedIncident is an incident held back for the second pass, with the
+// cost of its first, batched fix
+type requeuedIncident struct {
+	incident   violation.Incident
+	filePath   string
+	confidence float64
+	cost       float64
+	tokensUsed int
+}
+
 // batchResult contains the results from processing a batch
 type batchResult struct {
 	job        batchJob
@@ -106,6 +169,10 @@ type batchResult struct {
 	cost       float64
 	tokensUsed int
 	err        error
+
+	// File content sent to the provider, by full path: the base the fixes
+	// were generated from
+	fileContents map[string]string
 }
 
 // FixViolationBatch processes all incidents for a violation using batching
@@ -115,7 +182,14 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 		// Fall back to sequential processing
 		return bf.fixSequential(ctx, v)
 	}
+	if bf.reuse != nil {
+		return bf.fixReusing(ctx, v)
+	}
+	return bf.fixBatched(ctx, v)
+}
 
+// fixBatched fixes the violation's incidents in concurrent batches
+func (bf *BatchFixer) fixBatched(ctx context.Context, v violation.Violation) ([]FixResult, error) {
 	// Group incidents into batches
 	batches := bf.createBatches(v)
 
@@ -145,6 +219,7 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 
 	// Collect results
 	allResults := make([]FixResult, 0, len(v.Incidents))
+	var requeued []requeuedIncident
 	for result := range results {
 		if result.err != nil {
 			// If batch failed entirely, create failed results for all incidents
@@ -155,11 +230,14 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 					relPath = filepath.Base(incident.GetFilePath()) // fallback to base name
 				}
 				allResults = append(allResults, FixResult{
-					Success:    false,
-					FilePath:   relPath,
-					Error:      result.err,
-					TokensUsed: 0,
-					Cost:       0,
+					ViolationID: v.ID,
+					IncidentURI: incident.URI,
+					LineNumber:  incident.LineNumber,
+					Success:     false,
+					FilePath:    relPath,
+					Error:       result.err,
+					TokensUsed:  0,
+					Cost:        0,
 				})
 			}
 			continue
@@ -173,38 +251,86 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 			tokensPerFix = result.tokensUsed / len(result.fixes)
 		}
 
-		// Convert batch fixes to individual FixResults
-		for _, fix := range result.fixes {
+		// Fixes to write, by file: each file's edits are applied together
+		// and written once after the batch's results are converted
+		pendingWrites := make(map[string][]int) // Full path -> indexes in allResults
+		var pendingFiles []string
+		matched := make(map[int]bool) // Incidents of the job with a fix
+
+		// Convert batch fixes to individual FixResults, identified by the
+		// incident they fix: batches finish in any order, and second-pass
+		// results are added after all others
+		for k, fix := range result.fixes {
+			incident, found := result.job.incidentFor(fix.IncidentURI, k, matched)
+
 			// Resolve and validate file path first (needed for both success and failure cases)
 			filePath, err := resolveAndValidateFilePath(getFilePathFromURI(fix.IncidentURI), bf.inputDir)
 			if err != nil {
 				// If we can't resolve the path, create a failed result
 				fixResult := FixResult{
-					Success:    false,
-					FilePath:   filepath.Base(getFilePathFromURI(fix.IncidentURI)), // fallback to base name
-					TokensUsed: tokensPerFix,
-					Cost:       costPerFix,
-					Confidence: fix.Confidence,
-					Error:      fmt.Errorf("invalid file path: %w", err),
+					ViolationID: v.ID,
+					IncidentURI: incident.URI,
+					LineNumber:  incident.LineNumber,
+					Success:     false,
+					FilePath:    filepath.Base(getFilePathFromURI(fix.IncidentURI)), // fallback to base name
+					TokensUsed:  tokensPerFix,
+					Cost:        costPerFix,
+					Confidence:  fix.Confidence,
+					Error:       fmt.Errorf("invalid file path: %w", err),
 				}
 				allResults = append(allResults, fixResult)
 				continue
 			}
 
 			fixResult := FixResult{
+				ViolationID: v.ID,
+				IncidentURI: incident.URI,
+				LineNumber:  incident.LineNumber,
+
 				Success:    fix.Success,
 				FilePath:   filePath, // Use the full relative path
 				TokensUsed: tokensPerFix,
 				Cost:       costPerFix,
 				Confidence: fix.Confidence,
-			}
+				Extra:      fix.Extra,
+				Provider:   fix.Provider,
 
-			if fix.Success {
+				Explanation: fix.Explanation,
+			}
+			writePath := "" // Set when the fix is to be written
+
+			if fix.Success && bf.ignore.Match(filePath) {
+				// Never touch files listed in .kantra-ai-ignore
+				fixResult.Success = false
+				fixResult.SkippedIgnored = true
+				fixResult.SkipReason = IgnoreReason
+				fmt.Printf("  🚫 Skipped: %s (%s)\n", filepath.Join(bf.inputDir, filePath), IgnoreReason)
+			} else if err := bf.validateSyntax(filePath, fix); err != nil {
+				fixResult.Success = false
+				fixResult.Error = err
+				fmt.Printf("  ✗ Rejected: %s (%v)\n", filepath.Join(bf.inputDir, filePath), err)
+			} else if found && bf.requeue(fix) {
+				requeued = append(requeued, requeuedIncident{
+					incident:   incident,
+					filePath:   filePath,
+					confidence: fix.Confidence,
+					cost:       costPerFix,
+					tokensUsed: tokensPerFix,
+				})
+				continue
+			} else if fix.Success {
 				// Check confidence threshold before applying
-				shouldApply, reason := bf.confidenceConf.ShouldApplyFix(fix.Confidence, v.MigrationComplexity, v.Effort)
+				shouldApply, reason := bf.confidenceConf.ShouldApplyFix(fix.Confidence, v.Category, v.MigrationComplexity, v.Effort)
 				fullPath := filepath.Join(bf.inputDir, filePath)
 
+				// Read what's on disk now, so earlier fixes to the same file are not in the diff
+				if original, err := os.ReadFile(fullPath); err == nil {
+					fixResult.OriginalContent = string(original)
+				}
+				fixResult.FixedContent = fix.FixedContent
+
 				if !shouldApply {
+					fixResult.LowConfidence = true
 					// Handle based on configured action
 					switch bf.confidenceConf.OnLowConfidence {
 					case confidence.ActionSkip:
@@ -221,10 +347,7 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 						fmt.Printf("    Applying anyway (action: warn-and-apply)\n")
 						// Write the fixed file if not dry-run
 						if !bf.dryRun {
-							if err := os.WriteFile(fullPath, []byte(fix.FixedContent), 0644); err != nil {
-								fixResult.Success = false
-								fixResult.Error = fmt.Errorf("failed to write file: %w", err)
-							}
+							writePath = fullPath
 						}
 
 					case confidence.ActionManualReviewFile:
@@ -232,28 +355,27 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 						fixResult.SkipReason = reason
 						fixResult.Success = false
 						// Write to manual review file - need incident info
-						// Find the matching incident for this fix
-						for _, incident := range result.job.incidents {
-							if incident.URI == fix.IncidentURI {
-								tmpFixer := &Fixer{inputDir: bf.inputDir}
-								if err := tmpFixer.writeToReviewFile(v, incident, &fixResult, reason, fix.Confidence); err != nil {
-									fmt.Printf("  ⚠ Failed to write to review file: %v\n", err)
-								} else {
-									fmt.Printf("  ⚠ Low confidence: %s\n", fullPath)
-									fmt.Printf("    Reason: %s\n", reason)
-									fmt.Printf("    Added to .kantra-ai-review.yaml for manual review\n")
-								}
-								break
+						if found {
+							tmpFixer := &Fixer{inputDir: bf.inputDir, confidenceConf: bf.confidenceConf}
+							if err := tmpFixer.writeToReviewFile(v, incident, &fixResult, reason, fix.Confidence); err != nil {
+								fmt.Printf("  ⚠ Failed to write to review file: %v\n", err)
+							} else {
+								fmt.Printf("  ⚠ Low confidence: %s\n", fullPath)
+								fmt.Printf("    Reason: %s\n", reason)
+								fmt.Printf("    Added to %s for manual review\n", tmpFixer.reviewFileName())
+							}
+							if patch, err := tmpFixer.writeReviewPatch(v, incident, &fixResult, reason, fix.Confidence); err != nil {
+								fmt.Printf("  ⚠ Failed to write review patch: %v\n", err)
+							} else {
+								fmt.Printf("    Proposed change: %s (git apply to accept)\n", patch)
 							}
 						}
 					}
 				} else {
 					// Confidence is good, apply the fix
+					bf.recordReusable(v.ID, result, fix, fullPath)
 					if !bf.dryRun {
-						if err := os.WriteFile(fullPath, []byte(fix.FixedContent), 0644); err != nil {
-							fixResult.Success = false
-							fixResult.Error = fmt.Errorf("failed to write file: %w", err)
-						}
+						writePath = fullPath
 					}
 				}
 			} else {
@@ -261,12 +383,195 @@ func (bf *BatchFixer) FixViolationBatch(ctx context.Context, v violation.Violati
 			}
 
 			allResults = append(allResults, fixResult)
+			if writePath != "" {
+				if _, ok := pendingWrites[writePath]; !ok {
+					pendingFiles = append(pendingFiles, writePath)
+				}
+				pendingWrites[writePath] = append(pendingWrites[writePath], len(allResults)-1)
+			}
+		}
+
+		for _, fullPath := range pendingFiles {
+			fixes := make([]*FixResult, 0, len(pendingWrites[fullPath]))
+			for _, i := range pendingWrites[fullPath] {
+				fixes = append(fixes, &allResults[i])
+			}
+			base, ok := result.fileContents[fullPath]
+			if !ok {
+				base = fixes[0].OriginalContent
+			}
+			applyFixes(fullPath, base, fixes)
 		}
 	}
 
+	allResults = append(allResults, bf.fixSecondPass(ctx, v, requeued)...)
+
+	if bf.config.RateTracker != nil {
+		fmt.Printf("   📈 Token rate: %s\n", bf.config.RateTracker)
+	}
+
 	return allResults, nil
 }
 
+// fixReusing fixes one incident of each group with identical surrounding
+// code in batches, then fixes the rest of each group by reusing that fix.
+// Incidents whose group's fix can't be reused are batched afterwards.
+func (bf *BatchFixer) fixReusing(ctx context.Context, v violation.Violation) ([]FixResult, error) {
+	first, rest := bf.groupIdenticalIncidents(v)
+	if len(rest) == 0 {
+		return bf.fixBatched(ctx, v)
+	}
+
+	firstOnly := v
+	firstOnly.Incidents = first
+	results, err := bf.fixBatched(ctx, firstOnly)
+	if err != nil {
+		return results, err
+	}
+
+	reuser := NewWithConfidence(bf.provider, bf.inputDir, bf.dryRun, bf.confidenceConf)
+	reuser.SetRateTracker(bf.config.RateTracker)
+	reuser.ignore = bf.ignore
+	reuser.reuse = bf.reuse
+	reuser.SetSyntaxCheck(bf.config.SyntaxCheck)
+
+	var leftover []violation.Incident
+	for _, incident := range rest {
+		content, err := os.ReadFile(bf.fullPath(incident))
+		if err != nil {
+			leftover = append(leftover, incident)
+			continue
+		}
+		if _, ok := bf.reuse.lookup(v.ID, string(content), incident.LineNumber); !ok {
+			leftover = append(leftover, incident)
+			continue
+		}
+		// Errors are reported in the result
+		result, _ := reuser.FixIncident(ctx, v, incident)
+		results = append(results, *result)
+	}
+
+	if len(leftover) > 0 {
+		rest := v
+		rest.Incidents = leftover
+		more, err := bf.fixBatched(ctx, rest)
+		results = append(results, more...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// groupIdenticalIncidents splits the violation's incidents into the first of
+// each group with identical surrounding code, and the rest
+func (bf *BatchFixer) groupIdenticalIncidents(v violation.Violation) (first, rest []violation.Incident) {
+	contents := make(map[string][]string) // Full path -> lines
+	seen := make(map[string]bool)
+	for _, incident := range v.Incidents {
+		path := bf.fullPath(incident)
+		lines, ok := contents[path]
+		if !ok {
+			if content, err := os.ReadFile(path); err == nil {
+				lines = strings.SplitAfter(string(content), "\n")
+			}
+			contents[path] = lines
+		}
+		key, _, _, ok := reuseKey(v.ID, lines, incident.LineNumber)
+		if !ok {
+			first = append(first, incident)
+			continue
+		}
+		if seen[key] {
+			rest = append(rest, incident)
+		} else {
+			seen[key] = true
+			first = append(first, incident)
+		}
+	}
+	return first, rest
+}
+
+// fullPath returns the path of the incident's file, or "" if it is outside
+// the input directory
+func (bf *BatchFixer) fullPath(incident violation.Incident) string {
+	relPath, err := resolveAndValidateFilePath(incident.GetFilePath(), bf.inputDir)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(bf.inputDir, relPath)
+}
+
+// recordReusable records a batched fix for reuse. Fixes are only recorded
+// when their incident is the only one in the batch for its file, so the
+// change can be attributed to it.
+func (bf *BatchFixer) recordReusable(violationID string, result batchResult, fix provider.IncidentFix, fullPath string) {
+	if bf.reuse == nil {
+		return
+	}
+	var incident *violation.Incident
+	for i := range result.job.incidents {
+		if result.job.incidents[i].URI != fix.IncidentURI {
+			continue
+		}
+		if incident != nil {
+			return
+		}
+		incident = &result.job.incidents[i]
+	}
+	if incident == nil {
+		return
+	}
+	base, ok := result.fileContents[fullPath]
+	if !ok {
+		content, err := os.ReadFile(fullPath)
+		if err != nil {
+			return
+		}
+		base = string(content)
+	}
+	bf.reuse.record(violationID, base, fix.FixedContent, incident.LineNumber, fix.Confidence, fix.Explanation)
+}
+
+// requeue reports whether a fix is successful but below SecondPassThreshold,
+// to be fixed again in the second pass
+func (bf *BatchFixer) requeue(fix provider.IncidentFix) bool {
+	return fix.Success && fix.Confidence < bf.config.SecondPassThreshold
+}
+
+// fixSecondPass fixes re-queued incidents one at a time. The results include
+// the cost of the batched fixes they replace, and go through the confidence
+// threshold like any other fix.
+func (bf *BatchFixer) fixSecondPass(ctx context.Context, v violation.Violation, requeued []requeuedIncident) []FixResult {
+	if len(requeued) == 0 {
+		return nil
+	}
+	fmt.Printf("  🔁 Second pass: fixing %d incident(s) below %.2f confidence individually\n",
+		len(requeued), bf.config.SecondPassThreshold)
+
+	careful := NewWithConfidence(bf.provider, bf.inputDir, bf.dryRun, bf.confidenceConf)
+	careful.SetRateTracker(bf.config.RateTracker)
+	careful.ignore = bf.ignore
+	careful.SetSyntaxCheck(bf.config.SyntaxCheck)
+	careful.reuse = bf.reuse
+
+	results := make([]FixResult, 0, len(requeued))
+	for _, r := range requeued {
+		result, err := careful.FixIncident(ctx, v, r.incident)
+		if result == nil {
+			result = &FixResult{ViolationID: v.ID, IncidentURI: r.incident.URI, LineNumber: r.incident.LineNumber, FilePath: r.filePath}
+		}
+		if err != nil && result.Error == nil {
+			result.Error = err
+		}
+		fmt.Printf("    %s:%d: confidence %.2f → %.2f\n", r.filePath, r.incident.LineNumber, r.confidence, result.Confidence)
+		result.Cost += r.cost
+		result.TokensUsed += r.tokensUsed
+		results = append(results, *result)
+	}
+	return results
+}
+
 // createBatches splits incidents into batches of max size
 // If GroupByFile is enabled, it groups incidents by file first to reduce token usage
 func (bf *BatchFixer) createBatches(v violation.Violation) []batchJob {
@@ -392,34 +697,41 @@ func (bf *BatchFixer) worker(ctx context.Context, jobs <-chan batchJob, results
 			return
 		default:
 			// Process the batch
-			fixes, cost, tokensUsed, err := bf.processBatch(ctx, job)
-			results <- batchResult{
-				job:        job,
-				fixes:      fixes,
-				cost:       cost,
-				tokensUsed: tokensUsed,
-				err:        err,
-			}
+			results <- bf.processBatch(ctx, job)
 		}
 	}
 }
 
 // processBatch sends a batch to the provider and gets fixes
-func (bf *BatchFixer) processBatch(ctx context.Context, job batchJob) ([]provider.IncidentFix, float64, int, error) {
+func (bf *BatchFixer) processBatch(ctx context.Context, job batchJob) batchResult {
+	fixes, cost, tokensUsed, fileContents, err := bf.requestBatch(ctx, job)
+	return batchResult{
+		job:          job,
+		fixes:        fixes,
+		cost:         cost,
+		tokensUsed:   tokensUsed,
+		err:          err,
+		fileContents: fileContents,
+	}
+}
+
+// requestBatch reads the batch's files, sends them to the provider and
+// returns its fixes along with the file contents they were generated from
+func (bf *BatchFixer) requestBatch(ctx context.Context, job batchJob) ([]provider.IncidentFix, float64, int, map[string]string, error) {
 	// Load file contents for all incidents
 	fileContents := make(map[string]string)
 	for _, incident := range job.incidents {
 		// Check for context cancellation before expensive I/O
 		select {
 		case <-ctx.Done():
-			return nil, 0, 0, ctx.Err()
+			return nil, 0, 0, nil, ctx.Err()
 		default:
 		}
 
 		// Resolve and validate file path (prevents path traversal)
 		filePath, err := resolveAndValidateFilePath(incident.GetFilePath(), bf.inputDir)
 		if err != nil {
-			return nil, 0, 0, fmt.Errorf("invalid file path: %w", err)
+			return nil, 0, 0, nil, fmt.Errorf("invalid file path: %w", err)
 		}
 
 		fullPath := filepath.Join(bf.inputDir, filePath)
@@ -427,7 +739,7 @@ func (bf *BatchFixer) processBatch(ctx context.Context, job batchJob) ([]provide
 		if _, exists := fileContents[fullPath]; !exists {
 			content, err := os.ReadFile(fullPath)
 			if err != nil {
-				return nil, 0, 0, fmt.Errorf("failed to read file %s: %w", fullPath, err)
+				return nil, 0, 0, nil, fmt.Errorf("failed to read file %s: %w", fullPath, err)
 			}
 			fileContents[fullPath] = string(content)
 		}
@@ -438,7 +750,7 @@ func (bf *BatchFixer) processBatch(ctx context.Context, job batchJob) ([]provide
 	if len(job.incidents) > 0 {
 		filePath, err := resolveAndValidateFilePath(job.incidents[0].GetFilePath(), bf.inputDir)
 		if err != nil {
-			return nil, 0, 0, fmt.Errorf("invalid file path: %w", err)
+			return nil, 0, 0, nil, fmt.Errorf("invalid file path: %w", err)
 		}
 		language = detectLanguage(filePath)
 	}
@@ -451,22 +763,111 @@ func (bf *BatchFixer) processBatch(ctx context.Context, job batchJob) ([]provide
 		Language:     language,
 	}
 
+	contents := make([]string, 0, len(fileContents))
+	for _, content := range fileContents {
+		contents = append(contents, content)
+	}
+	done, err := pace(ctx, bf.config.RateTracker, estimateRequestTokens(contents...))
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+
 	// Call provider
 	resp, err := bf.provider.FixBatch(ctx, req)
 	if err != nil {
-		return nil, 0, 0, err
+		return nil, 0, 0, nil, err
 	}
+	done(resp.TokensUsed)
 
 	// Note: resp.Success=false just means one or more fixes failed,
 	// not that the batch processing itself failed. We return the fixes
 	// as-is and let the caller handle individual successes/failures.
-	return resp.Fixes, resp.Cost, resp.TokensUsed, nil
+	return resp.Fixes, resp.Cost, resp.TokensUsed, fileContents, nil
+}
+
+// fileLocks holds a *sync.Mutex per file path written by applyFixes
+var fileLocks sync.Map
+
+// lockFile serializes writes to path across batches and fixers, returning
+// the unlock function
+func lockFile(path string) func() {
+	lock, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// applyFixes applies batched fixes to one file, all generated from base,
+// and writes the result once, atomically. Each fix's FixedContent is merged
+// into the content so far - including changes other batches made to the file
+// since base was read - rather than overwriting it. Each fix's
+// OriginalContent and FixedContent are set to the content before and after
+// it; fixes that conflict or can't be written fail.
+func applyFixes(fullPath, base string, fixes []*FixResult) {
+	unlock := lockFile(fullPath)
+	defer unlock()
+
+	fail := func(fix *FixResult, err error) {
+		fix.Success = false
+		fix.Error = fmt.Errorf("failed to write file: %w", err)
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		for _, fix := range fixes {
+			fail(fix, err)
+		}
+		return
+	}
+	current := string(data)
+
+	content := current
+	applied := make([]*FixResult, 0, len(fixes))
+	for _, fix := range fixes {
+		merged := fix.FixedContent
+		if content != base {
+			merged, err = MergeEdits(base, content, fix.FixedContent)
+			if err != nil {
+				fail(fix, fmt.Errorf("the fix conflicts with another fix to %s: %w", filepath.Base(fullPath), err))
+				continue
+			}
+		}
+		fix.OriginalContent = content
+		fix.FixedContent = merged
+		content = merged
+		applied = append(applied, fix)
+	}
+
+	if content == current {
+		return
+	}
+	info, err := os.Stat(fullPath)
+	if err == nil {
+		err = writeFileAtomic(fullPath, []byte(content), info.Mode().Perm())
+	}
+	if err != nil {
+		for _, fix := range applied {
+			fail(fix, err)
+		}
+	}
+}
+
+// validateSyntax checks a successful fix's content if SyntaxCheck is enabled
+func (bf *BatchFixer) validateSyntax(filePath string, fix provider.IncidentFix) error {
+	if !bf.config.SyntaxCheck || !fix.Success {
+		return nil
+	}
+	return ValidateSyntax(filePath, fix.FixedContent)
 }
 
 // fixSequential falls back to sequential processing when batching is disabled
 func (bf *BatchFixer) fixSequential(ctx context.Context, v violation.Violation) ([]FixResult, error) {
 	// Create a regular fixer and process sequentially
 	regularFixer := New(bf.provider, bf.inputDir, bf.dryRun)
+	regularFixer.SetRateTracker(bf.config.RateTracker)
+	regularFixer.ignore = bf.ignore
+	regularFixer.SetSyntaxCheck(bf.config.SyntaxCheck)
+	regularFixer.reuse = bf.reuse
 
 	results := make([]FixResult, 0, len(v.Incidents))
 	for _, incident := range v.Incidents {
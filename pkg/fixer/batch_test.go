@@ -6,7 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,6 +49,7 @@ func TestBatchFixer_FixViolationBatch_SingleBatch(t *testing.T) {
 					FixedContent: "class TestFixed {}",
 					Explanation:  "Fixed line 10",
 					Confidence:   0.9,
+					Extra:        map[string]interface{}{"risk_notes": "none"},
 				},
 				{
 					IncidentURI:  "file://" + testFile + ":20",
@@ -85,6 +89,8 @@ func TestBatchFixer_FixViolationBatch_SingleBatch(t *testing.T) {
 	assert.Equal(t, 100, results[1].TokensUsed)
 	assert.Equal(t, 0.05, results[0].Cost) // 0.10/2
 	assert.Equal(t, 0.05, results[1].Cost)
+	assert.Equal(t, map[string]interface{}{"risk_notes": "none"}, results[0].Extra)
+	assert.Nil(t, results[1].Extra)
 
 	mockProvider.AssertExpectations(t)
 }
@@ -458,6 +464,180 @@ func TestBatchFixer_Parallelism(t *testing.T) {
 	mockProvider.AssertExpectations(t)
 }
 
+func TestBatchFixer_SameFileAcrossParallelBatches(t *testing.T) {
+	const (
+		original = "import javax.persistence.Entity;\nimport javax.inject.Inject;\n\npublic class Order {}\n"
+		expected = "import jakarta.persistence.Entity;\nimport jakarta.inject.Inject;\n\npublic class Order {}\n"
+	)
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Order.java")
+	require.NoError(t, os.WriteFile(testFile, []byte(original), 0644))
+
+	// Both batches read the file before either fix is written, so each fix
+	// is based on the original content
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	waitForBoth := func(mock.Arguments) {
+		arrived.Done()
+		done := make(chan struct{})
+		go func() {
+			arrived.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}
+	forLine := func(line int) interface{} {
+		return mock.MatchedBy(func(req provider.BatchRequest) bool {
+			return len(req.Incidents) == 1 && req.Incidents[0].LineNumber == line
+		})
+	}
+	fixLine := func(line int, from, to string) *provider.BatchResponse {
+		lines := strings.Split(original, "\n")
+		lines[line-1] = strings.Replace(lines[line-1], from, to, 1)
+		return &provider.BatchResponse{
+			Fixes: []provider.IncidentFix{{
+				IncidentURI:  fmt.Sprintf("file://%s:%d", testFile, line),
+				Success:      true,
+				FixedContent: strings.Join(lines, "\n"),
+				Confidence:   0.9,
+			}},
+			Success: true,
+		}
+	}
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, forLine(1)).Run(waitForBoth).
+		Return(fixLine(1, "javax.persistence", "jakarta.persistence"), nil).Once()
+	mockProvider.On("FixBatch", mock.Anything, forLine(2)).Run(waitForBoth).
+		Return(fixLine(2, "javax.inject", "jakarta.inject"), nil).Once()
+
+	config := DefaultBatchConfig()
+	config.MaxBatchSize = 1
+	config.Parallelism = 2
+	config.GroupByFile = false
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{
+		ID: "javax-to-jakarta",
+		Incidents: []violation.Incident{
+			{URI: "file://" + testFile, LineNumber: 1},
+			{URI: "file://" + testFile, LineNumber: 2},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.True(t, result.Success, "%v", result.Error)
+	}
+
+	// Neither fix overwrote the other
+	content, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+	mockProvider.AssertExpectations(t)
+
+	// The second fix's diff only shows its own change
+	last := results[1]
+	assert.Equal(t, expected, last.FixedContent)
+	assert.NotEqual(t, original, last.OriginalContent)
+}
+
+func TestApplyFixes(t *testing.T) {
+	const base = "import a.B;\nimport a.C;\nimport a.D;\n"
+
+	t.Run("edits to one file are applied together", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "App.java")
+		require.NoError(t, os.WriteFile(testFile, []byte(base), 0755))
+
+		first := &FixResult{Success: true, FixedContent: "import x.B;\nimport a.C;\nimport a.D;\n"}
+		second := &FixResult{Success: true, FixedContent: "import a.B;\nimport a.C;\nimport x.D;\n"}
+		applyFixes(testFile, base, []*FixResult{first, second})
+
+		assert.True(t, first.Success)
+		assert.True(t, second.Success)
+		content, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, "import x.B;\nimport a.C;\nimport x.D;\n", string(content))
+
+		// Each fix's diff shows only its own change
+		assert.Equal(t, base, first.OriginalContent)
+		assert.Equal(t, first.FixedContent, second.OriginalContent)
+		assert.Equal(t, string(content), second.FixedContent)
+
+		// The file keeps its permissions and no temporary files are left
+		info, err := os.Stat(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		entries, err := os.ReadDir(filepath.Dir(testFile))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("a conflicting fix fails without affecting the others", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "App.java")
+		require.NoError(t, os.WriteFile(testFile, []byte(base), 0644))
+
+		first := &FixResult{Success: true, FixedContent: "import c.B;\nimport a.C;\nimport a.D;\n"}
+		conflicting := &FixResult{Success: true, FixedContent: "import d.B;\nimport a.C;\nimport a.D;\n"}
+		third := &FixResult{Success: true, FixedContent: "import a.B;\nimport c.C;\nimport a.D;\n"}
+		applyFixes(testFile, base, []*FixResult{first, conflicting, third})
+
+		assert.True(t, first.Success)
+		assert.False(t, conflicting.Success)
+		assert.ErrorIs(t, conflicting.Error, ErrMergeConflict)
+		assert.True(t, third.Success)
+
+		content, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, "import c.B;\nimport c.C;\nimport a.D;\n", string(content))
+	})
+
+	t.Run("a failed write leaves the file intact", func(t *testing.T) {
+		dir := t.TempDir()
+		testFile := filepath.Join(dir, "App.java")
+		require.NoError(t, os.WriteFile(testFile, []byte(base), 0644))
+
+		fix := &FixResult{Success: true, FixedContent: "import x.B;\nimport a.C;\nimport a.D;\n"}
+		require.NoError(t, os.Chmod(dir, 0555))
+		defer func() { _ = os.Chmod(dir, 0755) }()
+		if f, err := os.CreateTemp(dir, "probe"); err == nil {
+			f.Close()
+			t.Skip("directory permissions are not enforced (running as root)")
+		}
+		applyFixes(testFile, base, []*FixResult{fix})
+
+		assert.False(t, fix.Success)
+		assert.Error(t, fix.Error)
+		content, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, base, string(content))
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "App.java")
+	require.NoError(t, writeFileAtomic(path, []byte("class App {}\n"), 0640))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "class App {}\n", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// A rename that fails leaves no temporary file behind
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.MkdirAll(filepath.Join(target, "child"), 0755))
+	assert.Error(t, writeFileAtomic(target, []byte("x"), 0644))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
 func TestBatchFixer_FixViolationBatch_FileGrouping(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -823,3 +1003,93 @@ func TestMin(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchFixer_SecondPass(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{"A.java": "class A {}\n", "B.java": "class B {}\n", "C.java": "class C {}\n"}
+	var incidents []violation.Incident
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+	for _, name := range []string{"A.java", "B.java", "C.java"} {
+		incidents = append(incidents, violation.Incident{URI: "file://" + filepath.Join(tmpDir, name), LineNumber: 1})
+	}
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: incidents[0].URI, Success: true, FixedContent: "class A { /* batch */ }\n", Confidence: 0.95},
+				{IncidentURI: incidents[1].URI, Success: true, FixedContent: "class B { /* batch */ }\n", Confidence: 0.9},
+				{IncidentURI: incidents[2].URI, Success: true, FixedContent: "class C { /* weak */ }\n", Confidence: 0.5},
+			},
+			Success:    true,
+			Cost:       0.03,
+			TokensUsed: 300,
+		},
+		nil,
+	).Once()
+	mockProvider.On("FixViolation", mock.Anything, mock.MatchedBy(func(req provider.FixRequest) bool {
+		return req.Incident.URI == incidents[2].URI
+	})).Return(
+		&provider.FixResponse{Success: true, FixedContent: "class C { /* careful */ }\n", Confidence: 0.92, Cost: 0.02, TokensUsed: 200},
+		nil,
+	).Once()
+
+	config := DefaultBatchConfig()
+	config.GroupByFile = false
+	config.SecondPassThreshold = 0.8
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{ID: "rename", Incidents: incidents})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	mockProvider.AssertExpectations(t)
+
+	byFile := map[string]FixResult{}
+	for _, result := range results {
+		byFile[result.FilePath] = result
+	}
+	for _, name := range []string{"A.java", "B.java", "C.java"} {
+		assert.True(t, byFile[name].Success, name)
+	}
+	assert.Equal(t, 0.95, byFile["A.java"].Confidence)
+	assert.Equal(t, 0.92, byFile["C.java"].Confidence, "the weak fix is replaced by the second pass")
+	assert.InDelta(t, 0.03, byFile["C.java"].Cost, 1e-9, "the second pass includes the batched fix's cost")
+	assert.Equal(t, 300, byFile["C.java"].TokensUsed)
+
+	for name, want := range map[string]string{
+		"A.java": "class A { /* batch */ }\n",
+		"B.java": "class B { /* batch */ }\n",
+		"C.java": "class C { /* careful */ }\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), name)
+	}
+}
+
+func TestBatchFixer_SecondPassDisabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "A.java")
+	require.NoError(t, os.WriteFile(file, []byte("class A {}\n"), 0644))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes:   []provider.IncidentFix{{IncidentURI: "file://" + file, Success: true, FixedContent: "class A { /* weak */ }\n", Confidence: 0.5}},
+			Success: true,
+		},
+		nil,
+	).Once()
+
+	bf := NewBatchFixer(mockProvider, tmpDir, false, DefaultBatchConfig())
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{
+		ID:        "rename",
+		Incidents: []violation.Incident{{URI: "file://" + file, LineNumber: 1}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	mockProvider.AssertNotCalled(t, "FixViolation", mock.Anything, mock.Anything)
+}
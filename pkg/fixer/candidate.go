@@ -0,0 +1,51 @@
+package fixer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/provider"
+)
+
+// SetCandidateProvider makes the fixer ask a second provider for every fix
+// and merge both fixes line by line (see MergeCandidates): changes the two
+// providers agree on are applied, and each region where they diverge gets the
+// more confident provider's version and is flagged for review. A nil provider
+// disables the comparison.
+func (f *Fixer) SetCandidateProvider(p provider.Provider) {
+	f.candidate = p
+}
+
+// mergeCandidate asks the candidate provider to fix req and merges its fix
+// into result. If the candidate doesn't return a fix, result is kept as is.
+func (f *Fixer) mergeCandidate(ctx context.Context, req provider.FixRequest, result *FixResult) {
+	resp, err := f.requestFix(ctx, f.candidate, req)
+	if resp != nil {
+		result.Cost += resp.Cost
+		result.TokensUsed += resp.TokensUsed
+	}
+	if err == nil && !resp.Success {
+		err = resp.Error
+	}
+	if err != nil || !resp.Success {
+		slog.Warn("candidate provider returned no fix, keeping the primary fix", "provider", f.candidate.Name(), "file", result.FilePath, "error", err)
+		return
+	}
+
+	merged := MergeCandidates(result.OriginalContent, result.FixedContent, cleanResponse(resp.FixedContent), result.Confidence, resp.Confidence)
+	result.FixedContent = merged.Content
+	result.Confidence = merged.Confidence
+	result.Divergences = merged.Divergences
+}
+
+// divergenceReason describes the regions where the candidate fixes diverged,
+// for the manual review file
+func divergenceReason(divergences []Divergence) string {
+	lines := make([]string, len(divergences))
+	for i, d := range divergences {
+		lines[i] = d.Lines()
+	}
+	return fmt.Sprintf("providers' fixes diverge at lines %s; the more confident version was applied", strings.Join(lines, ", "))
+}
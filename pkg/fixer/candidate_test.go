@@ -0,0 +1,106 @@
+package fixer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/confidence"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	candidateOriginal = "package com.example;\n\nimport javax.persistence.Entity;\n\n@Entity\npublic class Order {\n    private Long id;\n}\n"
+	candidateFixedA   = "package com.example;\n\nimport jakarta.persistence.Entity;\n\n@Entity\npublic class Order {\n    private Long orderId;\n}\n"
+	candidateFixedB   = "package com.example;\n\nimport jakarta.persistence.Entity;\n\n@Entity\npublic class Order {\n    private Long id;\n}\n"
+)
+
+// newCandidateFixer returns a fixer for a copy of candidateOriginal whose
+// primary and candidate providers return the given responses
+func newCandidateFixer(t *testing.T, primary, candidate *provider.FixResponse, candidateErr error) (*Fixer, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Order.java"), []byte(candidateOriginal), 0644))
+
+	primaryProvider := new(MockProvider)
+	primaryProvider.On("FixViolation", mock.Anything, mock.Anything).Return(primary, nil)
+	candidateProvider := new(MockProvider)
+	candidateProvider.On("Name").Return("openai")
+	candidateProvider.On("FixViolation", mock.Anything, mock.Anything).Return(candidate, candidateErr)
+
+	confidenceConf := confidence.DefaultConfig()
+	confidenceConf.Enabled = true
+	confidenceConf.OnLowConfidence = confidence.ActionWarnAndApply
+	fixer := NewWithConfidence(primaryProvider, tmpDir, false, confidenceConf)
+	fixer.SetCandidateProvider(candidateProvider)
+	return fixer, tmpDir
+}
+
+func TestFixIncident_CandidateProvider(t *testing.T) {
+	v := violation.Violation{ID: "javax-to-jakarta", Category: "mandatory", MigrationComplexity: "trivial"}
+	incident := violation.Incident{URI: "file:///Order.java", LineNumber: 3}
+
+	t.Run("agreed changes applied and divergent region flagged", func(t *testing.T) {
+		fixer, tmpDir := newCandidateFixer(t,
+			&provider.FixResponse{Success: true, FixedContent: candidateFixedA, Confidence: 0.95, Cost: 0.02},
+			&provider.FixResponse{Success: true, FixedContent: candidateFixedB, Confidence: 0.9, Cost: 0.01}, nil)
+
+		result, err := fixer.FixIncident(context.Background(), v, incident)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.InDelta(t, 0.03, result.Cost, 1e-9, "both requests are paid for")
+		assert.Equal(t, 0.9, result.Confidence, "divergent merges use the lower confidence")
+
+		// The shared import migration and the more confident rename are written
+		content, err := os.ReadFile(filepath.Join(tmpDir, "Order.java"))
+		require.NoError(t, err)
+		assert.Equal(t, candidateFixedA, string(content))
+
+		require.Len(t, result.Divergences, 1)
+		assert.Equal(t, "7", result.Divergences[0].Lines())
+		assert.Equal(t, "a", result.Divergences[0].Chosen)
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, ReviewFileName))
+		require.NoError(t, err)
+		var reviews []ReviewItem
+		require.NoError(t, yaml.Unmarshal(data, &reviews))
+		require.Len(t, reviews, 1)
+		assert.Equal(t, "Order.java", reviews[0].FilePath)
+		assert.Contains(t, reviews[0].Reason, "diverge at lines 7")
+	})
+
+	t.Run("identical fixes are not flagged", func(t *testing.T) {
+		fixer, tmpDir := newCandidateFixer(t,
+			&provider.FixResponse{Success: true, FixedContent: candidateFixedB, Confidence: 0.85},
+			&provider.FixResponse{Success: true, FixedContent: candidateFixedB, Confidence: 0.95}, nil)
+
+		result, err := fixer.FixIncident(context.Background(), v, incident)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Empty(t, result.Divergences)
+		assert.Equal(t, 0.95, result.Confidence, "agreeing fixes use the higher confidence")
+		assert.NoFileExists(t, filepath.Join(tmpDir, ReviewFileName))
+	})
+
+	t.Run("failed candidate keeps the primary fix", func(t *testing.T) {
+		fixer, tmpDir := newCandidateFixer(t,
+			&provider.FixResponse{Success: true, FixedContent: candidateFixedA, Confidence: 0.95},
+			nil, errors.New("rate limited"))
+
+		result, err := fixer.FixIncident(context.Background(), v, incident)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Empty(t, result.Divergences)
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "Order.java"))
+		require.NoError(t, err)
+		assert.Equal(t, candidateFixedA, string(content))
+	})
+}
@@ -0,0 +1,90 @@
+package fixer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultDiffContext is the number of unchanged lines shown around each change
+const DefaultDiffContext = 3
+
+// UnifiedDiff returns a unified diff (as produced by "diff -u") that turns
+// original into updated, with contextLines lines of context around each
+// change. The result is empty if the contents are identical.
+func UnifiedDiff(path, original, updated string, contextLines int) string {
+	oldLines := splitLines(original)
+	newLines := splitLines(updated)
+
+	edits := diffLines(oldLines, newLines)
+	if len(edits) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- a/%s\n+++ b/%s\n", path, path)
+
+	offset := 0 // Lines added minus lines removed by edits before the current hunk
+	for i := 0; i < len(edits); {
+		// Group edits whose context would overlap into one hunk
+		j := i + 1
+		for j < len(edits) && edits[j].start-edits[j-1].end <= 2*contextLines {
+			j++
+		}
+
+		oldStart := max(0, edits[i].start-contextLines)
+		oldEnd := min(len(oldLines), edits[j-1].end+contextLines)
+
+		var body strings.Builder
+		pos := oldStart
+		newCount := 0
+		for _, edit := range edits[i:j] {
+			for ; pos < edit.start; pos++ {
+				body.WriteString(" " + oldLines[pos] + "\n")
+				newCount++
+			}
+			for _, line := range oldLines[edit.start:edit.end] {
+				body.WriteString("-" + line + "\n")
+			}
+			for _, line := range edit.lines {
+				body.WriteString("+" + line + "\n")
+			}
+			newCount += len(edit.lines)
+			pos = edit.end
+		}
+		for ; pos < oldEnd; pos++ {
+			body.WriteString(" " + oldLines[pos] + "\n")
+			newCount++
+		}
+
+		oldCount := oldEnd - oldStart
+		newStart := oldStart + offset
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		buf.WriteString(body.String())
+
+		offset += newCount - oldCount
+		i = j
+	}
+
+	return buf.String()
+}
+
+// hunkRange formats a 0-based start and line count as a hunk header range.
+// Empty ranges refer to the line before the change, as in diff -u.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// TruncateDiff limits a diff to maxLines lines, noting how many were dropped
+func TruncateDiff(diff string, maxLines int) string {
+	lines := strings.SplitAfter(diff, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return diff
+	}
+	return strings.Join(lines[:maxLines], "") + fmt.Sprintf("... (%d more lines)\n", len(lines)-maxLines)
+}
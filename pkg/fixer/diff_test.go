@@ -0,0 +1,66 @@
+package fixer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("identical content", func(t *testing.T) {
+		assert.Empty(t, UnifiedDiff("A.java", "a\nb\n", "a\nb\n", DefaultDiffContext))
+	})
+
+	t.Run("single change", func(t *testing.T) {
+		original := "package com.example;\n\nimport javax.servlet.Servlet;\n\npublic class A {}\n"
+		updated := "package com.example;\n\nimport jakarta.servlet.Servlet;\n\npublic class A {}\n"
+
+		diff := UnifiedDiff("src/A.java", original, updated, 1)
+
+		assert.Equal(t, "--- a/src/A.java\n+++ b/src/A.java\n"+
+			"@@ -2,3 +2,3 @@\n"+
+			" \n"+
+			"-import javax.servlet.Servlet;\n"+
+			"+import jakarta.servlet.Servlet;\n"+
+			" \n", diff)
+	})
+
+	t.Run("distant changes produce separate hunks", func(t *testing.T) {
+		var lines []string
+		for i := 1; i <= 30; i++ {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+		original := strings.Join(lines, "\n") + "\n"
+		lines[2] = "changed"
+		lines = append(lines[:25], append([]string{"inserted"}, lines[25:]...)...)
+		updated := strings.Join(lines, "\n") + "\n"
+
+		diff := UnifiedDiff("f.txt", original, updated, DefaultDiffContext)
+
+		assert.Equal(t, 2, strings.Count(diff, "@@ -"))
+		assert.Contains(t, diff, "@@ -1,6 +1,6 @@\n line 1\n line 2\n-line 3\n+changed\n")
+		assert.Contains(t, diff, "@@ -23,6 +23,7 @@\n line 23\n line 24\n line 25\n+inserted\n line 26\n")
+	})
+
+	t.Run("round trips through ApplyUnifiedDiff", func(t *testing.T) {
+		original := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+		updated := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk2\nk3\nl\nm\n"
+
+		diff := UnifiedDiff("f.txt", original, updated, DefaultDiffContext)
+
+		patched, err := ApplyUnifiedDiff(original, diff)
+		require.NoError(t, err)
+		assert.Equal(t, updated, patched)
+	})
+}
+
+func TestTruncateDiff(t *testing.T) {
+	diff := "l1\nl2\nl3\nl4\n"
+
+	assert.Equal(t, diff, TruncateDiff(diff, 4))
+	assert.Equal(t, diff, TruncateDiff(diff, 0))
+	assert.Equal(t, "l1\nl2\n... (2 more lines)\n", TruncateDiff(diff, 2))
+}
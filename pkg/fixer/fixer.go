@@ -12,6 +12,7 @@
 //   - Reads source files and extracts violation context
 //   - Calls the AI provider to generate a fix
 //   - Validates the AI's confidence score against thresholds
+//   - Optionally rejects fixes whose content doesn't parse (SetSyntaxCheck)
 //   - Applies fixes to disk or writes to a review file
 //   - Supports dry-run mode for preview
 //
@@ -41,6 +42,9 @@
 // are resolved relative to the input directory and checked to ensure
 // they don't escape the project boundary.
 //
+// Files matching a .kantra-ai-ignore file in the input directory (gitignore
+// syntax) are never written; their fixes are skipped with IgnoreReason.
+//
 // # Example Usage
 //
 //	// Single-fix mode
@@ -55,6 +59,7 @@ package fixer
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -77,6 +82,12 @@ type Fixer struct {
 	inputDir       string
 	dryRun         bool
 	confidenceConf confidence.Config
+	rateTracker    *TPMTracker       // Optional: paces requests under a tokens-per-minute limit
+	ignore         *IgnoreList       // Files that must never be written (.kantra-ai-ignore)
+	approver       Approver          // Optional: reviews each fix before it is written
+	syntaxCheck    bool              // Reject fixes whose content doesn't parse (see SetSyntaxCheck)
+	reuse          *reuseCache       // Optional: fixes reused for identical incidents (see SetReuseIdenticalFixes)
+	candidate      provider.Provider // Optional: second provider whose fixes are merged in (see SetCandidateProvider)
 }
 
 // New creates a new Fixer
@@ -86,9 +97,16 @@ func New(provider provider.Provider, inputDir string, dryRun bool) *Fixer {
 		inputDir:       inputDir,
 		dryRun:         dryRun,
 		confidenceConf: confidence.DefaultConfig(),
+		ignore:         loadIgnoreList(inputDir),
 	}
 }
 
+// SetRateTracker makes the fixer record its token usage in tracker and wait
+// before requests that would exceed the tracker's tokens-per-minute limit
+func (f *Fixer) SetRateTracker(tracker *TPMTracker) {
+	f.rateTracker = tracker
+}
+
 // NewWithConfidence creates a new Fixer with confidence configuration
 func NewWithConfidence(provider provider.Provider, inputDir string, dryRun bool, confidenceConf confidence.Config) *Fixer {
 	return &Fixer{
@@ -96,6 +114,7 @@ func NewWithConfidence(provider provider.Provider, inputDir string, dryRun bool,
 		inputDir:       inputDir,
 		dryRun:         dryRun,
 		confidenceConf: confidenceConf,
+		ignore:         loadIgnoreList(inputDir),
 	}
 }
 
@@ -103,6 +122,7 @@ func NewWithConfidence(provider provider.Provider, inputDir string, dryRun bool,
 type FixResult struct {
 	ViolationID       string
 	IncidentURI       string
+	LineNumber        int     // Line of the incident; with IncidentURI, identifies it
 	FilePath          string  // Relative file path for git tracking
 	Success           bool
 	Cost              float64
@@ -111,7 +131,23 @@ type FixResult struct {
 	Explanation       string
 	Confidence        float64 // AI confidence score (0.0-1.0)
 	SkippedLowConfidence bool    // True if skipped due to low confidence
+	LowConfidence     bool    // True if below the confidence threshold (skipped, queued for review or applied with a warning)
 	SkipReason        string  // Reason for skipping
+	SkippedIgnored    bool    // True if skipped because the file matches .kantra-ai-ignore
+	SkippedByUser     bool    // True if skipped (or quit) during interactive review
+	Extra             map[string]interface{} // Configured extra fields from the AI response (nil if none)
+	OriginalContent   string  // File content before the fix (set when the provider returned a fix)
+	FixedContent      string  // File content after the fix
+	Provider          string  // Provider that produced the fix when a fallback chain is configured
+	Divergences       []Divergence // Regions where the candidate provider's fix differed (see SetCandidateProvider)
+}
+
+// Diff returns a unified diff of the fix, or "" if no content is available
+func (r *FixResult) Diff(contextLines int) string {
+	if r.FixedContent == "" {
+		return ""
+	}
+	return UnifiedDiff(r.FilePath, r.OriginalContent, r.FixedContent, contextLines)
 }
 
 // FixIncident fixes a single incident of a violation
@@ -119,6 +155,7 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 	result := &FixResult{
 		ViolationID: v.ID,
 		IncidentURI: incident.URI,
+		LineNumber:  incident.LineNumber,
 	}
 
 	// Get the file path and validate it
@@ -137,6 +174,14 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 	// Build full path for file operations
 	fullPath := filepath.Join(f.inputDir, cleanPath)
 
+	// Never touch files listed in .kantra-ai-ignore
+	if f.ignore.Match(cleanPath) {
+		result.SkippedIgnored = true
+		result.SkipReason = IgnoreReason
+		fmt.Printf("  🚫 Skipped: %s (%s)\n", fullPath, IgnoreReason)
+		return result, nil
+	}
+
 	// Read the current file content
 	fileContent, err := os.ReadFile(fullPath)
 	if err != nil {
@@ -164,11 +209,46 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 		Language:    language,
 	}
 
-	// Get the fix from AI provider
-	resp, err := f.provider.FixViolation(ctx, req)
-	if err != nil {
-		result.Error = err
-		return result, err
+	// Reuse the fix of an incident in identical code, or get one from the AI provider
+	resp, reused := f.reuse.lookup(v.ID, req.FileContent, incident.LineNumber)
+	if reused {
+		fmt.Println(reuseNotice())
+	} else {
+		resp, err = f.requestFix(ctx, f.provider, req)
+		if err != nil {
+			if resp != nil {
+				result.Cost = resp.Cost
+				result.TokensUsed = resp.TokensUsed
+			}
+			result.Error = err
+			return result, err
+		}
+	}
+
+	// Ask again for fixes whose content doesn't parse
+	var syntaxErr error
+	for attempt := 0; f.syntaxCheck && resp.Success; attempt++ {
+		syntaxErr = ValidateSyntax(cleanPath, cleanResponse(resp.FixedContent))
+		if syntaxErr == nil || attempt == SyntaxRetries {
+			break
+		}
+		fmt.Printf("  ⚠ %v, requesting another fix\n", syntaxErr)
+		retryResp, err := f.requestFix(ctx, f.provider, req)
+		if retryResp != nil {
+			// Account for every request
+			retryResp.Cost += resp.Cost
+			retryResp.TokensUsed += resp.TokensUsed
+		}
+		if err != nil {
+			if retryResp != nil {
+				resp = retryResp
+			}
+			result.Cost = resp.Cost
+			result.TokensUsed = resp.TokensUsed
+			result.Error = err
+			return result, err
+		}
+		resp = retryResp
 	}
 
 	result.Success = resp.Success
@@ -176,15 +256,37 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 	result.TokensUsed = resp.TokensUsed
 	result.Explanation = resp.Explanation
 	result.Confidence = resp.Confidence
+	result.Extra = resp.Extra
+	result.Provider = resp.Provider
 
 	if !resp.Success {
 		result.Error = resp.Error
 		return result, resp.Error
 	}
 
+	// Clean up the response (remove markdown code blocks if present)
+	result.OriginalContent = string(fileContent)
+	result.FixedContent = cleanResponse(resp.FixedContent)
+
+	// Merge in a second provider's fix, checking the merged content again
+	if f.candidate != nil && !reused && syntaxErr == nil {
+		f.mergeCandidate(ctx, req, result)
+		if f.syntaxCheck {
+			syntaxErr = ValidateSyntax(cleanPath, result.FixedContent)
+		}
+	}
+
+	if syntaxErr != nil {
+		result.Success = false
+		result.Error = syntaxErr
+		fmt.Printf("  ✗ Rejected: %s (%v)\n", fullPath, syntaxErr)
+		return result, syntaxErr
+	}
+
 	// Check confidence threshold before applying fix
-	shouldApply, reason := f.confidenceConf.ShouldApplyFix(resp.Confidence, v.MigrationComplexity, v.Effort)
+	shouldApply, reason := f.confidenceConf.ShouldApplyFix(result.Confidence, v.Category, v.MigrationComplexity, v.Effort)
 	if !shouldApply {
+		result.LowConfidence = true
 		// Handle based on configured action
 		switch f.confidenceConf.OnLowConfidence {
 		case confidence.ActionSkip:
@@ -193,7 +295,7 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 			result.Success = false
 			fmt.Printf("  ⚠ Skipped: %s\n", fullPath)
 			fmt.Printf("    Reason: %s\n", reason)
-			fmt.Printf("    To force: --enable-confidence=false or --min-confidence=%.2f\n", resp.Confidence)
+			fmt.Printf("    To force: --enable-confidence=false or --min-confidence=%.2f\n", result.Confidence)
 			return result, nil
 
 		case confidence.ActionWarnAndApply:
@@ -208,25 +310,68 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 			result.SkipReason = reason
 			result.Success = false
 			// Write to manual review file
-			if err := f.writeToReviewFile(v, incident, result, reason, resp.Confidence); err != nil {
+			if err := f.writeToReviewFile(v, incident, result, reason, result.Confidence); err != nil {
 				fmt.Printf("  ⚠ Failed to write to review file: %v\n", err)
 			} else {
 				fmt.Printf("  ⚠ Low confidence: %s\n", fullPath)
 				fmt.Printf("    Reason: %s\n", reason)
-				fmt.Printf("    Added to %s for manual review\n", ReviewFileName)
+				fmt.Printf("    Added to %s for manual review\n", f.reviewFileName())
+			}
+			if patch, err := f.writeReviewPatch(v, incident, result, reason, result.Confidence); err != nil {
+				fmt.Printf("  ⚠ Failed to write review patch: %v\n", err)
+			} else {
+				fmt.Printf("    Proposed change: %s (git apply to accept)\n", patch)
 			}
 			return result, nil
 		}
 	}
 
-	// Clean up the response (remove markdown code blocks if present)
-	fixedContent := cleanResponse(resp.FixedContent)
+	// Let the user review the fix before it is written
+	if f.approver != nil {
+		decision, err := f.approver.Review(v, incident, result)
+		if err != nil {
+			result.Success = false
+			result.Error = err
+			return result, err
+		}
+		switch decision {
+		case DecisionSkip:
+			result.Success = false
+			result.SkippedByUser = true
+			result.SkipReason = UserSkipReason
+			fmt.Printf("  ⏭ Skipped: %s\n", fullPath)
+			return result, nil
+		case DecisionQuit:
+			result.Success = false
+			result.SkippedByUser = true
+			result.SkipReason = UserSkipReason
+			return result, ErrReviewQuit
+		}
+	}
+
+	// Flag the regions the providers disagreed on for review
+	if len(result.Divergences) > 0 {
+		reason := divergenceReason(result.Divergences)
+		fmt.Printf("  ⚠ Review: %s (%s)\n", fullPath, reason)
+		if err := f.writeToReviewFile(v, incident, result, reason, result.Confidence); err != nil {
+			fmt.Printf("  ⚠ Failed to write to review file: %v\n", err)
+		}
+	}
+
+	fixedContent := result.FixedContent
+	if !reused {
+		f.reuse.record(v.ID, result.OriginalContent, fixedContent, incident.LineNumber, result.Confidence, result.Explanation)
+	}
 
 	// Apply the fix (or just log if dry-run)
 	if f.dryRun {
 		fmt.Printf("  [DRY-RUN] Would write %d bytes to %s\n", len(fixedContent), fullPath)
 	} else {
-		if err := os.WriteFile(fullPath, []byte(fixedContent), 0644); err != nil {
+		perm := os.FileMode(0644)
+		if info, err := os.Stat(fullPath); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if err := writeFileAtomic(fullPath, []byte(fixedContent), perm); err != nil {
 			result.Error = fmt.Errorf("failed to write file '%s': %w\n\n"+
 				"Possible causes:\n"+
 				"  - Insufficient write permissions\n"+
@@ -246,6 +391,54 @@ func (f *Fixer) FixIncident(ctx context.Context, v violation.Violation, incident
 	return result, nil
 }
 
+// requestFix gets a fix from p. Diff responses are applied to the
+// file content, falling back to a full-content request if the patch doesn't
+// apply cleanly. On error, the response returned (if any) holds the cost of
+// the requests made.
+func (f *Fixer) requestFix(ctx context.Context, p provider.Provider, req provider.FixRequest) (*provider.FixResponse, error) {
+	resp, err := f.fixViolation(ctx, p, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success || resp.Patch == "" {
+		return resp, nil
+	}
+
+	patched, patchErr := ApplyUnifiedDiff(req.FileContent, resp.Patch)
+	if patchErr == nil {
+		resp.FixedContent = patched
+		return resp, nil
+	}
+
+	slog.Warn("patch did not apply cleanly, retrying with full file content", "file", req.Incident.GetFilePath(), "error", patchErr)
+	req.ResponseFormat = provider.ResponseFormatFull
+	fullResp, err := f.fixViolation(ctx, p, req)
+	if err != nil {
+		return resp, err
+	}
+	// Account for both requests
+	fullResp.Cost += resp.Cost
+	fullResp.TokensUsed += resp.TokensUsed
+	return fullResp, nil
+}
+
+// EstimateRequest returns the fix request FixIncident would send for
+// incident, for cost estimates before fixing. The file content is left empty
+// if the file can't be read.
+func EstimateRequest(inputDir string, v violation.Violation, incident violation.Incident) provider.FixRequest {
+	req := provider.FixRequest{
+		Violation: v,
+		Incident:  incident,
+		Language:  detectLanguage(incident.GetFilePath()),
+	}
+	if cleanPath, err := resolveAndValidateFilePath(incident.GetFilePath(), inputDir); err == nil {
+		if content, err := os.ReadFile(filepath.Join(inputDir, cleanPath)); err == nil {
+			req.FileContent = string(content)
+		}
+	}
+	return req
+}
+
 // detectLanguage detects programming language from file extension
 func detectLanguage(filePath string) string {
 	ext := filepath.Ext(filePath)
@@ -268,6 +461,8 @@ func detectLanguage(filePath string) string {
 		return "ruby"
 	case ".xml":
 		return "xml"
+	case ".json":
+		return "json"
 	case ".yaml", ".yml":
 		return "yaml"
 	case ".css":
@@ -310,13 +505,21 @@ type ReviewItem struct {
 	Complexity   string  `yaml:"complexity,omitempty"`
 }
 
+// reviewFileName returns the manual review file name, relative to the input directory
+func (f *Fixer) reviewFileName() string {
+	if f.confidenceConf.ReviewFile != "" {
+		return f.confidenceConf.ReviewFile
+	}
+	return ReviewFileName
+}
+
 // writeToReviewFile appends a low-confidence fix to the manual review file
 // Uses atomic write-rename pattern to prevent corruption from concurrent writes
 func (f *Fixer) writeToReviewFile(v violation.Violation, incident violation.Incident, result *FixResult, reason string, confidenceScore float64) error {
 	reviewFileMutex.Lock()
 	defer reviewFileMutex.Unlock()
 
-	reviewPath := filepath.Join(f.inputDir, ReviewFileName)
+	reviewPath := filepath.Join(f.inputDir, f.reviewFileName())
 
 	// Load existing reviews if file exists
 	var reviews []ReviewItem
@@ -73,6 +73,24 @@ func TestDetectLanguage(t *testing.T) {
 	}
 }
 
+func TestEstimateRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "App.java"), []byte("import javax.ejb.Stateless;\n"), 0644))
+	v := violation.Violation{ID: "v1"}
+
+	req := EstimateRequest(tmpDir, v, violation.Incident{URI: "file:///src/App.java", LineNumber: 1})
+	assert.Equal(t, "v1", req.Violation.ID)
+	assert.Equal(t, "java", req.Language)
+	assert.Equal(t, "import javax.ejb.Stateless;\n", req.FileContent)
+
+	t.Run("unreadable file leaves the content empty", func(t *testing.T) {
+		req := EstimateRequest(tmpDir, v, violation.Incident{URI: "file:///src/Missing.java"})
+		assert.Empty(t, req.FileContent)
+		assert.Equal(t, "java", req.Language)
+	})
+}
+
 func TestCleanResponse(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -196,6 +214,29 @@ func TestFixer_FixIncident(t *testing.T) {
 		mockProvider.AssertExpectations(t)
 	})
 
+	t.Run("extra response fields are passed through", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		testFile := filepath.Join(tmpDir, "test.java")
+		require.NoError(t, os.WriteFile(testFile, []byte("import javax.servlet.*;"), 0644))
+
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(&provider.FixResponse{
+			Success:      true,
+			FixedContent: "import jakarta.servlet.*;",
+			Confidence:   0.9,
+			Extra:        map[string]interface{}{"risk_notes": "servlet API version bump"},
+		}, nil)
+
+		fixer := New(mockProvider, tmpDir, true)
+		result, err := fixer.FixIncident(context.Background(),
+			violation.Violation{ID: "test-violation"},
+			violation.Incident{URI: "file://" + testFile, LineNumber: 1})
+
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, "servlet API version bump", result.Extra["risk_notes"])
+	})
+
 	t.Run("dry-run mode doesn't write file", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		testFile := filepath.Join(tmpDir, "test.py")
@@ -381,6 +422,20 @@ func TestWriteToReviewFile(t *testing.T) {
 		assert.Equal(t, "medium", reviews[0].Complexity)
 	})
 
+	t.Run("uses configured review file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		confidenceConf := confidence.DefaultConfig()
+		confidenceConf.ReviewFile = ".kantra-ai-review-exp1.yaml"
+		fixer := NewWithConfidence(new(MockProvider), tmpDir, false, confidenceConf)
+
+		v := violation.Violation{ID: "test-001"}
+		err := fixer.writeToReviewFile(v, violation.Incident{LineNumber: 1}, &FixResult{FilePath: "src/test.java"}, "Confidence too low", 0.5)
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(tmpDir, ".kantra-ai-review-exp1.yaml"))
+		assert.NoFileExists(t, filepath.Join(tmpDir, ReviewFileName))
+	})
+
 	t.Run("appends to existing review file", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		mockProvider := new(MockProvider)
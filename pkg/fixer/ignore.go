@@ -0,0 +1,139 @@
+package fixer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+const (
+	// IgnoreFileName is the name of the file, in the input directory, listing
+	// files that must never be modified (gitignore syntax)
+	IgnoreFileName = ".kantra-ai-ignore"
+
+	// IgnoreReason is the skip reason recorded for fixes to ignored files
+	IgnoreReason = "ignored by " + IgnoreFileName
+)
+
+// IgnoreList matches paths against .kantra-ai-ignore rules. Unlike path
+// filters, which decide which incidents are sent to the model, the ignore
+// list is a guardrail checked before any file is written.
+//
+// The syntax is that of .gitignore: blank lines and lines starting with '#'
+// are ignored, '!' re-includes a previously ignored path, a trailing '/'
+// matches directories only, a pattern containing '/' is relative to the
+// input directory, other patterns match at any depth, and '**' matches any
+// number of directories. As in git, a file inside an ignored directory can't
+// be re-included.
+type IgnoreList struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern line of an ignore file
+type ignoreRule struct {
+	pattern string // Slash-separated glob, relative to the input directory
+	negate  bool   // '!' rule: re-include matching paths
+	dirOnly bool   // Trailing '/': only matches directories
+}
+
+// ParseIgnoreList parses rules in gitignore syntax
+func ParseIgnoreList(r io.Reader) (*IgnoreList, error) {
+	list := &IgnoreList{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns without a slash match at any depth
+		if strings.HasPrefix(line, "/") {
+			line = strings.TrimLeft(line, "/")
+		} else if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		rule.pattern = line
+		list.rules = append(list.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	return list, nil
+}
+
+// LoadIgnoreList loads inputDir/.kantra-ai-ignore. It returns nil if the
+// file doesn't exist.
+func LoadIgnoreList(inputDir string) (*IgnoreList, error) {
+	file, err := os.Open(filepath.Join(inputDir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", IgnoreFileName, err)
+	}
+	defer file.Close()
+	return ParseIgnoreList(file)
+}
+
+// loadIgnoreList loads the ignore file for a fixer. An ignore file that
+// exists but can't be read ignores everything, so the guardrail fails closed.
+func loadIgnoreList(inputDir string) *IgnoreList {
+	list, err := LoadIgnoreList(inputDir)
+	if err != nil {
+		fmt.Printf("  ⚠ %v; no files will be modified\n", err)
+		return &IgnoreList{rules: []ignoreRule{{pattern: "**"}}}
+	}
+	return list
+}
+
+// Match reports whether a file, relative to the input directory, is ignored.
+// A nil list ignores nothing.
+func (l *IgnoreList) Match(relPath string) bool {
+	if l == nil || len(l.rules) == 0 {
+		return false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(filepath.ToSlash(relPath), "./"), "/")
+
+	// Files in an ignored directory are ignored whatever later rules say
+	for i := 1; i < len(segments); i++ {
+		if l.matchPath(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return l.matchPath(strings.Join(segments, "/"), false)
+}
+
+// matchPath applies the rules to one path; the last matching rule wins
+func (l *IgnoreList) matchPath(p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range l.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if violation.MatchGlob(rule.pattern, p) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,157 @@
+package fixer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestIgnoreList_Match(t *testing.T) {
+	rules := `
+# Vendored code
+vendor/
+/generated/
+*.pb.go
+docs/**/*.md
+!keep.pb.go
+!vendor/patched.go
+\#literal
+`
+	list, err := ParseIgnoreList(strings.NewReader(rules))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"vendor/lib/a.go", true},
+		{"src/vendor/lib/a.go", true},
+		{"vendor/patched.go", true}, // can't re-include inside an ignored directory
+		{"vendor.go", false},
+		{"generated/api.go", true},
+		{"src/generated/api.go", false}, // leading slash anchors to the input directory
+		{"api/service.pb.go", true},
+		{"service.pb.go", true},
+		{"api/keep.pb.go", false},
+		{"docs/guide.md", true},
+		{"docs/a/b/guide.md", true},
+		{"README.md", false},
+		{"#literal", true},
+		{"src/main/java/App.java", false},
+		{"./vendor/x.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, list.Match(tt.path))
+		})
+	}
+}
+
+func TestIgnoreList_NilMatchesNothing(t *testing.T) {
+	var list *IgnoreList
+	assert.False(t, list.Match("vendor/a.go"))
+}
+
+func TestLoadIgnoreList(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		list, err := LoadIgnoreList(t.TempDir())
+		require.NoError(t, err)
+		assert.Nil(t, list)
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, IgnoreFileName), []byte("gen/\n"), 0644))
+
+		list, err := LoadIgnoreList(tmpDir)
+		require.NoError(t, err)
+		assert.True(t, list.Match("gen/a.go"))
+		assert.False(t, list.Match("src/a.go"))
+	})
+}
+
+func TestFixer_FixIncident_Ignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, IgnoreFileName), []byte("*.pb.go\n"), 0644))
+	testFile := filepath.Join(tmpDir, "api.pb.go")
+	require.NoError(t, os.WriteFile(testFile, []byte("package api"), 0644))
+
+	mockProvider := new(MockProvider)
+	fixer := New(mockProvider, tmpDir, false)
+
+	v := violation.Violation{ID: "test-violation"}
+	result, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + testFile, LineNumber: 1})
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.True(t, result.SkippedIgnored)
+	assert.Equal(t, IgnoreReason, result.SkipReason)
+	assert.Equal(t, "api.pb.go", result.FilePath)
+
+	content, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, "package api", string(content))
+
+	// The file is never sent to the model
+	mockProvider.AssertNotCalled(t, "FixViolation", mock.Anything, mock.Anything)
+}
+
+func TestBatchFixer_FixViolationBatch_Ignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, IgnoreFileName), []byte("vendor/\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "vendor"), 0755))
+	vendored := filepath.Join(tmpDir, "vendor", "lib.java")
+	own := filepath.Join(tmpDir, "App.java")
+	require.NoError(t, os.WriteFile(vendored, []byte("class Lib {}"), 0644))
+	require.NoError(t, os.WriteFile(own, []byte("class App {}"), 0644))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file://" + vendored, Success: true, FixedContent: "class LibFixed {}", Confidence: 0.9},
+				{IncidentURI: "file://" + own, Success: true, FixedContent: "class AppFixed {}", Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	)
+
+	config := DefaultBatchConfig()
+	config.GroupByFile = false
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	v := violation.Violation{
+		ID: "test-violation",
+		Incidents: []violation.Incident{
+			{URI: "file://" + vendored, LineNumber: 1},
+			{URI: "file://" + own, LineNumber: 1},
+		},
+	}
+
+	results, err := bf.FixViolationBatch(context.Background(), v)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.False(t, results[0].Success)
+	assert.True(t, results[0].SkippedIgnored)
+	assert.Equal(t, IgnoreReason, results[0].SkipReason)
+	assert.True(t, results[1].Success)
+
+	content, err := os.ReadFile(vendored)
+	require.NoError(t, err)
+	assert.Equal(t, "class Lib {}", string(content))
+
+	content, err = os.ReadFile(own)
+	require.NoError(t, err)
+	assert.Equal(t, "class AppFixed {}", string(content))
+}
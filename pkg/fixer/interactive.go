@@ -0,0 +1,145 @@
+package fixer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// Decision is the outcome of reviewing a proposed fix
+type Decision int
+
+const (
+	// DecisionApply writes the fix (with any edits made during review)
+	DecisionApply Decision = iota
+	// DecisionSkip leaves the file unchanged
+	DecisionSkip
+	// DecisionQuit leaves the file unchanged and stops fixing
+	DecisionQuit
+)
+
+// ErrReviewQuit is returned by FixIncident when the user quits during
+// interactive review
+var ErrReviewQuit = errors.New("quit during interactive review")
+
+// UserSkipReason is the skip reason of fixes skipped during interactive review
+const UserSkipReason = "skipped during interactive review"
+
+// Approver reviews each fix before it is written. It may change
+// result.FixedContent, e.g. when the user edits the proposed content.
+type Approver interface {
+	Review(v violation.Violation, incident violation.Incident, result *FixResult) (Decision, error)
+}
+
+// SetApprover makes the fixer ask approver before writing each fix
+func (f *Fixer) SetApprover(approver Approver) {
+	f.approver = approver
+}
+
+// InteractiveApprover shows the diff of each proposed fix and prompts
+// "[a]pply / [s]kip / [e]dit / [q]uit". Edit opens the editor on the proposed
+// content and shows the updated diff.
+type InteractiveApprover struct {
+	in     *bufio.Reader
+	out    io.Writer
+	editor string // Editor command, e.g. "vim" or "code --wait"
+}
+
+// NewInteractiveApprover creates an approver reading answers from in and
+// writing to out. The editor is $VISUAL or $EDITOR, falling back to vi.
+func NewInteractiveApprover(in io.Reader, out io.Writer) *InteractiveApprover {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	return &InteractiveApprover{in: bufio.NewReader(in), out: out, editor: editor}
+}
+
+// Review shows the proposed fix and prompts for a decision. End of input
+// counts as quit.
+func (a *InteractiveApprover) Review(v violation.Violation, incident violation.Incident, result *FixResult) (Decision, error) {
+	a.showDiff(result)
+	for {
+		fmt.Fprintf(a.out, "  %s ", ux.Bold("[a]pply / [s]kip / [e]dit / [q]uit?"))
+		answer, err := a.in.ReadString('\n')
+		if err != nil && answer == "" {
+			if err == io.EOF {
+				fmt.Fprintln(a.out)
+				return DecisionQuit, nil
+			}
+			return DecisionQuit, fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "a", "apply":
+			return DecisionApply, nil
+		case "s", "skip":
+			return DecisionSkip, nil
+		case "q", "quit":
+			return DecisionQuit, nil
+		case "e", "edit":
+			if err := a.edit(result); err != nil {
+				fmt.Fprintf(a.out, "  %s %v\n", ux.Error("✗"), err)
+				continue
+			}
+			a.showDiff(result)
+		default:
+			fmt.Fprintf(a.out, "  Please answer a, s, e or q\n")
+		}
+	}
+}
+
+// showDiff prints the diff of the proposed fix
+func (a *InteractiveApprover) showDiff(result *FixResult) {
+	diff := result.Diff(DefaultDiffContext)
+	if diff == "" {
+		fmt.Fprintf(a.out, "\n  %s\n\n", ux.Dim("(no changes)"))
+		return
+	}
+	fmt.Fprintf(a.out, "\n%s\n", ux.FormatDiff(diff))
+}
+
+// edit opens the editor on the proposed content and replaces it with the
+// edited version
+func (a *InteractiveApprover) edit(result *FixResult) error {
+	tmp, err := os.CreateTemp("", "kantra-ai-fix-*"+filepath.Ext(result.FilePath))
+	if err != nil {
+		return fmt.Errorf("failed to create file to edit: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(result.FixedContent)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file to edit: %w", err)
+	}
+
+	parts := strings.Fields(a.editor)
+	cmd := exec.Command(parts[0], append(parts[1:], tmp.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", a.editor, err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read edited file: %w", err)
+	}
+	result.FixedContent = string(edited)
+	return nil
+}
@@ -0,0 +1,141 @@
+package fixer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// stubApprover returns a fixed decision, optionally replacing the content
+type stubApprover struct {
+	decision Decision
+	content  string
+	reviewed int
+}
+
+func (a *stubApprover) Review(v violation.Violation, incident violation.Incident, result *FixResult) (Decision, error) {
+	a.reviewed++
+	if a.content != "" {
+		result.FixedContent = a.content
+	}
+	return a.decision, nil
+}
+
+func TestFixIncident_Approver(t *testing.T) {
+	const original = "import javax.servlet.*;\n"
+
+	fix := func(t *testing.T, approver Approver) (string, *FixResult, error) {
+		tmpDir := t.TempDir()
+		file := filepath.Join(tmpDir, "App.java")
+		require.NoError(t, os.WriteFile(file, []byte(original), 0644))
+
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(&provider.FixResponse{
+			Success:      true,
+			FixedContent: "import jakarta.servlet.*;\n",
+			Confidence:   0.9,
+		}, nil)
+		fixer := New(mockProvider, tmpDir, false)
+		fixer.SetApprover(approver)
+
+		result, err := fixer.FixIncident(context.Background(), violation.Violation{ID: "javax-to-jakarta"}, violation.Incident{URI: "file://" + file})
+		content, readErr := os.ReadFile(file)
+		require.NoError(t, readErr)
+		return string(content), result, err
+	}
+
+	t.Run("apply writes the fix", func(t *testing.T) {
+		content, result, err := fix(t, &stubApprover{decision: DecisionApply})
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, "import jakarta.servlet.*;\n", content)
+	})
+
+	t.Run("apply writes the edited content", func(t *testing.T) {
+		content, result, err := fix(t, &stubApprover{decision: DecisionApply, content: "import jakarta.servlet.http.*;\n"})
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, "import jakarta.servlet.http.*;\n", content)
+		assert.Equal(t, content, result.FixedContent)
+	})
+
+	t.Run("skip leaves the file unchanged", func(t *testing.T) {
+		content, result, err := fix(t, &stubApprover{decision: DecisionSkip})
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.True(t, result.SkippedByUser)
+		assert.Equal(t, UserSkipReason, result.SkipReason)
+		assert.Equal(t, original, content)
+	})
+
+	t.Run("quit stops", func(t *testing.T) {
+		content, result, err := fix(t, &stubApprover{decision: DecisionQuit})
+		assert.ErrorIs(t, err, ErrReviewQuit)
+		assert.True(t, result.SkippedByUser)
+		assert.Equal(t, original, content)
+	})
+}
+
+func TestInteractiveApprover_Review(t *testing.T) {
+	newResult := func() *FixResult {
+		return &FixResult{FilePath: "App.java", OriginalContent: "import javax.servlet.*;\n", FixedContent: "import jakarta.servlet.*;\n"}
+	}
+	review := func(t *testing.T, input string, result *FixResult) (Decision, string) {
+		var out bytes.Buffer
+		approver := NewInteractiveApprover(strings.NewReader(input), &out)
+		approver.editor = "sed -i s/servlet/servlet.http/"
+		decision, err := approver.Review(violation.Violation{}, violation.Incident{}, result)
+		require.NoError(t, err)
+		return decision, out.String()
+	}
+
+	t.Run("shows the diff and reads the answer", func(t *testing.T) {
+		decision, output := review(t, "a\n", newResult())
+		assert.Equal(t, DecisionApply, decision)
+		assert.Contains(t, output, "-import javax.servlet.*;")
+		assert.Contains(t, output, "+import jakarta.servlet.*;")
+		assert.Contains(t, output, "[a]pply / [s]kip / [e]dit / [q]uit?")
+	})
+
+	t.Run("asks again after an invalid answer", func(t *testing.T) {
+		decision, output := review(t, "maybe\nSkip\n", newResult())
+		assert.Equal(t, DecisionSkip, decision)
+		assert.Contains(t, output, "Please answer a, s, e or q")
+	})
+
+	t.Run("end of input quits", func(t *testing.T) {
+		decision, _ := review(t, "", newResult())
+		assert.Equal(t, DecisionQuit, decision)
+		decision, _ = review(t, "q", newResult())
+		assert.Equal(t, DecisionQuit, decision)
+	})
+
+	t.Run("edit replaces the proposed content", func(t *testing.T) {
+		result := newResult()
+		decision, output := review(t, "e\na\n", result)
+		assert.Equal(t, DecisionApply, decision)
+		assert.Equal(t, "import jakarta.servlet.http.*;\n", result.FixedContent)
+		assert.Contains(t, output, "+import jakarta.servlet.http.*;")
+	})
+
+	t.Run("a failing editor keeps the proposed content", func(t *testing.T) {
+		var out bytes.Buffer
+		approver := NewInteractiveApprover(strings.NewReader("e\ns\n"), &out)
+		approver.editor = "false"
+		result := newResult()
+		decision, err := approver.Review(violation.Violation{}, violation.Incident{}, result)
+		require.NoError(t, err)
+		assert.Equal(t, DecisionSkip, decision)
+		assert.Equal(t, "import jakarta.servlet.*;\n", result.FixedContent)
+		assert.Contains(t, out.String(), "editor \"false\" failed")
+	})
+}
@@ -0,0 +1,356 @@
+package fixer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table used to diff the changed middle of a file.
+// Larger regions are treated as a single replaced block.
+const maxDiffCells = 4_000_000
+
+// lineEdit replaces original lines [start, end) with lines
+type lineEdit struct {
+	start, end int
+	lines      []string
+}
+
+// Divergence is a region of the original file that two candidate fixes changed differently
+type Divergence struct {
+	StartLine int      // 1-based first original line of the region
+	EndLine   int      // 1-based last original line (StartLine-1 for a pure insertion)
+	Original  []string // Original lines in the region
+	A         []string // Candidate A's version of the region
+	B         []string // Candidate B's version of the region
+	Chosen    string   // "a" or "b": the version written to Content (higher confidence)
+}
+
+// Lines describes the region's original lines, e.g. "3-5", "10" or "after 7"
+func (d Divergence) Lines() string {
+	switch {
+	case d.EndLine < d.StartLine:
+		return fmt.Sprintf("after %d", d.EndLine)
+	case d.EndLine == d.StartLine:
+		return fmt.Sprintf("%d", d.StartLine)
+	default:
+		return fmt.Sprintf("%d-%d", d.StartLine, d.EndLine)
+	}
+}
+
+// MergeResult is the outcome of merging two candidate fixes line by line
+type MergeResult struct {
+	Content     string       // Merged file content
+	Agreed      int          // Regions both candidates changed identically
+	Divergences []Divergence // Regions that need review
+	Confidence  float64      // Merged confidence
+}
+
+// NeedsReview reports whether any region diverged between the candidates
+func (r *MergeResult) NeedsReview() bool {
+	return len(r.Divergences) > 0
+}
+
+// MergeCandidates merges two candidate fixes (for example from a primary and
+// a comparison provider) of the same original file at the line level.
+//
+// Each candidate is diffed against the original. Regions both candidates changed in
+// exactly the same way are applied. Any other change - made by only one candidate, or
+// made differently by both - is recorded as a Divergence, and the version from the
+// candidate with the higher confidence is used in the merged content (A wins ties).
+// The merged confidence is the higher of the two when the candidates fully agree and
+// the lower of the two otherwise.
+func MergeCandidates(original, a, b string, confidenceA, confidenceB float64) *MergeResult {
+	preferA := confidenceA >= confidenceB
+
+	origLines := splitLines(original)
+	editsA := diffLines(origLines, splitLines(a))
+	editsB := diffLines(origLines, splitLines(b))
+
+	result := &MergeResult{}
+	var merged []string
+	cursor := 0 // Next original line to copy
+	for _, region := range groupEdits(editsA, editsB) {
+		merged = append(merged, origLines[cursor:region.start]...)
+		cursor = region.end
+
+		versionA := applyEdits(origLines, region.start, region.end, region.a)
+		versionB := applyEdits(origLines, region.start, region.end, region.b)
+
+		if len(region.a) > 0 && len(region.b) > 0 && equalLines(versionA, versionB) {
+			result.Agreed++
+			merged = append(merged, versionA...)
+			continue
+		}
+
+		divergence := Divergence{
+			StartLine: region.start + 1,
+			EndLine:   region.end,
+			Original:  append([]string(nil), origLines[region.start:region.end]...),
+			A:         versionA,
+			B:         versionB,
+		}
+		if preferA {
+			divergence.Chosen = "a"
+			merged = append(merged, versionA...)
+		} else {
+			divergence.Chosen = "b"
+			merged = append(merged, versionB...)
+		}
+		result.Divergences = append(result.Divergences, divergence)
+	}
+	merged = append(merged, origLines[cursor:]...)
+
+	// Keep the trailing newline convention of the preferred candidate
+	preferred := a
+	if !preferA {
+		preferred = b
+	}
+	result.Content = strings.Join(merged, "\n")
+	if strings.HasSuffix(preferred, "\n") && len(merged) > 0 {
+		result.Content += "\n"
+	}
+
+	if result.NeedsReview() {
+		result.Confidence = math.Min(confidenceA, confidenceB)
+	} else {
+		result.Confidence = math.Max(confidenceA, confidenceB)
+	}
+
+	return result
+}
+
+// ErrMergeConflict is returned by MergeEdits when both versions changed the
+// same lines differently
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeEdits merges the changes two versions made to a common base, like a
+// three-way merge: changes to different lines are both applied and identical
+// changes once. If both versions changed the same lines differently, it
+// returns an error wrapping ErrMergeConflict. The result keeps ours' trailing
+// newline convention.
+func MergeEdits(base, ours, theirs string) (string, error) {
+	baseLines := splitLines(base)
+	editsA := diffLines(baseLines, splitLines(ours))
+	editsB := diffLines(baseLines, splitLines(theirs))
+
+	var merged []string
+	cursor := 0 // Next base line to copy
+	for _, region := range groupEdits(editsA, editsB) {
+		merged = append(merged, baseLines[cursor:region.start]...)
+		cursor = region.end
+
+		versionA := applyEdits(baseLines, region.start, region.end, region.a)
+		switch {
+		case len(region.b) == 0:
+			merged = append(merged, versionA...)
+		case len(region.a) == 0:
+			merged = append(merged, applyEdits(baseLines, region.start, region.end, region.b)...)
+		case equalLines(versionA, applyEdits(baseLines, region.start, region.end, region.b)):
+			merged = append(merged, versionA...)
+		default:
+			// Edits to adjacent lines can both be applied
+			combined, ok := combineEdits(region.a, region.b)
+			if !ok {
+				return "", fmt.Errorf("%w: both versions changed line %d", ErrMergeConflict, region.start+1)
+			}
+			merged = append(merged, applyEdits(baseLines, region.start, region.end, combined)...)
+		}
+	}
+	merged = append(merged, baseLines[cursor:]...)
+
+	content := strings.Join(merged, "\n")
+	if strings.HasSuffix(ours, "\n") && len(merged) > 0 {
+		content += "\n"
+	}
+	return content, nil
+}
+
+// combineEdits returns the edits of both sides in order, or false if an edit
+// of one side overlaps an edit of the other
+func combineEdits(editsA, editsB []lineEdit) ([]lineEdit, bool) {
+	for _, a := range editsA {
+		for _, b := range editsB {
+			if editsOverlap(a, b) {
+				return nil, false
+			}
+		}
+	}
+	combined := append(append([]lineEdit(nil), editsA...), editsB...)
+	sort.SliceStable(combined, func(x, y int) bool {
+		if combined[x].start != combined[y].start {
+			return combined[x].start < combined[y].start
+		}
+		// An insertion goes before a replacement starting at the same line
+		return combined[x].end < combined[y].end
+	})
+	return combined, true
+}
+
+// editsOverlap reports whether two edits touch the same base lines. Two
+// insertions at the same line overlap, since their order is ambiguous.
+func editsOverlap(a, b lineEdit) bool {
+	if a.start == a.end && b.start == b.end {
+		return a.start == b.start
+	}
+	return a.start < b.end && b.start < a.end
+}
+
+// editRegion is a range of base lines [start, end) with the edits each side
+// made within it
+type editRegion struct {
+	start, end int
+	a, b       []lineEdit
+}
+
+// groupEdits groups the edits two sides made to the same base into regions.
+// Each region starts with the earliest pending edit and absorbs every edit
+// from either side that overlaps or touches it.
+func groupEdits(editsA, editsB []lineEdit) []editRegion {
+	var regions []editRegion
+	i, j := 0, 0
+	for i < len(editsA) || j < len(editsB) {
+		start, end := regionStart(editsA, editsB, i, j)
+		region := editRegion{start: start}
+		for grew := true; grew; {
+			grew = false
+			for i < len(editsA) && editsA[i].start <= end && editsA[i].end >= start {
+				end = max(end, editsA[i].end)
+				region.a = append(region.a, editsA[i])
+				i++
+				grew = true
+			}
+			for j < len(editsB) && editsB[j].start <= end && editsB[j].end >= start {
+				end = max(end, editsB[j].end)
+				region.b = append(region.b, editsB[j])
+				j++
+				grew = true
+			}
+		}
+		region.end = end
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// regionStart returns the range of the earliest pending edit from either side
+func regionStart(editsA, editsB []lineEdit, i, j int) (int, int) {
+	switch {
+	case i >= len(editsA):
+		return editsB[j].start, editsB[j].end
+	case j >= len(editsB):
+		return editsA[i].start, editsA[i].end
+	case editsA[i].start <= editsB[j].start:
+		return editsA[i].start, editsA[i].end
+	default:
+		return editsB[j].start, editsB[j].end
+	}
+}
+
+// applyEdits returns original lines [start, end) with the given edits applied
+func applyEdits(original []string, start, end int, edits []lineEdit) []string {
+	result := make([]string, 0, end-start)
+	pos := start
+	for _, e := range edits {
+		result = append(result, original[pos:e.start]...)
+		result = append(result, e.lines...)
+		pos = e.end
+	}
+	return append(result, original[pos:end]...)
+}
+
+// diffLines computes the edits that turn original into updated
+func diffLines(original, updated []string) []lineEdit {
+	// Trim common prefix and suffix so the LCS only covers the changed middle
+	prefix := 0
+	for prefix < len(original) && prefix < len(updated) && original[prefix] == updated[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(original)-prefix && suffix < len(updated)-prefix &&
+		original[len(original)-1-suffix] == updated[len(updated)-1-suffix] {
+		suffix++
+	}
+
+	oldMid := original[prefix : len(original)-suffix]
+	newMid := updated[prefix : len(updated)-suffix]
+	if len(oldMid) == 0 && len(newMid) == 0 {
+		return nil
+	}
+	if len(oldMid)*len(newMid) > maxDiffCells || len(oldMid) == 0 || len(newMid) == 0 {
+		return []lineEdit{{start: prefix, end: prefix + len(oldMid), lines: append([]string(nil), newMid...)}}
+	}
+
+	// lcs[x][y] = length of the LCS of oldMid[x:] and newMid[y:]
+	n, m := len(oldMid), len(newMid)
+	lcs := make([][]int, n+1)
+	for x := range lcs {
+		lcs[x] = make([]int, m+1)
+	}
+	for x := n - 1; x >= 0; x-- {
+		for y := m - 1; y >= 0; y-- {
+			if oldMid[x] == newMid[y] {
+				lcs[x][y] = lcs[x+1][y+1] + 1
+			} else {
+				lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+			}
+		}
+	}
+
+	// Walk the table, grouping consecutive removals/additions into edits
+	var edits []lineEdit
+	var current *lineEdit
+	flush := func() {
+		if current != nil {
+			edits = append(edits, *current)
+			current = nil
+		}
+	}
+	x, y := 0, 0
+	for x < n || y < m {
+		switch {
+		case x < n && y < m && oldMid[x] == newMid[y]:
+			flush()
+			x++
+			y++
+		case y < m && (x == n || lcs[x][y+1] >= lcs[x+1][y]):
+			if current == nil {
+				current = &lineEdit{start: prefix + x, end: prefix + x}
+			}
+			current.lines = append(current.lines, newMid[y])
+			y++
+		default:
+			if current == nil {
+				current = &lineEdit{start: prefix + x, end: prefix + x}
+			}
+			current.end = prefix + x + 1
+			x++
+		}
+	}
+	flush()
+
+	return edits
+}
+
+// splitLines splits content into lines without a trailing empty element
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// equalLines reports whether two line slices are identical
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
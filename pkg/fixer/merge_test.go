@@ -0,0 +1,225 @@
+package fixer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeTestOriginal = `package com.example;
+
+import javax.persistence.Entity;
+import javax.persistence.Id;
+import javax.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long id;
+
+    @Inject
+    private Service service;
+}
+`
+
+func TestMergeCandidates(t *testing.T) {
+	t.Run("identical candidates merge cleanly", func(t *testing.T) {
+		fixed := `package com.example;
+
+import jakarta.persistence.Entity;
+import jakarta.persistence.Id;
+import jakarta.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long id;
+
+    @Inject
+    private Service service;
+}
+`
+		result := MergeCandidates(mergeTestOriginal, fixed, fixed, 0.9, 0.8)
+
+		assert.Equal(t, fixed, result.Content)
+		assert.False(t, result.NeedsReview())
+		assert.Equal(t, 1, result.Agreed)
+		assert.Equal(t, 0.9, result.Confidence)
+	})
+
+	t.Run("shared changes applied and divergent region flagged", func(t *testing.T) {
+		// Both candidates migrate the persistence imports identically but
+		// disagree on how to handle the injection import
+		candidateA := `package com.example;
+
+import jakarta.persistence.Entity;
+import jakarta.persistence.Id;
+import jakarta.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long id;
+
+    @Inject
+    private Service service;
+}
+`
+		candidateB := `package com.example;
+
+import jakarta.persistence.Entity;
+import jakarta.persistence.Id;
+import com.google.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long id;
+
+    @Inject
+    private Service service;
+}
+`
+		result := MergeCandidates(mergeTestOriginal, candidateA, candidateB, 0.7, 0.9)
+
+		require.True(t, result.NeedsReview())
+		assert.Equal(t, 0.7, result.Confidence, "divergent merges use the lower confidence")
+
+		// The diverging line is adjacent to the agreed lines, so they form one region
+		require.Len(t, result.Divergences, 1)
+		d := result.Divergences[0]
+		assert.Equal(t, 3, d.StartLine)
+		assert.Equal(t, 5, d.EndLine)
+		assert.Equal(t, "b", d.Chosen, "higher-confidence candidate wins the divergent region")
+		assert.Equal(t, candidateB, result.Content)
+	})
+
+	t.Run("separate regions agree and diverge independently", func(t *testing.T) {
+		candidateA := `package com.example;
+
+import jakarta.persistence.Entity;
+import jakarta.persistence.Id;
+import javax.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long orderId;
+
+    @Inject
+    private Service service;
+}
+`
+		candidateB := `package com.example;
+
+import jakarta.persistence.Entity;
+import jakarta.persistence.Id;
+import javax.inject.Inject;
+
+@Entity
+public class Order {
+    @Id
+    private Long id;
+
+    @Inject
+    private Service service;
+}
+`
+		result := MergeCandidates(mergeTestOriginal, candidateA, candidateB, 0.9, 0.6)
+
+		assert.Equal(t, 1, result.Agreed, "import migration is shared")
+		require.Len(t, result.Divergences, 1)
+
+		d := result.Divergences[0]
+		assert.Equal(t, 10, d.StartLine)
+		assert.Equal(t, 10, d.EndLine)
+		assert.Equal(t, []string{"    private Long id;"}, d.Original)
+		assert.Equal(t, []string{"    private Long orderId;"}, d.A)
+		assert.Equal(t, []string{"    private Long id;"}, d.B)
+		assert.Equal(t, "a", d.Chosen)
+		assert.Equal(t, candidateA, result.Content)
+	})
+
+	t.Run("lower-confidence one-sided change is flagged and not applied", func(t *testing.T) {
+		candidateA := "a\nb\nc\n"
+		candidateB := "a\nB\nc\n"
+
+		result := MergeCandidates("a\nb\nc\n", candidateA, candidateB, 0.9, 0.5)
+
+		require.Len(t, result.Divergences, 1)
+		assert.Equal(t, "a\nb\nc\n", result.Content)
+	})
+
+	t.Run("both insert the same lines", func(t *testing.T) {
+		original := "one\ntwo\nthree\n"
+		candidate := "one\ntwo\ninserted\nthree\n"
+
+		result := MergeCandidates(original, candidate, candidate, 0.8, 0.8)
+
+		assert.False(t, result.NeedsReview())
+		assert.Equal(t, candidate, result.Content)
+	})
+}
+
+func TestDivergence_Lines(t *testing.T) {
+	assert.Equal(t, "3-5", Divergence{StartLine: 3, EndLine: 5}.Lines())
+	assert.Equal(t, "10", Divergence{StartLine: 10, EndLine: 10}.Lines())
+	assert.Equal(t, "after 7", Divergence{StartLine: 8, EndLine: 7}.Lines())
+}
+
+func TestMergeEdits(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+
+	tests := []struct {
+		name     string
+		ours     string
+		theirs   string
+		expected string
+		conflict bool
+	}{
+		{name: "changes to different lines", ours: "A\nb\nc\nd\ne\n", theirs: "a\nb\nc\nd\nE\n", expected: "A\nb\nc\nd\nE\n"},
+		{name: "changes to adjacent lines", ours: "A\nb\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n", expected: "A\nB\nc\nd\ne\n"},
+		{name: "identical changes apply once", ours: "a\nB\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n", expected: "a\nB\nc\nd\ne\n"},
+		{name: "only one side changed", ours: base, theirs: "a\nb\nC\nd\ne\n", expected: "a\nb\nC\nd\ne\n"},
+		{name: "insertion and deletion", ours: "a\nb\nb2\nc\nd\ne\n", theirs: "a\nb\nc\ne\n", expected: "a\nb\nb2\nc\ne\n"},
+		{name: "insertion before a changed line", ours: "a\nb\nnew\nc\nd\ne\n", theirs: "a\nb\nC\nd\ne\n", expected: "a\nb\nnew\nC\nd\ne\n"},
+		{name: "same line changed differently", ours: "a\nB\nc\nd\ne\n", theirs: "a\nX\nc\nd\ne\n", conflict: true},
+		{name: "insertions at the same line", ours: "a\nx\nb\nc\nd\ne\n", theirs: "a\ny\nb\nc\nd\ne\n", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeEdits(base, tt.ours, tt.theirs)
+			if tt.conflict {
+				require.ErrorIs(t, err, ErrMergeConflict)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	edits := diffLines(
+		[]string{"a", "b", "c", "d", "e"},
+		[]string{"a", "B", "c", "d", "x", "e"},
+	)
+
+	require.Len(t, edits, 2)
+	assert.Equal(t, lineEdit{start: 1, end: 2, lines: []string{"B"}}, edits[0])
+	assert.Equal(t, lineEdit{start: 4, end: 4, lines: []string{"x"}}, edits[1])
+}
+
+func TestGroupEdits(t *testing.T) {
+	editsA := []lineEdit{{start: 0, end: 1}, {start: 5, end: 6}}
+	editsB := []lineEdit{{start: 1, end: 2}, {start: 8, end: 8}}
+
+	regions := groupEdits(editsA, editsB)
+
+	require.Len(t, regions, 3)
+	assert.Equal(t, editRegion{start: 0, end: 2, a: editsA[:1], b: editsB[:1]}, regions[0], "touching edits share a region")
+	assert.Equal(t, editRegion{start: 5, end: 6, a: editsA[1:]}, regions[1])
+	assert.Equal(t, editRegion{start: 8, end: 8, b: editsB[1:]}, regions[2])
+}
@@ -0,0 +1,179 @@
+package fixer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// hunkHeaderRegex matches unified diff hunk headers: @@ -start[,count] +start[,count] @@
+	hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// hunk is a single change block parsed from a unified diff
+type hunk struct {
+	oldStart int      // 1-based line in the original file
+	oldLines []string // context and removed lines (what must match)
+	newLines []string // context and added lines (the replacement)
+}
+
+// ApplyUnifiedDiff applies a unified diff to the original content.
+//
+// Each hunk is located by matching its context and removed lines against the
+// original, starting at the line given in the hunk header and searching outward
+// so that patches with slightly wrong line numbers still apply. Hunks must not
+// overlap and are applied in order. An error is returned if any hunk cannot be
+// matched exactly, in which case the caller should fall back to full content.
+func ApplyUnifiedDiff(original, patch string) (string, error) {
+	hunks, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", err
+	}
+	if len(hunks) == 0 {
+		return "", fmt.Errorf("patch contains no hunks")
+	}
+
+	// Preserve the trailing newline state of the original file
+	hasTrailingNewline := strings.HasSuffix(original, "\n")
+	lines := strings.Split(strings.TrimSuffix(original, "\n"), "\n")
+	if original == "" {
+		lines = []string{}
+	}
+
+	result := make([]string, 0, len(lines))
+	cursor := 0 // Index of the first original line not yet copied
+
+	for i, h := range hunks {
+		pos, ok := findHunk(lines, h, cursor)
+		if !ok {
+			return "", fmt.Errorf("hunk %d (line %d) does not match the original content", i+1, h.oldStart)
+		}
+
+		result = append(result, lines[cursor:pos]...)
+		result = append(result, h.newLines...)
+		cursor = pos + len(h.oldLines)
+	}
+	result = append(result, lines[cursor:]...)
+
+	patched := strings.Join(result, "\n")
+	if hasTrailingNewline && len(result) > 0 {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// parseUnifiedDiff extracts hunks from a unified diff, ignoring file headers
+func parseUnifiedDiff(patch string) ([]hunk, error) {
+	var hunks []hunk
+	var current *hunk
+
+	patchLines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for i, line := range patchLines {
+		if matches := hunkHeaderRegex.FindStringSubmatch(line); matches != nil {
+			if current != nil {
+				hunks = append(hunks, *current)
+			}
+			start, err := strconv.Atoi(matches[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid hunk header %q: %w", line, err)
+			}
+			current = &hunk{oldStart: start}
+			continue
+		}
+
+		// Skip file headers and anything before the first hunk
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(patchLines) && strings.HasPrefix(patchLines[i+1], "+++ "):
+			// Header of another file in a multi-file diff; stop collecting until the next hunk
+			hunks = append(hunks, *current)
+			current = nil
+		case strings.HasPrefix(line, "+"):
+			current.newLines = append(current.newLines, line[1:])
+		case strings.HasPrefix(line, "-"):
+			current.oldLines = append(current.oldLines, line[1:])
+		case strings.HasPrefix(line, " "):
+			current.oldLines = append(current.oldLines, line[1:])
+			current.newLines = append(current.newLines, line[1:])
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case line == "":
+			// Some models drop the leading space on blank context lines
+			current.oldLines = append(current.oldLines, "")
+			current.newLines = append(current.newLines, "")
+		default:
+			return nil, fmt.Errorf("unexpected line in hunk: %q", line)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	// Drop blank context lines picked up from trailing newlines in the patch text
+	for i := range hunks {
+		for len(hunks[i].oldLines) > 0 && len(hunks[i].newLines) > 0 &&
+			hunks[i].oldLines[len(hunks[i].oldLines)-1] == "" &&
+			hunks[i].newLines[len(hunks[i].newLines)-1] == "" {
+			hunks[i].oldLines = hunks[i].oldLines[:len(hunks[i].oldLines)-1]
+			hunks[i].newLines = hunks[i].newLines[:len(hunks[i].newLines)-1]
+		}
+	}
+
+	return hunks, nil
+}
+
+// findHunk locates a hunk's old lines in the original at or after minPos.
+// It starts at the header's line number and searches outward in both directions.
+func findHunk(lines []string, h hunk, minPos int) (int, bool) {
+	expected := h.oldStart - 1
+	if len(h.oldLines) == 0 {
+		// Pure insertion: trust the header position (oldStart is the line after which to insert)
+		pos := h.oldStart
+		if pos < minPos || pos > len(lines) {
+			return 0, false
+		}
+		return pos, true
+	}
+
+	maxPos := len(lines) - len(h.oldLines)
+	if maxPos < minPos {
+		return 0, false
+	}
+	if expected < minPos {
+		expected = minPos
+	}
+	if expected > maxPos {
+		expected = maxPos
+	}
+
+	for offset := 0; ; offset++ {
+		before, after := expected-offset, expected+offset
+		if before < minPos && after > maxPos {
+			return 0, false
+		}
+		if after <= maxPos && matchesAt(lines, h.oldLines, after) {
+			return after, true
+		}
+		if offset > 0 && before >= minPos && matchesAt(lines, h.oldLines, before) {
+			return before, true
+		}
+	}
+}
+
+// matchesAt reports whether want matches lines starting at pos
+func matchesAt(lines, want []string, pos int) bool {
+	if pos < 0 || pos+len(want) > len(lines) {
+		return false
+	}
+	for i, line := range want {
+		if lines[pos+i] != line {
+			return false
+		}
+	}
+	return true
+}
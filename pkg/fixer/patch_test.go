@@ -0,0 +1,209 @@
+package fixer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+const patchTestOriginal = `package com.example;
+
+import javax.servlet.http.HttpServlet;
+import javax.servlet.http.HttpServletRequest;
+
+public class MyServlet extends HttpServlet {
+    public void handle(HttpServletRequest req) {
+        System.out.println("hello");
+    }
+}
+`
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "single hunk with file headers",
+			original: patchTestOriginal,
+			patch: `--- a/MyServlet.java
++++ b/MyServlet.java
+@@ -1,6 +1,6 @@
+ package com.example;
+
+-import javax.servlet.http.HttpServlet;
+-import javax.servlet.http.HttpServletRequest;
++import jakarta.servlet.http.HttpServlet;
++import jakarta.servlet.http.HttpServletRequest;
+
+ public class MyServlet extends HttpServlet {
+`,
+			want: `package com.example;
+
+import jakarta.servlet.http.HttpServlet;
+import jakarta.servlet.http.HttpServletRequest;
+
+public class MyServlet extends HttpServlet {
+    public void handle(HttpServletRequest req) {
+        System.out.println("hello");
+    }
+}
+`,
+		},
+		{
+			name:     "wrong line numbers are matched by context",
+			original: patchTestOriginal,
+			patch: `@@ -2,3 +2,3 @@
+     public void handle(HttpServletRequest req) {
+-        System.out.println("hello");
++        System.out.println("goodbye");
+     }
+`,
+			want: `package com.example;
+
+import javax.servlet.http.HttpServlet;
+import javax.servlet.http.HttpServletRequest;
+
+public class MyServlet extends HttpServlet {
+    public void handle(HttpServletRequest req) {
+        System.out.println("goodbye");
+    }
+}
+`,
+		},
+		{
+			name:     "multiple hunks",
+			original: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			patch: `@@ -1,2 +1,2 @@
+-a
++A
+ b
+@@ -7,2 +7,3 @@
+ g
+-h
++H
++i
+`,
+			want: "A\nb\nc\nd\ne\nf\ng\nH\ni\n",
+		},
+		{
+			name:     "pure insertion",
+			original: "one\ntwo\n",
+			patch:    "@@ -1,0 +2,1 @@\n+inserted\n",
+			want:     "one\ninserted\ntwo\n",
+		},
+		{
+			name:     "preserves missing trailing newline",
+			original: "x := 1\ny := 2",
+			patch:    "@@ -2 +2 @@\n-y := 2\n+y := 3\n\\ No newline at end of file\n",
+			want:     "x := 1\ny := 3",
+		},
+		{
+			name:     "context mismatch fails",
+			original: patchTestOriginal,
+			patch:    "@@ -3,1 +3,1 @@\n-import javax.persistence.Entity;\n+import jakarta.persistence.Entity;\n",
+			wantErr:  true,
+		},
+		{
+			name:     "no hunks fails",
+			original: patchTestOriginal,
+			patch:    "not a diff at all",
+			wantErr:  true,
+		},
+		{
+			name:     "garbage inside hunk fails",
+			original: "a\n",
+			patch:    "@@ -1 +1 @@\n-a\n+b\n*garbage\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyUnifiedDiff(tt.original, tt.patch)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFixer_FixIncident_Patch(t *testing.T) {
+	v := violation.Violation{ID: "javax-to-jakarta"}
+
+	t.Run("applies patch response", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		testFile := filepath.Join(tmpDir, "Test.java")
+		require.NoError(t, os.WriteFile(testFile, []byte("import javax.servlet.*;\nclass Test {}\n"), 0644))
+
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(&provider.FixResponse{
+			Success:    true,
+			Patch:      "@@ -1,2 +1,2 @@\n-import javax.servlet.*;\n+import jakarta.servlet.*;\n class Test {}\n",
+			Confidence: 0.95,
+			Cost:       0.01,
+			TokensUsed: 100,
+		}, nil).Once()
+
+		fixer := New(mockProvider, tmpDir, false)
+		result, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + testFile})
+
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+
+		content, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, "import jakarta.servlet.*;\nclass Test {}\n", string(content))
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("falls back to full content when patch fails", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		testFile := filepath.Join(tmpDir, "Test.java")
+		require.NoError(t, os.WriteFile(testFile, []byte("import javax.servlet.*;\n"), 0644))
+
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.MatchedBy(func(req provider.FixRequest) bool {
+			return req.ResponseFormat == ""
+		})).Return(&provider.FixResponse{
+			Success:    true,
+			Patch:      "@@ -1 +1 @@\n-import javax.ejb.*;\n+import jakarta.ejb.*;\n",
+			Cost:       0.01,
+			TokensUsed: 100,
+		}, nil).Once()
+		mockProvider.On("FixViolation", mock.Anything, mock.MatchedBy(func(req provider.FixRequest) bool {
+			return req.ResponseFormat == provider.ResponseFormatFull
+		})).Return(&provider.FixResponse{
+			Success:      true,
+			FixedContent: "import jakarta.servlet.*;\n",
+			Cost:         0.02,
+			TokensUsed:   200,
+		}, nil).Once()
+
+		fixer := New(mockProvider, tmpDir, false)
+		result, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + testFile})
+
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.InDelta(t, 0.03, result.Cost, 0.0001, "cost should include both requests")
+		assert.Equal(t, 300, result.TokensUsed)
+
+		content, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		assert.Equal(t, "import jakarta.servlet.*;\n", string(content))
+		mockProvider.AssertExpectations(t)
+	})
+}
@@ -4,8 +4,17 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
+// ResolveFilePath resolves an incident's file path to be relative to the input
+// directory, as fixes do, failing if it escapes the input directory or belongs
+// to a different source directory
+func ResolveFilePath(filePath, inputDir string) (string, error) {
+	return resolveAndValidateFilePath(filePath, inputDir)
+}
+
 // resolveAndValidateFilePath resolves a file path to be relative to the input directory
 // and validates that it doesn't escape the input directory boundary.
 //
@@ -64,6 +73,9 @@ func resolveAndValidateFilePath(filePath, inputDir string) (string, error) {
 					"  • Your --input flag: %s\n\n"+
 					"These paths should match. Update your --input flag to point to the correct source directory.",
 					cleanPath, absInputDir, filepath.Dir(cleanPath), absInputDir)
+			} else if rel, ok := violation.ContainerRelativePath(filepath.ToSlash(cleanPath)); ok {
+				// kantra's container source directory is the input directory
+				cleanPath = filepath.FromSlash(rel)
 			} else {
 				// Looks like a container path (e.g., /src/file.java, /workspace/file.java)
 				// Strip leading slash(es) to make it relative
@@ -144,6 +144,15 @@ func TestResolveAndValidateFilePath_EdgeCases(t *testing.T) {
 		_ = err // May succeed or fail depending on OS path limits
 	})
 
+	t.Run("container source directory is the input directory", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Unix-style absolute paths")
+		}
+		result, err := resolveAndValidateFilePath("/opt/input/source/src/Main.java", "/workspace/project")
+		require.NoError(t, err)
+		assert.Equal(t, "src/Main.java", result)
+	})
+
 	t.Run("unicode characters in path", func(t *testing.T) {
 		result, err := resolveAndValidateFilePath("src/文件.java", "/workspace/project")
 		require.NoError(t, err)
@@ -0,0 +1,146 @@
+package fixer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tsanders/kantra-ai/pkg/provider"
+)
+
+const (
+	// ReuseMinConfidence is the lowest confidence of a fix whose change is
+	// reused for identical incidents
+	ReuseMinConfidence = 0.9
+
+	// ReuseContextLines is how many lines before and after an incident must
+	// be identical for a fix to be reused
+	ReuseContextLines = 5
+)
+
+// fixTransform is the change a fix made to the code around its incident
+type fixTransform struct {
+	after       string // Replacement for the context
+	confidence  float64
+	explanation string
+}
+
+// reuseCache remembers high-confidence fixes by the code around their
+// incident, so incidents of the same violation in identical code get the
+// same change without another API call. A nil cache reuses nothing.
+type reuseCache struct {
+	mu         sync.Mutex
+	transforms map[string]fixTransform // Violation ID and context -> change
+}
+
+// newReuseCache returns a cache, or nil if reuse is disabled
+func newReuseCache(enabled bool) *reuseCache {
+	if !enabled {
+		return nil
+	}
+	return &reuseCache{transforms: make(map[string]fixTransform)}
+}
+
+// SetReuseIdenticalFixes makes the fixer apply the change of a high-confidence
+// fix (see ReuseMinConfidence) to later incidents of the same violation whose
+// surrounding code is identical, without asking the model again
+func (f *Fixer) SetReuseIdenticalFixes(enabled bool) {
+	f.reuse = newReuseCache(enabled)
+}
+
+// incidentContext returns the bounds of the lines around line (1-based) that
+// identify an incident: [start, end) indexes into lines
+func incidentContext(lines []string, line int) (start, end int, ok bool) {
+	if line < 1 || line > len(lines) {
+		return 0, 0, false
+	}
+	start = max(line-1-ReuseContextLines, 0)
+	end = min(line+ReuseContextLines, len(lines))
+	return start, end, true
+}
+
+// reuseKey identifies the incident at line (1-based) of lines by its
+// violation, its context and its position in the context, which differs near
+// the start of a file
+func reuseKey(violationID string, lines []string, line int) (string, int, int, bool) {
+	start, end, ok := incidentContext(lines, line)
+	if !ok {
+		return "", 0, 0, false
+	}
+	key := fmt.Sprintf("%s\x00%d\x00%s", violationID, line-1-start, strings.Join(lines[start:end], ""))
+	return key, start, end, true
+}
+
+// record remembers the change from original to fixed for an incident at line,
+// if the fix is confident enough and changed nothing outside the incident's
+// context
+func (c *reuseCache) record(violationID, original, fixed string, line int, confidence float64, explanation string) {
+	if c == nil || confidence < ReuseMinConfidence || original == fixed {
+		return
+	}
+	before := strings.SplitAfter(original, "\n")
+	after := strings.SplitAfter(fixed, "\n")
+	key, start, end, ok := reuseKey(violationID, before, line)
+	if !ok {
+		return
+	}
+
+	// The changed lines: everything between the common prefix and suffix
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	if prefix < start || len(before)-suffix > end {
+		// Edits elsewhere in the file (e.g. a new import) may not apply to
+		// another file
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.transforms[key]; !exists {
+		c.transforms[key] = fixTransform{
+			after:       strings.Join(after[start:end+len(after)-len(before)], ""),
+			confidence:  confidence,
+			explanation: explanation,
+		}
+	}
+}
+
+// lookup returns a fix for an incident at line of content, made from the
+// recorded change of an identical incident
+func (c *reuseCache) lookup(violationID, content string, line int) (*provider.FixResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	lines := strings.SplitAfter(content, "\n")
+	key, start, end, ok := reuseKey(violationID, lines, line)
+	if !ok {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	transform, ok := c.transforms[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	fixed := strings.Join(lines[:start], "") + transform.after + strings.Join(lines[end:], "")
+	return &provider.FixResponse{
+		Success:      true,
+		FixedContent: fixed,
+		Explanation:  transform.explanation,
+		Confidence:   transform.confidence,
+	}, true
+}
+
+// reuseNotice is printed for a fix reused from an identical incident
+func reuseNotice() string {
+	return fmt.Sprintf("  ♻ Reusing the fix of an identical incident (confidence ≥ %.2f, no API call)", ReuseMinConfidence)
+}
@@ -0,0 +1,215 @@
+package fixer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// servlet returns a Java file with the javax import on line 8, or later if
+// header has several lines
+func servlet(header, class string) string {
+	return header + "\n" + strings.Repeat("// Licensed under the Apache License\n", 6) +
+		"import javax.servlet.http.HttpServlet;\n\npublic class " + class + " extends HttpServlet {\n}\n"
+}
+
+func TestReuseCache(t *testing.T) {
+	original := servlet("package a;", "Servlet")
+	fixed := strings.Replace(original, "javax.servlet", "jakarta.servlet", 1)
+
+	t.Run("identical context is reused", func(t *testing.T) {
+		cache := newReuseCache(true)
+		cache.record("javax", original, fixed, 8, 0.95, "Use jakarta")
+
+		other := "// Copyright\n" + original
+		resp, ok := cache.lookup("javax", other, 9)
+		require.True(t, ok)
+		assert.Equal(t, "// Copyright\n"+fixed, resp.FixedContent)
+		assert.Equal(t, 0.95, resp.Confidence)
+		assert.Equal(t, "Use jakarta", resp.Explanation)
+		assert.Zero(t, resp.Cost)
+	})
+
+	t.Run("different context, line or violation is not reused", func(t *testing.T) {
+		cache := newReuseCache(true)
+		cache.record("javax", original, fixed, 8, 0.95, "")
+
+		_, ok := cache.lookup("javax", servlet("package a;", "Other"), 8)
+		assert.False(t, ok)
+		_, ok = cache.lookup("javax", original, 9)
+		assert.False(t, ok)
+		_, ok = cache.lookup("other", original, 8)
+		assert.False(t, ok)
+	})
+
+	t.Run("low confidence fixes are not recorded", func(t *testing.T) {
+		cache := newReuseCache(true)
+		cache.record("javax", original, fixed, 8, ReuseMinConfidence-0.01, "")
+		_, ok := cache.lookup("javax", original, 8)
+		assert.False(t, ok)
+	})
+
+	t.Run("fixes with edits outside the context are not recorded", func(t *testing.T) {
+		long := original + strings.Repeat("// padding\n", 2*ReuseContextLines) + "// end\n"
+		edited := strings.Replace(long, "javax.servlet", "jakarta.servlet", 1)
+		edited = strings.Replace(edited, "// end", "// changed", 1)
+
+		cache := newReuseCache(true)
+		cache.record("javax", long, edited, 8, 0.95, "")
+		_, ok := cache.lookup("javax", long, 8)
+		assert.False(t, ok)
+	})
+
+	t.Run("fixes that add lines are reused", func(t *testing.T) {
+		added := strings.Replace(original, "import javax.servlet.http.HttpServlet;\n",
+			"import jakarta.servlet.http.HttpServlet;\nimport jakarta.servlet.ServletException;\n", 1)
+		cache := newReuseCache(true)
+		cache.record("javax", original, added, 8, 0.95, "")
+
+		resp, ok := cache.lookup("javax", original, 8)
+		require.True(t, ok)
+		assert.Equal(t, added, resp.FixedContent)
+	})
+
+	t.Run("a nil cache reuses nothing", func(t *testing.T) {
+		var cache *reuseCache
+		cache.record("javax", original, fixed, 8, 0.95, "")
+		_, ok := cache.lookup("javax", original, 8)
+		assert.False(t, ok)
+	})
+}
+
+func TestFixIncident_ReuseIdenticalFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	original := servlet("package a;", "Servlet")
+	fixed := strings.Replace(original, "javax.servlet", "jakarta.servlet", 1)
+	for _, name := range []string{"A.java", "B.java"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(original), 0644))
+	}
+	v := violation.Violation{ID: "javax-to-jakarta", Category: "mandatory"}
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(
+		&provider.FixResponse{Success: true, FixedContent: fixed, Confidence: 0.95, Cost: 0.05, TokensUsed: 500},
+		nil,
+	).Once()
+
+	fixer := New(mockProvider, tmpDir, false)
+	fixer.SetReuseIdenticalFixes(true)
+
+	first, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + filepath.Join(tmpDir, "A.java"), LineNumber: 8})
+	require.NoError(t, err)
+	assert.True(t, first.Success)
+
+	second, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + filepath.Join(tmpDir, "B.java"), LineNumber: 8})
+	require.NoError(t, err)
+	assert.True(t, second.Success)
+	assert.Zero(t, second.Cost)
+	assert.Zero(t, second.TokensUsed)
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "B.java"))
+	require.NoError(t, err)
+	assert.Equal(t, fixed, string(content))
+	mockProvider.AssertExpectations(t)
+}
+
+func TestBatchFixer_ReuseIdenticalFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	identical := servlet("package a;", "Servlet")
+	different := servlet("package a;", "Other")
+	files := map[string]string{"A.java": identical, "B.java": identical, "C.java": identical, "D.java": different}
+	var incidents []violation.Incident
+	for _, name := range []string{"A.java", "B.java", "C.java", "D.java"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(files[name]), 0644))
+		incidents = append(incidents, violation.Incident{URI: "file://" + filepath.Join(tmpDir, name), LineNumber: 8})
+	}
+	jakarta := func(content string) string {
+		return strings.Replace(content, "javax.servlet", "jakarta.servlet", 1)
+	}
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, mock.MatchedBy(func(req provider.BatchRequest) bool {
+		return len(req.Incidents) == 2
+	})).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: incidents[0].URI, Success: true, FixedContent: jakarta(identical), Confidence: 0.95},
+				{IncidentURI: incidents[3].URI, Success: true, FixedContent: jakarta(different), Confidence: 0.95},
+			},
+			Success: true,
+			Cost:    0.10,
+		},
+		nil,
+	).Once()
+
+	config := DefaultBatchConfig()
+	config.GroupByFile = false
+	config.ReuseIdenticalFixes = true
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{ID: "javax-to-jakarta", Incidents: incidents})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	mockProvider.AssertExpectations(t)
+
+	// Results are in the order A, D, then the reused B and C; each names
+	// the incident it fixes
+	var fixed []string
+	for _, result := range results {
+		assert.True(t, result.Success, result.FilePath)
+		assert.Equal(t, 8, result.LineNumber)
+		fixed = append(fixed, result.IncidentURI)
+		assert.Equal(t, "file://"+filepath.Join(tmpDir, result.FilePath), result.IncidentURI)
+	}
+	assert.ElementsMatch(t, []string{incidents[0].URI, incidents[1].URI, incidents[2].URI, incidents[3].URI}, fixed)
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(tmpDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, jakarta(content), string(got), name)
+	}
+}
+
+func TestBatchFixer_ReuseFallsBackToBatching(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := servlet("package a;", "Servlet")
+	var incidents []violation.Incident
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("S%d.java", i))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		incidents = append(incidents, violation.Incident{URI: "file://" + path, LineNumber: 8})
+	}
+	fixed := strings.Replace(content, "javax.servlet", "jakarta.servlet", 1)
+
+	// The first fix is not confident enough to reuse, so the duplicate is batched too
+	mockProvider := new(MockProvider)
+	for _, incident := range incidents {
+		mockProvider.On("FixBatch", mock.Anything, mock.MatchedBy(func(req provider.BatchRequest) bool {
+			return len(req.Incidents) == 1 && req.Incidents[0].URI == incident.URI
+		})).Return(
+			&provider.BatchResponse{
+				Fixes:   []provider.IncidentFix{{IncidentURI: incident.URI, Success: true, FixedContent: fixed, Confidence: 0.7}},
+				Success: true,
+			},
+			nil,
+		).Once()
+	}
+
+	config := DefaultBatchConfig()
+	config.GroupByFile = false
+	config.ReuseIdenticalFixes = true
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{ID: "javax-to-jakarta", Incidents: incidents})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	mockProvider.AssertExpectations(t)
+}
@@ -0,0 +1,157 @@
+package fixer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+const (
+	// ReviewDirName is the directory, relative to the input directory, that
+	// receives a patch per low-confidence fix with manual-review-file
+	ReviewDirName = ".kantra-ai-review"
+
+	// ReviewManifestName is the manifest listing the patches in the review directory
+	ReviewManifestName = "manifest.json"
+)
+
+// ReviewPatch describes a low-confidence fix written to the review directory
+// as a patch instead of being applied
+type ReviewPatch struct {
+	Patch       string  `json:"patch"` // Patch file name in the review directory
+	ViolationID string  `json:"violation_id"`
+	FilePath    string  `json:"file_path"`
+	LineNumber  int     `json:"line_number"`
+	Confidence  float64 `json:"confidence"`
+	Reason      string  `json:"reason"`
+	Explanation string  `json:"explanation,omitempty"`
+	Category    string  `json:"category,omitempty"`
+	Complexity  string  `json:"complexity,omitempty"`
+}
+
+// ReviewManifest lists the patches in the review directory
+type ReviewManifest struct {
+	Fixes []ReviewPatch `json:"fixes"`
+}
+
+// unsafePatchNameChars matches characters replaced in patch file names
+var unsafePatchNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// reviewDir returns the review directory, relative to the input directory
+func (f *Fixer) reviewDir() string {
+	if f.confidenceConf.ReviewDir != "" {
+		return f.confidenceConf.ReviewDir
+	}
+	return ReviewDirName
+}
+
+// writeReviewPatch writes the proposed change of a low-confidence fix to
+// <review dir>/<violation>-<file>.patch, for applying with "git apply" from
+// the input directory, and records it in the manifest. It returns the
+// patch's path relative to the input directory.
+func (f *Fixer) writeReviewPatch(v violation.Violation, incident violation.Incident, result *FixResult, reason string, confidenceScore float64) (string, error) {
+	reviewFileMutex.Lock()
+	defer reviewFileMutex.Unlock()
+
+	diff := result.Diff(DefaultDiffContext)
+	if diff == "" {
+		return "", fmt.Errorf("the fix for %s makes no change to review", result.FilePath)
+	}
+
+	dir := filepath.Join(f.inputDir, f.reviewDir())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create review directory: %w", err)
+	}
+
+	manifestPath := filepath.Join(dir, ReviewManifestName)
+	var manifest ReviewManifest
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		_ = json.Unmarshal(data, &manifest) // Ignore errors, start fresh if corrupt
+	}
+
+	entry := ReviewPatch{
+		Patch:       reviewPatchName(v.ID, result.FilePath, ""),
+		ViolationID: v.ID,
+		FilePath:    result.FilePath,
+		LineNumber:  incident.LineNumber,
+		Confidence:  confidenceScore,
+		Reason:      reason,
+		Explanation: result.Explanation,
+		Category:    v.Category,
+		Complexity:  v.MigrationComplexity,
+	}
+
+	// A fix for the same incident (e.g. from an earlier run) is replaced;
+	// other incidents of the violation in the file get their own patch
+	index := -1
+	for i, existing := range manifest.Fixes {
+		if existing.ViolationID == entry.ViolationID && existing.FilePath == entry.FilePath {
+			if existing.LineNumber == entry.LineNumber {
+				index = i
+				entry.Patch = existing.Patch
+				break
+			}
+			if existing.Patch == entry.Patch {
+				entry.Patch = reviewPatchName(v.ID, result.FilePath, fmt.Sprintf("-L%d", incident.LineNumber))
+			}
+		}
+	}
+	if index >= 0 {
+		manifest.Fixes[index] = entry
+	} else {
+		manifest.Fixes = append(manifest.Fixes, entry)
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, entry.Patch), []byte(diff), 0644); err != nil {
+		return "", fmt.Errorf("failed to write review patch: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal review manifest: %w", err)
+	}
+	if err := writeFileAtomic(manifestPath, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write review manifest: %w", err)
+	}
+
+	return filepath.Join(f.reviewDir(), entry.Patch), nil
+}
+
+// reviewPatchName returns the patch file name for a violation's fix to file,
+// e.g. "javax-to-jakarta-src_main_App.java.patch"
+func reviewPatchName(violationID, file, suffix string) string {
+	name := violationID + "-" + strings.ReplaceAll(filepath.ToSlash(file), "/", "_") + suffix
+	return unsafePatchNameChars.ReplaceAllString(name, "_") + ".patch"
+}
+
+// writeFileAtomic writes data to a temporary file in path's directory and
+// renames it over path, so neither readers nor an interrupted run ever
+// leave a partial file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
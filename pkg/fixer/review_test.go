@@ -0,0 +1,145 @@
+package fixer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/confidence"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+const (
+	reviewOriginal = "package com.example;\n\nimport javax.servlet.http.HttpServlet;\n\npublic class App extends HttpServlet {}\n"
+	reviewFixed    = "package com.example;\n\nimport jakarta.servlet.http.HttpServlet;\n\npublic class App extends HttpServlet {}\n"
+)
+
+func readReviewManifest(t *testing.T, dir string) ReviewManifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ReviewManifestName))
+	require.NoError(t, err)
+	var manifest ReviewManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return manifest
+}
+
+func TestFixIncident_ManualReviewWritesPatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "src", "App.java")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(reviewOriginal), 0644))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(&provider.FixResponse{
+		Success:      true,
+		FixedContent: reviewFixed,
+		Explanation:  "Replaced javax with jakarta",
+		Confidence:   0.5,
+	}, nil)
+
+	confidenceConf := confidence.DefaultConfig()
+	confidenceConf.Enabled = true
+	confidenceConf.OnLowConfidence = confidence.ActionManualReviewFile
+	confidenceConf.ReviewDir = "review"
+	fixer := NewWithConfidence(mockProvider, tmpDir, false, confidenceConf)
+
+	v := violation.Violation{ID: "javax-to-jakarta", Category: "mandatory", MigrationComplexity: "trivial"}
+	result, err := fixer.FixIncident(context.Background(), v, violation.Incident{URI: "file://" + file, LineNumber: 3})
+	require.NoError(t, err)
+	assert.True(t, result.SkippedLowConfidence)
+
+	// The fix isn't applied
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, reviewOriginal, string(content))
+
+	// The proposed change is written as a patch and listed in the manifest
+	reviewDir := filepath.Join(tmpDir, "review")
+	manifest := readReviewManifest(t, reviewDir)
+	require.Len(t, manifest.Fixes, 1)
+	entry := manifest.Fixes[0]
+	assert.Equal(t, "javax-to-jakarta-src_App.java.patch", entry.Patch)
+	assert.Equal(t, "javax-to-jakarta", entry.ViolationID)
+	assert.Equal(t, "src/App.java", entry.FilePath)
+	assert.Equal(t, 3, entry.LineNumber)
+	assert.Equal(t, 0.5, entry.Confidence)
+	assert.Equal(t, "Replaced javax with jakarta", entry.Explanation)
+	assert.NotEmpty(t, entry.Reason)
+
+	patch, err := os.ReadFile(filepath.Join(reviewDir, entry.Patch))
+	require.NoError(t, err)
+	applied, err := ApplyUnifiedDiff(reviewOriginal, string(patch))
+	require.NoError(t, err)
+	assert.Equal(t, reviewFixed, applied)
+	assert.NoFileExists(t, filepath.Join(tmpDir, ReviewDirName))
+}
+
+func TestWriteReviewPatch(t *testing.T) {
+	v := violation.Violation{ID: "javax-to-jakarta"}
+	result := &FixResult{FilePath: "src/App.java", OriginalContent: reviewOriginal, FixedContent: reviewFixed}
+
+	t.Run("other incidents in the file get their own patch", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		fixer := New(new(MockProvider), tmpDir, false)
+
+		first, err := fixer.writeReviewPatch(v, violation.Incident{LineNumber: 3}, result, "low", 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(ReviewDirName, "javax-to-jakarta-src_App.java.patch"), first)
+		second, err := fixer.writeReviewPatch(v, violation.Incident{LineNumber: 5}, result, "low", 0.6)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(ReviewDirName, "javax-to-jakarta-src_App.java-L5.patch"), second)
+
+		// Rewriting an incident's fix replaces its entry
+		again, err := fixer.writeReviewPatch(v, violation.Incident{LineNumber: 5}, result, "lower", 0.4)
+		require.NoError(t, err)
+		assert.Equal(t, second, again)
+
+		manifest := readReviewManifest(t, filepath.Join(tmpDir, ReviewDirName))
+		require.Len(t, manifest.Fixes, 2)
+		assert.Equal(t, 0.4, manifest.Fixes[1].Confidence)
+		assert.FileExists(t, filepath.Join(tmpDir, first))
+		assert.FileExists(t, filepath.Join(tmpDir, second))
+	})
+
+	t.Run("a fix without changes has no patch", func(t *testing.T) {
+		fixer := New(new(MockProvider), t.TempDir(), false)
+		_, err := fixer.writeReviewPatch(v, violation.Incident{}, &FixResult{FilePath: "App.java", OriginalContent: "x\n", FixedContent: "x\n"}, "low", 0.5)
+		assert.Error(t, err)
+	})
+
+	t.Run("the patch applies with git apply", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "App.java"), []byte(reviewOriginal), 0644))
+		require.NoError(t, exec.Command("git", "-C", tmpDir, "init", "-q").Run())
+
+		fixer := New(new(MockProvider), tmpDir, false)
+		patch, err := fixer.writeReviewPatch(v, violation.Incident{LineNumber: 3}, result, "low", 0.5)
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "apply", patch)
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "src", "App.java"))
+		require.NoError(t, err)
+		assert.Equal(t, reviewFixed, string(content))
+	})
+}
+
+func TestReviewPatchName(t *testing.T) {
+	assert.Equal(t, "javax-to-jakarta-00010-src_main_App.java.patch", reviewPatchName("javax-to-jakarta-00010", "src/main/App.java", ""))
+	assert.Equal(t, "rule_1-pom.xml-L7.patch", reviewPatchName("rule/1", "pom.xml", "-L7"))
+	assert.Equal(t, "weird_id-a_b.go.patch", reviewPatchName("weird id", "a b.go", ""))
+}
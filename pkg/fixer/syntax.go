@@ -0,0 +1,136 @@
+package fixer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SyntaxRetries is how many more fixes are requested when a fix's content
+// doesn't parse, before the fix is rejected
+const SyntaxRetries = 1
+
+// SyntaxValidator checks that fixed content is syntactically valid. It is a
+// cheap check of the provider's output, not a build.
+type SyntaxValidator interface {
+	Validate(content string) error
+}
+
+// SyntaxValidatorFunc adapts a function to a SyntaxValidator
+type SyntaxValidatorFunc func(content string) error
+
+// Validate calls f(content)
+func (f SyntaxValidatorFunc) Validate(content string) error {
+	return f(content)
+}
+
+var (
+	syntaxValidatorsMu sync.RWMutex
+	syntaxValidators   = map[string]SyntaxValidator{
+		"go":   SyntaxValidatorFunc(validateGoSyntax),
+		"json": SyntaxValidatorFunc(validateJSONSyntax),
+		"xml":  SyntaxValidatorFunc(validateXMLSyntax),
+		"yaml": SyntaxValidatorFunc(validateYAMLSyntax),
+	}
+)
+
+// RegisterSyntaxValidator sets the validator for a language, as returned by
+// the fixer's language detection (java, go, xml, ...), replacing any
+// validator it had. A nil validator removes it.
+func RegisterSyntaxValidator(language string, validator SyntaxValidator) {
+	syntaxValidatorsMu.Lock()
+	defer syntaxValidatorsMu.Unlock()
+	if validator == nil {
+		delete(syntaxValidators, language)
+		return
+	}
+	syntaxValidators[language] = validator
+}
+
+// SyntaxLanguages returns the languages with a syntax validator, sorted
+func SyntaxLanguages() []string {
+	syntaxValidatorsMu.RLock()
+	defer syntaxValidatorsMu.RUnlock()
+	languages := make([]string, 0, len(syntaxValidators))
+	for language := range syntaxValidators {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ValidateSyntax checks content of the given file with the validator for
+// its language. Files in languages without a validator always pass.
+func ValidateSyntax(filePath, content string) error {
+	language := detectLanguage(filePath)
+	syntaxValidatorsMu.RLock()
+	validator, ok := syntaxValidators[language]
+	syntaxValidatorsMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(content); err != nil {
+		return fmt.Errorf("fixed content is not valid %s: %w", language, err)
+	}
+	return nil
+}
+
+// SetSyntaxCheck makes the fixer check that fixed content parses before
+// writing it. Invalid fixes are requested again up to SyntaxRetries times,
+// then rejected.
+func (f *Fixer) SetSyntaxCheck(enabled bool) {
+	f.syntaxCheck = enabled
+}
+
+func validateGoSyntax(content string) error {
+	_, err := parser.ParseFile(token.NewFileSet(), "", content, parser.AllErrors)
+	return err
+}
+
+func validateJSONSyntax(content string) error {
+	var value interface{}
+	return json.Unmarshal([]byte(content), &value)
+}
+
+func validateXMLSyntax(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = true
+	decoder.Entity = xml.HTMLEntity
+	root := false
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if !root {
+				return errors.New("no root element")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			root = true
+		}
+	}
+}
+
+func validateYAMLSyntax(content string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
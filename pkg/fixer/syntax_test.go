@@ -0,0 +1,188 @@
+package fixer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestValidateSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		valid   bool
+	}{
+		{"valid go", "main.go", "package main\n\nfunc main() {}\n", true},
+		{"go with unbalanced braces", "main.go", "package main\n\nfunc main() {\n", false},
+		{"go without package clause", "main.go", "func main() {}\n", false},
+		{"valid json", "package.json", `{"name": "app", "version": "1.0.0"}`, true},
+		{"json with trailing comma", "package.json", `{"name": "app",}`, false},
+		{"valid xml", "pom.xml", "<?xml version=\"1.0\"?>\n<project><artifactId>app</artifactId></project>\n", true},
+		{"xml with mismatched tags", "pom.xml", "<project><artifactId>app</groupId></project>", false},
+		{"truncated xml", "pom.xml", "<project><artifactId>app</artifactId>", false},
+		{"empty xml", "pom.xml", "", false},
+		{"valid yaml", "app.yaml", "server:\n  port: 8080\n---\nother: true\n", true},
+		{"yaml with bad indentation", "app.yml", "server:\n  port: 8080\n bad: [1, 2\n", false},
+		{"languages without a validator pass", "App.java", "class {", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSyntax(tt.file, tt.content)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "fixed content is not valid "+detectLanguage(tt.file))
+			}
+		})
+	}
+}
+
+func TestRegisterSyntaxValidator(t *testing.T) {
+	RegisterSyntaxValidator("java", SyntaxValidatorFunc(func(content string) error {
+		if strings.Count(content, "{") != strings.Count(content, "}") {
+			return errors.New("unbalanced braces")
+		}
+		return nil
+	}))
+	defer RegisterSyntaxValidator("java", nil)
+
+	assert.Contains(t, SyntaxLanguages(), "java")
+	assert.NoError(t, ValidateSyntax("App.java", "class App {}"))
+	assert.EqualError(t, ValidateSyntax("App.java", "class App {"), "fixed content is not valid java: unbalanced braces")
+
+	RegisterSyntaxValidator("java", nil)
+	assert.NotContains(t, SyntaxLanguages(), "java")
+	assert.NoError(t, ValidateSyntax("App.java", "class App {"))
+}
+
+func TestFixIncident_SyntaxCheck(t *testing.T) {
+	const (
+		original = "package main\n\nimport \"io/ioutil\"\n\nvar _ = ioutil.ReadFile\n"
+		invalid  = "package main\n\nimport \"os\"\n\nvar _ = os.ReadFile(\n"
+		valid    = "package main\n\nimport \"os\"\n\nvar _ = os.ReadFile\n"
+	)
+	v := violation.Violation{ID: "ioutil-deprecated", Category: "optional"}
+
+	setup := func(t *testing.T) (string, violation.Incident) {
+		tmpDir := t.TempDir()
+		file := filepath.Join(tmpDir, "main.go")
+		require.NoError(t, os.WriteFile(file, []byte(original), 0644))
+		return tmpDir, violation.Incident{URI: "file://" + file, LineNumber: 3}
+	}
+	response := func(content string) *provider.FixResponse {
+		return &provider.FixResponse{Success: true, FixedContent: content, Confidence: 0.9, Cost: 0.01, TokensUsed: 100}
+	}
+
+	t.Run("an invalid fix is requested again", func(t *testing.T) {
+		tmpDir, incident := setup(t)
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(response(invalid), nil).Once()
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(response(valid), nil).Once()
+
+		fixer := New(mockProvider, tmpDir, false)
+		fixer.SetSyntaxCheck(true)
+		result, err := fixer.FixIncident(context.Background(), v, incident)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 200, result.TokensUsed)
+		assert.InDelta(t, 0.02, result.Cost, 1e-9)
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, valid, string(content))
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("a fix that stays invalid is rejected", func(t *testing.T) {
+		tmpDir, incident := setup(t)
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(response(invalid), nil).Times(1 + SyntaxRetries)
+
+		fixer := New(mockProvider, tmpDir, false)
+		fixer.SetSyntaxCheck(true)
+		result, err := fixer.FixIncident(context.Background(), v, incident)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fixed content is not valid go")
+		assert.False(t, result.Success)
+		assert.Equal(t, invalid, result.FixedContent)
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, original, string(content))
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("invalid fixes are written without the check", func(t *testing.T) {
+		tmpDir, incident := setup(t)
+		mockProvider := new(MockProvider)
+		mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(response(invalid), nil).Once()
+
+		result, err := New(mockProvider, tmpDir, false).FixIncident(context.Background(), v, incident)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		mockProvider.AssertExpectations(t)
+	})
+}
+
+func TestBatchFixer_SyntaxCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	validFile := filepath.Join(tmpDir, "valid.json")
+	invalidFile := filepath.Join(tmpDir, "invalid.json")
+	require.NoError(t, os.WriteFile(validFile, []byte(`{"a": 1}`), 0644))
+	require.NoError(t, os.WriteFile(invalidFile, []byte(`{"b": 1}`), 0644))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file://" + validFile, Success: true, FixedContent: `{"a": 2}`, Confidence: 0.9},
+				{IncidentURI: "file://" + invalidFile, Success: true, FixedContent: `{"b": 2`, Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	).Once()
+
+	config := DefaultBatchConfig()
+	config.GroupByFile = false
+	config.SyntaxCheck = true
+	bf := NewBatchFixer(mockProvider, tmpDir, false, config)
+
+	results, err := bf.FixViolationBatch(context.Background(), violation.Violation{
+		ID: "bump",
+		Incidents: []violation.Incident{
+			{URI: "file://" + validFile, LineNumber: 1},
+			{URI: "file://" + invalidFile, LineNumber: 1},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	byFile := map[string]FixResult{}
+	for _, result := range results {
+		byFile[result.FilePath] = result
+	}
+	assert.True(t, byFile["valid.json"].Success)
+	assert.False(t, byFile["invalid.json"].Success)
+	require.Error(t, byFile["invalid.json"].Error)
+	assert.Contains(t, byFile["invalid.json"].Error.Error(), "fixed content is not valid json")
+
+	content, err := os.ReadFile(validFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 2}`, string(content))
+	content, err = os.ReadFile(invalidFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b": 1}`, string(content))
+}
@@ -0,0 +1,199 @@
+package fixer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/provider"
+)
+
+const (
+	// tpmWindow is the rolling window tokens-per-minute is measured over
+	tpmWindow = time.Minute
+	// requestTokenOverhead estimates the prompt instructions, violation
+	// context and response sent with each request on top of the file content
+	requestTokenOverhead = 1000
+)
+
+// TPMTracker tracks tokens used over a rolling one-minute window. With a
+// limit, Acquire waits before a request that would push usage over it, so
+// we slow down ahead of the provider's tokens-per-minute rate limit instead
+// of being rejected with a 429. It is safe for concurrent use, so parallel
+// batches share one budget.
+type TPMTracker struct {
+	limit int
+
+	mu      sync.Mutex
+	entries []*tpmEntry
+
+	// Replaceable in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// tpmEntry is the tokens of one request, estimated until the response arrives
+type tpmEntry struct {
+	at     time.Time
+	tokens int
+}
+
+// NewTPMTracker creates a tracker. limit is the tokens-per-minute ceiling;
+// 0 only tracks the rate without waiting.
+func NewTPMTracker(limit int) *TPMTracker {
+	return &TPMTracker{
+		limit: limit,
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+}
+
+// Limit returns the tokens-per-minute ceiling (0 = none)
+func (t *TPMTracker) Limit() int {
+	return t.limit
+}
+
+// Current returns the tokens used in the last minute
+func (t *TPMTracker) Current() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(t.now())
+	return t.total()
+}
+
+// Acquire reserves estimatedTokens for a request, first waiting as long as
+// the request would push the last minute's usage over the limit. Call the
+// returned function with the request's actual token usage once it
+// completes. waited is how long Acquire slept.
+func (t *TPMTracker) Acquire(ctx context.Context, estimatedTokens int) (done func(actualTokens int), waited time.Duration, err error) {
+	for {
+		t.mu.Lock()
+		now := t.now()
+		t.prune(now)
+		delay := t.delayFor(now, estimatedTokens)
+		if delay <= 0 {
+			entry := &tpmEntry{at: now, tokens: estimatedTokens}
+			t.entries = append(t.entries, entry)
+			t.mu.Unlock()
+			return func(actualTokens int) {
+				t.mu.Lock()
+				defer t.mu.Unlock()
+				entry.tokens = actualTokens
+			}, waited, nil
+		}
+		t.mu.Unlock()
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, waited, err
+		}
+		waited += delay
+	}
+}
+
+// delayFor returns how long until a request of tokens fits under the limit.
+// A request larger than the limit on its own only waits for the window to
+// clear. Callers hold t.mu.
+func (t *TPMTracker) delayFor(now time.Time, tokens int) time.Duration {
+	if t.limit <= 0 || len(t.entries) == 0 {
+		return 0
+	}
+
+	used := t.total()
+	for _, entry := range t.entries {
+		if used+tokens <= t.limit {
+			break
+		}
+		// Waiting for this entry to leave the window frees its tokens
+		used -= entry.tokens
+		if used+tokens <= t.limit || used == 0 {
+			return entry.at.Add(tpmWindow).Sub(now)
+		}
+	}
+	return 0
+}
+
+// prune drops entries older than the window. Callers hold t.mu.
+func (t *TPMTracker) prune(now time.Time) {
+	cutoff := now.Add(-tpmWindow)
+	i := 0
+	for i < len(t.entries) && !t.entries[i].at.After(cutoff) {
+		i++
+	}
+	t.entries = t.entries[i:]
+}
+
+// total sums the tokens in the window. Callers hold t.mu.
+func (t *TPMTracker) total() int {
+	total := 0
+	for _, entry := range t.entries {
+		total += entry.tokens
+	}
+	return total
+}
+
+// String formats the current rate for progress output, e.g. "12.3k tokens/min (limit 30.0k)"
+func (t *TPMTracker) String() string {
+	s := formatTokenCount(t.Current()) + " tokens/min"
+	if t.limit > 0 {
+		s += " (limit " + formatTokenCount(t.limit) + ")"
+	}
+	return s
+}
+
+func formatTokenCount(tokens int) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d", tokens)
+	}
+	return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+}
+
+// estimateRequestTokens estimates the tokens of a request sending fileContents
+func estimateRequestTokens(fileContents ...string) int {
+	tokens := requestTokenOverhead
+	for _, content := range fileContents {
+		tokens += provider.EstimatePromptTokens(content)
+	}
+	return tokens
+}
+
+// pace waits until a request of estimatedTokens fits under the tracker's
+// limit and returns the function that records its actual usage. A nil
+// tracker doesn't pace.
+func pace(ctx context.Context, tracker *TPMTracker, estimatedTokens int) (func(actualTokens int), error) {
+	if tracker == nil {
+		return func(int) {}, nil
+	}
+	done, waited, err := tracker.Acquire(ctx, estimatedTokens)
+	if err != nil {
+		return nil, err
+	}
+	if waited > 0 {
+		fmt.Printf("   ⏳ Paused %s to stay under the tokens-per-minute limit (now %s)\n", waited.Round(time.Second), tracker)
+	}
+	return done, nil
+}
+
+// fixViolation requests a fix from p, paced by the rate tracker
+func (f *Fixer) fixViolation(ctx context.Context, p provider.Provider, req provider.FixRequest) (*provider.FixResponse, error) {
+	done, err := pace(ctx, f.rateTracker, estimateRequestTokens(req.FileContent))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.FixViolation(ctx, req)
+	if err == nil && resp != nil {
+		done(resp.TokensUsed)
+	}
+	return resp, err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+package fixer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// fakeClock drives a TPMTracker without real sleeping
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClockTracker(limit int) (*TPMTracker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	tracker := NewTPMTracker(limit)
+	tracker.now = func() time.Time {
+		clock.mu.Lock()
+		defer clock.mu.Unlock()
+		return clock.now
+	}
+	tracker.sleep = func(ctx context.Context, d time.Duration) error {
+		clock.advance(d)
+		clock.mu.Lock()
+		clock.sleeps = append(clock.sleeps, d)
+		clock.mu.Unlock()
+		return ctx.Err()
+	}
+	return tracker, clock
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestTPMTracker_RollingWindow(t *testing.T) {
+	tracker, clock := newFakeClockTracker(0)
+	ctx := context.Background()
+
+	done, waited, err := tracker.Acquire(ctx, 1000)
+	require.NoError(t, err)
+	assert.Zero(t, waited)
+	assert.Equal(t, 1000, tracker.Current())
+
+	// Actual usage replaces the estimate
+	done(4000)
+	assert.Equal(t, 4000, tracker.Current())
+
+	clock.advance(30 * time.Second)
+	done, _, err = tracker.Acquire(ctx, 2000)
+	require.NoError(t, err)
+	done(2000)
+	assert.Equal(t, 6000, tracker.Current())
+
+	// The first request leaves the window after a minute
+	clock.advance(31 * time.Second)
+	assert.Equal(t, 2000, tracker.Current())
+	assert.Equal(t, "2.0k tokens/min", tracker.String())
+}
+
+func TestTPMTracker_WaitsBeforeExceedingLimit(t *testing.T) {
+	tracker, clock := newFakeClockTracker(10000)
+	ctx := context.Background()
+
+	done, _, err := tracker.Acquire(ctx, 6000)
+	require.NoError(t, err)
+	done(6000)
+
+	clock.advance(20 * time.Second)
+	done, waited, err := tracker.Acquire(ctx, 3000)
+	require.NoError(t, err)
+	assert.Zero(t, waited, "9k of 10k fits")
+	done(3000)
+
+	// 5k more would reach 14k; the first 6k expire 40s from now
+	clock.advance(5 * time.Second)
+	_, waited, err = tracker.Acquire(ctx, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, 35*time.Second, waited)
+	assert.Equal(t, "8.0k tokens/min (limit 10.0k)", tracker.String())
+}
+
+func TestTPMTracker_OversizedRequestWaitsForEmptyWindow(t *testing.T) {
+	tracker, clock := newFakeClockTracker(1000)
+	ctx := context.Background()
+
+	done, _, err := tracker.Acquire(ctx, 500)
+	require.NoError(t, err)
+	done(500)
+
+	clock.advance(10 * time.Second)
+	_, waited, err := tracker.Acquire(ctx, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Second, waited)
+
+	// With an empty window an oversized request goes straight through
+	clock.advance(2 * time.Minute)
+	_, waited, err = tracker.Acquire(ctx, 5000)
+	require.NoError(t, err)
+	assert.Zero(t, waited)
+}
+
+func TestTPMTracker_CancelledWhileWaiting(t *testing.T) {
+	tracker, _ := newFakeClockTracker(1000)
+	_, _, err := tracker.Acquire(context.Background(), 1000)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = tracker.Acquire(ctx, 1000)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFixer_RateTrackerRecordsUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.java")
+	require.NoError(t, os.WriteFile(testFile, []byte("import javax.servlet.*;"), 0644))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(&provider.FixResponse{
+		Success:      true,
+		FixedContent: "import jakarta.servlet.*;",
+		TokensUsed:   1234,
+	}, nil)
+
+	tracker, _ := newFakeClockTracker(50000)
+	fixer := New(mockProvider, tmpDir, false)
+	fixer.SetRateTracker(tracker)
+
+	v := violation.Violation{ID: "test-violation"}
+	incident := violation.Incident{URI: "file://" + testFile, LineNumber: 1}
+	_, err := fixer.FixIncident(context.Background(), v, incident)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1234, tracker.Current())
+	mockProvider.AssertExpectations(t)
+}
@@ -0,0 +1,89 @@
+package gitutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxBranchLength is the default maximum length of generated branch
+// names. Git itself allows longer refs, but many hosts and filesystems don't.
+const DefaultMaxBranchLength = 100
+
+// branchHashLength is the number of hex characters of the hash appended to
+// truncated branch name components
+const branchHashLength = 8
+
+var (
+	// invalidBranchCharsRegex matches runs of characters that are not allowed
+	// in a single branch name component (slashes would create hierarchy)
+	invalidBranchCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	// repeatedSeparatorsRegex matches runs of separators left by sanitization
+	repeatedSeparatorsRegex = regexp.MustCompile(`[-.]{2,}`)
+)
+
+// SanitizeBranchComponent makes an arbitrary identifier (e.g. a violation ID
+// like "java-ee/javax:servlet") safe to use as part of a git branch name.
+// Invalid characters are replaced with '-', and leading or trailing
+// separators are removed.
+func SanitizeBranchComponent(s string) string {
+	s = invalidBranchCharsRegex.ReplaceAllString(s, "-")
+	s = repeatedSeparatorsRegex.ReplaceAllStringFunc(s, func(run string) string {
+		if strings.Contains(run, "-") {
+			return "-"
+		}
+		return "." // Only dots; ".." is invalid in git refs
+	})
+	s = strings.TrimSuffix(s, ".lock")
+	return strings.Trim(s, "-.")
+}
+
+// BuildBranchName joins prefix, an identifier and a suffix with '-' into a
+// valid branch name of at most maxLen characters (0 = DefaultMaxBranchLength).
+// The identifier is sanitized; if the result is too long, the identifier is
+// truncated and a short hash of it is appended so distinct identifiers still
+// produce distinct names. Empty parts are omitted.
+func BuildBranchName(prefix, id, suffix string, maxLen int) string {
+	if maxLen <= 0 {
+		maxLen = DefaultMaxBranchLength
+	}
+	original := id
+	id = SanitizeBranchComponent(id)
+
+	name := joinBranchParts(prefix, id, suffix)
+	if len(name) <= maxLen {
+		return name
+	}
+
+	if id != "" {
+		sum := sha256.Sum256([]byte(original))
+		hash := hex.EncodeToString(sum[:])[:branchHashLength]
+
+		// Room left for the identifier once everything else is accounted for
+		room := maxLen - len(joinBranchParts(prefix, hash, suffix))
+		if room > 1 {
+			id = strings.TrimRight(id[:min(len(id), room-1)], "-.") + "-" + hash
+		} else {
+			id = hash
+		}
+		name = joinBranchParts(prefix, id, suffix)
+	}
+
+	// The prefix and suffix alone are too long; hard truncate as a last resort
+	if len(name) > maxLen {
+		name = strings.TrimRight(name[:maxLen], "-./")
+	}
+	return name
+}
+
+// joinBranchParts joins the non-empty parts with '-'
+func joinBranchParts(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "-")
+}
@@ -0,0 +1,79 @@
+package gitutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeBranchComponent(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"javax-to-jakarta-00001", "javax-to-jakarta-00001"},
+		{"java-ee/javax:servlet", "java-ee-javax-servlet"},
+		{"rule with spaces~^?*[", "rule-with-spaces"},
+		{"..hidden..rule..", "hidden.rule"},
+		{"-leading/-/trailing-", "leading-trailing"},
+		{"config.lock", "config"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeBranchComponent(tt.input))
+		})
+	}
+}
+
+func TestBuildBranchName(t *testing.T) {
+	t.Run("invalid characters", func(t *testing.T) {
+		name := BuildBranchName("kantra-ai/remediation", "java-ee/javax:servlet", "1700000000", 0)
+
+		assert.Equal(t, "kantra-ai/remediation-java-ee-javax-servlet-1700000000", name)
+		assert.NoError(t, validateBranchName(name))
+	})
+
+	t.Run("empty identifier is omitted", func(t *testing.T) {
+		assert.Equal(t, "kantra-ai/remediation-1700000000",
+			BuildBranchName("kantra-ai/remediation", "", "1700000000", 0))
+	})
+
+	t.Run("long identifier is truncated and hashed", func(t *testing.T) {
+		longID := "eap8/eap7/" + strings.Repeat("javax-to-jakarta-", 20) + "00010"
+
+		name := BuildBranchName("kantra-ai/remediation", longID, "1700000000", 0)
+
+		assert.LessOrEqual(t, len(name), DefaultMaxBranchLength)
+		assert.True(t, strings.HasPrefix(name, "kantra-ai/remediation-eap8-eap7-javax-to-jakarta"))
+		assert.True(t, strings.HasSuffix(name, "-1700000000"), "suffix must be kept: %s", name)
+		assert.NoError(t, validateBranchName(name))
+	})
+
+	t.Run("truncated identifiers stay unique", func(t *testing.T) {
+		base := strings.Repeat("a", 200)
+
+		first := BuildBranchName("prefix", base+"-one", "1", 60)
+		second := BuildBranchName("prefix", base+"-two", "1", 60)
+
+		assert.Len(t, first, 60)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("custom max length", func(t *testing.T) {
+		name := BuildBranchName("kantra-ai", "violation-with-a-fairly-long-id", "42", 30)
+
+		require.LessOrEqual(t, len(name), 30)
+		assert.True(t, strings.HasPrefix(name, "kantra-ai-"))
+		assert.True(t, strings.HasSuffix(name, "-42"))
+		assert.NoError(t, validateBranchName(name))
+	})
+
+	t.Run("short names are unchanged", func(t *testing.T) {
+		assert.Equal(t, "kantra-ai/remediation-phase-1-1700000000",
+			BuildBranchName("kantra-ai/remediation", "phase-1", "1700000000", 0))
+	})
+}
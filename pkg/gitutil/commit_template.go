@@ -0,0 +1,133 @@
+package gitutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// CommitMessageData is the data available to a commit message template.
+// The violation fields are empty for an at-end commit spanning several
+// violations; the file fields describe the first fix, and Fixes lists all.
+type CommitMessageData struct {
+	ViolationID  string   // Empty for at-end commits spanning several violations
+	ViolationIDs []string // Every violation in the commit, sorted
+	Description  string
+	Category     string
+	Effort       int
+	FilePath     string
+	Line         int
+	Confidence   float64
+	Explanation  string // The first fix's explanation from the provider
+	Provider     string
+	Strategy     string // per-incident, per-violation or at-end
+	Fixes        []CommitFixData
+	Cost         float64 // Total for the commit
+	Tokens       int     // Total for the commit
+}
+
+// CommitFixData describes one fix in a commit
+type CommitFixData struct {
+	ViolationID string
+	FilePath    string
+	Line        int
+	Confidence  float64
+	Explanation string
+}
+
+// CommitTemplate renders commit messages from a Go text/template, e.g.
+//
+//	fix(deps): {{.ViolationID}} {{.Description}}
+type CommitTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseCommitTemplate parses a commit message template. Referencing a field
+// that doesn't exist is an error here rather than when the first commit is made.
+func ParseCommitTemplate(text string) (*CommitTemplate, error) {
+	tmpl, err := template.New("commit").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit template: %w", err)
+	}
+
+	ct := &CommitTemplate{tmpl: tmpl}
+	sample := CommitMessageData{
+		ViolationID:  "sample-violation",
+		ViolationIDs: []string{"sample-violation"},
+		Fixes:        []CommitFixData{{ViolationID: "sample-violation"}},
+	}
+	if _, err := ct.Render(sample); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+// LoadCommitTemplate parses value as a commit message template. If value
+// is the path of an existing file, the template is read from the file.
+func LoadCommitTemplate(value string) (*CommitTemplate, error) {
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit template '%s': %w", value, err)
+		}
+		value = string(data)
+	}
+	return ParseCommitTemplate(value)
+}
+
+// Render renders the commit message for data
+func (ct *CommitTemplate) Render(data CommitMessageData) (string, error) {
+	var buf bytes.Buffer
+	if err := ct.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render commit template: %w", err)
+	}
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("commit template rendered an empty message")
+	}
+	return message + "\n", nil
+}
+
+// newCommitMessageData builds template data for a commit containing fixes
+func newCommitMessageData(fixes []FixRecord, providerName string, strategy CommitStrategy) CommitMessageData {
+	data := CommitMessageData{
+		Provider: providerName,
+		Strategy: strategy.String(),
+	}
+
+	violationIDs := make(map[string]bool)
+	for _, fix := range fixes {
+		violationIDs[fix.Violation.ID] = true
+		data.Fixes = append(data.Fixes, CommitFixData{
+			ViolationID: fix.Violation.ID,
+			FilePath:    fix.Result.FilePath,
+			Line:        fix.Incident.LineNumber,
+			Confidence:  fix.Result.Confidence,
+			Explanation: fix.Result.Explanation,
+		})
+		data.Cost += fix.Result.Cost
+		data.Tokens += fix.Result.TokensUsed
+	}
+	for id := range violationIDs {
+		data.ViolationIDs = append(data.ViolationIDs, id)
+	}
+	sort.Strings(data.ViolationIDs)
+
+	if len(fixes) > 0 {
+		first := fixes[0]
+		if len(data.ViolationIDs) == 1 {
+			data.ViolationID = first.Violation.ID
+			data.Description = first.Violation.Description
+			data.Category = first.Violation.Category
+			data.Effort = first.Violation.Effort
+		}
+		data.FilePath = first.Result.FilePath
+		data.Line = first.Incident.LineNumber
+		data.Confidence = first.Result.Confidence
+		data.Explanation = first.Result.Explanation
+	}
+	return data
+}
@@ -0,0 +1,127 @@
+package gitutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestParseCommitTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "valid", text: "fix(deps): {{.ViolationID}} {{.Description}}"},
+		{name: "range over fixes", text: "fix: {{range .Fixes}}{{.FilePath}}:{{.Line}} {{end}}"},
+		{name: "syntax error", text: "fix: {{.ViolationID", wantErr: "invalid commit template"},
+		{name: "unknown field", text: "fix: {{.Violation}}", wantErr: "failed to render commit template"},
+		{name: "empty message", text: "   ", wantErr: "empty message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseCommitTemplate(tt.text)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, tmpl)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, tmpl)
+		})
+	}
+}
+
+func TestLoadCommitTemplate(t *testing.T) {
+	t.Run("inline", func(t *testing.T) {
+		tmpl, err := LoadCommitTemplate("fix: {{.ViolationID}}")
+		require.NoError(t, err)
+
+		message, err := tmpl.Render(CommitMessageData{ViolationID: "v1"})
+		require.NoError(t, err)
+		assert.Equal(t, "fix: v1\n", message)
+	})
+
+	t.Run("from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "commit.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte("fix({{.Category}}): {{.ViolationID}}\n\nProvider: {{.Provider}}\n"), 0644))
+
+		tmpl, err := LoadCommitTemplate(path)
+		require.NoError(t, err)
+
+		message, err := tmpl.Render(CommitMessageData{ViolationID: "v1", Category: "mandatory", Provider: "claude"})
+		require.NoError(t, err)
+		assert.Equal(t, "fix(mandatory): v1\n\nProvider: claude\n", message)
+	})
+}
+
+func TestNewCommitMessageData(t *testing.T) {
+	v1 := violation.Violation{ID: "v1", Description: "Replace javax", Category: "mandatory", Effort: 3}
+	v2 := violation.Violation{ID: "v2", Description: "Update config", Category: "optional", Effort: 1}
+
+	fixes := []FixRecord{
+		{Violation: v1, Incident: violation.Incident{LineNumber: 10}, Result: fixer.FixResult{FilePath: "A.java", Confidence: 0.9, Cost: 0.01, TokensUsed: 100}},
+		{Violation: v1, Incident: violation.Incident{LineNumber: 20}, Result: fixer.FixResult{FilePath: "B.java", Confidence: 0.8, Cost: 0.02, TokensUsed: 200}},
+	}
+
+	t.Run("single violation", func(t *testing.T) {
+		data := newCommitMessageData(fixes, "claude", StrategyPerViolation)
+		assert.Equal(t, "v1", data.ViolationID)
+		assert.Equal(t, []string{"v1"}, data.ViolationIDs)
+		assert.Equal(t, "Replace javax", data.Description)
+		assert.Equal(t, "mandatory", data.Category)
+		assert.Equal(t, 3, data.Effort)
+		assert.Equal(t, "A.java", data.FilePath)
+		assert.Equal(t, 10, data.Line)
+		assert.Equal(t, 0.9, data.Confidence)
+		assert.Equal(t, "claude", data.Provider)
+		assert.Equal(t, "per-violation", data.Strategy)
+		assert.Len(t, data.Fixes, 2)
+		assert.InDelta(t, 0.03, data.Cost, 0.0001)
+		assert.Equal(t, 300, data.Tokens)
+	})
+
+	t.Run("several violations", func(t *testing.T) {
+		all := append(fixes, FixRecord{Violation: v2, Incident: violation.Incident{LineNumber: 5}, Result: fixer.FixResult{FilePath: "app.yaml"}})
+		data := newCommitMessageData(all, "openai", StrategyAtEnd)
+		assert.Empty(t, data.ViolationID)
+		assert.Empty(t, data.Description)
+		assert.Equal(t, []string{"v1", "v2"}, data.ViolationIDs)
+		assert.Equal(t, "at-end", data.Strategy)
+		assert.Len(t, data.Fixes, 3)
+	})
+}
+
+func TestCommitTracker_MessageTemplate(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	configGitUser(t, tmpDir)
+
+	tmpl, err := ParseCommitTemplate("fix({{.Category}}): {{.ViolationID}} in {{.FilePath}}:{{.Line}}")
+	require.NoError(t, err)
+
+	tracker := NewCommitTracker(StrategyPerIncident, tmpDir, "claude")
+	tracker.SetMessageTemplate(tmpl)
+
+	path := filepath.Join(tmpDir, "Main.java")
+	require.NoError(t, os.WriteFile(path, []byte("fixed content"), 0644))
+
+	v := violation.Violation{ID: "javax-to-jakarta", Description: "Replace javax", Category: "mandatory"}
+	incident := violation.Incident{URI: "file://" + path, LineNumber: 42}
+	result := &fixer.FixResult{FilePath: "Main.java", Success: true}
+
+	require.NoError(t, tracker.TrackFix(v, incident, result))
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B")
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "fix(mandatory): javax-to-jakarta in Main.java:42\n\n", string(output))
+}
@@ -7,8 +7,11 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/version"
 )
 
 const (
@@ -26,6 +29,14 @@ const (
 	// retryBackoffBase is the base duration for exponential backoff between retries
 	// Actual backoff = attempt * retryBackoffBase (1s, 2s, 3s)
 	retryBackoffBase = 1 * time.Second
+
+	// secondaryRateLimitWait is how long to wait after hitting a secondary rate limit
+	// that doesn't say when to retry (GitHub recommends waiting at least one minute)
+	secondaryRateLimitWait = 1 * time.Minute
+
+	// maxRateLimitWait caps how long we are willing to wait for a rate limit to reset;
+	// longer waits fail immediately instead of stalling the run
+	maxRateLimitWait = 15 * time.Minute
 )
 
 // GitHubClient handles GitHub API interactions
@@ -35,6 +46,9 @@ type GitHubClient struct {
 	repo    string
 	baseURL string
 	client  *http.Client
+	sleep   func(time.Duration) // Used for retry backoff (nil = time.Sleep)
+
+	userAgent string // Sent with every request (empty = kantra-ai/<version>)
 }
 
 // PullRequestRequest represents a GitHub PR creation request
@@ -97,6 +111,157 @@ func NewGitHubClient(workingDir string, token string) (*GitHubClient, error) {
 	}, nil
 }
 
+// SetUserAgent sets the User-Agent sent with every request (empty =
+// kantra-ai/<version>)
+func (c *GitHubClient) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// UserAgent returns the User-Agent sent with every request
+func (c *GitHubClient) UserAgent() string {
+	if c.userAgent == "" {
+		return version.UserAgent()
+	}
+	return c.userAgent
+}
+
+// doRequest executes an authenticated GitHub API request. Transient server errors
+// (502, 503, 504) are retried with backoff, and rate limit responses (429, or 403
+// for secondary rate limits) are retried after the delay GitHub asks for via
+// Retry-After or X-RateLimit-Reset. The caller must close the response body.
+func (c *GitHubClient) doRequest(method, url string, body []byte) (*http.Response, error) {
+	var resp *http.Response
+	var lastErr error
+	wait := time.Duration(0)
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			c.wait(wait)
+		}
+		// Default backoff before the next attempt (attempt * retryBackoffBase)
+		wait = time.Duration(attempt+1) * retryBackoffBase
+
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		httpReq, err := http.NewRequest(method, url, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
+		httpReq.Header.Set("Accept", "application/vnd.github.v3+json")
+		httpReq.Header.Set("User-Agent", c.UserAgent())
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err = c.client.Do(httpReq)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		lastAttempt := attempt == maxRetries-1
+		if delay, limited := rateLimitDelay(resp); limited {
+			if lastAttempt || delay > maxRateLimitWait {
+				break
+			}
+			resp.Body.Close()
+			wait = delay
+			lastErr = fmt.Errorf("HTTP %d rate limited (attempt %d)", resp.StatusCode, attempt+1)
+			continue
+		}
+
+		// Success or non-retriable error
+		if resp.StatusCode != http.StatusServiceUnavailable &&
+			resp.StatusCode != http.StatusBadGateway &&
+			resp.StatusCode != http.StatusGatewayTimeout {
+			break
+		}
+		if lastAttempt {
+			break
+		}
+
+		// Close response body before retrying
+		resp.Body.Close()
+		lastErr = fmt.Errorf("HTTP %d (attempt %d)", resp.StatusCode, attempt+1)
+	}
+
+	if resp == nil {
+		return nil, fmt.Errorf("all retry attempts failed: %w", lastErr)
+	}
+	return resp, nil
+}
+
+// wait sleeps for d using the client's sleep function
+func (c *GitHubClient) wait(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// rateLimitDelay reports whether resp is a GitHub rate limit response and how long
+// to wait before retrying. Plain 403s (e.g. missing permissions) are not rate limits.
+func rateLimitDelay(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return delay, true
+	}
+
+	// Primary rate limit exhausted: wait until the window resets
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			delay := time.Until(time.Unix(reset, 0))
+			if delay < 0 {
+				delay = 0
+			}
+			return delay, true
+		}
+		return secondaryRateLimitWait, true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryRateLimitWait, true
+	}
+
+	// A 403 without rate limit headers is only a rate limit if the message says so.
+	// Read the body and put it back so callers can still report the error.
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err == nil && strings.Contains(strings.ToLower(string(respBody)), "rate limit") {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header value (seconds or an HTTP date)
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		delay := time.Until(t)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
 // ParseGitHubURL extracts owner and repo from a GitHub remote URL
 // Supports: https://github.com/owner/repo.git, git@github.com:owner/repo.git
 func ParseGitHubURL(remoteURL string) (owner, repo string, err error) {
@@ -129,48 +294,11 @@ func (c *GitHubClient) CreatePullRequest(req PullRequestRequest) (*PullRequestRe
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
-	httpReq.Header.Set("Accept", "application/vnd.github.v3+json")
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	// Execute request with retry logic
-	var resp *http.Response
-	var lastErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff using retryBackoffBase
-			time.Sleep(time.Duration(attempt) * retryBackoffBase)
-		}
-
-		resp, err = c.client.Do(httpReq)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		// Success or non-retriable error
-		if resp.StatusCode != http.StatusServiceUnavailable &&
-			resp.StatusCode != http.StatusBadGateway &&
-			resp.StatusCode != http.StatusGatewayTimeout {
-			break
-		}
-
-		// Close response body before retrying
-		resp.Body.Close()
-		lastErr = fmt.Errorf("HTTP %d (attempt %d)", resp.StatusCode, attempt+1)
-	}
-
-	if resp == nil {
-		return nil, fmt.Errorf("all retry attempts failed: %w", lastErr)
+	resp, err := c.doRequest("POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
 	}
-	// Ensure response body is always closed (whether we broke or completed loop)
 	defer resp.Body.Close()
 
 	// Read response body with size limit to prevent memory exhaustion
@@ -204,18 +332,8 @@ func (c *GitHubClient) GetDefaultBranch() (string, error) {
 	// Build API URL
 	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, c.owner, c.repo)
 
-	// Create HTTP request
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
-	httpReq.Header.Set("Accept", "application/vnd.github.v3+json")
-
 	// Execute request
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.doRequest("GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to get repository info: %w", err)
 	}
@@ -308,46 +426,10 @@ func (c *GitHubClient) CreateCommitStatus(sha string, req CommitStatusRequest) (
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
-	httpReq.Header.Set("Accept", "application/vnd.github.v3+json")
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	// Execute request with retry logic
-	var resp *http.Response
-	var lastErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			time.Sleep(time.Duration(attempt) * retryBackoffBase)
-		}
-
-		resp, err = c.client.Do(httpReq)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		// Success or non-retriable error
-		if resp.StatusCode != http.StatusServiceUnavailable &&
-			resp.StatusCode != http.StatusBadGateway &&
-			resp.StatusCode != http.StatusGatewayTimeout {
-			break
-		}
-
-		// Close response body before retrying
-		resp.Body.Close()
-		lastErr = fmt.Errorf("HTTP %d (attempt %d)", resp.StatusCode, attempt+1)
-	}
-
-	if resp == nil {
-		return nil, fmt.Errorf("all retry attempts failed: %w", lastErr)
+	resp, err := c.doRequest("POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -427,46 +509,10 @@ func (c *GitHubClient) CreateReviewComment(prNumber int, req ReviewCommentReques
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
-	httpReq.Header.Set("Accept", "application/vnd.github.v3+json")
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	// Execute request with retry logic
-	var resp *http.Response
-	var lastErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			time.Sleep(time.Duration(attempt) * retryBackoffBase)
-		}
-
-		resp, err = c.client.Do(httpReq)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		// Success or non-retriable error
-		if resp.StatusCode != http.StatusServiceUnavailable &&
-			resp.StatusCode != http.StatusBadGateway &&
-			resp.StatusCode != http.StatusGatewayTimeout {
-			break
-		}
-
-		// Close response body before retrying
-		resp.Body.Close()
-		lastErr = fmt.Errorf("HTTP %d (attempt %d)", resp.StatusCode, attempt+1)
-	}
-
-	if resp == nil {
-		return nil, fmt.Errorf("all retry attempts failed: %w", lastErr)
+	resp, err := c.doRequest("POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/version"
 )
 
 func TestParseGitHubURL(t *testing.T) {
@@ -659,3 +661,141 @@ func TestGitHubClient_CreateReviewComment(t *testing.T) {
 		assert.Equal(t, 3, attempts, "should have retried twice before succeeding")
 	})
 }
+
+func TestGitHubClient_RateLimitRetry(t *testing.T) {
+	t.Run("honors Retry-After and secondary rate limits", func(t *testing.T) {
+		calls := 0
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			var req PullRequestRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			bodies = append(bodies, req.Title)
+
+			switch calls {
+			case 1:
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"message": "Too many requests"}`))
+			case 2:
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+			default:
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(PullRequestResponse{Number: 7})
+			}
+		}))
+		defer server.Close()
+
+		var waits []time.Duration
+		client := &GitHubClient{
+			token:   "test-token",
+			owner:   "test-owner",
+			repo:    "test-repo",
+			baseURL: server.URL,
+			client:  server.Client(),
+			sleep:   func(d time.Duration) { waits = append(waits, d) },
+		}
+
+		resp, err := client.CreatePullRequest(PullRequestRequest{Title: "Test PR"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 7, resp.Number)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []time.Duration{7 * time.Second, secondaryRateLimitWait}, waits)
+		// The request body is re-sent on every attempt
+		assert.Equal(t, []string{"Test PR", "Test PR", "Test PR"}, bodies)
+	})
+
+	t.Run("permission 403 is not retried", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		}))
+		defer server.Close()
+
+		client := &GitHubClient{
+			token:   "test-token",
+			owner:   "test-owner",
+			repo:    "test-repo",
+			baseURL: server.URL,
+			client:  server.Client(),
+			sleep:   func(time.Duration) { t.Fatal("unexpected retry") },
+		}
+
+		_, err := client.CreatePullRequest(PullRequestRequest{Title: "Test PR"})
+
+		require.Error(t, err)
+		ghErr, ok := err.(*GitHubError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, ghErr.StatusCode)
+		assert.Contains(t, ghErr.Message, "Resource not accessible")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "Too many requests"}`))
+		}))
+		defer server.Close()
+
+		client := &GitHubClient{
+			token:   "test-token",
+			owner:   "test-owner",
+			repo:    "test-repo",
+			baseURL: server.URL,
+			client:  server.Client(),
+			sleep:   func(time.Duration) {},
+		}
+
+		_, err := client.CreatePullRequest(PullRequestRequest{Title: "Test PR"})
+
+		require.Error(t, err)
+		ghErr, ok := err.(*GitHubError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, ghErr.StatusCode)
+		assert.Equal(t, maxRetries, calls)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("30")
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), delay.Seconds(), 2)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestGitHubClient_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(PullRequestResponse{Number: 1, State: "open"})
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{token: "test-token", owner: "o", repo: "r", baseURL: server.URL, client: server.Client()}
+	_, err := client.CreatePullRequest(PullRequestRequest{Title: "t", Head: "h", Base: "main"})
+	require.NoError(t, err)
+
+	client.SetUserAgent("acme-migrations/1.0")
+	_, err = client.CreatePullRequest(PullRequestRequest{Title: "t", Head: "h", Base: "main"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{version.UserAgent(), "acme-migrations/1.0"}, userAgents)
+}
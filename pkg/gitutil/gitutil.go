@@ -1,6 +1,7 @@
 package gitutil
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -74,14 +75,16 @@ func validateFilePath(workingDir, filePath string) (string, error) {
 	return relPath, nil
 }
 
-// IsGitRepository checks if the given directory is a git repository
+// IsGitRepository checks if the given directory is a git repository.
+// Submodules and worktrees have a .git file pointing at the real git directory,
+// so both a .git directory and a .git file are accepted.
 func IsGitRepository(dir string) bool {
 	gitDir := filepath.Join(dir, ".git")
 	info, err := os.Stat(gitDir)
 	if err != nil {
 		return false
 	}
-	return info.IsDir()
+	return info.IsDir() || info.Mode().IsRegular()
 }
 
 // HasUncommittedChanges checks if there are uncommitted changes in the repository
@@ -129,7 +132,18 @@ func StageFile(workingDir string, filePath string) error {
 
 // CreateCommit creates a git commit with the given message and returns the commit SHA
 func CreateCommit(workingDir string, message string) (string, error) {
-	cmd := exec.Command("git", "commit", "-m", message)
+	return createCommit(workingDir, message)
+}
+
+// CreateCommitNoVerify is like CreateCommit but skips the pre-commit and
+// commit-msg hooks (git commit --no-verify)
+func CreateCommitNoVerify(workingDir string, message string) (string, error) {
+	return createCommit(workingDir, message, "--no-verify")
+}
+
+func createCommit(workingDir string, message string, extraArgs ...string) (string, error) {
+	args := append([]string{"commit", "-m", message}, extraArgs...)
+	cmd := exec.Command("git", args...)
 	cmd.Dir = workingDir
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("failed to create commit: %w\nOutput: %s", err, string(output))
@@ -154,6 +168,22 @@ func ResetChanges(workingDir string) error {
 	return nil
 }
 
+// RestoreFile discards the uncommitted changes to one file, restoring its
+// content at HEAD
+func RestoreFile(workingDir string, filePath string) error {
+	relPath, err := validateFilePath(workingDir, filePath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "checkout", "HEAD", "--", relPath)
+	cmd.Dir = workingDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore %s: %w\nOutput: %s", filePath, err, string(output))
+	}
+	return nil
+}
+
 // IsGitInstalled checks if git is installed and available in PATH
 func IsGitInstalled() bool {
 	_, err := exec.LookPath("git")
@@ -182,6 +212,83 @@ func GetCurrentCommitSHA(workingDir string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// CommitDiff returns the diff introduced by a commit, limited to paths if any are given
+func CommitDiff(workingDir string, sha string, paths []string) (string, error) {
+	if !isCommitSHA(sha) {
+		return "", fmt.Errorf("invalid commit SHA: %q", sha)
+	}
+	return gitDiff(workingDir, []string{"show", "--format=", sha}, paths)
+}
+
+// WorkingTreeDiff returns the uncommitted changes against HEAD, limited to paths if any are given
+func WorkingTreeDiff(workingDir string, paths []string) (string, error) {
+	return gitDiff(workingDir, []string{"diff", "HEAD"}, paths)
+}
+
+// ChangedFilesSince returns the files changed between ref and HEAD that are
+// still present, relative to workingDir; changes outside workingDir are left
+// out. The result is non-nil even if nothing changed.
+func ChangedFilesSince(workingDir string, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref: %q", ref)
+	}
+	verify := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	verify.Dir = workingDir
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q in %s", ref, workingDir)
+	}
+
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", "--diff-filter=d", ref+"..HEAD")
+	cmd.Dir = workingDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", ref, err)
+	}
+
+	files := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// gitDiff runs a git diff-producing command with validated pathspecs
+func gitDiff(workingDir string, args []string, paths []string) (string, error) {
+	if len(paths) > 0 {
+		args = append(args, "--")
+		for _, path := range paths {
+			cleanPath, err := validateFilePath(workingDir, path)
+			if err != nil {
+				return "", fmt.Errorf("invalid file path: %w", err)
+			}
+			args = append(args, cleanPath)
+		}
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workingDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+	return string(output), nil
+}
+
+// isCommitSHA reports whether s looks like an abbreviated or full commit SHA
+func isCommitSHA(s string) bool {
+	if len(s) < 4 || len(s) > 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
 // CreateBranch creates and checks out a new branch
 func CreateBranch(workingDir string, branchName string) error {
 	// Validate branch name to prevent command injection
@@ -227,6 +334,27 @@ func PushBranch(workingDir string, branchName string) error {
 	return nil
 }
 
+// RemoteBranchExists reports whether branchName exists on remote origin
+func RemoteBranchExists(workingDir string, branchName string) (bool, error) {
+	// Validate branch name to prevent command injection
+	if err := validateBranchName(branchName); err != nil {
+		return false, fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	// --exit-code makes ls-remote exit 2 when no ref matches
+	cmd := exec.Command("git", "ls-remote", "--exit-code", "--heads", "origin", "refs/heads/"+branchName)
+	cmd.Dir = workingDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to list remote branches: %w\nOutput: %s", err, string(output))
+	}
+	return true, nil
+}
+
 // GetRemoteURL gets the URL for the 'origin' remote
 func GetRemoteURL(workingDir string) (string, error) {
 	cmd := exec.Command("git", "remote", "get-url", "origin")
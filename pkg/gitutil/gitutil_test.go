@@ -66,6 +66,12 @@ func TestIsGitRepository(t *testing.T) {
 	t.Run("non-existent directory", func(t *testing.T) {
 		assert.False(t, IsGitRepository("/nonexistent/directory"))
 	})
+
+	t.Run("submodule with .git file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".git"), []byte("gitdir: ../.git/modules/sub\n"), 0644))
+		assert.True(t, IsGitRepository(tmpDir))
+	})
 }
 
 func TestIsGitInstalled(t *testing.T) {
@@ -169,6 +175,30 @@ func TestStageFile(t *testing.T) {
 	})
 }
 
+func TestRestoreFile(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	testFile := filepath.Join(tmpDir, "test.txt")
+	require.NoError(t, createAndCommitFile(t, tmpDir, testFile, "committed"))
+	other := filepath.Join(tmpDir, "other.txt")
+	require.NoError(t, createAndCommitFile(t, tmpDir, other, "committed"))
+
+	require.NoError(t, os.WriteFile(testFile, []byte("modified"), 0644))
+	require.NoError(t, os.WriteFile(other, []byte("modified"), 0644))
+
+	require.NoError(t, RestoreFile(tmpDir, "test.txt"))
+
+	content, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, "committed", string(content))
+
+	// Other files keep their changes
+	content, err = os.ReadFile(other)
+	require.NoError(t, err)
+	assert.Equal(t, "modified", string(content))
+
+	assert.Error(t, RestoreFile(tmpDir, "../outside.txt"))
+}
+
 func TestCreateCommit(t *testing.T) {
 	t.Run("create commit with staged changes", func(t *testing.T) {
 		tmpDir := createTestGitRepo(t)
@@ -219,4 +249,143 @@ func TestCreateCommit(t *testing.T) {
 		_, err := CreateCommit(tmpDir, "Empty commit")
 		assert.Error(t, err)
 	})
+
+	t.Run("no-verify skips failing hooks", func(t *testing.T) {
+		tmpDir := createTestGitRepo(t)
+
+		hook := filepath.Join(tmpDir, ".git", "hooks", "pre-commit")
+		require.NoError(t, os.MkdirAll(filepath.Dir(hook), 0755))
+		require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+		testFile := filepath.Join(tmpDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("content"), 0644))
+		require.NoError(t, StageFile(tmpDir, "test.txt"))
+
+		_, err := CreateCommit(tmpDir, "Blocked by hook")
+		assert.Error(t, err)
+
+		sha, err := CreateCommitNoVerify(tmpDir, "Hook skipped")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, sha)
+	})
+}
+
+func TestCommitDiff(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "a.txt"), "old\n"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("new\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("other\n"), 0644))
+	require.NoError(t, StageFile(tmpDir, "a.txt"))
+	require.NoError(t, StageFile(tmpDir, "b.txt"))
+	sha, err := CreateCommit(tmpDir, "change files")
+	require.NoError(t, err)
+
+	t.Run("limited to paths", func(t *testing.T) {
+		diff, err := CommitDiff(tmpDir, sha, []string{"a.txt"})
+		require.NoError(t, err)
+		assert.Contains(t, diff, "-old")
+		assert.Contains(t, diff, "+new")
+		assert.NotContains(t, diff, "b.txt")
+	})
+
+	t.Run("whole commit", func(t *testing.T) {
+		diff, err := CommitDiff(tmpDir, sha, nil)
+		require.NoError(t, err)
+		assert.Contains(t, diff, "b.txt")
+	})
+
+	t.Run("invalid SHA", func(t *testing.T) {
+		_, err := CommitDiff(tmpDir, "--output=x", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("path outside repository", func(t *testing.T) {
+		_, err := CommitDiff(tmpDir, sha, []string{"../etc/passwd"})
+		assert.Error(t, err)
+	})
+}
+
+func TestWorkingTreeDiff(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "a.txt"), "old\n"))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("new\n"), 0644))
+
+	diff, err := WorkingTreeDiff(tmpDir, []string{"a.txt"})
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+new")
+}
+
+func TestChangedFilesSince(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "src", "Old.java"), "old\n"))
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "src", "Gone.java"), "gone\n"))
+	base, err := GetCurrentCommitSHA(tmpDir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "src", "Gone.java")))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("readme\n"), 0644))
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "src", "New.java"), "new\n"))
+	// Uncommitted changes aren't included
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "Old.java"), []byte("changed\n"), 0644))
+
+	files, err := ChangedFilesSince(tmpDir, base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "src/New.java"}, files, "deleted files are left out")
+
+	t.Run("relative to a subdirectory", func(t *testing.T) {
+		files, err := ChangedFilesSince(filepath.Join(tmpDir, "src"), base)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"New.java"}, files)
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		files, err := ChangedFilesSince(tmpDir, "HEAD")
+		require.NoError(t, err)
+		assert.NotNil(t, files)
+		assert.Empty(t, files)
+	})
+
+	t.Run("invalid refs", func(t *testing.T) {
+		_, err := ChangedFilesSince(tmpDir, "no-such-branch")
+		assert.ErrorContains(t, err, `unknown git ref "no-such-branch"`)
+		_, err = ChangedFilesSince(tmpDir, "--output=/tmp/x")
+		assert.ErrorContains(t, err, "invalid git ref")
+	})
+}
+
+func TestRemoteBranchExists(t *testing.T) {
+	remoteDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare")
+	cmd.Dir = remoteDir
+	require.NoError(t, cmd.Run())
+
+	tmpDir := createTestGitRepo(t)
+	require.NoError(t, createAndCommitFile(t, tmpDir, filepath.Join(tmpDir, "a.txt"), "content\n"))
+	for _, args := range [][]string{
+		{"remote", "add", "origin", remoteDir},
+		{"branch", "develop"},
+		{"push", "origin", "develop"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run(), "git %v", args)
+	}
+
+	exists, err := RemoteBranchExists(tmpDir, "develop")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = RemoteBranchExists(tmpDir, "release/1.0")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = RemoteBranchExists(tmpDir, "--upload-pack=x")
+	assert.Error(t, err)
+
+	t.Run("no remote", func(t *testing.T) {
+		_, err := RemoteBranchExists(createTestGitRepo(t), "develop")
+		assert.Error(t, err)
+	})
 }
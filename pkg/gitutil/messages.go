@@ -3,9 +3,29 @@ package gitutil
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
+const (
+	// maxExplanationLength truncates the explanation of a single-fix commit
+	maxExplanationLength = 500
+	// maxFileExplanationLength truncates each file's explanation in commits
+	// with several fixes
+	maxFileExplanationLength = 120
+)
+
+// summarizeExplanation collapses a fix explanation to a single line of at
+// most maxLen characters, for commit bodies
+func summarizeExplanation(explanation string, maxLen int) string {
+	summary := strings.Join(strings.Fields(explanation), " ")
+	runes := []rune(summary)
+	if len(runes) > maxLen {
+		summary = strings.TrimSpace(string(runes[:maxLen-3])) + "..."
+	}
+	return summary
+}
+
 // FormatPerViolationMessage formats a detailed commit message for a violation
 func FormatPerViolationMessage(violationID, description, category string, effort int,
 	fixes []FixRecord, providerName string) string {
@@ -31,6 +51,9 @@ func FormatPerViolationMessage(violationID, description, category string, effort
 	totalTokens := 0
 	for _, fix := range fixes {
 		sb.WriteString(fmt.Sprintf("- %s:%d\n", fix.Result.FilePath, fix.Incident.LineNumber))
+		if explanation := summarizeExplanation(fix.Result.Explanation, maxFileExplanationLength); explanation != "" {
+			sb.WriteString(fmt.Sprintf("  %s\n", explanation))
+		}
 		totalCost += fix.Result.Cost
 		totalTokens += fix.Result.TokensUsed
 	}
@@ -44,9 +67,10 @@ func FormatPerViolationMessage(violationID, description, category string, effort
 	return sb.String()
 }
 
-// FormatPerIncidentMessage formats a detailed commit message for a single incident
+// FormatPerIncidentMessage formats a detailed commit message for a single
+// incident. The fix's explanation, if any, is included truncated.
 func FormatPerIncidentMessage(violationID, description, filePath string, lineNumber int,
-	cost float64, tokens int, providerName string) string {
+	explanation string, cost float64, tokens int, providerName string) string {
 
 	var sb strings.Builder
 
@@ -60,6 +84,10 @@ func FormatPerIncidentMessage(violationID, description, filePath string, lineNum
 	sb.WriteString(fmt.Sprintf("File: %s\n", filePath))
 	sb.WriteString(fmt.Sprintf("Line: %d\n\n", lineNumber))
 
+	if explanation = summarizeExplanation(explanation, maxExplanationLength); explanation != "" {
+		sb.WriteString(fmt.Sprintf("Explanation: %s\n\n", explanation))
+	}
+
 	// Stats
 	sb.WriteString(fmt.Sprintf("Provider: %s\n", providerName))
 	sb.WriteString(fmt.Sprintf("Cost: $%.4f\n", cost))
@@ -104,6 +132,8 @@ func FormatAtEndMessage(fixesByViolation map[string][]FixRecord, providerName st
 		}
 	}
 
+	writeFileExplanations(&sb, fixesByViolation)
+
 	// Summary stats
 	sb.WriteString(fmt.Sprintf("\nTotal Files Modified: %d\n", len(filesModified)))
 	sb.WriteString(fmt.Sprintf("Provider: %s\n", providerName))
@@ -112,3 +142,28 @@ func FormatAtEndMessage(fixesByViolation map[string][]FixRecord, providerName st
 
 	return sb.String()
 }
+
+// writeFileExplanations lists the explanation of each fix of a batched
+// commit, sorted by violation, if any fix has one
+func writeFileExplanations(sb *strings.Builder, fixesByViolation map[string][]FixRecord) {
+	violationIDs := make([]string, 0, len(fixesByViolation))
+	for violationID := range fixesByViolation {
+		violationIDs = append(violationIDs, violationID)
+	}
+	sort.Strings(violationIDs)
+
+	header := false
+	for _, violationID := range violationIDs {
+		for _, fix := range fixesByViolation[violationID] {
+			explanation := summarizeExplanation(fix.Result.Explanation, maxFileExplanationLength)
+			if explanation == "" {
+				continue
+			}
+			if !header {
+				sb.WriteString("\nChanges:\n")
+				header = true
+			}
+			sb.WriteString(fmt.Sprintf("- %s:%d (%s): %s\n", fix.Result.FilePath, fix.Incident.LineNumber, violationID, explanation))
+		}
+	}
+}
@@ -77,6 +77,7 @@ func TestFormatPerIncidentMessage(t *testing.T) {
 		"Update deprecated API usage",
 		"src/Service.java",
 		42,
+		"",
 		0.015,
 		125,
 		"openai",
@@ -211,3 +212,55 @@ func TestFormatPerViolationMessage_ZeroCostAndTokens(t *testing.T) {
 	assert.Contains(t, message, "Total Cost: $0.0000")
 	assert.Contains(t, message, "Total Tokens: 0")
 }
+
+func TestCommitMessages_Explanations(t *testing.T) {
+	v1 := violation.Violation{ID: "javax-to-jakarta", Description: "Replace javax", Category: "mandatory"}
+	v2 := violation.Violation{ID: "log4j", Description: "Replace log4j", Category: "optional"}
+	fix := func(v violation.Violation, file string, line int, explanation string) FixRecord {
+		return FixRecord{
+			Violation: v,
+			Incident:  violation.Incident{LineNumber: line},
+			Result:    fixer.FixResult{FilePath: file, Explanation: explanation},
+		}
+	}
+
+	t.Run("per-incident", func(t *testing.T) {
+		message := FormatPerIncidentMessage(v1.ID, v1.Description, "src/App.java", 3,
+			"Replaced the javax.servlet import\nwith jakarta.servlet.", 0.01, 100, "claude")
+		assert.Contains(t, message, "Explanation: Replaced the javax.servlet import with jakarta.servlet.\n")
+
+		message = FormatPerIncidentMessage(v1.ID, v1.Description, "src/App.java", 3, "", 0.01, 100, "claude")
+		assert.NotContains(t, message, "Explanation:")
+	})
+
+	t.Run("long explanations are truncated", func(t *testing.T) {
+		message := FormatPerIncidentMessage(v1.ID, v1.Description, "src/App.java", 3,
+			strings.Repeat("word ", 200), 0.01, 100, "claude")
+		line := message[strings.Index(message, "Explanation: "):]
+		line = line[:strings.Index(line, "\n")]
+		assert.LessOrEqual(t, len(line), len("Explanation: ")+maxExplanationLength)
+		assert.True(t, strings.HasSuffix(line, "..."))
+	})
+
+	t.Run("per-violation lists each file's explanation", func(t *testing.T) {
+		message := FormatPerViolationMessage(v1.ID, v1.Description, v1.Category, 1, []FixRecord{
+			fix(v1, "src/A.java", 3, "Switched A to jakarta"),
+			fix(v1, "src/B.java", 5, ""),
+			fix(v1, "src/C.java", 7, strings.Repeat("x", 200)),
+		}, "claude")
+		assert.Contains(t, message, "- src/A.java:3\n  Switched A to jakarta\n- src/B.java:5\n- src/C.java:7\n  "+strings.Repeat("x", maxFileExplanationLength-3)+"...\n")
+	})
+
+	t.Run("at-end lists each file's explanation", func(t *testing.T) {
+		message := FormatAtEndMessage(map[string][]FixRecord{
+			v2.ID: {fix(v2, "pom.xml", 12, "Replaced log4j with reload4j")},
+			v1.ID: {fix(v1, "src/A.java", 3, "Switched A to jakarta"), fix(v1, "src/B.java", 5, "")},
+		}, "claude")
+		assert.Contains(t, message, "\nChanges:\n"+
+			"- src/A.java:3 (javax-to-jakarta): Switched A to jakarta\n"+
+			"- pom.xml:12 (log4j): Replaced log4j with reload4j\n")
+
+		message = FormatAtEndMessage(map[string][]FixRecord{v1.ID: {fix(v1, "src/B.java", 5, "")}}, "claude")
+		assert.NotContains(t, message, "Changes:")
+	})
+}
@@ -0,0 +1,181 @@
+package gitutil
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+)
+
+// DiffFormat controls how code changes are rendered in PR bodies
+type DiffFormat string
+
+const (
+	// DiffFormatUnified renders a fenced unified diff per file
+	DiffFormatUnified DiffFormat = "unified"
+	// DiffFormatSideBySide renders an HTML before/after table per file
+	DiffFormatSideBySide DiffFormat = "side-by-side"
+	// DiffFormatNone leaves diffs out of PR bodies
+	DiffFormatNone DiffFormat = "none"
+)
+
+const (
+	// maxPRDiffLines caps the lines shown for a single file's diff
+	maxPRDiffLines = 200
+	// maxPRDiffChars caps the whole diff section; GitHub rejects bodies over 65536 characters
+	maxPRDiffChars = 40000
+)
+
+// ParseDiffFormat parses a diff format string. An empty string selects unified diffs.
+func ParseDiffFormat(s string) (DiffFormat, error) {
+	switch DiffFormat(s) {
+	case "", DiffFormatUnified:
+		return DiffFormatUnified, nil
+	case DiffFormatSideBySide, DiffFormatNone:
+		return DiffFormat(s), nil
+	default:
+		return DiffFormatNone, fmt.Errorf("invalid PR diff format: %s (expected unified, side-by-side, or none)", s)
+	}
+}
+
+// fileChange is the combined change made to one file by one or more fixes
+type fileChange struct {
+	path     string
+	original string
+	fixed    string
+}
+
+// collectFileChanges combines fixes into one change per file, from the
+// content before the first fix to the content after the last. Fixes without
+// recorded content are skipped.
+func collectFileChanges(fixes []FixRecord) []fileChange {
+	var changes []fileChange
+	index := make(map[string]int)
+	for _, fix := range fixes {
+		if fix.Result.OriginalContent == "" && fix.Result.FixedContent == "" {
+			continue
+		}
+		if i, ok := index[fix.Result.FilePath]; ok {
+			changes[i].fixed = fix.Result.FixedContent
+			continue
+		}
+		index[fix.Result.FilePath] = len(changes)
+		changes = append(changes, fileChange{
+			path:     fix.Result.FilePath,
+			original: fix.Result.OriginalContent,
+			fixed:    fix.Result.FixedContent,
+		})
+	}
+	return changes
+}
+
+// formatDiffSection renders the "Code Changes" section of a PR body, or ""
+// if the format is none or no fix recorded file content
+func formatDiffSection(fixes []FixRecord, format DiffFormat) string {
+	if format == DiffFormatNone {
+		return ""
+	}
+
+	var files []string
+	omitted := 0
+	size := 0
+	for _, change := range collectFileChanges(fixes) {
+		diff := fixer.UnifiedDiff(change.path, change.original, change.fixed, fixer.DefaultDiffContext)
+		if diff == "" {
+			continue
+		}
+		diff = fixer.TruncateDiff(diff, maxPRDiffLines)
+
+		var rendered string
+		if format == DiffFormatSideBySide {
+			rendered = renderSideBySide(diff)
+		} else {
+			rendered = "```diff\n" + diff + "```\n"
+		}
+
+		block := fmt.Sprintf("<details>\n<summary><code>%s</code></summary>\n\n%s\n</details>\n\n", html.EscapeString(change.path), rendered)
+		if size+len(block) > maxPRDiffChars {
+			omitted++
+			continue
+		}
+		size += len(block)
+		files = append(files, block)
+	}
+
+	if len(files) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Code Changes\n\n")
+	for _, block := range files {
+		sb.WriteString(block)
+	}
+	if omitted > 0 {
+		sb.WriteString(fmt.Sprintf("*%d more file(s) not shown to keep this description within GitHub's size limit.*\n\n", omitted))
+	}
+	return sb.String()
+}
+
+// renderSideBySide converts a unified diff into an HTML table with the
+// original lines on the left and the fixed lines on the right. Runs of
+// removed and added lines are paired row by row.
+func renderSideBySide(diff string) string {
+	var sb strings.Builder
+	sb.WriteString("<table>\n<tr><th>Before</th><th>After</th></tr>\n")
+
+	var removed, added []string
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			left, right := "", ""
+			if i < len(removed) {
+				left = sideBySideCell(removed[i], "-")
+			}
+			if i < len(added) {
+				right = sideBySideCell(added[i], "+")
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td></tr>\n", left, right))
+		}
+		removed, added = nil, nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			// File headers are shown in the summary instead
+		case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "..."):
+			flush()
+			sb.WriteString(fmt.Sprintf("<tr><td colspan=\"2\"><code>%s</code></td></tr>\n", html.EscapeString(line)))
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, line[1:])
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		default:
+			flush()
+			text := strings.TrimPrefix(line, " ")
+			cell := sideBySideCell(text, "")
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td></tr>\n", cell, cell))
+		}
+	}
+	flush()
+
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
+
+// sideBySideCell renders one line for a table cell, keeping indentation
+// visible and marking removed or added lines
+func sideBySideCell(line, marker string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+	text := strings.Repeat("&nbsp;", indent) + html.EscapeString(trimmed)
+	switch marker {
+	case "-":
+		return "<code>- " + text + "</code>"
+	case "+":
+		return "<code>+ " + text + "</code>"
+	default:
+		return "<code>" + text + "</code>"
+	}
+}
@@ -0,0 +1,118 @@
+package gitutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func sampleDiffFix() FixRecord {
+	return FixRecord{
+		Violation: violation.Violation{ID: "javax-to-jakarta", Category: "mandatory", Effort: 3},
+		Incident:  violation.Incident{URI: "file:///src/Servlet.java", LineNumber: 1},
+		Result: fixer.FixResult{
+			FilePath:        "src/Servlet.java",
+			Success:         true,
+			Confidence:      0.9,
+			OriginalContent: "import javax.servlet.Servlet;\n\npublic class Servlet {}\n",
+			FixedContent:    "import jakarta.servlet.Servlet;\n\npublic class Servlet {}\n",
+		},
+	}
+}
+
+func TestParseDiffFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    DiffFormat
+		wantErr bool
+	}{
+		{"", DiffFormatUnified, false},
+		{"unified", DiffFormatUnified, false},
+		{"side-by-side", DiffFormatSideBySide, false},
+		{"none", DiffFormatNone, false},
+		{"split", DiffFormatNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDiffFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatPRBody_DiffFormats(t *testing.T) {
+	fix := sampleDiffFix()
+	fixes := []FixRecord{fix}
+
+	t.Run("unified", func(t *testing.T) {
+		body := FormatPRBodyForViolation("javax-to-jakarta", "Use jakarta", "mandatory", 3, fixes, "claude", DiffFormatUnified)
+		assert.Contains(t, body, "### Code Changes")
+		assert.Contains(t, body, "<code>src/Servlet.java</code>")
+		assert.Contains(t, body, "```diff\n")
+		assert.Contains(t, body, "-import javax.servlet.Servlet;\n+import jakarta.servlet.Servlet;\n")
+		assert.NotContains(t, body, "<table>")
+	})
+
+	t.Run("side-by-side", func(t *testing.T) {
+		body := FormatPRBodyForViolation("javax-to-jakarta", "Use jakarta", "mandatory", 3, fixes, "claude", DiffFormatSideBySide)
+		assert.Contains(t, body, "### Code Changes")
+		assert.Contains(t, body, "<tr><th>Before</th><th>After</th></tr>")
+		assert.Contains(t, body, "<tr><td><code>- import javax.servlet.Servlet;</code></td><td><code>+ import jakarta.servlet.Servlet;</code></td></tr>")
+		assert.Contains(t, body, "<tr><td><code>public class Servlet {}</code></td><td><code>public class Servlet {}</code></td></tr>")
+		assert.NotContains(t, body, "```diff")
+	})
+
+	t.Run("none", func(t *testing.T) {
+		body := FormatPRBodyForViolation("javax-to-jakarta", "Use jakarta", "mandatory", 3, fixes, "claude", DiffFormatNone)
+		assert.NotContains(t, body, "### Code Changes")
+	})
+
+	t.Run("other body helpers", func(t *testing.T) {
+		byViolation := map[string][]FixRecord{"javax-to-jakarta": fixes}
+		for name, body := range map[string]string{
+			"incident": FormatPRBodyForIncident("javax-to-jakarta", "Use jakarta", fix.Result.FilePath, 1, 0, 0, "claude", fix, DiffFormatSideBySide),
+			"phase":    FormatPRBodyForPhase("phase-1", byViolation, "claude", DiffFormatSideBySide),
+			"at-end":   FormatPRBodyAtEnd(byViolation, "claude", DiffFormatSideBySide),
+		} {
+			assert.Contains(t, body, "<tr><th>Before</th><th>After</th></tr>", name)
+		}
+	})
+}
+
+func TestFormatDiffSection(t *testing.T) {
+	t.Run("fixes without content render nothing", func(t *testing.T) {
+		assert.Empty(t, formatDiffSection([]FixRecord{{Result: fixer.FixResult{FilePath: "a.java"}}}, DiffFormatUnified))
+	})
+
+	t.Run("sequential fixes to one file are combined", func(t *testing.T) {
+		first := sampleDiffFix()
+		second := sampleDiffFix()
+		second.Result.OriginalContent = first.Result.FixedContent
+		second.Result.FixedContent = "import jakarta.servlet.Servlet;\n\npublic class JakartaServlet {}\n"
+
+		section := formatDiffSection([]FixRecord{first, second}, DiffFormatUnified)
+		assert.Equal(t, 1, strings.Count(section, "<details>"))
+		assert.Contains(t, section, "-import javax.servlet.Servlet;")
+		assert.Contains(t, section, "+public class JakartaServlet {}")
+	})
+
+	t.Run("html is escaped in side-by-side cells", func(t *testing.T) {
+		fix := sampleDiffFix()
+		fix.Result.OriginalContent = "List<String> a;\n"
+		fix.Result.FixedContent = "List<Integer> a;\n"
+
+		section := formatDiffSection([]FixRecord{fix}, DiffFormatSideBySide)
+		assert.Contains(t, section, "List&lt;String&gt; a;")
+		assert.NotContains(t, section, "List<Integer>")
+	})
+}
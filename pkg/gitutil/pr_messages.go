@@ -3,6 +3,7 @@ package gitutil
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -13,9 +14,10 @@ func FormatPRTitleForViolation(violationID, description string) string {
 	return fmt.Sprintf("fix: Konveyor violation %s", violationID)
 }
 
-// FormatPRBodyForViolation creates a PR body for a violation
+// FormatPRBodyForViolation creates a PR body for a violation.
+// Code changes are rendered in diffFormat.
 func FormatPRBodyForViolation(violationID, description, category string, effort int,
-	fixes []FixRecord, providerName string) string {
+	fixes []FixRecord, providerName string, diffFormat DiffFormat) string {
 
 	var sb strings.Builder
 
@@ -143,6 +145,8 @@ func FormatPRBodyForViolation(violationID, description, category string, effort
 
 	sb.WriteString("\n</details>\n\n")
 
+	sb.WriteString(formatDiffSection(fixes, diffFormat))
+
 	// Review checklist
 	sb.WriteString("### Review Checklist\n\n")
 	sb.WriteString("- [ ] Verify fixes are semantically correct\n")
@@ -183,9 +187,10 @@ func FormatPRTitleForIncident(violationID, description, filename string) string
 	return fmt.Sprintf("fix: %s in %s", violationID, filename)
 }
 
-// FormatPRBodyForIncident creates a PR body for a single incident
+// FormatPRBodyForIncident creates a PR body for a single incident.
+// The change recorded in fix is rendered in diffFormat.
 func FormatPRBodyForIncident(violationID, description, filePath string, lineNumber int,
-	cost float64, tokens int, providerName string) string {
+	cost float64, tokens int, providerName string, fix FixRecord, diffFormat DiffFormat) string {
 
 	var sb strings.Builder
 
@@ -199,6 +204,8 @@ func FormatPRBodyForIncident(violationID, description, filePath string, lineNumb
 	sb.WriteString(fmt.Sprintf("**File:** `%s`\n", filePath))
 	sb.WriteString(fmt.Sprintf("**Line:** %d\n\n", lineNumber))
 
+	sb.WriteString(formatDiffSection([]FixRecord{fix}, diffFormat))
+
 	// AI details section
 	sb.WriteString("## AI Remediation Details\n\n")
 	sb.WriteString(fmt.Sprintf("- **Provider:** %s\n", providerName))
@@ -228,8 +235,9 @@ func FormatPRTitleAtEnd(violationCount int) string {
 	return fmt.Sprintf("fix: Konveyor batch remediation (%d violations)", violationCount)
 }
 
-// FormatPRBodyForPhase creates a PR body for a phase
-func FormatPRBodyForPhase(phaseID string, fixesByViolation map[string][]FixRecord, providerName string) string {
+// FormatPRBodyForPhase creates a PR body for a phase.
+// Code changes are rendered in diffFormat.
+func FormatPRBodyForPhase(phaseID string, fixesByViolation map[string][]FixRecord, providerName string, diffFormat DiffFormat) string {
 	var sb strings.Builder
 
 	// Calculate statistics
@@ -349,6 +357,8 @@ func FormatPRBodyForPhase(phaseID string, fixesByViolation map[string][]FixRecor
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(formatDiffSection(sortedFixes(fixesByViolation), diffFormat))
+
 	// Review checklist
 	sb.WriteString("### Review Checklist\n\n")
 	sb.WriteString("- [ ] Verify fixes are semantically correct across all files\n")
@@ -378,8 +388,9 @@ func FormatPRBodyForPhase(phaseID string, fixesByViolation map[string][]FixRecor
 	return sb.String()
 }
 
-// FormatPRBodyAtEnd creates a PR body for batch remediation
-func FormatPRBodyAtEnd(fixesByViolation map[string][]FixRecord, providerName string) string {
+// FormatPRBodyAtEnd creates a PR body for batch remediation.
+// Code changes are rendered in diffFormat.
+func FormatPRBodyAtEnd(fixesByViolation map[string][]FixRecord, providerName string, diffFormat DiffFormat) string {
 	var sb strings.Builder
 
 	// Calculate statistics
@@ -499,6 +510,8 @@ func FormatPRBodyAtEnd(fixesByViolation map[string][]FixRecord, providerName str
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(formatDiffSection(sortedFixes(fixesByViolation), diffFormat))
+
 	// Review checklist
 	sb.WriteString("### Review Checklist\n\n")
 	sb.WriteString("- [ ] Verify fixes are semantically correct across all files\n")
@@ -526,3 +539,19 @@ func FormatPRBodyAtEnd(fixesByViolation map[string][]FixRecord, providerName str
 
 	return sb.String()
 }
+
+// sortedFixes flattens fixes grouped by violation in violation ID order, so
+// rendered diffs are stable across runs
+func sortedFixes(fixesByViolation map[string][]FixRecord) []FixRecord {
+	ids := make([]string, 0, len(fixesByViolation))
+	for id := range fixesByViolation {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var fixes []FixRecord
+	for _, id := range ids {
+		fixes = append(fixes, fixesByViolation[id]...)
+	}
+	return fixes
+}
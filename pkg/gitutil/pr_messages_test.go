@@ -61,7 +61,7 @@ func TestFormatPRBodyForViolation(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyForViolation("test-001", "Test violation", "mandatory", 1, fixes, "claude")
+		body := FormatPRBodyForViolation("test-001", "Test violation", "mandatory", 1, fixes, "claude", DiffFormatUnified)
 
 		// Verify key sections are present
 		assert.Contains(t, body, "### Summary")
@@ -116,7 +116,7 @@ func TestFormatPRBodyForViolation(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyForViolation("test-002", "Multiple fixes", "optional", 2, fixes, "openai")
+		body := FormatPRBodyForViolation("test-002", "Multiple fixes", "optional", 2, fixes, "openai", DiffFormatUnified)
 
 		// Verify aggregation
 		assert.Contains(t, body, "**Incidents Fixed:** 2")
@@ -140,7 +140,7 @@ func TestFormatPRBodyForViolation(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyForViolation("v1", "desc", "mandatory", 1, fixes, "claude")
+		body := FormatPRBodyForViolation("v1", "desc", "mandatory", 1, fixes, "claude", DiffFormatUnified)
 
 		assert.Contains(t, body, "**Incidents Fixed:** 2")
 		assert.Contains(t, body, "**Files Modified:** 2")
@@ -190,6 +190,8 @@ func TestFormatPRBodyForIncident(t *testing.T) {
 		0.123,
 		456,
 		"claude",
+		FixRecord{},
+		DiffFormatUnified,
 	)
 
 	// Verify all key elements are present
@@ -257,7 +259,7 @@ func TestFormatPRBodyAtEnd(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyAtEnd(fixesByViolation, "claude")
+		body := FormatPRBodyAtEnd(fixesByViolation, "claude", DiffFormatUnified)
 
 		assert.Contains(t, body, "## Summary")
 		assert.Contains(t, body, "1** Konveyor violation")
@@ -318,7 +320,7 @@ func TestFormatPRBodyAtEnd(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyAtEnd(fixesByViolation, "openai")
+		body := FormatPRBodyAtEnd(fixesByViolation, "openai", DiffFormatUnified)
 
 		// Verify summary
 		assert.Contains(t, body, "2** Konveyor violation")
@@ -355,7 +357,7 @@ func TestFormatPRBodyAtEnd(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyAtEnd(fixesByViolation, "claude")
+		body := FormatPRBodyAtEnd(fixesByViolation, "claude", DiffFormatUnified)
 
 		// Verify truncation
 		assert.Contains(t, body, "...")
@@ -381,7 +383,7 @@ func TestFormatPRBodyAtEnd(t *testing.T) {
 		}
 
 		fixesByViolation := map[string][]FixRecord{"v1": fixes}
-		body := FormatPRBodyAtEnd(fixesByViolation, "claude")
+		body := FormatPRBodyAtEnd(fixesByViolation, "claude", DiffFormatUnified)
 
 		// Should show count instead of listing all files
 		assert.Contains(t, body, "10 files modified")
@@ -440,7 +442,7 @@ func TestFormatPRBodyForPhase(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyForPhase("phase-1", fixesByViolation, "claude")
+		body := FormatPRBodyForPhase("phase-1", fixesByViolation, "claude", DiffFormatUnified)
 
 		assert.Contains(t, body, "Phase phase-1")
 		assert.Contains(t, body, "1** violation(s)")
@@ -508,7 +510,7 @@ func TestFormatPRBodyForPhase(t *testing.T) {
 			},
 		}
 
-		body := FormatPRBodyForPhase("phase-1", fixesByViolation, "openai")
+		body := FormatPRBodyForPhase("phase-1", fixesByViolation, "openai", DiffFormatUnified)
 
 		// Verify summary
 		assert.Contains(t, body, "Phase phase-1")
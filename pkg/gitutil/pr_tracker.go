@@ -49,6 +49,10 @@ type PRConfig struct {
 	GitHubToken      string
 	DryRun           bool    // If true, show what would be done without actually doing it
 	CommentThreshold float64 // Add inline comments for fixes with confidence below this (0.0-1.0, 0 = disabled)
+	OperationDelay   time.Duration // Minimum delay between branch pushes, PR creations and PR comments (0 = no delay)
+	MaxBranchLength  int           // Maximum length of generated branch names (0 = DefaultMaxBranchLength)
+	DiffFormat       DiffFormat    // How code changes appear in PR bodies ("" = unified)
+	UserAgent        string        // User-Agent sent to the GitHub API (empty = kantra-ai/<version>)
 }
 
 // PendingPR represents a PR that needs to be created
@@ -95,6 +99,10 @@ type PRTracker struct {
 
 	// Track created PRs
 	createdPRs []CreatedPR
+
+	// Throttling of GitHub operations (see PRConfig.OperationDelay)
+	lastOperation time.Time
+	sleep         func(time.Duration) // nil = time.Sleep
 }
 
 // NewPRTracker creates a new PR tracker for managing GitHub pull request creation.
@@ -138,12 +146,24 @@ func NewPRTracker(config PRConfig, workingDir string, providerName string, progr
 		if err != nil {
 			return nil, fmt.Errorf("failed to create GitHub client: %w", err)
 		}
+		githubClient.SetUserAgent(config.UserAgent)
 
 		// Get current branch to restore later
 		currentBranch, err = GetCurrentBranch(workingDir)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get current branch: %w", err)
 		}
+
+		// Fail before any work is done if PRs can't target the base branch
+		if config.BaseBranch != "" {
+			exists, err := RemoteBranchExists(workingDir, config.BaseBranch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check base branch '%s': %w", config.BaseBranch, err)
+			}
+			if !exists {
+				return nil, fmt.Errorf("base branch '%s' does not exist on remote 'origin' (push it first, or omit the base branch to target the repository's default branch)", config.BaseBranch)
+			}
+		}
 	}
 
 	// Use NoOp progress writer if none provided
@@ -300,7 +320,7 @@ func (pt *PRTracker) createPRsPerViolation(baseBranch string) error {
 		pt.progress.Printf("\n[%d/%d] Creating PR for violation: %s\n", currentPR, prCount, violationID)
 
 		// Generate branch name
-		branchName := fmt.Sprintf("%s-%s-%d", pt.config.BranchPrefix, violationID, timestamp)
+		branchName := pt.branchName(violationID, fmt.Sprintf("%d", timestamp))
 
 		// Create and push branch
 		if err := pt.createAndPushBranch(branchName); err != nil {
@@ -317,6 +337,7 @@ func (pt *PRTracker) createPRsPerViolation(baseBranch string) error {
 			violation.Effort,
 			fixes,
 			pt.providerName,
+			pt.config.DiffFormat,
 		)
 
 		pr, err := pt.createPR(title, body, branchName, baseBranch)
@@ -372,11 +393,7 @@ func (pt *PRTracker) createPRsPerIncident(baseBranch string) error {
 
 	for i, fix := range pt.allFixes {
 		// Generate branch name
-		branchName := fmt.Sprintf("%s-%s-%d-%d",
-			pt.config.BranchPrefix,
-			fix.Violation.ID,
-			timestamp,
-			i)
+		branchName := pt.branchName(fix.Violation.ID, fmt.Sprintf("%d-%d", timestamp, i))
 
 		// Create and push branch
 		if err := pt.createAndPushBranch(branchName); err != nil {
@@ -397,6 +414,8 @@ func (pt *PRTracker) createPRsPerIncident(baseBranch string) error {
 			fix.Result.Cost,
 			fix.Result.TokensUsed,
 			pt.providerName,
+			fix,
+			pt.config.DiffFormat,
 		)
 
 		pr, err := pt.createPR(title, body, branchName, baseBranch)
@@ -462,7 +481,7 @@ func (pt *PRTracker) createPRsPerPhase(baseBranch string) error {
 		pt.progress.Printf("\n[%d/%d] Creating PR for phase: %s\n", currentPR, prCount, phaseID)
 
 		// Generate branch name
-		branchName := fmt.Sprintf("%s-%s-%d", pt.config.BranchPrefix, phaseID, timestamp)
+		branchName := pt.branchName(phaseID, fmt.Sprintf("%d", timestamp))
 
 		// Create and push branch
 		if err := pt.createAndPushBranch(branchName); err != nil {
@@ -478,7 +497,7 @@ func (pt *PRTracker) createPRsPerPhase(baseBranch string) error {
 
 		// Create PR
 		title := FormatPRTitleForPhase(phaseID, len(fixesByViolation))
-		body := FormatPRBodyForPhase(phaseID, fixesByViolation, pt.providerName)
+		body := FormatPRBodyForPhase(phaseID, fixesByViolation, pt.providerName, pt.config.DiffFormat)
 
 		pr, err := pt.createPR(title, body, branchName, baseBranch)
 		if err != nil {
@@ -534,7 +553,7 @@ func (pt *PRTracker) createPRAtEnd(baseBranch string) error {
 	}
 
 	timestamp := time.Now().Unix()
-	branchName := fmt.Sprintf("%s-%d", pt.config.BranchPrefix, timestamp)
+	branchName := pt.branchName("", fmt.Sprintf("%d", timestamp))
 
 	// Create and push branch
 	if err := pt.createAndPushBranch(branchName); err != nil {
@@ -543,7 +562,7 @@ func (pt *PRTracker) createPRAtEnd(baseBranch string) error {
 
 	// Create PR
 	title := FormatPRTitleAtEnd(len(pt.fixesByViolation))
-	body := FormatPRBodyAtEnd(pt.fixesByViolation, pt.providerName)
+	body := FormatPRBodyAtEnd(pt.fixesByViolation, pt.providerName, pt.config.DiffFormat)
 
 	pr, err := pt.createPR(title, body, branchName, baseBranch)
 	if err != nil {
@@ -618,6 +637,7 @@ func (pt *PRTracker) createAndPushBranch(branchName string) error {
 	}
 
 	// Push branch
+	pt.throttle()
 	pt.progress.Printf("  Pushing to remote...\n")
 	if err := PushBranch(pt.workingDir, branchName); err != nil {
 		// Provide helpful error messages for common push failures
@@ -642,6 +662,31 @@ func (pt *PRTracker) createAndPushBranch(branchName string) error {
 	return nil
 }
 
+// branchName builds a sanitized, length-limited branch name from the
+// configured prefix, an identifier (violation or phase ID) and a suffix.
+func (pt *PRTracker) branchName(id, suffix string) string {
+	return BuildBranchName(pt.config.BranchPrefix, id, suffix, pt.config.MaxBranchLength)
+}
+
+// throttle waits until at least OperationDelay has passed since the previous
+// GitHub operation. Spacing out pushes and PR creation avoids tripping GitHub's
+// secondary rate limits when many PRs are created in one run.
+func (pt *PRTracker) throttle() {
+	if pt.config.DryRun || pt.config.OperationDelay <= 0 {
+		return
+	}
+	if !pt.lastOperation.IsZero() {
+		if wait := pt.config.OperationDelay - time.Since(pt.lastOperation); wait > 0 {
+			if pt.sleep != nil {
+				pt.sleep(wait)
+			} else {
+				time.Sleep(wait)
+			}
+		}
+	}
+	pt.lastOperation = time.Now()
+}
+
 // createPR creates a pull request on GitHub via the GitHub API.
 // Reports progress and provides helpful error messages for common API errors.
 //
@@ -685,6 +730,7 @@ func (pt *PRTracker) createPR(title, body, head, base string) (*PullRequestRespo
 		Base:  base,
 	}
 
+	pt.throttle()
 	pr, err := pt.githubClient.CreatePullRequest(req)
 	if err != nil {
 		// Provide better error messages for common GitHub API errors
@@ -764,6 +810,7 @@ func (pt *PRTracker) addLowConfidenceComments(prNumber int, fixes []FixRecord) e
 			Side:     "RIGHT", // Comment on the new version (after changes)
 		}
 
+		pt.throttle()
 		_, err := pt.githubClient.CreateReviewComment(prNumber, req)
 		if err != nil {
 			// Log warning but don't fail the PR creation
@@ -785,3 +832,74 @@ func (pt *PRTracker) addLowConfidenceComments(prNumber int, fixes []FixRecord) e
 func (pt *PRTracker) GetCreatedPRs() []CreatedPR {
 	return pt.createdPRs
 }
+
+// PRCountPreview holds the number of pull requests each PR strategy would
+// create for the same set of fixes. It lets users compare strategies before
+// committing to one.
+type PRCountPreview struct {
+	PerViolation int
+	PerIncident  int
+	PerPhase     int
+	AtEnd        int
+}
+
+// Count returns the preview count for the given strategy (0 for PRStrategyNone)
+func (p PRCountPreview) Count(strategy PRStrategy) int {
+	switch strategy {
+	case PRStrategyPerViolation:
+		return p.PerViolation
+	case PRStrategyPerIncident:
+		return p.PerIncident
+	case PRStrategyPerPhase:
+		return p.PerPhase
+	case PRStrategyAtEnd:
+		return p.AtEnd
+	default:
+		return 0
+	}
+}
+
+// EstimatePRCounts computes how many PRs each strategy would create for the
+// given fixes, mirroring the grouping used by Finalize:
+//   - per-violation: one PR per distinct violation ID
+//   - per-incident: one PR per fix
+//   - per-phase: one PR per distinct non-empty phase ID
+//   - at-end: a single PR if there is at least one fix
+func EstimatePRCounts(fixes []FixRecord) PRCountPreview {
+	violations := make(map[string]bool)
+	phases := make(map[string]bool)
+
+	for _, fix := range fixes {
+		violations[fix.Violation.ID] = true
+		if fix.PhaseID != "" {
+			phases[fix.PhaseID] = true
+		}
+	}
+
+	preview := PRCountPreview{
+		PerViolation: len(violations),
+		PerIncident:  len(fixes),
+		PerPhase:     len(phases),
+	}
+	if len(fixes) > 0 {
+		preview.AtEnd = 1
+	}
+
+	return preview
+}
+
+// SuccessfulFixes returns the records of fixes that were applied
+func SuccessfulFixes(fixes []FixRecord) []FixRecord {
+	successful := make([]FixRecord, 0, len(fixes))
+	for _, fix := range fixes {
+		if fix.Result.Success {
+			successful = append(successful, fix)
+		}
+	}
+	return successful
+}
+
+// EstimatePRCounts returns the PR count preview for all fixes tracked so far
+func (pt *PRTracker) EstimatePRCounts() PRCountPreview {
+	return EstimatePRCounts(pt.allFixes)
+}
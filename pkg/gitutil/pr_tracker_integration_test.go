@@ -260,7 +260,7 @@ func TestPRTracker_CreatePRAtEnd_DryRun(t *testing.T) {
 
 	// We can test the PR message formatting without actually creating the PR
 	title := FormatPRTitleAtEnd(len(tracker.fixesByViolation))
-	body := FormatPRBodyAtEnd(tracker.fixesByViolation, tracker.providerName)
+	body := FormatPRBodyAtEnd(tracker.fixesByViolation, tracker.providerName, DiffFormatUnified)
 
 	assert.Contains(t, title, "Konveyor")
 	assert.Contains(t, body, "v1")
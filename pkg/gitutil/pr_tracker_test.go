@@ -1,9 +1,13 @@
 package gitutil
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -418,6 +422,38 @@ func TestPRTracker_DryRunFinalize(t *testing.T) {
 		// Verify all three "mock" PRs were tracked
 		assert.Len(t, tracker.createdPRs, 3)
 	})
+
+	t.Run("dry-run per-violation sanitizes and limits branch names", func(t *testing.T) {
+		tmpDir := createTestGitRepo(t)
+		configGitUser(t, tmpDir)
+
+		tracker := &PRTracker{
+			config: PRConfig{
+				Strategy:        PRStrategyPerViolation,
+				BranchPrefix:    "kantra-ai/remediation",
+				DryRun:          true,
+				MaxBranchLength: 60,
+			},
+			workingDir:       tmpDir,
+			providerName:     "claude",
+			fixesByViolation: make(map[string][]FixRecord),
+			allFixes:         make([]FixRecord, 0),
+			createdPRs:       make([]CreatedPR, 0),
+			progress:         &NoOpProgressWriter{},
+		}
+
+		v := violation.Violation{ID: "eap8/javax:servlet " + strings.Repeat("x", 80), Description: "Test"}
+		err := tracker.TrackForPR(v, violation.Incident{LineNumber: 10}, &fixer.FixResult{FilePath: "test.java"})
+		require.NoError(t, err)
+
+		require.NoError(t, tracker.Finalize())
+		require.Len(t, tracker.createdPRs, 1)
+
+		branch := tracker.createdPRs[0].BranchName
+		assert.LessOrEqual(t, len(branch), 60)
+		assert.True(t, strings.HasPrefix(branch, "kantra-ai/remediation-eap8-javax-servlet"), branch)
+		assert.NoError(t, validateBranchName(branch))
+	})
 }
 
 func TestPRTracker_addLowConfidenceComments(t *testing.T) {
@@ -932,3 +968,137 @@ func TestPRTracker_PRInfoFields(t *testing.T) {
 	assert.Len(t, pr.CommitSHAs[0], 40, "Commit SHA should be 40 characters")
 	assert.NotZero(t, pr.Timestamp, "Timestamp should be set")
 }
+
+func TestEstimatePRCounts(t *testing.T) {
+	makeFix := func(violationID, phaseID, file string) FixRecord {
+		return FixRecord{
+			Violation: violation.Violation{ID: violationID},
+			Incident:  violation.Incident{URI: "file:///src/" + file},
+			Result:    fixer.FixResult{ViolationID: violationID, FilePath: file, Success: true},
+			PhaseID:   phaseID,
+		}
+	}
+
+	t.Run("sample fix set", func(t *testing.T) {
+		fixes := []FixRecord{
+			makeFix("violation-a", "phase-1", "A.java"),
+			makeFix("violation-a", "phase-1", "B.java"),
+			makeFix("violation-b", "phase-1", "C.java"),
+			makeFix("violation-c", "phase-2", "D.java"),
+			makeFix("violation-c", "phase-2", "E.java"),
+		}
+
+		preview := EstimatePRCounts(fixes)
+
+		assert.Equal(t, 3, preview.PerViolation)
+		assert.Equal(t, 5, preview.PerIncident)
+		assert.Equal(t, 2, preview.PerPhase)
+		assert.Equal(t, 1, preview.AtEnd)
+
+		assert.Equal(t, 3, preview.Count(PRStrategyPerViolation))
+		assert.Equal(t, 5, preview.Count(PRStrategyPerIncident))
+		assert.Equal(t, 2, preview.Count(PRStrategyPerPhase))
+		assert.Equal(t, 1, preview.Count(PRStrategyAtEnd))
+		assert.Equal(t, 0, preview.Count(PRStrategyNone))
+	})
+
+	t.Run("fixes without phases", func(t *testing.T) {
+		fixes := []FixRecord{
+			makeFix("violation-a", "", "A.java"),
+			makeFix("violation-b", "", "B.java"),
+		}
+
+		preview := EstimatePRCounts(fixes)
+
+		assert.Equal(t, 2, preview.PerViolation)
+		assert.Equal(t, 2, preview.PerIncident)
+		assert.Equal(t, 0, preview.PerPhase)
+		assert.Equal(t, 1, preview.AtEnd)
+	})
+
+	t.Run("no fixes", func(t *testing.T) {
+		assert.Equal(t, PRCountPreview{}, EstimatePRCounts(nil))
+	})
+
+	t.Run("tracker uses tracked fixes", func(t *testing.T) {
+		tracker := &PRTracker{
+			fixesByViolation: make(map[string][]FixRecord),
+			fixesByPhase:     make(map[string][]FixRecord),
+			allFixes:         make([]FixRecord, 0),
+		}
+
+		v := violation.Violation{ID: "violation-a"}
+		for i := 0; i < 2; i++ {
+			result := &fixer.FixResult{ViolationID: v.ID, Success: true}
+			require.NoError(t, tracker.TrackForPRWithPhase(v, violation.Incident{}, result, "phase-1"))
+		}
+
+		preview := tracker.EstimatePRCounts()
+		assert.Equal(t, 1, preview.PerViolation)
+		assert.Equal(t, 2, preview.PerIncident)
+		assert.Equal(t, 1, preview.PerPhase)
+		assert.Equal(t, 1, preview.AtEnd)
+	})
+}
+
+func TestPRTracker_CreatePRBacksOffOnRateLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(PullRequestResponse{Number: 42, HTMLURL: "https://github.com/o/r/pull/42"})
+	}))
+	defer server.Close()
+
+	var clientWaits []time.Duration
+	client := &GitHubClient{
+		token:   "test-token",
+		owner:   "o",
+		repo:    "r",
+		baseURL: server.URL,
+		client:  server.Client(),
+		sleep:   func(d time.Duration) { clientWaits = append(clientWaits, d) },
+	}
+
+	var trackerWaits []time.Duration
+	tracker := &PRTracker{
+		config:       PRConfig{OperationDelay: time.Hour},
+		githubClient: client,
+		progress:     &NoOpProgressWriter{},
+		sleep:        func(d time.Duration) { trackerWaits = append(trackerWaits, d) },
+	}
+
+	pr, err := tracker.createPR("title", "body", "head", "main")
+	require.NoError(t, err)
+	assert.Equal(t, 42, pr.Number)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, clientWaits)
+
+	// The first operation is not delayed, the next one waits for OperationDelay
+	assert.Empty(t, trackerWaits)
+	_, err = tracker.createPR("title 2", "body", "head-2", "main")
+	require.NoError(t, err)
+	require.Len(t, trackerWaits, 1)
+	assert.InDelta(t, time.Hour.Seconds(), trackerWaits[0].Seconds(), 1)
+}
+
+func TestPRTracker_ThrottleDisabled(t *testing.T) {
+	tracker := &PRTracker{
+		config: PRConfig{OperationDelay: time.Hour, DryRun: true},
+		sleep:  func(time.Duration) { t.Fatal("dry-run must not throttle") },
+	}
+	tracker.throttle()
+	tracker.throttle()
+
+	tracker = &PRTracker{
+		sleep: func(time.Duration) { t.Fatal("zero delay must not throttle") },
+	}
+	tracker.throttle()
+	tracker.throttle()
+}
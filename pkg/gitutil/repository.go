@@ -0,0 +1,91 @@
+package gitutil
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RepositoryInfo describes the kind of git repository an input directory belongs to
+type RepositoryInfo struct {
+	Bare           bool   // Repository has no working tree
+	Submodule      bool   // Directory is a submodule of another repository
+	Superproject   string // Working tree of the parent repository (submodules only)
+	DetachedHEAD   bool   // HEAD is not on a branch
+	WorkingTreeDir string // Top-level directory of the working tree (empty for bare repos)
+}
+
+// InspectRepository determines whether dir is a regular repository, a bare
+// repository or a submodule. It returns an error if dir is not inside a git repository.
+func InspectRepository(dir string) (*RepositoryInfo, error) {
+	bare, err := gitRevParse(dir, "--is-bare-repository")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository", dir)
+	}
+
+	info := &RepositoryInfo{Bare: bare == "true"}
+	if info.Bare {
+		return info, nil
+	}
+
+	if info.WorkingTreeDir, err = gitRevParse(dir, "--show-toplevel"); err != nil {
+		return nil, fmt.Errorf("failed to determine working tree of %s: %w", dir, err)
+	}
+
+	// Empty output means dir is not a submodule
+	if superproject, err := gitRevParse(dir, "--show-superproject-working-tree"); err == nil && superproject != "" {
+		info.Submodule = true
+		info.Superproject = superproject
+	}
+
+	// symbolic-ref fails when HEAD points directly at a commit
+	cmd := exec.Command("git", "symbolic-ref", "-q", "HEAD")
+	cmd.Dir = dir
+	info.DetachedHEAD = cmd.Run() != nil
+
+	return info, nil
+}
+
+// ValidateRepository checks that dir can be used for commits and pull requests.
+// Supported setups are a regular clone, or a submodule with a branch checked out
+// (commits and PRs then go to the submodule's own repository). Bare repositories
+// and submodules on a detached HEAD are rejected with instructions to fix them.
+func ValidateRepository(dir string) error {
+	info, err := InspectRepository(dir)
+	if err != nil {
+		return fmt.Errorf("input directory is not a git repository: %s\n\n"+
+			"To fix:\n"+
+			"  - Run kantra-ai against a clone of your project, or\n"+
+			"  - Initialize a repository: git -C %s init", dir, dir)
+	}
+
+	if info.Bare {
+		return fmt.Errorf("input directory is a bare git repository: %s\n\n"+
+			"Bare repositories have no working tree, so fixes cannot be applied or committed.\n\n"+
+			"To fix:\n"+
+			"  1. Clone it into a working directory: git clone %s <workdir>\n"+
+			"  2. Run kantra-ai with --input=<workdir>", dir, dir)
+	}
+
+	if info.Submodule && info.DetachedHEAD {
+		return fmt.Errorf("input directory is a git submodule with a detached HEAD: %s\n"+
+			"  (superproject: %s)\n\n"+
+			"Commits and PRs are created in the submodule's own repository, which needs a branch.\n\n"+
+			"To fix:\n"+
+			"  1. Check out a branch in the submodule: git -C %s checkout -b <branch>\n"+
+			"  2. Re-run kantra-ai, then update the submodule pointer in the superproject", dir, info.Superproject, dir)
+	}
+
+	return nil
+}
+
+// gitRevParse runs git rev-parse with a single flag and returns the trimmed output
+func gitRevParse(dir, flag string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", flag)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,109 @@
+package gitutil
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs a git command in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, output)
+}
+
+// createTestSubmodule creates a superproject containing a submodule at "sub"
+// and returns the submodule directory
+func createTestSubmodule(t *testing.T) string {
+	upstream := createTestGitRepo(t)
+	require.NoError(t, createAndCommitFile(t, upstream, filepath.Join(upstream, "lib.java"), "class Lib {}"))
+
+	super := createTestGitRepo(t)
+	require.NoError(t, createAndCommitFile(t, super, filepath.Join(super, "app.java"), "class App {}"))
+	runGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", upstream, "sub")
+
+	return filepath.Join(super, "sub")
+}
+
+func TestInspectRepository(t *testing.T) {
+	t.Run("regular repository", func(t *testing.T) {
+		repo := createTestGitRepo(t)
+		require.NoError(t, createAndCommitFile(t, repo, filepath.Join(repo, "a.txt"), "a"))
+
+		info, err := InspectRepository(repo)
+		require.NoError(t, err)
+		assert.False(t, info.Bare)
+		assert.False(t, info.Submodule)
+		assert.False(t, info.DetachedHEAD)
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "--bare")
+
+		info, err := InspectRepository(dir)
+		require.NoError(t, err)
+		assert.True(t, info.Bare)
+	})
+
+	t.Run("submodule", func(t *testing.T) {
+		sub := createTestSubmodule(t)
+
+		info, err := InspectRepository(sub)
+		require.NoError(t, err)
+		assert.True(t, info.Submodule)
+		assert.NotEmpty(t, info.Superproject)
+		assert.False(t, info.Bare)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		_, err := InspectRepository(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateRepository(t *testing.T) {
+	t.Run("regular repository is valid", func(t *testing.T) {
+		repo := createTestGitRepo(t)
+		require.NoError(t, createAndCommitFile(t, repo, filepath.Join(repo, "a.txt"), "a"))
+		assert.NoError(t, ValidateRepository(repo))
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "--bare")
+
+		err := ValidateRepository(dir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bare git repository")
+		assert.Contains(t, err.Error(), "git clone")
+	})
+
+	t.Run("submodule on a branch is valid", func(t *testing.T) {
+		sub := createTestSubmodule(t)
+		runGit(t, sub, "checkout", "-B", "work")
+		assert.NoError(t, ValidateRepository(sub))
+	})
+
+	t.Run("submodule with detached HEAD", func(t *testing.T) {
+		sub := createTestSubmodule(t)
+		runGit(t, sub, "checkout", "--detach")
+
+		err := ValidateRepository(sub)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "submodule with a detached HEAD")
+		assert.Contains(t, err.Error(), "checkout -b")
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		err := ValidateRepository(t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a git repository")
+	})
+}
@@ -5,6 +5,7 @@ package gitutil
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tsanders/kantra-ai/pkg/fixer"
@@ -25,17 +26,53 @@ const (
 	StrategyAtEnd
 )
 
+// commitStrategies lists the selectable strategies, in the order they are documented
+var commitStrategies = []CommitStrategy{StrategyPerViolation, StrategyPerIncident, StrategyAtEnd}
+
+// StrategyNames returns the command-line names of the commit strategies
+func StrategyNames() []string {
+	names := make([]string, 0, len(commitStrategies))
+	for _, strategy := range commitStrategies {
+		names = append(names, strategy.String())
+	}
+	return names
+}
+
 // ParseStrategy parses a strategy string into a CommitStrategy
 func ParseStrategy(s string) (CommitStrategy, error) {
+	for _, strategy := range commitStrategies {
+		if s == strategy.String() {
+			return strategy, nil
+		}
+	}
+	return StrategyNone, fmt.Errorf("invalid commit strategy: %s (must be one of: %s)", s, strings.Join(StrategyNames(), ", "))
+}
+
+// ParseCategoryStrategies parses a map of violation category (mandatory,
+// optional, potential) to strategy name
+func ParseCategoryStrategies(names map[string]string) (map[string]CommitStrategy, error) {
+	strategies := make(map[string]CommitStrategy, len(names))
+	for category, name := range names {
+		strategy, err := ParseStrategy(name)
+		if err != nil {
+			return nil, fmt.Errorf("category %s: %w", category, err)
+		}
+		strategies[strings.ToLower(category)] = strategy
+	}
+	return strategies, nil
+}
+
+// String returns the strategy's command-line name
+func (s CommitStrategy) String() string {
 	switch s {
-	case "per-violation":
-		return StrategyPerViolation, nil
-	case "per-incident":
-		return StrategyPerIncident, nil
-	case "at-end":
-		return StrategyAtEnd, nil
+	case StrategyPerViolation:
+		return "per-violation"
+	case StrategyPerIncident:
+		return "per-incident"
+	case StrategyAtEnd:
+		return "at-end"
 	default:
-		return StrategyNone, fmt.Errorf("invalid commit strategy: %s (must be one of: per-violation, per-incident, at-end)", s)
+		return ""
 	}
 }
 
@@ -78,7 +115,13 @@ type CommitTracker struct {
 	fixesByViolation map[string][]FixRecord
 	allFixes         []FixRecord
 	lastViolationID  string
-	commits          []CommitInfo // Track all created commits
+	commits          []CommitInfo    // Track all created commits
+	squash           bool            // Collapse per-incident commits into one per violation
+	messageTemplate  *CommitTemplate // Custom commit messages (nil = built-in format)
+	noVerify         bool            // Skip git hooks when committing
+
+	// Strategy per violation category; other categories use strategy
+	categoryStrategies map[string]CommitStrategy
 }
 
 // NewCommitTracker creates a new CommitTracker
@@ -94,6 +137,57 @@ func NewCommitTracker(strategy CommitStrategy, workingDir string, providerName s
 	}
 }
 
+// SetSquash collapses per-incident commits into one commit per violation:
+// fixes are buffered until the violation changes and committed together with
+// a message listing every file and line fixed. Other strategies are unaffected.
+func (ct *CommitTracker) SetSquash(squash bool) {
+	ct.squash = squash
+}
+
+// SetNoVerify makes commits skip the repository's pre-commit and commit-msg
+// hooks (git commit --no-verify).
+func (ct *CommitTracker) SetNoVerify(noVerify bool) {
+	ct.noVerify = noVerify
+}
+
+// SetCategoryStrategies commits fixes of the mapped violation categories with
+// their own strategy, e.g. mandatory per-violation and optional at-end. Fixes
+// of other categories use the tracker's strategy.
+func (ct *CommitTracker) SetCategoryStrategies(strategies map[string]CommitStrategy) {
+	ct.categoryStrategies = strategies
+}
+
+// strategyFor returns the strategy for fixes of v
+func (ct *CommitTracker) strategyFor(v violation.Violation) CommitStrategy {
+	if strategy, ok := ct.categoryStrategies[strings.ToLower(v.Category)]; ok {
+		return strategy
+	}
+	return ct.strategy
+}
+
+// createCommit commits the staged changes, honoring SetNoVerify
+func (ct *CommitTracker) createCommit(message string) (string, error) {
+	if ct.noVerify {
+		return CreateCommitNoVerify(ct.workingDir, message)
+	}
+	return CreateCommit(ct.workingDir, message)
+}
+
+// SetMessageTemplate renders commit messages from tmpl instead of the
+// built-in format. nil restores the built-in format.
+func (ct *CommitTracker) SetMessageTemplate(tmpl *CommitTemplate) {
+	ct.messageTemplate = tmpl
+}
+
+// commitMessage returns the templated message for a commit of fixes, or
+// defaultMessage if no template is set
+func (ct *CommitTracker) commitMessage(defaultMessage string, fixes []FixRecord, strategy CommitStrategy) (string, error) {
+	if ct.messageTemplate == nil {
+		return defaultMessage, nil
+	}
+	return ct.messageTemplate.Render(newCommitMessageData(fixes, ct.providerName, strategy))
+}
+
 // TrackFix records a successful fix and potentially creates a commit
 func (ct *CommitTracker) TrackFix(v violation.Violation, incident violation.Incident, result *fixer.FixResult) error {
 	record := FixRecord{
@@ -103,10 +197,13 @@ func (ct *CommitTracker) TrackFix(v violation.Violation, incident violation.Inci
 		Timestamp: time.Now(),
 	}
 
-	switch ct.strategy {
+	switch ct.strategyFor(v) {
 	case StrategyPerViolation:
 		return ct.trackForPerViolation(record)
 	case StrategyPerIncident:
+		if ct.squash {
+			return ct.trackForPerViolation(record)
+		}
 		return ct.commitPerIncident(record)
 	case StrategyAtEnd:
 		return ct.trackForAtEnd(record)
@@ -166,13 +263,18 @@ func (ct *CommitTracker) commitPerIncident(record FixRecord) error {
 		record.Violation.Description,
 		record.Result.FilePath,
 		record.Incident.LineNumber,
+		record.Result.Explanation,
 		record.Result.Cost,
 		record.Result.TokensUsed,
 		ct.providerName,
 	)
+	message, err = ct.commitMessage(message, []FixRecord{record}, StrategyPerIncident)
+	if err != nil {
+		return err
+	}
 
 	// Create commit
-	sha, err := CreateCommit(ct.workingDir, message)
+	sha, err := ct.createCommit(message)
 	if err != nil {
 		return fmt.Errorf("failed to create per-incident commit: %w", err)
 	}
@@ -228,9 +330,13 @@ func (ct *CommitTracker) commitViolation(violationID string) error {
 		fixes,
 		ct.providerName,
 	)
+	message, err = ct.commitMessage(message, fixes, StrategyPerViolation)
+	if err != nil {
+		return err
+	}
 
 	// Create commit
-	sha, err := CreateCommit(ct.workingDir, message)
+	sha, err := ct.createCommit(message)
 	if err != nil {
 		return fmt.Errorf("failed to create violation commit: %w", err)
 	}
@@ -255,23 +361,16 @@ func (ct *CommitTracker) commitViolation(violationID string) error {
 
 // Finalize commits any remaining fixes based on strategy
 func (ct *CommitTracker) Finalize() error {
-	switch ct.strategy {
-	case StrategyPerViolation:
-		// Commit the last violation if there are pending fixes
-		if ct.lastViolationID != "" {
-			if err := ct.commitViolation(ct.lastViolationID); err != nil {
-				return err
-			}
+	// Commit the last violation if there are pending per-violation (or
+	// squashed per-incident) fixes. Per-incident commits were created
+	// incrementally.
+	if ct.lastViolationID != "" {
+		if err := ct.commitViolation(ct.lastViolationID); err != nil {
+			return err
 		}
-	case StrategyAtEnd:
-		return ct.commitAtEnd()
-	case StrategyPerIncident:
-		// Nothing to do - commits were created incrementally
-		return nil
-	default:
-		return nil
 	}
-	return nil
+	// With per-category strategies, at-end fixes follow the other commits
+	return ct.commitAtEnd()
 }
 
 // commitAtEnd commits all accumulated fixes in one commit
@@ -291,11 +390,26 @@ func (ct *CommitTracker) commitAtEnd() error {
 		}
 	}
 
+	// Fixes may already be committed with a per-violation commit of another
+	// category that changed the same file
+	hasChanges, err := HasStagedChanges(ct.workingDir)
+	if err != nil {
+		return fmt.Errorf("failed to check for staged changes: %w", err)
+	}
+	if !hasChanges {
+		fmt.Printf("⏭️  Skipping batch commit (no changes to commit)\n")
+		return nil
+	}
+
 	// Create commit message
 	message := FormatAtEndMessage(ct.fixesByViolation, ct.providerName)
+	message, err = ct.commitMessage(message, ct.allFixes, StrategyAtEnd)
+	if err != nil {
+		return err
+	}
 
 	// Create commit
-	sha, err := CreateCommit(ct.workingDir, message)
+	sha, err := ct.createCommit(message)
 	if err != nil {
 		return fmt.Errorf("failed to create at-end commit: %w", err)
 	}
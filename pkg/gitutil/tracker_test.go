@@ -201,6 +201,124 @@ func TestCommitTracker_Integration(t *testing.T) {
 	})
 }
 
+func TestParseCategoryStrategies(t *testing.T) {
+	strategies, err := ParseCategoryStrategies(map[string]string{"Mandatory": "per-violation", "optional": "at-end"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]CommitStrategy{"mandatory": StrategyPerViolation, "optional": StrategyAtEnd}, strategies)
+
+	_, err = ParseCategoryStrategies(map[string]string{"optional": "later"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "category optional")
+}
+
+func TestCommitTracker_CategoryStrategies(t *testing.T) {
+	tmpDir := createTestGitRepo(t)
+	configGitUser(t, tmpDir)
+
+	tracker := NewCommitTracker(StrategyPerIncident, tmpDir, "claude")
+	tracker.SetCategoryStrategies(map[string]CommitStrategy{
+		"mandatory": StrategyPerViolation,
+		"optional":  StrategyAtEnd,
+	})
+
+	fixes := []struct {
+		violation violation.Violation
+		file      string
+	}{
+		{violation.Violation{ID: "m1", Description: "Mandatory 1", Category: "mandatory"}, "m1-a.txt"},
+		{violation.Violation{ID: "m1", Description: "Mandatory 1", Category: "mandatory"}, "m1-b.txt"},
+		{violation.Violation{ID: "o1", Description: "Optional 1", Category: "optional"}, "o1.txt"},
+		{violation.Violation{ID: "m2", Description: "Mandatory 2", Category: "mandatory"}, "m2.txt"},
+		{violation.Violation{ID: "o2", Description: "Optional 2", Category: "optional"}, "o2.txt"},
+		{violation.Violation{ID: "p1", Description: "Potential 1", Category: "potential"}, "p1.txt"},
+	}
+	commitsAfter := make([]int, 0, len(fixes))
+	for _, fix := range fixes {
+		path := filepath.Join(tmpDir, fix.file)
+		require.NoError(t, os.WriteFile(path, []byte("fixed"), 0644))
+		err := tracker.TrackFix(fix.violation, violation.Incident{URI: "file://" + path, LineNumber: 1}, &fixer.FixResult{
+			FilePath: fix.file,
+			Success:  true,
+		})
+		require.NoError(t, err)
+		commitsAfter = append(commitsAfter, len(tracker.GetCommits()))
+	}
+
+	// m1 is committed once m2 starts; the unmapped potential fix uses the
+	// tracker's per-incident strategy; optional fixes wait for Finalize
+	assert.Equal(t, []int{0, 0, 0, 1, 1, 2}, commitsAfter)
+
+	require.NoError(t, tracker.Finalize())
+	commits := tracker.GetCommits()
+	require.Len(t, commits, 4)
+
+	assert.Equal(t, "m1", commits[0].ViolationID)
+	assert.Equal(t, 2, commits[0].FileCount)
+	assert.Equal(t, "p1", commits[1].ViolationID)
+	assert.Equal(t, 1, commits[1].FileCount)
+	assert.Equal(t, "m2", commits[2].ViolationID)
+	assert.Equal(t, 1, commits[2].FileCount)
+
+	// The optional fixes are batched into the last commit
+	assert.Empty(t, commits[3].ViolationID)
+	assert.Equal(t, 2, commits[3].FileCount)
+	assert.Contains(t, commits[3].Message, "Batch remediation of 2 violations")
+	assert.Contains(t, commits[3].Message, "o1 (optional)")
+	assert.Contains(t, commits[3].Message, "o2 (optional)")
+	assert.NotContains(t, commits[3].Message, "m1")
+
+	cmd := exec.Command("git", "show", "--name-only", "--format=", commits[3].SHA)
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "o1.txt\no2.txt\n", string(output))
+}
+
+func TestCommitTracker_ExplanationsInCommitBody(t *testing.T) {
+	commitBody := func(t *testing.T, dir string) string {
+		t.Helper()
+		cmd := exec.Command("git", "log", "-1", "--format=%B")
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return string(output)
+	}
+	track := func(t *testing.T, tracker *CommitTracker, dir, file, explanation string) {
+		t.Helper()
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.WriteFile(path, []byte("fixed"), 0644))
+		v := violation.Violation{ID: "javax-to-jakarta", Description: "Replace javax", Category: "mandatory"}
+		require.NoError(t, tracker.TrackFix(v, violation.Incident{URI: "file://" + path, LineNumber: 3}, &fixer.FixResult{
+			FilePath:    file,
+			Explanation: explanation,
+			Success:     true,
+		}))
+	}
+
+	t.Run("per-incident", func(t *testing.T) {
+		tmpDir := createTestGitRepo(t)
+		configGitUser(t, tmpDir)
+		tracker := NewCommitTracker(StrategyPerIncident, tmpDir, "claude")
+
+		track(t, tracker, tmpDir, "App.java", "Replaced the javax.servlet import with jakarta.servlet")
+		assert.Contains(t, commitBody(t, tmpDir), "Explanation: Replaced the javax.servlet import with jakarta.servlet\n")
+	})
+
+	t.Run("at-end", func(t *testing.T) {
+		tmpDir := createTestGitRepo(t)
+		configGitUser(t, tmpDir)
+		tracker := NewCommitTracker(StrategyAtEnd, tmpDir, "claude")
+
+		track(t, tracker, tmpDir, "App.java", "Switched App to jakarta")
+		track(t, tracker, tmpDir, "Filter.java", "Switched Filter to jakarta")
+		require.NoError(t, tracker.Finalize())
+
+		body := commitBody(t, tmpDir)
+		assert.Contains(t, body, "- App.java:3 (javax-to-jakarta): Switched App to jakarta\n")
+		assert.Contains(t, body, "- Filter.java:3 (javax-to-jakarta): Switched Filter to jakarta\n")
+	})
+}
+
 func TestFormatPerViolationMessage_EmptyFixes(t *testing.T) {
 	// Edge case: empty fixes list
 	message := FormatPerViolationMessage(
@@ -306,6 +424,40 @@ func TestCommitTracker_GetCommits(t *testing.T) {
 		assert.NotEqual(t, commits[0].SHA, commits[1].SHA)
 	})
 
+	t.Run("squashes per-incident commits per violation", func(t *testing.T) {
+		tmpDir := createTestGitRepo(t)
+		configGitUser(t, tmpDir)
+		tracker := NewCommitTracker(StrategyPerIncident, tmpDir, "claude")
+		tracker.SetSquash(true)
+
+		trackFix := func(v violation.Violation, filename string, line int) {
+			filepath := filepath.Join(tmpDir, filename)
+			require.NoError(t, os.WriteFile(filepath, []byte("fixed"), 0644))
+			incident := violation.Incident{URI: "file://" + filepath, LineNumber: line}
+			result := &fixer.FixResult{FilePath: filename, Success: true}
+			require.NoError(t, tracker.TrackFix(v, incident, result))
+		}
+
+		v1 := violation.Violation{ID: "v1", Description: "Test 1", Category: "mandatory", Effort: 1}
+		trackFix(v1, "a.txt", 3)
+		trackFix(v1, "b.txt", 7)
+		assert.Empty(t, tracker.GetCommits(), "fixes are buffered until the violation changes")
+
+		v2 := violation.Violation{ID: "v2", Description: "Test 2", Category: "optional", Effort: 2}
+		trackFix(v2, "c.txt", 1)
+		require.Len(t, tracker.GetCommits(), 1)
+
+		require.NoError(t, tracker.Finalize())
+
+		commits := tracker.GetCommits()
+		require.Len(t, commits, 2)
+		assert.Equal(t, "v1", commits[0].ViolationID)
+		assert.Contains(t, commits[0].Message, "- a.txt:3\n- b.txt:7\n")
+		assert.Contains(t, commits[0].Message, "Incidents Fixed: 2")
+		assert.Equal(t, "v2", commits[1].ViolationID)
+		assert.Contains(t, commits[1].Message, "- c.txt:1\n")
+	})
+
 	t.Run("tracks commit from at-end strategy", func(t *testing.T) {
 		tmpDir := createTestGitRepo(t)
 		configGitUser(t, tmpDir)
@@ -1,7 +1,12 @@
 package gitutil
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/tsanders/kantra-ai/pkg/fixer"
@@ -17,14 +22,77 @@ type VerifiedCommitTracker struct {
 	stats         VerificationStats
 	githubClient  *GitHubClient // Optional: for reporting status checks
 	workingDir    string
+
+	trackedViolations []string              // Violation IDs with tracked fixes, in order
+	results           map[string]bool       // Verification outcome per violation ID
+	changes           []trackedChange       // Files changed by tracked fixes, in order
+	failures          []VerificationFailure // Failed verifications, in order
+	reverted          []RevertedIncident    // Fixes reverted by per-fix verification, in order
+	restored          map[string]bool       // Files restored by a revert
+}
+
+// ErrFixReverted is returned by TrackFix when a fix failed per-fix
+// verification and was reverted. The run continues, but the incident is
+// unfixed and needs manual handling.
+var ErrFixReverted = errors.New("fix reverted after failing verification")
+
+// RevertedIncident is an incident whose fix failed per-fix verification and
+// was reverted
+type RevertedIncident struct {
+	ViolationID string
+	Incident    violation.Incident
+	FilePath    string
+	Command     string // Verification command that failed
+}
+
+// FailureOutputLines is how many lines of a failed verification's output
+// are shown on the console
+const FailureOutputLines = 20
+
+// VerificationFailure records a failed verification and why it failed
+type VerificationFailure struct {
+	ViolationIDs []string // Violations whose fixes were verified
+	Command      string
+	Error        string
+	Output       string // Complete stdout and stderr of the command
+	Duration     time.Duration
+	Timestamp    time.Time
+}
+
+// OutputTail returns the last n lines of the failure's output, prefixed
+// with "..." if lines were dropped
+func (f VerificationFailure) OutputTail(n int) string {
+	output := strings.TrimRight(f.Output, "\n")
+	lines := strings.Split(output, "\n")
+	if len(lines) <= n {
+		return output
+	}
+	return "...\n" + strings.Join(lines[len(lines)-n:], "\n")
+}
+
+// trackedChange is a file changed by a fix for a violation
+type trackedChange struct {
+	file        string
+	violationID string
+}
+
+// moduleChanges groups the violations whose fixes touched a module
+type moduleChanges struct {
+	dir          string // Relative to the working directory; "." is the root
+	language     string // Language with a configured verification command ("" = none)
+	violationIDs []string
+	files        []string // Changed files, relative to the working directory
 }
 
 // VerificationStats tracks verification outcomes
 type VerificationStats struct {
-	TotalVerifications int
-	PassedVerifications int
-	FailedVerifications int
-	SkippedFixes       int // Fixes skipped due to verification failure
+	TotalVerifications   int
+	PassedVerifications  int
+	FailedVerifications  int
+	SkippedFixes         int // Fixes skipped due to verification failure
+	SkippedVerifications int // Verifications not run (e.g. dry-run)
+	WarningVerifications int // Passed verifications whose failure was downgraded to a warning
+	RevertedFixes        int // Per-fix failures whose file was restored to its pre-fix content
 }
 
 // NewVerifiedCommitTracker creates a commit tracker with verification
@@ -53,6 +121,13 @@ func NewVerifiedCommitTrackerWithGitHub(
 		if err != nil {
 			return nil, fmt.Errorf("failed to create verifier: %w", err)
 		}
+		// Coverage is compared against the project before any fix
+		if verifyConfig.SkipReason() == "" {
+			if err := v.MeasureBaseline(); err != nil {
+				v.Close()
+				return nil, err
+			}
+		}
 	}
 
 	return &VerifiedCommitTracker{
@@ -62,38 +137,82 @@ func NewVerifiedCommitTrackerWithGitHub(
 		stats:         VerificationStats{},
 		githubClient:  githubClient,
 		workingDir:    workingDir,
+		results:       make(map[string]bool),
+		restored:      make(map[string]bool),
 	}, nil
 }
 
 // TrackFix records a fix and verifies it based on the strategy
 func (vct *VerifiedCommitTracker) TrackFix(v violation.Violation, incident violation.Incident, result *fixer.FixResult) error {
-	// If no verification, just track the fix
-	if vct.verifier == nil {
-		return vct.commitTracker.TrackFix(v, incident, result)
+	if !vct.isTracked(v.ID) {
+		vct.trackedViolations = append(vct.trackedViolations, v.ID)
 	}
 
-	// Determine if we should verify now based on strategy
-	shouldVerify := vct.shouldVerifyNow(v, incident)
+	// Per-fix verification runs before the fix is tracked, so a fix that
+	// fails verification is reverted rather than committed
+	if vct.shouldVerifyNow(v, incident) && result != nil && result.FilePath != "" {
+		// Batched fixes are all applied before they are verified, so reverting
+		// an earlier fix to the same file may have undone this one too
+		if vct.undoneByRevert(result) {
+			return vct.recordRevert(v, incident, result)
+		}
 
-	// Track the fix first
-	if err := vct.commitTracker.TrackFix(v, incident, result); err != nil {
-		return err
+		group := vct.changeGroup(result.FilePath)
+		group.files = []string{result.FilePath}
+		fixVerifier := vct.verifier.ForChanges(group.files)
+		if group.language != "" {
+			fixVerifier = vct.verifierFor(group)
+		}
+
+		passed, err := vct.verifyWith(fixVerifier, []string{v.ID}, func() error {
+			return vct.restoreFile(result)
+		})
+		if err != nil {
+			return err
+		}
+		if !passed {
+			return vct.recordRevert(v, incident, result)
+		}
 	}
 
-	// Run verification if needed
-	if shouldVerify {
-		return vct.runVerification()
+	if result != nil && result.FilePath != "" {
+		vct.changes = append(vct.changes, trackedChange{file: result.FilePath, violationID: v.ID})
 	}
+	return vct.commitTracker.TrackFix(v, incident, result)
+}
 
-	return nil
+// undoneByRevert reports whether a fix is no longer on disk because its file
+// was restored after an earlier fix to it failed verification
+func (vct *VerifiedCommitTracker) undoneByRevert(result *fixer.FixResult) bool {
+	if !vct.restored[result.FilePath] || result.FixedContent == "" {
+		return false
+	}
+	content, err := os.ReadFile(filepath.Join(vct.workingDir, result.FilePath))
+	return err == nil && string(content) != result.FixedContent
+}
+
+// recordRevert records a reverted fix for manual handling and returns
+// ErrFixReverted
+func (vct *VerifiedCommitTracker) recordRevert(v violation.Violation, incident violation.Incident, result *fixer.FixResult) error {
+	reverted := RevertedIncident{ViolationID: v.ID, Incident: incident, FilePath: result.FilePath}
+	if len(vct.failures) > 0 {
+		reverted.Command = vct.failures[len(vct.failures)-1].Command
+	}
+	vct.reverted = append(vct.reverted, reverted)
+	return fmt.Errorf("%w: %s", ErrFixReverted, result.FilePath)
 }
 
 // Finalize commits any pending fixes and runs final verification if needed
 func (vct *VerifiedCommitTracker) Finalize() error {
+	// The verification session ends with the run, whatever the outcome
+	if vct.verifier != nil {
+		defer vct.closeVerifier()
+	}
+
 	// For at-end strategy, verify before final commit
 	if vct.verifier != nil && vct.verifyConfig.Strategy == verifier.StrategyAtEnd {
 		// Don't commit yet - we need to verify first
-		if err := vct.runVerification(); err != nil {
+		if err := vct.verifyTouchedModules(); err != nil {
 			return err
 		}
 	}
@@ -106,6 +225,14 @@ func (vct *VerifiedCommitTracker) Finalize() error {
 	return nil
 }
 
+// closeVerifier ends the verifier's session. Stopping build daemons is
+// cleanup, so a failure is reported but doesn't fail the run.
+func (vct *VerifiedCommitTracker) closeVerifier() {
+	if err := vct.verifier.Close(); err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+	}
+}
+
 // shouldVerifyNow determines if verification should run now
 func (vct *VerifiedCommitTracker) shouldVerifyNow(v violation.Violation, incident violation.Incident) bool {
 	if vct.verifier == nil {
@@ -129,8 +256,155 @@ func (vct *VerifiedCommitTracker) shouldVerifyNow(v violation.Violation, inciden
 	}
 }
 
-// runVerification runs the verification and handles the result
-func (vct *VerifiedCommitTracker) runVerification() error {
+// SetDryRun updates whether verification runs in dry-run mode, so every
+// caller skips verification the same way regardless of how it built the config
+func (vct *VerifiedCommitTracker) SetDryRun(dryRun bool) {
+	vct.verifyConfig.DryRun = dryRun
+	if vct.verifier != nil {
+		vct.verifier.SetDryRun(dryRun)
+	}
+}
+
+// SkipReason explains why verification will not run, or returns "" if it will
+func (vct *VerifiedCommitTracker) SkipReason() string {
+	return vct.verifyConfig.SkipReason()
+}
+
+// VerifyDryRun runs verification once without committing or reverting
+// anything. It is used when verification is forced during a dry-run.
+func (vct *VerifiedCommitTracker) VerifyDryRun() (*verifier.Result, error) {
+	if reason := vct.SkipReason(); reason != "" {
+		vct.stats.SkippedVerifications++
+		return &verifier.Result{Success: true, Skipped: true, SkipReason: reason}, nil
+	}
+
+	vct.stats.TotalVerifications++
+	result, err := vct.verifier.Verify()
+	if err != nil {
+		return nil, fmt.Errorf("verification error: %w", err)
+	}
+	if result.Success {
+		vct.stats.PassedVerifications++
+		if result.Warning {
+			vct.stats.WarningVerifications++
+		}
+	} else {
+		vct.stats.FailedVerifications++
+	}
+	return result, nil
+}
+
+// verifyTouchedModules runs the at-end verification once per module touched
+// by the tracked fixes, so a monorepo only verifies the modules that changed.
+// It stops at the first failure, since the failure reverts all changes.
+func (vct *VerifiedCommitTracker) verifyTouchedModules() error {
+	modules := vct.touchedModules()
+	if len(modules) == 0 {
+		return vct.runVerification(vct.trackedViolations)
+	}
+
+	if len(modules) > 1 || modules[0].dir != "." {
+		dirs := make([]string, len(modules))
+		for i, m := range modules {
+			dirs[i] = m.dir
+			if m.language != "" {
+				dirs[i] += " (" + m.language + ")"
+			}
+		}
+		fmt.Printf("Verifying %d touched module(s): %s\n", len(modules), strings.Join(dirs, ", "))
+	}
+
+	for _, m := range modules {
+		passed, err := vct.verifyWith(vct.verifierFor(m), m.violationIDs, vct.revertUnlessFailFast)
+		if err != nil {
+			return err
+		}
+		if !passed {
+			break
+		}
+	}
+	return nil
+}
+
+// touchedModules groups the tracked changes by module, in the order the
+// modules were first touched. Files in a language with its own verification
+// command are grouped separately, so a polyglot module runs each command.
+// With a custom verification command, which isn't module-aware, everything
+// else belongs to the root.
+func (vct *VerifiedCommitTracker) touchedModules() []moduleChanges {
+	var modules []moduleChanges
+	index := make(map[string]int)
+	for _, change := range vct.changes {
+		group := vct.changeGroup(change.file)
+		key := group.dir + "\x00" + group.language
+
+		i, ok := index[key]
+		if !ok {
+			i = len(modules)
+			index[key] = i
+			modules = append(modules, group)
+		}
+		if !containsString(modules[i].violationIDs, change.violationID) {
+			modules[i].violationIDs = append(modules[i].violationIDs, change.violationID)
+		}
+		if !containsString(modules[i].files, change.file) {
+			modules[i].files = append(modules[i].files, change.file)
+		}
+	}
+	return modules
+}
+
+// changeGroup returns the module and language whose verification covers a
+// changed file (without violation IDs)
+func (vct *VerifiedCommitTracker) changeGroup(file string) moduleChanges {
+	language := verifier.LanguageForFile(file)
+	if !vct.verifyConfig.HasLanguageCommand(language) {
+		language = ""
+	}
+
+	dir := "."
+	if language != "" || vct.verifyConfig.CustomCommand == "" {
+		dir = verifier.ModuleDir(vct.workingDir, file)
+	}
+	return moduleChanges{dir: dir, language: language}
+}
+
+// verifierFor returns the verifier for a group of changes
+func (vct *VerifiedCommitTracker) verifierFor(m moduleChanges) *verifier.Verifier {
+	v := vct.verifier.InModule(m.dir)
+	if m.language != "" {
+		v = vct.verifier.ForLanguage(m.language, m.dir)
+	}
+
+	// The module's verifier runs from its directory
+	files := m.files
+	if m.dir != "." {
+		files = make([]string, 0, len(m.files))
+		for _, file := range m.files {
+			if rel, err := filepath.Rel(m.dir, file); err == nil {
+				files = append(files, rel)
+			}
+		}
+	}
+	return v.ForChanges(files)
+}
+
+// runVerification runs the verification for the fixes of the given
+// violations and handles the result
+func (vct *VerifiedCommitTracker) runVerification(violationIDs []string) error {
+	_, err := vct.verifyWith(vct.verifier, violationIDs, vct.revertUnlessFailFast)
+	return err
+}
+
+// verifyWith runs verification with v for the fixes of the given violations
+// and handles the result, calling rollback to undo the fixes if it fails. It
+// reports whether verification passed; skipped verifications count as passed.
+func (vct *VerifiedCommitTracker) verifyWith(v *verifier.Verifier, violationIDs []string, rollback func() error) (bool, error) {
+	if vct.SkipReason() != "" {
+		vct.stats.SkippedVerifications++
+		return true, nil
+	}
+
 	vct.stats.TotalVerifications++
 
 	// Report pending status to GitHub if enabled
@@ -138,36 +412,59 @@ func (vct *VerifiedCommitTracker) runVerification() error {
 		vct.reportPendingStatus()
 	}
 
-	result, err := vct.verifier.Verify()
+	result, err := v.Verify()
 	if err != nil {
 		// Report error status to GitHub if enabled
 		if vct.githubClient != nil {
 			vct.reportErrorStatus(err)
 		}
-		return fmt.Errorf("verification error: %w", err)
+		return false, fmt.Errorf("verification error: %w", err)
 	}
 
+	vct.recordResult(violationIDs, result.Success)
+
 	if result.Success {
 		vct.stats.PassedVerifications++
+		if result.Warning {
+			vct.stats.WarningVerifications++
+			fmt.Printf("\n⚠ Verification passed with warnings (%s): %s\n", result.Command, result.WarningReason)
+		}
 		// Report success status to GitHub if enabled
 		if vct.githubClient != nil {
 			vct.reportSuccessStatus(result)
 		}
-		return nil
+		return true, nil
 	}
 
 	// Verification failed
 	vct.stats.FailedVerifications++
+	failure := VerificationFailure{
+		ViolationIDs: append([]string(nil), violationIDs...),
+		Command:      result.Command,
+		Output:       result.Output,
+		Duration:     result.Duration,
+		Timestamp:    result.Timestamp,
+	}
+	if result.Error != nil {
+		failure.Error = result.Error.Error()
+	}
+	vct.failures = append(vct.failures, failure)
 
 	// Report failure status to GitHub if enabled
 	if vct.githubClient != nil {
 		vct.reportFailureStatus(result)
 	}
 
+	// Undo the failing changes first, so fail-fast doesn't leave them on disk
+	// and later fixes aren't verified on top of them
+	if err := rollback(); err != nil {
+		return false, fmt.Errorf("failed to revert changes after verification failure: %w", err)
+	}
+
 	// Handle failure based on configuration
 	if vct.verifyConfig.FailFast {
-		return fmt.Errorf("verification failed (fail-fast enabled):\n%s\n\nCommand: %s\nError: %v",
-			result.Output, result.Command, result.Error)
+		return false, fmt.Errorf("verification failed (fail-fast enabled):\n%s\n\nCommand: %s\nError: %v",
+			failure.OutputTail(FailureOutputLines), result.Command, result.Error)
 	}
 
 	// Log failure but continue
@@ -177,21 +474,62 @@ func (vct *VerifiedCommitTracker) runVerification() error {
 	if result.Error != nil {
 		fmt.Printf("  Error: %v\n", result.Error)
 	}
-	fmt.Printf("  Output (last 500 chars):\n")
-	output := result.Output
-	if len(output) > 500 {
-		output = "..." + output[len(output)-500:]
+	fmt.Printf("  Output (last %d lines):\n", FailureOutputLines)
+	fmt.Printf("%s\n\n", indent(failure.OutputTail(FailureOutputLines), "    "))
+
+	vct.stats.SkippedFixes++
+	return false, nil
+}
+
+// recordResult records a verification outcome for each violation. A failure
+// is kept even if a later verification of the same violation passes.
+func (vct *VerifiedCommitTracker) recordResult(violationIDs []string, passed bool) {
+	for _, id := range violationIDs {
+		if previous, ok := vct.results[id]; ok && !previous {
+			continue
+		}
+		vct.results[id] = passed
 	}
-	fmt.Printf("  %s\n\n", output)
+}
+
+// isTracked reports whether fixes for the violation have been tracked
+func (vct *VerifiedCommitTracker) isTracked(violationID string) bool {
+	return containsString(vct.trackedViolations, violationID)
+}
 
-	// For now, we'll revert the last commit if verification fails
-	// In the future, we might want more sophisticated rollback
-	if err := vct.revertLastChange(); err != nil {
-		return fmt.Errorf("failed to revert changes after verification failure: %w", err)
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
 
-	vct.stats.SkippedFixes++
-	return nil
+// VerificationResults returns the verification outcome for each violation
+// whose fixes were verified (true if verification passed)
+func (vct *VerifiedCommitTracker) VerificationResults() map[string]bool {
+	results := make(map[string]bool, len(vct.results))
+	for id, passed := range vct.results {
+		results[id] = passed
+	}
+	return results
+}
+
+// Failures returns the failed verifications, in order
+func (vct *VerifiedCommitTracker) Failures() []VerificationFailure {
+	return append([]VerificationFailure(nil), vct.failures...)
+}
+
+// RevertedIncidents returns the incidents whose fixes were reverted after
+// failing per-fix verification, in order
+func (vct *VerifiedCommitTracker) RevertedIncidents() []RevertedIncident {
+	return append([]RevertedIncident(nil), vct.reverted...)
+}
+
+// indent prefixes every line of s with prefix
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
 }
 
 // revertLastChange reverts the most recent uncommitted changes
@@ -204,11 +542,73 @@ func (vct *VerifiedCommitTracker) revertLastChange() error {
 	return nil
 }
 
+// revertUnlessFailFast reverts the uncommitted changes after a failed
+// verification of several fixes. Fail-fast stops the run instead and leaves
+// the changes in place for inspection.
+func (vct *VerifiedCommitTracker) revertUnlessFailFast() error {
+	if vct.verifyConfig.FailFast {
+		return nil
+	}
+	return vct.revertLastChange()
+}
+
+// restoreFile restores the file changed by a fix that failed per-fix
+// verification to its content before the fix (see UndoFix)
+func (vct *VerifiedCommitTracker) restoreFile(result *fixer.FixResult) error {
+	if err := UndoFix(vct.workingDir, result); err != nil {
+		return err
+	}
+
+	vct.restored[result.FilePath] = true
+	vct.stats.RevertedFixes++
+	fmt.Printf("  ↩ Reverted %s to its content before the fix\n", result.FilePath)
+	return nil
+}
+
+// UndoFix removes a fix's change from its file in workingDir. If the fix's
+// content was recorded, changes other fixes made to the file since are kept;
+// if they touch the same lines, the fix can't be undone and an error is
+// returned. If the content before the
+// fix wasn't recorded, the file is restored from HEAD when git is available.
+func UndoFix(workingDir string, result *fixer.FixResult) error {
+	path := filepath.Join(workingDir, result.FilePath)
+	if result.OriginalContent == "" {
+		if !IsGitInstalled() || !IsGitRepository(workingDir) {
+			return fmt.Errorf("cannot restore %s: its original content is unknown and git is not available", result.FilePath)
+		}
+		return RestoreFile(workingDir, result.FilePath)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", result.FilePath, err)
+	}
+	content := result.OriginalContent
+	if current := string(data); result.FixedContent != "" && current != result.FixedContent {
+		if content, err = fixer.MergeEdits(result.FixedContent, current, result.OriginalContent); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", result.FilePath, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", result.FilePath, err)
+	}
+	return nil
+}
+
 // GetStats returns the verification statistics
 func (vct *VerifiedCommitTracker) GetStats() VerificationStats {
 	return vct.stats
 }
 
+// BaselineCoverage returns the coverage fixes are compared against with
+// coverage verification, or nil for other verification types
+func (vct *VerifiedCommitTracker) BaselineCoverage() *verifier.Coverage {
+	if vct.verifier == nil {
+		return nil
+	}
+	return vct.verifier.Baseline()
+}
+
 // GetCommitTracker returns the underlying commit tracker
 func (vct *VerifiedCommitTracker) GetCommitTracker() *CommitTracker {
 	return vct.commitTracker
@@ -218,7 +618,7 @@ func (vct *VerifiedCommitTracker) GetCommitTracker() *CommitTracker {
 func (vct *VerifiedCommitTracker) reportPendingStatus() {
 	sha, err := GetCurrentCommitSHA(vct.workingDir)
 	if err != nil {
-		fmt.Printf("Warning: failed to get commit SHA for status check: %v\n", err)
+		slog.Warn("failed to get commit SHA for status check", "error", err)
 		return
 	}
 
@@ -240,7 +640,7 @@ func (vct *VerifiedCommitTracker) reportPendingStatus() {
 func (vct *VerifiedCommitTracker) reportSuccessStatus(result *verifier.Result) {
 	sha, err := GetCurrentCommitSHA(vct.workingDir)
 	if err != nil {
-		fmt.Printf("Warning: failed to get commit SHA for status check: %v\n", err)
+		slog.Warn("failed to get commit SHA for status check", "error", err)
 		return
 	}
 
@@ -264,7 +664,7 @@ func (vct *VerifiedCommitTracker) reportSuccessStatus(result *verifier.Result) {
 func (vct *VerifiedCommitTracker) reportFailureStatus(result *verifier.Result) {
 	sha, err := GetCurrentCommitSHA(vct.workingDir)
 	if err != nil {
-		fmt.Printf("Warning: failed to get commit SHA for status check: %v\n", err)
+		slog.Warn("failed to get commit SHA for status check", "error", err)
 		return
 	}
 
@@ -288,7 +688,7 @@ func (vct *VerifiedCommitTracker) reportFailureStatus(result *verifier.Result) {
 func (vct *VerifiedCommitTracker) reportErrorStatus(verifyErr error) {
 	sha, err := GetCurrentCommitSHA(vct.workingDir)
 	if err != nil {
-		fmt.Printf("Warning: failed to get commit SHA for status check: %v\n", err)
+		slog.Warn("failed to get commit SHA for status check", "error", err)
 		return
 	}
 
@@ -315,6 +715,8 @@ func (vct *VerifiedCommitTracker) getStatusContext() string {
 		verifyType = "build"
 	case verifier.VerificationTest:
 		verifyType = "test"
+	case verifier.VerificationCoverage:
+		verifyType = "coverage"
 	}
 	return fmt.Sprintf("kantra-ai/verify-%s", verifyType)
 }
@@ -326,6 +728,8 @@ func (vct *VerifiedCommitTracker) getVerificationDescription() string {
 		return "Build verification"
 	case verifier.VerificationTest:
 		return "Test verification"
+	case verifier.VerificationCoverage:
+		return "Coverage verification"
 	default:
 		return "Verification"
 	}
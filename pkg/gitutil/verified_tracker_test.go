@@ -0,0 +1,429 @@
+package gitutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/verifier"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestVerifiedCommitTracker_DryRunSkipsVerification(t *testing.T) {
+	tmpDir := t.TempDir()
+	marker := filepath.Join(tmpDir, "verified")
+
+	vct, err := NewVerifiedCommitTracker(StrategyAtEnd, tmpDir, "test-provider", verifier.Config{
+		Type:          verifier.VerificationBuild,
+		Strategy:      verifier.StrategyPerFix,
+		WorkingDir:    tmpDir,
+		CustomCommand: "touch " + marker,
+		DryRun:        true,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, vct.SkipReason(), "dry-run")
+	require.NoError(t, vct.runVerification([]string{"v1"}))
+	assert.NoFileExists(t, marker)
+
+	result, err := vct.VerifyDryRun()
+	require.NoError(t, err)
+	assert.True(t, result.Skipped)
+	assert.NoFileExists(t, marker)
+
+	stats := vct.GetStats()
+	assert.Equal(t, 2, stats.SkippedVerifications)
+	assert.Equal(t, 0, stats.TotalVerifications)
+
+	// Turning dry-run off lets verification run again
+	vct.SetDryRun(false)
+	assert.Empty(t, vct.SkipReason())
+	require.NoError(t, vct.runVerification([]string{"v1"}))
+	assert.FileExists(t, marker)
+	assert.Equal(t, 1, vct.GetStats().PassedVerifications)
+	assert.Equal(t, map[string]bool{"v1": true}, vct.VerificationResults())
+}
+
+func TestVerifiedCommitTracker_VerificationResults(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	vct, err := NewVerifiedCommitTracker(StrategyAtEnd, tmpDir, "test-provider", verifier.Config{
+		Type:          verifier.VerificationBuild,
+		Strategy:      verifier.StrategyPerFix,
+		WorkingDir:    tmpDir,
+		CustomCommand: "false",
+		FailFast:      true,
+	})
+	require.NoError(t, err)
+
+	// Skipped verifications record nothing
+	vct.SetDryRun(true)
+	require.NoError(t, vct.runVerification([]string{"v1"}))
+	assert.Empty(t, vct.VerificationResults())
+	vct.SetDryRun(false)
+
+	assert.Error(t, vct.runVerification([]string{"v1"}))
+	assert.Equal(t, map[string]bool{"v1": false}, vct.VerificationResults())
+
+	// A failure is kept even if a later verification passes
+	vct.recordResult([]string{"v1", "v2"}, true)
+	assert.Equal(t, map[string]bool{"v1": false, "v2": true}, vct.VerificationResults())
+}
+
+func TestVerifiedCommitTracker_AtEndVerifiesTouchedModules(t *testing.T) {
+	root := t.TempDir()
+	writeModule := func(dir, source string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module example.com/"+dir+"\n\ngo 1.21\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "lib.go"), []byte(source), 0644))
+	}
+	writeModule("svc-a", "package lib\n")
+	writeModule("svc-b", "package lib\n")
+	// svc-c doesn't compile, so verifying it would fail
+	writeModule("svc-c", "package lib\n\nfunc broken() { undefined() }\n")
+
+	newTracker := func() *VerifiedCommitTracker {
+		vct, err := NewVerifiedCommitTracker(StrategyAtEnd, root, "test-provider", verifier.Config{
+			Type:       verifier.VerificationBuild,
+			Strategy:   verifier.StrategyAtEnd,
+			WorkingDir: root,
+			FailFast:   true,
+		})
+		require.NoError(t, err)
+		return vct
+	}
+	track := func(vct *VerifiedCommitTracker, violationID, file string) {
+		v := violation.Violation{ID: violationID}
+		require.NoError(t, vct.TrackFix(v, violation.Incident{}, &fixer.FixResult{Success: true, FilePath: file}))
+	}
+
+	t.Run("only touched modules are verified", func(t *testing.T) {
+		vct := newTracker()
+		track(vct, "v1", "svc-a/lib.go")
+		track(vct, "v2", "svc-b/lib.go")
+		track(vct, "v1", "svc-b/lib.go")
+
+		modules := vct.touchedModules()
+		require.Len(t, modules, 2)
+		assert.Equal(t, "svc-a", modules[0].dir)
+		assert.Equal(t, []string{"v1"}, modules[0].violationIDs)
+		assert.Equal(t, "svc-b", modules[1].dir)
+		assert.Equal(t, []string{"v2", "v1"}, modules[1].violationIDs)
+
+		// svc-c is broken but untouched, so verification passes
+		require.NoError(t, vct.verifyTouchedModules())
+		stats := vct.GetStats()
+		assert.Equal(t, 2, stats.TotalVerifications)
+		assert.Equal(t, 2, stats.PassedVerifications)
+		assert.Equal(t, map[string]bool{"v1": true, "v2": true}, vct.VerificationResults())
+	})
+
+	t.Run("a touched module failing fails its violations", func(t *testing.T) {
+		vct := newTracker()
+		track(vct, "v1", "svc-a/lib.go")
+		track(vct, "v3", "svc-c/lib.go")
+
+		assert.Error(t, vct.verifyTouchedModules())
+		assert.Equal(t, map[string]bool{"v1": true, "v3": false}, vct.VerificationResults())
+	})
+
+	t.Run("custom command runs once from the root", func(t *testing.T) {
+		vct, err := NewVerifiedCommitTracker(StrategyAtEnd, root, "test-provider", verifier.Config{
+			Type:          verifier.VerificationBuild,
+			Strategy:      verifier.StrategyAtEnd,
+			WorkingDir:    root,
+			CustomCommand: "touch custom-verified",
+		})
+		require.NoError(t, err)
+		track(vct, "v1", "svc-a/lib.go")
+		track(vct, "v2", "svc-b/lib.go")
+
+		require.NoError(t, vct.verifyTouchedModules())
+		assert.Equal(t, 1, vct.GetStats().TotalVerifications)
+		assert.FileExists(t, filepath.Join(root, "custom-verified"))
+	})
+}
+
+func TestVerifiedCommitTracker_LanguageCommands(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "svc-java", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "svc-java", "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "svc-go"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "svc-go", "go.mod"), []byte("module example.com/svc\n"), 0644))
+
+	newTracker := func(strategy verifier.VerificationStrategy) *VerifiedCommitTracker {
+		vct, err := NewVerifiedCommitTracker(StrategyAtEnd, root, "test-provider", verifier.Config{
+			Type:          verifier.VerificationBuild,
+			Strategy:      strategy,
+			WorkingDir:    root,
+			CustomCommand: "touch custom-verified",
+			LanguageCommands: map[string]string{
+				"java": "touch java-verified",
+				"go":   "touch go-verified",
+			},
+		})
+		require.NoError(t, err)
+		return vct
+	}
+	track := func(vct *VerifiedCommitTracker, violationID, file string) {
+		v := violation.Violation{ID: violationID}
+		require.NoError(t, vct.TrackFix(v, violation.Incident{}, &fixer.FixResult{Success: true, FilePath: file}))
+	}
+
+	t.Run("each language runs its command in its module", func(t *testing.T) {
+		vct := newTracker(verifier.StrategyAtEnd)
+		track(vct, "v1", "svc-java/src/App.java")
+		track(vct, "v2", "svc-go/lib.go")
+		track(vct, "v3", "README.md")
+
+		modules := vct.touchedModules()
+		require.Len(t, modules, 3)
+		assert.Equal(t, moduleChanges{dir: "svc-java", language: "java", violationIDs: []string{"v1"}, files: []string{"svc-java/src/App.java"}}, modules[0])
+		assert.Equal(t, moduleChanges{dir: "svc-go", language: "go", violationIDs: []string{"v2"}, files: []string{"svc-go/lib.go"}}, modules[1])
+		assert.Equal(t, moduleChanges{dir: ".", violationIDs: []string{"v3"}, files: []string{"README.md"}}, modules[2])
+
+		require.NoError(t, vct.verifyTouchedModules())
+		assert.Equal(t, 3, vct.GetStats().TotalVerifications)
+		assert.FileExists(t, filepath.Join(root, "svc-java", "java-verified"))
+		assert.FileExists(t, filepath.Join(root, "svc-go", "go-verified"))
+		assert.FileExists(t, filepath.Join(root, "custom-verified"))
+	})
+
+	t.Run("per-fix verification uses the fixed file's language", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(root, "svc-go", "go-verified")))
+
+		vct := newTracker(verifier.StrategyPerFix)
+		track(vct, "v2", "svc-go/lib.go")
+
+		assert.Equal(t, 1, vct.GetStats().PassedVerifications)
+		assert.FileExists(t, filepath.Join(root, "svc-go", "go-verified"))
+	})
+}
+
+func TestVerifiedCommitTracker_AffectedScope(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "svc", "pkg", "auth"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "svc", "go.mod"), []byte("module example.com/svc\n"), 0644))
+
+	vct, err := NewVerifiedCommitTracker(StrategyAtEnd, root, "test-provider", verifier.Config{
+		Type:            verifier.VerificationTest,
+		Strategy:        verifier.StrategyAtEnd,
+		WorkingDir:      root,
+		Scope:           verifier.ScopeAffected,
+		AffectedCommand: "touch {{range .ChangedFiles}}{{.}}.verified {{end}}",
+	})
+	require.NoError(t, err)
+
+	v := violation.Violation{ID: "v1"}
+	require.NoError(t, vct.TrackFix(v, violation.Incident{}, &fixer.FixResult{Success: true, FilePath: "svc/pkg/auth/token.go"}))
+	require.NoError(t, vct.verifyTouchedModules())
+
+	// The command runs from the module, with files relative to it
+	assert.Equal(t, 1, vct.GetStats().PassedVerifications)
+	assert.FileExists(t, filepath.Join(root, "svc", "pkg", "auth", "token.go.verified"))
+}
+
+func TestVerifiedCommitTracker_PerFixFailureRestoresFile(t *testing.T) {
+	newTracker := func(t *testing.T, dir, command string, failFast bool) *VerifiedCommitTracker {
+		vct, err := NewVerifiedCommitTracker(StrategyAtEnd, dir, "test-provider", verifier.Config{
+			Type:          verifier.VerificationBuild,
+			Strategy:      verifier.StrategyPerFix,
+			WorkingDir:    dir,
+			CustomCommand: command,
+			FailFast:      failFast,
+		})
+		require.NoError(t, err)
+		return vct
+	}
+	v := violation.Violation{ID: "v1"}
+	incident := violation.Incident{URI: "file:///src/App.java"}
+
+	t.Run("fail-fast restores the original content", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "App.java")
+		require.NoError(t, os.WriteFile(file, []byte("broken"), 0644))
+
+		vct := newTracker(t, dir, "false", true)
+		err := vct.TrackFix(v, incident, &fixer.FixResult{FilePath: "App.java", OriginalContent: "original", Success: true})
+		require.Error(t, err)
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+		assert.Equal(t, 1, vct.GetStats().RevertedFixes)
+		assert.Empty(t, vct.changes)
+		assert.Empty(t, vct.commitTracker.allFixes)
+	})
+
+	t.Run("without fail-fast the fix is reverted and recorded", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "App.java")
+		require.NoError(t, os.WriteFile(file, []byte("broken"), 0644))
+
+		vct := newTracker(t, dir, "false", false)
+		err := vct.TrackFix(v, incident, &fixer.FixResult{FilePath: "App.java", OriginalContent: "original", Success: true})
+		assert.ErrorIs(t, err, ErrFixReverted)
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+		stats := vct.GetStats()
+		assert.Equal(t, 1, stats.RevertedFixes)
+		assert.Equal(t, 1, stats.SkippedFixes)
+		assert.Empty(t, vct.commitTracker.allFixes)
+
+		reverted := vct.RevertedIncidents()
+		require.Len(t, reverted, 1)
+		assert.Equal(t, "v1", reverted[0].ViolationID)
+		assert.Equal(t, incident, reverted[0].Incident)
+		assert.Equal(t, "App.java", reverted[0].FilePath)
+		assert.Equal(t, "false", reverted[0].Command)
+	})
+
+	t.Run("restores from HEAD without the original content", func(t *testing.T) {
+		repoDir := createTestGitRepo(t)
+		file := filepath.Join(repoDir, "App.java")
+		require.NoError(t, createAndCommitFile(t, repoDir, file, "committed"))
+		require.NoError(t, os.WriteFile(file, []byte("broken"), 0644))
+
+		vct := newTracker(t, repoDir, "false", true)
+		require.Error(t, vct.TrackFix(v, incident, &fixer.FixResult{FilePath: "App.java", Success: true}))
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "committed", string(content))
+		assert.Equal(t, 1, vct.GetStats().RevertedFixes)
+	})
+
+	t.Run("a passing fix is kept", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "App.java")
+		require.NoError(t, os.WriteFile(file, []byte("fixed"), 0644))
+
+		vct := newTracker(t, dir, "true", true)
+		require.NoError(t, vct.TrackFix(v, incident, &fixer.FixResult{FilePath: "App.java", OriginalContent: "original", Success: true}))
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "fixed", string(content))
+		assert.Equal(t, 0, vct.GetStats().RevertedFixes)
+		assert.Len(t, vct.changes, 1)
+		assert.Len(t, vct.commitTracker.allFixes, 1)
+	})
+}
+
+func TestVerifiedCommitTracker_CoverageRegression(t *testing.T) {
+	// The fixed file is the coverage report the verification command
+	// publishes, so a fix can lower the measured coverage
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.info")
+	original := "SF:app.js\nLF:10\nLH:8\nend_of_record\n"
+	require.NoError(t, os.WriteFile(file, []byte(original), 0644))
+
+	vct, err := NewVerifiedCommitTracker(StrategyAtEnd, dir, "test-provider", verifier.Config{
+		Type:           verifier.VerificationCoverage,
+		Strategy:       verifier.StrategyPerFix,
+		WorkingDir:     dir,
+		CustomCommand:  "cp app.info coverage.info",
+		CoverageReport: "coverage.info",
+		FailFast:       true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, vct.BaselineCoverage())
+	assert.Equal(t, 80.0, vct.BaselineCoverage().Percent())
+
+	require.NoError(t, os.WriteFile(file, []byte("SF:app.js\nLF:10\nLH:6\nend_of_record\n"), 0644))
+	v := violation.Violation{ID: "v1"}
+	err = vct.TrackFix(v, violation.Incident{}, &fixer.FixResult{FilePath: "app.info", OriginalContent: original, Success: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "coverage dropped from 80.0% (8/10) to 60.0% (6/10)")
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+	require.Len(t, vct.Failures(), 1)
+	assert.Equal(t, map[string]bool{"v1": false}, vct.VerificationResults())
+}
+
+func TestVerifiedCommitTracker_Failures(t *testing.T) {
+	tmpDir := t.TempDir()
+	var script strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&script, "echo line %d\n", i)
+	}
+	script.WriteString("echo 'compilation failed' >&2\nexit 1\n")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "build.sh"), []byte(script.String()), 0755))
+
+	vct, err := NewVerifiedCommitTracker(StrategyAtEnd, tmpDir, "test-provider", verifier.Config{
+		Type:          verifier.VerificationBuild,
+		Strategy:      verifier.StrategyAtEnd,
+		WorkingDir:    tmpDir,
+		CustomCommand: "sh build.sh",
+		FailFast:      true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, vct.Failures())
+
+	err = vct.runVerification([]string{"v1", "v2"})
+	require.Error(t, err)
+
+	failures := vct.Failures()
+	require.Len(t, failures, 1)
+	failure := failures[0]
+	assert.Equal(t, []string{"v1", "v2"}, failure.ViolationIDs)
+	assert.Equal(t, "sh build.sh", failure.Command)
+	assert.Contains(t, failure.Error, "exit status 1")
+	assert.Contains(t, failure.Output, "line 1\n")
+	assert.Contains(t, failure.Output, "compilation failed")
+
+	// The error and console show only the end of the output
+	tail := failure.OutputTail(FailureOutputLines)
+	assert.True(t, strings.HasPrefix(tail, "...\nline 12\n"))
+	assert.True(t, strings.HasSuffix(tail, "line 30\ncompilation failed"))
+	assert.Contains(t, err.Error(), tail)
+	assert.NotContains(t, err.Error(), "line 11\n")
+}
+
+func TestVerificationFailure_OutputTail(t *testing.T) {
+	failure := VerificationFailure{Output: "a\nb\nc\n"}
+	assert.Equal(t, "a\nb\nc", failure.OutputTail(3))
+	assert.Equal(t, "...\nb\nc", failure.OutputTail(2))
+	assert.Equal(t, "", VerificationFailure{}.OutputTail(5))
+}
+
+func TestUndoFix(t *testing.T) {
+	t.Run("restores the content before the fix", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "App.java")
+		require.NoError(t, os.WriteFile(path, []byte("a\nB\nc\n"), 0644))
+
+		err := UndoFix(tmpDir, &fixer.FixResult{FilePath: "App.java", OriginalContent: "a\nb\nc\n", FixedContent: "a\nB\nc\n"})
+		require.NoError(t, err)
+		content, _ := os.ReadFile(path)
+		assert.Equal(t, "a\nb\nc\n", string(content))
+	})
+
+	t.Run("keeps later changes to other lines", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "App.java")
+		require.NoError(t, os.WriteFile(path, []byte("a\nB\nc\nD\n"), 0644))
+
+		err := UndoFix(tmpDir, &fixer.FixResult{FilePath: "App.java", OriginalContent: "a\nb\nc\nd\n", FixedContent: "a\nB\nc\nd\n"})
+		require.NoError(t, err)
+		content, _ := os.ReadFile(path)
+		assert.Equal(t, "a\nb\nc\nD\n", string(content))
+	})
+
+	t.Run("unknown original content outside git", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "App.java"), []byte("fixed"), 0644))
+
+		err := UndoFix(tmpDir, &fixer.FixResult{FilePath: "App.java"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+// Package logging configures the structured logger for diagnostic output.
+//
+// Diagnostics (retries, fallbacks, server errors and, at debug level, the raw
+// provider traffic) go through log/slog to stderr, so they can be filtered by
+// level and captured in CI. The pretty progress output of package ux stays on
+// stdout.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/redact"
+)
+
+// DefaultLevel is the log level unless configured
+const DefaultLevel = slog.LevelInfo
+
+// ParseLevel parses a log level: debug, info, warn or error ("" is the default)
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return DefaultLevel, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s (expected debug, info, warn or error)", s)
+	}
+}
+
+// New returns a logger writing text records at level and above to w. If
+// redactor is non-nil, string and error attributes are redacted before they
+// are written, which keeps secrets in logged prompts and responses out of
+// the log.
+func New(w io.Writer, level slog.Level, redactor *redact.Redactor) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if redactor != nil {
+		options.ReplaceAttr = func(_ []string, a slog.Attr) slog.Attr {
+			switch a.Value.Kind() {
+			case slog.KindString:
+				a.Value = slog.StringValue(redactor.Redact(a.Value.String()))
+			case slog.KindAny:
+				if err, ok := a.Value.Any().(error); ok {
+					a.Value = slog.StringValue(redactor.Redact(err.Error()))
+				}
+			}
+			return a
+		}
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/redact"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	for s, want := range tests {
+		level, err := ParseLevel(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, level, s)
+	}
+
+	_, err := ParseLevel("trace")
+	assert.ErrorContains(t, err, "expected debug, info, warn or error")
+}
+
+func TestNew(t *testing.T) {
+	t.Run("filters by level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(&buf, slog.LevelWarn, nil)
+		logger.Info("hidden")
+		logger.Warn("rate limit hit", "retry", 1)
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), `level=WARN msg="rate limit hit" retry=1`)
+	})
+
+	t.Run("redacts strings and errors", func(t *testing.T) {
+		redactor, err := redact.New(redact.Config{})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		logger := New(&buf, slog.LevelDebug, redactor)
+		logger.Debug("provider request", "body", "String apiKey = \"x\"; // api_key=abc123", "error", errors.New("bad token sk-ant-abcdefghijklmnop"))
+		assert.NotContains(t, buf.String(), "abc123")
+		assert.NotContains(t, buf.String(), "sk-ant-")
+		assert.Contains(t, buf.String(), redact.Placeholder)
+	})
+
+	t.Run("no redactor logs bodies as is", func(t *testing.T) {
+		var buf bytes.Buffer
+		New(&buf, slog.LevelDebug, nil).Debug("provider request", "body", "api_key=abc123")
+		assert.Contains(t, buf.String(), "api_key=abc123")
+	})
+}
@@ -0,0 +1,182 @@
+// Package notify posts a summary of a finished run to a webhook, so long runs
+// can ping a chat channel or automation when they complete or fail.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/report"
+	"github.com/tsanders/kantra-ai/pkg/version"
+)
+
+// Timeout bounds a webhook request, so a slow endpoint can't hold up the
+// end of a run
+const Timeout = 10 * time.Second
+
+// Format selects the webhook payload
+type Format string
+
+const (
+	// FormatJSON posts the Notification as JSON (default)
+	FormatJSON Format = "json"
+	// FormatSlack posts a Slack-compatible {"text": ...} message
+	FormatSlack Format = "slack"
+)
+
+// ParseFormat parses a payload format ("" defaults to json)
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatSlack:
+		return FormatSlack, nil
+	default:
+		return "", fmt.Errorf("invalid notification format: %s (expected json or slack)", s)
+	}
+}
+
+// Status is the outcome of a run
+type Status string
+
+const (
+	// StatusSucceeded is a run that finished, even if some fixes failed
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is a run that stopped with an error
+	StatusFailed Status = "failed"
+)
+
+// Notification summarizes a finished run
+type Notification struct {
+	Command         string   `json:"command"`
+	Status          Status   `json:"status"`
+	Error           string   `json:"error,omitempty"` // Set when Status is failed
+	DryRun          bool     `json:"dry_run"`
+	SuccessfulFixes int      `json:"successful_fixes"`
+	FailedFixes     int      `json:"failed_fixes"`
+	SkippedFixes    int      `json:"skipped_fixes"`
+	TotalCost       float64  `json:"total_cost"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	PRURLs          []string `json:"pr_urls"`
+}
+
+// Build returns the notification for a run of command that took duration
+// and ended with runErr. summary is nil if the run stopped before it was
+// summarized, leaving the counts and cost at zero. DryRun is left to the
+// caller, as a run can stop before its summary records it.
+func Build(command string, summary *report.Summary, prURLs []string, duration time.Duration, runErr error) Notification {
+	n := Notification{
+		Command:         command,
+		Status:          StatusSucceeded,
+		DurationSeconds: duration.Seconds(),
+		PRURLs:          prURLs,
+	}
+	if runErr != nil {
+		n.Status = StatusFailed
+		n.Error = runErr.Error()
+	}
+	if summary != nil {
+		n.SuccessfulFixes = summary.SuccessfulFixes
+		n.FailedFixes = summary.FailedFixes
+		n.SkippedFixes = summary.SkippedFixes
+		n.TotalCost = summary.TotalCost
+	}
+	return n
+}
+
+// Duration returns the run's duration, rounded to the second
+func (n Notification) Duration() time.Duration {
+	return time.Duration(n.DurationSeconds * float64(time.Second)).Round(time.Second)
+}
+
+// slackTemplate renders a Notification as a Slack message; json quotes a
+// value as a JSON string
+var slackTemplate = template.Must(template.New("slack").Funcs(template.FuncMap{
+	"json": func(s string) (string, error) {
+		quoted, err := json.Marshal(s)
+		return string(quoted), err
+	},
+}).Parse(`{"text": {{json .Text}}}`))
+
+// slackText is the text of the Slack message for a Notification
+func slackText(n Notification) string {
+	var b strings.Builder
+	if n.Status == StatusFailed {
+		// Long errors end in remediation advice; the first line says what failed
+		fmt.Fprintf(&b, ":x: kantra-ai %s failed after %s: %s\n", n.Command, n.Duration(), strings.SplitN(n.Error, "\n", 2)[0])
+	} else {
+		fmt.Fprintf(&b, ":white_check_mark: kantra-ai %s finished in %s\n", n.Command, n.Duration())
+	}
+	if n.DryRun {
+		b.WriteString("(dry run, no changes made)\n")
+	}
+	fmt.Fprintf(&b, "Fixes: %d succeeded, %d failed, %d skipped | Cost: $%.2f", n.SuccessfulFixes, n.FailedFixes, n.SkippedFixes, n.TotalCost)
+	for _, url := range n.PRURLs {
+		fmt.Fprintf(&b, "\nPR: %s", url)
+	}
+	return b.String()
+}
+
+// Payload returns the request body for a Notification in format
+func Payload(n Notification, format Format) ([]byte, error) {
+	if n.PRURLs == nil {
+		n.PRURLs = []string{}
+	}
+	if format != FormatSlack {
+		return json.Marshal(n)
+	}
+	var buf bytes.Buffer
+	if err := slackTemplate.Execute(&buf, struct{ Text string }{slackText(n)}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Webhook posts notifications to a URL
+type Webhook struct {
+	URL       string
+	Format    Format
+	UserAgent string // Empty = kantra-ai/<version>
+	Client    *http.Client
+}
+
+// NewWebhook returns a webhook posting to url in format
+func NewWebhook(url string, format Format, userAgent string) *Webhook {
+	return &Webhook{URL: url, Format: format, UserAgent: userAgent, Client: &http.Client{Timeout: Timeout}}
+}
+
+// Send posts n, failing on a non-2xx response
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	body, err := Payload(n, w.Format)
+	if err != nil {
+		return fmt.Errorf("failed to build notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	userAgent := w.UserAgent
+	if userAgent == "" {
+		userAgent = version.UserAgent()
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
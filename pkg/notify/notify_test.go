@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/report"
+	"github.com/tsanders/kantra-ai/pkg/version"
+)
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	format, err = ParseFormat("slack")
+	require.NoError(t, err)
+	assert.Equal(t, FormatSlack, format)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorContains(t, err, "expected json or slack")
+}
+
+func TestBuild(t *testing.T) {
+	summary := &report.Summary{SuccessfulFixes: 12, FailedFixes: 2, SkippedFixes: 1, TotalCost: 1.234}
+
+	n := Build("execute", summary, []string{"https://github.com/o/r/pull/7"}, 90*time.Second, nil)
+	assert.Equal(t, Notification{
+		Command:         "execute",
+		Status:          StatusSucceeded,
+		SuccessfulFixes: 12,
+		FailedFixes:     2,
+		SkippedFixes:    1,
+		TotalCost:       1.234,
+		DurationSeconds: 90,
+		PRURLs:          []string{"https://github.com/o/r/pull/7"},
+	}, n)
+
+	t.Run("failed before the summary", func(t *testing.T) {
+		n := Build("remediate", nil, nil, time.Second, errors.New("failed to load analysis"))
+		assert.Equal(t, StatusFailed, n.Status)
+		assert.Equal(t, "failed to load analysis", n.Error)
+		assert.Zero(t, n.SuccessfulFixes)
+	})
+}
+
+func TestPayload(t *testing.T) {
+	n := Notification{
+		Command:         "remediate",
+		Status:          StatusSucceeded,
+		SuccessfulFixes: 3,
+		FailedFixes:     1,
+		TotalCost:       0.456,
+		DurationSeconds: 125.4,
+		PRURLs:          []string{"https://github.com/o/r/pull/1", "https://github.com/o/r/pull/2"},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		body, err := Payload(Notification{Command: "execute", Status: StatusSucceeded}, FormatJSON)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &decoded))
+		assert.Equal(t, "succeeded", decoded["status"])
+		assert.Equal(t, []interface{}{}, decoded["pr_urls"], "no PRs is an empty list, not null")
+		assert.NotContains(t, decoded, "error")
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		body, err := Payload(n, FormatSlack)
+		require.NoError(t, err)
+		var decoded map[string]string
+		require.NoError(t, json.Unmarshal(body, &decoded))
+		assert.Equal(t, ":white_check_mark: kantra-ai remediate finished in 2m5s\n"+
+			"Fixes: 3 succeeded, 1 failed, 0 skipped | Cost: $0.46\n"+
+			"PR: https://github.com/o/r/pull/1\n"+
+			"PR: https://github.com/o/r/pull/2", decoded["text"])
+	})
+
+	t.Run("slack failure with quotes in the error", func(t *testing.T) {
+		failed := Notification{Command: "execute", Status: StatusFailed, DryRun: true,
+			Error: "file \"App.java\" not found\n\nPlease verify:\n  1. the path"}
+		body, err := Payload(failed, FormatSlack)
+		require.NoError(t, err)
+		var decoded map[string]string
+		require.NoError(t, json.Unmarshal(body, &decoded))
+		assert.Equal(t, ":x: kantra-ai execute failed after 0s: file \"App.java\" not found\n"+
+			"(dry run, no changes made)\n"+
+			"Fixes: 0 succeeded, 0 failed, 0 skipped | Cost: $0.00", decoded["text"])
+	})
+}
+
+func TestWebhook_Send(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		gotHeader = r.Header
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("invalid_payload"))
+	}))
+	defer server.Close()
+
+	n := Notification{Command: "execute", Status: StatusSucceeded, SuccessfulFixes: 5}
+	require.NoError(t, NewWebhook(server.URL, FormatJSON, "").Send(context.Background(), n))
+	assert.Equal(t, "application/json", gotHeader.Get("Content-Type"))
+	assert.Equal(t, version.UserAgent(), gotHeader.Get("User-Agent"))
+	assert.Contains(t, string(gotBody), `"successful_fixes":5`)
+
+	t.Run("custom User-Agent", func(t *testing.T) {
+		require.NoError(t, NewWebhook(server.URL, FormatSlack, "acme-ci/1.0").Send(context.Background(), n))
+		assert.Equal(t, "acme-ci/1.0", gotHeader.Get("User-Agent"))
+		assert.Contains(t, string(gotBody), `"text"`)
+	})
+
+	t.Run("non-2xx response", func(t *testing.T) {
+		status = http.StatusBadRequest
+		err := NewWebhook(server.URL, FormatSlack, "").Send(context.Background(), n)
+		assert.ErrorContains(t, err, "webhook returned 400 Bad Request: invalid_payload")
+	})
+}
@@ -0,0 +1,232 @@
+package planfile
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tsanders/kantra-ai/pkg/violation"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of a plan problem
+const (
+	ProblemError   = "error"   // execute would fail or silently skip fixes
+	ProblemWarning = "warning" // probably a mistake, but the plan can be executed
+)
+
+// PlanProblem is a problem found in a plan file by CheckPlan
+type PlanProblem struct {
+	Line     int    // Line in the plan file (0 = unknown)
+	Path     string // Where in the plan, e.g. phases[1].violations[0].violation_id
+	Severity string // ProblemError or ProblemWarning
+	Message  string
+}
+
+// validCategories are the violation categories of Konveyor analyses
+var validCategories = map[string]bool{"mandatory": true, "optional": true, "potential": true}
+
+// CheckPlan checks a plan file, e.g. after editing it by hand, against the
+// analysis it was generated from. Unlike ValidatePlan it reports every
+// problem with its line, and also checks that every violation ID exists in
+// the analysis, phase orders are unique and every incident's file resolves:
+// resolveFile returns an error for a file path that doesn't (nil skips the
+// check).
+func CheckPlan(data []byte, analysis *violation.Analysis, resolveFile func(path string) error) []PlanProblem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []PlanProblem{{Severity: ProblemError, Message: fmt.Sprintf("invalid YAML: %v", err)}}
+	}
+	var plan Plan
+	if err := root.Decode(&plan); err != nil {
+		return []PlanProblem{{Severity: ProblemError, Message: fmt.Sprintf("invalid plan: %v", err)}}
+	}
+
+	c := &planChecker{root: &root}
+
+	if plan.Version != PlanVersion {
+		c.add(ProblemError, []interface{}{"version"}, "unsupported plan version %q (expected %s)", plan.Version, PlanVersion)
+	}
+	if plan.Metadata.Provider == "" {
+		c.add(ProblemError, []interface{}{"metadata", "provider"}, "provider is required")
+	}
+	if len(plan.Phases) == 0 {
+		c.add(ProblemError, []interface{}{"phases"}, "plan must have at least one phase")
+	}
+
+	analysisViolations := make(map[string]bool)
+	if analysis != nil {
+		for _, v := range analysis.Violations {
+			analysisViolations[v.ID] = true
+		}
+	}
+
+	phaseIDs := make(map[string]int)           // Phase ID -> index of first phase
+	orders := make(map[int]int)                // Order -> index of first phase
+	violationPhases := make(map[string]string) // Violation ID -> first phase ID
+	for i, phase := range plan.Phases {
+		at := func(path ...interface{}) []interface{} {
+			return append([]interface{}{"phases", i}, path...)
+		}
+
+		switch first, dup := phaseIDs[phase.ID]; {
+		case phase.ID == "":
+			c.add(ProblemError, at("id"), "phase ID is required")
+		case dup:
+			c.add(ProblemError, at("id"), "duplicate phase ID %q (also phases[%d])", phase.ID, first)
+		default:
+			phaseIDs[phase.ID] = i
+		}
+		if phase.Name == "" {
+			c.add(ProblemError, at("name"), "phase name is required")
+		}
+		if first, dup := orders[phase.Order]; dup {
+			c.add(ProblemError, at("order"), "duplicate order %d (also phases[%d])", phase.Order, first)
+		} else {
+			orders[phase.Order] = i
+		}
+		if phase.Order < 0 {
+			c.add(ProblemError, at("order"), "order must be non-negative")
+		}
+		if !isValidRiskLevel(phase.Risk) {
+			c.add(ProblemError, at("risk"), "invalid risk level %q (must be low, medium, or high)", phase.Risk)
+		}
+		switch {
+		case phase.Category == "":
+			c.add(ProblemError, at("category"), "phase category is required")
+		case !validCategories[phase.Category]:
+			c.add(ProblemWarning, at("category"), "unknown category %q (expected mandatory, optional, or potential)", phase.Category)
+		}
+		if phase.EstimatedCost < 0 {
+			c.add(ProblemError, at("estimated_cost"), "estimated cost must be non-negative")
+		}
+		if len(phase.Violations) == 0 {
+			c.add(ProblemError, at("violations"), "phase must have at least one violation")
+		}
+
+		for j, v := range phase.Violations {
+			vAt := func(path ...interface{}) []interface{} {
+				return at(append([]interface{}{"violations", j}, path...)...)
+			}
+
+			switch {
+			case v.ViolationID == "":
+				c.add(ProblemError, vAt("violation_id"), "violation ID is required")
+			case analysis != nil && !analysisViolations[v.ViolationID]:
+				c.add(ProblemError, vAt("violation_id"), "violation %q is not in the analysis; its fixes would be skipped", v.ViolationID)
+			}
+			if v.ViolationID != "" {
+				if firstPhase, dup := violationPhases[v.ViolationID]; dup {
+					c.add(ProblemWarning, vAt("violation_id"), "violation %q is also in phase %s", v.ViolationID, firstPhase)
+				} else {
+					violationPhases[v.ViolationID] = phase.ID
+				}
+			}
+			if v.Description == "" {
+				c.add(ProblemError, vAt("description"), "description is required")
+			}
+			if v.IncidentCount < 0 {
+				c.add(ProblemError, vAt("incident_count"), "incident count must be non-negative")
+			} else if len(v.Incidents) > 0 && v.IncidentCount != len(v.Incidents) {
+				c.add(ProblemError, vAt("incident_count"), "incident count mismatch: count=%d, incidents=%d", v.IncidentCount, len(v.Incidents))
+			}
+
+			if resolveFile == nil {
+				continue
+			}
+			for k, incident := range v.Incidents {
+				if incident.URI == "" {
+					c.add(ProblemError, vAt("incidents", k), "incident has no URI")
+					continue
+				}
+				if err := resolveFile(incident.GetFilePath()); err != nil {
+					c.add(ProblemError, vAt("incidents", k, "uri"), "%s: %v", incident.URI, err)
+				}
+			}
+		}
+	}
+
+	// Anything else execute would refuse
+	if !c.hasErrors() {
+		if err := ValidatePlan(&plan); err != nil {
+			c.add(ProblemError, nil, "%v", err)
+		}
+	}
+	return c.problems
+}
+
+// planChecker collects problems with the line of the YAML node they're about
+type planChecker struct {
+	root     *yaml.Node
+	problems []PlanProblem
+}
+
+func (c *planChecker) add(severity string, path []interface{}, format string, args ...interface{}) {
+	c.problems = append(c.problems, PlanProblem{
+		Line:     c.line(path),
+		Path:     formatPlanPath(path),
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *planChecker) hasErrors() bool {
+	for _, p := range c.problems {
+		if p.Severity == ProblemError {
+			return true
+		}
+	}
+	return false
+}
+
+// line returns the line of the node at path, of map keys (string) and
+// sequence indexes (int), or of its closest existing ancestor
+func (c *planChecker) line(path []interface{}) int {
+	node := c.root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, step := range path {
+		next := childNode(node, step)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node.Line
+}
+
+func childNode(node *yaml.Node, step interface{}) *yaml.Node {
+	switch step := step.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == step {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && step < len(node.Content) {
+			return node.Content[step]
+		}
+	}
+	return nil
+}
+
+// formatPlanPath formats a path as phases[1].violations[0].violation_id
+func formatPlanPath(path []interface{}) string {
+	s := ""
+	for _, step := range path {
+		switch step := step.(type) {
+		case string:
+			if s != "" {
+				s += "."
+			}
+			s += step
+		case int:
+			s += "[" + strconv.Itoa(step) + "]"
+		}
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package planfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+const checkedPlan = `version: "1.0"
+metadata:
+  provider: claude
+phases:
+  - id: phase-1
+    name: Jakarta imports
+    order: 1
+    risk: low
+    category: mandatory
+    violations:
+      - violation_id: javax-to-jakarta
+        description: Replace javax imports
+        incident_count: 1
+        incidents:
+          - uri: file:///src/A.java
+            lineNumber: 3
+  - id: phase-2
+    name: Config
+    order: 1
+    risk: severe
+    category: cleanup
+    violations:
+      - violation_id: javax-to-jakrta
+        description: Typo
+        incident_count: 1
+        incidents:
+          - uri: file:///src/Missing.java
+            lineNumber: 7
+`
+
+func TestCheckPlan(t *testing.T) {
+	analysis := &violation.Analysis{Violations: []violation.Violation{{ID: "javax-to-jakarta"}}}
+	resolveFile := func(path string) error {
+		if strings.HasSuffix(path, "Missing.java") {
+			return errors.New("file not found")
+		}
+		return nil
+	}
+
+	problems := CheckPlan([]byte(checkedPlan), analysis, resolveFile)
+
+	byPath := make(map[string]PlanProblem)
+	for _, p := range problems {
+		byPath[p.Path] = p
+	}
+	require.Len(t, problems, 5, "%v", problems)
+
+	order := byPath["phases[1].order"]
+	assert.Equal(t, ProblemError, order.Severity)
+	assert.Equal(t, 19, order.Line)
+	assert.Contains(t, order.Message, "duplicate order 1")
+
+	assert.Equal(t, ProblemError, byPath["phases[1].risk"].Severity)
+	assert.Equal(t, 20, byPath["phases[1].risk"].Line)
+	assert.Equal(t, ProblemWarning, byPath["phases[1].category"].Severity)
+
+	id := byPath["phases[1].violations[0].violation_id"]
+	assert.Equal(t, ProblemError, id.Severity)
+	assert.Equal(t, 23, id.Line)
+	assert.Contains(t, id.Message, `"javax-to-jakrta" is not in the analysis`)
+
+	uri := byPath["phases[1].violations[0].incidents[0].uri"]
+	assert.Equal(t, 27, uri.Line)
+	assert.Contains(t, uri.Message, "file not found")
+}
+
+func TestCheckPlan_Valid(t *testing.T) {
+	analysis := &violation.Analysis{Violations: []violation.Violation{{ID: "javax-to-jakarta"}}}
+	valid := checkedPlan[:strings.Index(checkedPlan, "  - id: phase-2")]
+
+	assert.Empty(t, CheckPlan([]byte(valid), analysis, func(string) error { return nil }))
+}
+
+func TestCheckPlan_InvalidYAML(t *testing.T) {
+	problems := CheckPlan([]byte("phases: [\n"), nil, nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, ProblemError, problems[0].Severity)
+	assert.Contains(t, problems[0].Message, "invalid YAML")
+}
@@ -1,8 +1,12 @@
 package planfile
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -42,6 +46,9 @@ func SavePlan(plan *Plan, path string) error {
 		return fmt.Errorf("failed to marshal plan: %w", err)
 	}
 
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write plan file: %w", err)
 	}
@@ -49,6 +56,15 @@ func SavePlan(plan *Plan, path string) error {
 	return nil
 }
 
+// ensureParentDir creates the directory a file is written to, so configured
+// locations like .kantra-ai/plan.yaml work without creating it first
+func ensureParentDir(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	return nil
+}
+
 // NewPlan creates a new migration plan with default values and empty phases list.
 func NewPlan(provider string, totalViolations int) *Plan {
 	return &Plan{
@@ -75,6 +91,87 @@ func (p *Plan) GetPhaseByID(phaseID string) (*Phase, error) {
 	return nil, fmt.Errorf("phase not found: %s", phaseID)
 }
 
+// MovePhase moves a phase to the given 1-based position and renumbers the
+// Order of all phases to match their new positions.
+func (p *Plan) MovePhase(phaseID string, position int) error {
+	if position < 1 || position > len(p.Phases) {
+		return fmt.Errorf("invalid position %d: must be between 1 and %d", position, len(p.Phases))
+	}
+
+	index := -1
+	for i := range p.Phases {
+		if p.Phases[i].ID == phaseID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("phase not found: %s", phaseID)
+	}
+
+	phase := p.Phases[index]
+	phases := append(p.Phases[:index:index], p.Phases[index+1:]...)
+	phases = append(phases[:position-1], append([]Phase{phase}, phases[position-1:]...)...)
+	p.Phases = phases
+	p.renumberPhases()
+	return nil
+}
+
+// ReorderPhases puts the phases in the order given by phaseIDs, which must
+// list every phase exactly once, and renumbers their Order.
+func (p *Plan) ReorderPhases(phaseIDs []string) error {
+	if len(phaseIDs) != len(p.Phases) {
+		return fmt.Errorf("expected %d phase IDs, got %d", len(p.Phases), len(phaseIDs))
+	}
+
+	byID := make(map[string]Phase, len(p.Phases))
+	for _, phase := range p.Phases {
+		byID[phase.ID] = phase
+	}
+
+	phases := make([]Phase, 0, len(phaseIDs))
+	for _, id := range phaseIDs {
+		phase, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("phase not found or listed twice: %s", id)
+		}
+		delete(byID, id)
+		phases = append(phases, phase)
+	}
+
+	p.Phases = phases
+	p.renumberPhases()
+	return nil
+}
+
+// renumberPhases sets each phase's Order to its 1-based position
+func (p *Plan) renumberPhases() {
+	for i := range p.Phases {
+		p.Phases[i].Order = i + 1
+	}
+}
+
+// Hash returns a fingerprint of the plan's violations and incidents. It
+// changes whenever violations or incidents are added, removed or edited.
+// Review and ordering metadata (deferring or reordering phases, their risks
+// and estimates) and plan metadata such as the creation time are not
+// included, so reviewing a plan doesn't block resuming its execution.
+func (p *Plan) Hash() (string, error) {
+	var violations []PlannedViolation
+	for _, phase := range p.Phases {
+		violations = append(violations, phase.Violations...)
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].ViolationID < violations[j].ViolationID
+	})
+	data, err := yaml.Marshal(violations)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash plan: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // GetActivePhases returns phases that are not deferred
 func (p *Plan) GetActivePhases() []Phase {
 	active := make([]Phase, 0)
@@ -194,3 +194,117 @@ func TestGetTotalCost(t *testing.T) {
 	total := plan.GetTotalCost()
 	assert.Equal(t, 2.0, total)
 }
+
+func TestPlanHash(t *testing.T) {
+	plan := NewPlan("claude", 1)
+	plan.Phases = []Phase{{
+		ID:   "phase-1",
+		Name: "Phase 1",
+		Violations: []PlannedViolation{{
+			ViolationID: "v1",
+			Incidents:   []violation.Incident{{URI: "file:///a.java", LineNumber: 1}},
+		}},
+	}}
+
+	hash, err := plan.Hash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	// Metadata changes don't affect the hash
+	plan.Metadata.CreatedAt = plan.Metadata.CreatedAt.Add(time.Hour)
+	same, err := plan.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, same)
+
+	// Neither do review and ordering changes
+	plan.Phases = append(plan.Phases, Phase{ID: "phase-2", Violations: []PlannedViolation{{ViolationID: "v0"}}})
+	hash, err = plan.Hash()
+	require.NoError(t, err)
+	plan.Phases[0].Deferred = true
+	plan.Phases[0].Risk = RiskHigh
+	plan.Phases[0].EstimatedCost = 1.5
+	require.NoError(t, plan.MovePhase("phase-2", 1))
+	same, err = plan.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, same)
+
+	// Editing the violations does
+	plan.Phases[1].Violations[0].Incidents = append(plan.Phases[1].Violations[0].Incidents,
+		violation.Incident{URI: "file:///a.java", LineNumber: 2})
+	changed, err := plan.Hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+}
+
+func TestPlanMovePhase(t *testing.T) {
+	newPlan := func() *Plan {
+		plan := NewPlan("claude", 3)
+		plan.Phases = []Phase{{ID: "a", Order: 1}, {ID: "b", Order: 2}, {ID: "c", Order: 3}}
+		return plan
+	}
+	ids := func(plan *Plan) []string {
+		var result []string
+		for _, phase := range plan.Phases {
+			result = append(result, phase.ID)
+		}
+		return result
+	}
+
+	plan := newPlan()
+	require.NoError(t, plan.MovePhase("c", 1))
+	assert.Equal(t, []string{"c", "a", "b"}, ids(plan))
+	assert.Equal(t, []int{1, 2, 3}, []int{plan.Phases[0].Order, plan.Phases[1].Order, plan.Phases[2].Order})
+
+	plan = newPlan()
+	require.NoError(t, plan.MovePhase("a", 3))
+	assert.Equal(t, []string{"b", "c", "a"}, ids(plan))
+	assert.Equal(t, 3, plan.Phases[2].Order)
+
+	plan = newPlan()
+	require.NoError(t, plan.MovePhase("b", 2))
+	assert.Equal(t, []string{"a", "b", "c"}, ids(plan))
+
+	assert.Error(t, newPlan().MovePhase("a", 0))
+	assert.Error(t, newPlan().MovePhase("a", 4))
+	assert.Error(t, newPlan().MovePhase("missing", 1))
+}
+
+func TestPlanReorderPhases(t *testing.T) {
+	plan := NewPlan("claude", 3)
+	plan.Phases = []Phase{{ID: "a", Order: 1}, {ID: "b", Order: 2}, {ID: "c", Order: 3}}
+
+	require.NoError(t, plan.ReorderPhases([]string{"b", "c", "a"}))
+	assert.Equal(t, "b", plan.Phases[0].ID)
+	assert.Equal(t, 1, plan.Phases[0].Order)
+	assert.Equal(t, "a", plan.Phases[2].ID)
+	assert.Equal(t, 3, plan.Phases[2].Order)
+
+	assert.Error(t, plan.ReorderPhases([]string{"a", "b"}), "every phase must be listed")
+	assert.Error(t, plan.ReorderPhases([]string{"a", "a", "b"}), "duplicates are rejected")
+	assert.Error(t, plan.ReorderPhases([]string{"a", "b", "x"}), "unknown phases are rejected")
+	assert.Equal(t, "b", plan.Phases[0].ID, "plan is unchanged after an error")
+}
+
+func TestSavePlan_CreatesParentDirectories(t *testing.T) {
+	planPath := filepath.Join(t.TempDir(), ".kantra-ai", "plan.yaml")
+
+	plan := NewPlan("claude", 0)
+	plan.Phases = []Phase{{
+		ID:       "phase-1",
+		Name:     "Phase",
+		Order:    1,
+		Risk:     RiskLow,
+		Category: "mandatory",
+		Violations: []PlannedViolation{{
+			ViolationID:   "v1",
+			Description:   "Test violation",
+			Category:      "mandatory",
+			Effort:        1,
+			IncidentCount: 1,
+			Incidents:     []violation.Incident{{URI: "file:///test.java", LineNumber: 10}},
+		}},
+	}}
+	require.NoError(t, SavePlan(plan, planPath))
+
+	assert.FileExists(t, planPath)
+}
@@ -0,0 +1,85 @@
+package planfile
+
+import (
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// RemediateProgress records the remediate command's outcome for each incident
+// in a state file as it happens, so a run that stops midway can be resumed
+// instead of paying to fix everything again. A nil RemediateProgress records
+// nothing and resumes nothing.
+type RemediateProgress struct {
+	state   *ExecutionState
+	path    string
+	resume  bool // Skip the incidents fixed by a previous run
+	dryRun  bool // Read the state file but never write it
+	resumed bool // A previous run's state file was loaded
+}
+
+// LoadRemediateProgress returns the progress to record to path for the
+// analysis file. With resume, the state file at path (if any) is loaded and
+// the incidents it records as fixed are skipped; otherwise recording starts
+// afresh. In dry-run, nothing is written.
+func LoadRemediateProgress(path, analysisPath string, resume, dryRun bool) (*RemediateProgress, error) {
+	p := &RemediateProgress{path: path, resume: resume, dryRun: dryRun}
+	if resume {
+		state, err := LoadState(path)
+		if err != nil {
+			return nil, err
+		}
+		p.state = state
+		p.resumed = state != nil
+	}
+	if p.state == nil {
+		p.state = NewState(analysisPath, 0)
+	}
+	return p, nil
+}
+
+// Resumed reports whether a previous run's state file was loaded
+func (p *RemediateProgress) Resumed() bool {
+	return p != nil && p.resumed
+}
+
+// Done reports whether the incident is to be skipped because a previous run
+// fixed it
+func (p *RemediateProgress) Done(violationID string, incident violation.Incident) bool {
+	return p != nil && p.resume && p.state.IsIncidentCompleted(violationID, IncidentKey(incident))
+}
+
+// RecordFix records an applied fix and writes the state file
+func (p *RemediateProgress) RecordFix(violationID string, incident violation.Incident, cost float64) error {
+	if p == nil {
+		return nil
+	}
+	p.state.RecordIncidentFix(violationID, IncidentKey(incident), cost)
+	return p.save()
+}
+
+// RecordSkip records a fix skipped for reason, e.g. for low confidence or
+// during interactive review, and writes the state file. Skipped incidents
+// are attempted again on resume.
+func (p *RemediateProgress) RecordSkip(violationID string, incident violation.Incident, reason string) error {
+	if p == nil {
+		return nil
+	}
+	p.state.RecordIncidentSkip(violationID, IncidentKey(incident), reason)
+	return p.save()
+}
+
+// RecordFailure records a failed fix and writes the state file
+func (p *RemediateProgress) RecordFailure(violationID string, incident violation.Incident, errorMsg string) error {
+	if p == nil {
+		return nil
+	}
+	p.state.RecordIncidentFailure("", violationID, IncidentKey(incident), errorMsg)
+	return p.save()
+}
+
+// save writes the state file, except in dry-run
+func (p *RemediateProgress) save() error {
+	if p.dryRun {
+		return nil
+	}
+	return SaveState(p.state, p.path)
+}
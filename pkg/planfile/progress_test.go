@@ -0,0 +1,87 @@
+package planfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestRemediateProgress(t *testing.T) {
+	fixed := violation.Incident{URI: "file:///src/A.java", LineNumber: 3}
+	skipped := violation.Incident{URI: "file:///src/A.java", LineNumber: 9}
+	failed := violation.Incident{URI: "file:///src/B.java", LineNumber: 1}
+
+	t.Run("records each outcome as it happens", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.yaml")
+		progress, err := LoadRemediateProgress(path, "output.yaml", false, false)
+		require.NoError(t, err)
+		assert.False(t, progress.Resumed())
+
+		require.NoError(t, progress.RecordFix("v1", fixed, 0.02))
+		require.NoError(t, progress.RecordSkip("v1", skipped, "below confidence threshold"))
+		require.NoError(t, progress.RecordFailure("v2", failed, "rate limited"))
+
+		state, err := LoadState(path)
+		require.NoError(t, err)
+		require.NotNil(t, state)
+		assert.Equal(t, "output.yaml", state.PlanFile)
+		assert.Equal(t, StatusCompleted, state.Violations["v1"].Incidents[IncidentKey(fixed)].Status)
+		assert.Equal(t, StatusSkipped, state.Violations["v1"].Incidents[IncidentKey(skipped)].Status)
+		assert.Equal(t, "below confidence threshold", state.Violations["v1"].Incidents[IncidentKey(skipped)].Reason)
+		assert.Equal(t, StatusFailed, state.Violations["v2"].Incidents[IncidentKey(failed)].Status)
+		require.NotNil(t, state.LastFailure)
+		assert.Equal(t, "rate limited", state.LastFailure.Error)
+
+		assert.False(t, progress.Done("v1", fixed), "only --resume skips fixed incidents")
+	})
+
+	t.Run("resume skips only fixed incidents", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.yaml")
+		first, err := LoadRemediateProgress(path, "output.yaml", false, false)
+		require.NoError(t, err)
+		require.NoError(t, first.RecordFix("v1", fixed, 0.02))
+		require.NoError(t, first.RecordSkip("v1", skipped, "below confidence threshold"))
+		require.NoError(t, first.RecordFailure("v2", failed, "rate limited"))
+
+		progress, err := LoadRemediateProgress(path, "output.yaml", true, false)
+		require.NoError(t, err)
+		assert.True(t, progress.Resumed())
+		assert.True(t, progress.Done("v1", fixed))
+		assert.False(t, progress.Done("v1", skipped))
+		assert.False(t, progress.Done("v2", failed))
+
+		// Recording continues in the same file
+		require.NoError(t, progress.RecordFix("v2", failed, 0.01))
+		state, err := LoadState(path)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, state.Violations["v2"].Incidents[IncidentKey(failed)].Status)
+		assert.Equal(t, 2, state.Violations["v2"].Incidents[IncidentKey(failed)].Attempts)
+	})
+
+	t.Run("resume without a state file starts afresh", func(t *testing.T) {
+		progress, err := LoadRemediateProgress(filepath.Join(t.TempDir(), "state.yaml"), "output.yaml", true, false)
+		require.NoError(t, err)
+		assert.False(t, progress.Resumed())
+		assert.False(t, progress.Done("v1", fixed))
+	})
+
+	t.Run("dry-run writes nothing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.yaml")
+		progress, err := LoadRemediateProgress(path, "output.yaml", false, true)
+		require.NoError(t, err)
+		require.NoError(t, progress.RecordFix("v1", fixed, 0.02))
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("nil progress records nothing", func(t *testing.T) {
+		var progress *RemediateProgress
+		assert.False(t, progress.Resumed())
+		assert.False(t, progress.Done("v1", fixed))
+		assert.NoError(t, progress.RecordFix("v1", fixed, 0.02))
+		assert.NoError(t, progress.RecordSkip("v1", skipped, "ignored"))
+		assert.NoError(t, progress.RecordFailure("v2", failed, "rate limited"))
+	})
+}
@@ -0,0 +1,86 @@
+package planfile
+
+// EffortRisk is the risk of a violation by its effort: up to 3 is low, up to
+// 6 medium, above that high
+func EffortRisk(effort int) RiskLevel {
+	switch {
+	case effort <= 3:
+		return RiskLow
+	case effort <= 6:
+		return RiskMedium
+	default:
+		return RiskHigh
+	}
+}
+
+// RiskRank orders risk levels from lowest to highest
+func RiskRank(risk RiskLevel) int {
+	switch risk {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ViolationRisk is the risk of fixing a violation of category: its
+// EffortRisk, but at least medium if it requires manual review (high or
+// expert migration complexity) or its category is potential, since the
+// analysis only suspects that potential issues need changing and a fix may
+// change code that was correct
+func ViolationRisk(v PlannedViolation, category string) RiskLevel {
+	risk := EffortRisk(v.Effort)
+	if risk == RiskLow && (v.ManualReviewRequired || category == "potential") {
+		risk = RiskMedium
+	}
+	return risk
+}
+
+// ComputedRisk is the risk of a phase by its violations: the highest
+// ViolationRisk among them, or its Risk if it has none. A violation without
+// a category has the phase's.
+func (p *Phase) ComputedRisk() RiskLevel {
+	if len(p.Violations) == 0 {
+		return p.Risk
+	}
+	risk := RiskLow
+	for _, v := range p.Violations {
+		category := v.Category
+		if category == "" {
+			category = p.Category
+		}
+		if r := ViolationRisk(v, category); RiskRank(r) > RiskRank(risk) {
+			risk = r
+		}
+	}
+	return risk
+}
+
+// RiskChange is a phase whose risk RecomputeRisk changed
+type RiskChange struct {
+	PhaseID string
+	From    RiskLevel
+	To      RiskLevel
+}
+
+// RecomputeRisk sets the risk of every phase that isn't RiskLocked to its
+// ComputedRisk, e.g. after violations were moved between phases by hand, and
+// returns the phases that changed
+func RecomputeRisk(plan *Plan) []RiskChange {
+	var changes []RiskChange
+	for i := range plan.Phases {
+		phase := &plan.Phases[i]
+		if phase.RiskLocked {
+			continue
+		}
+		if risk := phase.ComputedRisk(); risk != phase.Risk {
+			changes = append(changes, RiskChange{PhaseID: phase.ID, From: phase.Risk, To: risk})
+			phase.Risk = risk
+		}
+	}
+	return changes
+}
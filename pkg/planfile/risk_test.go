@@ -0,0 +1,42 @@
+package planfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffortRisk(t *testing.T) {
+	assert.Equal(t, RiskLow, EffortRisk(3))
+	assert.Equal(t, RiskMedium, EffortRisk(4))
+	assert.Equal(t, RiskMedium, EffortRisk(6))
+	assert.Equal(t, RiskHigh, EffortRisk(7))
+}
+
+func TestRecomputeRisk(t *testing.T) {
+	plan := NewPlan("claude", 4)
+	plan.Phases = []Phase{
+		{ID: "phase-1", Risk: RiskLow, Violations: []PlannedViolation{{Effort: 1}, {Effort: 8}}},
+		{ID: "phase-2", Risk: RiskHigh, Violations: []PlannedViolation{{Effort: 2}}},
+		{ID: "phase-3", Risk: RiskLow, Violations: []PlannedViolation{{Effort: 2, ManualReviewRequired: true}}},
+		{ID: "phase-4", Risk: RiskLow, RiskLocked: true, Violations: []PlannedViolation{{Effort: 9}}},
+		{ID: "phase-5", Risk: RiskMedium, Violations: []PlannedViolation{{Effort: 5}}},
+		{ID: "phase-6", Risk: RiskLow, Violations: []PlannedViolation{{Effort: 1, Category: "optional"}, {Effort: 2, Category: "potential"}}},
+		{ID: "phase-7", Risk: RiskLow, Category: "potential", Violations: []PlannedViolation{{Effort: 1}}},
+		{ID: "phase-8", Risk: RiskMedium, Category: "potential", Violations: []PlannedViolation{{Effort: 1, Category: "mandatory"}}},
+	}
+
+	changes := RecomputeRisk(plan)
+
+	assert.Equal(t, []RiskChange{
+		{PhaseID: "phase-1", From: RiskLow, To: RiskHigh},
+		{PhaseID: "phase-2", From: RiskHigh, To: RiskLow},
+		{PhaseID: "phase-3", From: RiskLow, To: RiskMedium},
+		{PhaseID: "phase-6", From: RiskLow, To: RiskMedium},
+		{PhaseID: "phase-7", From: RiskLow, To: RiskMedium},
+		{PhaseID: "phase-8", From: RiskMedium, To: RiskLow},
+	}, changes)
+	assert.Equal(t, RiskHigh, plan.Phases[0].Risk)
+	assert.Equal(t, RiskLow, plan.Phases[3].Risk, "locked")
+	assert.Equal(t, RiskMedium, plan.Phases[4].Risk)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/tsanders/kantra-ai/pkg/violation"
 	"gopkg.in/yaml.v3"
 )
 
@@ -45,6 +46,9 @@ func SaveState(state *ExecutionState, path string) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
@@ -133,8 +137,29 @@ func (s *ExecutionState) MarkPhaseFailed(phaseID string) {
 	}
 }
 
-// RecordIncidentFix records a successful fix for an incident
-func (s *ExecutionState) RecordIncidentFix(violationID, incidentURI string, cost float64) {
+// IncidentKey returns the key that identifies an incident in the state file.
+// Several incidents can share a file URI, so the line number is included.
+func IncidentKey(incident violation.Incident) string {
+	return fmt.Sprintf("%s:%d", incident.URI, incident.LineNumber)
+}
+
+// GetIncidentStatus returns the recorded status of an incident. State files
+// written before incident keys included the line number are keyed by URI only;
+// those entries are used as a fallback.
+func (s *ExecutionState) GetIncidentStatus(violationID string, incident violation.Incident) (IncidentStatus, bool) {
+	violationStatus, exists := s.Violations[violationID]
+	if !exists {
+		return IncidentStatus{}, false
+	}
+	if status, ok := violationStatus.Incidents[IncidentKey(incident)]; ok {
+		return status, true
+	}
+	status, ok := violationStatus.Incidents[incident.URI]
+	return status, ok
+}
+
+// RecordIncidentFix records a successful fix for an incident (keyed by IncidentKey)
+func (s *ExecutionState) RecordIncidentFix(violationID, incidentKey string, cost float64) {
 	if s.Violations == nil {
 		s.Violations = make(map[string]ViolationStatus)
 	}
@@ -147,10 +172,11 @@ func (s *ExecutionState) RecordIncidentFix(violationID, incidentURI string, cost
 		}
 	}
 
-	violationStatus.Incidents[incidentURI] = IncidentStatus{
+	violationStatus.Incidents[incidentKey] = IncidentStatus{
 		Status:    StatusCompleted,
 		Cost:      cost,
 		Timestamp: time.Now(),
+		Attempts:  violationStatus.Incidents[incidentKey].Attempts + 1,
 	}
 
 	allCompleted := true
@@ -169,8 +195,9 @@ func (s *ExecutionState) RecordIncidentFix(violationID, incidentURI string, cost
 	s.ExecutionSummary.TotalCost += cost
 }
 
-// RecordIncidentFailure records a failed fix attempt
-func (s *ExecutionState) RecordIncidentFailure(phaseID, violationID, incidentURI, errorMsg string) {
+// RecordIncidentFailure records a failed fix attempt (keyed by IncidentKey)
+// and returns the number of attempts made on the incident so far
+func (s *ExecutionState) RecordIncidentFailure(phaseID, violationID, incidentKey, errorMsg string) int {
 	if s.Violations == nil {
 		s.Violations = make(map[string]ViolationStatus)
 	}
@@ -183,9 +210,11 @@ func (s *ExecutionState) RecordIncidentFailure(phaseID, violationID, incidentURI
 		}
 	}
 
-	violationStatus.Incidents[incidentURI] = IncidentStatus{
+	attempts := violationStatus.Incidents[incidentKey].Attempts + 1
+	violationStatus.Incidents[incidentKey] = IncidentStatus{
 		Status:    StatusFailed,
 		Timestamp: time.Now(),
+		Attempts:  attempts,
 	}
 	violationStatus.Status = StatusFailed
 
@@ -194,9 +223,104 @@ func (s *ExecutionState) RecordIncidentFailure(phaseID, violationID, incidentURI
 	s.LastFailure = &FailureInfo{
 		PhaseID:     phaseID,
 		ViolationID: violationID,
-		IncidentURI: incidentURI,
+		IncidentURI: incidentKey,
 		Error:       errorMsg,
 	}
+	return attempts
+}
+
+// RecordIncidentSkip records an incident (keyed by IncidentKey) whose fix was
+// skipped rather than failed, such as one below the confidence threshold.
+// The violation's status is left as it is.
+func (s *ExecutionState) RecordIncidentSkip(violationID, incidentKey, reason string) {
+	if s.Violations == nil {
+		s.Violations = make(map[string]ViolationStatus)
+	}
+
+	violationStatus, exists := s.Violations[violationID]
+	if !exists {
+		violationStatus = ViolationStatus{
+			Status:    StatusInProgress,
+			Incidents: make(map[string]IncidentStatus),
+		}
+	}
+
+	violationStatus.Incidents[incidentKey] = IncidentStatus{
+		Status:    StatusSkipped,
+		Timestamp: time.Now(),
+		Attempts:  violationStatus.Incidents[incidentKey].Attempts + 1,
+		Reason:    reason,
+	}
+	s.Violations[violationID] = violationStatus
+}
+
+// MarkIncidentPermanentlyFailed marks a failed incident as permanently
+// failed, so resume skips it instead of retrying it again
+func (s *ExecutionState) MarkIncidentPermanentlyFailed(violationID, incidentKey string) {
+	violationStatus, exists := s.Violations[violationID]
+	if !exists {
+		return
+	}
+	incident, exists := violationStatus.Incidents[incidentKey]
+	if !exists || incident.Status == StatusCompleted {
+		return
+	}
+	incident.Status = StatusPermanentlyFailed
+	violationStatus.Incidents[incidentKey] = incident
+	s.Violations[violationID] = violationStatus
+}
+
+// RecordViolationChanges records the files changed and the commits created
+// for a violation's fixes. Entries that are already recorded are not repeated.
+func (s *ExecutionState) RecordViolationChanges(violationID string, files, commits []string) {
+	if s.Violations == nil {
+		s.Violations = make(map[string]ViolationStatus)
+	}
+
+	violationStatus, exists := s.Violations[violationID]
+	if !exists {
+		violationStatus = ViolationStatus{
+			Status:    StatusInProgress,
+			Incidents: make(map[string]IncidentStatus),
+		}
+	}
+
+	violationStatus.Files = appendUnique(violationStatus.Files, files...)
+	violationStatus.Commits = appendUnique(violationStatus.Commits, commits...)
+	s.Violations[violationID] = violationStatus
+}
+
+// RecordVerification records the verification outcome for a violation. A
+// failure is kept even if a later verification passes.
+func (s *ExecutionState) RecordVerification(violationID string, passed bool) {
+	violationStatus, exists := s.Violations[violationID]
+	if !exists {
+		return
+	}
+
+	if !passed {
+		violationStatus.Verification = VerificationFailed
+	} else if violationStatus.Verification != VerificationFailed {
+		violationStatus.Verification = VerificationPassed
+	}
+	s.Violations[violationID] = violationStatus
+}
+
+// appendUnique appends values that are not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
 }
 
 // HasFailures returns true if there are any failed incidents
@@ -205,7 +329,7 @@ func (s *ExecutionState) HasFailures() bool {
 }
 
 // IsIncidentCompleted checks if an incident has been successfully fixed
-func (s *ExecutionState) IsIncidentCompleted(violationID, incidentURI string) bool {
+func (s *ExecutionState) IsIncidentCompleted(violationID, incidentKey string) bool {
 	if s.Violations == nil {
 		return false
 	}
@@ -213,7 +337,7 @@ func (s *ExecutionState) IsIncidentCompleted(violationID, incidentURI string) bo
 	if !exists {
 		return false
 	}
-	incident, exists := violation.Incidents[incidentURI]
+	incident, exists := violation.Incidents[incidentKey]
 	return exists && incident.Status == StatusCompleted
 }
 
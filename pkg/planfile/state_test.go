@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
 func TestNewState(t *testing.T) {
@@ -232,6 +233,48 @@ func TestRecordIncidentFailure(t *testing.T) {
 	assert.Equal(t, "AI timeout", state.LastFailure.Error)
 }
 
+func TestRecordIncidentAttempts(t *testing.T) {
+	state := NewState(".kantra-ai-plan.yaml", 1)
+
+	assert.Equal(t, 1, state.RecordIncidentFailure("phase-1", "v1", "file:///a.java:10", "AI timeout"))
+	assert.Equal(t, 2, state.RecordIncidentFailure("phase-1", "v1", "file:///a.java:10", "AI timeout"))
+	assert.Equal(t, 1, state.RecordIncidentFailure("phase-1", "v1", "file:///b.java:10", "AI timeout"))
+
+	state.MarkIncidentPermanentlyFailed("v1", "file:///a.java:10")
+	incident := state.Violations["v1"].Incidents["file:///a.java:10"]
+	assert.Equal(t, StatusPermanentlyFailed, incident.Status)
+	assert.Equal(t, 2, incident.Attempts)
+
+	// A success counts as an attempt too and is never marked permanently failed
+	state.RecordIncidentFix("v1", "file:///b.java:10", 0.01)
+	state.MarkIncidentPermanentlyFailed("v1", "file:///b.java:10")
+	incident = state.Violations["v1"].Incidents["file:///b.java:10"]
+	assert.Equal(t, StatusCompleted, incident.Status)
+	assert.Equal(t, 2, incident.Attempts)
+
+	// Unknown incidents are ignored
+	state.MarkIncidentPermanentlyFailed("v2", "file:///c.java:1")
+	assert.NotContains(t, state.Violations, "v2")
+}
+
+func TestRecordIncidentSkip(t *testing.T) {
+	state := NewState(".kantra-ai-plan.yaml", 1)
+	state.RecordIncidentFix("v1", "file:///a.java:10", 0.5)
+
+	state.RecordIncidentSkip("v1", "file:///b.java:10", "confidence 0.50 below threshold 0.80")
+
+	incident := state.Violations["v1"].Incidents["file:///b.java:10"]
+	assert.Equal(t, StatusSkipped, incident.Status)
+	assert.Equal(t, "confidence 0.50 below threshold 0.80", incident.Reason)
+	assert.Equal(t, 1, incident.Attempts)
+	assert.Equal(t, StatusCompleted, state.Violations["v1"].Status, "a skip doesn't fail the violation")
+	assert.False(t, state.HasFailures())
+	assert.False(t, state.IsIncidentCompleted("v1", "file:///b.java:10"))
+
+	state.RecordIncidentSkip("v2", "file:///c.java:1", "ignored")
+	assert.Equal(t, StatusInProgress, state.Violations["v2"].Status)
+}
+
 func TestHasFailures(t *testing.T) {
 	state := NewState(".kantra-ai-plan.yaml", 1)
 
@@ -287,3 +330,69 @@ func TestGetCompletedIncidentCount(t *testing.T) {
 	// Different violation should return 0
 	assert.Equal(t, 0, state.GetCompletedIncidentCount("v2"))
 }
+
+func TestExecutionState_GetIncidentStatus(t *testing.T) {
+	state := NewState("plan.yaml", 1)
+	incident10 := violation.Incident{URI: "file:///src/A.java", LineNumber: 10}
+	incident20 := violation.Incident{URI: "file:///src/A.java", LineNumber: 20}
+
+	state.RecordIncidentFix("v1", IncidentKey(incident10), 0.01)
+
+	status, ok := state.GetIncidentStatus("v1", incident10)
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, status.Status)
+
+	// Another incident in the same file is tracked separately
+	_, ok = state.GetIncidentStatus("v1", incident20)
+	assert.False(t, ok)
+
+	// State written with URI-only keys is still honored
+	legacy := NewState("plan.yaml", 1)
+	legacy.RecordIncidentFix("v1", incident10.URI, 0.01)
+	status, ok = legacy.GetIncidentStatus("v1", incident20)
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, status.Status)
+
+	_, ok = state.GetIncidentStatus("unknown", incident10)
+	assert.False(t, ok)
+}
+
+func TestRecordViolationChanges(t *testing.T) {
+	state := NewState("plan.yaml", 1)
+	state.RecordIncidentFix("v1", "file:///src/A.java:10", 0.01)
+
+	state.RecordViolationChanges("v1", []string{"src/A.java", "src/B.java"}, []string{"abc123"})
+	state.RecordViolationChanges("v1", []string{"src/A.java"}, []string{"abc123", "def456"})
+
+	status := state.Violations["v1"]
+	assert.Equal(t, StatusCompleted, status.Status)
+	assert.Equal(t, []string{"src/A.java", "src/B.java"}, status.Files)
+	assert.Equal(t, []string{"abc123", "def456"}, status.Commits)
+}
+
+func TestRecordVerification(t *testing.T) {
+	state := NewState("plan.yaml", 1)
+	state.RecordIncidentFix("v1", "file:///src/A.java:10", 0.01)
+
+	state.RecordVerification("v1", true)
+	assert.Equal(t, VerificationPassed, state.Violations["v1"].Verification)
+
+	state.RecordVerification("v1", false)
+	state.RecordVerification("v1", true)
+	assert.Equal(t, VerificationFailed, state.Violations["v1"].Verification)
+
+	// Unknown violations are ignored
+	state.RecordVerification("v2", true)
+	_, exists := state.Violations["v2"]
+	assert.False(t, exists)
+}
+
+func TestSaveState_CreatesParentDirectories(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), ".kantra-ai", "runs", "state.yaml")
+
+	require.NoError(t, SaveState(NewState("plan.yaml", 1), statePath))
+
+	state, err := LoadState(statePath)
+	require.NoError(t, err)
+	assert.NotNil(t, state)
+}
@@ -23,6 +23,7 @@ type PlanMetadata struct {
 	CreatedAt       time.Time `yaml:"created_at"`
 	Provider        string    `yaml:"provider"`
 	TotalViolations int       `yaml:"total_violations"`
+	Heuristic       bool      `yaml:"heuristic,omitempty"` // Grouped by category and effort without calling the model
 }
 
 // Phase represents a logical grouping of violations to fix together
@@ -31,15 +32,24 @@ type Phase struct {
 	Name                    string              `yaml:"name"`
 	Order                   int                 `yaml:"order"`
 	Risk                    RiskLevel           `yaml:"risk"`
+	RiskLocked              bool                `yaml:"risk_locked,omitempty"` // Keep Risk as set by hand when risks are recomputed (see RecomputeRisk)
 	Category                string              `yaml:"category"`
 	EffortRange             [2]int              `yaml:"effort_range"`
 	Explanation             string              `yaml:"explanation"`
 	Violations              []PlannedViolation  `yaml:"violations"`
 	EstimatedCost           float64             `yaml:"estimated_cost"`
 	EstimatedDurationMinutes int                `yaml:"estimated_duration_minutes"`
+	CostSource              string              `yaml:"cost_source,omitempty"` // Where EstimatedCost came from (CostSource* constants)
 	Deferred                bool                `yaml:"deferred"`
 }
 
+// Sources of a phase's estimated cost
+const (
+	CostSourceModel   = "model"   // Estimated by the AI model while planning
+	CostSourceHistory = "history" // Historical per-incident averages for every violation in the phase
+	CostSourceMixed   = "mixed"   // Historical averages for some violations, model estimate for the rest
+)
+
 // RiskLevel indicates the risk associated with a phase
 type RiskLevel string
 
@@ -65,6 +75,7 @@ type PlannedViolation struct {
 type ExecutionState struct {
 	Version          string                     `yaml:"version"`
 	PlanFile         string                     `yaml:"plan_file"`
+	PlanHash         string                     `yaml:"plan_hash,omitempty"` // Plan.Hash() of the plan this state was written for
 	StartedAt        time.Time                  `yaml:"started_at"`
 	UpdatedAt        time.Time                  `yaml:"updated_at"`
 	ExecutionSummary ExecutionSummary           `yaml:"execution_summary"`
@@ -83,18 +94,23 @@ type ExecutionSummary struct {
 
 // PhaseStatus tracks the execution status of a phase
 type PhaseStatus struct {
-	PhaseID      string       `yaml:"phase_id"`
-	Status       StatusType   `yaml:"status"`
-	StartedAt    *time.Time   `yaml:"started_at,omitempty"`
-	CompletedAt  *time.Time   `yaml:"completed_at,omitempty"`
-	FixesApplied int          `yaml:"fixes_applied"`
-	Cost         float64      `yaml:"cost"`
+	PhaseID       string     `yaml:"phase_id"`
+	Status        StatusType `yaml:"status"`
+	StartedAt     *time.Time `yaml:"started_at,omitempty"`
+	CompletedAt   *time.Time `yaml:"completed_at,omitempty"`
+	FixesApplied  int        `yaml:"fixes_applied"`
+	Cost          float64    `yaml:"cost"`                     // Actual cost, accumulated across resumed runs
+	Tokens        int        `yaml:"tokens"`                   // Actual tokens, accumulated across resumed runs
+	EstimatedCost float64    `yaml:"estimated_cost,omitempty"` // Plan estimate, kept for comparison
 }
 
 // ViolationStatus tracks the execution status of a violation
 type ViolationStatus struct {
-	Status    StatusType                 `yaml:"status"`
-	Incidents map[string]IncidentStatus  `yaml:"incidents"`
+	Status       StatusType                 `yaml:"status"`
+	Incidents    map[string]IncidentStatus  `yaml:"incidents"`
+	Files        []string                   `yaml:"files,omitempty"`        // Files changed by this violation's fixes, relative to the input directory
+	Commits      []string                   `yaml:"commits,omitempty"`      // SHAs of commits containing this violation's fixes
+	Verification VerificationStatus         `yaml:"verification,omitempty"` // Outcome of verification covering this violation's fixes
 }
 
 // IncidentStatus tracks the execution status of a specific incident
@@ -102,6 +118,8 @@ type IncidentStatus struct {
 	Status    StatusType `yaml:"status"`
 	Cost      float64    `yaml:"cost"`
 	Timestamp time.Time  `yaml:"timestamp"`
+	Attempts  int        `yaml:"attempts,omitempty"` // Fix attempts across runs, successful or not
+	Reason    string     `yaml:"reason,omitempty"`   // Why a skipped incident was skipped
 }
 
 // StatusType represents the execution status
@@ -112,6 +130,20 @@ const (
 	StatusInProgress StatusType = "in_progress"
 	StatusCompleted  StatusType = "completed"
 	StatusFailed     StatusType = "failed"
+	// StatusPermanentlyFailed marks an incident that reached the attempt
+	// limit; it is skipped on resume instead of being retried
+	StatusPermanentlyFailed StatusType = "permanently_failed"
+	// StatusSkipped marks an incident whose fix was deliberately not applied,
+	// e.g. for low confidence; it is attempted again on resume
+	StatusSkipped StatusType = "skipped"
+)
+
+// VerificationStatus is the outcome of build/test verification for a violation
+type VerificationStatus string
+
+const (
+	VerificationPassed VerificationStatus = "passed"
+	VerificationFailed VerificationStatus = "failed"
 )
 
 // FailureInfo captures details about the last failure
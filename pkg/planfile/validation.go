@@ -241,7 +241,7 @@ func validateViolationStatus(status *ViolationStatus, violationID string) error
 // isValidStatusType checks if a status type is valid
 func isValidStatusType(status StatusType) bool {
 	switch status {
-	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed:
+	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed, StatusPermanentlyFailed, StatusSkipped:
 		return true
 	default:
 		return false
@@ -0,0 +1,128 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+// Grouping modes for Config.GroupBy
+const (
+	GroupByDefault     = ""             // Keep the phases proposed by the AI provider
+	GroupByFileOverlap = "file-overlap" // Keep violations that touch the same files in the same phase
+)
+
+// ValidateGroupBy returns an error if mode is not a supported grouping mode.
+func ValidateGroupBy(mode string) error {
+	switch mode {
+	case GroupByDefault, GroupByFileOverlap:
+		return nil
+	default:
+		return fmt.Errorf("invalid group-by mode: %s (must be one of: %s)", mode, GroupByFileOverlap)
+	}
+}
+
+// groupByFileOverlap regroups phases so that violations sharing any file end
+// up in the same phase. Violations that are connected through shared files
+// (directly or transitively) move to the earliest phase containing one of
+// them. Moved violations take their share of the source phase's estimated
+// cost and duration with them, the destination phase takes the higher of the
+// two risk levels, phases left empty are dropped, and phase orders are
+// renumbered.
+func groupByFileOverlap(phases []planfile.Phase) []planfile.Phase {
+	type location struct {
+		phase     int
+		violation int
+	}
+
+	// Union violations that touch a common file
+	var locations []location
+	parent := make(map[int]int)
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	fileOwner := make(map[string]int)
+	for p, phase := range phases {
+		for v, pv := range phase.Violations {
+			idx := len(locations)
+			locations = append(locations, location{phase: p, violation: v})
+			parent[idx] = idx
+
+			for _, incident := range pv.Incidents {
+				file := incident.GetFilePath()
+				if file == "" {
+					continue
+				}
+				if owner, ok := fileOwner[file]; ok {
+					parent[find(idx)] = find(owner)
+				} else {
+					fileOwner[file] = idx
+				}
+			}
+		}
+	}
+
+	// Each group lands in the earliest phase holding one of its violations
+	destination := make(map[int]int)
+	for idx, loc := range locations {
+		root := find(idx)
+		if dest, ok := destination[root]; !ok || loc.phase < dest {
+			destination[root] = loc.phase
+		}
+	}
+
+	result := make([]planfile.Phase, len(phases))
+	for p, phase := range phases {
+		result[p] = phase
+		result[p].Violations = make([]planfile.PlannedViolation, 0, len(phase.Violations))
+	}
+
+	moved := make([]int, len(phases))
+	for idx, loc := range locations {
+		src := phases[loc.phase]
+		dest := destination[find(idx)]
+		result[dest].Violations = append(result[dest].Violations, src.Violations[loc.violation])
+		if dest == loc.phase {
+			continue
+		}
+
+		share := 1 / float64(len(src.Violations))
+		cost := src.EstimatedCost * share
+		duration := int(math.Round(float64(src.EstimatedDurationMinutes) * share))
+		result[loc.phase].EstimatedCost -= cost
+		result[loc.phase].EstimatedDurationMinutes -= duration
+		result[dest].EstimatedCost += cost
+		result[dest].EstimatedDurationMinutes += duration
+		if planfile.RiskRank(src.Risk) > planfile.RiskRank(result[dest].Risk) {
+			result[dest].Risk = src.Risk
+		}
+		moved[dest]++
+	}
+
+	grouped := make([]planfile.Phase, 0, len(result))
+	for p, phase := range result {
+		if len(phase.Violations) == 0 {
+			continue
+		}
+		if moved[p] > 0 {
+			phase.Explanation = fmt.Sprintf("%s Includes %d violation(s) from other phases that touch the same files.", phase.Explanation, moved[p])
+		}
+		if phase.EstimatedDurationMinutes < 0 {
+			phase.EstimatedDurationMinutes = 0
+		}
+		grouped = append(grouped, phase)
+	}
+
+	for i := range grouped {
+		grouped[i].Order = i + 1
+	}
+
+	return grouped
+}
@@ -0,0 +1,101 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func plannedViolation(id string, files ...string) planfile.PlannedViolation {
+	pv := planfile.PlannedViolation{ViolationID: id}
+	for _, file := range files {
+		pv.Incidents = append(pv.Incidents, violation.Incident{URI: "file:///src/" + file})
+	}
+	pv.IncidentCount = len(pv.Incidents)
+	return pv
+}
+
+func phaseViolationIDs(phase planfile.Phase) []string {
+	var ids []string
+	for _, v := range phase.Violations {
+		ids = append(ids, v.ViolationID)
+	}
+	return ids
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	assert.NoError(t, ValidateGroupBy(""))
+	assert.NoError(t, ValidateGroupBy("file-overlap"))
+
+	err := ValidateGroupBy("directory")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid group-by mode")
+}
+
+func TestGroupByFileOverlap(t *testing.T) {
+	t.Run("violations sharing files land in the same phase", func(t *testing.T) {
+		phases := []planfile.Phase{
+			{
+				ID:                       "phase-1",
+				Risk:                     planfile.RiskLow,
+				Violations:               []planfile.PlannedViolation{plannedViolation("v1", "A.java"), plannedViolation("v2", "B.java")},
+				EstimatedCost:            0.20,
+				EstimatedDurationMinutes: 10,
+				Explanation:              "Low risk changes.",
+			},
+			{
+				ID:                       "phase-2",
+				Risk:                     planfile.RiskHigh,
+				Violations:               []planfile.PlannedViolation{plannedViolation("v3", "A.java", "C.java"), plannedViolation("v4", "D.java")},
+				EstimatedCost:            0.40,
+				EstimatedDurationMinutes: 20,
+			},
+		}
+
+		result := groupByFileOverlap(phases)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, []string{"v1", "v2", "v3"}, phaseViolationIDs(result[0]))
+		assert.Equal(t, []string{"v4"}, phaseViolationIDs(result[1]))
+
+		// v3 carries half of phase-2's estimates and its risk
+		assert.InDelta(t, 0.40, result[0].EstimatedCost, 0.0001)
+		assert.Equal(t, 20, result[0].EstimatedDurationMinutes)
+		assert.InDelta(t, 0.20, result[1].EstimatedCost, 0.0001)
+		assert.Equal(t, 10, result[1].EstimatedDurationMinutes)
+		assert.Equal(t, planfile.RiskHigh, result[0].Risk)
+		assert.Contains(t, result[0].Explanation, "Includes 1 violation(s) from other phases")
+	})
+
+	t.Run("transitive overlap merges phases and drops empty ones", func(t *testing.T) {
+		phases := []planfile.Phase{
+			{ID: "phase-1", Violations: []planfile.PlannedViolation{plannedViolation("v1", "A.java")}},
+			{ID: "phase-2", Violations: []planfile.PlannedViolation{plannedViolation("v2", "B.java")}},
+			{ID: "phase-3", Violations: []planfile.PlannedViolation{plannedViolation("v3", "A.java", "B.java")}},
+		}
+
+		result := groupByFileOverlap(phases)
+
+		require.Len(t, result, 1)
+		assert.Equal(t, "phase-1", result[0].ID)
+		assert.Equal(t, 1, result[0].Order)
+		assert.Equal(t, []string{"v1", "v2", "v3"}, phaseViolationIDs(result[0]))
+	})
+
+	t.Run("disjoint files keep the original phases", func(t *testing.T) {
+		phases := []planfile.Phase{
+			{ID: "phase-1", Order: 1, Violations: []planfile.PlannedViolation{plannedViolation("v1", "A.java")}},
+			{ID: "phase-2", Order: 2, Violations: []planfile.PlannedViolation{plannedViolation("v2", "B.java")}},
+		}
+
+		result := groupByFileOverlap(phases)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, []string{"v1"}, phaseViolationIDs(result[0]))
+		assert.Equal(t, []string{"v2"}, phaseViolationIDs(result[1]))
+		assert.Empty(t, result[0].Explanation)
+	})
+}
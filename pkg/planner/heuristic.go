@@ -0,0 +1,44 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// heuristicPlan groups violations into phases by category and risk without
+// calling the model, using the same merging and ordering as batched AI plans
+// (see provider.MergePlannedPhases). A violation's risk comes from its effort
+// (see planfile.EffortRisk) and its estimated cost from the provider's
+// per-incident estimate, which counts the incident's file under InputPath as
+// remediate's cost check does. The response costs nothing.
+func (p *Planner) heuristicPlan(violations []violation.Violation) *provider.PlanResponse {
+	phases := make([]provider.PlannedPhase, 0, len(violations))
+	for _, v := range violations {
+		risk := string(planfile.EffortRisk(v.Effort))
+		phase := provider.PlannedPhase{
+			Name:         fmt.Sprintf("%s - %s Risk", v.Category, risk),
+			Risk:         risk,
+			Category:     v.Category,
+			EffortRange:  [2]int{v.Effort, v.Effort},
+			ViolationIDs: []string{v.ID},
+		}
+		for _, incident := range v.Incidents {
+			cost, _ := p.config.Provider.EstimateCost(fixer.EstimateRequest(p.config.InputPath, v, incident))
+			phase.EstimatedCost += cost
+		}
+		phases = append(phases, phase)
+	}
+
+	// No phase limit: truncating would drop violations from the plan
+	phases = provider.MergePlannedPhases(phases, 0)
+	for i := range phases {
+		phases[i].Explanation = fmt.Sprintf("Heuristic grouping of %d %s violations at %s risk (effort %d-%d), without AI review.",
+			len(phases[i].ViolationIDs), phases[i].Category, phases[i].Risk, phases[i].EffortRange[0], phases[i].EffortRange[1])
+	}
+
+	return &provider.PlanResponse{Phases: phases}
+}
@@ -0,0 +1,104 @@
+package planner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestGenerate_Estimate(t *testing.T) {
+	tmpDir := t.TempDir()
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	incident := violation.Incident{URI: "file:///src/A.java", LineNumber: 1}
+	analysis := &violation.Analysis{Violations: []violation.Violation{
+		{ID: "v-imports", Description: "d", Category: "mandatory", Effort: 1, Incidents: []violation.Incident{incident, incident}},
+		{ID: "v-logging", Description: "d", Category: "optional", Effort: 3, Incidents: []violation.Incident{incident}},
+		{ID: "v-ejb", Description: "d", Category: "mandatory", Effort: 7, Incidents: []violation.Incident{incident}},
+		{ID: "v-annotations", Description: "d", Category: "mandatory", Effort: 2, Incidents: []violation.Incident{incident}},
+	}}
+	require.NoError(t, saveAnalysis(analysis, analysisPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("claude").Maybe()
+	mockProvider.On("EstimateCost", mock.Anything).Return(0.05, nil)
+
+	result, err := New(Config{
+		AnalysisPath: analysisPath,
+		Provider:     mockProvider,
+		OutputPath:   filepath.Join(tmpDir, "output"),
+		MaxPhases:    1, // Ignored: every violation stays in the plan
+		Estimate:     true,
+	}).Generate(context.Background())
+	require.NoError(t, err)
+	mockProvider.AssertNotCalled(t, "GeneratePlan", mock.Anything, mock.Anything)
+
+	assert.True(t, result.Heuristic)
+	assert.Zero(t, result.GenerateCost)
+	assert.Zero(t, result.TokensUsed)
+	assert.Equal(t, 3, result.TotalPhases)
+	assert.InDelta(t, 0.25, result.TotalCost, 1e-9)
+
+	plan := result.Plan
+	assert.True(t, plan.Metadata.Heuristic)
+	assert.NoError(t, planfile.ValidatePlan(plan))
+
+	// Mandatory before optional, high risk before low
+	high := plan.Phases[0]
+	assert.Equal(t, "phase-1", high.ID)
+	assert.Equal(t, planfile.RiskHigh, high.Risk)
+	assert.Len(t, high.Violations, 1)
+
+	low := plan.Phases[1]
+	assert.Equal(t, "mandatory", low.Category)
+	assert.Equal(t, planfile.RiskLow, low.Risk)
+	assert.Equal(t, [2]int{1, 2}, low.EffortRange)
+	assert.Len(t, low.Violations, 2)
+	assert.InDelta(t, 0.15, low.EstimatedCost, 1e-9)
+	assert.Contains(t, low.Explanation, "Heuristic")
+
+	assert.Equal(t, "optional", plan.Phases[2].Category)
+	assert.Equal(t, 3, plan.Phases[2].Order)
+
+	saved, err := planfile.LoadPlan(result.PlanPath)
+	require.NoError(t, err)
+	assert.True(t, saved.Metadata.Heuristic)
+}
+
+func TestGenerate_EstimateCountsFileContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	filePath := filepath.Join(inputDir, "src", "A.java")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, os.WriteFile(filePath, []byte("import javax.ejb.Stateless;\n"), 0644))
+
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	analysis := &violation.Analysis{Violations: []violation.Violation{
+		{ID: "v-ejb", Description: "d", Category: "mandatory", Effort: 1, Incidents: []violation.Incident{{URI: "file://" + filePath, LineNumber: 1}}},
+	}}
+	require.NoError(t, saveAnalysis(analysis, analysisPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("claude").Maybe()
+	mockProvider.On("EstimateCost", mock.MatchedBy(func(req provider.FixRequest) bool {
+		return req.FileContent == "import javax.ejb.Stateless;\n" && req.Language == "java"
+	})).Return(0.05, nil)
+
+	result, err := New(Config{
+		AnalysisPath: analysisPath,
+		InputPath:    inputDir,
+		Provider:     mockProvider,
+		OutputPath:   filepath.Join(tmpDir, "output"),
+		Estimate:     true,
+	}).Generate(context.Background())
+	require.NoError(t, err)
+	mockProvider.AssertExpectations(t)
+	assert.InDelta(t, 0.05, result.TotalCost, 1e-9)
+}
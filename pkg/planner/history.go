@@ -0,0 +1,105 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+// CostHistory holds the average actual cost of fixing one incident of each
+// violation type, taken from the state files of earlier executions.
+type CostHistory struct {
+	averages map[string]float64
+}
+
+// NewCostHistory computes per-violation averages over the completed incidents
+// in the given execution states.
+func NewCostHistory(states ...*planfile.ExecutionState) *CostHistory {
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, state := range states {
+		for violationID, vs := range state.Violations {
+			for _, incident := range vs.Incidents {
+				if incident.Status != planfile.StatusCompleted {
+					continue
+				}
+				totals[violationID] += incident.Cost
+				counts[violationID]++
+			}
+		}
+	}
+
+	history := &CostHistory{averages: make(map[string]float64, len(counts))}
+	for violationID, count := range counts {
+		history.averages[violationID] = totals[violationID] / float64(count)
+	}
+	return history
+}
+
+// LoadCostHistory builds a cost history from execution state files
+func LoadCostHistory(paths []string) (*CostHistory, error) {
+	states := make([]*planfile.ExecutionState, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		state, err := planfile.LoadState(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cost history: %w", err)
+		}
+		if state == nil {
+			return nil, fmt.Errorf("cost history file not found: %s", path)
+		}
+		states = append(states, state)
+	}
+	return NewCostHistory(states...), nil
+}
+
+// AverageCost returns the average cost per incident for a violation type.
+// ok is false if the history has no completed incidents for it.
+func (h *CostHistory) AverageCost(violationID string) (cost float64, ok bool) {
+	if h == nil {
+		return 0, false
+	}
+	cost, ok = h.averages[violationID]
+	return cost, ok
+}
+
+// applyCostHistory replaces model cost estimates with historical averages
+// where the history covers a phase's violations. Incidents of violations
+// without history keep their proportional share of the model estimate.
+func applyCostHistory(phases []planfile.Phase, history *CostHistory) {
+	for i := range phases {
+		phase := &phases[i]
+		phase.CostSource = planfile.CostSourceModel
+		if history == nil {
+			continue
+		}
+
+		totalIncidents := 0
+		uncoveredIncidents := 0
+		historicalCost := 0.0
+		for _, pv := range phase.Violations {
+			totalIncidents += pv.IncidentCount
+			if avg, ok := history.AverageCost(pv.ViolationID); ok {
+				historicalCost += avg * float64(pv.IncidentCount)
+			} else {
+				uncoveredIncidents += pv.IncidentCount
+			}
+		}
+
+		switch {
+		case totalIncidents == 0 || uncoveredIncidents == totalIncidents:
+			// Nothing covered; keep the model estimate
+		case uncoveredIncidents == 0:
+			phase.EstimatedCost = historicalCost
+			phase.CostSource = planfile.CostSourceHistory
+		default:
+			modelShare := phase.EstimatedCost * float64(uncoveredIncidents) / float64(totalIncidents)
+			phase.EstimatedCost = historicalCost + modelShare
+			phase.CostSource = planfile.CostSourceMixed
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package planner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+)
+
+func createHistoryState() *planfile.ExecutionState {
+	now := time.Now()
+	return &planfile.ExecutionState{
+		Violations: map[string]planfile.ViolationStatus{
+			"javax-to-jakarta": {
+				Status: planfile.StatusCompleted,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///a.java:1": {Status: planfile.StatusCompleted, Cost: 0.02, Timestamp: now},
+					"file:///b.java:2": {Status: planfile.StatusCompleted, Cost: 0.04, Timestamp: now},
+					"file:///c.java:3": {Status: planfile.StatusFailed, Cost: 0.50, Timestamp: now},
+				},
+			},
+			"never-fixed": {
+				Status: planfile.StatusFailed,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///d.java:4": {Status: planfile.StatusFailed, Cost: 0.10, Timestamp: now},
+				},
+			},
+		},
+	}
+}
+
+func TestNewCostHistory(t *testing.T) {
+	history := NewCostHistory(createHistoryState())
+
+	avg, ok := history.AverageCost("javax-to-jakarta")
+	assert.True(t, ok)
+	assert.InDelta(t, 0.03, avg, 1e-9, "failed incidents should not count toward the average")
+
+	_, ok = history.AverageCost("never-fixed")
+	assert.False(t, ok, "violations without completed incidents have no history")
+
+	var nilHistory *CostHistory
+	_, ok = nilHistory.AverageCost("javax-to-jakarta")
+	assert.False(t, ok)
+}
+
+func TestLoadCostHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.yaml")
+	state := createHistoryState()
+	state.Version = planfile.StateVersion
+	state.PlanFile = "plan.yaml"
+	state.StartedAt = time.Now()
+	state.UpdatedAt = state.StartedAt
+	require.NoError(t, planfile.SaveState(state, statePath))
+
+	history, err := LoadCostHistory([]string{statePath})
+	require.NoError(t, err)
+	avg, ok := history.AverageCost("javax-to-jakarta")
+	assert.True(t, ok)
+	assert.InDelta(t, 0.03, avg, 1e-9)
+
+	_, err = LoadCostHistory([]string{filepath.Join(tmpDir, "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestApplyCostHistory(t *testing.T) {
+	history := NewCostHistory(createHistoryState())
+
+	phases := []planfile.Phase{
+		{
+			ID:            "covered",
+			EstimatedCost: 5.00,
+			Violations: []planfile.PlannedViolation{
+				{ViolationID: "javax-to-jakarta", IncidentCount: 10},
+			},
+		},
+		{
+			ID:            "partial",
+			EstimatedCost: 1.00,
+			Violations: []planfile.PlannedViolation{
+				{ViolationID: "javax-to-jakarta", IncidentCount: 2},
+				{ViolationID: "logger-update", IncidentCount: 2},
+			},
+		},
+		{
+			ID:            "uncovered",
+			EstimatedCost: 0.75,
+			Violations: []planfile.PlannedViolation{
+				{ViolationID: "logger-update", IncidentCount: 3},
+			},
+		},
+	}
+
+	applyCostHistory(phases, history)
+
+	assert.InDelta(t, 0.30, phases[0].EstimatedCost, 1e-9)
+	assert.Equal(t, planfile.CostSourceHistory, phases[0].CostSource)
+
+	// 2 incidents at the historical 0.03, plus half the model estimate
+	assert.InDelta(t, 0.56, phases[1].EstimatedCost, 1e-9)
+	assert.Equal(t, planfile.CostSourceMixed, phases[1].CostSource)
+
+	assert.Equal(t, 0.75, phases[2].EstimatedCost)
+	assert.Equal(t, planfile.CostSourceModel, phases[2].CostSource)
+}
+
+func TestApplyCostHistory_NoHistory(t *testing.T) {
+	phases := []planfile.Phase{{ID: "phase-1", EstimatedCost: 0.50}}
+
+	applyCostHistory(phases, nil)
+
+	assert.Equal(t, 0.50, phases[0].EstimatedCost)
+	assert.Equal(t, planfile.CostSourceModel, phases[0].CostSource)
+}
+
+func TestGenerate_UsesCostHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	require.NoError(t, saveAnalysis(createTestAnalysis(), analysisPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("GeneratePlan", mock.Anything, mock.Anything).Return(
+		&provider.PlanResponse{
+			Phases: []provider.PlannedPhase{
+				{
+					ID:            "phase-1",
+					Name:          "Critical Mandatory Fixes",
+					Order:         1,
+					Risk:          "high",
+					Category:      "mandatory",
+					EffortRange:   [2]int{5, 7},
+					Explanation:   "High effort mandatory fixes",
+					ViolationIDs:  []string{"javax-to-jakarta"},
+					EstimatedCost: 0.50,
+				},
+			},
+		},
+		nil,
+	).Once()
+
+	p := New(Config{
+		AnalysisPath: analysisPath,
+		InputPath:    tmpDir,
+		Provider:     mockProvider,
+		OutputPath:   filepath.Join(tmpDir, "output"),
+		CostHistory:  NewCostHistory(createHistoryState()),
+	})
+
+	result, err := p.Generate(context.Background())
+	require.NoError(t, err)
+
+	// Two incidents at the historical average of 0.03 replace the model's 0.50
+	assert.InDelta(t, 0.06, result.TotalCost, 1e-9)
+
+	plan, err := planfile.LoadPlan(result.PlanPath)
+	require.NoError(t, err)
+	require.Len(t, plan.Phases, 1)
+	assert.Equal(t, planfile.CostSourceHistory, plan.Phases[0].CostSource)
+	assert.InDelta(t, 0.06, plan.Phases[0].EstimatedCost, 1e-9)
+}
@@ -0,0 +1,137 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// DiscoveredPhasePrefix starts the IDs of phases added for newly discovered
+// violations by a merge
+const DiscoveredPhasePrefix = "discovered"
+
+// mergeResult counts what mergeViolations changed
+type mergeResult struct {
+	newViolations int // Violations added to the plan
+	newPhases     int // Phases added for violations no phase fits
+}
+
+// mergeViolations adds the violations that aren't in plan yet, keeping its
+// phases, order and deferred flags as they are. A new violation joins the
+// first phase that isn't deferred, has its category and whose effort range
+// covers its effort; the rest get a new "Newly discovered" phase per
+// category at the end of the plan. Estimated costs and durations grow by the
+// per-incident averages of the phase joined, or of the whole plan.
+func mergeViolations(plan *planfile.Plan, violations []violation.Violation) mergeResult {
+	existing := make(map[string]bool)
+	for _, phase := range plan.Phases {
+		for _, v := range phase.Violations {
+			existing[v.ViolationID] = true
+		}
+	}
+	planCost, planMinutes := perIncidentEstimates(plan.Phases)
+
+	var result mergeResult
+	discovered := make(map[string]int) // Category -> index of its new phase
+	for _, v := range violations {
+		if existing[v.ID] {
+			continue
+		}
+		existing[v.ID] = true
+		result.newViolations++
+		planned := toPlannedViolation(v)
+
+		i := fittingPhase(plan.Phases, v)
+		if i >= 0 {
+			// Joining an existing phase leaves its risk and effort range as
+			// they are: the violation fits them
+			cost, minutes := perIncidentEstimates(plan.Phases[i : i+1])
+			addViolation(&plan.Phases[i], planned, cost, minutes)
+			continue
+		}
+
+		i, ok := discovered[v.Category]
+		if !ok {
+			plan.Phases = append(plan.Phases, discoveredPhase(plan, v.Category, v.Effort))
+			i = len(plan.Phases) - 1
+			discovered[v.Category] = i
+			result.newPhases++
+		}
+		phase := &plan.Phases[i]
+		phase.EffortRange = [2]int{min(phase.EffortRange[0], v.Effort), max(phase.EffortRange[1], v.Effort)}
+		if planfile.RiskRank(planfile.EffortRisk(v.Effort)) > planfile.RiskRank(phase.Risk) {
+			phase.Risk = planfile.EffortRisk(v.Effort)
+		}
+		addViolation(phase, planned, planCost, planMinutes)
+	}
+
+	plan.Metadata.TotalViolations += result.newViolations
+	return result
+}
+
+// fittingPhase returns the index of the first phase a new violation fits, or -1
+func fittingPhase(phases []planfile.Phase, v violation.Violation) int {
+	for i, phase := range phases {
+		if phase.Deferred || phase.Category != v.Category {
+			continue
+		}
+		if v.Effort >= phase.EffortRange[0] && v.Effort <= phase.EffortRange[1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// discoveredPhase returns an empty phase for newly discovered violations of a
+// category, starting with one of the given effort, ordered after every phase
+// of the plan
+func discoveredPhase(plan *planfile.Plan, category string, effort int) planfile.Phase {
+	ids := make(map[string]bool)
+	order := 0
+	for _, phase := range plan.Phases {
+		ids[phase.ID] = true
+		order = max(order, phase.Order)
+	}
+	id := fmt.Sprintf("%s-%s", DiscoveredPhasePrefix, category)
+	for n := 2; ids[id]; n++ {
+		id = fmt.Sprintf("%s-%s-%d", DiscoveredPhasePrefix, category, n)
+	}
+
+	return planfile.Phase{
+		ID:          id,
+		Name:        fmt.Sprintf("Newly discovered %s violations", category),
+		Order:       order + 1,
+		Risk:        planfile.EffortRisk(effort),
+		Category:    category,
+		EffortRange: [2]int{effort, effort},
+		Explanation: "Violations found by a later analysis that don't fit an existing phase",
+		Violations:  make([]planfile.PlannedViolation, 0),
+	}
+}
+
+// addViolation adds a violation to a phase, with the given estimated cost and
+// minutes per incident
+func addViolation(phase *planfile.Phase, planned planfile.PlannedViolation, cost, minutes float64) {
+	phase.Violations = append(phase.Violations, planned)
+	phase.EstimatedCost += cost * float64(planned.IncidentCount)
+	phase.EstimatedDurationMinutes += int(math.Ceil(minutes * float64(planned.IncidentCount)))
+}
+
+// perIncidentEstimates returns the average estimated cost and minutes per
+// incident over phases
+func perIncidentEstimates(phases []planfile.Phase) (cost, minutes float64) {
+	incidents := 0
+	for _, phase := range phases {
+		cost += phase.EstimatedCost
+		minutes += float64(phase.EstimatedDurationMinutes)
+		for _, v := range phase.Violations {
+			incidents += v.IncidentCount
+		}
+	}
+	if incidents == 0 {
+		return 0, 0
+	}
+	return cost / float64(incidents), minutes / float64(incidents)
+}
@@ -0,0 +1,113 @@
+package planner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func mergeTestPlan() *planfile.Plan {
+	plan := planfile.NewPlan("claude", 2)
+	plan.Phases = []planfile.Phase{
+		{
+			ID: "phase-2", Name: "Reordered first", Order: 1, Risk: planfile.RiskLow,
+			Category: "mandatory", EffortRange: [2]int{1, 3}, EstimatedCost: 0.20, EstimatedDurationMinutes: 4,
+			Violations: []planfile.PlannedViolation{
+				{ViolationID: "v-imports", Description: "imports", Category: "mandatory", Effort: 1, IncidentCount: 2,
+					Incidents: []violation.Incident{{URI: "file:///A.java"}, {URI: "file:///B.java"}}},
+			},
+		},
+		{
+			ID: "phase-1", Name: "Deferred", Order: 2, Risk: planfile.RiskMedium,
+			Category: "optional", EffortRange: [2]int{1, 5}, EstimatedCost: 1.00, Deferred: true,
+			Violations: []planfile.PlannedViolation{
+				{ViolationID: "v-logging", Description: "logging", Category: "optional", Effort: 3, IncidentCount: 1,
+					Incidents: []violation.Incident{{URI: "file:///C.java"}}},
+			},
+		},
+	}
+	return plan
+}
+
+func TestMergeViolations(t *testing.T) {
+	plan := mergeTestPlan()
+	incident := []violation.Incident{{URI: "file:///D.java"}}
+	violations := []violation.Violation{
+		{ID: "v-imports", Description: "d", Category: "mandatory", Effort: 1, Incidents: incident},                  // already planned
+		{ID: "v-annotations", Description: "d", Category: "mandatory", Effort: 2, Incidents: incident},              // fits phase-2
+		{ID: "v-config", Description: "d", Category: "optional", Effort: 2, Incidents: incident},                    // phase-1 is deferred
+		{ID: "v-ejb", Description: "d", Category: "mandatory", Effort: 8, Incidents: incident},                      // beyond phase-2's effort
+		{ID: "v-jndi", Description: "d", Category: "optional", Effort: 4, Incidents: append(incident, incident...)}, // joins v-config
+	}
+
+	merged := mergeViolations(plan, violations)
+
+	assert.Equal(t, 4, merged.newViolations)
+	assert.Equal(t, 2, merged.newPhases)
+	assert.Equal(t, 6, plan.Metadata.TotalViolations)
+	require.Len(t, plan.Phases, 4)
+
+	// Existing phases keep their order, flags and risk
+	assert.Equal(t, "phase-2", plan.Phases[0].ID)
+	assert.Equal(t, planfile.RiskLow, plan.Phases[0].Risk)
+	assert.Len(t, plan.Phases[0].Violations, 2)
+	assert.Equal(t, "v-annotations", plan.Phases[0].Violations[1].ViolationID)
+	assert.InDelta(t, 0.30, plan.Phases[0].EstimatedCost, 1e-9, "grows by the phase's cost per incident")
+	assert.True(t, plan.Phases[1].Deferred)
+	assert.Len(t, plan.Phases[1].Violations, 1)
+
+	// New phases per category, in the order the violations came
+	config := plan.Phases[2]
+	assert.Equal(t, "discovered-optional", config.ID)
+	assert.Equal(t, 3, config.Order)
+	assert.Equal(t, planfile.RiskMedium, config.Risk)
+	assert.Equal(t, [2]int{2, 4}, config.EffortRange)
+	require.Len(t, config.Violations, 2)
+	assert.Equal(t, 2, config.Violations[1].IncidentCount)
+
+	ejb := plan.Phases[3]
+	assert.Equal(t, "discovered-mandatory", ejb.ID)
+	assert.Equal(t, 4, ejb.Order)
+	assert.Equal(t, planfile.RiskHigh, ejb.Risk)
+	assert.Equal(t, [2]int{8, 8}, ejb.EffortRange)
+
+	assert.NoError(t, planfile.ValidatePlan(plan))
+}
+
+func TestMergeViolations_UniquePhaseIDs(t *testing.T) {
+	plan := mergeTestPlan()
+	mergeViolations(plan, []violation.Violation{{ID: "v-ejb", Category: "mandatory", Effort: 8}})
+	mergeViolations(plan, []violation.Violation{{ID: "v-cdi", Category: "mandatory", Effort: 8}})
+
+	require.Len(t, plan.Phases, 3, "a later merge joins the discovered phase")
+	mergeViolations(plan, []violation.Violation{{ID: "v-jms", Category: "mandatory", Effort: 5}})
+	require.Len(t, plan.Phases, 4)
+	assert.Equal(t, "discovered-mandatory-2", plan.Phases[3].ID)
+	assert.Equal(t, 4, plan.Phases[3].Order)
+}
+
+func TestGenerate_MergeInto(t *testing.T) {
+	tmpDir := t.TempDir()
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	require.NoError(t, saveAnalysis(createTestAnalysis(), analysisPath))
+
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	require.NoError(t, planfile.SavePlan(mergeTestPlan(), planPath))
+
+	// No provider: merging doesn't call the model
+	result, err := New(Config{AnalysisPath: analysisPath, MergeInto: planPath}).Generate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, planPath, result.PlanPath)
+	assert.Positive(t, result.NewViolations)
+
+	saved, err := planfile.LoadPlan(planPath)
+	require.NoError(t, err)
+	assert.Equal(t, "phase-2", saved.Phases[0].ID)
+	assert.True(t, saved.Phases[1].Deferred)
+	assert.Len(t, saved.Phases, result.TotalPhases)
+}
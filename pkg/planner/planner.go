@@ -3,6 +3,7 @@ package planner
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
@@ -19,12 +20,15 @@ type Planner struct {
 }
 
 // New creates a new Planner with the given configuration.
-// It sets default values for OutputPath and RiskTolerance if not provided.
+// It sets default values for OutputPath, PlanFileName and RiskTolerance if not provided.
 func New(config Config) *Planner {
 	// Set defaults
 	if config.OutputPath == "" {
 		config.OutputPath = ".kantra-ai-plan"
 	}
+	if config.PlanFileName == "" {
+		config.PlanFileName = "plan.yaml"
+	}
 	if config.RiskTolerance == "" {
 		config.RiskTolerance = "balanced"
 	}
@@ -38,6 +42,10 @@ func New(config Config) *Planner {
 // filtering based on configuration, and using the AI provider to group
 // violations into phases with risk assessment and explanations.
 // If Interactive mode is enabled, prompts the user to approve/defer each phase.
+// With MergeInto, violations that aren't in that plan yet are added to it
+// instead, keeping its phases, order and deferred flags (see mergeViolations).
+// With Estimate, the phases are grouped heuristically without calling the
+// provider (see heuristicPlan), so the plan costs nothing to generate.
 func (p *Planner) Generate(ctx context.Context) (*Result, error) {
 	// Load violations from analysis file
 	analysis, err := violation.LoadAnalysis(p.config.AnalysisPath)
@@ -47,27 +55,54 @@ func (p *Planner) Generate(ctx context.Context) (*Result, error) {
 
 	// Apply filters using the Analysis method
 	filtered := analysis.FilterViolations(p.config.ViolationIDs, p.config.Categories, p.config.MaxEffort)
+	filtered = p.config.PathFilter.Apply(filtered)
 	if len(filtered) == 0 {
 		return nil, fmt.Errorf("no violations match the specified filters")
 	}
 
+	if p.config.MergeInto != "" {
+		return p.merge(filtered)
+	}
+
 	// Call AI provider to generate plan
 	planReq := provider.PlanRequest{
 		Violations:    filtered,
 		MaxPhases:     p.config.MaxPhases,
 		RiskTolerance: p.config.RiskTolerance,
+		BatchSize:     p.config.BatchSize,
+		BatchDelay:    p.config.BatchDelay,
+		Progress:      p.config.Progress,
 	}
 
-	planResp, err := p.config.Provider.GeneratePlan(ctx, planReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate plan: %w", err)
-	}
-	if planResp.Error != nil {
-		return nil, planResp.Error
+	var planResp *provider.PlanResponse
+	if p.config.Estimate {
+		planResp = p.heuristicPlan(filtered)
+	} else {
+		planResp, err = p.config.Provider.GeneratePlan(ctx, planReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate plan: %w", err)
+		}
+		if planResp.Error != nil {
+			return nil, planResp.Error
+		}
 	}
 
 	// Convert provider response to planfile.Plan
 	plan := p.buildPlan(planResp, filtered)
+	plan.Metadata.Heuristic = p.config.Estimate
+
+	// Keep violations that touch the same files together
+	if p.config.GroupBy == GroupByFileOverlap {
+		plan.Phases = groupByFileOverlap(plan.Phases)
+	}
+
+	// Split phases that are too large to review comfortably
+	if p.config.MaxPhaseViolations > 0 {
+		plan.Phases = splitOversizedPhases(plan.Phases, p.config.MaxPhaseViolations)
+	}
+
+	// Prefer costs observed in earlier executions over the model's guesses
+	applyCostHistory(plan.Phases, p.config.CostHistory)
 
 	// Run interactive approval if enabled
 	if p.config.Interactive {
@@ -82,8 +117,8 @@ func (p *Planner) Generate(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// OutputPath is now a directory, save plan.yaml inside it
-	planPath := filepath.Join(p.config.OutputPath, "plan.yaml")
+	// OutputPath is now a directory, save the plan inside it
+	planPath := filepath.Join(p.config.OutputPath, p.config.PlanFileName)
 	if err := planfile.SavePlan(plan, planPath); err != nil {
 		return nil, fmt.Errorf("failed to save plan: %w", err)
 	}
@@ -95,6 +130,32 @@ func (p *Planner) Generate(ctx context.Context) (*Result, error) {
 		TotalCost:    plan.GetTotalCost(),
 		TokensUsed:   planResp.TokensUsed,
 		GenerateCost: planResp.Cost,
+		Heuristic:    p.config.Estimate,
+	}, nil
+}
+
+// merge adds the violations that aren't in the MergeInto plan yet to it and
+// saves it in place, without asking the provider
+func (p *Planner) merge(violations []violation.Violation) (*Result, error) {
+	plan, err := planfile.LoadPlan(p.config.MergeInto)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeViolations(plan, violations)
+	if merged.newViolations > 0 {
+		if err := planfile.SavePlan(plan, p.config.MergeInto); err != nil {
+			return nil, fmt.Errorf("failed to save plan: %w", err)
+		}
+	}
+
+	return &Result{
+		Plan:          plan,
+		PlanPath:      p.config.MergeInto,
+		TotalPhases:   len(plan.Phases),
+		TotalCost:     plan.GetTotalCost(),
+		NewViolations: merged.newViolations,
+		NewPhases:     merged.newPhases,
 	}, nil
 }
 
@@ -129,17 +190,7 @@ func (p *Planner) buildPlan(resp *provider.PlanResponse, violations []violation.
 		// Add violations to phase
 		for _, violationID := range providerPhase.ViolationIDs {
 			if v, ok := violationMap[violationID]; ok {
-				plannedViolation := planfile.PlannedViolation{
-					ViolationID:         v.ID,
-					Description:         v.Description,
-					Category:            v.Category,
-					Effort:              v.Effort,
-					MigrationComplexity: v.MigrationComplexity,
-					ManualReviewRequired: isHighComplexity(v.MigrationComplexity, v.Effort),
-					IncidentCount:       len(v.Incidents),
-					Incidents:           v.Incidents,
-				}
-				phase.Violations = append(phase.Violations, plannedViolation)
+				phase.Violations = append(phase.Violations, toPlannedViolation(v))
 			}
 		}
 
@@ -149,6 +200,63 @@ func (p *Planner) buildPlan(resp *provider.PlanResponse, violations []violation.
 	return plan
 }
 
+// toPlannedViolation converts an analysis violation for a plan
+func toPlannedViolation(v violation.Violation) planfile.PlannedViolation {
+	return planfile.PlannedViolation{
+		ViolationID:          v.ID,
+		Description:          v.Description,
+		Category:             v.Category,
+		Effort:               v.Effort,
+		MigrationComplexity:  v.MigrationComplexity,
+		ManualReviewRequired: isHighComplexity(v.MigrationComplexity, v.Effort),
+		IncidentCount:        len(v.Incidents),
+		Incidents:            v.Incidents,
+	}
+}
+
+// splitOversizedPhases splits any phase with more than maxViolations violations
+// into sequential sub-phases. Violation order, category and risk are preserved,
+// estimated cost and duration are divided proportionally, and phase orders are
+// renumbered so the sub-phases run back to back.
+func splitOversizedPhases(phases []planfile.Phase, maxViolations int) []planfile.Phase {
+	if maxViolations <= 0 {
+		return phases
+	}
+
+	result := make([]planfile.Phase, 0, len(phases))
+	for _, phase := range phases {
+		total := len(phase.Violations)
+		if total <= maxViolations {
+			result = append(result, phase)
+			continue
+		}
+
+		parts := (total + maxViolations - 1) / maxViolations
+		for i := 0; i < parts; i++ {
+			start := i * maxViolations
+			end := start + maxViolations
+			if end > total {
+				end = total
+			}
+			share := float64(end-start) / float64(total)
+
+			sub := phase
+			sub.ID = fmt.Sprintf("%s-%d", phase.ID, i+1)
+			sub.Name = fmt.Sprintf("%s (part %d/%d)", phase.Name, i+1, parts)
+			sub.Violations = append([]planfile.PlannedViolation(nil), phase.Violations[start:end]...)
+			sub.EstimatedCost = phase.EstimatedCost * share
+			sub.EstimatedDurationMinutes = int(math.Ceil(float64(phase.EstimatedDurationMinutes) * share))
+			result = append(result, sub)
+		}
+	}
+
+	for i := range result {
+		result[i].Order = i + 1
+	}
+
+	return result
+}
+
 // mapRiskLevel converts a string risk level ("low", "medium", "high")
 // to a planfile.RiskLevel constant. Returns RiskMedium as default for unknown values.
 func mapRiskLevel(risk string) planfile.RiskLevel {
@@ -2,14 +2,18 @@ package planner
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/ux"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 	"gopkg.in/yaml.v3"
 )
@@ -58,6 +62,7 @@ func TestNew(t *testing.T) {
 
 	assert.NotNil(t, p)
 	assert.Equal(t, ".kantra-ai-plan", p.config.OutputPath)
+	assert.Equal(t, "plan.yaml", p.config.PlanFileName)
 	assert.Equal(t, "balanced", p.config.RiskTolerance)
 }
 
@@ -147,6 +152,38 @@ func TestGenerate_BasicFlow(t *testing.T) {
 	mockProvider.AssertExpectations(t)
 }
 
+func TestGenerate_ConfiguredPlanFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	require.NoError(t, saveAnalysis(createTestAnalysis(), analysisPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("GeneratePlan", mock.Anything, mock.Anything).Return(
+		&provider.PlanResponse{
+			Phases: []provider.PlannedPhase{
+				{ID: "phase-1", Name: "Fixes", Order: 1, Risk: "low", Category: "mandatory", ViolationIDs: []string{"javax-to-jakarta"}},
+			},
+		},
+		nil,
+	).Once()
+
+	// paths.plan: .kantra-ai/plans/migration.yaml
+	outputDir := filepath.Join(tmpDir, ".kantra-ai", "plans")
+	p := New(Config{
+		AnalysisPath: analysisPath,
+		InputPath:    tmpDir,
+		Provider:     mockProvider,
+		OutputPath:   outputDir,
+		PlanFileName: "migration.yaml",
+	})
+
+	result, err := p.Generate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, "migration.yaml"), result.PlanPath)
+	assert.FileExists(t, result.PlanPath)
+}
+
 func TestGenerate_WithFilters(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "planner-test-*")
 	assert.NoError(t, err)
@@ -203,6 +240,34 @@ func TestGenerate_WithFilters(t *testing.T) {
 	mockProvider.AssertExpectations(t)
 }
 
+func TestGenerate_BatchingAndProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	analysisPath := filepath.Join(tmpDir, "analysis.yaml")
+	require.NoError(t, saveAnalysis(createTestAnalysis(), analysisPath))
+
+	delay := time.Duration(0)
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("GeneratePlan", mock.Anything, mock.MatchedBy(func(req provider.PlanRequest) bool {
+		return req.BatchSize == 50 && req.BatchDelay != nil && *req.BatchDelay == 0 && req.Progress != nil
+	})).Return(&provider.PlanResponse{
+		Phases: []provider.PlannedPhase{
+			{ID: "phase-1", Name: "Jakarta", Order: 1, Risk: "high", Category: "mandatory", ViolationIDs: []string{"javax-to-jakarta"}},
+		},
+	}, nil).Once()
+
+	_, err := New(Config{
+		AnalysisPath: analysisPath,
+		Provider:     mockProvider,
+		OutputPath:   filepath.Join(tmpDir, "output"),
+		BatchSize:    50,
+		BatchDelay:   &delay,
+		Progress:     &ux.NoOpProgressWriter{},
+	}).Generate(context.Background())
+	require.NoError(t, err)
+	mockProvider.AssertExpectations(t)
+}
+
 func TestGenerate_ProviderError(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "planner-test-*")
 	assert.NoError(t, err)
@@ -504,3 +569,74 @@ func TestGenerate_AllViolationsFilteredOut(t *testing.T) {
 
 	mockProvider.AssertNotCalled(t, "GeneratePlan")
 }
+
+func TestSplitOversizedPhases(t *testing.T) {
+	makeViolations := func(n int) []planfile.PlannedViolation {
+		violations := make([]planfile.PlannedViolation, n)
+		for i := range violations {
+			violations[i] = planfile.PlannedViolation{ViolationID: fmt.Sprintf("v%02d", i+1)}
+		}
+		return violations
+	}
+
+	phases := []planfile.Phase{
+		{
+			ID:                       "phase-1",
+			Name:                     "Small Phase",
+			Order:                    1,
+			Risk:                     planfile.RiskLow,
+			Category:                 "optional",
+			Violations:               makeViolations(2),
+			EstimatedCost:            0.10,
+			EstimatedDurationMinutes: 5,
+		},
+		{
+			ID:                       "phase-2",
+			Name:                     "Huge Phase",
+			Order:                    2,
+			Risk:                     planfile.RiskHigh,
+			Category:                 "mandatory",
+			Violations:               makeViolations(12),
+			EstimatedCost:            1.20,
+			EstimatedDurationMinutes: 60,
+		},
+	}
+
+	result := splitOversizedPhases(phases, 5)
+
+	// 1 untouched phase + ceil(12/5) = 3 sub-phases
+	assert.Len(t, result, 4)
+	assert.Equal(t, "phase-1", result[0].ID)
+	assert.Len(t, result[0].Violations, 2)
+
+	expectedSizes := []int{5, 5, 2}
+	var ids []string
+	for i, sub := range result[1:] {
+		assert.Equal(t, fmt.Sprintf("phase-2-%d", i+1), sub.ID)
+		assert.Equal(t, fmt.Sprintf("Huge Phase (part %d/3)", i+1), sub.Name)
+		assert.Equal(t, planfile.RiskHigh, sub.Risk)
+		assert.Equal(t, "mandatory", sub.Category)
+		assert.Len(t, sub.Violations, expectedSizes[i])
+		for _, v := range sub.Violations {
+			ids = append(ids, v.ViolationID)
+		}
+	}
+
+	// Violation order is preserved across sub-phases
+	var expectedIDs []string
+	for _, v := range phases[1].Violations {
+		expectedIDs = append(expectedIDs, v.ViolationID)
+	}
+	assert.Equal(t, expectedIDs, ids)
+
+	// Orders are sequential and cost is divided proportionally
+	for i, phase := range result {
+		assert.Equal(t, i+1, phase.Order)
+	}
+	assert.InDelta(t, 0.50, result[1].EstimatedCost, 0.0001)
+	assert.InDelta(t, 0.20, result[3].EstimatedCost, 0.0001)
+	assert.Equal(t, 25, result[1].EstimatedDurationMinutes)
+
+	// No limit leaves phases untouched
+	assert.Equal(t, phases, splitOversizedPhases(phases, 0))
+}
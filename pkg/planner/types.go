@@ -6,8 +6,12 @@
 package planner
 
 import (
+	"time"
+
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
 // Config holds configuration for plan generation.
@@ -15,13 +19,29 @@ type Config struct {
 	AnalysisPath  string   // Path to Konveyor output.yaml
 	InputPath     string   // Path to source code directory
 	Provider      provider.Provider
-	OutputPath    string   // Where to save the plan (default: .kantra-ai-plan.yaml)
+	OutputPath    string   // Directory to save the plan in (default: .kantra-ai-plan)
+	PlanFileName  string   // Name of the plan file in OutputPath (default: plan.yaml)
 	MaxPhases     int      // Maximum number of phases (0 = auto)
 	RiskTolerance string   // conservative | balanced | aggressive
 	Categories    []string // Filter by categories
 	ViolationIDs  []string // Filter by violation IDs
 	MaxEffort     int      // Only include violations with effort <= this value
 	Interactive   bool     // Enable interactive approval mode
+	PathFilter    *violation.PathFilter // Filter incidents by file path (nil = no filtering)
+
+	GroupBy            string // Regroup the AI's phases: "" (as proposed) or file-overlap
+	MaxPhaseViolations int // Split phases with more violations than this into sub-phases (0 = no limit)
+
+	BatchSize  int            // Violations per planning request for providers that batch them (0 = provider default)
+	BatchDelay *time.Duration // Delay between planning batches (nil = provider default, 0 = none)
+
+	Progress ux.ProgressWriter // Receives plan generation progress, e.g. for a web UI (nil = progress bar on stdout)
+
+	CostHistory *CostHistory // Historical per-incident costs used instead of model estimates (nil = model only)
+
+	Estimate bool // Group violations heuristically by category and effort instead of calling the model (see heuristicPlan)
+
+	MergeInto string // Add violations that aren't in this existing plan to it instead of generating a plan (see Generate)
 }
 
 // Result contains the result of plan generation with cost and phase metrics.
@@ -31,5 +51,9 @@ type Result struct {
 	TotalPhases  int            // Number of phases generated
 	TotalCost    float64        // Estimated total cost
 	TokensUsed   int            // Tokens consumed for plan generation
-	GenerateCost float64        // Cost to generate the plan
+	GenerateCost float64        // Cost to generate the plan (0 with Estimate)
+	Heuristic    bool           // The plan was estimated without calling the model
+
+	NewViolations int // With MergeInto: violations added to the existing plan
+	NewPhases     int // With MergeInto: phases added for new violations that fit no existing phase
 }
@@ -35,7 +35,8 @@ Rule Message: {{.RuleMessage}}
 
 FILE LOCATION:
 File: {{.File}}
-Line: {{.Line}}
+Line: {{.Line}}{{if .Column}}
+Column: {{.Column}}{{end}}
 
 CURRENT CODE SNIPPET:
 {{.CodeSnippet}}
@@ -81,7 +82,8 @@ Fix the following {{.IncidentCount}} incident(s):
 {{range .Incidents}}
 INCIDENT {{.Index}}:
 File: {{.File}}
-Line: {{.Line}}
+Line: {{.Line}}{{if .Column}}
+Column: {{.Column}}{{end}}
 Issue: {{.Message}}
 {{if .CodeContext}}
 {{.CodeContext}}
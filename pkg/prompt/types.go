@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 )
 
@@ -48,8 +50,17 @@ type Config struct {
 	BatchFixPath  string
 	// Language-specific template overrides (optional)
 	LanguageTemplates map[string]LanguagePaths
+	// Directory of template files overriding the built-in templates by name
+	// (optional, see TemplateFileExt). The paths above take precedence.
+	Dir string
 }
 
+// TemplateFileExt is the extension of template files in Config.Dir. A file
+// is named after the template it overrides: single-fix.tmpl, batch-fix.tmpl,
+// or single-fix-<language>.tmpl and batch-fix-<language>.tmpl for one
+// language, e.g. single-fix-java.tmpl. Other files are ignored.
+const TemplateFileExt = ".tmpl"
+
 // LanguagePaths holds template paths for a specific language
 type LanguagePaths struct {
 	SingleFixPath string
@@ -64,6 +75,7 @@ type SingleFixData struct {
 	RuleMessage    string
 	File           string
 	Line           int
+	Column         int // 1-based; 0 if unknown
 	CodeSnippet    string
 	FileContent    string
 	Language       string
@@ -84,6 +96,7 @@ type BatchIncident struct {
 	Index       int    // 1-based index
 	File        string
 	Line        int
+	Column      int // 1-based; 0 if unknown
 	Message     string
 	CodeContext string
 }
@@ -94,6 +107,11 @@ func Load(cfg Config) (*Templates, error) {
 		languageTemplates: make(map[string]*LanguageTemplates),
 	}
 
+	dirTemplates, err := loadDir(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load base single fix template (fallback)
 	if cfg.SingleFixPath != "" {
 		tmpl, err := loadFromFile(cfg.SingleFixPath, "single-fix")
@@ -101,6 +119,8 @@ func Load(cfg Config) (*Templates, error) {
 			return nil, fmt.Errorf("failed to load single-fix template: %w", err)
 		}
 		templates.SingleFix = tmpl
+	} else if tmpl, ok := dirTemplates[string(SingleFixTemplate)]; ok {
+		templates.SingleFix = tmpl
 	} else {
 		templates.SingleFix = getDefaultSingleFixTemplate(cfg.Provider)
 	}
@@ -112,6 +132,8 @@ func Load(cfg Config) (*Templates, error) {
 			return nil, fmt.Errorf("failed to load batch-fix template: %w", err)
 		}
 		templates.BatchFix = tmpl
+	} else if tmpl, ok := dirTemplates[string(BatchFixTemplate)]; ok {
+		templates.BatchFix = tmpl
 	} else {
 		templates.BatchFix = getDefaultBatchFixTemplate(cfg.Provider)
 	}
@@ -124,9 +146,31 @@ func Load(cfg Config) (*Templates, error) {
 		return nil, fmt.Errorf("failed to compile batch-fix template: %w", err)
 	}
 
+	// Language-specific templates from the directory, which the paths below
+	// override
+	for name, tmpl := range dirTemplates {
+		templateType, lang := splitTemplateName(name)
+		if lang == "" {
+			continue
+		}
+		langTemplates, ok := templates.languageTemplates[lang]
+		if !ok {
+			langTemplates = &LanguageTemplates{}
+			templates.languageTemplates[lang] = langTemplates
+		}
+		if templateType == SingleFixTemplate {
+			langTemplates.SingleFix = tmpl
+		} else {
+			langTemplates.BatchFix = tmpl
+		}
+	}
+
 	// Load language-specific templates
 	for lang, paths := range cfg.LanguageTemplates {
-		langTemplates := &LanguageTemplates{}
+		langTemplates, ok := templates.languageTemplates[lang]
+		if !ok {
+			langTemplates = &LanguageTemplates{}
+		}
 
 		// Load language-specific single-fix template
 		if paths.SingleFixPath != "" {
@@ -161,6 +205,54 @@ func Load(cfg Config) (*Templates, error) {
 	return templates, nil
 }
 
+// loadDir loads and compiles the template files of a Config.Dir by template
+// name, e.g. "single-fix" or "batch-fix-java". An empty dir has none.
+func loadDir(dir string) (map[string]*Template, error) {
+	templates := make(map[string]*Template)
+	if dir == "" {
+		return templates, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != TemplateFileExt {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), TemplateFileExt)
+		if templateType, _ := splitTemplateName(name); templateType == "" {
+			return nil, fmt.Errorf("unknown prompt template %s in %s (expected %s, %s, or %s-<language> or %s-<language>, with the %s extension)",
+				entry.Name(), dir, SingleFixTemplate, BatchFixTemplate, SingleFixTemplate, BatchFixTemplate, TemplateFileExt)
+		}
+		path := filepath.Join(dir, entry.Name())
+		tmpl, err := loadFromFile(path, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s template: %w", name, err)
+		}
+		// Every file must parse, even one the configured paths override
+		if err := tmpl.compile(); err != nil {
+			return nil, fmt.Errorf("failed to compile %s: %w", path, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// splitTemplateName splits a template file name into its template type and
+// language ("" for a base template). The type is "" for an unknown name.
+func splitTemplateName(name string) (TemplateType, string) {
+	for _, templateType := range []TemplateType{SingleFixTemplate, BatchFixTemplate} {
+		if name == string(templateType) {
+			return templateType, ""
+		}
+		if lang, ok := strings.CutPrefix(name, string(templateType)+"-"); ok && lang != "" {
+			return templateType, lang
+		}
+	}
+	return "", ""
+}
+
 // loadFromFile loads a template from a file
 func loadFromFile(path string, name string) (*Template, error) {
 	content, err := os.ReadFile(path)
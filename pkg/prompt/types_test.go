@@ -3,6 +3,7 @@ package prompt
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -153,6 +154,73 @@ func TestLoad_LanguageSpecificTemplates(t *testing.T) {
 	})
 }
 
+func TestLoad_Dir(t *testing.T) {
+	writeDir := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		return dir
+	}
+
+	t.Run("overrides built-in templates by name", func(t *testing.T) {
+		dir := writeDir(t, map[string]string{
+			"single-fix.tmpl":      "Org standards: {{.File}}",
+			"batch-fix-java.tmpl":  "Java batch: {{.ViolationID}}",
+			"README.md":            "not a template",
+			"single-fix.tmpl.orig": "ignored",
+		})
+
+		templates, err := Load(Config{Provider: "claude", Dir: dir})
+		require.NoError(t, err)
+		assert.Equal(t, "Org standards: {{.File}}", templates.SingleFix.Content)
+		assert.Contains(t, templates.BatchFix.Content, "code modernization assistant", "no batch-fix.tmpl: built-in")
+		assert.Equal(t, "Java batch: {{.ViolationID}}", templates.GetBatchFixTemplate("java").Content)
+		assert.Equal(t, templates.SingleFix, templates.GetSingleFixTemplate("java"), "no single-fix-java.tmpl: base")
+
+		prompt, err := templates.SingleFix.RenderSingleFix(SingleFixData{File: "App.java"})
+		require.NoError(t, err)
+		assert.Equal(t, "Org standards: App.java", prompt)
+	})
+
+	t.Run("configured paths take precedence", func(t *testing.T) {
+		dir := writeDir(t, map[string]string{
+			"single-fix.tmpl":      "From dir",
+			"single-fix-java.tmpl": "Java from dir",
+			"batch-fix-java.tmpl":  "Java batch from dir",
+		})
+		javaPath := filepath.Join(t.TempDir(), "java.txt")
+		require.NoError(t, os.WriteFile(javaPath, []byte("Java from path"), 0644))
+
+		templates, err := Load(Config{
+			Provider:          "claude",
+			Dir:               dir,
+			LanguageTemplates: map[string]LanguagePaths{"java": {SingleFixPath: javaPath}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "From dir", templates.SingleFix.Content)
+		assert.Equal(t, "Java from path", templates.GetSingleFixTemplate("java").Content)
+		assert.Equal(t, "Java batch from dir", templates.GetBatchFixTemplate("java").Content)
+	})
+
+	t.Run("unknown template name", func(t *testing.T) {
+		dir := writeDir(t, map[string]string{"single_fix.tmpl": "{{.File}}"})
+		_, err := Load(Config{Provider: "claude", Dir: dir})
+		assert.ErrorContains(t, err, "unknown prompt template single_fix.tmpl")
+	})
+
+	t.Run("template that doesn't parse", func(t *testing.T) {
+		dir := writeDir(t, map[string]string{"batch-fix-python.tmpl": "{{.ViolationID"})
+		_, err := Load(Config{Provider: "claude", Dir: dir})
+		assert.ErrorContains(t, err, "failed to compile "+filepath.Join(dir, "batch-fix-python.tmpl"))
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := Load(Config{Provider: "claude", Dir: filepath.Join(t.TempDir(), "prompts")})
+		assert.ErrorContains(t, err, "failed to read prompt directory")
+	})
+}
+
 func TestGetSingleFixTemplate(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -498,3 +566,35 @@ func TestLoad_MixedConfiguration(t *testing.T) {
 		assert.Equal(t, baseBatch, batchTmpl.Content)
 	})
 }
+
+func TestDefaultTemplates_Column(t *testing.T) {
+	templates, err := Load(Config{Provider: "claude"})
+	require.NoError(t, err)
+
+	t.Run("single fix includes column when known", func(t *testing.T) {
+		result, err := templates.SingleFix.RenderSingleFix(SingleFixData{File: "Main.java", Line: 12, Column: 8})
+		require.NoError(t, err)
+		assert.Contains(t, result, "Line: 12\nColumn: 8\n")
+	})
+
+	t.Run("single fix omits column when unknown", func(t *testing.T) {
+		result, err := templates.SingleFix.RenderSingleFix(SingleFixData{File: "Main.java", Line: 12})
+		require.NoError(t, err)
+		assert.Contains(t, result, "Line: 12\n")
+		assert.NotContains(t, result, "Column:")
+	})
+
+	t.Run("batch fix includes column per incident", func(t *testing.T) {
+		result, err := templates.BatchFix.RenderBatchFix(BatchFixData{
+			IncidentCount: 2,
+			Incidents: []BatchIncident{
+				{Index: 1, File: "A.java", Line: 3, Column: 5},
+				{Index: 2, File: "B.java", Line: 7},
+			},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result, "Line: 3\nColumn: 5\n")
+		assert.Contains(t, result, "Line: 7\nIssue:")
+		assert.Equal(t, 1, strings.Count(result, "Column:"))
+	})
+}
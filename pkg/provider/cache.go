@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+)
+
+// CacheKeyMode selects what identifies a cached fix
+type CacheKeyMode string
+
+const (
+	// CacheKeyPrompt keys on the complete request: any change to the prompt
+	// inputs (messages, descriptions, labels, code) is a miss
+	CacheKeyPrompt CacheKeyMode = "prompt"
+	// CacheKeyContent keys on the normalized file content, incident line,
+	// violation ID and model, so requests for identical code hit the cache
+	// even when non-code prompt metadata changed
+	CacheKeyContent CacheKeyMode = "content"
+)
+
+// ParseCacheKeyMode parses a cache key mode ("" defaults to prompt)
+func ParseCacheKeyMode(s string) (CacheKeyMode, error) {
+	switch CacheKeyMode(s) {
+	case "", CacheKeyPrompt:
+		return CacheKeyPrompt, nil
+	case CacheKeyContent:
+		return CacheKeyContent, nil
+	default:
+		return "", fmt.Errorf("invalid cache key: %s (valid: prompt, content)", s)
+	}
+}
+
+// cachedFix is the stored part of a successful FixResponse
+type cachedFix struct {
+	FixedContent string                 `json:"fixed_content,omitempty"`
+	Patch        string                 `json:"patch,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Confidence   float64                `json:"confidence"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
+}
+
+// cachedBatchFix is the stored part of one fix of a successful BatchResponse
+type cachedBatchFix struct {
+	IncidentURI  string                 `json:"incident_uri"`
+	FixedContent string                 `json:"fixed_content,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Confidence   float64                `json:"confidence"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
+}
+
+// CachingProvider wraps a provider and serves repeated fixes and batches from
+// a ResponseCache. Cache hits cost nothing and use no tokens. Only successful
+// fixes, and batches whose fixes all succeeded, are cached; plans go straight
+// to the wrapped provider.
+type CachingProvider struct {
+	Provider
+	cache   *common.ResponseCache
+	config  Config
+	keyMode CacheKeyMode
+
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+// NewCachingProvider wraps p with cache. config is the wrapped provider's
+// configuration; its model, temperature, response format and extra fields
+// are part of every key.
+func NewCachingProvider(p Provider, cache *common.ResponseCache, config Config, keyMode CacheKeyMode) *CachingProvider {
+	if keyMode == "" {
+		keyMode = CacheKeyPrompt
+	}
+	return &CachingProvider{
+		Provider: p,
+		cache:    cache,
+		config:   config,
+		keyMode:  keyMode,
+	}
+}
+
+// FixViolation returns the cached fix for the request, or asks the wrapped
+// provider and caches a successful result
+func (c *CachingProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	key := c.cacheKey(req)
+
+	var cached cachedFix
+	if c.cache.Get(key, &cached) {
+		c.record(true, 1)
+		return &FixResponse{
+			Success:      true,
+			FixedContent: cached.FixedContent,
+			Patch:        cached.Patch,
+			Explanation:  cached.Explanation,
+			Confidence:   cached.Confidence,
+			Extra:        cached.Extra,
+		}, nil
+	}
+	c.record(false, 1)
+
+	resp, err := c.Provider.FixViolation(ctx, req)
+	if err != nil || resp == nil || !resp.Success || resp.Error != nil {
+		return resp, err
+	}
+
+	// A failed write only costs a future cache miss
+	_ = c.cache.Put(key, cachedFix{
+		FixedContent: resp.FixedContent,
+		Patch:        resp.Patch,
+		Explanation:  resp.Explanation,
+		Confidence:   resp.Confidence,
+		Extra:        resp.Extra,
+	})
+	return resp, nil
+}
+
+// FixBatch returns the cached fixes for the batch, or asks the wrapped
+// provider and caches the response if every fix succeeded
+func (c *CachingProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	key := c.batchCacheKey(req)
+
+	var cached []cachedBatchFix
+	if c.cache.Get(key, &cached) && len(cached) == len(req.Incidents) {
+		c.record(true, len(cached))
+		fixes := make([]IncidentFix, len(cached))
+		for i, fix := range cached {
+			fixes[i] = IncidentFix{
+				IncidentURI:  fix.IncidentURI,
+				Success:      true,
+				FixedContent: fix.FixedContent,
+				Explanation:  fix.Explanation,
+				Confidence:   fix.Confidence,
+				Extra:        fix.Extra,
+			}
+		}
+		return &BatchResponse{Fixes: fixes, Success: true}, nil
+	}
+	c.record(false, len(req.Incidents))
+
+	resp, err := c.Provider.FixBatch(ctx, req)
+	if err != nil || resp == nil || !resp.Success || resp.Error != nil {
+		return resp, err
+	}
+
+	entry := make([]cachedBatchFix, len(resp.Fixes))
+	for i, fix := range resp.Fixes {
+		if !fix.Success || fix.Error != nil {
+			return resp, nil
+		}
+		entry[i] = cachedBatchFix{
+			IncidentURI:  fix.IncidentURI,
+			FixedContent: fix.FixedContent,
+			Explanation:  fix.Explanation,
+			Confidence:   fix.Confidence,
+			Extra:        fix.Extra,
+		}
+	}
+	// A failed write only costs a future cache miss
+	_ = c.cache.Put(key, entry)
+	return resp, nil
+}
+
+// Stats returns the number of cache hits and misses so far, counting each
+// incident of a batch
+func (c *CachingProvider) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *CachingProvider) record(hit bool, incidents int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.hits += incidents
+	} else {
+		c.misses += incidents
+	}
+}
+
+// keyParts returns the parts of every key: the key mode and the provider
+// settings that change responses
+func (c *CachingProvider) keyParts(format ResponseFormat) []string {
+	if format == "" {
+		format = c.config.ResponseFormat
+	}
+	return []string{
+		string(c.keyMode),
+		c.Provider.Name(),
+		c.config.Model,
+		strconv.FormatFloat(c.config.Temperature, 'f', -1, 64),
+		string(format),
+		strings.Join(c.config.ExtraFields, ","),
+	}
+}
+
+// cacheKey builds the key for a request in the configured mode
+func (c *CachingProvider) cacheKey(req FixRequest) string {
+	parts := c.keyParts(req.ResponseFormat)
+
+	switch c.keyMode {
+	case CacheKeyContent:
+		// Cached fixes replace the whole file, so the whole file is the
+		// code region: identical code elsewhere in the file matters too
+		parts = append(parts,
+			req.Violation.ID,
+			strconv.Itoa(req.Incident.LineNumber),
+			NormalizeContent(req.FileContent),
+		)
+	default:
+		// Every input the prompt is rendered from
+		data, _ := json.Marshal(struct {
+			Violation interface{}
+			Incident  interface{}
+			Language  string
+		}{req.Violation, req.Incident, req.Language})
+		parts = append(parts, string(data), req.FileContent)
+	}
+
+	return common.HashKey(parts...)
+}
+
+// batchCacheKey builds the key for a batch request in the configured mode.
+// Batch keys never match single fix keys.
+func (c *CachingProvider) batchCacheKey(req BatchRequest) string {
+	parts := append(c.keyParts(""), "batch")
+
+	switch c.keyMode {
+	case CacheKeyContent:
+		parts = append(parts, req.Violation.ID)
+		for _, incident := range req.Incidents {
+			parts = append(parts, incident.URI, strconv.Itoa(incident.LineNumber))
+		}
+		paths := make([]string, 0, len(req.FileContents))
+		for path := range req.FileContents {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			parts = append(parts, path, NormalizeContent(req.FileContents[path]))
+		}
+	default:
+		// Every input the prompt is rendered from; maps encode in key order
+		data, _ := json.Marshal(struct {
+			Violation    interface{}
+			Incidents    interface{}
+			FileContents map[string]string
+			Language     string
+		}{req.Violation, req.Incidents, req.FileContents, req.Language})
+		parts = append(parts, string(data))
+	}
+
+	return common.HashKey(parts...)
+}
+
+// NormalizeContent normalizes code for content cache keys: line endings
+// become \n and trailing whitespace is removed from each line and the file
+func NormalizeContent(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
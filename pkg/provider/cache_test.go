@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// countingProvider returns a fix and counts the calls that reach it
+type countingProvider struct {
+	calls   int
+	success bool
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	p.calls++
+	if !p.success {
+		return &FixResponse{Success: false, Error: fmt.Errorf("model refused")}, nil
+	}
+	return &FixResponse{
+		Success:      true,
+		FixedContent: "fixed " + req.FileContent,
+		Explanation:  "replaced javax",
+		Confidence:   0.9,
+		TokensUsed:   100,
+		Cost:         0.01,
+	}, nil
+}
+
+func (p *countingProvider) EstimateCost(req FixRequest) (float64, error) { return 0, nil }
+
+func (p *countingProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	return nil, nil
+}
+
+func (p *countingProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	p.calls++
+	fixes := make([]IncidentFix, len(req.Incidents))
+	for i, incident := range req.Incidents {
+		fixes[i] = IncidentFix{IncidentURI: incident.URI, Success: p.success, FixedContent: "fixed", Confidence: 0.9}
+		if !p.success {
+			fixes[i].Error = fmt.Errorf("model refused")
+		}
+	}
+	return &BatchResponse{Fixes: fixes, Success: p.success, TokensUsed: 300, Cost: 0.03}, nil
+}
+
+func newCachingProvider(t *testing.T, mode CacheKeyMode) (*CachingProvider, *countingProvider) {
+	t.Helper()
+	cache, err := common.NewResponseCache(t.TempDir())
+	require.NoError(t, err)
+	inner := &countingProvider{success: true}
+	return NewCachingProvider(inner, cache, Config{Model: "test-model", Temperature: 0.2}, mode), inner
+}
+
+func cacheTestRequest() FixRequest {
+	return FixRequest{
+		Violation: violation.Violation{
+			ID:          "javax-to-jakarta",
+			Description: "Replace javax with jakarta",
+			Category:    "mandatory",
+		},
+		Incident: violation.Incident{
+			URI:        "file:///src/App.java",
+			Message:    "javax.servlet import",
+			LineNumber: 3,
+		},
+		FileContent: "package app;\n\nimport javax.servlet.http.HttpServlet;\n",
+		Language:    "java",
+	}
+}
+
+func TestCachingProvider_ContentKeyIgnoresPromptMetadata(t *testing.T) {
+	p, inner := newCachingProvider(t, CacheKeyContent)
+	ctx := context.Background()
+
+	first, err := p.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 0.01, first.Cost)
+
+	// Only non-code prompt metadata differs (and trailing whitespace/CRLF)
+	req := cacheTestRequest()
+	req.Violation.Description = "Migrate to Jakarta EE 9 namespaces"
+	req.Violation.Labels = map[string]string{"konveyor.io/target": "jakarta-ee9"}
+	req.Incident.Message = "Replace the javax.servlet import"
+	req.Incident.URI = "file:///src/Other.java"
+	req.FileContent = "package app;  \r\n\r\nimport javax.servlet.http.HttpServlet;\r\n"
+
+	second, err := p.FixViolation(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls, "second request should be served from the cache")
+	assert.True(t, second.Success)
+	assert.Equal(t, first.FixedContent, second.FixedContent)
+	assert.Equal(t, first.Explanation, second.Explanation)
+	assert.Equal(t, first.Confidence, second.Confidence)
+	assert.Zero(t, second.Cost)
+	assert.Zero(t, second.TokensUsed)
+
+	hits, misses := p.Stats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+}
+
+func TestCachingProvider_ContentKeyMisses(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		modify func(req *FixRequest)
+	}{
+		{"code changed", func(req *FixRequest) { req.FileContent += "class App {}\n" }},
+		{"different violation", func(req *FixRequest) { req.Violation.ID = "other-rule" }},
+		{"different line", func(req *FixRequest) { req.Incident.LineNumber = 1 }},
+		{"different response format", func(req *FixRequest) { req.ResponseFormat = ResponseFormatDiff }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, inner := newCachingProvider(t, CacheKeyContent)
+			_, err := p.FixViolation(ctx, cacheTestRequest())
+			require.NoError(t, err)
+
+			req := cacheTestRequest()
+			tt.modify(&req)
+			_, err = p.FixViolation(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, 2, inner.calls)
+		})
+	}
+}
+
+func TestCachingProvider_ContentKeyIncludesModel(t *testing.T) {
+	cache, err := common.NewResponseCache(t.TempDir())
+	require.NoError(t, err)
+	inner := &countingProvider{success: true}
+	ctx := context.Background()
+
+	_, err = NewCachingProvider(inner, cache, Config{Model: "model-a"}, CacheKeyContent).FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	_, err = NewCachingProvider(inner, cache, Config{Model: "model-b"}, CacheKeyContent).FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// The cache is shared on disk, e.g. across runs
+	_, err = NewCachingProvider(inner, cache, Config{Model: "model-a"}, CacheKeyContent).FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCachingProvider_PromptKeyMissesOnMetadataChange(t *testing.T) {
+	p, inner := newCachingProvider(t, CacheKeyPrompt)
+	ctx := context.Background()
+
+	_, err := p.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	_, err = p.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls, "identical request should hit")
+
+	req := cacheTestRequest()
+	req.Incident.Message = "Replace the javax.servlet import"
+	_, err = p.FixViolation(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCachingProvider_FailuresNotCached(t *testing.T) {
+	p, inner := newCachingProvider(t, CacheKeyContent)
+	inner.success = false
+	ctx := context.Background()
+
+	resp, err := p.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+
+	inner.success = true
+	resp, err = p.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestParseCacheKeyMode(t *testing.T) {
+	mode, err := ParseCacheKeyMode("")
+	require.NoError(t, err)
+	assert.Equal(t, CacheKeyPrompt, mode)
+
+	mode, err = ParseCacheKeyMode("content")
+	require.NoError(t, err)
+	assert.Equal(t, CacheKeyContent, mode)
+
+	_, err = ParseCacheKeyMode("file")
+	assert.Error(t, err)
+}
+
+func cacheTestBatch() BatchRequest {
+	return BatchRequest{
+		Violation: violation.Violation{ID: "javax-to-jakarta", Description: "Replace javax with jakarta"},
+		Incidents: []violation.Incident{
+			{URI: "file:///src/A.java", LineNumber: 3},
+			{URI: "file:///src/B.java", LineNumber: 5},
+		},
+		FileContents: map[string]string{
+			"src/A.java": "import javax.servlet.http.HttpServlet;\n",
+			"src/B.java": "import javax.servlet.Filter;\n",
+		},
+		Language: "java",
+	}
+}
+
+func TestCachingProvider_FixBatch(t *testing.T) {
+	for _, mode := range []CacheKeyMode{CacheKeyPrompt, CacheKeyContent} {
+		t.Run(string(mode), func(t *testing.T) {
+			p, inner := newCachingProvider(t, mode)
+			ctx := context.Background()
+
+			first, err := p.FixBatch(ctx, cacheTestBatch())
+			require.NoError(t, err)
+			assert.Equal(t, 0.03, first.Cost)
+
+			second, err := p.FixBatch(ctx, cacheTestBatch())
+			require.NoError(t, err)
+			assert.Equal(t, 1, inner.calls, "identical batch served from the cache")
+			assert.True(t, second.Success)
+			assert.Zero(t, second.Cost)
+			assert.Zero(t, second.TokensUsed)
+			require.Len(t, second.Fixes, 2)
+			assert.Equal(t, "file:///src/B.java", second.Fixes[1].IncidentURI)
+			assert.Equal(t, "fixed", second.Fixes[1].FixedContent)
+
+			hits, misses := p.Stats()
+			assert.Equal(t, 2, hits, "each incident of a batch counts")
+			assert.Equal(t, 2, misses)
+
+			// Changed code is a miss
+			changed := cacheTestBatch()
+			changed.FileContents["src/B.java"] = "import javax.servlet.Servlet;\n"
+			_, err = p.FixBatch(ctx, changed)
+			require.NoError(t, err)
+			assert.Equal(t, 2, inner.calls)
+		})
+	}
+}
+
+func TestCachingProvider_FixBatchFailuresNotCached(t *testing.T) {
+	p, inner := newCachingProvider(t, CacheKeyPrompt)
+	inner.success = false
+	ctx := context.Background()
+
+	_, err := p.FixBatch(ctx, cacheTestBatch())
+	require.NoError(t, err)
+	_, err = p.FixBatch(ctx, cacheTestBatch())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCachingProvider_BatchAndSingleKeysDiffer(t *testing.T) {
+	p, _ := newCachingProvider(t, CacheKeyContent)
+	batch := cacheTestBatch()
+	batch.Incidents = batch.Incidents[:1]
+	assert.NotEqual(t, p.cacheKey(cacheTestRequest()), p.batchCacheKey(batch))
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// builtinDescriptions describes the built-in providers, like a preset's
+// Description
+var builtinDescriptions = map[string]string{
+	"claude": "Anthropic Claude - Recommended for code migrations",
+	"openai": "OpenAI - GPT-4 and o-series models",
+	"gemini": "Google Gemini - Large context window",
+}
+
+// builtinAPIKeyEnv maps the built-in providers to the environment variable
+// holding their API key; presets use OpenAI's
+var builtinAPIKeyEnv = map[string]string{
+	"claude": "ANTHROPIC_API_KEY",
+	"openai": "OPENAI_API_KEY",
+	"gemini": "GOOGLE_API_KEY",
+}
+
+// ProviderInfo describes a provider or preset for 'kantra-ai providers'
+type ProviderInfo struct {
+	Name         string   `json:"name"`
+	Preset       bool     `json:"preset"` // OpenAI-compatible preset rather than a built-in API
+	Description  string   `json:"description"`
+	DefaultModel string   `json:"default_model"`
+	Models       []string `json:"models,omitempty"`   // Known models, default first (empty = any model)
+	BaseURL      string   `json:"base_url,omitempty"` // Empty = the provider's own API
+	APIKeyEnv    string   `json:"api_key_env"`
+	APIKeySet    bool     `json:"api_key_set"` // Whether APIKeyEnv is set in the environment
+}
+
+// APIKeyEnv returns the environment variable the named provider or preset
+// reads its API key from, or "" for an unknown provider
+func APIKeyEnv(name string) string {
+	if env, ok := builtinAPIKeyEnv[name]; ok {
+		return env
+	}
+	if _, ok := ProviderPresets[name]; ok {
+		return builtinAPIKeyEnv["openai"]
+	}
+	return ""
+}
+
+// Catalog describes every built-in provider, then every preset in
+// alphabetical order, with whether its API key is set in the environment.
+// The replay provider isn't included as it calls no API.
+func Catalog() []ProviderInfo {
+	var infos []ProviderInfo
+	for _, name := range Names() {
+		info := ProviderInfo{Name: name, Models: KnownModels(name), APIKeyEnv: APIKeyEnv(name)}
+		if preset, ok := ProviderPresets[name]; ok {
+			info.Preset = true
+			info.Description = preset.Description
+			info.DefaultModel = preset.DefaultModel
+			info.BaseURL = preset.BaseURL
+		} else if description, ok := builtinDescriptions[name]; ok {
+			info.Description = description
+			info.DefaultModel = info.Models[0]
+		} else {
+			continue
+		}
+		info.APIKeySet = os.Getenv(info.APIKeyEnv) != ""
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// WriteCatalogJSON writes catalog as indented JSON
+func WriteCatalogJSON(w io.Writer, catalog []ProviderInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+
+	catalog := Catalog()
+	require.Len(t, catalog, len(BuiltinProviders)+len(ProviderPresets), "every provider and preset, without replay")
+
+	byName := make(map[string]ProviderInfo)
+	for _, info := range catalog {
+		byName[info.Name] = info
+		assert.NotEmpty(t, info.Description, info.Name)
+		assert.NotEmpty(t, info.DefaultModel, info.Name)
+	}
+	assert.Equal(t, "claude", catalog[0].Name, "built-in providers first")
+
+	claude := byName["claude"]
+	assert.False(t, claude.Preset)
+	assert.Empty(t, claude.BaseURL)
+	assert.Equal(t, "claude-sonnet-4-20250514", claude.DefaultModel)
+	assert.Equal(t, "ANTHROPIC_API_KEY", claude.APIKeyEnv)
+	assert.True(t, claude.APIKeySet)
+
+	assert.Equal(t, "GOOGLE_API_KEY", byName["gemini"].APIKeyEnv)
+	assert.False(t, byName["gemini"].APIKeySet, "an empty variable isn't set")
+
+	groq := byName["groq"]
+	assert.True(t, groq.Preset)
+	assert.Equal(t, ProviderPresets["groq"].BaseURL, groq.BaseURL)
+	assert.Equal(t, ProviderPresets["groq"].DefaultModel, groq.DefaultModel)
+	assert.Equal(t, KnownModels("groq"), groq.Models)
+	assert.Equal(t, "OPENAI_API_KEY", groq.APIKeyEnv, "presets use the OpenAI API")
+	assert.False(t, groq.APIKeySet)
+
+	assert.Empty(t, byName["ollama"].Models, "any model")
+}
+
+func TestWriteCatalogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCatalogJSON(&buf, []ProviderInfo{{Name: "groq", Preset: true, APIKeyEnv: "OPENAI_API_KEY"}}))
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "groq", decoded[0]["name"])
+	assert.Equal(t, "OPENAI_API_KEY", decoded[0]["api_key_env"])
+	assert.Equal(t, false, decoded[0]["api_key_set"])
+	assert.NotContains(t, decoded[0], "models")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -33,27 +34,42 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 	if err != nil {
 		return nil, fmt.Errorf("failed to render batch prompt template: %w", err)
 	}
+	promptText = provider.ApplyExtraFields(promptText, p.extraFields)
 
-	// Call Claude API
-	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:       anthropic.F(p.model),
-		MaxTokens:   anthropic.F(int64(PlanningMaxTokens)), // Higher limit for batch processing
-		Temperature: anthropic.F(p.temperature),
-		Messages: anthropic.F([]anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(promptText)),
-		}),
-	})
+	// Fail fast with a clear error instead of a cryptic API error
+	if err := provider.CheckPromptSize(promptText, provider.PromptTokenLimit(p.model, p.maxPromptTokens, PlanningMaxTokens)); err != nil {
+		return nil, err
+	}
 
+	// Call Claude API
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(promptText)),
+	}
+	message, err := p.sendBatchMessages(ctx, messages)
 	if err != nil {
 		return nil, fmt.Errorf("Claude API error: %w", err)
 	}
+	responseText := messageText(message)
+	inputTokens := message.Usage.InputTokens
+	outputTokens := message.Usage.OutputTokens
 
-	// Extract response text
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-		}
+	// Large batches can hit the output token limit; continue the response
+	// instead of losing the whole batch to invalid JSON
+	responseText, err = provider.CompleteTruncated(responseText, message.StopReason == anthropic.MessageStopReasonMaxTokens,
+		func(partial string) (string, bool, error) {
+			continued, err := p.sendBatchMessages(ctx, append(messages,
+				anthropic.NewAssistantMessage(anthropic.NewTextBlock(partial)),
+				anthropic.NewUserMessage(anthropic.NewTextBlock(provider.ContinuationPrompt)),
+			))
+			if err != nil {
+				return "", false, err
+			}
+			inputTokens += continued.Usage.InputTokens
+			outputTokens += continued.Usage.OutputTokens
+			return messageText(continued), continued.StopReason == anthropic.MessageStopReasonMaxTokens, nil
+		})
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse the batch response
@@ -62,12 +78,8 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 		return nil, fmt.Errorf("failed to parse batch response: %w", err)
 	}
 
-	// Calculate costs (Sonnet 4 pricing: $3/1M input, $15/1M output)
-	inputTokens := message.Usage.InputTokens
-	outputTokens := message.Usage.OutputTokens
-	inputCost := float64(inputTokens) * 3.0 / 1000000.0
-	outputCost := float64(outputTokens) * 15.0 / 1000000.0
-	cost := inputCost + outputCost
+	// Calculate costs (Sonnet 4 pricing unless configured)
+	cost := p.pricing.Cost(int(inputTokens), int(outputTokens))
 
 	// Check if all fixes succeeded
 	allSuccess := true
@@ -86,6 +98,33 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 	}, nil
 }
 
+// sendBatchMessages sends a batch conversation with retries
+func (p *Provider) sendBatchMessages(ctx context.Context, messages []anthropic.MessageParam) (*anthropic.Message, error) {
+	var message *anthropic.Message
+	err := common.RetryWithBackoff(ctx, p.retry, func() error {
+		var apiErr error
+		message, apiErr = p.client.Messages.New(ctx, anthropic.MessageNewParams{
+			Model:       anthropic.F(p.model),
+			MaxTokens:   anthropic.F(int64(PlanningMaxTokens)), // Higher limit for batch processing
+			Temperature: anthropic.F(p.temperature),
+			Messages:    anthropic.F(messages),
+		})
+		return apiErr
+	})
+	return message, err
+}
+
+// messageText returns the text of a message's last text block
+func messageText(message *anthropic.Message) string {
+	var text string
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			text = block.Text
+		}
+	}
+	return text
+}
+
 // parseBatchResponse parses Claude's JSON response into IncidentFix structs
 func (p *Provider) parseBatchResponse(responseText string, incidents []violation.Incident) ([]provider.IncidentFix, error) {
 	// Extract JSON from markdown code blocks
@@ -103,6 +142,7 @@ func (p *Provider) parseBatchResponse(responseText string, incidents []violation
 	if err := json.Unmarshal(jsonData, &rawFixes); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	extras := provider.ExtractBatchExtraFields(jsonData, p.extraFields)
 
 	// Verify we got a fix for each incident
 	if len(rawFixes) != len(incidents) {
@@ -119,6 +159,9 @@ func (p *Provider) parseBatchResponse(responseText string, incidents []violation
 			Explanation:  raw.Explanation,
 			Confidence:   raw.Confidence,
 		}
+		if extras != nil {
+			fixes[i].Extra = extras[i]
+		}
 
 		if !raw.Success {
 			fixes[i].Error = fmt.Errorf("%s", raw.Explanation)
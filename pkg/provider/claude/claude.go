@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"regexp"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
@@ -21,22 +20,30 @@ const (
 	DefaultMaxTokens = 4096
 	// PlanningMaxTokens is the maximum tokens for plan generation (requires more output)
 	PlanningMaxTokens = 8192
-)
-
-var (
-	// Compiled regexes for JSON extraction (compiled once at package init time)
-	jsonCodeBlockRegex = regexp.MustCompile(`(?s)` + "```" + `(?:json)?\s*([\[{].*?[\]}])\s*` + "```")
-	jsonArrayRegex     = regexp.MustCompile(`(?s)(\[.*\])`)
+	// PlanningRetryBaseDelay is the wait before the first retry of a rate-limited
+	// planning request. Planning requests are large, so their retries back off
+	// linearly from a longer delay than fixes (30s, 60s, 90s).
+	PlanningRetryBaseDelay = 30 * time.Second
 )
 
 // Provider implements the Claude AI provider
 type Provider struct {
-	client      *anthropic.Client
-	model       string
-	temperature float64
-	templates   *prompt.Templates
+	client          *anthropic.Client
+	model           string
+	temperature     float64
+	templates       *prompt.Templates
+	responseFormat  provider.ResponseFormat
+	retry           common.RetryConfig
+	planRetry       common.RetryConfig // Retries of planning requests (see PlanningRetryBaseDelay)
+	maxTokens       int
+	extraFields     []string
+	maxPromptTokens int
+	pricing         provider.Pricing
 }
 
+// DefaultPricing is Claude Sonnet 4's list price, used unless configured
+var DefaultPricing = provider.Pricing{InputPerMillion: 3.0, OutputPerMillion: 15.0}
+
 // New creates a new Claude provider
 func New(config provider.Config) (*Provider, error) {
 	apiKey := config.APIKey
@@ -64,7 +71,17 @@ func New(config provider.Config) (*Provider, error) {
 		temperature = 0.2 // Low temperature for code fixes
 	}
 
-	client := anthropic.NewClient(option.WithAPIKey(apiKey))
+	maxTokens := config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
+	// The header client sends the User-Agent and extra headers, and logs the
+	// traffic at debug level
+	client := anthropic.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(common.NewHeaderClient(config.RequestHeaders())),
+	)
 
 	// Load templates (use defaults if not provided)
 	templates := config.Templates
@@ -78,14 +95,43 @@ func New(config provider.Config) (*Provider, error) {
 		}
 	}
 
+	responseFormat := config.ResponseFormat
+	if responseFormat == "" {
+		responseFormat = provider.ResponseFormatFull
+	}
+
 	return &Provider{
-		client:      client,
-		model:       model,
-		temperature: temperature,
-		templates:   templates,
+		client:          client,
+		model:           model,
+		temperature:     temperature,
+		templates:       templates,
+		responseFormat:  responseFormat,
+		maxTokens:       maxTokens,
+		extraFields:     config.ExtraFields,
+		maxPromptTokens: config.MaxPromptTokens,
+		pricing:         config.PricingOr(DefaultPricing),
+		retry: common.RetryConfig{
+			MaxRetries: config.MaxRetries,
+			BaseDelay:  config.RetryBaseDelay,
+		},
+		planRetry: planRetryConfig(config),
 	}, nil
 }
 
+// planRetryConfig returns the retry settings for planning requests: linear
+// backoff from PlanningRetryBaseDelay, unless a base delay is configured
+func planRetryConfig(config provider.Config) common.RetryConfig {
+	retry := common.RetryConfig{
+		MaxRetries: config.MaxRetries,
+		BaseDelay:  config.RetryBaseDelay,
+		Linear:     true,
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = PlanningRetryBaseDelay
+	}
+	return retry
+}
+
 // Name returns the provider name
 func (p *Provider) Name() string {
 	return "claude"
@@ -93,25 +139,35 @@ func (p *Provider) Name() string {
 
 // FixViolation sends the violation to Claude and gets a fix
 func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*provider.FixResponse, error) {
-	// Build prompt from template
-	data := provider.BuildSingleFixData(req)
-	// Select language-specific template or fall back to base template
-	tmpl := p.templates.GetSingleFixTemplate(data.Language)
-	promptText, err := tmpl.RenderSingleFix(data)
+	format := p.responseFormatFor(req)
+	promptText, err := p.fixPrompt(req)
 	if err != nil {
 		return &provider.FixResponse{
 			Success: false,
-			Error:   fmt.Errorf("failed to render prompt template: %w", err),
+			Error:   err,
 		}, nil
 	}
 
-	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:       anthropic.F(p.model),
-		MaxTokens:   anthropic.F(int64(DefaultMaxTokens)),
-		Temperature: anthropic.F(p.temperature),
-		Messages: anthropic.F([]anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(promptText)),
-		}),
+	// Fail fast with a clear error instead of a cryptic API error
+	if err := provider.CheckPromptSize(promptText, provider.PromptTokenLimit(p.model, p.maxPromptTokens, p.maxTokens)); err != nil {
+		return &provider.FixResponse{
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	var message *anthropic.Message
+	err = common.RetryWithBackoff(ctx, p.retry, func() error {
+		var apiErr error
+		message, apiErr = p.client.Messages.New(ctx, anthropic.MessageNewParams{
+			Model:       anthropic.F(p.model),
+			MaxTokens:   anthropic.F(int64(p.maxTokens)),
+			Temperature: anthropic.F(p.temperature),
+			Messages: anthropic.F([]anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock(promptText)),
+			}),
+		})
+		return apiErr
 	})
 
 	if err != nil {
@@ -132,6 +188,7 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 	// Parse JSON response
 	type Response struct {
 		FixedContent string  `json:"fixed_content"`
+		Patch        string  `json:"patch"`
 		Confidence   float64 `json:"confidence"`
 		Explanation  string  `json:"explanation"`
 	}
@@ -142,16 +199,17 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 	var resp Response
 	if err := json.Unmarshal(jsonData, &resp); err != nil {
 		// If JSON parsing fails, fall back to treating entire response as code with default confidence
-		inputCost := float64(message.Usage.InputTokens) * 3.0 / 1000000.0
-		outputCost := float64(message.Usage.OutputTokens) * 15.0 / 1000000.0
-		return &provider.FixResponse{
+		fallback := &provider.FixResponse{
 			Success:      true,
 			FixedContent: responseText,
 			Explanation:  "Fixed by Claude (JSON parse failed, using raw response)",
 			Confidence:   0.85, // Default when JSON parsing fails
 			TokensUsed:   int(message.Usage.InputTokens + message.Usage.OutputTokens),
-			Cost:         inputCost + outputCost,
-		}, nil
+			Cost:         p.messageCost(message),
+		}
+		// In diff mode a raw response is most likely a bare patch
+		provider.PreferPatch(fallback, format, responseText, true)
+		return fallback, nil
 	}
 
 	// Validate confidence range
@@ -159,32 +217,60 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 		resp.Confidence = 0.85 // Clamp to reasonable default
 	}
 
-	// Calculate cost (Sonnet 4 pricing: $3/1M input, $15/1M output)
-	inputCost := float64(message.Usage.InputTokens) * 3.0 / 1000000.0
-	outputCost := float64(message.Usage.OutputTokens) * 15.0 / 1000000.0
-	totalCost := inputCost + outputCost
+	// Calculate cost (Sonnet 4 pricing unless configured)
+	totalCost := p.messageCost(message)
 
-	return &provider.FixResponse{
+	fixResp := &provider.FixResponse{
 		Success:      true,
 		FixedContent: resp.FixedContent,
 		Explanation:  resp.Explanation,
 		Confidence:   resp.Confidence,
 		TokensUsed:   int(message.Usage.InputTokens + message.Usage.OutputTokens),
 		Cost:         totalCost,
-	}, nil
+		Extra:        provider.ExtractExtraFields(jsonData, p.extraFields),
+	}
+	// Prefer the patch in diff mode
+	provider.PreferPatch(fixResp, format, resp.Patch, false)
+
+	return fixResp, nil
 }
 
 // EstimateCost estimates the cost for fixing a violation
 func (p *Provider) EstimateCost(req provider.FixRequest) (float64, error) {
-	// Rough estimate: ~2000 tokens input + ~1000 tokens output
-	// Using Sonnet 4 pricing: $3/$15 per 1M tokens
-	estimatedInputTokens := 2000.0
-	estimatedOutputTokens := 1000.0
+	promptText, err := p.fixPrompt(req)
+	if err != nil {
+		return 0, err
+	}
+	return p.pricing.Cost(provider.EstimateFixTokens(p.model, promptText)), nil
+}
 
-	inputCost := estimatedInputTokens * 3.0 / 1000000.0
-	outputCost := estimatedOutputTokens * 15.0 / 1000000.0
+// fixPrompt renders the prompt for a single fix request
+func (p *Provider) fixPrompt(req provider.FixRequest) (string, error) {
+	data := provider.BuildSingleFixData(req)
+	// Select language-specific template or fall back to base template
+	tmpl := p.templates.GetSingleFixTemplate(data.Language)
+	promptText, err := tmpl.RenderSingleFix(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
 
-	return inputCost + outputCost, nil
+	// Request a unified diff instead of the whole file if configured
+	promptText = provider.ApplyResponseFormat(promptText, p.responseFormatFor(req))
+	return provider.ApplyExtraFields(promptText, p.extraFields), nil
+}
+
+// responseFormatFor returns the response format of a fix request: its own,
+// or the provider's
+func (p *Provider) responseFormatFor(req provider.FixRequest) provider.ResponseFormat {
+	if req.ResponseFormat != "" {
+		return req.ResponseFormat
+	}
+	return p.responseFormat
+}
+
+// messageCost returns the cost of a message's input and output tokens
+func (p *Provider) messageCost(message *anthropic.Message) float64 {
+	return p.pricing.Cost(int(message.Usage.InputTokens), int(message.Usage.OutputTokens))
 }
 
 // enhanceAPIError adds helpful context to Claude API errors using the common error handler.
@@ -197,41 +283,38 @@ func enhanceAPIError(err error) error {
 	})
 }
 
-// isRateLimitError checks if an error is a rate limit error (429)
-func isRateLimitError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	return regexp.MustCompile(`(?i)rate.limit|429|too many requests`).MatchString(errStr)
-}
+// DefaultPlanBatchSize is the number of violations per planning request
+// unless PlanRequest.BatchSize is set. Batches are kept small to avoid token
+// limits.
+const DefaultPlanBatchSize = 15
 
 // GeneratePlan generates a phased migration plan using Claude
 // If there are too many violations, it batches them to avoid rate limits
 func (p *Provider) GeneratePlan(ctx context.Context, req provider.PlanRequest) (*provider.PlanResponse, error) {
-	const maxViolationsPerBatch = 15 // Keep batches small to avoid token limits
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultPlanBatchSize
+	}
 
 	// If violations fit in one batch, use direct approach
-	if len(req.Violations) <= maxViolationsPerBatch {
+	if len(req.Violations) <= batchSize {
 		return p.generatePlanDirect(ctx, req)
 	}
 
 	// Otherwise, batch the violations
 	fmt.Printf("📦 Batching %d violations into smaller groups to avoid rate limits...\n", len(req.Violations))
-	return p.generatePlanBatched(ctx, req, maxViolationsPerBatch)
+	return p.generatePlanBatched(ctx, req, batchSize)
 }
 
 // generatePlanDirect generates a plan directly without batching
 func (p *Provider) generatePlanDirect(ctx context.Context, req provider.PlanRequest) (*provider.PlanResponse, error) {
-	prompt := buildPlanPrompt(req)
+	prompt := provider.BuildPlanPrompt(req)
 
 	// Retry logic for rate limits
 	var message *anthropic.Message
-	var err error
-	maxRetries := 3
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		message, err = p.client.Messages.New(ctx, anthropic.MessageNewParams{
+	err := common.RetryWithBackoff(ctx, p.planRetry, func() error {
+		var apiErr error
+		message, apiErr = p.client.Messages.New(ctx, anthropic.MessageNewParams{
 			Model:       anthropic.F(p.model),
 			MaxTokens:   anthropic.F(int64(PlanningMaxTokens)), // Higher limit for planning
 			Temperature: anthropic.F(0.3),                      // Slightly higher for creativity in planning
@@ -239,44 +322,15 @@ func (p *Provider) generatePlanDirect(ctx context.Context, req provider.PlanRequ
 				anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
 			}),
 		})
+		return apiErr
+	})
 
-		// Success - break out of retry loop
-		if err == nil {
-			break
-		}
-
-		// Check if it's a rate limit error
-		if !isRateLimitError(err) {
-			// Not a rate limit error, return immediately
-			return &provider.PlanResponse{
-				Error: enhanceAPIError(err),
-			}, nil
-		}
-
-		// Rate limit error - check if we should retry
-		if attempt == maxRetries {
-			// Max retries reached
-			return &provider.PlanResponse{
-				Error: enhanceAPIError(err),
-			}, nil
-		}
-
-		// Calculate backoff delay (exponential: 30s, 60s, 90s)
-		backoff := time.Duration(30*(attempt+1)) * time.Second
-		fmt.Printf("   ⏳ Rate limit hit, waiting %ds before retry %d/%d...\n",
-			int(backoff.Seconds()), attempt+1, maxRetries)
-
-		// Wait with context cancellation support
-		select {
-		case <-ctx.Done():
-			return &provider.PlanResponse{
-				Error: ctx.Err(),
-			}, nil
-		case <-time.After(backoff):
-			// Continue to next attempt
-		}
+	// Cancelled while waiting to retry
+	if err != nil && err == ctx.Err() {
+		return &provider.PlanResponse{
+			Error: err,
+		}, nil
 	}
-
 	if err != nil {
 		return &provider.PlanResponse{
 			Error: enhanceAPIError(err),
@@ -292,7 +346,7 @@ func (p *Provider) generatePlanDirect(ctx context.Context, req provider.PlanRequ
 	}
 
 	// Parse the response into phases
-	phases, err := parsePlanResponse(responseText, req.Violations)
+	phases, err := provider.ParsePlanResponse(responseText, req.Violations)
 	if err != nil {
 		return &provider.PlanResponse{
 			Error: fmt.Errorf("failed to parse plan response: %w", err),
@@ -300,9 +354,7 @@ func (p *Provider) generatePlanDirect(ctx context.Context, req provider.PlanRequ
 	}
 
 	// Calculate cost
-	inputCost := float64(message.Usage.InputTokens) * 3.0 / 1000000.0
-	outputCost := float64(message.Usage.OutputTokens) * 15.0 / 1000000.0
-	totalCost := inputCost + outputCost
+	totalCost := p.messageCost(message)
 
 	return &provider.PlanResponse{
 		Phases:     phases,
@@ -317,9 +369,22 @@ func (p *Provider) generatePlanBatched(ctx context.Context, req provider.PlanReq
 	batches := batchViolations(req.Violations, batchSize)
 	fmt.Printf("   Split into %d batches\n\n", len(batches))
 
-	// Hide cursor during progress updates
-	os.Stdout.WriteString("\033[?25l")
-	defer os.Stdout.WriteString("\033[?25h\n") // Show cursor when done
+	// Report progress to the request's writer, or as a progress bar
+	progress := provider.NewPlanProgress(len(batches))
+	report := func(current int, status string) {
+		if req.Progress != nil {
+			if current < len(batches) {
+				req.Progress.Info("Planning batch %d/%d %s", current+1, len(batches), progress.ETA())
+			}
+			return
+		}
+		updateBatchProgress(current, len(batches), status, progress.ETA())
+	}
+	if req.Progress == nil {
+		// Hide cursor during progress updates
+		os.Stdout.WriteString("\033[?25l")
+		defer os.Stdout.WriteString("\033[?25h\n") // Show cursor when done
+	}
 
 	var allPhases []provider.PlannedPhase
 	var totalTokens int
@@ -327,15 +392,14 @@ func (p *Provider) generatePlanBatched(ctx context.Context, req provider.PlanReq
 
 	// Generate a mini-plan for each batch
 	for i, batch := range batches {
-		// Update progress bar
-		updateBatchProgress(i, len(batches), "Processing")
+		started := time.Now()
+
+		// Update progress
+		report(i, "Processing")
 
 		// Add delay between batches to respect rate limits
 		if i > 0 {
-			delay := calculateBatchDelay(totalTokens, i)
-
-			// Show processing status in progress bar during delay
-			updateBatchProgress(i, len(batches), "Processing...")
+			delay := batchDelay(req, totalTokens, i)
 
 			select {
 			case <-ctx.Done():
@@ -369,15 +433,16 @@ func (p *Provider) generatePlanBatched(ctx context.Context, req provider.PlanReq
 		allPhases = append(allPhases, resp.Phases...)
 		totalTokens += resp.TokensUsed
 		totalCost += resp.Cost
+		progress.BatchDone(time.Since(started))
 	}
 
 	// Complete the progress bar
-	updateBatchProgress(len(batches), len(batches), "Complete")
+	report(len(batches), "Complete")
 	fmt.Println()
 
 	// Merge and reorganize phases
 	fmt.Printf("\n   Merging %d phases from all batches...\n", len(allPhases))
-	mergedPhases := mergePhases(allPhases, req.MaxPhases)
+	mergedPhases := provider.MergePlannedPhases(allPhases, req.MaxPhases)
 	fmt.Printf("✓ Generated %d final phases\n", len(mergedPhases))
 
 	return &provider.PlanResponse{
@@ -387,6 +452,15 @@ func (p *Provider) generatePlanBatched(ctx context.Context, req provider.PlanReq
 	}, nil
 }
 
+// batchDelay returns how long to wait before a batch: the request's delay if
+// it sets one, otherwise calculateBatchDelay
+func batchDelay(req provider.PlanRequest, tokensSoFar int, batchIndex int) time.Duration {
+	if req.BatchDelay != nil {
+		return *req.BatchDelay
+	}
+	return calculateBatchDelay(tokensSoFar, batchIndex)
+}
+
 // calculateBatchDelay calculates how long to wait between batches to respect rate limits
 // Claude has a 30,000 tokens/minute limit, so we need to space requests accordingly
 func calculateBatchDelay(tokensSoFar int, batchIndex int) time.Duration {
@@ -404,7 +478,7 @@ func calculateBatchDelay(tokensSoFar int, batchIndex int) time.Duration {
 }
 
 // updateBatchProgress displays a progress bar for batch processing
-func updateBatchProgress(current, total int, status string) {
+func updateBatchProgress(current, total int, status string, eta string) {
 	// Current is 0-based, so add 1 for display
 	currentBatch := current + 1
 
@@ -431,8 +505,12 @@ func updateBatchProgress(current, total int, status string) {
 	// Calculate percentage
 	percentage := int(progress * 100)
 
+	if eta != "" {
+		status += " " + eta
+	}
+
 	// Print progress bar with trailing spaces to clear leftover characters
-	fmt.Printf("\r   Progress [%s] %d%% | Batch %d/%d | %-20s",
+	fmt.Printf("\r   Progress [%s] %d%% | Batch %d/%d | %-35s",
 		bar, percentage, currentBatch, total, status)
 }
 
@@ -448,299 +526,3 @@ func batchViolations(violations []violation.Violation, batchSize int) [][]violat
 	}
 	return batches
 }
-
-// mergePhases merges mini-plans into a cohesive final plan
-func mergePhases(phases []provider.PlannedPhase, maxPhases int) []provider.PlannedPhase {
-	if len(phases) == 0 {
-		return phases
-	}
-
-	// Group phases by category and risk level
-	type phaseKey struct {
-		category string
-		risk     string
-	}
-
-	groups := make(map[phaseKey][]provider.PlannedPhase)
-	for _, phase := range phases {
-		key := phaseKey{
-			category: phase.Category,
-			risk:     phase.Risk,
-		}
-		groups[key] = append(groups[key], phase)
-	}
-
-	// Merge phases within each group
-	var merged []provider.PlannedPhase
-	for key, groupPhases := range groups {
-		if len(groupPhases) == 1 {
-			merged = append(merged, groupPhases[0])
-			continue
-		}
-
-		// Merge multiple phases into one
-		mergedPhase := provider.PlannedPhase{
-			ID:                       fmt.Sprintf("phase-%s-%s", key.category, key.risk),
-			Name:                     fmt.Sprintf("%s - %s Risk", key.category, key.risk),
-			Risk:                     key.risk,
-			Category:                 key.category,
-			ViolationIDs:             []string{},
-			EstimatedCost:            0,
-			EstimatedDurationMinutes: 0,
-		}
-
-		// Aggregate from all phases in this group
-		minEffort := 10
-		maxEffort := 0
-		for _, phase := range groupPhases {
-			mergedPhase.ViolationIDs = append(mergedPhase.ViolationIDs, phase.ViolationIDs...)
-			mergedPhase.EstimatedCost += phase.EstimatedCost
-			mergedPhase.EstimatedDurationMinutes += phase.EstimatedDurationMinutes
-
-			if phase.EffortRange[0] < minEffort {
-				minEffort = phase.EffortRange[0]
-			}
-			if phase.EffortRange[1] > maxEffort {
-				maxEffort = phase.EffortRange[1]
-			}
-		}
-
-		mergedPhase.EffortRange = [2]int{minEffort, maxEffort}
-		mergedPhase.Explanation = fmt.Sprintf("Merged %d phases with %s violations at %s risk level.",
-			len(groupPhases), key.category, key.risk)
-
-		merged = append(merged, mergedPhase)
-	}
-
-	// Sort by priority: mandatory > optional > potential, then high risk > medium > low
-	sortPhasesByPriority(merged)
-
-	// Reassign order and IDs
-	for i := range merged {
-		merged[i].Order = i + 1
-		merged[i].ID = fmt.Sprintf("phase-%d", i+1)
-	}
-
-	// Limit to maxPhases if specified
-	if maxPhases > 0 && len(merged) > maxPhases {
-		merged = merged[:maxPhases]
-	}
-
-	return merged
-}
-
-// sortPhasesByPriority sorts phases by category and risk
-func sortPhasesByPriority(phases []provider.PlannedPhase) {
-	// Simple bubble sort for small slices
-	for i := 0; i < len(phases); i++ {
-		for j := i + 1; j < len(phases); j++ {
-			if phasePriority(phases[i]) > phasePriority(phases[j]) {
-				phases[i], phases[j] = phases[j], phases[i]
-			}
-		}
-	}
-}
-
-// phasePriority returns a priority score (lower = higher priority)
-func phasePriority(phase provider.PlannedPhase) int {
-	priority := 0
-
-	// Category priority (mandatory = 0, optional = 100, potential = 200)
-	switch phase.Category {
-	case "mandatory":
-		priority += 0
-	case "optional":
-		priority += 100
-	case "potential":
-		priority += 200
-	default:
-		priority += 300
-	}
-
-	// Risk priority (high = 0, medium = 10, low = 20)
-	switch phase.Risk {
-	case "high":
-		priority += 0
-	case "medium":
-		priority += 10
-	case "low":
-		priority += 20
-	default:
-		priority += 30
-	}
-
-	return priority
-}
-
-// buildPlanPrompt constructs the prompt for plan generation
-func buildPlanPrompt(req provider.PlanRequest) string {
-	// Create a lightweight version of violations (without full incident details)
-	// to avoid exceeding token limits
-	type lightweightViolation struct {
-		ID                  string `json:"id"`
-		Description         string `json:"description"`
-		Category            string `json:"category"`
-		Effort              int    `json:"effort"`
-		IncidentCount       int    `json:"incident_count"`
-		MigrationComplexity string `json:"migration_complexity,omitempty"`
-	}
-
-	lightViolations := make([]lightweightViolation, len(req.Violations))
-	for i, v := range req.Violations {
-		lightViolations[i] = lightweightViolation{
-			ID:                  v.ID,
-			Description:         v.Description,
-			Category:            v.Category,
-			Effort:              v.Effort,
-			IncidentCount:       len(v.Incidents),
-			MigrationComplexity: v.MigrationComplexity,
-		}
-	}
-
-	violationsJSON, _ := json.MarshalIndent(lightViolations, "", "  ")
-
-	maxPhases := req.MaxPhases
-	if maxPhases == 0 {
-		maxPhases = 5 // Default to 5 phases
-	}
-
-	return fmt.Sprintf(`You are a migration planning expert helping create a phased migration plan for code violations found by Konveyor static analysis.
-
-VIOLATIONS TO ANALYZE:
-%s
-
-REQUIREMENTS:
-1. Group violations into %d logical phases (or fewer if appropriate)
-2. Prioritize phases by: category (mandatory > optional > potential) > effort level
-3. For each phase provide:
-   - A clear, descriptive name
-   - Risk level assessment (low/medium/high)
-   - Explanation of WHY these violations are grouped together
-   - Recommended execution order
-   - Violation IDs to include in this phase
-   - Estimated cost per phase ($0.05-0.15 per incident typically)
-   - Estimated duration in minutes
-
-GROUPING STRATEGY:
-- Group by category first (mandatory, optional, potential)
-- Within each category, group by effort level (high effort separate from low effort)
-- Consider dependencies and risk
-- Explain the reasoning for each grouping
-
-RISK TOLERANCE: %s
-- conservative: Smaller phases, lower risk, more phases
-- balanced: Moderate phase sizes, mixed complexity
-- aggressive: Larger phases, higher efficiency, fewer phases
-
-OUTPUT FORMAT: Return a valid JSON array of phases:
-[
-  {
-    "id": "phase-1",
-    "name": "Critical Mandatory Fixes - High Effort",
-    "order": 1,
-    "risk": "high",
-    "category": "mandatory",
-    "effort_range": [5, 7],
-    "explanation": "These violations require significant refactoring of core APIs...",
-    "violation_ids": ["javax-to-jakarta-001", "javax-to-jakarta-002"],
-    "estimated_cost": 2.45,
-    "estimated_duration_minutes": 15
-  }
-]
-
-Return ONLY the JSON array with no additional text or markdown formatting.`,
-		string(violationsJSON),
-		maxPhases,
-		req.RiskTolerance)
-}
-
-// parsePlanResponse parses Claude's JSON response into PlannedPhase structs
-func parsePlanResponse(responseText string, violations []violation.Violation) ([]provider.PlannedPhase, error) {
-	// Extract JSON from response (handle markdown code blocks if present)
-	jsonStr := extractJSON(responseText)
-
-	var rawPhases []map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &rawPhases); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w\nResponse: %s", err, responseText)
-	}
-
-	phases := make([]provider.PlannedPhase, 0, len(rawPhases))
-	for _, raw := range rawPhases {
-		phase := provider.PlannedPhase{
-			ID:          getString(raw, "id"),
-			Name:        getString(raw, "name"),
-			Order:       getInt(raw, "order"),
-			Risk:        getString(raw, "risk"),
-			Category:    getString(raw, "category"),
-			Explanation: getString(raw, "explanation"),
-		}
-
-		// Parse effort_range as [min, max]
-		if effortRange, ok := raw["effort_range"].([]interface{}); ok && len(effortRange) >= 2 {
-			if min, ok := effortRange[0].(float64); ok {
-				phase.EffortRange[0] = int(min)
-			}
-			if max, ok := effortRange[1].(float64); ok {
-				phase.EffortRange[1] = int(max)
-			}
-		}
-
-		// Parse violation_ids
-		if ids, ok := raw["violation_ids"].([]interface{}); ok {
-			phase.ViolationIDs = make([]string, 0, len(ids))
-			for _, id := range ids {
-				if str, ok := id.(string); ok {
-					phase.ViolationIDs = append(phase.ViolationIDs, str)
-				}
-			}
-		}
-
-		phase.EstimatedCost = getFloat(raw, "estimated_cost")
-		phase.EstimatedDurationMinutes = getInt(raw, "estimated_duration_minutes")
-
-		phases = append(phases, phase)
-	}
-
-	return phases, nil
-}
-
-// extractJSON extracts JSON from a response that might contain markdown code blocks
-func extractJSON(text string) string {
-	// Try to extract JSON from markdown code blocks using pre-compiled regex
-	matches := jsonCodeBlockRegex.FindStringSubmatch(text)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// If no code blocks, try to find JSON array or object directly using pre-compiled regex
-	matches = jsonArrayRegex.FindStringSubmatch(text)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// Return original text if no JSON pattern found
-	return text
-}
-
-// Helper functions for safe type conversion from map[string]interface{}
-func getString(m map[string]interface{}, key string) string {
-	if val, ok := m[key].(string); ok {
-		return val
-	}
-	return ""
-}
-
-func getInt(m map[string]interface{}, key string) int {
-	if val, ok := m[key].(float64); ok {
-		return int(val)
-	}
-	return 0
-}
-
-func getFloat(m map[string]interface{}, key string) float64 {
-	if val, ok := m[key].(float64); ok {
-		return val
-	}
-	return 0.0
-}
-
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ResponseCache stores provider responses on disk, one JSON file per key,
+// so identical requests aren't paid for twice across runs
+type ResponseCache struct {
+	dir string
+	ttl time.Duration // Age after which entries are misses (0 = never expire)
+}
+
+// NewResponseCache creates a cache in dir, creating the directory if needed
+func NewResponseCache(dir string) (*ResponseCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory '%s': %w", dir, err)
+	}
+	return &ResponseCache{dir: dir}, nil
+}
+
+// Dir returns the cache directory
+func (c *ResponseCache) Dir() string {
+	return c.dir
+}
+
+// SetTTL makes entries older than ttl misses, so fixes are eventually asked
+// for again, e.g. from a newer model version behind the same name. 0 keeps
+// entries forever.
+func (c *ResponseCache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// Get loads the entry for key into v. It returns false if there is no
+// entry, it has expired or it can't be read; a corrupt entry is treated as
+// a miss.
+func (c *ResponseCache) Get(key string, v interface{}) bool {
+	if c.ttl > 0 {
+		info, err := os.Stat(c.path(key))
+		if err != nil || time.Since(info.ModTime()) > c.ttl {
+			return false
+		}
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Put stores v under key. The entry is written to a temporary file and
+// renamed, so concurrent readers never see a partial entry.
+func (c *ResponseCache) Put(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// HashKey returns a hex SHA-256 cache key over parts. Each part is length
+// prefixed, so ("ab", "c") and ("a", "bc") produce different keys.
+func HashKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
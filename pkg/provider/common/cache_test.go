@@ -0,0 +1,62 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	cache, err := NewResponseCache(dir)
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+
+	type entry struct {
+		Content string
+	}
+
+	var got entry
+	assert.False(t, cache.Get("missing", &got))
+
+	require.NoError(t, cache.Put("k1", entry{Content: "fixed"}))
+	assert.True(t, cache.Get("k1", &got))
+	assert.Equal(t, "fixed", got.Content)
+
+	// Corrupt entries are misses
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0644))
+	assert.False(t, cache.Get("bad", &got))
+
+	_, err = NewResponseCache("")
+	assert.Error(t, err)
+}
+
+func TestResponseCache_TTL(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewResponseCache(dir)
+	require.NoError(t, err)
+	cache.SetTTL(time.Hour)
+
+	var got string
+	require.NoError(t, cache.Put("fresh", "fixed"))
+	assert.True(t, cache.Get("fresh", &got))
+
+	require.NoError(t, cache.Put("stale", "fixed"))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "stale.json"), old, old))
+	assert.False(t, cache.Get("stale", &got), "entries older than the TTL are misses")
+
+	// Without a TTL entries never expire
+	cache.SetTTL(0)
+	assert.True(t, cache.Get("stale", &got))
+}
+
+func TestHashKey(t *testing.T) {
+	assert.Equal(t, HashKey("a", "b"), HashKey("a", "b"))
+	assert.NotEqual(t, HashKey("ab", "c"), HashKey("a", "bc"))
+	assert.Len(t, HashKey("x"), 64)
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// headerTransport adds fixed headers to every outgoing request
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Clone the request so the caller's request is never modified
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Exchange is one HTTP request to a provider and its response, as recorded
+// in a Trace
+type Exchange struct {
+	URL      string
+	Status   int // 0 if the request failed
+	Request  string
+	Response string
+	Duration time.Duration
+	Error    string
+}
+
+// Trace collects the exchanges made by requests with its context (see
+// WithTrace), e.g. the retries and fallbacks of one fix
+type Trace struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// Exchanges returns the exchanges recorded so far, in order
+func (t *Trace) Exchanges() []Exchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Exchange(nil), t.exchanges...)
+}
+
+func (t *Trace) add(exchange Exchange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.exchanges = append(t.exchanges, exchange)
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context whose provider requests are recorded to trace
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// traceFrom returns the context's trace, or nil
+func traceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// observingTransport logs the body of every request and response at debug
+// level: the full prompt and the raw model response. It also records them
+// to the request context's Trace, if any. Headers are never logged or
+// recorded, as they carry the API key.
+type observingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	trace := traceFrom(ctx)
+	debug := slog.Default().Enabled(ctx, slog.LevelDebug)
+	if trace == nil && !debug {
+		return t.base.RoundTrip(req)
+	}
+
+	exchange := Exchange{URL: req.URL.String()}
+	if req.Body != nil && req.GetBody != nil {
+		// GetBody returns a fresh copy, leaving req.Body to be sent
+		if body, err := req.GetBody(); err == nil {
+			content, _ := io.ReadAll(body)
+			body.Close()
+			exchange.Request = string(content)
+		}
+	}
+	if debug {
+		slog.DebugContext(ctx, "provider request", "method", req.Method, "url", exchange.URL, "body", exchange.Request)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		exchange.Duration = time.Since(start)
+		exchange.Error = err.Error()
+		if trace != nil {
+			trace.add(exchange)
+		}
+		if debug {
+			slog.DebugContext(ctx, "provider request failed", "url", exchange.URL, "error", err)
+		}
+		return nil, err
+	}
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// Hand the caller the body just read, and any read error with it
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(content), errReader{err}))
+
+	exchange.Duration = time.Since(start)
+	exchange.Status = resp.StatusCode
+	exchange.Response = string(content)
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	if trace != nil {
+		trace.add(exchange)
+	}
+	if debug {
+		slog.DebugContext(ctx, "provider response", "status", resp.StatusCode, "url", exchange.URL, "body", exchange.Response)
+	}
+	return resp, nil
+}
+
+// errReader returns err, or io.EOF if err is nil
+type errReader struct {
+	err error
+}
+
+// Read implements io.Reader
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// NewHeaderClient returns an HTTP client that sends the given headers with every request.
+// It is used for providers whose SDKs don't support custom headers directly.
+// At debug level, the client logs every request and response body, and it
+// records them to the request context's Trace.
+func NewHeaderClient(headers map[string]string) *http.Client {
+	return &http.Client{
+		Transport: &headerTransport{
+			headers: headers,
+			base:    &observingTransport{base: http.DefaultTransport},
+		},
+	}
+}
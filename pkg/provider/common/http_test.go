@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHeaderClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "kantra-ai/test", r.Header.Get("User-Agent"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"prompt":"fix"}`, string(body), "the body is sent after it's logged")
+		_, _ = w.Write([]byte(`{"content":"fixed"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	post := func() string {
+		client := NewHeaderClient(map[string]string{"User-Agent": "kantra-ai/test", "Authorization": "Bearer secret"})
+		resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"prompt":"fix"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("debug level logs the bodies", func(t *testing.T) {
+		logs.Reset()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		assert.Equal(t, `{"content":"fixed"}`, post(), "the response is still readable")
+		assert.Contains(t, logs.String(), `msg="provider request"`)
+		assert.Contains(t, logs.String(), `body="{\"prompt\":\"fix\"}"`)
+		assert.Contains(t, logs.String(), `msg="provider response" status=200`)
+		assert.Contains(t, logs.String(), `body="{\"content\":\"fixed\"}"`)
+		assert.NotContains(t, logs.String(), "secret", "headers aren't logged")
+	})
+
+	t.Run("info level logs nothing", func(t *testing.T) {
+		logs.Reset()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+		assert.Equal(t, `{"content":"fixed"}`, post())
+		assert.Empty(t, logs.String())
+	})
+}
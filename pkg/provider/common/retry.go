@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries after a rate-limited request
+	DefaultMaxRetries = 3
+	// DefaultBaseDelay is the wait before the first retry; it doubles on each attempt
+	DefaultBaseDelay = 10 * time.Second
+)
+
+var rateLimitRegex = regexp.MustCompile(`(?i)rate.limit|429|too many requests`)
+
+// RetryConfig controls RetryWithBackoff.
+// Zero values use the defaults; a negative MaxRetries disables retries.
+type RetryConfig struct {
+	MaxRetries int           // Maximum number of retries after the first attempt
+	BaseDelay  time.Duration // Delay before the first retry (doubles each retry)
+	Linear     bool          // Grow the delay by BaseDelay each retry instead of doubling it
+}
+
+// withDefaults fills in zero values with the package defaults
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultBaseDelay
+	}
+	return c
+}
+
+// backoff returns the wait before the retry after the given attempt (0-based)
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	if c.Linear {
+		return c.BaseDelay * time.Duration(attempt+1)
+	}
+	return c.BaseDelay * time.Duration(1<<attempt)
+}
+
+// IsRateLimitError checks if an error is a rate limit error (429)
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return rateLimitRegex.MatchString(err.Error())
+}
+
+// RetryWithBackoff calls fn until it succeeds, returns a non rate-limit error,
+// or the retries are exhausted. Rate-limited attempts are retried with
+// exponential backoff (BaseDelay, 2*BaseDelay, 4*BaseDelay, ...), or linear
+// backoff (BaseDelay, 2*BaseDelay, 3*BaseDelay, ...) if Linear is set.
+// The wait is abandoned with ctx.Err() if the context is cancelled.
+func RetryWithBackoff(ctx context.Context, config RetryConfig, fn func() error) error {
+	config = config.withDefaults()
+
+	var err error
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
+		err = fn()
+
+		// Success or an error that retrying won't fix
+		if err == nil || !IsRateLimitError(err) {
+			return err
+		}
+
+		// Max retries reached
+		if attempt == config.MaxRetries {
+			break
+		}
+
+		backoff := config.backoff(attempt)
+		slog.Warn("rate limit hit, waiting before retrying",
+			"wait", backoff, "retry", attempt+1, "max_retries", config.MaxRetries)
+
+		// Wait with context cancellation support
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+			// Continue to next attempt
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRateLimitError(t *testing.T) {
+	assert.False(t, IsRateLimitError(nil))
+	assert.True(t, IsRateLimitError(errors.New("status 429")))
+	assert.True(t, IsRateLimitError(errors.New("Rate limit exceeded")))
+	assert.True(t, IsRateLimitError(errors.New("Too Many Requests")))
+	assert.False(t, IsRateLimitError(errors.New("401 unauthorized")))
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	fast := RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond}
+	rateLimited := errors.New("429 too many requests")
+
+	t.Run("succeeds after rate limits", func(t *testing.T) {
+		calls := 0
+		err := RetryWithBackoff(context.Background(), fast, func() error {
+			calls++
+			if calls < 3 {
+				return rateLimited
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		err := RetryWithBackoff(context.Background(), fast, func() error {
+			calls++
+			return errors.New("invalid request")
+		})
+
+		assert.EqualError(t, err, "invalid request")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		err := RetryWithBackoff(context.Background(), fast, func() error {
+			calls++
+			return rateLimited
+		})
+
+		assert.ErrorIs(t, err, rateLimited)
+		assert.Equal(t, 4, calls) // first attempt + 3 retries
+	})
+
+	t.Run("negative max retries disables retry", func(t *testing.T) {
+		calls := 0
+		err := RetryWithBackoff(context.Background(), RetryConfig{MaxRetries: -1}, func() error {
+			calls++
+			return rateLimited
+		})
+
+		assert.ErrorIs(t, err, rateLimited)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("context cancellation stops waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := RetryWithBackoff(ctx, RetryConfig{MaxRetries: 3, BaseDelay: time.Hour}, func() error {
+			calls++
+			cancel()
+			return rateLimited
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	exponential := RetryConfig{BaseDelay: 10 * time.Second}
+	assert.Equal(t, 10*time.Second, exponential.backoff(0))
+	assert.Equal(t, 20*time.Second, exponential.backoff(1))
+	assert.Equal(t, 40*time.Second, exponential.backoff(2))
+
+	linear := RetryConfig{BaseDelay: 30 * time.Second, Linear: true}
+	assert.Equal(t, 30*time.Second, linear.backoff(0))
+	assert.Equal(t, 60*time.Second, linear.backoff(1))
+	assert.Equal(t, 90*time.Second, linear.backoff(2))
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// MaxBatchContinuations is how many times a batch response cut off by the
+// output token limit is continued before giving up
+const MaxBatchContinuations = 3
+
+// ContinuationPrompt asks the model to continue a response that was cut off
+const ContinuationPrompt = "Your previous response was cut off by the output token limit. " +
+	"Continue it exactly where it stopped, without repeating anything already written " +
+	"and without any introduction or markdown fences, so the two parts join into valid JSON."
+
+// minContinuationOverlap is the shortest repeated text removed when a
+// continuation restarts before the point where the response was cut off
+const minContinuationOverlap = 16
+
+// JSONTruncated reports whether the JSON array or object in text (possibly
+// inside a markdown code block) ends before it is closed, as when a response
+// is cut off by the output token limit. Text without JSON is not truncated.
+func JSONTruncated(text string) bool {
+	start := strings.IndexAny(text, "[{")
+	if start < 0 {
+		return false
+	}
+
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range text[start:] {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// StitchContinuation joins a truncated response and its continuation. Markdown
+// fences opening the continuation and text it repeats from the end of the
+// response are dropped.
+func StitchContinuation(partial, continuation string) string {
+	trimmed := strings.TrimLeft(continuation, " \t\r\n")
+	if strings.HasPrefix(trimmed, "```") {
+		// An opening fence, e.g. ```json, up to the end of its line
+		if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+			continuation = trimmed[newline+1:]
+		}
+	}
+
+	// Longest suffix of partial that the continuation starts with
+	for n := min(len(partial), len(continuation)); n >= minContinuationOverlap; n-- {
+		if strings.HasSuffix(partial, continuation[:n]) {
+			return partial + continuation[n:]
+		}
+	}
+	return partial + continuation
+}
+
+// CompleteTruncated continues a batch response until its JSON is complete.
+// truncated reports whether the API stopped the response at the output token
+// limit; JSON left unclosed counts as truncated too, since not every
+// OpenAI-compatible API reports why it stopped. continueFn asks the model to
+// continue from the response so far, returning the continuation and whether
+// it was truncated as well. The stitched response is returned, with an error
+// if it is still truncated after MaxBatchContinuations.
+func CompleteTruncated(text string, truncated bool, continueFn func(partial string) (string, bool, error)) (string, error) {
+	for i := 0; truncated || JSONTruncated(text); i++ {
+		if i == MaxBatchContinuations {
+			return text, fmt.Errorf("response still truncated after %d continuations, consider a smaller --max-batch-size", MaxBatchContinuations)
+		}
+
+		slog.Info("batch response truncated, continuing", "continuation", i+1, "max_continuations", MaxBatchContinuations)
+		continuation, more, err := continueFn(text)
+		if err != nil {
+			return text, fmt.Errorf("failed to continue truncated response: %w", err)
+		}
+		text = StitchContinuation(text, continuation)
+		truncated = more
+	}
+	return text, nil
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "complete array", text: `[{"a": 1}, {"b": [2]}]`, want: false},
+		{name: "complete in code block", text: "```json\n[{\"a\": 1}]\n```", want: false},
+		{name: "cut off mid-object", text: `[{"a": 1}, {"b": `, want: true},
+		{name: "cut off mid-string", text: `[{"fixed_content": "class A {`, want: true},
+		{name: "brackets in strings don't count", text: `[{"fixed_content": "]}"}]`, want: false},
+		{name: "escaped quote in string", text: `[{"fixed_content": "say \"]\""`, want: true},
+		{name: "no JSON", text: "I cannot fix this", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, JSONTruncated(tt.text))
+		})
+	}
+}
+
+func TestStitchContinuation(t *testing.T) {
+	t.Run("joins the parts", func(t *testing.T) {
+		assert.Equal(t, `[{"a": 1}, {"b": 2}]`, StitchContinuation(`[{"a": 1}, {"b"`, `: 2}]`))
+	})
+
+	t.Run("drops an opening fence", func(t *testing.T) {
+		assert.Equal(t, "```json\n[{\"a\": 1}]\n```",
+			StitchContinuation("```json\n[{\"a\": ", "```json\n1}]\n```"))
+	})
+
+	t.Run("drops text repeated from the end of the response", func(t *testing.T) {
+		partial := `[{"incident_uri": "file:///A.java", "fixed_content": "class`
+		continuation := `"fixed_content": "class A {}"}]`
+		assert.Equal(t, `[{"incident_uri": "file:///A.java", "fixed_content": "class A {}"}]`,
+			StitchContinuation(partial, continuation))
+	})
+}
+
+func TestCompleteTruncated(t *testing.T) {
+	t.Run("truncated then continued assembles valid JSON", func(t *testing.T) {
+		parts := []string{
+			`{"incident_uri": "file:///B.java", "success": true, "fixed_content": "class B {}"`,
+			`}]`,
+		}
+		var partials []string
+		text, err := CompleteTruncated(`[{"incident_uri": "file:///A.java", "success": true}, `, true,
+			func(partial string) (string, bool, error) {
+				partials = append(partials, partial)
+				next := parts[0]
+				parts = parts[1:]
+				return next, len(parts) > 0, nil
+			})
+		require.NoError(t, err)
+		assert.Len(t, partials, 2)
+
+		var fixes []map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(text), &fixes))
+		require.Len(t, fixes, 2)
+		assert.Equal(t, "class B {}", fixes[1]["fixed_content"])
+	})
+
+	t.Run("complete response is not continued", func(t *testing.T) {
+		text, err := CompleteTruncated(`[{"a": 1}]`, false, func(string) (string, bool, error) {
+			t.Fatal("unexpected continuation")
+			return "", false, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `[{"a": 1}]`, text)
+	})
+
+	t.Run("unclosed JSON is continued without a finish reason", func(t *testing.T) {
+		text, err := CompleteTruncated(`[{"a": 1}`, false, func(string) (string, bool, error) {
+			return `]`, false, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `[{"a": 1}]`, text)
+	})
+
+	t.Run("gives up after the maximum continuations", func(t *testing.T) {
+		calls := 0
+		_, err := CompleteTruncated(`[`, true, func(string) (string, bool, error) {
+			calls++
+			return `{"a": 1},`, true, nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "still truncated")
+		assert.Equal(t, MaxBatchContinuations, calls)
+	})
+
+	t.Run("continuation error", func(t *testing.T) {
+		_, err := CompleteTruncated(`[`, true, func(string) (string, bool, error) {
+			return "", false, errors.New("rate limited")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rate limited")
+	})
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ApplyExtraFields appends instructions asking the model to include additional,
+// user-configured fields in its JSON response (in every fix object for batch
+// responses). The core response fields are unchanged. Prompts are returned
+// unchanged when no extra fields are configured.
+func ApplyExtraFields(promptText string, fields []string) string {
+	if len(fields) == 0 {
+		return promptText
+	}
+
+	var b strings.Builder
+	b.WriteString(promptText)
+	b.WriteString("\n\nADDITIONAL RESPONSE FIELDS:\n")
+	b.WriteString("In addition to the fields described above, include these fields in the JSON response ")
+	b.WriteString("(in every fix object when returning an array):\n")
+	for _, field := range fields {
+		fmt.Fprintf(&b, "- %q\n", field)
+	}
+	return b.String()
+}
+
+// ExtractExtraFields returns the configured extra fields present in a JSON
+// object response, or nil if none are present (or the response isn't an object).
+func ExtractExtraFields(jsonData []byte, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return nil
+	}
+	return pickExtraFields(raw, fields)
+}
+
+// ExtractBatchExtraFields returns the configured extra fields of each object in
+// a JSON array response, indexed like the array. It returns nil if no extra
+// fields are configured or the response isn't an array.
+func ExtractBatchExtraFields(jsonData []byte, fields []string) []map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return nil
+	}
+	extras := make([]map[string]interface{}, len(raw))
+	for i, obj := range raw {
+		extras[i] = pickExtraFields(obj, fields)
+	}
+	return extras
+}
+
+// pickExtraFields decodes the configured fields from a parsed JSON object
+func pickExtraFields(raw map[string]json.RawMessage, fields []string) map[string]interface{} {
+	var extra map[string]interface{}
+	for _, field := range fields {
+		value, ok := raw[field]
+		if !ok {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[field] = decoded
+	}
+	return extra
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyExtraFields(t *testing.T) {
+	assert.Equal(t, "prompt", ApplyExtraFields("prompt", nil))
+
+	got := ApplyExtraFields("prompt", []string{"risk_notes", "ticket"})
+	assert.Contains(t, got, "prompt\n\nADDITIONAL RESPONSE FIELDS:")
+	assert.Contains(t, got, `- "risk_notes"`)
+	assert.Contains(t, got, `- "ticket"`)
+}
+
+func TestExtractExtraFields(t *testing.T) {
+	response := []byte(`{"fixed_content": "x", "confidence": 0.9, "risk_notes": "touches auth", "tags": ["a", "b"]}`)
+
+	t.Run("present fields are captured", func(t *testing.T) {
+		extra := ExtractExtraFields(response, []string{"risk_notes", "tags", "missing"})
+		require.NotNil(t, extra)
+		assert.Equal(t, "touches auth", extra["risk_notes"])
+		assert.Equal(t, []interface{}{"a", "b"}, extra["tags"])
+		assert.NotContains(t, extra, "missing")
+		assert.NotContains(t, extra, "confidence", "core fields are not duplicated")
+	})
+
+	t.Run("absent fields give nil", func(t *testing.T) {
+		assert.Nil(t, ExtractExtraFields(response, []string{"missing"}))
+	})
+
+	t.Run("no configured fields", func(t *testing.T) {
+		assert.Nil(t, ExtractExtraFields(response, nil))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		assert.Nil(t, ExtractExtraFields([]byte("not json"), []string{"risk_notes"}))
+	})
+}
+
+func TestExtractBatchExtraFields(t *testing.T) {
+	response := []byte(`[
+		{"incident_uri": "file:///a.java", "risk_notes": "low"},
+		{"incident_uri": "file:///b.java"}
+	]`)
+
+	extras := ExtractBatchExtraFields(response, []string{"risk_notes"})
+	require.Len(t, extras, 2)
+	assert.Equal(t, map[string]interface{}{"risk_notes": "low"}, extras[0])
+	assert.Nil(t, extras[1])
+
+	assert.Nil(t, ExtractBatchExtraFields(response, nil))
+	assert.Nil(t, ExtractBatchExtraFields([]byte(`{"not": "an array"}`), []string{"risk_notes"}))
+}
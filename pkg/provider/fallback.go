@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// FallbackProvider tries a chain of providers in order: when one fails to
+// produce a fix (an API error that survived its retries, or no response),
+// the same request goes to the next. Responses name the provider that
+// produced them, and spend is tracked per provider, including failed
+// attempts.
+type FallbackProvider struct {
+	providers []Provider
+
+	mu    sync.Mutex
+	spend map[string]float64
+	order []string // Providers in the order they first spent anything
+}
+
+// NewFallbackProvider returns a provider trying providers in order. The
+// first is the primary provider, whose name and cost estimates are used.
+func NewFallbackProvider(providers ...Provider) (*FallbackProvider, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("fallback chain must contain at least one provider")
+	}
+	return &FallbackProvider{
+		providers: providers,
+		spend:     make(map[string]float64),
+	}, nil
+}
+
+// FallbackSpec is a provider in a fallback chain
+type FallbackSpec struct {
+	Name  string
+	Model string // Empty = the provider's default model
+}
+
+// ParseFallbackChain parses a comma-separated --provider-fallback list of
+// providers, each optionally with a model (e.g. "openai:gpt-4o,groq"), into
+// the providers to try after primary. primary is dropped from the list, so
+// the chain may be given with or without it.
+func ParseFallbackChain(chain, primary string) ([]FallbackSpec, error) {
+	var specs []FallbackSpec
+	seen := map[string]bool{primary: true}
+	for _, entry := range strings.Split(chain, ",") {
+		entry = strings.TrimSpace(entry)
+		name, model, _ := strings.Cut(entry, ":")
+		if name == "" || seen[name] {
+			continue
+		}
+		if name == ReplayProviderName {
+			return nil, fmt.Errorf("the %s provider can't be a fallback", ReplayProviderName)
+		}
+		seen[name] = true
+		specs = append(specs, FallbackSpec{Name: name, Model: model})
+	}
+	return specs, nil
+}
+
+// Name returns the primary provider's name
+func (f *FallbackProvider) Name() string {
+	return f.providers[0].Name()
+}
+
+// EstimateCost uses the primary provider's pricing
+func (f *FallbackProvider) EstimateCost(req FixRequest) (float64, error) {
+	return f.providers[0].EstimateCost(req)
+}
+
+// FixViolation asks each provider in turn until one returns a fix or a
+// response without an error. The returned cost includes failed attempts.
+func (f *FallbackProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	var resp *FixResponse
+	var err error
+	var cost float64
+	var tokens int
+	for i, p := range f.providers {
+		if i > 0 {
+			f.notice(f.providers[i-1].Name(), p.Name(), fixError(resp, err))
+		}
+		resp, err = p.FixViolation(ctx, req)
+		if resp != nil {
+			f.recordSpend(p.Name(), resp.Cost)
+			cost += resp.Cost
+			tokens += resp.TokensUsed
+			resp.Cost = cost
+			resp.TokensUsed = tokens
+			resp.Provider = p.Name()
+		}
+		if ctx.Err() != nil || !fixFailed(resp, err) {
+			break
+		}
+	}
+	return resp, err
+}
+
+// FixBatch asks each provider in turn until one processes the batch. A batch
+// that was processed with some incidents failing isn't retried.
+func (f *FallbackProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	var resp *BatchResponse
+	var err error
+	var cost float64
+	var tokens int
+	for i, p := range f.providers {
+		if i > 0 {
+			f.notice(f.providers[i-1].Name(), p.Name(), batchError(resp, err))
+		}
+		resp, err = p.FixBatch(ctx, req)
+		if resp != nil {
+			f.recordSpend(p.Name(), resp.Cost)
+			cost += resp.Cost
+			tokens += resp.TokensUsed
+			resp.Cost = cost
+			resp.TokensUsed = tokens
+			for j := range resp.Fixes {
+				resp.Fixes[j].Provider = p.Name()
+			}
+		}
+		if ctx.Err() != nil || !batchFailed(resp, err) {
+			break
+		}
+	}
+	return resp, err
+}
+
+// GeneratePlan asks each provider in turn until one returns a plan
+func (f *FallbackProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	var resp *PlanResponse
+	var err error
+	var cost float64
+	var tokens int
+	for i, p := range f.providers {
+		if i > 0 {
+			prevErr := err
+			if prevErr == nil && resp != nil {
+				prevErr = resp.Error
+			}
+			f.notice(f.providers[i-1].Name(), p.Name(), prevErr)
+		}
+		resp, err = p.GeneratePlan(ctx, req)
+		if resp != nil {
+			f.recordSpend(p.Name(), resp.Cost)
+			cost += resp.Cost
+			tokens += resp.TokensUsed
+			resp.Cost = cost
+			resp.TokensUsed = tokens
+		}
+		if ctx.Err() != nil || (err == nil && resp != nil && resp.Error == nil) {
+			break
+		}
+	}
+	return resp, err
+}
+
+// Spend returns the cost so far of each provider that was called, in the
+// order they were first called
+func (f *FallbackProvider) Spend() (providers []string, costs map[string]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	costs = make(map[string]float64, len(f.spend))
+	for name, cost := range f.spend {
+		costs[name] = cost
+	}
+	return append([]string(nil), f.order...), costs
+}
+
+func (f *FallbackProvider) recordSpend(name string, cost float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.spend[name]; !ok {
+		f.order = append(f.order, name)
+	}
+	f.spend[name] += cost
+}
+
+func (f *FallbackProvider) notice(from, to string, err error) {
+	reason := "no response"
+	if err != nil {
+		reason = err.Error()
+		if newline := strings.IndexByte(reason, '\n'); newline >= 0 {
+			reason = reason[:newline]
+		}
+	}
+	slog.Warn("provider failed, falling back", "provider", from, "error", reason, "fallback", to)
+}
+
+// fixFailed reports whether a fix attempt failed in a way another provider
+// might not: an error rather than a response
+func fixFailed(resp *FixResponse, err error) bool {
+	return err != nil || resp == nil || (!resp.Success && resp.Error != nil)
+}
+
+func fixError(resp *FixResponse, err error) error {
+	if err == nil && resp != nil {
+		return resp.Error
+	}
+	return err
+}
+
+// batchFailed reports whether a batch failed as a whole
+func batchFailed(resp *BatchResponse, err error) bool {
+	return err != nil || resp == nil || (resp.Error != nil && len(resp.Fixes) == 0)
+}
+
+func batchError(resp *BatchResponse, err error) error {
+	if err == nil && resp != nil {
+		return resp.Error
+	}
+	return err
+}
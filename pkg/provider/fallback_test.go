@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// namedProvider is a countingProvider with its own name
+type namedProvider struct {
+	*countingProvider
+	name string
+}
+
+func (p namedProvider) Name() string { return p.name }
+
+// erroringProvider fails every request with an API error
+type erroringProvider struct {
+	namedProvider
+}
+
+func (p erroringProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	p.calls++
+	return &FixResponse{Success: false, Error: errors.New("rate limit exceeded after 3 retries"), TokensUsed: 10, Cost: 0.001}, nil
+}
+
+func (p erroringProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	p.calls++
+	return nil, errors.New("service unavailable")
+}
+
+func TestFallbackProvider_FixViolation(t *testing.T) {
+	claude := erroringProvider{namedProvider{&countingProvider{}, "claude"}}
+	openai := namedProvider{&countingProvider{success: true}, "openai"}
+	groq := namedProvider{&countingProvider{success: true}, "groq"}
+	chain, err := NewFallbackProvider(claude, openai, groq)
+	require.NoError(t, err)
+
+	resp, err := chain.FixViolation(context.Background(), FixRequest{FileContent: "code"})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "openai", resp.Provider)
+	assert.InDelta(t, 0.011, resp.Cost, 1e-9, "includes the failed attempt")
+	assert.Equal(t, 110, resp.TokensUsed)
+	assert.Equal(t, 1, claude.calls)
+	assert.Equal(t, 1, openai.calls)
+	assert.Equal(t, 0, groq.calls)
+
+	names, costs := chain.Spend()
+	assert.Equal(t, []string{"claude", "openai"}, names)
+	assert.InDelta(t, 0.001, costs["claude"], 1e-9)
+	assert.InDelta(t, 0.01, costs["openai"], 1e-9)
+	assert.Equal(t, "claude", chain.Name())
+}
+
+func TestFallbackProvider_DoesNotRetryModelFailures(t *testing.T) {
+	primary := namedProvider{&countingProvider{success: false}, "claude"}
+	fallback := namedProvider{&countingProvider{success: true}, "openai"}
+	chain, err := NewFallbackProvider(primary, fallback)
+	require.NoError(t, err)
+
+	batch := BatchRequest{Incidents: []violation.Incident{{URI: "file:///A.java"}}}
+	resp, err := chain.FixBatch(context.Background(), batch)
+	require.NoError(t, err)
+	assert.False(t, resp.Success, "a processed batch with failed fixes is returned as is")
+	assert.Equal(t, "claude", resp.Fixes[0].Provider)
+	assert.Equal(t, 0, fallback.calls)
+}
+
+func TestFallbackProvider_FixBatch(t *testing.T) {
+	primary := erroringProvider{namedProvider{&countingProvider{}, "claude"}}
+	fallback := namedProvider{&countingProvider{success: true}, "groq"}
+	chain, err := NewFallbackProvider(primary, fallback)
+	require.NoError(t, err)
+
+	batch := BatchRequest{Incidents: []violation.Incident{{URI: "file:///A.java"}, {URI: "file:///B.java"}}}
+	resp, err := chain.FixBatch(context.Background(), batch)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	for _, fix := range resp.Fixes {
+		assert.Equal(t, "groq", fix.Provider)
+	}
+}
+
+func TestFallbackProvider_AllFail(t *testing.T) {
+	chain, err := NewFallbackProvider(
+		erroringProvider{namedProvider{&countingProvider{}, "claude"}},
+		erroringProvider{namedProvider{&countingProvider{}, "openai"}},
+	)
+	require.NoError(t, err)
+
+	resp, err := chain.FixViolation(context.Background(), FixRequest{})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, "openai", resp.Provider)
+	assert.InDelta(t, 0.002, resp.Cost, 1e-9)
+}
+
+func TestParseFallbackChain(t *testing.T) {
+	specs, err := ParseFallbackChain("claude, openai:gpt-4o,groq,openai", "claude")
+	require.NoError(t, err)
+	assert.Equal(t, []FallbackSpec{{Name: "openai", Model: "gpt-4o"}, {Name: "groq"}}, specs)
+
+	_, err = ParseFallbackChain("openai,replay", "claude")
+	assert.Error(t, err)
+
+	_, err = NewFallbackProvider()
+	assert.Error(t, err)
+}
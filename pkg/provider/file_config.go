@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileConfig is the JSON form of a provider configuration, loaded with
+// --provider-config so scripts can pass all provider settings in one file.
+type FileConfig struct {
+	Name            string            `json:"name"`
+	Model           string            `json:"model"`
+	BaseURL         string            `json:"base_url"`
+	Temperature     float64           `json:"temperature"`
+	MaxTokens       int               `json:"max_tokens"`
+	Headers         map[string]string `json:"headers"`
+	MaxRetries      int               `json:"max_retries"`
+	RetryBaseDelay  string            `json:"retry_base_delay"` // Go duration, e.g. "10s"
+	ExtraFields     []string          `json:"extra_fields"`
+	MaxPromptTokens int               `json:"max_prompt_tokens"`
+}
+
+// LoadFileConfig reads a provider configuration JSON file
+func LoadFileConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider config: %w", err)
+	}
+
+	var fc FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config %s: %w", path, err)
+	}
+
+	if fc.RetryBaseDelay != "" {
+		if _, err := time.ParseDuration(fc.RetryBaseDelay); err != nil {
+			return nil, fmt.Errorf("invalid retry_base_delay %q in provider config: %w", fc.RetryBaseDelay, err)
+		}
+	}
+
+	return &fc, nil
+}
+
+// Apply merges the file's values into config. Values already set in config
+// (from CLI flags) take precedence; only zero-valued fields are filled in.
+// Headers are merged key by key with the same precedence.
+func (fc *FileConfig) Apply(config *Config) {
+	if fc == nil {
+		return
+	}
+
+	if config.Name == "" {
+		config.Name = fc.Name
+	}
+	if config.Model == "" {
+		config.Model = fc.Model
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = fc.BaseURL
+	}
+	if config.Temperature == 0 {
+		config.Temperature = fc.Temperature
+	}
+	if config.MaxTokens == 0 {
+		config.MaxTokens = fc.MaxTokens
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = fc.MaxRetries
+	}
+	if config.RetryBaseDelay == 0 && fc.RetryBaseDelay != "" {
+		// Validated in LoadFileConfig
+		config.RetryBaseDelay, _ = time.ParseDuration(fc.RetryBaseDelay)
+	}
+	if len(config.ExtraFields) == 0 {
+		config.ExtraFields = fc.ExtraFields
+	}
+	if config.MaxPromptTokens == 0 {
+		config.MaxPromptTokens = fc.MaxPromptTokens
+	}
+
+	if len(fc.Headers) > 0 {
+		if config.Headers == nil {
+			config.Headers = make(map[string]string, len(fc.Headers))
+		}
+		for key, value := range fc.Headers {
+			if _, ok := config.Headers[key]; !ok {
+				config.Headers[key] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProviderConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "provider.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+const testProviderJSON = `{
+  "name": "openai",
+  "model": "gpt-4o",
+  "base_url": "https://gateway.example.com/v1",
+  "temperature": 0.4,
+  "max_tokens": 2048,
+  "headers": {"X-Team": "migration", "X-Env": "ci"},
+  "max_retries": 5,
+  "retry_base_delay": "2s"
+}`
+
+func TestLoadFileConfig(t *testing.T) {
+	t.Run("valid file", func(t *testing.T) {
+		fc, err := LoadFileConfig(writeProviderConfig(t, testProviderJSON))
+		require.NoError(t, err)
+
+		assert.Equal(t, "openai", fc.Name)
+		assert.Equal(t, "gpt-4o", fc.Model)
+		assert.Equal(t, 2048, fc.MaxTokens)
+		assert.Equal(t, "ci", fc.Headers["X-Env"])
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadFileConfig(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := LoadFileConfig(writeProviderConfig(t, "{not json"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid retry delay", func(t *testing.T) {
+		_, err := LoadFileConfig(writeProviderConfig(t, `{"retry_base_delay": "soon"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "retry_base_delay")
+	})
+}
+
+func TestFileConfig_Apply(t *testing.T) {
+	fc, err := LoadFileConfig(writeProviderConfig(t, testProviderJSON))
+	require.NoError(t, err)
+
+	t.Run("file populates empty config", func(t *testing.T) {
+		var config Config
+		fc.Apply(&config)
+
+		assert.Equal(t, "openai", config.Name)
+		assert.Equal(t, "gpt-4o", config.Model)
+		assert.Equal(t, "https://gateway.example.com/v1", config.BaseURL)
+		assert.Equal(t, 0.4, config.Temperature)
+		assert.Equal(t, 2048, config.MaxTokens)
+		assert.Equal(t, map[string]string{"X-Team": "migration", "X-Env": "ci"}, config.Headers)
+		assert.Equal(t, 5, config.MaxRetries)
+		assert.Equal(t, 2*time.Second, config.RetryBaseDelay)
+	})
+
+	t.Run("flags win on conflict", func(t *testing.T) {
+		config := Config{
+			Name:      "groq",
+			Model:     "llama-3.1-70b-versatile",
+			MaxTokens: 1024,
+			Headers:   map[string]string{"X-Env": "local"},
+		}
+		fc.Apply(&config)
+
+		assert.Equal(t, "groq", config.Name)
+		assert.Equal(t, "llama-3.1-70b-versatile", config.Model)
+		assert.Equal(t, 1024, config.MaxTokens)
+		assert.Equal(t, "local", config.Headers["X-Env"])
+		// Non-conflicting values still come from the file
+		assert.Equal(t, "migration", config.Headers["X-Team"])
+		assert.Equal(t, "https://gateway.example.com/v1", config.BaseURL)
+	})
+
+	t.Run("nil file config is a no-op", func(t *testing.T) {
+		var nilConfig *FileConfig
+		config := Config{Name: "claude"}
+		nilConfig.Apply(&config)
+		assert.Equal(t, Config{Name: "claude"}, config)
+	})
+}
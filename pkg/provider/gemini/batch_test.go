@@ -0,0 +1,72 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestParseBatchResponse(t *testing.T) {
+	p := &Provider{}
+
+	incidents := []violation.Incident{
+		{URI: "file:///test1.java", LineNumber: 10},
+		{URI: "file:///test2.java", LineNumber: 20},
+	}
+
+	t.Run("valid JSON in code block", func(t *testing.T) {
+		responseText := "```json\n" +
+			"[\n" +
+			"  {\"incident_uri\": \"file:///test1.java:10\", \"success\": true, \"fixed_content\": \"fixed 1\", \"explanation\": \"Fixed 1\", \"confidence\": 0.95},\n" +
+			"  {\"incident_uri\": \"file:///test2.java:20\", \"success\": false, \"fixed_content\": \"\", \"explanation\": \"Could not fix\", \"confidence\": 0.3}\n" +
+			"]\n" +
+			"```\n"
+
+		fixes, err := p.parseBatchResponse(responseText, incidents)
+		require.NoError(t, err)
+		require.Len(t, fixes, 2)
+
+		assert.True(t, fixes[0].Success)
+		assert.Equal(t, "fixed 1", fixes[0].FixedContent)
+		assert.Equal(t, 0.95, fixes[0].Confidence)
+		assert.Nil(t, fixes[0].Error)
+
+		assert.False(t, fixes[1].Success)
+		require.Error(t, fixes[1].Error)
+		assert.Equal(t, "Could not fix", fixes[1].Error.Error())
+	})
+
+	t.Run("count mismatch", func(t *testing.T) {
+		responseText := `[{"incident_uri": "file:///test1.java:10", "success": true}]`
+
+		_, err := p.parseBatchResponse(responseText, incidents)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 2 fixes but got 1")
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := p.parseBatchResponse("not json", incidents)
+		assert.Error(t, err)
+	})
+}
+
+func TestExtractJSONFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"json code block", "```json\n[{\"a\": 1}]\n```", `[{"a": 1}]`},
+		{"plain code block", "```\n[1, 2]\n```", `[1, 2]`},
+		{"raw array with prose", "Here you go: [1, 2] done", `[1, 2]`},
+		{"no JSON", "nothing here", "nothing here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(extractJSONFromMarkdown(tt.text)))
+		})
+	}
+}
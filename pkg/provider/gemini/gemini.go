@@ -0,0 +1,341 @@
+// Package gemini implements the Google Gemini AI provider.
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/tsanders/kantra-ai/pkg/prompt"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"google.golang.org/api/option"
+)
+
+const (
+	// DefaultModel is the Gemini model used when none is configured
+	DefaultModel = "gemini-2.5-pro"
+	// DefaultMaxTokens is the default maximum tokens for fix generation
+	DefaultMaxTokens = 4096
+	// PlanningMaxTokens is the maximum tokens for plan generation (requires more output)
+	PlanningMaxTokens = 8192
+)
+
+// DefaultPricing is Gemini 2.5 Pro's list price (prompts up to 200k tokens),
+// used unless configured
+var DefaultPricing = provider.Pricing{InputPerMillion: 1.25, OutputPerMillion: 10.0}
+
+// Provider implements the Google Gemini provider
+type Provider struct {
+	client          *genai.Client
+	model           string
+	temperature     float32
+	templates       *prompt.Templates
+	retry           common.RetryConfig
+	maxTokens       int32
+	extraFields     []string
+	maxPromptTokens int
+	pricing         provider.Pricing
+	responseFormat  provider.ResponseFormat
+}
+
+// New creates a new Gemini provider
+func New(config provider.Config) (*Provider, error) {
+	apiKey := config.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable is not set\n\n" +
+			"To use Gemini (Google):\n" +
+			"  1. Get an API key from: https://aistudio.google.com/app/apikey\n" +
+			"  2. Export it as an environment variable:\n" +
+			"     export GOOGLE_API_KEY=...\n" +
+			"  3. Or set it in your shell profile (~/.bashrc, ~/.zshrc)\n\n" +
+			"Alternatively, use Claude instead:\n" +
+			"  --provider=claude")
+	}
+
+	model := config.Model
+	if model == "" {
+		model = DefaultModel
+	}
+
+	temperature := float32(config.Temperature)
+	if temperature == 0 {
+		temperature = 0.2 // Low temperature for code fixes
+	}
+
+	maxTokens := config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
+	clientOptions := []option.ClientOption{option.WithAPIKey(apiKey)}
+	// Send the User-Agent and extra headers. A custom HTTP client replaces the
+	// SDK's API key handling, so send the key as a header too.
+	headers := config.RequestHeaders()
+	headers["x-goog-api-key"] = apiKey
+	clientOptions = append(clientOptions, option.WithHTTPClient(common.NewHeaderClient(headers)))
+
+	// Client creation does not contact the API; connections are made lazily
+	client, err := genai.NewClient(context.Background(), clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	// Load templates (use defaults if not provided)
+	templates := config.Templates
+	if templates == nil {
+		templates, err = prompt.Load(prompt.Config{
+			Provider: "gemini",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load default templates: %w", err)
+		}
+	}
+
+	return &Provider{
+		client:          client,
+		model:           model,
+		temperature:     temperature,
+		templates:       templates,
+		maxTokens:       int32(maxTokens),
+		extraFields:     config.ExtraFields,
+		maxPromptTokens: config.MaxPromptTokens,
+		pricing:         config.PricingOr(DefaultPricing),
+		responseFormat:  config.ResponseFormat,
+		retry: common.RetryConfig{
+			MaxRetries: config.MaxRetries,
+			BaseDelay:  config.RetryBaseDelay,
+		},
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "gemini"
+}
+
+// FixViolation sends the violation to Gemini and gets a fix
+func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*provider.FixResponse, error) {
+	format := p.responseFormatFor(req)
+	promptText, err := p.fixPrompt(req)
+	if err != nil {
+		return &provider.FixResponse{
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	// Fail fast with a clear error instead of a cryptic API error
+	if err := provider.CheckPromptSize(promptText, provider.PromptTokenLimit(p.model, p.maxPromptTokens, int(p.maxTokens))); err != nil {
+		return &provider.FixResponse{
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	resp, err := p.generate(ctx, promptText, p.temperature, p.maxTokens)
+	if err != nil {
+		return &provider.FixResponse{
+			Success: false,
+			Error:   enhanceAPIError(err),
+		}, nil
+	}
+
+	text := responseText(resp)
+	inputTokens, outputTokens := usage(resp)
+
+	// Parse JSON response
+	type Response struct {
+		FixedContent string  `json:"fixed_content"`
+		Patch        string  `json:"patch"`
+		Confidence   float64 `json:"confidence"`
+		Explanation  string  `json:"explanation"`
+	}
+
+	// Try to extract JSON from response (may be wrapped in markdown)
+	jsonData := extractJSONFromMarkdown(text)
+
+	var parsedResp Response
+	if err := json.Unmarshal(jsonData, &parsedResp); err != nil {
+		// If JSON parsing fails, fall back to treating entire response as code with default confidence
+		fallback := &provider.FixResponse{
+			Success:      true,
+			FixedContent: text,
+			Explanation:  "Fixed by Gemini (JSON parse failed, using raw response)",
+			Confidence:   0.85, // Default when JSON parsing fails
+			TokensUsed:   inputTokens + outputTokens,
+			Cost:         p.calculateCost(inputTokens, outputTokens),
+		}
+		// In diff mode a raw response is most likely a bare patch
+		provider.PreferPatch(fallback, format, text, true)
+		return fallback, nil
+	}
+
+	// Validate confidence range
+	if parsedResp.Confidence < 0.0 || parsedResp.Confidence > 1.0 {
+		parsedResp.Confidence = 0.85 // Clamp to reasonable default
+	}
+
+	fixResp := &provider.FixResponse{
+		Success:      true,
+		FixedContent: parsedResp.FixedContent,
+		Explanation:  parsedResp.Explanation,
+		Confidence:   parsedResp.Confidence,
+		TokensUsed:   inputTokens + outputTokens,
+		Cost:         p.calculateCost(inputTokens, outputTokens),
+		Extra:        provider.ExtractExtraFields(jsonData, p.extraFields),
+	}
+	// Prefer the patch in diff mode
+	provider.PreferPatch(fixResp, format, parsedResp.Patch, false)
+	return fixResp, nil
+}
+
+// EstimateCost estimates the cost for fixing a violation
+func (p *Provider) EstimateCost(req provider.FixRequest) (float64, error) {
+	promptText, err := p.fixPrompt(req)
+	if err != nil {
+		return 0, err
+	}
+	return p.calculateCost(provider.EstimateFixTokens(p.model, promptText)), nil
+}
+
+// fixPrompt renders the prompt for a single fix request
+func (p *Provider) fixPrompt(req provider.FixRequest) (string, error) {
+	data := provider.BuildSingleFixData(req)
+	// Select language-specific template or fall back to base template
+	tmpl := p.templates.GetSingleFixTemplate(data.Language)
+	promptText, err := tmpl.RenderSingleFix(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+
+	// Request a unified diff instead of the whole file if configured
+	promptText = provider.ApplyResponseFormat(promptText, p.responseFormatFor(req))
+	return provider.ApplyExtraFields(promptText, p.extraFields), nil
+}
+
+// responseFormatFor returns the response format of a fix request: its own,
+// or the provider's
+func (p *Provider) responseFormatFor(req provider.FixRequest) provider.ResponseFormat {
+	if req.ResponseFormat != "" {
+		return req.ResponseFormat
+	}
+	return p.responseFormat
+}
+
+// GeneratePlan generates a phased migration plan using Gemini.
+// Gemini's large context window lets all violations be planned in a single request.
+func (p *Provider) GeneratePlan(ctx context.Context, req provider.PlanRequest) (*provider.PlanResponse, error) {
+	promptText := provider.BuildPlanPrompt(req)
+
+	// Slightly higher temperature for creativity in planning
+	resp, err := p.generate(ctx, promptText, 0.3, PlanningMaxTokens)
+	if err != nil {
+		return &provider.PlanResponse{
+			Error: enhanceAPIError(err),
+		}, nil
+	}
+
+	phases, err := provider.ParsePlanResponse(responseText(resp), req.Violations)
+	if err != nil {
+		return &provider.PlanResponse{
+			Error: fmt.Errorf("failed to parse plan response: %w", err),
+		}, nil
+	}
+
+	inputTokens, outputTokens := usage(resp)
+
+	return &provider.PlanResponse{
+		Phases:     phases,
+		TokensUsed: inputTokens + outputTokens,
+		Cost:       p.calculateCost(inputTokens, outputTokens),
+	}, nil
+}
+
+// generate sends a single prompt to the configured Gemini model,
+// retrying with backoff if the request is rate limited
+func (p *Provider) generate(ctx context.Context, promptText string, temperature float32, maxTokens int32) (*genai.GenerateContentResponse, error) {
+	model := p.client.GenerativeModel(p.model)
+	model.SetTemperature(temperature)
+	model.SetMaxOutputTokens(maxTokens)
+
+	var resp *genai.GenerateContentResponse
+	err := common.RetryWithBackoff(ctx, p.retry, func() error {
+		var apiErr error
+		resp, apiErr = model.GenerateContent(ctx, genai.Text(promptText))
+		return apiErr
+	})
+	return resp, err
+}
+
+// continueGeneration asks the model to continue its response to promptText,
+// which was cut off after partial
+func (p *Provider) continueGeneration(ctx context.Context, promptText, partial string, temperature float32, maxTokens int32) (*genai.GenerateContentResponse, error) {
+	model := p.client.GenerativeModel(p.model)
+	model.SetTemperature(temperature)
+	model.SetMaxOutputTokens(maxTokens)
+
+	var resp *genai.GenerateContentResponse
+	err := common.RetryWithBackoff(ctx, p.retry, func() error {
+		// A new session per attempt, since sending adds to the history
+		chat := model.StartChat()
+		chat.History = []*genai.Content{
+			genai.NewUserContent(genai.Text(promptText)),
+			{Role: "model", Parts: []genai.Part{genai.Text(partial)}},
+		}
+		var apiErr error
+		resp, apiErr = chat.SendMessage(ctx, genai.Text(provider.ContinuationPrompt))
+		return apiErr
+	})
+	return resp, err
+}
+
+// maxTokensReached reports whether a response stopped at the output token limit
+func maxTokensReached(resp *genai.GenerateContentResponse) bool {
+	return resp != nil && len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonMaxTokens
+}
+
+// responseText concatenates the text parts of the first candidate
+func responseText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return sb.String()
+}
+
+// usage returns the input and output token counts of a response
+func usage(resp *genai.GenerateContentResponse) (int, int) {
+	if resp == nil || resp.UsageMetadata == nil {
+		return 0, 0
+	}
+	return int(resp.UsageMetadata.PromptTokenCount), int(resp.UsageMetadata.CandidatesTokenCount)
+}
+
+// calculateCost converts token counts to USD using the provider's pricing
+func (p *Provider) calculateCost(inputTokens, outputTokens int) float64 {
+	return p.pricing.Cost(inputTokens, outputTokens)
+}
+
+// enhanceAPIError adds helpful context to Gemini API errors using the common error handler.
+func enhanceAPIError(err error) error {
+	return common.EnhanceAPIError(err, common.ProviderErrorContext{
+		ProviderName:      "Google",
+		APIKeysURL:        "https://aistudio.google.com/app/apikey",
+		StatusPageURL:     "https://aistudio.google.com/status",
+		BillingURL:        "https://console.cloud.google.com/billing",
+		AlternateProvider: "claude",
+	})
+}
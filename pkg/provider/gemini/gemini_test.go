@@ -0,0 +1,121 @@
+package gemini
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("with API key in config", func(t *testing.T) {
+		config := provider.Config{
+			APIKey:      "test-api-key",
+			Model:       "gemini-2.5-flash",
+			Temperature: 0.3,
+		}
+
+		p, err := New(config)
+		require.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, "gemini-2.5-flash", p.model)
+		assert.Equal(t, float32(0.3), p.temperature)
+	})
+
+	t.Run("with default model", func(t *testing.T) {
+		config := provider.Config{
+			APIKey: "test-api-key",
+		}
+
+		p, err := New(config)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultModel, p.model)
+		assert.Equal(t, float32(0.2), p.temperature) // Default temperature
+	})
+
+	t.Run("with environment variable", func(t *testing.T) {
+		os.Setenv("GOOGLE_API_KEY", "env-api-key")
+		defer os.Unsetenv("GOOGLE_API_KEY")
+
+		p, err := New(provider.Config{})
+		require.NoError(t, err)
+		assert.NotNil(t, p)
+	})
+
+	t.Run("missing API key", func(t *testing.T) {
+		os.Unsetenv("GOOGLE_API_KEY")
+
+		_, err := New(provider.Config{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GOOGLE_API_KEY environment variable is not set")
+		assert.Contains(t, err.Error(), "https://aistudio.google.com/app/apikey")
+	})
+}
+
+func TestProvider_Name(t *testing.T) {
+	p, err := New(provider.Config{APIKey: "test"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gemini", p.Name())
+}
+
+func TestProvider_EstimateCost(t *testing.T) {
+	p, err := New(provider.Config{APIKey: "test"})
+	require.NoError(t, err)
+
+	req := provider.FixRequest{
+		Violation: violation.Violation{ID: "test"},
+	}
+	cost, err := p.EstimateCost(req)
+	require.NoError(t, err)
+
+	assert.Greater(t, cost, 0.0)
+
+	// The estimate scales with the file in the prompt: its tokens, plus half
+	// as many output tokens, at $1.25/1M input and $10.0/1M output
+	req.FileContent = strings.Repeat("import javax.servlet.http.HttpServlet;\n", 2000)
+	large, err := p.EstimateCost(req)
+	require.NoError(t, err)
+	fileTokens := float64(provider.CountTokens(p.model, req.FileContent))
+	minimum := fileTokens*1.25/1000000.0 + fileTokens*provider.FixOutputRatio*10.0/1000000.0
+	assert.GreaterOrEqual(t, large, minimum)
+	assert.InEpsilon(t, minimum, large, 0.1, "the template adds little to a large file")
+}
+
+func TestResponseTextAndUsage(t *testing.T) {
+	t.Run("concatenates text parts", func(t *testing.T) {
+		resp := &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{
+				{Content: &genai.Content{Parts: []genai.Part{genai.Text("{\"a\":"), genai.Text("1}")}}},
+			},
+			UsageMetadata: &genai.UsageMetadata{PromptTokenCount: 120, CandidatesTokenCount: 30},
+		}
+
+		assert.Equal(t, "{\"a\":1}", responseText(resp))
+		in, out := usage(resp)
+		assert.Equal(t, 120, in)
+		assert.Equal(t, 30, out)
+	})
+
+	t.Run("handles empty responses", func(t *testing.T) {
+		assert.Equal(t, "", responseText(nil))
+		assert.Equal(t, "", responseText(&genai.GenerateContentResponse{}))
+		in, out := usage(&genai.GenerateContentResponse{})
+		assert.Zero(t, in)
+		assert.Zero(t, out)
+	})
+}
+
+func TestEnhanceAPIError(t *testing.T) {
+	originalErr := errors.New("API error")
+	enhanced := enhanceAPIError(originalErr)
+
+	assert.Error(t, enhanced)
+	assert.ErrorIs(t, enhanced, originalErr)
+}
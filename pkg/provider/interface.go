@@ -2,8 +2,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/tsanders/kantra-ai/pkg/prompt"
+	"github.com/tsanders/kantra-ai/pkg/ux"
+	"github.com/tsanders/kantra-ai/pkg/version"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -25,23 +31,50 @@ type Provider interface {
 	FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error)
 }
 
+// ResponseFormat controls how a provider returns single-incident fixes
+type ResponseFormat string
+
+const (
+	// ResponseFormatFull returns the entire fixed file in FixResponse.FixedContent (default)
+	ResponseFormatFull ResponseFormat = "full"
+	// ResponseFormatDiff returns a unified diff in FixResponse.Patch, which is much
+	// cheaper in output tokens for large files
+	ResponseFormatDiff ResponseFormat = "diff"
+)
+
+// ParseResponseFormat parses a response format string ("" defaults to full)
+func ParseResponseFormat(s string) (ResponseFormat, error) {
+	switch ResponseFormat(s) {
+	case "", ResponseFormatFull:
+		return ResponseFormatFull, nil
+	case ResponseFormatDiff:
+		return ResponseFormatDiff, nil
+	default:
+		return "", fmt.Errorf("invalid response format: %s (valid: full, diff)", s)
+	}
+}
+
 // FixRequest contains all the context needed to fix a violation
 type FixRequest struct {
-	Violation   violation.Violation
-	Incident    violation.Incident
-	FileContent string // Full file content
-	Language    string // Programming language (java, python, go, etc.)
+	Violation      violation.Violation
+	Incident       violation.Incident
+	FileContent    string         // Full file content
+	Language       string         // Programming language (java, python, go, etc.)
+	ResponseFormat ResponseFormat // Overrides the provider's configured format (empty = use provider default)
 }
 
 // FixResponse contains the AI's fix attempt
 type FixResponse struct {
 	Success      bool    // Whether the fix was successful
-	FixedContent string  // The fixed file content
+	FixedContent string  // The fixed file content (empty when Patch is set)
+	Patch        string  // Unified diff against the original file (diff response format only)
 	Explanation  string  // AI's explanation of what was changed
 	Confidence   float64 // Confidence score (0.0-1.0)
 	TokensUsed   int     // Number of tokens consumed
 	Cost         float64 // Cost in USD
 	Error        error   // Error if fix failed
+	Extra        map[string]interface{} // Configured extra response fields that were present (see Config.ExtraFields)
+	Provider     string  // Provider that produced the response, set by a FallbackProvider (empty = the configured provider)
 }
 
 // Config holds provider configuration
@@ -52,6 +85,37 @@ type Config struct {
 	Temperature float64           // Temperature (0.0-1.0)
 	BaseURL     string            // Custom base URL for OpenAI-compatible APIs
 	Templates   *prompt.Templates // Prompt templates (optional, uses defaults if nil)
+	ResponseFormat ResponseFormat // Single-fix response format: full (default) or diff
+	MaxRetries     int            // Retries after a rate-limited request (0 = default, negative = no retries)
+	RetryBaseDelay time.Duration  // Delay before the first retry, doubled each retry (0 = default)
+	MaxTokens      int               // Max output tokens for single fixes (0 = provider default)
+	Headers        map[string]string // Extra HTTP headers sent with every API request
+	ExtraFields    []string          // Additional JSON fields requested from the model and passed through in Extra
+	MaxPromptTokens int              // Prompt size limit for the pre-flight check (0 = model's context window minus output tokens)
+	UserAgent       string           // User-Agent sent with every API request (empty = kantra-ai/<version>)
+	Pricing         *Pricing         // Token prices for cost estimates and actual costs (nil = the provider's list prices)
+}
+
+// RequestHeaders returns the HTTP headers to send with every API request:
+// Headers plus a User-Agent. UserAgent takes precedence over a User-Agent in
+// Headers, which takes precedence over the default kantra-ai/<version>.
+func (c Config) RequestHeaders() map[string]string {
+	headers := make(map[string]string, len(c.Headers)+1)
+	userAgent := c.UserAgent
+	for key, value := range c.Headers {
+		if strings.EqualFold(key, "User-Agent") {
+			if userAgent == "" {
+				userAgent = value
+			}
+			continue
+		}
+		headers[key] = value
+	}
+	if userAgent == "" {
+		userAgent = version.UserAgent()
+	}
+	headers["User-Agent"] = userAgent
+	return headers
 }
 
 // PlanRequest contains the context needed to generate a migration plan
@@ -59,6 +123,9 @@ type PlanRequest struct {
 	Violations      []violation.Violation // All violations to plan for
 	MaxPhases       int                   // Maximum number of phases (0 = auto)
 	RiskTolerance   string                // conservative | balanced | aggressive
+	BatchSize       int                   // Violations per planning request for providers that batch them (0 = provider default)
+	BatchDelay      *time.Duration        // Delay between planning batches (nil = provider default, 0 = none)
+	Progress        ux.ProgressWriter     // Receives batch progress with the time remaining (nil = progress bar on stdout)
 }
 
 // PlanResponse contains the generated migration plan
@@ -108,6 +175,23 @@ type IncidentFix struct {
 	Explanation  string  // AI's explanation of the change
 	Confidence   float64 // Confidence score (0.0-1.0)
 	Error        error   // Error if this fix failed
+	Extra        map[string]interface{} // Configured extra response fields that were present
+	Provider     string  // Provider that produced the fix, set by a FallbackProvider (empty = the configured provider)
+}
+
+// BuiltinProviders lists the providers with their own API implementation
+var BuiltinProviders = []string{"claude", "openai", "gemini"}
+
+// Names returns every provider name accepted by --provider: the built-in
+// providers, the replay provider, then the presets in alphabetical order
+func Names() []string {
+	presets := make([]string, 0, len(ProviderPresets))
+	for name := range ProviderPresets {
+		presets = append(presets, name)
+	}
+	sort.Strings(presets)
+	names := append(append([]string(nil), BuiltinProviders...), ReplayProviderName)
+	return append(names, presets...)
 }
 
 // ProviderPresets maps provider names to their OpenAI-compatible base URLs
@@ -117,42 +201,55 @@ var ProviderPresets = map[string]ProviderPreset{
 		BaseURL:     "https://api.groq.com/openai/v1",
 		Description: "Groq - Fast inference with Llama, Mixtral, and Gemma models",
 		DefaultModel: "llama-3.1-70b-versatile",
+		Models:       []string{"llama-3.3-70b-versatile", "llama-3.1-8b-instant", "mixtral-8x7b-32768", "gemma2-9b-it"},
+		Pricing:      &Pricing{InputPerMillion: 0.59, OutputPerMillion: 0.79},
 	},
 	"together": {
 		BaseURL:     "https://api.together.xyz/v1",
 		Description: "Together AI - Open source models (Llama, Mixtral, Qwen, etc.)",
 		DefaultModel: "meta-llama/Meta-Llama-3.1-70B-Instruct-Turbo",
+		Models:       []string{"meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo", "meta-llama/Meta-Llama-3.1-405B-Instruct-Turbo", "mistralai/Mixtral-8x7B-Instruct-v0.1", "Qwen/Qwen2.5-72B-Instruct-Turbo"},
+		Pricing:      &Pricing{InputPerMillion: 0.88, OutputPerMillion: 0.88},
 	},
 	"anyscale": {
 		BaseURL:     "https://api.endpoints.anyscale.com/v1",
 		Description: "Anyscale - Llama, Mistral, and Mixtral models",
 		DefaultModel: "meta-llama/Meta-Llama-3.1-70B-Instruct",
+		Models:       []string{"meta-llama/Meta-Llama-3.1-8B-Instruct", "mistralai/Mixtral-8x7B-Instruct-v0.1", "mistralai/Mistral-7B-Instruct-v0.1"},
+		Pricing:      &Pricing{InputPerMillion: 1.00, OutputPerMillion: 1.00},
 	},
 	"perplexity": {
 		BaseURL:     "https://api.perplexity.ai",
 		Description: "Perplexity AI - Llama and Mistral models with online context",
 		DefaultModel: "llama-3.1-sonar-large-128k-online",
+		Models:       []string{"llama-3.1-sonar-small-128k-online", "llama-3.1-sonar-huge-128k-online"},
+		Pricing:      &Pricing{InputPerMillion: 1.00, OutputPerMillion: 1.00},
 	},
 	"ollama": {
 		BaseURL:     "http://localhost:11434/v1",
 		Description: "Ollama - Local models (requires Ollama running locally)",
 		DefaultModel: "codellama",
+		Pricing:      &Pricing{}, // Local models are free
 	},
 	"lmstudio": {
 		BaseURL:     "http://localhost:1234/v1",
 		Description: "LM Studio - Local models (requires LM Studio running locally)",
 		DefaultModel: "local-model",
+		Pricing:      &Pricing{}, // Local models are free
 	},
 	"openrouter": {
 		BaseURL:     "https://openrouter.ai/api/v1",
 		Description: "OpenRouter - Access to 100+ models through one API",
 		DefaultModel: "meta-llama/llama-3.1-70b-instruct",
+		Pricing:      &Pricing{InputPerMillion: 0.52, OutputPerMillion: 0.75},
 	},
 }
 
 // ProviderPreset contains configuration for a provider preset
 type ProviderPreset struct {
-	BaseURL      string // OpenAI-compatible base URL
-	Description  string // Human-readable description
-	DefaultModel string // Default model for this provider
+	BaseURL      string   // OpenAI-compatible base URL
+	Description  string   // Human-readable description
+	DefaultModel string   // Default model for this provider
+	Models       []string // Other models known to be served, checked by CheckModel (nil = any model, e.g. local or routed)
+	Pricing      *Pricing // List prices of the default model (nil = OpenAI's)
 }
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tsanders/kantra-ai/pkg/version"
+)
+
+func TestConfig_RequestHeaders(t *testing.T) {
+	t.Run("default User-Agent", func(t *testing.T) {
+		headers := Config{Headers: map[string]string{"X-Team": "platform"}}.RequestHeaders()
+		assert.Equal(t, map[string]string{"X-Team": "platform", "User-Agent": version.UserAgent()}, headers)
+	})
+
+	t.Run("User-Agent from Headers replaces the default", func(t *testing.T) {
+		headers := Config{Headers: map[string]string{"user-agent": "gateway/2"}}.RequestHeaders()
+		assert.Equal(t, map[string]string{"User-Agent": "gateway/2"}, headers)
+	})
+
+	t.Run("UserAgent takes precedence over Headers", func(t *testing.T) {
+		headers := Config{
+			UserAgent: "acme/1.0",
+			Headers:   map[string]string{"User-Agent": "gateway/2"},
+		}.RequestHeaders()
+		assert.Equal(t, map[string]string{"User-Agent": "acme/1.0"}, headers)
+	})
+
+	t.Run("Headers is not modified", func(t *testing.T) {
+		config := Config{Headers: map[string]string{"X-Team": "platform"}}
+		config.RequestHeaders()
+		assert.Len(t, config.Headers, 1)
+	})
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// builtinModels lists the known models of the built-in providers, default first
+var builtinModels = map[string][]string{
+	"claude": {
+		"claude-sonnet-4-20250514", "claude-sonnet-4-0", "claude-opus-4-1-20250805", "claude-opus-4-20250514", "claude-opus-4-0",
+		"claude-3-7-sonnet-20250219", "claude-3-7-sonnet-latest", "claude-3-5-sonnet-20241022", "claude-3-5-sonnet-latest",
+		"claude-3-5-haiku-20241022", "claude-3-5-haiku-latest", "claude-3-opus-20240229", "claude-3-haiku-20240307",
+	},
+	"openai": {
+		"gpt-4", "gpt-4.1", "gpt-4.1-mini", "gpt-4.1-nano", "gpt-4o", "gpt-4o-mini", "gpt-4-turbo", "gpt-3.5-turbo",
+		"o1", "o1-mini", "o3", "o3-mini", "o4-mini",
+	},
+	"gemini": {
+		"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.0-flash", "gemini-1.5-pro", "gemini-1.5-flash",
+	},
+}
+
+// modelFamilies maps the built-in providers to the name prefixes of their
+// models
+var modelFamilies = map[string][]string{
+	"claude": {"claude-"},
+	"openai": {"gpt-", "chatgpt-", "o1", "o3", "o4"},
+	"gemini": {"gemini-"},
+}
+
+// KnownModels returns the models known to be served by the named provider or
+// preset, default first, or nil if any model may be (e.g. local presets)
+func KnownModels(name string) []string {
+	if models, ok := builtinModels[name]; ok {
+		return models
+	}
+	if preset, ok := ProviderPresets[name]; ok && len(preset.Models) > 0 {
+		return append([]string{preset.DefaultModel}, preset.Models...)
+	}
+	return nil
+}
+
+// modelFamily returns the built-in provider whose models are named like
+// model, or "" if none is
+func modelFamily(model string) string {
+	model = strings.ToLower(model)
+	for name, prefixes := range modelFamilies {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(model, prefix) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// CheckModel checks config's model against the models the named provider is
+// known to serve, so a mismatch fails before any request instead of deep
+// inside an API call. A model of another provider (e.g. a Claude model with
+// openai), or a built-in provider's model outside its family, is an error;
+// an unknown but plausible model returns a warning. Default models, custom
+// base URLs, local presets and replay aren't checked.
+func CheckModel(name string, config Config) (warning string, err error) {
+	model := config.Model
+	known := KnownModels(name)
+	if model == "" || len(known) == 0 || containsString(known, model) {
+		return "", nil
+	}
+	if preset, ok := ProviderPresets[name]; config.BaseURL != "" && (!ok || config.BaseURL != preset.BaseURL) {
+		// Any model may be served there
+		return "", nil
+	}
+
+	family := modelFamily(model)
+	_, builtin := builtinModels[name]
+	switch {
+	case family != "" && family != name:
+		return "", fmt.Errorf("model %q is for --provider %s and can't be used with --provider %s\n\n"+
+			"Use --provider %s, or one of %s's models: %s", model, family, name, family, name, strings.Join(known, ", "))
+	case builtin && family != name:
+		return "", fmt.Errorf("model %q is not a %s model\n\nKnown %s models: %s", model, name, name, strings.Join(known, ", "))
+	}
+	return fmt.Sprintf("model %q is not a known %s model (known: %s); using it anyway", model, name, strings.Join(known, ", ")), nil
+}
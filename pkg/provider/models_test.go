@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnownModels(t *testing.T) {
+	assert.Equal(t, "claude-sonnet-4-20250514", KnownModels("claude")[0])
+	assert.Contains(t, KnownModels("openai"), "gpt-4o")
+
+	groq := KnownModels("groq")
+	assert.Equal(t, ProviderPresets["groq"].DefaultModel, groq[0])
+	assert.Contains(t, groq, "llama-3.1-8b-instant")
+
+	assert.Nil(t, KnownModels("ollama"), "local presets serve any model")
+	assert.Nil(t, KnownModels("openrouter"), "routed presets serve any model")
+	assert.Nil(t, KnownModels("unknown"))
+}
+
+func TestCheckModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		config      Config
+		wantWarning string
+		wantErr     string
+	}{
+		{name: "default model", provider: "openai"},
+		{name: "known model", provider: "openai", config: Config{Model: "gpt-4o-mini"}},
+		{name: "known preset model", provider: "groq", config: Config{Model: "mixtral-8x7b-32768"}},
+		{name: "local preset", provider: "ollama", config: Config{Model: "codellama"}},
+		{name: "custom base URL", provider: "openai", config: Config{Model: "claude-sonnet-4", BaseURL: "http://localhost:4000/v1"}},
+		{name: "replay", provider: ReplayProviderName, config: Config{Model: "gpt-4o"}},
+
+		{name: "unknown model of the family", provider: "openai", config: Config{Model: "gpt-5"},
+			wantWarning: `model "gpt-5" is not a known openai model`},
+		{name: "unknown preset model", provider: "groq", config: Config{Model: "llama-4-scout"},
+			wantWarning: `model "llama-4-scout" is not a known groq model`},
+		{name: "preset's own base URL is still checked", provider: "groq", config: Config{Model: "llama-4-scout", BaseURL: ProviderPresets["groq"].BaseURL},
+			wantWarning: `model "llama-4-scout" is not a known groq model`},
+
+		{name: "claude model with openai", provider: "openai", config: Config{Model: "claude-sonnet-4"},
+			wantErr: `model "claude-sonnet-4" is for --provider claude and can't be used with --provider openai`},
+		{name: "openai model with a preset", provider: "groq", config: Config{Model: "gpt-4o"},
+			wantErr: `model "gpt-4o" is for --provider openai and can't be used with --provider groq`},
+		{name: "other model with claude", provider: "claude", config: Config{Model: "llama3"},
+			wantErr: `model "llama3" is not a claude model`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := CheckModel(tt.provider, tt.config)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.ErrorContains(t, err, KnownModels(tt.provider)[0], "lists the valid models")
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantWarning == "" {
+				assert.Empty(t, warning)
+			} else {
+				assert.Contains(t, warning, tt.wantWarning)
+			}
+		})
+	}
+}
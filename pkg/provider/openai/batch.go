@@ -9,9 +9,16 @@ import (
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
+var (
+	// Compiled regexes for batch JSON extraction (compiled once at package init time)
+	batchJSONCodeBlockRegex = regexp.MustCompile("```(?:json)?\\s*\\n([\\s\\S]*?)\\n```")
+	batchJSONArrayRegex     = regexp.MustCompile(`(?s)(\[.*\])`)
+)
+
 // FixBatch processes multiple incidents of the same violation in one API call.
 // This reduces costs and execution time by batching similar fixes together.
 func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*provider.BatchResponse, error) {
@@ -27,26 +34,48 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 	if err != nil {
 		return nil, fmt.Errorf("failed to render batch prompt template: %w", err)
 	}
+	promptText = provider.ApplyExtraFields(promptText, p.extraFields)
+
+	// Fail fast with a clear error instead of a cryptic API error
+	if err := provider.CheckPromptSize(promptText, provider.PromptTokenLimit(p.model, p.maxPromptTokens, PlanningMaxTokens)); err != nil {
+		return nil, err
+	}
 
 	// Call OpenAI API
-	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       p.model,
-		Temperature: p.temperature,
-		MaxTokens:   8192,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: promptText,
-			},
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: promptText,
 		},
-	})
-
+	}
+	resp, err := p.sendBatchMessages(ctx, messages)
 	if err != nil {
-		return nil, enhanceAPIError(fmt.Errorf("OpenAI API error: %w", err))
+		return nil, err
 	}
 
 	// Extract response text
 	responseText := resp.Choices[0].Message.Content
+	usage := resp.Usage
+
+	// Large batches can hit the output token limit; continue the response
+	// instead of losing the whole batch to invalid JSON
+	responseText, err = provider.CompleteTruncated(responseText, resp.Choices[0].FinishReason == openai.FinishReasonLength,
+		func(partial string) (string, bool, error) {
+			continued, err := p.sendBatchMessages(ctx, append(messages,
+				openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: partial},
+				openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: provider.ContinuationPrompt},
+			))
+			if err != nil {
+				return "", false, err
+			}
+			usage.PromptTokens += continued.Usage.PromptTokens
+			usage.CompletionTokens += continued.Usage.CompletionTokens
+			usage.TotalTokens += continued.Usage.TotalTokens
+			return continued.Choices[0].Message.Content, continued.Choices[0].FinishReason == openai.FinishReasonLength, nil
+		})
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse the batch response
 	fixes, err := p.parseBatchResponse(responseText, req.Incidents)
@@ -54,15 +83,8 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 		return nil, fmt.Errorf("failed to parse batch response: %w", err)
 	}
 
-	// Calculate costs
-	// Note: For custom providers (Groq, Together, etc.), pricing may vary
-	// Currently using GPT-4 pricing as baseline: $30/1M input, $60/1M output
-	// Future enhancement: Make pricing configurable per provider preset
-	inputTokens := resp.Usage.PromptTokens
-	outputTokens := resp.Usage.CompletionTokens
-	inputCost := float64(inputTokens) * 30.0 / 1000000.0
-	outputCost := float64(outputTokens) * 60.0 / 1000000.0
-	cost := inputCost + outputCost
+	// Calculate costs with the preset's or configured pricing (GPT-4's by default)
+	cost := p.pricing.Cost(usage.PromptTokens, usage.CompletionTokens)
 
 	// Check if all fixes succeeded
 	allSuccess := true
@@ -76,12 +98,38 @@ func (p *Provider) FixBatch(ctx context.Context, req provider.BatchRequest) (*pr
 	return &provider.BatchResponse{
 		Fixes:      fixes,
 		Success:    allSuccess,
-		TokensUsed: resp.Usage.TotalTokens,
+		TokensUsed: usage.TotalTokens,
 		Cost:       cost,
 	}, nil
 }
 
-// parseBatchResponse parses the JSON response into IncidentFix structs
+// sendBatchMessages sends a batch conversation with retries. The response has
+// at least one choice.
+func (p *Provider) sendBatchMessages(ctx context.Context, messages []openai.ChatCompletionMessage) (openai.ChatCompletionResponse, error) {
+	var resp openai.ChatCompletionResponse
+	err := common.RetryWithBackoff(ctx, p.retry, func() error {
+		var apiErr error
+		resp, apiErr = p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model:       p.model,
+			Temperature: p.temperature,
+			MaxTokens:   PlanningMaxTokens,
+			Messages:    messages,
+		})
+		return apiErr
+	})
+	if err != nil {
+		return resp, enhanceAPIError(fmt.Errorf("OpenAI API error: %w", err))
+	}
+	if len(resp.Choices) == 0 {
+		return resp, fmt.Errorf("OpenAI API returned no choices")
+	}
+	return resp, nil
+}
+
+// parseBatchResponse parses the JSON response into one IncidentFix per requested incident.
+// Fixes are matched to incidents by incident_uri (with or without a ":line" suffix)
+// rather than by position, and are returned in the order of the requested incidents.
+// Incidents the model did not return a fix for are marked as failed.
 func (p *Provider) parseBatchResponse(responseText string, incidents []violation.Incident) ([]provider.IncidentFix, error) {
 	// Extract JSON from markdown code blocks
 	jsonData := extractJSONFromMarkdown(responseText)
@@ -98,22 +146,52 @@ func (p *Provider) parseBatchResponse(responseText string, incidents []violation
 	if err := json.Unmarshal(jsonData, &rawFixes); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	extras := provider.ExtractBatchExtraFields(jsonData, p.extraFields)
 
-	// Verify we got a fix for each incident
-	if len(rawFixes) != len(incidents) {
-		return nil, fmt.Errorf("expected %d fixes but got %d", len(incidents), len(rawFixes))
+	// Index returned fixes by URI; several incidents may share a file URI
+	byURI := make(map[string][]int)
+	for i, raw := range rawFixes {
+		byURI[raw.IncidentURI] = append(byURI[raw.IncidentURI], i)
+	}
+	used := make([]bool, len(rawFixes))
+
+	// takeFix returns the first unused fix returned under the given URI
+	takeFix := func(uri string) (int, bool) {
+		for _, idx := range byURI[uri] {
+			if !used[idx] {
+				used[idx] = true
+				return idx, true
+			}
+		}
+		return 0, false
 	}
 
-	// Convert to IncidentFix structs
-	fixes := make([]provider.IncidentFix, len(rawFixes))
-	for i, raw := range rawFixes {
+	fixes := make([]provider.IncidentFix, len(incidents))
+	for i, incident := range incidents {
+		idx, ok := takeFix(fmt.Sprintf("%s:%d", incident.URI, incident.LineNumber))
+		if !ok {
+			idx, ok = takeFix(incident.URI)
+		}
+		if !ok {
+			fixes[i] = provider.IncidentFix{
+				IncidentURI: incident.URI,
+				Success:     false,
+				Error:       fmt.Errorf("no fix returned for incident %s:%d", incident.URI, incident.LineNumber),
+			}
+			continue
+		}
+
+		raw := rawFixes[idx]
 		fixes[i] = provider.IncidentFix{
-			IncidentURI:  raw.IncidentURI,
+			IncidentURI:  incident.URI,
 			Success:      raw.Success,
 			FixedContent: raw.FixedContent,
 			Explanation:  raw.Explanation,
 			Confidence:   raw.Confidence,
 		}
+		if extras != nil {
+			fixes[i].Extra = extras[idx]
+		}
 
 		if !raw.Success {
 			fixes[i].Error = fmt.Errorf("%s", raw.Explanation)
@@ -125,16 +203,14 @@ func (p *Provider) parseBatchResponse(responseText string, incidents []violation
 
 // extractJSONFromMarkdown extracts JSON content from markdown code blocks
 func extractJSONFromMarkdown(text string) []byte {
-	// Try to find JSON in code blocks first
-	re := regexp.MustCompile("```(?:json)?\\s*\\n([\\s\\S]*?)\\n```")
-	matches := re.FindStringSubmatch(text)
+	// Try to find JSON in code blocks first using pre-compiled regex
+	matches := batchJSONCodeBlockRegex.FindStringSubmatch(text)
 	if len(matches) > 1 {
 		return []byte(strings.TrimSpace(matches[1]))
 	}
 
-	// Try to find raw JSON array
-	re = regexp.MustCompile(`(?s)(\[.*\])`)
-	matches = re.FindStringSubmatch(text)
+	// Try to find raw JSON array using pre-compiled regex
+	matches = batchJSONArrayRegex.FindStringSubmatch(text)
 	if len(matches) > 1 {
 		return []byte(strings.TrimSpace(matches[1]))
 	}
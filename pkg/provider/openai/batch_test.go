@@ -1,10 +1,16 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -43,14 +49,14 @@ func TestParseBatchResponse(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, fixes, 2)
 
-		assert.Equal(t, "file:///test1.java:10", fixes[0].IncidentURI)
+		assert.Equal(t, "file:///test1.java", fixes[0].IncidentURI)
 		assert.True(t, fixes[0].Success)
 		assert.Equal(t, "fixed content 1", fixes[0].FixedContent)
 		assert.Equal(t, "Fixed issue 1", fixes[0].Explanation)
 		assert.Equal(t, 0.95, fixes[0].Confidence)
 		assert.Nil(t, fixes[0].Error)
 
-		assert.Equal(t, "file:///test2.java:20", fixes[1].IncidentURI)
+		assert.Equal(t, "file:///test2.java", fixes[1].IncidentURI)
 		assert.True(t, fixes[1].Success)
 	})
 
@@ -109,22 +115,74 @@ func TestParseBatchResponse(t *testing.T) {
 		assert.Contains(t, fixes[1].Error.Error(), "Could not parse code")
 	})
 
-	t.Run("wrong number of fixes", func(t *testing.T) {
+	t.Run("missing fixes are marked failed", func(t *testing.T) {
 		responseText := "```json\n" +
 			"[\n" +
 			"  {\n" +
-			"    \"incident_uri\": \"file:///test1.java:10\",\n" +
+			"    \"incident_uri\": \"file:///test2.java\",\n" +
 			"    \"success\": true,\n" +
-			"    \"fixed_content\": \"fixed content 1\",\n" +
-			"    \"explanation\": \"Fixed issue 1\",\n" +
-			"    \"confidence\": 0.95\n" +
+			"    \"fixed_content\": \"fixed content 2\",\n" +
+			"    \"explanation\": \"Fixed issue 2\",\n" +
+			"    \"confidence\": 0.9\n" +
 			"  }\n" +
 			"]\n" +
 			"```\n"
-		_, err := p.parseBatchResponse(responseText, incidents)
+		fixes, err := p.parseBatchResponse(responseText, incidents)
 
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "expected 2 fixes but got 1")
+		require.NoError(t, err)
+		require.Len(t, fixes, 2, "one fix per requested incident")
+
+		assert.Equal(t, "file:///test1.java", fixes[0].IncidentURI)
+		assert.False(t, fixes[0].Success)
+		require.Error(t, fixes[0].Error)
+		assert.Contains(t, fixes[0].Error.Error(), "no fix returned")
+
+		assert.Equal(t, "file:///test2.java", fixes[1].IncidentURI)
+		assert.True(t, fixes[1].Success)
+		assert.Equal(t, "fixed content 2", fixes[1].FixedContent)
+	})
+
+	t.Run("fixes are matched by URI not position", func(t *testing.T) {
+		sameFile := []violation.Incident{
+			{URI: "file:///Same.java", LineNumber: 5},
+			{URI: "file:///Same.java", LineNumber: 9},
+			{URI: "file:///Other.java", LineNumber: 1},
+		}
+		responseText := `[
+  {"incident_uri": "file:///Other.java", "success": true, "fixed_content": "other", "confidence": 0.9},
+  {"incident_uri": "file:///Same.java:9", "success": true, "fixed_content": "same-9", "confidence": 0.9},
+  {"incident_uri": "file:///Same.java", "success": true, "fixed_content": "same-5", "confidence": 0.9},
+  {"incident_uri": "file:///Unrequested.java", "success": true, "fixed_content": "ignored", "confidence": 0.9}
+]`
+		fixes, err := p.parseBatchResponse(responseText, sameFile)
+
+		require.NoError(t, err)
+		require.Len(t, fixes, 3)
+		assert.Equal(t, "same-5", fixes[0].FixedContent)
+		assert.Equal(t, "same-9", fixes[1].FixedContent)
+		assert.Equal(t, "other", fixes[2].FixedContent)
+		assert.Equal(t, "file:///Other.java", fixes[2].IncidentURI)
+	})
+
+	t.Run("extra fields follow the matched fix", func(t *testing.T) {
+		withExtras := &Provider{extraFields: []string{"risk_notes"}}
+		// Returned out of order; only the second incident has risk notes
+		responseText := `[
+			{"incident_uri": "file:///test2.java:20", "success": true, "fixed_content": "two", "confidence": 0.9, "risk_notes": "changes public API"},
+			{"incident_uri": "file:///test1.java:10", "success": true, "fixed_content": "one", "confidence": 0.9}
+		]`
+
+		fixes, err := withExtras.parseBatchResponse(responseText, incidents)
+
+		require.NoError(t, err)
+		require.Len(t, fixes, 2)
+		assert.Nil(t, fixes[0].Extra)
+		assert.Equal(t, map[string]interface{}{"risk_notes": "changes public API"}, fixes[1].Extra)
+
+		// Without configured extra fields nothing is captured
+		fixes, err = p.parseBatchResponse(responseText, incidents)
+		require.NoError(t, err)
+		assert.Nil(t, fixes[1].Extra)
 	})
 
 	t.Run("invalid JSON", func(t *testing.T) {
@@ -172,3 +230,65 @@ func TestExtractJSONFromMarkdown(t *testing.T) {
 		})
 	}
 }
+
+func TestFixBatch_ContinuesTruncatedResponse(t *testing.T) {
+	// The first response stops at the output token limit mid-JSON
+	responses := []struct {
+		content      string
+		finishReason openai.FinishReason
+	}{
+		{
+			content:      "```json\n[\n  {\"incident_uri\": \"file:///A.java:10\", \"success\": true, \"fixed_content\": \"class A {}\", \"confidence\": 0.9},\n  {\"incident_uri\": \"file:///B.java:20\", \"success\": true, \"fixed_",
+			finishReason: openai.FinishReasonLength,
+		},
+		{
+			content:      "```json\ncontent\": \"class B {}\", \"confidence\": 0.8}\n]\n```",
+			finishReason: openai.FinishReasonStop,
+		},
+	}
+
+	var requests []openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		resp := responses[len(requests)-1]
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: resp.content},
+				FinishReason: resp.finishReason,
+			}},
+			Usage: openai.Usage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150},
+		})
+	}))
+	defer server.Close()
+
+	p, err := New(provider.Config{APIKey: "test-key", BaseURL: server.URL, MaxRetries: -1})
+	require.NoError(t, err)
+
+	resp, err := p.FixBatch(context.Background(), provider.BatchRequest{
+		Violation: violation.Violation{ID: "v1", Description: "Replace javax"},
+		Incidents: []violation.Incident{
+			{URI: "file:///A.java", LineNumber: 10},
+			{URI: "file:///B.java", LineNumber: 20},
+		},
+		FileContents: map[string]string{"A.java": "class A {}", "B.java": "class B {}"},
+		Language:     "java",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	continuation := requests[1].Messages
+	require.Len(t, continuation, 3, "prompt, truncated response, continuation request")
+	assert.Equal(t, openai.ChatMessageRoleAssistant, continuation[1].Role)
+	assert.Equal(t, responses[0].content, continuation[1].Content)
+	assert.Equal(t, provider.ContinuationPrompt, continuation[2].Content)
+
+	require.Len(t, resp.Fixes, 2)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "class B {}", resp.Fixes[1].FixedContent)
+	assert.Equal(t, 0.8, resp.Fixes[1].Confidence)
+	assert.Equal(t, 300, resp.TokensUsed, "tokens of both calls count")
+}
@@ -19,12 +19,22 @@ const (
 	PlanningMaxTokens = 8192
 )
 
+// DefaultPricing is GPT-4's list price, used unless the configuration or a
+// preset sets the endpoint's pricing
+var DefaultPricing = provider.Pricing{InputPerMillion: 30.0, OutputPerMillion: 60.0}
+
 // Provider implements the OpenAI provider
 type Provider struct {
 	client      *openai.Client
 	model       string
 	temperature float32
 	templates   *prompt.Templates
+	retry       common.RetryConfig
+	maxTokens   int
+	extraFields []string
+	maxPromptTokens int
+	pricing     provider.Pricing
+	responseFormat provider.ResponseFormat
 }
 
 // New creates a new OpenAI provider
@@ -62,8 +72,16 @@ func New(config provider.Config) (*Provider, error) {
 		clientConfig.BaseURL = config.BaseURL
 	}
 
+	// Send the User-Agent and extra headers (e.g. for API gateways and proxies)
+	clientConfig.HTTPClient = common.NewHeaderClient(config.RequestHeaders())
+
 	client := openai.NewClientWithConfig(clientConfig)
 
+	maxTokens := config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
 	// Load templates (use defaults if not provided)
 	templates := config.Templates
 	if templates == nil {
@@ -81,6 +99,15 @@ func New(config provider.Config) (*Provider, error) {
 		model:       model,
 		temperature: temperature,
 		templates:   templates,
+		maxTokens:   maxTokens,
+		extraFields: config.ExtraFields,
+		maxPromptTokens: config.MaxPromptTokens,
+		pricing:         config.PricingOr(DefaultPricing),
+		responseFormat:  config.ResponseFormat,
+		retry: common.RetryConfig{
+			MaxRetries: config.MaxRetries,
+			BaseDelay:  config.RetryBaseDelay,
+		},
 	}, nil
 }
 
@@ -91,28 +118,38 @@ func (p *Provider) Name() string {
 
 // FixViolation sends the violation to OpenAI and gets a fix
 func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*provider.FixResponse, error) {
-	// Build prompt from template
-	data := provider.BuildSingleFixData(req)
-	// Select language-specific template or fall back to base template
-	tmpl := p.templates.GetSingleFixTemplate(data.Language)
-	promptText, err := tmpl.RenderSingleFix(data)
+	format := p.responseFormatFor(req)
+	promptText, err := p.fixPrompt(req)
 	if err != nil {
 		return &provider.FixResponse{
 			Success: false,
-			Error:   fmt.Errorf("failed to render prompt template: %w", err),
+			Error:   err,
 		}, nil
 	}
 
-	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       p.model,
-		Temperature: p.temperature,
-		MaxTokens:   DefaultMaxTokens,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: promptText,
+	// Fail fast with a clear error instead of a cryptic API error
+	if err := provider.CheckPromptSize(promptText, provider.PromptTokenLimit(p.model, p.maxPromptTokens, p.maxTokens)); err != nil {
+		return &provider.FixResponse{
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	var resp openai.ChatCompletionResponse
+	err = common.RetryWithBackoff(ctx, p.retry, func() error {
+		var apiErr error
+		resp, apiErr = p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model:       p.model,
+			Temperature: p.temperature,
+			MaxTokens:   p.maxTokens,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: promptText,
+				},
 			},
-		},
+		})
+		return apiErr
 	})
 
 	if err != nil {
@@ -127,6 +164,7 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 	// Parse JSON response
 	type Response struct {
 		FixedContent string  `json:"fixed_content"`
+		Patch        string  `json:"patch"`
 		Confidence   float64 `json:"confidence"`
 		Explanation  string  `json:"explanation"`
 	}
@@ -137,16 +175,17 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 	var parsedResp Response
 	if err := json.Unmarshal(jsonData, &parsedResp); err != nil {
 		// If JSON parsing fails, fall back to treating entire response as code with default confidence
-		inputCost := float64(resp.Usage.PromptTokens) * 30.0 / 1000000.0
-		outputCost := float64(resp.Usage.CompletionTokens) * 60.0 / 1000000.0
-		return &provider.FixResponse{
+		fallback := &provider.FixResponse{
 			Success:      true,
 			FixedContent: responseText,
 			Explanation:  "Fixed by GPT-4 (JSON parse failed, using raw response)",
 			Confidence:   0.85, // Default when JSON parsing fails
 			TokensUsed:   resp.Usage.TotalTokens,
-			Cost:         inputCost + outputCost,
-		}, nil
+			Cost:         p.pricing.Cost(resp.Usage.PromptTokens, resp.Usage.CompletionTokens),
+		}
+		// In diff mode a raw response is most likely a bare patch
+		provider.PreferPatch(fallback, format, responseText, true)
+		return fallback, nil
 	}
 
 	// Validate confidence range
@@ -154,32 +193,54 @@ func (p *Provider) FixViolation(ctx context.Context, req provider.FixRequest) (*
 		parsedResp.Confidence = 0.85 // Clamp to reasonable default
 	}
 
-	// Calculate cost (GPT-4 pricing: $30/$60 per 1M tokens)
-	inputCost := float64(resp.Usage.PromptTokens) * 30.0 / 1000000.0
-	outputCost := float64(resp.Usage.CompletionTokens) * 60.0 / 1000000.0
-	totalCost := inputCost + outputCost
+	// Calculate cost (GPT-4 pricing unless configured)
+	totalCost := p.pricing.Cost(resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 
-	return &provider.FixResponse{
+	fixResp := &provider.FixResponse{
 		Success:      true,
 		FixedContent: parsedResp.FixedContent,
 		Explanation:  parsedResp.Explanation,
 		Confidence:   parsedResp.Confidence,
 		TokensUsed:   resp.Usage.TotalTokens,
 		Cost:         totalCost,
-	}, nil
+		Extra:        provider.ExtractExtraFields(jsonData, p.extraFields),
+	}
+	// Prefer the patch in diff mode
+	provider.PreferPatch(fixResp, format, parsedResp.Patch, false)
+	return fixResp, nil
 }
 
 // EstimateCost estimates the cost for fixing a violation
 func (p *Provider) EstimateCost(req provider.FixRequest) (float64, error) {
-	// Rough estimate: ~2000 tokens input + ~1000 tokens output
-	// Using GPT-4 pricing: $30/$60 per 1M tokens
-	estimatedInputTokens := 2000.0
-	estimatedOutputTokens := 1000.0
+	promptText, err := p.fixPrompt(req)
+	if err != nil {
+		return 0, err
+	}
+	return p.pricing.Cost(provider.EstimateFixTokens(p.model, promptText)), nil
+}
+
+// fixPrompt renders the prompt for a single fix request
+func (p *Provider) fixPrompt(req provider.FixRequest) (string, error) {
+	data := provider.BuildSingleFixData(req)
+	// Select language-specific template or fall back to base template
+	tmpl := p.templates.GetSingleFixTemplate(data.Language)
+	promptText, err := tmpl.RenderSingleFix(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
 
-	inputCost := estimatedInputTokens * 30.0 / 1000000.0
-	outputCost := estimatedOutputTokens * 60.0 / 1000000.0
+	// Request a unified diff instead of the whole file if configured
+	promptText = provider.ApplyResponseFormat(promptText, p.responseFormatFor(req))
+	return provider.ApplyExtraFields(promptText, p.extraFields), nil
+}
 
-	return inputCost + outputCost, nil
+// responseFormatFor returns the response format of a fix request: its own,
+// or the provider's
+func (p *Provider) responseFormatFor(req provider.FixRequest) provider.ResponseFormat {
+	if req.ResponseFormat != "" {
+		return req.ResponseFormat
+	}
+	return p.responseFormat
 }
 
 // enhanceAPIError adds helpful context to OpenAI API errors using the common error handler.
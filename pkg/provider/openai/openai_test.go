@@ -2,13 +2,18 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/version"
 	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
@@ -83,10 +88,17 @@ func TestProvider_EstimateCost(t *testing.T) {
 	cost, err := p.EstimateCost(req)
 	require.NoError(t, err)
 
-	// Estimate: 2000 input tokens * $30/1M + 1000 output tokens * $60/1M
-	expectedCost := (2000.0 * 30.0 / 1000000.0) + (1000.0 * 60.0 / 1000000.0)
-	assert.InDelta(t, expectedCost, cost, 0.0001) // Use InDelta for float comparison
 	assert.Greater(t, cost, 0.0)
+
+	// The estimate scales with the file in the prompt: its tokens, plus half
+	// as many output tokens, at $30.0/1M input and $60.0/1M output
+	req.FileContent = strings.Repeat("import javax.servlet.http.HttpServlet;\n", 2000)
+	large, err := p.EstimateCost(req)
+	require.NoError(t, err)
+	fileTokens := float64(provider.CountTokens(p.model, req.FileContent))
+	minimum := fileTokens*30.0/1000000.0 + fileTokens*provider.FixOutputRatio*60.0/1000000.0
+	assert.GreaterOrEqual(t, large, minimum)
+	assert.InEpsilon(t, minimum, large, 0.1, "the template adds little to a large file")
 }
 
 // NOTE: buildPrompt tests removed - prompts now generated via configurable templates
@@ -132,3 +144,149 @@ func TestGeneratePlan(t *testing.T) {
 		assert.Contains(t, resp.Error.Error(), "use --provider=claude")
 	})
 }
+
+func TestFixViolation_PromptTooLarge(t *testing.T) {
+	// gpt-4 has an 8192 token window; 4096 tokens are reserved for the response
+	p, err := New(provider.Config{APIKey: "test-key", Model: "gpt-4", BaseURL: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+
+	resp, err := p.FixViolation(context.Background(), provider.FixRequest{
+		Violation:   violation.Violation{ID: "v1", Description: "Replace javax"},
+		Incident:    violation.Incident{URI: "file:///src/A.java", LineNumber: 1},
+		FileContent: strings.Repeat("import javax.ejb.Stateless;\n", 1000),
+		Language:    "java",
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	require.Error(t, resp.Error)
+	assert.True(t, errors.Is(resp.Error, provider.ErrPromptTooLarge))
+	assert.Contains(t, resp.Error.Error(), "> limit 4096)")
+}
+
+func TestFixBatch_PromptTooLarge(t *testing.T) {
+	p, err := New(provider.Config{APIKey: "test-key", BaseURL: "http://127.0.0.1:1", MaxPromptTokens: 100})
+	require.NoError(t, err)
+
+	_, err = p.FixBatch(context.Background(), provider.BatchRequest{
+		Violation: violation.Violation{ID: "v1", Description: "Replace javax"},
+		Incidents: []violation.Incident{{URI: "file:///src/A.java", LineNumber: 1}},
+		FileContents: map[string]string{
+			"/src/A.java": strings.Repeat("import javax.ejb.Stateless;\n", 100),
+		},
+		Language: "java",
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, provider.ErrPromptTooLarge))
+}
+
+func TestProvider_Pricing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"role": "assistant", "content": "{\"fixed_content\": \"class A {}\", \"confidence\": 0.9}"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 2000, "completion_tokens": 1000, "total_tokens": 3000}
+		}`))
+	}))
+	defer server.Close()
+
+	request := provider.FixRequest{
+		Violation: violation.Violation{ID: "v1"},
+		Incident:  violation.Incident{URI: "file:///src/A.java", LineNumber: 1},
+		Language:  "java",
+	}
+
+	t.Run("local model is free", func(t *testing.T) {
+		p, err := New(provider.Config{APIKey: "test-key", BaseURL: server.URL, MaxRetries: -1, Pricing: &provider.Pricing{}})
+		require.NoError(t, err)
+
+		estimate, err := p.EstimateCost(request)
+		require.NoError(t, err)
+		assert.Zero(t, estimate)
+
+		resp, err := p.FixViolation(context.Background(), request)
+		require.NoError(t, err)
+		require.True(t, resp.Success)
+		assert.Zero(t, resp.Cost)
+		assert.Equal(t, 3000, resp.TokensUsed)
+	})
+
+	t.Run("custom pricing", func(t *testing.T) {
+		p, err := New(provider.Config{
+			APIKey: "test-key", BaseURL: server.URL, MaxRetries: -1,
+			Pricing: &provider.Pricing{InputPerMillion: 0.5, OutputPerMillion: 1.5},
+		})
+		require.NoError(t, err)
+
+		resp, err := p.FixViolation(context.Background(), request)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0025, resp.Cost, 1e-9)
+	})
+}
+
+func TestUserAgent(t *testing.T) {
+	var userAgent, gateway string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		gateway = r.Header.Get("X-Gateway-Team")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "bad request"}}`))
+	}))
+	defer server.Close()
+
+	request := provider.FixRequest{
+		Violation: violation.Violation{ID: "v1", Description: "Replace javax"},
+		Incident:  violation.Incident{URI: "file:///src/A.java", LineNumber: 1},
+		Language:  "java",
+	}
+
+	t.Run("defaults to kantra-ai/<version>", func(t *testing.T) {
+		p, err := New(provider.Config{APIKey: "test-key", BaseURL: server.URL, MaxRetries: -1})
+		require.NoError(t, err)
+		_, _ = p.FixViolation(context.Background(), request)
+		assert.Equal(t, version.UserAgent(), userAgent)
+	})
+
+	t.Run("custom User-Agent is sent alongside extra headers", func(t *testing.T) {
+		p, err := New(provider.Config{
+			APIKey:     "test-key",
+			BaseURL:    server.URL,
+			MaxRetries: -1,
+			UserAgent:  "acme-migrations/1.0",
+			Headers:    map[string]string{"X-Gateway-Team": "platform"},
+		})
+		require.NoError(t, err)
+		_, _ = p.FixViolation(context.Background(), request)
+		assert.Equal(t, "acme-migrations/1.0", userAgent)
+		assert.Equal(t, "platform", gateway)
+	})
+}
+
+func TestFixViolation_DiffFormat(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		prompt = body.Messages[0].Content
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"role": "assistant", "content": "{\"patch\": \"@@ -1 +1 @@\\n-import javax.a;\\n+import jakarta.a;\\n\", \"confidence\": 0.9}"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 20, "completion_tokens": 10, "total_tokens": 30}
+		}`))
+	}))
+	defer server.Close()
+
+	p, err := New(provider.Config{APIKey: "test-key", BaseURL: server.URL, MaxRetries: -1, ResponseFormat: provider.ResponseFormatDiff})
+	require.NoError(t, err)
+
+	resp, err := p.FixViolation(context.Background(), provider.FixRequest{
+		Violation: violation.Violation{ID: "v1"},
+		Incident:  violation.Incident{URI: "file:///src/A.java", LineNumber: 1},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Contains(t, prompt, "unified diff", "the prompt asks for a diff")
+	assert.Equal(t, "@@ -1 +1 @@\n-import javax.a;\n+import jakarta.a;\n", resp.Patch)
+	assert.Empty(t, resp.FixedContent)
+}
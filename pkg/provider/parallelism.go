@@ -0,0 +1,43 @@
+package provider
+
+// DefaultMaxParallelism caps concurrent requests to providers without a
+// limit of their own
+const DefaultMaxParallelism = 8
+
+// maxParallelism is the most concurrent requests each provider handles
+// without running into rate limits on typical accounts: hosted APIs with low
+// tokens-per-minute tiers get fewer, fast inference APIs more, and local
+// servers, which process one request at a time, the fewest.
+var maxParallelism = map[string]int{
+	"claude":     4,
+	"openai":     6,
+	"gemini":     4,
+	"groq":       8,
+	"together":   8,
+	"anyscale":   6,
+	"perplexity": 4,
+	"openrouter": 6,
+	"ollama":     2,
+	"lmstudio":   1,
+
+	ReplayProviderName: DefaultMaxParallelism,
+}
+
+// MaxParallelism returns the most concurrent requests to send to the named
+// provider
+func MaxParallelism(name string) int {
+	if limit, ok := maxParallelism[name]; ok {
+		return limit
+	}
+	return DefaultMaxParallelism
+}
+
+// ClampParallelism returns requested capped at the provider's
+// MaxParallelism. 0 (or less) means auto: the provider's MaxParallelism.
+func ClampParallelism(name string, requested int) int {
+	limit := MaxParallelism(name)
+	if requested <= 0 || requested > limit {
+		return limit
+	}
+	return requested
+}
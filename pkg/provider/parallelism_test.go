@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampParallelism(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		requested int
+		want      int
+	}{
+		{"auto uses the provider's limit", "claude", 0, 4},
+		{"requests within the limit are kept", "openai", 3, 3},
+		{"requests above the limit are capped", "claude", 16, 4},
+		{"local servers get the fewest", "lmstudio", 4, 1},
+		{"unknown providers use the default", "custom", 0, DefaultMaxParallelism},
+		{"unknown providers are capped at the default", "custom", 32, DefaultMaxParallelism},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClampParallelism(tt.provider, tt.requested))
+		})
+	}
+}
+
+func TestMaxParallelism_CoversPresets(t *testing.T) {
+	for name := range ProviderPresets {
+		_, ok := maxParallelism[name]
+		assert.True(t, ok, "preset %s has no parallelism limit", name)
+	}
+}
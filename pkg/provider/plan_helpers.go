@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+var (
+	// Compiled regexes for JSON extraction (compiled once at package init time)
+	jsonCodeBlockRegex = regexp.MustCompile(`(?s)` + "```" + `(?:json)?\s*([\[{].*?[\]}])\s*` + "```")
+	jsonArrayRegex     = regexp.MustCompile(`(?s)(\[.*\])`)
+)
+
+// BuildPlanPrompt constructs the prompt for plan generation.
+// It is shared by all providers that support planning.
+func BuildPlanPrompt(req PlanRequest) string {
+	// Create a lightweight version of violations (without full incident details)
+	// to avoid exceeding token limits
+	type lightweightViolation struct {
+		ID                  string `json:"id"`
+		Description         string `json:"description"`
+		Category            string `json:"category"`
+		Effort              int    `json:"effort"`
+		IncidentCount       int    `json:"incident_count"`
+		MigrationComplexity string `json:"migration_complexity,omitempty"`
+	}
+
+	lightViolations := make([]lightweightViolation, len(req.Violations))
+	for i, v := range req.Violations {
+		lightViolations[i] = lightweightViolation{
+			ID:                  v.ID,
+			Description:         v.Description,
+			Category:            v.Category,
+			Effort:              v.Effort,
+			IncidentCount:       len(v.Incidents),
+			MigrationComplexity: v.MigrationComplexity,
+		}
+	}
+
+	violationsJSON, _ := json.MarshalIndent(lightViolations, "", "  ")
+
+	maxPhases := req.MaxPhases
+	if maxPhases == 0 {
+		maxPhases = 5 // Default to 5 phases
+	}
+
+	return fmt.Sprintf(`You are a migration planning expert helping create a phased migration plan for code violations found by Konveyor static analysis.
+
+VIOLATIONS TO ANALYZE:
+%s
+
+REQUIREMENTS:
+1. Group violations into %d logical phases (or fewer if appropriate)
+2. Prioritize phases by: category (mandatory > optional > potential) > effort level
+3. For each phase provide:
+   - A clear, descriptive name
+   - Risk level assessment (low/medium/high)
+   - Explanation of WHY these violations are grouped together
+   - Recommended execution order
+   - Violation IDs to include in this phase
+   - Estimated cost per phase ($0.05-0.15 per incident typically)
+   - Estimated duration in minutes
+
+GROUPING STRATEGY:
+- Group by category first (mandatory, optional, potential)
+- Within each category, group by effort level (high effort separate from low effort)
+- Consider dependencies and risk
+- Explain the reasoning for each grouping
+
+RISK TOLERANCE: %s
+- conservative: Smaller phases, lower risk, more phases
+- balanced: Moderate phase sizes, mixed complexity
+- aggressive: Larger phases, higher efficiency, fewer phases
+
+OUTPUT FORMAT: Return a valid JSON array of phases:
+[
+  {
+    "id": "phase-1",
+    "name": "Critical Mandatory Fixes - High Effort",
+    "order": 1,
+    "risk": "high",
+    "category": "mandatory",
+    "effort_range": [5, 7],
+    "explanation": "These violations require significant refactoring of core APIs...",
+    "violation_ids": ["javax-to-jakarta-001", "javax-to-jakarta-002"],
+    "estimated_cost": 2.45,
+    "estimated_duration_minutes": 15
+  }
+]
+
+Return ONLY the JSON array with no additional text or markdown formatting.`,
+		string(violationsJSON),
+		maxPhases,
+		req.RiskTolerance)
+}
+
+// ParsePlanResponse parses a provider's JSON plan response into PlannedPhase structs
+func ParsePlanResponse(responseText string, violations []violation.Violation) ([]PlannedPhase, error) {
+	// Extract JSON from response (handle markdown code blocks if present)
+	jsonStr := extractJSON(responseText)
+
+	var rawPhases []map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &rawPhases); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w\nResponse: %s", err, responseText)
+	}
+
+	phases := make([]PlannedPhase, 0, len(rawPhases))
+	for _, raw := range rawPhases {
+		phase := PlannedPhase{
+			ID:          getString(raw, "id"),
+			Name:        getString(raw, "name"),
+			Order:       getInt(raw, "order"),
+			Risk:        getString(raw, "risk"),
+			Category:    getString(raw, "category"),
+			Explanation: getString(raw, "explanation"),
+		}
+
+		// Parse effort_range as [min, max]
+		if effortRange, ok := raw["effort_range"].([]interface{}); ok && len(effortRange) >= 2 {
+			if min, ok := effortRange[0].(float64); ok {
+				phase.EffortRange[0] = int(min)
+			}
+			if max, ok := effortRange[1].(float64); ok {
+				phase.EffortRange[1] = int(max)
+			}
+		}
+
+		// Parse violation_ids
+		if ids, ok := raw["violation_ids"].([]interface{}); ok {
+			phase.ViolationIDs = make([]string, 0, len(ids))
+			for _, id := range ids {
+				if str, ok := id.(string); ok {
+					phase.ViolationIDs = append(phase.ViolationIDs, str)
+				}
+			}
+		}
+
+		phase.EstimatedCost = getFloat(raw, "estimated_cost")
+		phase.EstimatedDurationMinutes = getInt(raw, "estimated_duration_minutes")
+
+		phases = append(phases, phase)
+	}
+
+	return phases, nil
+}
+
+// extractJSON extracts JSON from a response that might contain markdown code blocks
+func extractJSON(text string) string {
+	// Try to extract JSON from markdown code blocks using pre-compiled regex
+	matches := jsonCodeBlockRegex.FindStringSubmatch(text)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+
+	// If no code blocks, try to find JSON array or object directly using pre-compiled regex
+	matches = jsonArrayRegex.FindStringSubmatch(text)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+
+	// Return original text if no JSON pattern found
+	return text
+}
+
+// Helper functions for safe type conversion from map[string]interface{}
+func getString(m map[string]interface{}, key string) string {
+	if val, ok := m[key].(string); ok {
+		return val
+	}
+	return ""
+}
+
+func getInt(m map[string]interface{}, key string) int {
+	if val, ok := m[key].(float64); ok {
+		return int(val)
+	}
+	return 0
+}
+
+func getFloat(m map[string]interface{}, key string) float64 {
+	if val, ok := m[key].(float64); ok {
+		return val
+	}
+	return 0.0
+}
+
+// MergePlannedPhases merges phases planned separately (e.g. for batches of
+// violations) into one plan: phases with the same category and risk are
+// combined, then ordered by priority (see phasePriority)
+func MergePlannedPhases(phases []PlannedPhase, maxPhases int) []PlannedPhase {
+	if len(phases) == 0 {
+		return phases
+	}
+
+	// Group phases by category and risk level
+	type phaseKey struct {
+		category string
+		risk     string
+	}
+
+	groups := make(map[phaseKey][]PlannedPhase)
+	for _, phase := range phases {
+		key := phaseKey{
+			category: phase.Category,
+			risk:     phase.Risk,
+		}
+		groups[key] = append(groups[key], phase)
+	}
+
+	// Merge phases within each group
+	var merged []PlannedPhase
+	for key, groupPhases := range groups {
+		if len(groupPhases) == 1 {
+			merged = append(merged, groupPhases[0])
+			continue
+		}
+
+		// Merge multiple phases into one
+		mergedPhase := PlannedPhase{
+			ID:                       fmt.Sprintf("phase-%s-%s", key.category, key.risk),
+			Name:                     fmt.Sprintf("%s - %s Risk", key.category, key.risk),
+			Risk:                     key.risk,
+			Category:                 key.category,
+			ViolationIDs:             []string{},
+			EstimatedCost:            0,
+			EstimatedDurationMinutes: 0,
+		}
+
+		// Aggregate from all phases in this group
+		minEffort := 10
+		maxEffort := 0
+		for _, phase := range groupPhases {
+			mergedPhase.ViolationIDs = append(mergedPhase.ViolationIDs, phase.ViolationIDs...)
+			mergedPhase.EstimatedCost += phase.EstimatedCost
+			mergedPhase.EstimatedDurationMinutes += phase.EstimatedDurationMinutes
+
+			if phase.EffortRange[0] < minEffort {
+				minEffort = phase.EffortRange[0]
+			}
+			if phase.EffortRange[1] > maxEffort {
+				maxEffort = phase.EffortRange[1]
+			}
+		}
+
+		mergedPhase.EffortRange = [2]int{minEffort, maxEffort}
+		mergedPhase.Explanation = fmt.Sprintf("Merged %d phases with %s violations at %s risk level.",
+			len(groupPhases), key.category, key.risk)
+
+		merged = append(merged, mergedPhase)
+	}
+
+	// Sort by priority: mandatory > optional > potential, then high risk > medium > low
+	sortPhasesByPriority(merged)
+
+	// Reassign order and IDs
+	for i := range merged {
+		merged[i].Order = i + 1
+		merged[i].ID = fmt.Sprintf("phase-%d", i+1)
+	}
+
+	// Limit to maxPhases if specified
+	if maxPhases > 0 && len(merged) > maxPhases {
+		merged = merged[:maxPhases]
+	}
+
+	return merged
+}
+
+// sortPhasesByPriority sorts phases by category and risk
+func sortPhasesByPriority(phases []PlannedPhase) {
+	// Simple bubble sort for small slices
+	for i := 0; i < len(phases); i++ {
+		for j := i + 1; j < len(phases); j++ {
+			if phasePriority(phases[i]) > phasePriority(phases[j]) {
+				phases[i], phases[j] = phases[j], phases[i]
+			}
+		}
+	}
+}
+
+// phasePriority returns a priority score (lower = higher priority)
+func phasePriority(phase PlannedPhase) int {
+	priority := 0
+
+	// Category priority (mandatory = 0, optional = 100, potential = 200)
+	switch phase.Category {
+	case "mandatory":
+		priority += 0
+	case "optional":
+		priority += 100
+	case "potential":
+		priority += 200
+	default:
+		priority += 300
+	}
+
+	// Risk priority (high = 0, medium = 10, low = 20)
+	switch phase.Risk {
+	case "high":
+		priority += 0
+	case "medium":
+		priority += 10
+	case "low":
+		priority += 20
+	default:
+		priority += 30
+	}
+
+	return priority
+}
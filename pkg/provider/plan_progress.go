@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PlanProgress estimates the time left in batched plan generation from the
+// average time a batch has taken so far, including the delay before it
+type PlanProgress struct {
+	total   int
+	done    int
+	elapsed time.Duration
+}
+
+// NewPlanProgress returns progress for total batches
+func NewPlanProgress(total int) *PlanProgress {
+	return &PlanProgress{total: total}
+}
+
+// BatchDone records a finished batch and how long it took
+func (p *PlanProgress) BatchDone(took time.Duration) {
+	p.done++
+	p.elapsed += took
+}
+
+// Remaining returns the estimated time until every batch is done, or 0 before
+// the first batch finishes
+func (p *PlanProgress) Remaining() time.Duration {
+	if p.done == 0 || p.done >= p.total {
+		return 0
+	}
+	return p.elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+}
+
+// ETA formats Remaining, e.g. "~3m remaining", or returns "" while there is
+// no estimate yet
+func (p *PlanProgress) ETA() string {
+	remaining := p.Remaining()
+	switch {
+	case remaining <= 0:
+		return ""
+	case remaining < time.Minute:
+		return fmt.Sprintf("~%ds remaining", int(math.Ceil(remaining.Seconds())))
+	default:
+		return fmt.Sprintf("~%dm remaining", int(math.Ceil(remaining.Minutes())))
+	}
+}
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanProgress(t *testing.T) {
+	progress := NewPlanProgress(5)
+	assert.Equal(t, "", progress.ETA(), "no estimate before the first batch")
+
+	progress.BatchDone(20 * time.Second)
+	progress.BatchDone(40 * time.Second)
+	assert.Equal(t, 90*time.Second, progress.Remaining())
+	assert.Equal(t, "~2m remaining", progress.ETA())
+
+	progress.BatchDone(30 * time.Second)
+	progress.BatchDone(30 * time.Second)
+	assert.Equal(t, "~30s remaining", progress.ETA())
+
+	progress.BatchDone(30 * time.Second)
+	assert.Zero(t, progress.Remaining())
+}
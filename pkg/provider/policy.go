@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Policy restricts which providers and models may be used, e.g. to the ones
+// approved in a regulated environment. The zero value allows everything.
+type Policy struct {
+	AllowedProviders []string // Provider names (empty = any)
+	AllowedModels    []string // Model names or glob patterns such as "claude-sonnet-*" (empty = any)
+	MaxTemperature   *float64 // Highest sampling temperature (nil = no limit)
+	RequireOffline   bool     // Only providers served from this machine (loopback base URL) or replay
+}
+
+// IsZero reports whether the policy allows everything
+func (p Policy) IsZero() bool {
+	return len(p.AllowedProviders) == 0 && len(p.AllowedModels) == 0 && p.MaxTemperature == nil && !p.RequireOffline
+}
+
+// Check returns an error if the policy doesn't allow the named provider with
+// config. Presets are checked with their default base URL and model. The
+// replay provider sends no requests and is always allowed.
+func (p Policy) Check(name string, config Config) error {
+	if name == ReplayProviderName {
+		return nil
+	}
+	if preset, ok := ProviderPresets[name]; ok {
+		if config.BaseURL == "" {
+			config.BaseURL = preset.BaseURL
+		}
+		if config.Model == "" {
+			config.Model = preset.DefaultModel
+		}
+	}
+
+	if len(p.AllowedProviders) > 0 && !containsString(p.AllowedProviders, name) {
+		return fmt.Errorf("provider %q is not allowed by policy (allowed providers: %s)", name, strings.Join(p.AllowedProviders, ", "))
+	}
+
+	if len(p.AllowedModels) > 0 {
+		if config.Model == "" {
+			return fmt.Errorf("policy restricts models to %s: set --model explicitly instead of using %s's default model",
+				strings.Join(p.AllowedModels, ", "), name)
+		}
+		if !matchesAny(p.AllowedModels, config.Model) {
+			return fmt.Errorf("model %q is not allowed by policy (allowed models: %s)", config.Model, strings.Join(p.AllowedModels, ", "))
+		}
+	}
+
+	if p.MaxTemperature != nil && config.Temperature > *p.MaxTemperature {
+		return fmt.Errorf("temperature %g exceeds the policy's maximum of %g", config.Temperature, *p.MaxTemperature)
+	}
+
+	if p.RequireOffline && !isLoopbackURL(config.BaseURL) {
+		where := "a hosted API"
+		if config.BaseURL != "" {
+			where = config.BaseURL
+		}
+		return fmt.Errorf("policy requires an offline provider, but %s sends code to %s (use a local provider such as ollama or lmstudio, or a localhost base URL)", name, where)
+	}
+
+	return nil
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackURL reports whether rawURL points at this machine
+func isLoopbackURL(rawURL string) bool {
+	if rawURL == "" {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	maxTemperature := 0.3
+	policy := Policy{
+		AllowedProviders: []string{"claude", "ollama", "openai"},
+		AllowedModels:    []string{"claude-sonnet-*", "codellama", "gpt-4o"},
+		MaxTemperature:   &maxTemperature,
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		config   Config
+		wantErr  string
+	}{
+		{name: "allowed provider and model", provider: "claude", config: Config{Model: "claude-sonnet-4-20250514", Temperature: 0.2}},
+		{name: "exact model name", provider: "openai", config: Config{Model: "gpt-4o", Temperature: 0.2}},
+		{name: "preset with its default model", provider: "ollama", config: Config{Temperature: 0.2}},
+		{name: "temperature at the maximum", provider: "claude", config: Config{Model: "claude-sonnet-4", Temperature: 0.3}},
+		{name: "replay is always allowed", provider: ReplayProviderName},
+
+		{name: "provider not allowed", provider: "groq", config: Config{Model: "codellama", Temperature: 0.2}, wantErr: `provider "groq" is not allowed by policy (allowed providers: claude, ollama, openai)`},
+		{name: "model not allowed", provider: "claude", config: Config{Model: "claude-opus-4", Temperature: 0.2}, wantErr: `model "claude-opus-4" is not allowed by policy`},
+		{name: "preset default model not allowed", provider: "ollama", config: Config{Model: "llama3", Temperature: 0.2}, wantErr: `model "llama3" is not allowed`},
+		{name: "builtin default model can't be checked", provider: "openai", config: Config{Temperature: 0.2}, wantErr: "set --model explicitly"},
+		{name: "temperature too high", provider: "claude", config: Config{Model: "claude-sonnet-4", Temperature: 0.7}, wantErr: "temperature 0.7 exceeds the policy's maximum of 0.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.provider, tt.config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_RequireOffline(t *testing.T) {
+	policy := Policy{RequireOffline: true}
+
+	assert.NoError(t, policy.Check("ollama", Config{}))
+	assert.NoError(t, policy.Check("lmstudio", Config{}))
+	assert.NoError(t, policy.Check("openai", Config{BaseURL: "http://127.0.0.1:8000/v1"}))
+	assert.NoError(t, policy.Check("openai", Config{BaseURL: "http://[::1]:8000/v1"}))
+	assert.NoError(t, policy.Check(ReplayProviderName, Config{}))
+
+	err := policy.Check("claude", Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "policy requires an offline provider, but claude sends code to a hosted API")
+
+	err = policy.Check("groq", Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "https://api.groq.com/openai/v1")
+
+	// A preset pointed at a remote host is not offline
+	assert.Error(t, policy.Check("ollama", Config{BaseURL: "http://gpu-box.internal:11434/v1"}))
+}
+
+func TestPolicy_IsZero(t *testing.T) {
+	assert.True(t, Policy{}.IsZero())
+	assert.NoError(t, Policy{}.Check("groq", Config{Temperature: 1}))
+	assert.False(t, Policy{RequireOffline: true}.IsZero())
+}
@@ -0,0 +1,38 @@
+package provider
+
+import "fmt"
+
+// Pricing is what a model charges, in USD per million tokens
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost returns the cost in USD of a request with the given token counts
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)*p.InputPerMillion/1000000.0 + float64(outputTokens)*p.OutputPerMillion/1000000.0
+}
+
+// String formats the pricing for display, e.g. "$3.00 / $15.00 per 1M tokens"
+func (p Pricing) String() string {
+	if p.InputPerMillion == 0 && p.OutputPerMillion == 0 {
+		return "free"
+	}
+	return fmt.Sprintf("$%.2f / $%.2f per 1M tokens", p.InputPerMillion, p.OutputPerMillion)
+}
+
+// PricingOr returns the configured pricing, or fallback if none was configured
+func (c Config) PricingOr(fallback Pricing) Pricing {
+	if c.Pricing != nil {
+		return *c.Pricing
+	}
+	return fallback
+}
+
+// NewPricing validates token prices given on the command line
+func NewPricing(inputPerMillion, outputPerMillion float64) (*Pricing, error) {
+	if inputPerMillion < 0 || outputPerMillion < 0 {
+		return nil, fmt.Errorf("token prices must not be negative")
+	}
+	return &Pricing{InputPerMillion: inputPerMillion, OutputPerMillion: outputPerMillion}, nil
+}
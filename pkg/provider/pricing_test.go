@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	pricing := Pricing{InputPerMillion: 3.0, OutputPerMillion: 15.0}
+	assert.InDelta(t, 0.021, pricing.Cost(2000, 1000), 1e-9)
+	assert.Zero(t, Pricing{}.Cost(2000, 1000))
+
+	assert.Equal(t, "$3.00 / $15.00 per 1M tokens", pricing.String())
+	assert.Equal(t, "free", Pricing{}.String())
+}
+
+func TestPresetPricing(t *testing.T) {
+	for _, name := range []string{"ollama", "lmstudio"} {
+		preset := ProviderPresets[name]
+		require.NotNil(t, preset.Pricing, name)
+		assert.Zero(t, preset.Pricing.Cost(1000000, 1000000), "local models are free")
+	}
+	for name, preset := range ProviderPresets {
+		assert.NotNil(t, preset.Pricing, "%s declares its pricing", name)
+	}
+}
+
+func TestConfig_PricingOr(t *testing.T) {
+	fallback := Pricing{InputPerMillion: 30, OutputPerMillion: 60}
+	assert.Equal(t, fallback, Config{}.PricingOr(fallback))
+	assert.Equal(t, Pricing{}, Config{Pricing: &Pricing{}}.PricingOr(fallback), "zero pricing is kept")
+}
+
+func TestNewPricing(t *testing.T) {
+	pricing, err := NewPricing(0.5, 1.5)
+	require.NoError(t, err)
+	assert.Equal(t, Pricing{InputPerMillion: 0.5, OutputPerMillion: 1.5}, *pricing)
+
+	_, err = NewPricing(-1, 1)
+	assert.Error(t, err)
+}
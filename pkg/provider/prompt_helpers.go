@@ -2,6 +2,7 @@ package provider
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/tsanders/kantra-ai/pkg/prompt"
 )
@@ -15,6 +16,7 @@ func BuildSingleFixData(req FixRequest) prompt.SingleFixData {
 		RuleMessage:     req.Violation.Rule.Message,
 		File:            req.Incident.URI,
 		Line:            req.Incident.LineNumber,
+		Column:          req.Incident.GetColumn(),
 		CodeSnippet:     req.Incident.CodeSnip,
 		FileContent:     req.FileContent,
 		Language:        req.Language,
@@ -22,6 +24,51 @@ func BuildSingleFixData(req FixRequest) prompt.SingleFixData {
 	}
 }
 
+// diffResponseInstructions overrides the output format of single-fix templates
+// so the model returns a unified diff instead of the entire file
+const diffResponseInstructions = `
+
+RESPONSE FORMAT OVERRIDE:
+Do NOT return the complete file. Instead, return a JSON object with these fields:
+- "patch": A unified diff (as produced by "diff -u") against the FULL FILE CONTENT above,
+  with "@@ -start,count +start,count @@" hunk headers and 3 lines of context per hunk
+- "confidence": A confidence score between 0.0 and 1.0
+- "explanation": A brief explanation of what was changed
+
+Example response format:
+{
+  "patch": "--- a/File.java\n+++ b/File.java\n@@ -1,3 +1,3 @@\n-import javax.servlet.*;\n+import jakarta.servlet.*;\n ...",
+  "confidence": 0.95,
+  "explanation": "Replaced javax import with jakarta"
+}`
+
+// ApplyResponseFormat appends format-specific instructions to a rendered single-fix prompt.
+// Full-content prompts are returned unchanged.
+func ApplyResponseFormat(promptText string, format ResponseFormat) string {
+	if format == ResponseFormatDiff {
+		return promptText + diffResponseInstructions
+	}
+	return promptText
+}
+
+// IsUnifiedDiff reports whether text looks like a unified diff (has a hunk header)
+func IsUnifiedDiff(text string) bool {
+	return strings.Contains(text, "@@ -")
+}
+
+// PreferPatch makes a diff-format fix response carry its patch instead of
+// the full file content. patch is the response's parsed patch field, or with
+// raw set, the whole response text when it didn't parse as JSON, which is
+// only taken as a patch if it looks like one. Models occasionally return full
+// content anyway, which is kept.
+func PreferPatch(resp *FixResponse, format ResponseFormat, patch string, raw bool) {
+	if format != ResponseFormatDiff || patch == "" || (raw && !IsUnifiedDiff(patch)) {
+		return
+	}
+	resp.Patch = patch
+	resp.FixedContent = ""
+}
+
 // BuildBatchFixData constructs template data from a BatchRequest
 func BuildBatchFixData(req BatchRequest) prompt.BatchFixData {
 	incidents := make([]prompt.BatchIncident, len(req.Incidents))
@@ -39,6 +86,7 @@ func BuildBatchFixData(req BatchRequest) prompt.BatchFixData {
 			Index:       i + 1,
 			File:        filePath,
 			Line:        incident.LineNumber,
+			Column:      incident.GetColumn(),
 			Message:     incident.Message,
 			CodeContext: codeContext,
 		}
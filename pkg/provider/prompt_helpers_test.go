@@ -77,6 +77,21 @@ func TestBuildSingleFixData(t *testing.T) {
 	})
 }
 
+func TestBuildFixData_Column(t *testing.T) {
+	withColumn := violation.Incident{URI: "file:///src/A.java", LineNumber: 3, Column: 8}
+	withoutColumn := violation.Incident{URI: "file:///src/B.java", LineNumber: 5}
+
+	single := BuildSingleFixData(FixRequest{Incident: withColumn})
+	assert.Equal(t, 8, single.Column)
+
+	single = BuildSingleFixData(FixRequest{Incident: withoutColumn})
+	assert.Equal(t, 0, single.Column)
+
+	batch := BuildBatchFixData(BatchRequest{Incidents: []violation.Incident{withColumn, withoutColumn}})
+	assert.Equal(t, 8, batch.Incidents[0].Column)
+	assert.Equal(t, 0, batch.Incidents[1].Column)
+}
+
 func TestBuildBatchFixData(t *testing.T) {
 	t.Run("builds batch data with multiple incidents", func(t *testing.T) {
 		req := BatchRequest{
@@ -536,3 +551,31 @@ public class MyFilter implements Filter {
 		assert.Contains(t, result.Incidents[1].CodeContext, "javax.servlet.Filter")
 	})
 }
+
+func TestPreferPatch(t *testing.T) {
+	patch := "@@ -1 +1 @@\n-import javax.a;\n+import jakarta.a;\n"
+
+	resp := &FixResponse{FixedContent: "class A {}"}
+	PreferPatch(resp, ResponseFormatFull, patch, false)
+	assert.Equal(t, "class A {}", resp.FixedContent, "full format keeps the content")
+	assert.Empty(t, resp.Patch)
+
+	resp = &FixResponse{FixedContent: "class A {}"}
+	PreferPatch(resp, ResponseFormatDiff, "", false)
+	assert.Equal(t, "class A {}", resp.FixedContent, "no patch: the full content the model returned anyway")
+
+	resp = &FixResponse{FixedContent: "class A {}"}
+	PreferPatch(resp, ResponseFormatDiff, patch, false)
+	assert.Equal(t, patch, resp.Patch)
+	assert.Empty(t, resp.FixedContent)
+
+	raw := "class A {}"
+	resp = &FixResponse{FixedContent: raw}
+	PreferPatch(resp, ResponseFormatDiff, raw, true)
+	assert.Equal(t, raw, resp.FixedContent, "raw text without a hunk header isn't a patch")
+
+	resp = &FixResponse{FixedContent: patch}
+	PreferPatch(resp, ResponseFormatDiff, patch, true)
+	assert.Equal(t, patch, resp.Patch)
+	assert.Empty(t, resp.FixedContent)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// charsPerToken approximates how many characters make up one token
+const charsPerToken = 4
+
+// ErrPromptTooLarge is returned (wrapped in a *PromptTooLargeError) when an
+// assembled prompt exceeds the model's input limit
+var ErrPromptTooLarge = errors.New("prompt too large")
+
+// PromptTooLargeError reports a prompt that failed the pre-flight size check
+type PromptTooLargeError struct {
+	Tokens int // Estimated prompt tokens
+	Limit  int // Maximum prompt tokens allowed
+}
+
+func (e *PromptTooLargeError) Error() string {
+	return fmt.Sprintf("prompt too large (%d tokens > limit %d), consider a smaller --max-batch-size or diff mode (--response-format=diff)", e.Tokens, e.Limit)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrPromptTooLarge)
+func (e *PromptTooLargeError) Unwrap() error {
+	return ErrPromptTooLarge
+}
+
+// modelContextWindows maps model name prefixes to context window sizes in
+// tokens. More specific prefixes must come before shorter ones.
+var modelContextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 128000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"gemini-1.5-pro", 2000000},
+	{"gemini-", 1000000},
+}
+
+// ModelContextWindow returns the context window of a known model in tokens,
+// or 0 if the model is unknown
+func ModelContextWindow(model string) int {
+	model = strings.ToLower(model)
+	for _, entry := range modelContextWindows {
+		if strings.HasPrefix(model, entry.prefix) {
+			return entry.tokens
+		}
+	}
+	return 0
+}
+
+// PromptTokenLimit returns the maximum prompt size for a request. An explicit
+// limit wins; otherwise the model's context window minus the tokens reserved
+// for the response is used. Returns 0 (no limit) for unknown models.
+func PromptTokenLimit(model string, explicit int, maxOutputTokens int) int {
+	if explicit > 0 {
+		return explicit
+	}
+	window := ModelContextWindow(model)
+	if window == 0 {
+		return 0
+	}
+	return window - maxOutputTokens
+}
+
+// EstimatePromptTokens estimates the number of tokens in a prompt
+// (1 token ≈ 4 characters)
+func EstimatePromptTokens(promptText string) int {
+	return (len(promptText) + charsPerToken - 1) / charsPerToken
+}
+
+// CheckPromptSize returns a *PromptTooLargeError if the prompt's estimated
+// size exceeds limit. A limit of 0 disables the check.
+func CheckPromptSize(promptText string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	if tokens := EstimatePromptTokens(promptText); tokens > limit {
+		return &PromptTooLargeError{Tokens: tokens, Limit: limit}
+	}
+	return nil
+}
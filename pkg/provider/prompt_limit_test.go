@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelContextWindow(t *testing.T) {
+	assert.Equal(t, 200000, ModelContextWindow("claude-sonnet-4-20250514"))
+	assert.Equal(t, 128000, ModelContextWindow("gpt-4o-mini"))
+	assert.Equal(t, 8192, ModelContextWindow("gpt-4"))
+	assert.Equal(t, 32768, ModelContextWindow("gpt-4-32k-0613"))
+	assert.Equal(t, 1000000, ModelContextWindow("gemini-2.0-flash"))
+	assert.Equal(t, 0, ModelContextWindow("codellama"))
+}
+
+func TestPromptTokenLimit(t *testing.T) {
+	assert.Equal(t, 196000, PromptTokenLimit("claude-sonnet-4-20250514", 0, 4000))
+	assert.Equal(t, 5000, PromptTokenLimit("claude-sonnet-4-20250514", 5000, 4000), "explicit limit wins")
+	assert.Equal(t, 0, PromptTokenLimit("codellama", 0, 4000), "unknown model has no limit")
+}
+
+func TestCheckPromptSize(t *testing.T) {
+	assert.NoError(t, CheckPromptSize(strings.Repeat("a", 400), 100))
+	assert.NoError(t, CheckPromptSize(strings.Repeat("a", 4000), 0), "zero limit disables the check")
+
+	err := CheckPromptSize(strings.Repeat("a", 401), 100)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPromptTooLarge))
+
+	var tooLarge *PromptTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, 101, tooLarge.Tokens)
+	assert.Equal(t, 100, tooLarge.Limit)
+	assert.Contains(t, err.Error(), "prompt too large (101 tokens > limit 100)")
+}
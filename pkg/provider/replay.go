@@ -0,0 +1,390 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"github.com/tsanders/kantra-ai/pkg/redact"
+)
+
+// ReplayProviderName is the --provider name of the ReplayProvider
+const ReplayProviderName = "replay"
+
+// Recorded response types
+const (
+	recordedFixType   = "fix"
+	recordedBatchType = "batch"
+	recordedPlanType  = "plan"
+)
+
+// recordedResponse is one line of a responses file written by a
+// RecordingProvider. PromptHash identifies the request: it hashes every
+// input the prompt is rendered from.
+type recordedResponse struct {
+	Type       string         `json:"type"`
+	PromptHash string         `json:"prompt_hash"`
+	Provider   string         `json:"provider,omitempty"`
+	Error      string         `json:"error,omitempty"` // The call failed with this error
+	Fix        *recordedFix   `json:"fix,omitempty"`
+	Batch      *recordedBatch `json:"batch,omitempty"`
+	Plan       *recordedPlan  `json:"plan,omitempty"`
+}
+
+type recordedFix struct {
+	Success      bool                   `json:"success"`
+	FixedContent string                 `json:"fixed_content,omitempty"`
+	Patch        string                 `json:"patch,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Confidence   float64                `json:"confidence"`
+	TokensUsed   int                    `json:"tokens_used,omitempty"`
+	Cost         float64                `json:"cost,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
+}
+
+type recordedIncidentFix struct {
+	IncidentURI  string                 `json:"incident_uri"`
+	Success      bool                   `json:"success"`
+	FixedContent string                 `json:"fixed_content,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Confidence   float64                `json:"confidence"`
+	Error        string                 `json:"error,omitempty"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
+}
+
+type recordedBatch struct {
+	Fixes      []recordedIncidentFix `json:"fixes"`
+	Success    bool                  `json:"success"`
+	TokensUsed int                   `json:"tokens_used,omitempty"`
+	Cost       float64               `json:"cost,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+type recordedPlan struct {
+	Phases     []PlannedPhase `json:"phases"`
+	TokensUsed int            `json:"tokens_used,omitempty"`
+	Cost       float64        `json:"cost,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// promptHash identifies a request of the given type for recording and replay
+func promptHash(requestType string, req interface{}) string {
+	data, _ := json.Marshal(req)
+	return common.HashKey(requestType, string(data))
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func stringError(s string) error {
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
+
+// RecordingProvider wraps a provider and appends every response it returns
+// to a responses file, which a ReplayProvider can play back. Fixed content,
+// explanations and errors are redacted before they are written.
+type RecordingProvider struct {
+	Provider
+	path     string
+	redactor *redact.Redactor
+
+	mu sync.Mutex
+}
+
+// NewRecordingProvider wraps p, recording its responses to path. An existing
+// file is replaced. redactor redacts the explanations and errors recorded;
+// fixed content and patches are recorded as returned, since replaying them
+// must write the same code. redactor may be nil to record everything as
+// returned.
+func NewRecordingProvider(p Provider, path string, redactor *redact.Redactor) (*RecordingProvider, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create responses file directory: %w", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create responses file: %w", err)
+	}
+	return &RecordingProvider{Provider: p, path: path, redactor: redactor}, nil
+}
+
+// FixViolation asks the wrapped provider for a fix and records the response
+func (r *RecordingProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	resp, err := r.Provider.FixViolation(ctx, req)
+
+	entry := recordedResponse{Type: recordedFixType, PromptHash: promptHash(recordedFixType, req), Error: errorString(err)}
+	if resp != nil {
+		entry.Fix = &recordedFix{
+			Success:      resp.Success,
+			FixedContent: resp.FixedContent,
+			Patch:        resp.Patch,
+			Explanation:  resp.Explanation,
+			Confidence:   resp.Confidence,
+			TokensUsed:   resp.TokensUsed,
+			Cost:         resp.Cost,
+			Error:        errorString(resp.Error),
+			Extra:        resp.Extra,
+		}
+	}
+	if recordErr := r.record(entry); recordErr != nil {
+		return nil, recordErr
+	}
+	return resp, err
+}
+
+// FixBatch asks the wrapped provider for a batch of fixes and records the response
+func (r *RecordingProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	resp, err := r.Provider.FixBatch(ctx, req)
+
+	entry := recordedResponse{Type: recordedBatchType, PromptHash: promptHash(recordedBatchType, req), Error: errorString(err)}
+	if resp != nil {
+		batch := &recordedBatch{
+			Success:    resp.Success,
+			TokensUsed: resp.TokensUsed,
+			Cost:       resp.Cost,
+			Error:      errorString(resp.Error),
+		}
+		for _, fix := range resp.Fixes {
+			batch.Fixes = append(batch.Fixes, recordedIncidentFix{
+				IncidentURI:  fix.IncidentURI,
+				Success:      fix.Success,
+				FixedContent: fix.FixedContent,
+				Explanation:  fix.Explanation,
+				Confidence:   fix.Confidence,
+				Error:        errorString(fix.Error),
+				Extra:        fix.Extra,
+			})
+		}
+		entry.Batch = batch
+	}
+	if recordErr := r.record(entry); recordErr != nil {
+		return nil, recordErr
+	}
+	return resp, err
+}
+
+// GeneratePlan asks the wrapped provider for a plan and records the response
+func (r *RecordingProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	resp, err := r.Provider.GeneratePlan(ctx, req)
+
+	entry := recordedResponse{Type: recordedPlanType, PromptHash: promptHash(recordedPlanType, req), Error: errorString(err)}
+	if resp != nil {
+		entry.Plan = &recordedPlan{
+			Phases:     resp.Phases,
+			TokensUsed: resp.TokensUsed,
+			Cost:       resp.Cost,
+			Error:      errorString(resp.Error),
+		}
+	}
+	if recordErr := r.record(entry); recordErr != nil {
+		return nil, recordErr
+	}
+	return resp, err
+}
+
+// record appends entry to the responses file. The file is opened for each
+// entry, so everything recorded before an interrupted run is kept.
+func (r *RecordingProvider) record(entry recordedResponse) error {
+	entry.Provider = r.Provider.Name()
+	r.redact(&entry)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+	return nil
+}
+
+// redact redacts the recorded explanations and errors of entry
+func (r *RecordingProvider) redact(entry *recordedResponse) {
+	entry.Error = r.redactor.Redact(entry.Error)
+	if fix := entry.Fix; fix != nil {
+		fix.Explanation = r.redactor.Redact(fix.Explanation)
+		fix.Error = r.redactor.Redact(fix.Error)
+	}
+	if batch := entry.Batch; batch != nil {
+		batch.Error = r.redactor.Redact(batch.Error)
+		for i := range batch.Fixes {
+			batch.Fixes[i].Explanation = r.redactor.Redact(batch.Fixes[i].Explanation)
+			batch.Fixes[i].Error = r.redactor.Redact(batch.Fixes[i].Error)
+		}
+	}
+	if plan := entry.Plan; plan != nil {
+		plan.Error = r.redactor.Redact(plan.Error)
+	}
+}
+
+// ReplayProvider returns the responses recorded by a RecordingProvider for
+// identical requests, without calling an API. Replayed responses cost
+// nothing and use no tokens. A request recorded several times gets its
+// responses in the recorded order, then the last one again.
+type ReplayProvider struct {
+	lenient bool
+
+	mu        sync.Mutex
+	responses map[string][]recordedResponse // type + prompt hash → responses not yet replayed
+}
+
+// NewReplayProvider loads the responses file at path. A request with no
+// recorded response is an error; if lenient, it gets a failed response
+// instead, so the run continues without that fix.
+func NewReplayProvider(path string, lenient bool) (*ReplayProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the %s provider requires a responses file (--replay-file), recorded with --dump-responses", ReplayProviderName)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open responses file: %w", err)
+	}
+	defer f.Close()
+
+	r := &ReplayProvider{lenient: lenient, responses: make(map[string][]recordedResponse)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Entries hold whole files
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry recordedResponse
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid responses file %s, line %d: %w", path, line, err)
+		}
+		key := entry.Type + ":" + entry.PromptHash
+		r.responses[key] = append(r.responses[key], entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read responses file: %w", err)
+	}
+	return r, nil
+}
+
+// Name returns the provider name
+func (r *ReplayProvider) Name() string {
+	return ReplayProviderName
+}
+
+// EstimateCost returns 0: replayed responses are free
+func (r *ReplayProvider) EstimateCost(req FixRequest) (float64, error) {
+	return 0, nil
+}
+
+// next returns the next recorded response for a request
+func (r *ReplayProvider) next(requestType string, req interface{}) (recordedResponse, bool) {
+	key := requestType + ":" + promptHash(requestType, req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		return recordedResponse{}, false
+	}
+	if len(responses) > 1 {
+		r.responses[key] = responses[1:]
+	}
+	return responses[0], true
+}
+
+func (r *ReplayProvider) missError(requestType string, req interface{}) error {
+	return fmt.Errorf("no recorded %s response for this request (prompt hash %s)", requestType, promptHash(requestType, req))
+}
+
+// FixViolation replays the recorded fix for req
+func (r *ReplayProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	entry, ok := r.next(recordedFixType, req)
+	if !ok {
+		err := r.missError(recordedFixType, req)
+		if r.lenient {
+			return &FixResponse{Success: false, Error: err}, nil
+		}
+		return nil, err
+	}
+	if entry.Fix == nil {
+		return nil, stringError(entry.Error)
+	}
+	return &FixResponse{
+		Success:      entry.Fix.Success,
+		FixedContent: entry.Fix.FixedContent,
+		Patch:        entry.Fix.Patch,
+		Explanation:  entry.Fix.Explanation,
+		Confidence:   entry.Fix.Confidence,
+		Error:        stringError(entry.Fix.Error),
+		Extra:        entry.Fix.Extra,
+	}, stringError(entry.Error)
+}
+
+// FixBatch replays the recorded batch of fixes for req
+func (r *ReplayProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	entry, ok := r.next(recordedBatchType, req)
+	if !ok {
+		err := r.missError(recordedBatchType, req)
+		if r.lenient {
+			return &BatchResponse{Success: false, Error: err}, nil
+		}
+		return nil, err
+	}
+	if entry.Batch == nil {
+		return nil, stringError(entry.Error)
+	}
+
+	resp := &BatchResponse{
+		Success: entry.Batch.Success,
+		Error:   stringError(entry.Batch.Error),
+	}
+	for _, fix := range entry.Batch.Fixes {
+		resp.Fixes = append(resp.Fixes, IncidentFix{
+			IncidentURI:  fix.IncidentURI,
+			Success:      fix.Success,
+			FixedContent: fix.FixedContent,
+			Explanation:  fix.Explanation,
+			Confidence:   fix.Confidence,
+			Error:        stringError(fix.Error),
+			Extra:        fix.Extra,
+		})
+	}
+	return resp, stringError(entry.Error)
+}
+
+// GeneratePlan replays the recorded plan for req
+func (r *ReplayProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	entry, ok := r.next(recordedPlanType, req)
+	if !ok {
+		err := r.missError(recordedPlanType, req)
+		if r.lenient {
+			return &PlanResponse{Error: err}, nil
+		}
+		return nil, err
+	}
+	if entry.Plan == nil {
+		return nil, stringError(entry.Error)
+	}
+	return &PlanResponse{
+		Phases: entry.Plan.Phases,
+		Error:  stringError(entry.Plan.Error),
+	}, stringError(entry.Error)
+}
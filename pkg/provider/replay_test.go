@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/redact"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// sessionProvider answers every kind of request, numbering its fixes so
+// repeated requests get distinguishable responses
+type sessionProvider struct {
+	countingProvider
+}
+
+func (p *sessionProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	if req.Language == "broken" {
+		return nil, fmt.Errorf("API error")
+	}
+	resp, err := p.countingProvider.FixViolation(ctx, req)
+	resp.FixedContent = fmt.Sprintf("%s (%d)", resp.FixedContent, p.calls)
+	resp.Extra = map[string]interface{}{"risk": "low"}
+	return resp, err
+}
+
+func (p *sessionProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	resp := &BatchResponse{Success: false, TokensUsed: 300, Cost: 0.03}
+	for _, incident := range req.Incidents {
+		resp.Fixes = append(resp.Fixes, IncidentFix{IncidentURI: incident.URI, Success: true, FixedContent: "fixed", Confidence: 0.8})
+	}
+	resp.Fixes[len(resp.Fixes)-1].Success = false
+	resp.Fixes[len(resp.Fixes)-1].Error = fmt.Errorf("no change needed")
+	return resp, nil
+}
+
+func (p *sessionProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	return &PlanResponse{
+		Phases:     []PlannedPhase{{ID: "phase-1", Name: "Mandatory", Order: 1, Risk: "low", ViolationIDs: []string{"javax-to-jakarta"}}},
+		TokensUsed: 500,
+		Cost:       0.05,
+	}, nil
+}
+
+func TestRecordingProvider_Replay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "recordings", "responses.jsonl")
+
+	fixReq := cacheTestRequest()
+	otherReq := cacheTestRequest()
+	otherReq.Incident.LineNumber = 7
+	brokenReq := cacheTestRequest()
+	brokenReq.Language = "broken"
+	batchReq := BatchRequest{
+		Violation:    fixReq.Violation,
+		Incidents:    []violation.Incident{{URI: "file:///src/A.java"}, {URI: "file:///src/B.java"}},
+		FileContents: map[string]string{"src/A.java": "a", "src/B.java": "b"},
+		Language:     "java",
+	}
+	planReq := PlanRequest{Violations: []violation.Violation{fixReq.Violation}, RiskTolerance: "balanced"}
+
+	// Record a session
+	recorder, err := NewRecordingProvider(&sessionProvider{countingProvider{success: true}}, path, nil)
+	require.NoError(t, err)
+
+	first, err := recorder.FixViolation(ctx, fixReq)
+	require.NoError(t, err)
+	second, err := recorder.FixViolation(ctx, fixReq)
+	require.NoError(t, err)
+	other, err := recorder.FixViolation(ctx, otherReq)
+	require.NoError(t, err)
+	_, err = recorder.FixViolation(ctx, brokenReq)
+	require.Error(t, err)
+	batch, err := recorder.FixBatch(ctx, batchReq)
+	require.NoError(t, err)
+	plan, err := recorder.GeneratePlan(ctx, planReq)
+	require.NoError(t, err)
+
+	// Replay it
+	replay, err := NewReplayProvider(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, ReplayProviderName, replay.Name())
+
+	resp, err := replay.FixViolation(ctx, fixReq)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, first.FixedContent, resp.FixedContent)
+	assert.Equal(t, first.Explanation, resp.Explanation)
+	assert.Equal(t, first.Confidence, resp.Confidence)
+	assert.Equal(t, first.Extra, resp.Extra)
+	assert.Zero(t, resp.Cost)
+	assert.Zero(t, resp.TokensUsed)
+
+	// Repeated requests replay in the recorded order, then repeat the last
+	resp, err = replay.FixViolation(ctx, fixReq)
+	require.NoError(t, err)
+	assert.Equal(t, second.FixedContent, resp.FixedContent)
+	resp, err = replay.FixViolation(ctx, fixReq)
+	require.NoError(t, err)
+	assert.Equal(t, second.FixedContent, resp.FixedContent)
+
+	resp, err = replay.FixViolation(ctx, otherReq)
+	require.NoError(t, err)
+	assert.Equal(t, other.FixedContent, resp.FixedContent)
+
+	_, err = replay.FixViolation(ctx, brokenReq)
+	require.Error(t, err)
+	assert.Equal(t, "API error", err.Error())
+
+	batchResp, err := replay.FixBatch(ctx, batchReq)
+	require.NoError(t, err)
+	assert.Equal(t, batch.Success, batchResp.Success)
+	require.Len(t, batchResp.Fixes, 2)
+	assert.Equal(t, batch.Fixes[0].IncidentURI, batchResp.Fixes[0].IncidentURI)
+	assert.Equal(t, batch.Fixes[0].FixedContent, batchResp.Fixes[0].FixedContent)
+	assert.EqualError(t, batchResp.Fixes[1].Error, "no change needed")
+	assert.Zero(t, batchResp.Cost)
+
+	planResp, err := replay.GeneratePlan(ctx, planReq)
+	require.NoError(t, err)
+	assert.Equal(t, plan.Phases, planResp.Phases)
+	assert.Zero(t, planResp.Cost)
+
+	cost, err := replay.EstimateCost(fixReq)
+	require.NoError(t, err)
+	assert.Zero(t, cost)
+}
+
+func TestReplayProvider_Miss(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "responses.jsonl")
+	recorder, err := NewRecordingProvider(&sessionProvider{countingProvider{success: true}}, path, nil)
+	require.NoError(t, err)
+	_, err = recorder.FixViolation(ctx, cacheTestRequest())
+	require.NoError(t, err)
+
+	changed := cacheTestRequest()
+	changed.FileContent += "// edited\n"
+
+	t.Run("strict", func(t *testing.T) {
+		replay, err := NewReplayProvider(path, false)
+		require.NoError(t, err)
+
+		_, err = replay.FixViolation(ctx, changed)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no recorded fix response")
+
+		_, err = replay.FixBatch(ctx, BatchRequest{})
+		assert.Error(t, err)
+		_, err = replay.GeneratePlan(ctx, PlanRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		replay, err := NewReplayProvider(path, true)
+		require.NoError(t, err)
+
+		resp, err := replay.FixViolation(ctx, changed)
+		require.NoError(t, err)
+		assert.False(t, resp.Success)
+		assert.Error(t, resp.Error)
+
+		batchResp, err := replay.FixBatch(ctx, BatchRequest{})
+		require.NoError(t, err)
+		assert.False(t, batchResp.Success)
+		assert.Error(t, batchResp.Error)
+	})
+}
+
+func TestNewRecordingProvider_ReplacesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "responses.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("old recording\n"), 0644))
+
+	_, err := NewRecordingProvider(&countingProvider{}, path, nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+// secretProvider returns fixes that contain a credential
+type secretProvider struct {
+	countingProvider
+}
+
+func (p *secretProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	return &FixResponse{
+		Success:      true,
+		FixedContent: "String password = config.getPassword();",
+		Explanation:  "kept api_key=abc123",
+	}, nil
+}
+
+func TestRecordingProvider_Redacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "responses.jsonl")
+	redactor, err := redact.New(redact.Config{HashCode: true})
+	require.NoError(t, err)
+
+	recorder, err := NewRecordingProvider(&secretProvider{}, path, redactor)
+	require.NoError(t, err)
+	resp, err := recorder.FixViolation(context.Background(), cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "String password = config.getPassword();", resp.FixedContent, "the caller gets the response as returned")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "abc123", "explanations are redacted")
+	assert.Contains(t, string(data), redact.Placeholder)
+
+	// The fix replays as recorded
+	replay, err := NewReplayProvider(path, false)
+	require.NoError(t, err)
+	replayed, err := replay.FixViolation(context.Background(), cacheTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "String password = config.getPassword();", replayed.FixedContent)
+	assert.NotContains(t, replayed.Explanation, "abc123")
+}
+
+func TestNewReplayProvider_Errors(t *testing.T) {
+	_, err := NewReplayProvider("", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--replay-file")
+
+	_, err = NewReplayProvider(filepath.Join(t.TempDir(), "missing.jsonl"), false)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "responses.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"type\":\"fix\"}\nnot json\n"), 0644))
+	_, err = NewReplayProvider(path, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
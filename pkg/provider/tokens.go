@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// FixOutputRatio models a fix's output tokens as a fraction of its prompt
+// tokens: the response repeats most of the file the prompt contains, without
+// the prompt's instructions and context
+const FixOutputRatio = 0.5
+
+// defaultOpenAIEncoding is the tokenizer of OpenAI models tiktoken doesn't
+// know yet (e.g. gpt-4.1 and the o-series)
+const defaultOpenAIEncoding = "o200k_base"
+
+func init() {
+	// Use the encodings bundled with the binary instead of downloading them
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = make(map[string]*tiktoken.Tiktoken) // By model
+)
+
+// openAIEncoding returns the tokenizer of an OpenAI model, loading it once
+func openAIEncoding(model string) (*tiktoken.Tiktoken, error) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	if encoding, ok := encodings[model]; ok {
+		return encoding, nil
+	}
+	encoding, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		if encoding, err = tiktoken.GetEncoding(defaultOpenAIEncoding); err != nil {
+			return nil, err
+		}
+	}
+	encodings[model] = encoding
+	return encoding, nil
+}
+
+// CountTokens counts the tokens of text for model: exactly with tiktoken for
+// OpenAI models, and otherwise estimated from its length (see
+// EstimatePromptTokens), which is close for Claude and Gemini on code
+func CountTokens(model, text string) int {
+	if modelFamily(model) == "openai" {
+		if encoding, err := openAIEncoding(strings.ToLower(model)); err == nil {
+			return len(encoding.EncodeOrdinary(text))
+		}
+	}
+	return EstimatePromptTokens(text)
+}
+
+// EstimateFixTokens returns the estimated input and output tokens of a fix
+// request with the rendered promptText, for cost estimates
+func EstimateFixTokens(model, promptText string) (input, output int) {
+	input = CountTokens(model, promptText)
+	return input, int(float64(input) * FixOutputRatio)
+}
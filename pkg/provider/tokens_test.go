@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountTokens(t *testing.T) {
+	code := strings.Repeat("import javax.servlet.http.HttpServletRequest;\n", 100)
+
+	t.Run("OpenAI models are tokenized", func(t *testing.T) {
+		assert.Equal(t, 2, CountTokens("gpt-4o", "hello world"))
+		assert.Equal(t, 2, CountTokens("gpt-4", "hello world"))
+		assert.Equal(t, 2, CountTokens("o3-mini", "hello world"), "unknown to tiktoken: o200k_base")
+
+		tokens := CountTokens("gpt-4o", code)
+		assert.NotEqual(t, EstimatePromptTokens(code), tokens)
+		assert.InEpsilon(t, EstimatePromptTokens(code), tokens, 0.5, "close to the length estimate")
+	})
+
+	t.Run("other models are estimated from the length", func(t *testing.T) {
+		assert.Equal(t, EstimatePromptTokens(code), CountTokens("claude-sonnet-4-20250514", code))
+		assert.Equal(t, EstimatePromptTokens(code), CountTokens("llama-3.1-70b-versatile", code))
+		assert.Equal(t, EstimatePromptTokens(code), CountTokens("", code))
+	})
+}
+
+func TestEstimateFixTokens(t *testing.T) {
+	small, _ := EstimateFixTokens("claude-sonnet-4", "fix this")
+	input, output := EstimateFixTokens("claude-sonnet-4", strings.Repeat("x", 40000))
+	assert.Equal(t, 10000, input)
+	assert.Equal(t, 5000, output)
+	assert.Less(t, small, input, "scales with the prompt")
+}
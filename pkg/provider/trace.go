@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"github.com/tsanders/kantra-ai/pkg/redact"
+)
+
+// Trace file types
+const (
+	traceFixType   = "fix"
+	traceBatchType = "batch"
+	tracePlanType  = "plan"
+)
+
+// traceNameRegex matches characters not kept in trace file names
+var traceNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// traceFile is the JSON trace of one provider request
+type traceFile struct {
+	Type        string              `json:"type"`
+	Provider    string              `json:"provider"`
+	ViolationID string              `json:"violation_id,omitempty"`
+	IncidentURI string              `json:"incident_uri,omitempty"`
+	LineNumber  int                 `json:"line_number,omitempty"`
+	Started     time.Time           `json:"started"`
+	DurationMS  int64               `json:"duration_ms"`
+	Success     bool                `json:"success"`
+	Confidence  float64             `json:"confidence,omitempty"`
+	Fixes       []tracedIncidentFix `json:"fixes,omitempty"` // Batch requests
+	TokensUsed  int                 `json:"tokens_used"`
+	Cost        float64             `json:"cost"`
+	Error       string              `json:"error,omitempty"`
+	Exchanges   []tracedExchange    `json:"exchanges"` // Empty for a cached or replayed response
+}
+
+// tracedIncidentFix is the outcome of one incident of a batch request
+type tracedIncidentFix struct {
+	IncidentURI string  `json:"incident_uri"`
+	LineNumber  int     `json:"line_number,omitempty"`
+	Success     bool    `json:"success"`
+	Confidence  float64 `json:"confidence,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// tracedExchange is one HTTP request to the provider API. The bodies are
+// embedded as JSON when they are JSON, and as strings otherwise.
+type tracedExchange struct {
+	URL        string      `json:"url"`
+	Status     int         `json:"status,omitempty"`
+	DurationMS int64       `json:"duration_ms"`
+	Request    interface{} `json:"request"`
+	Response   interface{} `json:"response,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// TracingProvider wraps a provider and writes a JSON trace file for each
+// request: the exact request sent to the API (the rendered prompt), the raw
+// response, the parsed confidence and the timing. Retries and fallbacks add
+// an exchange each. Bodies are redacted, and headers (which carry the API
+// key) are never traced.
+type TracingProvider struct {
+	Provider
+	dir      string
+	redactor *redact.Redactor
+
+	seq atomic.Int64
+}
+
+// NewTracingProvider wraps p, writing its traces to dir. redactor may be nil
+// to trace bodies as sent.
+func NewTracingProvider(p Provider, dir string, redactor *redact.Redactor) (*TracingProvider, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	return &TracingProvider{Provider: p, dir: dir, redactor: redactor}, nil
+}
+
+// FixViolation asks the wrapped provider for a fix and traces the request
+func (t *TracingProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	trace := &common.Trace{}
+	started := time.Now()
+	resp, err := t.Provider.FixViolation(common.WithTrace(ctx, trace), req)
+
+	file := t.newTraceFile(traceFixType, trace, started, err)
+	file.ViolationID = req.Violation.ID
+	file.IncidentURI = req.Incident.URI
+	file.LineNumber = req.Incident.LineNumber
+	if resp != nil {
+		file.Success = resp.Success
+		file.Confidence = resp.Confidence
+		file.TokensUsed = resp.TokensUsed
+		file.Cost = resp.Cost
+		if resp.Provider != "" {
+			file.Provider = resp.Provider
+		}
+		if file.Error == "" && resp.Error != nil {
+			file.Error = t.redactor.Redact(resp.Error.Error())
+		}
+	}
+	name := fmt.Sprintf("%s-%s-L%d", req.Violation.ID, filepath.Base(req.Incident.GetFilePath()), req.Incident.LineNumber)
+	t.write(file, name)
+	return resp, err
+}
+
+// FixBatch asks the wrapped provider for a batch of fixes and traces the request
+func (t *TracingProvider) FixBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	trace := &common.Trace{}
+	started := time.Now()
+	resp, err := t.Provider.FixBatch(common.WithTrace(ctx, trace), req)
+
+	file := t.newTraceFile(traceBatchType, trace, started, err)
+	file.ViolationID = req.Violation.ID
+	if resp != nil {
+		file.Success = resp.Success
+		file.TokensUsed = resp.TokensUsed
+		file.Cost = resp.Cost
+		if file.Error == "" && resp.Error != nil {
+			file.Error = t.redactor.Redact(resp.Error.Error())
+		}
+		lines := make(map[string]int, len(req.Incidents))
+		for _, incident := range req.Incidents {
+			lines[incident.URI] = incident.LineNumber
+		}
+		for _, fix := range resp.Fixes {
+			traced := tracedIncidentFix{
+				IncidentURI: fix.IncidentURI,
+				LineNumber:  lines[fix.IncidentURI],
+				Success:     fix.Success,
+				Confidence:  fix.Confidence,
+			}
+			if fix.Error != nil {
+				traced.Error = t.redactor.Redact(fix.Error.Error())
+			}
+			file.Fixes = append(file.Fixes, traced)
+		}
+	}
+	t.write(file, req.Violation.ID)
+	return resp, err
+}
+
+// GeneratePlan asks the wrapped provider for a plan and traces the request
+func (t *TracingProvider) GeneratePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	trace := &common.Trace{}
+	started := time.Now()
+	resp, err := t.Provider.GeneratePlan(common.WithTrace(ctx, trace), req)
+
+	file := t.newTraceFile(tracePlanType, trace, started, err)
+	if resp != nil {
+		file.Success = resp.Error == nil
+		file.TokensUsed = resp.TokensUsed
+		file.Cost = resp.Cost
+		if file.Error == "" && resp.Error != nil {
+			file.Error = t.redactor.Redact(resp.Error.Error())
+		}
+	}
+	t.write(file, "")
+	return resp, err
+}
+
+// newTraceFile returns a trace file with the request's timing, error and
+// redacted exchanges
+func (t *TracingProvider) newTraceFile(traceType string, trace *common.Trace, started time.Time, err error) traceFile {
+	file := traceFile{
+		Type:       traceType,
+		Provider:   t.Provider.Name(),
+		Started:    started,
+		DurationMS: time.Since(started).Milliseconds(),
+		Exchanges:  []tracedExchange{},
+	}
+	if err != nil {
+		file.Error = t.redactor.Redact(err.Error())
+	}
+	for _, exchange := range trace.Exchanges() {
+		traced := tracedExchange{
+			URL:        exchange.URL,
+			Status:     exchange.Status,
+			DurationMS: exchange.Duration.Milliseconds(),
+			Request:    t.body(exchange.Request),
+			Response:   t.body(exchange.Response),
+			Error:      t.redactor.Redact(exchange.Error),
+		}
+		if exchange.Response == "" {
+			traced.Response = nil
+		}
+		file.Exchanges = append(file.Exchanges, traced)
+	}
+	return file
+}
+
+// body redacts an HTTP body, keeping it as JSON if it is JSON
+func (t *TracingProvider) body(content string) interface{} {
+	content = t.redactor.Redact(content)
+	if json.Valid([]byte(content)) {
+		return json.RawMessage(content)
+	}
+	return content
+}
+
+// write writes file to the trace directory. Names are numbered in request
+// order, so a directory listing follows the run. Failures are logged: a
+// trace that can't be written doesn't fail the request it traces, whose
+// response was already paid for.
+func (t *TracingProvider) write(file traceFile, name string) {
+	base := fmt.Sprintf("%04d-%s", t.seq.Add(1), file.Type)
+	if name != "" {
+		base += "-" + traceNameRegex.ReplaceAllString(name, "_")
+	}
+	path := filepath.Join(t.dir, base+".json")
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		slog.Warn("failed to write trace", "path", path, "error", err)
+	}
+}
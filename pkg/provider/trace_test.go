@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/provider/common"
+	"github.com/tsanders/kantra-ai/pkg/redact"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+// apiProvider is a countingProvider that sends the fix prompt to an API
+// first, as the built-in providers do
+type apiProvider struct {
+	*countingProvider
+	url string
+}
+
+func (p apiProvider) FixViolation(ctx context.Context, req FixRequest) (*FixResponse, error) {
+	prompt := `{"prompt":"Fix ` + req.Violation.ID + `","api_key":"sk-abcdefghijklmnopqrstuvwxyz"}`
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(prompt))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := common.NewHeaderClient(map[string]string{"Authorization": "Bearer secret-token-1234"}).Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.ReadAll(resp.Body)
+	return p.countingProvider.FixViolation(ctx, req)
+}
+
+// readTrace decodes the only trace file in dir
+func readTrace(t *testing.T, dir string) (string, map[string]interface{}) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	var trace map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &trace))
+	return entries[0].Name(), trace
+}
+
+func TestTracingProvider_FixViolation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"content":"{\"fixed_content\":\"import jakarta.servlet.*;\",\"confidence\":0.9}"}`))
+	}))
+	defer server.Close()
+
+	redactor, err := redact.New(redact.Config{})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	tracing, err := NewTracingProvider(apiProvider{&countingProvider{success: true}, server.URL}, dir, redactor)
+	require.NoError(t, err)
+
+	req := FixRequest{
+		Violation: violation.Violation{ID: "javax-to-jakarta/servlet"},
+		Incident:  violation.Incident{URI: "file:///src/main/java/App.java", LineNumber: 12},
+	}
+	resp, err := tracing.FixViolation(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	name, trace := readTrace(t, dir)
+	assert.Equal(t, "0001-fix-javax-to-jakarta_servlet-App.java-L12.json", name)
+	assert.Equal(t, "fix", trace["type"])
+	assert.Equal(t, "counting", trace["provider"])
+	assert.Equal(t, "javax-to-jakarta/servlet", trace["violation_id"])
+	assert.Equal(t, "file:///src/main/java/App.java", trace["incident_uri"])
+	assert.Equal(t, 0.9, trace["confidence"])
+	assert.Contains(t, trace, "duration_ms")
+
+	exchanges := trace["exchanges"].([]interface{})
+	require.Len(t, exchanges, 1)
+	exchange := exchanges[0].(map[string]interface{})
+	assert.Equal(t, server.URL, exchange["url"])
+	assert.Equal(t, float64(200), exchange["status"])
+	request := exchange["request"].(map[string]interface{})
+	assert.Equal(t, "Fix javax-to-jakarta/servlet", request["prompt"], "the request is embedded as JSON")
+	assert.Equal(t, redact.Placeholder, request["api_key"], "API keys are redacted")
+	response := exchange["response"].(map[string]interface{})
+	assert.Contains(t, response["content"], "jakarta.servlet", "the raw response")
+
+	data, err := json.Marshal(trace)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token", "headers aren't traced")
+}
+
+func TestTracingProvider_CachedAndBatch(t *testing.T) {
+	dir := t.TempDir()
+	tracing, err := NewTracingProvider(&countingProvider{success: false}, dir, nil)
+	require.NoError(t, err)
+
+	req := BatchRequest{
+		Violation: violation.Violation{ID: "v1"},
+		Incidents: []violation.Incident{{URI: "file:///src/A.java", LineNumber: 3}},
+	}
+	_, err = tracing.FixBatch(context.Background(), req)
+	require.NoError(t, err)
+
+	name, trace := readTrace(t, dir)
+	assert.Equal(t, "0001-batch-v1.json", name)
+	assert.Equal(t, []interface{}{}, trace["exchanges"], "no request was sent")
+	fixes := trace["fixes"].([]interface{})
+	require.Len(t, fixes, 1)
+	fix := fixes[0].(map[string]interface{})
+	assert.Equal(t, "file:///src/A.java", fix["incident_uri"])
+	assert.Equal(t, float64(3), fix["line_number"])
+	assert.Equal(t, "model refused", fix["error"])
+}
+
+func TestTracingProvider_WriteFailureKeepsResponse(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	// The trace directory disappears during the run
+	dir := filepath.Join(t.TempDir(), "traces")
+	tracing, err := NewTracingProvider(&countingProvider{success: true}, dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(dir))
+
+	resp, err := tracing.FixViolation(context.Background(), FixRequest{
+		Violation:   violation.Violation{ID: "v1"},
+		Incident:    violation.Incident{URI: "file:///src/A.java", LineNumber: 3},
+		FileContent: "import javax.servlet.*;",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "fixed import javax.servlet.*;", resp.FixedContent)
+
+	batch, err := tracing.FixBatch(context.Background(), BatchRequest{
+		Violation: violation.Violation{ID: "v1"},
+		Incidents: []violation.Incident{{URI: "file:///src/A.java", LineNumber: 3}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, batch)
+	assert.Len(t, batch.Fixes, 1)
+
+	assert.Contains(t, logs.String(), `msg="failed to write trace"`)
+}
@@ -0,0 +1,108 @@
+// Package redact scrubs secrets and source code from provider request/response
+// bodies before they are written to audit logs or dump files.
+//
+// Two mechanisms are supported:
+//
+//   - Regex patterns: every match is replaced with a fixed placeholder. A set of
+//     default patterns covers common credentials (API keys, tokens, passwords).
+//   - Code hashing: fenced code blocks and code-bearing JSON fields
+//     (fixed_content, patch, file_content) are replaced with a SHA-256 digest so
+//     that entries can still be correlated without storing the code itself.
+//
+// Redaction only touches bodies; metadata such as provider, model, violation ID,
+// token counts and cost is always preserved.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// Placeholder replaces text matched by a redaction pattern
+	Placeholder = "[REDACTED]"
+)
+
+// DefaultPatterns match common credentials that may appear in source files or prompts
+var DefaultPatterns = []string{
+	`sk-ant-[A-Za-z0-9_\-]{10,}`,        // Anthropic API keys
+	`sk-[A-Za-z0-9_\-]{20,}`,            // OpenAI-style API keys
+	`gh[pousr]_[A-Za-z0-9]{20,}`,        // GitHub tokens
+	`AKIA[0-9A-Z]{16}`,                  // AWS access key IDs
+	`AIza[0-9A-Za-z_\-]{35}`,            // Google API keys
+	`(?i)bearer\s+[A-Za-z0-9._\-]{10,}`, // Bearer tokens
+	`(?i)(?:password|passwd|secret|api[_-]?key)\s*[:=]\s*["']?[^\s"',;]+`, // key=value secrets
+}
+
+var (
+	// codeBlockRegex matches fenced markdown code blocks (including the fences)
+	codeBlockRegex = regexp.MustCompile("(?s)```[^\\n]*\\n.*?```")
+
+	// codeFieldRegex matches JSON string fields that carry source code
+	codeFieldRegex = regexp.MustCompile(`("(?:fixed_content|patch|file_content)"\s*:\s*)"((?:\\.|[^"\\])*)"`)
+)
+
+// Config configures redaction
+type Config struct {
+	Patterns               []string // Additional regex patterns to redact
+	HashCode               bool     // Replace code bodies with a SHA-256 digest
+	DisableDefaultPatterns bool     // Don't apply DefaultPatterns
+}
+
+// Redactor applies redaction rules to provider bodies
+type Redactor struct {
+	patterns []*regexp.Regexp
+	hashCode bool
+}
+
+// New compiles the configured patterns into a Redactor
+func New(config Config) (*Redactor, error) {
+	var sources []string
+	if !config.DisableDefaultPatterns {
+		sources = append(sources, DefaultPatterns...)
+	}
+	sources = append(sources, config.Patterns...)
+
+	patterns := make([]*regexp.Regexp, 0, len(sources))
+	for _, src := range sources {
+		re, err := regexp.Compile(src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", src, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return &Redactor{
+		patterns: patterns,
+		hashCode: config.HashCode,
+	}, nil
+}
+
+// Redact returns body with code hashed (if enabled) and all pattern matches replaced
+func (r *Redactor) Redact(body string) string {
+	if r == nil {
+		return body
+	}
+
+	if r.hashCode {
+		body = codeBlockRegex.ReplaceAllStringFunc(body, HashCode)
+		body = codeFieldRegex.ReplaceAllStringFunc(body, func(field string) string {
+			parts := codeFieldRegex.FindStringSubmatch(field)
+			return parts[1] + `"` + HashCode(parts[2]) + `"`
+		})
+	}
+
+	for _, re := range r.patterns {
+		body = re.ReplaceAllString(body, Placeholder)
+	}
+
+	return body
+}
+
+// HashCode replaces code with a short, stable digest that still allows correlation
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return fmt.Sprintf("[code sha256:%s len:%d]", hex.EncodeToString(sum[:])[:16], len(code))
+}
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+// ConsolidatedReport combines the state files of several execute runs, e.g.
+// over a migration spanning weeks, into one view
+type ConsolidatedReport struct {
+	Runs []RunSummary `json:"runs"` // Timeline, oldest run first
+
+	ViolationsResolved    int     `json:"violations_resolved"`    // All incidents fixed
+	ViolationsOutstanding int     `json:"violations_outstanding"` // Attempted, with incidents not fixed yet
+	IncidentsFixed        int     `json:"incidents_fixed"`
+	IncidentsFailed       int     `json:"incidents_failed"` // Incidents whose latest attempt failed
+	TotalCost             float64 `json:"total_cost"`
+	TotalTokens           int     `json:"total_tokens"`
+}
+
+// RunSummary is one state file's contribution to a ConsolidatedReport
+type RunSummary struct {
+	StateFile       string    `json:"state_file"`
+	PlanFile        string    `json:"plan_file"`
+	StartedAt       time.Time `json:"started_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	CompletedPhases int       `json:"completed_phases"`
+	TotalPhases     int       `json:"total_phases"`
+	IncidentsFixed  int       `json:"incidents_fixed"`
+	IncidentsFailed int       `json:"incidents_failed"`
+	Cost            float64   `json:"cost"`
+	Tokens          int       `json:"tokens"`
+}
+
+// Consolidate merges execution states, keyed by the path they were loaded
+// from. Costs and tokens add up across runs. An incident recorded by several
+// runs counts once, with the outcome of its latest attempt, so fixing in a
+// later run what an earlier one failed on resolves the violation.
+func Consolidate(states map[string]*planfile.ExecutionState) *ConsolidatedReport {
+	report := &ConsolidatedReport{Runs: make([]RunSummary, 0, len(states))}
+	incidents := make(map[string]map[string]planfile.IncidentStatus)
+	completed := make(map[string]bool) // Violations marked completed without incidents
+
+	for path, state := range states {
+		if state == nil {
+			continue
+		}
+		run := RunSummary{
+			StateFile:       path,
+			PlanFile:        state.PlanFile,
+			StartedAt:       state.StartedAt,
+			UpdatedAt:       state.UpdatedAt,
+			CompletedPhases: state.ExecutionSummary.CompletedPhases,
+			TotalPhases:     state.ExecutionSummary.TotalPhases,
+		}
+		for _, phase := range state.Phases {
+			run.Cost += phase.Cost
+			run.Tokens += phase.Tokens
+		}
+		if run.Cost == 0 {
+			// States without per-phase costs only record the fixes' cost
+			run.Cost = state.ExecutionSummary.TotalCost
+		}
+
+		for violationID, vs := range state.Violations {
+			if len(vs.Incidents) == 0 {
+				if vs.Status == planfile.StatusCompleted {
+					completed[violationID] = true
+				}
+				continue
+			}
+			merged, ok := incidents[violationID]
+			if !ok {
+				merged = make(map[string]planfile.IncidentStatus)
+				incidents[violationID] = merged
+			}
+			for key, incident := range vs.Incidents {
+				switch incident.Status {
+				case planfile.StatusCompleted:
+					run.IncidentsFixed++
+				case planfile.StatusFailed, planfile.StatusPermanentlyFailed:
+					run.IncidentsFailed++
+				}
+				if previous, seen := merged[key]; !seen || incident.Timestamp.After(previous.Timestamp) {
+					merged[key] = incident
+				}
+			}
+		}
+
+		report.Runs = append(report.Runs, run)
+		report.TotalCost += run.Cost
+		report.TotalTokens += run.Tokens
+	}
+
+	sort.Slice(report.Runs, func(i, j int) bool {
+		a, b := report.Runs[i], report.Runs[j]
+		if !a.StartedAt.Equal(b.StartedAt) {
+			return a.StartedAt.Before(b.StartedAt)
+		}
+		return a.StateFile < b.StateFile
+	})
+
+	for violationID, merged := range incidents {
+		resolved := true
+		for _, incident := range merged {
+			switch incident.Status {
+			case planfile.StatusCompleted:
+				report.IncidentsFixed++
+			case planfile.StatusFailed, planfile.StatusPermanentlyFailed:
+				report.IncidentsFailed++
+				resolved = false
+			default:
+				resolved = false
+			}
+		}
+		if resolved {
+			report.ViolationsResolved++
+		} else {
+			report.ViolationsOutstanding++
+		}
+		delete(completed, violationID)
+	}
+	report.ViolationsResolved += len(completed)
+
+	return report
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *ConsolidatedReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report's totals and timeline for the terminal
+func (r *ConsolidatedReport) WriteText(w io.Writer) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	printf("Runs:                   %d\n", len(r.Runs))
+	printf("Violations resolved:    %d\n", r.ViolationsResolved)
+	printf("Violations outstanding: %d\n", r.ViolationsOutstanding)
+	printf("Incidents fixed:        %d\n", r.IncidentsFixed)
+	printf("Incidents failed:       %d\n", r.IncidentsFailed)
+	printf("Total cost:             $%.4f\n", r.TotalCost)
+	printf("Total tokens:           %d\n", r.TotalTokens)
+
+	if len(r.Runs) > 0 {
+		printf("\nTimeline:\n")
+		for _, run := range r.Runs {
+			printf("  %s  %-32s phases %d/%d, %d fixed, %d failed, $%.4f, %d tokens\n",
+				run.StartedAt.Format("2006-01-02 15:04"), run.StateFile,
+				run.CompletedPhases, run.TotalPhases, run.IncidentsFixed, run.IncidentsFailed, run.Cost, run.Tokens)
+		}
+	}
+	return err
+}
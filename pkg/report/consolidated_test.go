@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+func TestConsolidate(t *testing.T) {
+	week1 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+
+	first := &planfile.ExecutionState{
+		PlanFile:         "plan.yaml",
+		StartedAt:        week1,
+		UpdatedAt:        week1.Add(time.Hour),
+		ExecutionSummary: planfile.ExecutionSummary{TotalPhases: 2, CompletedPhases: 1, TotalCost: 0.30},
+		Phases: []planfile.PhaseStatus{
+			{PhaseID: "phase-1", Status: planfile.StatusCompleted, Cost: 0.30, Tokens: 3000},
+			{PhaseID: "phase-2", Status: planfile.StatusFailed, Cost: 0.05, Tokens: 500},
+		},
+		Violations: map[string]planfile.ViolationStatus{
+			"javax-to-jakarta": {
+				Status: planfile.StatusCompleted,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///src/A.java:3": {Status: planfile.StatusCompleted, Cost: 0.10, Timestamp: week1},
+					"file:///src/B.java:5": {Status: planfile.StatusCompleted, Cost: 0.20, Timestamp: week1},
+				},
+			},
+			"log4j-update": {
+				Status: planfile.StatusFailed,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///src/C.java:7": {Status: planfile.StatusFailed, Timestamp: week1},
+				},
+			},
+		},
+	}
+	second := &planfile.ExecutionState{
+		PlanFile:         "plan.yaml",
+		StartedAt:        week2,
+		UpdatedAt:        week2.Add(time.Hour),
+		ExecutionSummary: planfile.ExecutionSummary{TotalPhases: 2, CompletedPhases: 2, TotalCost: 0.25},
+		Phases: []planfile.PhaseStatus{
+			{PhaseID: "phase-2", Status: planfile.StatusCompleted, Cost: 0.15, Tokens: 1500},
+			{PhaseID: "phase-3", Status: planfile.StatusCompleted, Cost: 0.10, Tokens: 1000},
+		},
+		Violations: map[string]planfile.ViolationStatus{
+			// Fixed this week after failing last week
+			"log4j-update": {
+				Status: planfile.StatusCompleted,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///src/C.java:7": {Status: planfile.StatusCompleted, Cost: 0.15, Timestamp: week2},
+				},
+			},
+			"spring-boot-3": {
+				Status: planfile.StatusInProgress,
+				Incidents: map[string]planfile.IncidentStatus{
+					"file:///src/D.java:1": {Status: planfile.StatusCompleted, Cost: 0.10, Timestamp: week2},
+					"file:///src/E.java:2": {Status: planfile.StatusPermanentlyFailed, Timestamp: week2},
+				},
+			},
+		},
+	}
+
+	report := Consolidate(map[string]*planfile.ExecutionState{
+		"week2.yaml": second,
+		"week1.yaml": first,
+	})
+
+	require.Len(t, report.Runs, 2)
+	assert.Equal(t, "week1.yaml", report.Runs[0].StateFile, "runs are ordered by start time")
+	assert.Equal(t, "week2.yaml", report.Runs[1].StateFile)
+	assert.Equal(t, 2, report.Runs[0].IncidentsFixed)
+	assert.Equal(t, 1, report.Runs[0].IncidentsFailed)
+	assert.InDelta(t, 0.35, report.Runs[0].Cost, 1e-9)
+	assert.Equal(t, 3500, report.Runs[0].Tokens)
+
+	assert.Equal(t, 2, report.ViolationsResolved)
+	assert.Equal(t, 1, report.ViolationsOutstanding)
+	assert.Equal(t, 4, report.IncidentsFixed)
+	assert.Equal(t, 1, report.IncidentsFailed, "an incident fixed in a later run no longer counts as failed")
+	assert.InDelta(t, 0.60, report.TotalCost, 1e-9)
+	assert.Equal(t, 6000, report.TotalTokens)
+}
+
+func TestConsolidate_CostWithoutPhases(t *testing.T) {
+	report := Consolidate(map[string]*planfile.ExecutionState{
+		"state.yaml": {ExecutionSummary: planfile.ExecutionSummary{TotalCost: 0.42}},
+	})
+	assert.InDelta(t, 0.42, report.TotalCost, 1e-9)
+}
+
+func TestConsolidatedReport_Write(t *testing.T) {
+	report := Consolidate(map[string]*planfile.ExecutionState{
+		"week1.yaml": {
+			StartedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+			Phases:    []planfile.PhaseStatus{{PhaseID: "phase-1", Cost: 0.5, Tokens: 100}},
+		},
+	})
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "Total cost:             $0.5000")
+	assert.Contains(t, text.String(), "2026-03-02 09:00  week1.yaml")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 100.0, decoded["total_tokens"])
+	assert.Len(t, decoded["runs"], 1)
+}
@@ -4,6 +4,7 @@ package report
 import (
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,27 +19,48 @@ func GenerateHTML(plan *planfile.Plan, planPath string) (string, error) {
 	dir := filepath.Dir(planPath)
 	htmlPath := filepath.Join(dir, "plan.html")
 
+	if err := WriteHTML(plan, nil, htmlPath); err != nil {
+		return "", err
+	}
+
+	return htmlPath, nil
+}
+
+// WriteHTML renders the HTML report for a migration plan to outputPath.
+// If state is non-nil, actual execution results are overlaid on the report.
+func WriteHTML(plan *planfile.Plan, state *planfile.ExecutionState, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
 	// Create HTML file
-	f, err := os.Create(htmlPath)
+	f, err := os.Create(outputPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTML file: %w", err)
+		return fmt.Errorf("failed to create HTML file: %w", err)
 	}
 	defer f.Close()
 
+	return RenderHTML(f, plan, state)
+}
+
+// RenderHTML renders the HTML report for a migration plan to w.
+// If state is non-nil, actual execution results are overlaid on the report.
+func RenderHTML(w io.Writer, plan *planfile.Plan, state *planfile.ExecutionState) error {
 	// Prepare template data
 	data := prepareTemplateData(plan)
+	data.applyState(state)
 
 	// Execute template
 	tmpl, err := template.New("plan").Funcs(templateFuncs()).Parse(htmlTemplate)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	if err := tmpl.Execute(f, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return htmlPath, nil
+	return nil
 }
 
 // TemplateData holds all data needed for the HTML template
@@ -50,6 +72,12 @@ type TemplateData struct {
 	CategoryCounts  map[string]int
 	RiskCounts      map[string]int
 	EffortDistribution map[int]int
+
+	// Execution results, set only when a state file is overlaid
+	HasState        bool
+	ActualCost      float64
+	CompletedPhases int
+	PhaseResults    map[string]*planfile.PhaseStatus
 }
 
 // prepareTemplateData extracts summary statistics from the plan
@@ -76,6 +104,24 @@ func prepareTemplateData(plan *planfile.Plan) *TemplateData {
 	return data
 }
 
+// applyState overlays execution results from state onto the template data
+func (d *TemplateData) applyState(state *planfile.ExecutionState) {
+	if state == nil {
+		return
+	}
+
+	d.HasState = true
+	d.PhaseResults = make(map[string]*planfile.PhaseStatus)
+	for i := range state.Phases {
+		result := &state.Phases[i]
+		d.PhaseResults[result.PhaseID] = result
+		d.ActualCost += result.Cost
+		if result.Status == planfile.StatusCompleted {
+			d.CompletedPhases++
+		}
+	}
+}
+
 // templateFuncs returns custom template functions
 func templateFuncs() template.FuncMap {
 	return template.FuncMap{
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+func testPlan() *planfile.Plan {
+	return &planfile.Plan{
+		Version:  "1.0",
+		Metadata: planfile.PlanMetadata{CreatedAt: time.Now(), Provider: "claude", TotalViolations: 2},
+		Phases: []planfile.Phase{
+			{
+				ID:            "phase-1",
+				Name:          "Critical fixes",
+				Order:         1,
+				Risk:          planfile.RiskLow,
+				Category:      "mandatory",
+				EstimatedCost: 1.50,
+				Violations: []planfile.PlannedViolation{
+					{ViolationID: "javax-to-jakarta", Category: "mandatory", Effort: 1, IncidentCount: 3},
+				},
+			},
+			{
+				ID:            "phase-2",
+				Name:          "Cleanup",
+				Order:         2,
+				Risk:          planfile.RiskMedium,
+				Category:      "optional",
+				EstimatedCost: 0.75,
+				Violations: []planfile.PlannedViolation{
+					{ViolationID: "log4j-update", Category: "optional", Effort: 3, IncidentCount: 2},
+				},
+			},
+		},
+	}
+}
+
+func TestPrepareTemplateData(t *testing.T) {
+	data := prepareTemplateData(testPlan())
+
+	assert.Equal(t, 5, data.TotalIncidents)
+	assert.InDelta(t, 2.25, data.TotalCost, 0.0001)
+	assert.Equal(t, 1, data.CategoryCounts["mandatory"])
+	assert.Equal(t, 1, data.RiskCounts["medium"])
+	assert.False(t, data.HasState)
+}
+
+func TestRenderHTML_WithoutState(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderHTML(&buf, testPlan(), nil))
+
+	out := buf.String()
+	assert.Contains(t, out, "Critical fixes")
+	assert.Contains(t, out, "$2.25")
+	assert.NotContains(t, out, "Actual Cost")
+}
+
+func TestRenderHTML_WithState(t *testing.T) {
+	state := &planfile.ExecutionState{
+		Phases: []planfile.PhaseStatus{
+			{PhaseID: "phase-1", Status: planfile.StatusCompleted, FixesApplied: 3, Cost: 1.20},
+			{PhaseID: "phase-2", Status: planfile.StatusFailed, FixesApplied: 1, Cost: 0.40},
+		},
+	}
+
+	data := prepareTemplateData(testPlan())
+	data.applyState(state)
+	assert.True(t, data.HasState)
+	assert.Equal(t, 1, data.CompletedPhases)
+	assert.InDelta(t, 1.60, data.ActualCost, 0.0001)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderHTML(&buf, testPlan(), state))
+
+	out := buf.String()
+	assert.Contains(t, out, "Actual Cost")
+	assert.Contains(t, out, "$1.60")
+	assert.Contains(t, out, "1 / 2")
+	assert.Contains(t, out, `<span class="status-badge completed">completed</span>`)
+	assert.Contains(t, out, `<span class="status-badge failed">failed</span>`)
+	assert.Contains(t, out, "Actual: $1.20")
+}
+
+func TestWriteHTML(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	require.NoError(t, WriteHTML(testPlan(), nil, outputPath))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Cleanup")
+}
+
+func TestWriteHTML_CreatesReportDirectory(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), ".kantra-ai", "reports", "plan.html")
+	require.NoError(t, WriteHTML(testPlan(), nil, outputPath))
+	assert.FileExists(t, outputPath)
+}
@@ -0,0 +1,237 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+// maxResultsDiffLines caps the diff shown for a single violation
+const maxResultsDiffLines = 500
+
+// Diff sources shown in the results report
+const (
+	DiffSourceCommitted   = "committed"
+	DiffSourceUncommitted = "uncommitted"
+)
+
+// ResultsData holds all data needed for the post-execution results template
+type ResultsData struct {
+	Plan   *planfile.Plan
+	State  *planfile.ExecutionState
+	Phases []PhaseResult
+
+	EstimatedCost    float64
+	ActualCost       float64
+	CompletedPhases  int
+	FixedViolations  int
+	FailedViolations int
+	VerifiedPassed   int
+	VerifiedFailed   int
+}
+
+// PhaseResult is a plan phase with the execution results of its violations
+type PhaseResult struct {
+	Phase      planfile.Phase
+	Status     planfile.StatusType
+	Cost       float64
+	Violations []ViolationResult
+}
+
+// ViolationResult holds the execution results and applied diff for one violation
+type ViolationResult struct {
+	ViolationID     string
+	Description     string
+	Category        string
+	Status          planfile.StatusType
+	Verification    planfile.VerificationStatus
+	Incidents       int
+	FixedIncidents  int
+	FailedIncidents int
+	Cost            float64
+	Commits         []string
+	Files           []string
+	Diff            string
+	DiffSource      string // DiffSourceCommitted or DiffSourceUncommitted, empty if no diff
+	DiffError       string
+}
+
+// BuildResults combines a plan with its execution state. If repoDir is a git
+// repository, the applied diff of each violation is read from the commits
+// recorded in the state, or from the working tree if nothing was committed.
+func BuildResults(plan *planfile.Plan, state *planfile.ExecutionState, repoDir string) *ResultsData {
+	data := &ResultsData{
+		Plan:       plan,
+		State:      state,
+		ActualCost: state.ExecutionSummary.TotalCost,
+	}
+
+	useGit := repoDir != "" && gitutil.IsGitRepository(repoDir)
+
+	for _, phase := range plan.Phases {
+		phaseResult := PhaseResult{
+			Phase:  phase,
+			Status: planfile.StatusPending,
+		}
+		if status := state.GetPhaseStatus(phase.ID); status != nil {
+			phaseResult.Status = status.Status
+			phaseResult.Cost = status.Cost
+		}
+		if phaseResult.Status == planfile.StatusCompleted {
+			data.CompletedPhases++
+		}
+		data.EstimatedCost += phase.EstimatedCost
+
+		for _, planned := range phase.Violations {
+			result := ViolationResult{
+				ViolationID: planned.ViolationID,
+				Description: planned.Description,
+				Category:    planned.Category,
+				Status:      planfile.StatusPending,
+				Incidents:   planned.IncidentCount,
+			}
+
+			if status, ok := state.Violations[planned.ViolationID]; ok {
+				result.Status = status.Status
+				result.Verification = status.Verification
+				result.Commits = status.Commits
+				result.Files = status.Files
+				for _, incident := range status.Incidents {
+					result.Cost += incident.Cost
+					switch incident.Status {
+					case planfile.StatusCompleted:
+						result.FixedIncidents++
+					case planfile.StatusFailed, planfile.StatusPermanentlyFailed:
+						result.FailedIncidents++
+					}
+				}
+			}
+
+			switch result.Status {
+			case planfile.StatusCompleted:
+				data.FixedViolations++
+			case planfile.StatusFailed:
+				data.FailedViolations++
+			}
+			switch result.Verification {
+			case planfile.VerificationPassed:
+				data.VerifiedPassed++
+			case planfile.VerificationFailed:
+				data.VerifiedFailed++
+			}
+
+			if useGit {
+				result.loadDiff(repoDir)
+			}
+
+			phaseResult.Violations = append(phaseResult.Violations, result)
+		}
+
+		data.Phases = append(data.Phases, phaseResult)
+	}
+
+	return data
+}
+
+// loadDiff reads the violation's applied diff from git
+func (r *ViolationResult) loadDiff(repoDir string) {
+	if len(r.Files) == 0 {
+		return
+	}
+
+	var diff strings.Builder
+	source := DiffSourceCommitted
+	if len(r.Commits) > 0 {
+		for _, sha := range r.Commits {
+			commitDiff, err := gitutil.CommitDiff(repoDir, sha, r.Files)
+			if err != nil {
+				r.DiffError = fmt.Sprintf("commit %s: %v", sha, err)
+				return
+			}
+			diff.WriteString(commitDiff)
+		}
+	} else {
+		workingDiff, err := gitutil.WorkingTreeDiff(repoDir, r.Files)
+		if err != nil {
+			r.DiffError = err.Error()
+			return
+		}
+		diff.WriteString(workingDiff)
+		source = DiffSourceUncommitted
+	}
+
+	if diff.Len() == 0 {
+		return
+	}
+	r.Diff = fixer.TruncateDiff(diff.String(), maxResultsDiffLines)
+	r.DiffSource = source
+}
+
+// WriteResultsHTML renders the post-execution results report to outputPath
+func WriteResultsHTML(data *ResultsData, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML file: %w", err)
+	}
+	defer f.Close()
+
+	return RenderResultsHTML(f, data)
+}
+
+// RenderResultsHTML renders the post-execution results report to w
+func RenderResultsHTML(w io.Writer, data *ResultsData) error {
+	tmpl, err := template.New("results").Funcs(resultsTemplateFuncs()).Parse(resultsTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// resultsTemplateFuncs returns the template functions for the results report
+func resultsTemplateFuncs() template.FuncMap {
+	funcs := templateFuncs()
+	funcs["diffLines"] = func(diff string) []string {
+		return strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	}
+	funcs["diffLineClass"] = diffLineClass
+	funcs["shortSHA"] = func(sha string) string {
+		if len(sha) > 8 {
+			return sha[:8]
+		}
+		return sha
+	}
+	return funcs
+}
+
+// diffLineClass returns the CSS class for a line of a unified diff
+func diffLineClass(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+		strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		return "meta"
+	case strings.HasPrefix(line, "@@"):
+		return "hunk"
+	case strings.HasPrefix(line, "+"):
+		return "add"
+	case strings.HasPrefix(line, "-"):
+		return "del"
+	default:
+		return ""
+	}
+}
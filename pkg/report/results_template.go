@@ -0,0 +1,299 @@
+package report
+
+const resultsTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>kantra-ai Migration Results</title>
+    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.5.1/css/all.min.css">
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
+            background-color: #f5f5f5;
+            color: #333;
+            line-height: 1.6;
+        }
+
+        #app {
+            max-width: 1200px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+
+        header {
+            background: white;
+            padding: 20px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+
+        header h1 {
+            font-size: 24px;
+            color: #2c3e50;
+        }
+
+        header p {
+            font-size: 14px;
+            color: #7f8c8d;
+        }
+
+        .dashboard {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(180px, 1fr));
+            gap: 15px;
+            margin-bottom: 20px;
+        }
+
+        .metric-card {
+            background: white;
+            padding: 20px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            text-align: center;
+        }
+
+        .metric-value {
+            font-size: 28px;
+            font-weight: bold;
+            color: #2c3e50;
+            margin-bottom: 5px;
+        }
+
+        .metric-label {
+            font-size: 14px;
+            color: #7f8c8d;
+        }
+
+        .phase {
+            background: white;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 15px;
+            overflow: hidden;
+        }
+
+        .phase-header {
+            padding: 20px;
+            border-bottom: 1px solid #ecf0f1;
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+        }
+
+        .phase-header h3 {
+            font-size: 18px;
+            color: #2c3e50;
+        }
+
+        .phase-cost {
+            font-size: 14px;
+            color: #7f8c8d;
+        }
+
+        .violation {
+            padding: 15px 20px;
+            border-bottom: 1px solid #ecf0f1;
+        }
+
+        .violation:last-child {
+            border-bottom: none;
+        }
+
+        .violation-header {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            gap: 10px;
+        }
+
+        .violation-description {
+            color: #555;
+            margin: 5px 0;
+        }
+
+        .violation-meta {
+            font-size: 13px;
+            color: #7f8c8d;
+        }
+
+        .badges {
+            display: flex;
+            gap: 6px;
+        }
+
+        .status-badge {
+            padding: 3px 10px;
+            border-radius: 4px;
+            font-size: 12px;
+            font-weight: 600;
+            text-transform: uppercase;
+            white-space: nowrap;
+        }
+
+        .status-badge.completed,
+        .status-badge.passed {
+            background-color: #d5f4e6;
+            color: #27ae60;
+        }
+
+        .status-badge.failed {
+            background-color: #fadbd8;
+            color: #c0392b;
+        }
+
+        .status-badge.pending,
+        .status-badge.in_progress,
+        .status-badge.unverified {
+            background-color: #e8f4f8;
+            color: #3498db;
+        }
+
+        details {
+            margin-top: 10px;
+        }
+
+        summary {
+            cursor: pointer;
+            font-size: 14px;
+            color: #2c3e50;
+        }
+
+        .diff {
+            margin-top: 8px;
+            background: #fafafa;
+            border: 1px solid #ecf0f1;
+            border-radius: 4px;
+            padding: 10px;
+            overflow-x: auto;
+            font-family: 'Monaco', 'Courier New', 'Consolas', monospace;
+            font-size: 12px;
+            line-height: 1.5;
+        }
+
+        .diff span {
+            display: block;
+            white-space: pre;
+        }
+
+        .diff .add {
+            background-color: #e6ffed;
+            color: #22863a;
+        }
+
+        .diff .del {
+            background-color: #ffeef0;
+            color: #cb2431;
+        }
+
+        .diff .hunk {
+            color: #6f42c1;
+        }
+
+        .diff .meta {
+            color: #7f8c8d;
+            font-weight: bold;
+        }
+
+        .notice {
+            font-size: 13px;
+            color: #7f8c8d;
+            font-style: italic;
+            margin-top: 8px;
+        }
+
+        .notice.error {
+            color: #c0392b;
+        }
+    </style>
+</head>
+<body>
+    <div id="app">
+        <header>
+            <h1><i class="fas fa-clipboard-check"></i> kantra-ai Migration Results</h1>
+            <p>Started: {{.State.StartedAt.Format "January 2, 2006 at 3:04 PM"}} &bull; Last updated: {{.State.UpdatedAt.Format "January 2, 2006 at 3:04 PM"}}</p>
+        </header>
+
+        <!-- Dashboard -->
+        <div class="dashboard">
+            <div class="metric-card">
+                <div class="metric-value">{{.CompletedPhases}} / {{len .Plan.Phases}}</div>
+                <div class="metric-label">Phases Completed</div>
+            </div>
+
+            <div class="metric-card">
+                <div class="metric-value">{{.FixedViolations}}</div>
+                <div class="metric-label">Violations Fixed</div>
+            </div>
+
+            <div class="metric-card">
+                <div class="metric-value">{{.FailedViolations}}</div>
+                <div class="metric-label">Violations Failed</div>
+            </div>
+
+            <div class="metric-card">
+                <div class="metric-value">{{.VerifiedPassed}} / {{.VerifiedFailed}}</div>
+                <div class="metric-label">Verification Passed / Failed</div>
+            </div>
+
+            <div class="metric-card">
+                <div class="metric-value">${{printf "%.2f" .ActualCost}}</div>
+                <div class="metric-label">Final Cost (estimated ${{printf "%.2f" .EstimatedCost}})</div>
+            </div>
+        </div>
+
+        <!-- Phases -->
+        {{range .Phases}}
+        <div class="phase" id="phase-{{.Phase.ID}}">
+            <div class="phase-header">
+                <h3>{{.Phase.Order}}. {{.Phase.Name}}</h3>
+                <div class="badges">
+                    <span class="phase-cost">${{printf "%.2f" .Cost}}</span>
+                    <span class="status-badge {{.Status}}">{{.Status}}</span>
+                </div>
+            </div>
+
+            {{range .Violations}}
+            <div class="violation" id="violation-{{.ViolationID}}">
+                <div class="violation-header">
+                    <strong>{{.ViolationID}}</strong>
+                    <div class="badges">
+                        <span class="status-badge {{.Status}}">{{.Status}}</span>
+                        {{if .Verification}}
+                        <span class="status-badge {{.Verification}}"><i class="fas fa-vial"></i> {{.Verification}}</span>
+                        {{else}}
+                        <span class="status-badge unverified">not verified</span>
+                        {{end}}
+                    </div>
+                </div>
+                <div class="violation-description">{{.Description}}</div>
+                <div class="violation-meta">
+                    {{.Category}} &bull; {{.FixedIncidents}}/{{.Incidents}} incidents fixed{{if .FailedIncidents}} &bull; {{.FailedIncidents}} failed{{end}} &bull; ${{printf "%.4f" .Cost}}
+                    {{if .Commits}}&bull; Commits: {{range $i, $sha := .Commits}}{{if $i}}, {{end}}<code>{{shortSHA $sha}}</code>{{end}}{{end}}
+                </div>
+
+                {{if .Diff}}
+                <details>
+                    <summary>Applied changes ({{len .Files}} file(s), {{.DiffSource}})</summary>
+                    <div class="diff">{{range diffLines .Diff}}<span class="{{diffLineClass .}}">{{.}}</span>{{end}}</div>
+                </details>
+                {{else if .DiffError}}
+                <div class="notice error">Could not read the applied changes from git: {{.DiffError}}</div>
+                {{else if .Files}}
+                <div class="notice">Changed files: {{range $i, $f := .Files}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}</div>
+                {{end}}
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+    </div>
+</body>
+</html>
+`
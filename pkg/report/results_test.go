@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/planfile"
+)
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return string(bytes.TrimSpace(output))
+}
+
+func testResultsState() *planfile.ExecutionState {
+	state := planfile.NewState(".kantra-ai-plan.yaml", 2)
+	state.StartedAt = time.Now()
+	state.UpdatedAt = time.Now()
+	state.MarkPhaseStarted("phase-1")
+	state.MarkPhaseCompleted("phase-1")
+	state.RecordIncidentFix("javax-to-jakarta", "file:///src/A.java:1", 0.05)
+	state.RecordIncidentFailure("phase-2", "log4j-update", "file:///src/B.java:2", "provider timeout")
+	return state
+}
+
+func TestBuildResults(t *testing.T) {
+	state := testResultsState()
+	state.RecordViolationChanges("javax-to-jakarta", []string{"src/A.java"}, nil)
+	state.RecordVerification("javax-to-jakarta", true)
+
+	data := BuildResults(testPlan(), state, "")
+
+	assert.Equal(t, 1, data.CompletedPhases)
+	assert.Equal(t, 1, data.FixedViolations)
+	assert.Equal(t, 1, data.FailedViolations)
+	assert.Equal(t, 1, data.VerifiedPassed)
+	assert.InDelta(t, 0.05, data.ActualCost, 0.0001)
+	assert.InDelta(t, 2.25, data.EstimatedCost, 0.0001)
+
+	require.Len(t, data.Phases, 2)
+	assert.Equal(t, planfile.StatusCompleted, data.Phases[0].Status)
+	fixed := data.Phases[0].Violations[0]
+	assert.Equal(t, 1, fixed.FixedIncidents)
+	assert.Equal(t, planfile.VerificationPassed, fixed.Verification)
+	assert.Empty(t, fixed.Diff, "no repository given")
+
+	failed := data.Phases[1].Violations[0]
+	assert.Equal(t, planfile.StatusFailed, failed.Status)
+	assert.Equal(t, 1, failed.FailedIncidents)
+}
+
+func TestBuildResults_CommittedDiff(t *testing.T) {
+	repoDir := t.TempDir()
+	runGit(t, repoDir, "init")
+	runGit(t, repoDir, "config", "user.name", "Test User")
+	runGit(t, repoDir, "config", "user.email", "test@example.com")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "src", "A.java"), []byte("import javax.ejb.Stateless;\n"), 0644))
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "src", "A.java"), []byte("import jakarta.ejb.Stateless;\n"), 0644))
+	runGit(t, repoDir, "commit", "-am", "fix(konveyor): javax-to-jakarta")
+	sha := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	state := testResultsState()
+	state.RecordViolationChanges("javax-to-jakarta", []string{"src/A.java"}, []string{sha})
+	state.RecordVerification("javax-to-jakarta", false)
+
+	data := BuildResults(testPlan(), state, repoDir)
+	fixed := data.Phases[0].Violations[0]
+	assert.Empty(t, fixed.DiffError)
+	assert.Equal(t, DiffSourceCommitted, fixed.DiffSource)
+	assert.Contains(t, fixed.Diff, "+import jakarta.ejb.Stateless;")
+	assert.Equal(t, 1, data.VerifiedFailed)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderResultsHTML(&buf, data))
+	out := buf.String()
+	assert.Contains(t, out, `<span class="add">&#43;import jakarta.ejb.Stateless;</span>`)
+	assert.Contains(t, out, `<span class="del">-import javax.ejb.Stateless;</span>`)
+	assert.Contains(t, out, `<i class="fas fa-vial"></i> failed`)
+	assert.Contains(t, out, "<code>"+sha[:8]+"</code>")
+	assert.Contains(t, out, "$0.05")
+}
+
+func TestDiffLineClass(t *testing.T) {
+	assert.Equal(t, "meta", diffLineClass("+++ b/src/A.java"))
+	assert.Equal(t, "hunk", diffLineClass("@@ -1 +1 @@"))
+	assert.Equal(t, "add", diffLineClass("+new"))
+	assert.Equal(t, "del", diffLineClass("-old"))
+	assert.Equal(t, "", diffLineClass(" context"))
+}
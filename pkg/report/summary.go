@@ -0,0 +1,193 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
+)
+
+// Summary is the machine-readable result of a remediate or execute run,
+// emitted with --output-format=json
+type Summary struct {
+	Command         string             `json:"command"`
+	DryRun          bool               `json:"dry_run"`
+	SuccessfulFixes int                `json:"successful_fixes"`
+	FailedFixes     int                `json:"failed_fixes"`
+	SkippedFixes    int                `json:"skipped_fixes"` // Skipped due to low confidence or .kantra-ai-ignore
+	TotalCost       float64            `json:"total_cost"`
+	AverageCost     float64            `json:"average_cost"` // Per successful fix
+	TotalTokens     int                `json:"total_tokens"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	Violations      []ViolationSummary `json:"violations"`
+
+	CostByProvider map[string]float64 `json:"cost_by_provider,omitempty"` // Set with a provider fallback chain, including failed attempts
+
+	Verification *VerificationSummary `json:"verification,omitempty"` // Set when verification was enabled
+}
+
+// VerificationSummary is the outcome of build/test verification
+type VerificationSummary struct {
+	Total         int                          `json:"total"`
+	Passed        int                          `json:"passed"`
+	Failed        int                          `json:"failed"`
+	Warnings      int                          `json:"warnings"`
+	Skipped       int                          `json:"skipped"`        // Verifications not run (e.g. dry-run)
+	SkippedFixes  int                          `json:"skipped_fixes"`  // Fixes dropped after a failure
+	RevertedFixes int                          `json:"reverted_fixes"` // Fixes restored to their content before the fix
+	Failures      []VerificationFailureSummary `json:"failures"`
+}
+
+// VerificationFailureSummary is a failed verification with its complete log
+type VerificationFailureSummary struct {
+	ViolationIDs    []string `json:"violation_ids"`
+	Command         string   `json:"command"`
+	Error           string   `json:"error,omitempty"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Output          string   `json:"output"` // Complete stdout and stderr
+}
+
+// BuildVerificationSummary summarizes a run's verification stats and failures
+func BuildVerificationSummary(stats gitutil.VerificationStats, failures []gitutil.VerificationFailure) *VerificationSummary {
+	summary := &VerificationSummary{
+		Total:         stats.TotalVerifications,
+		Passed:        stats.PassedVerifications,
+		Failed:        stats.FailedVerifications,
+		Warnings:      stats.WarningVerifications,
+		Skipped:       stats.SkippedVerifications,
+		SkippedFixes:  stats.SkippedFixes,
+		RevertedFixes: stats.RevertedFixes,
+		Failures:      make([]VerificationFailureSummary, 0, len(failures)),
+	}
+	for _, failure := range failures {
+		summary.Failures = append(summary.Failures, VerificationFailureSummary{
+			ViolationIDs:    failure.ViolationIDs,
+			Command:         failure.Command,
+			Error:           failure.Error,
+			DurationSeconds: failure.Duration.Seconds(),
+			Output:          failure.Output,
+		})
+	}
+	return summary
+}
+
+// ViolationSummary aggregates the fix results for one violation
+type ViolationSummary struct {
+	ID                string            `json:"id"`
+	Category          string            `json:"category,omitempty"`
+	SuccessfulFixes   int               `json:"successful_fixes"`
+	FailedFixes       int               `json:"failed_fixes"`
+	Cost              float64           `json:"cost"`
+	Tokens            int               `json:"tokens"`
+	AverageConfidence float64           `json:"average_confidence"` // Over successful fixes
+	Incidents         []IncidentSummary `json:"incidents"`
+}
+
+// IncidentSummary is the outcome of fixing a single incident
+type IncidentSummary struct {
+	File                 string                 `json:"file"`
+	Line                 int                    `json:"line"`
+	Success              bool                   `json:"success"`
+	Confidence           float64                `json:"confidence"`
+	SkippedLowConfidence bool                   `json:"skipped_low_confidence,omitempty"`
+	LowConfidence        bool                   `json:"low_confidence,omitempty"` // Below the confidence threshold, whatever was done with it
+	Explanation          string                 `json:"explanation,omitempty"`    // The model's reasoning (see OmitExplanations)
+	SkipReason           string                 `json:"skip_reason,omitempty"`
+	Error                string                 `json:"error,omitempty"`
+	Extra                map[string]interface{} `json:"extra,omitempty"`
+	Provider             string                 `json:"provider,omitempty"` // Provider that produced the fix, with a fallback chain
+}
+
+// BuildSummary aggregates fix records into a Summary. Violations appear in the
+// order they were first attempted.
+func BuildSummary(command string, dryRun bool, fixes []gitutil.FixRecord, duration time.Duration) *Summary {
+	summary := &Summary{
+		Command:         command,
+		DryRun:          dryRun,
+		DurationSeconds: duration.Seconds(),
+		Violations:      make([]ViolationSummary, 0),
+	}
+
+	index := make(map[string]int)
+	confidenceSums := make(map[string]float64)
+
+	for _, fix := range fixes {
+		i, ok := index[fix.Violation.ID]
+		if !ok {
+			i = len(summary.Violations)
+			index[fix.Violation.ID] = i
+			summary.Violations = append(summary.Violations, ViolationSummary{
+				ID:        fix.Violation.ID,
+				Category:  fix.Violation.Category,
+				Incidents: make([]IncidentSummary, 0),
+			})
+		}
+		vs := &summary.Violations[i]
+
+		incident := IncidentSummary{
+			File:                 fix.Incident.GetFilePath(),
+			Line:                 fix.Incident.LineNumber,
+			Success:              fix.Result.Success,
+			Confidence:           fix.Result.Confidence,
+			SkippedLowConfidence: fix.Result.SkippedLowConfidence,
+			LowConfidence:        fix.Result.LowConfidence,
+			Explanation:          fix.Result.Explanation,
+			SkipReason:           fix.Result.SkipReason,
+			Extra:                fix.Result.Extra,
+			Provider:             fix.Result.Provider,
+		}
+		if fix.Result.Error != nil {
+			incident.Error = fix.Result.Error.Error()
+		}
+		vs.Incidents = append(vs.Incidents, incident)
+
+		vs.Cost += fix.Result.Cost
+		vs.Tokens += fix.Result.TokensUsed
+		summary.TotalCost += fix.Result.Cost
+		summary.TotalTokens += fix.Result.TokensUsed
+
+		switch {
+		case fix.Result.SkippedLowConfidence, fix.Result.SkippedIgnored, fix.Result.SkippedByUser:
+			summary.SkippedFixes++
+		case fix.Result.Success:
+			vs.SuccessfulFixes++
+			summary.SuccessfulFixes++
+			confidenceSums[vs.ID] += fix.Result.Confidence
+		default:
+			vs.FailedFixes++
+			summary.FailedFixes++
+		}
+	}
+
+	for i := range summary.Violations {
+		vs := &summary.Violations[i]
+		if vs.SuccessfulFixes > 0 {
+			vs.AverageConfidence = confidenceSums[vs.ID] / float64(vs.SuccessfulFixes)
+		}
+	}
+	if summary.SuccessfulFixes > 0 {
+		summary.AverageCost = summary.TotalCost / float64(summary.SuccessfulFixes)
+	}
+
+	return summary
+}
+
+// OmitExplanations drops the explanations of incidents, except for
+// low-confidence fixes, whose reasoning reviewers always need
+func (s *Summary) OmitExplanations() {
+	for i := range s.Violations {
+		for j := range s.Violations[i].Incidents {
+			if incident := &s.Violations[i].Incidents[j]; !incident.LowConfidence {
+				incident.Explanation = ""
+			}
+		}
+	}
+}
+
+// WriteJSON writes the summary as indented JSON
+func (s *Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
+	"github.com/tsanders/kantra-ai/pkg/violation"
+)
+
+func TestBuildSummary(t *testing.T) {
+	v1 := violation.Violation{ID: "javax-to-jakarta", Category: "mandatory"}
+	v2 := violation.Violation{ID: "log4j-update", Category: "optional"}
+
+	fixes := []gitutil.FixRecord{
+		{
+			Violation: v1,
+			Incident:  violation.Incident{URI: "file:///src/A.java", LineNumber: 3},
+			Result:    fixer.FixResult{Success: true, Confidence: 0.9, Cost: 0.02, TokensUsed: 100},
+		},
+		{
+			Violation: v2,
+			Incident:  violation.Incident{URI: "file:///src/B.java", LineNumber: 7},
+			Result:    fixer.FixResult{Success: false, Error: errors.New("provider timeout")},
+		},
+		{
+			Violation: v1,
+			Incident:  violation.Incident{URI: "file:///src/C.java", LineNumber: 12},
+			Result:    fixer.FixResult{Success: true, Confidence: 0.7, Cost: 0.04, TokensUsed: 300},
+		},
+		{
+			Violation: v2,
+			Incident:  violation.Incident{URI: "file:///src/D.java", LineNumber: 1},
+			Result:    fixer.FixResult{SkippedLowConfidence: true, Confidence: 0.4, Cost: 0.01, TokensUsed: 50},
+		},
+		{
+			Violation: v2,
+			Incident:  violation.Incident{URI: "file:///src/vendor/E.java", LineNumber: 4},
+			Result:    fixer.FixResult{SkippedIgnored: true, SkipReason: fixer.IgnoreReason},
+		},
+	}
+
+	summary := BuildSummary("remediate", true, fixes, 90*time.Second)
+
+	assert.Equal(t, "remediate", summary.Command)
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, 2, summary.SuccessfulFixes)
+	assert.Equal(t, 1, summary.FailedFixes)
+	assert.Equal(t, 2, summary.SkippedFixes)
+	assert.InDelta(t, 0.07, summary.TotalCost, 0.0001)
+	assert.InDelta(t, 0.035, summary.AverageCost, 0.0001)
+	assert.Equal(t, 450, summary.TotalTokens)
+	assert.Equal(t, 90.0, summary.DurationSeconds)
+
+	require.Len(t, summary.Violations, 2)
+	first := summary.Violations[0]
+	assert.Equal(t, "javax-to-jakarta", first.ID)
+	assert.Equal(t, 2, first.SuccessfulFixes)
+	assert.InDelta(t, 0.8, first.AverageConfidence, 0.0001)
+	require.Len(t, first.Incidents, 2)
+	assert.Equal(t, "/src/A.java", first.Incidents[0].File)
+	assert.Equal(t, 3, first.Incidents[0].Line)
+
+	second := summary.Violations[1]
+	assert.Equal(t, 1, second.FailedFixes)
+	assert.Equal(t, "provider timeout", second.Incidents[0].Error)
+	assert.True(t, second.Incidents[1].SkippedLowConfidence)
+	assert.Equal(t, "ignored by .kantra-ai-ignore", second.Incidents[2].SkipReason)
+}
+
+func TestSummary_Explanations(t *testing.T) {
+	v := violation.Violation{ID: "javax-to-jakarta"}
+	fixes := []gitutil.FixRecord{
+		{
+			Violation: v,
+			Incident:  violation.Incident{URI: "file:///src/A.java", LineNumber: 3},
+			Result:    fixer.FixResult{Success: true, Confidence: 0.95, Explanation: "Renamed the javax.servlet import"},
+		},
+		{
+			Violation: v,
+			Incident:  violation.Incident{URI: "file:///src/B.java", LineNumber: 7},
+			Result:    fixer.FixResult{Success: true, Confidence: 0.5, LowConfidence: true, Explanation: "Unsure whether the filter is still used"},
+		},
+	}
+
+	summary := BuildSummary("remediate", false, fixes, time.Second)
+	incidents := summary.Violations[0].Incidents
+	assert.Equal(t, "Renamed the javax.servlet import", incidents[0].Explanation)
+	assert.True(t, incidents[1].LowConfidence)
+
+	// Without --explain, only low-confidence fixes keep their explanation
+	summary.OmitExplanations()
+	assert.Empty(t, incidents[0].Explanation)
+	assert.Equal(t, "Unsure whether the filter is still used", incidents[1].Explanation)
+}
+
+func TestSummary_WriteJSON(t *testing.T) {
+	summary := BuildSummary("execute", false, nil, time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, summary.WriteJSON(&buf))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "execute", decoded["command"])
+	assert.Equal(t, []interface{}{}, decoded["violations"], "violations is an empty array, not null")
+}
+
+func TestBuildVerificationSummary(t *testing.T) {
+	output := "[INFO] Compiling 12 source files\n[ERROR] App.java:[3,8] cannot find symbol\n"
+	summary := BuildSummary("remediate", false, nil, time.Second)
+	summary.Verification = BuildVerificationSummary(gitutil.VerificationStats{
+		TotalVerifications:  3,
+		PassedVerifications: 2,
+		FailedVerifications: 1,
+		SkippedFixes:        1,
+	}, []gitutil.VerificationFailure{{
+		ViolationIDs: []string{"javax-to-jakarta"},
+		Command:      "mvn compile",
+		Error:        "verification failed: exit status 1",
+		Output:       output,
+		Duration:     2 * time.Second,
+	}})
+
+	var buf bytes.Buffer
+	require.NoError(t, summary.WriteJSON(&buf))
+
+	var decoded Summary
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.NotNil(t, decoded.Verification)
+	assert.Equal(t, 3, decoded.Verification.Total)
+	assert.Equal(t, 1, decoded.Verification.Failed)
+	assert.Equal(t, 1, decoded.Verification.SkippedFixes)
+	require.Len(t, decoded.Verification.Failures, 1)
+	failure := decoded.Verification.Failures[0]
+	assert.Equal(t, []string{"javax-to-jakarta"}, failure.ViolationIDs)
+	assert.Equal(t, "mvn compile", failure.Command)
+	assert.Equal(t, output, failure.Output, "JSON has the complete log")
+	assert.Equal(t, 2.0, failure.DurationSeconds)
+
+	t.Run("omitted without verification", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, BuildSummary("remediate", false, nil, time.Second).WriteJSON(&buf))
+		assert.NotContains(t, buf.String(), "verification")
+	})
+}
@@ -179,6 +179,21 @@ const htmlTemplate = `<!DOCTYPE html>
             color: #3498db;
         }
 
+        .status-badge.completed {
+            background-color: #d5f4e6;
+            color: #27ae60;
+        }
+
+        .status-badge.failed {
+            background-color: #fadbd8;
+            color: #c0392b;
+        }
+
+        .status-badge.in_progress {
+            background-color: #e8f4f8;
+            color: #3498db;
+        }
+
         .phase-content {
             padding: 20px;
             display: none;
@@ -517,7 +532,7 @@ const htmlTemplate = `<!DOCTYPE html>
     <div id="app">
         <header>
             <div>
-                <h1><i class="fas fa-robot"></i> kantra-ai Migration Plan</h1>
+                <h1><i class="fas fa-robot"></i> kantra-ai Migration Plan{{if .Plan.Metadata.Heuristic}} (heuristic estimate){{end}}</h1>
                 <p>Generated: {{.Plan.Metadata.CreatedAt.Format "January 2, 2006 at 3:04 PM"}}</p>
             </div>
         </header>
@@ -543,6 +558,18 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="metric-value">${{printf "%.2f" .TotalCost}}</div>
                 <div class="metric-label">Estimated Cost</div>
             </div>
+            {{if .HasState}}
+
+            <div class="metric-card">
+                <div class="metric-value">{{.CompletedPhases}} / {{len .Plan.Phases}}</div>
+                <div class="metric-label">Phases Completed</div>
+            </div>
+
+            <div class="metric-card">
+                <div class="metric-value">${{printf "%.2f" .ActualCost}}</div>
+                <div class="metric-label">Actual Cost</div>
+            </div>
+            {{end}}
         </div>
 
         <!-- Charts -->
@@ -576,6 +603,9 @@ const htmlTemplate = `<!DOCTYPE html>
                         {{else}}
                         <span class="status-badge approved">✓ Approved</span>
                         {{end}}
+                        {{with index $.PhaseResults $phase.ID}}
+                        <span class="status-badge {{.Status}}">{{.Status}}</span>
+                        {{end}}
                         <span class="arrow">▶</span>
                     </div>
                 </div>
@@ -600,6 +630,10 @@ const htmlTemplate = `<!DOCTYPE html>
 
                     <div class="phase-stats">
                         <span><i class="fas fa-dollar-sign"></i> Cost: ${{printf "%.2f" $phase.EstimatedCost}}</span>
+                        {{with index $.PhaseResults $phase.ID}}
+                        <span><i class="fas fa-receipt"></i> Actual: ${{printf "%.2f" .Cost}}</span>
+                        <span><i class="fas fa-check"></i> Fixes applied: {{.FixesApplied}}</span>
+                        {{end}}
                         <span><i class="fas fa-clock"></i> Duration: {{$phase.EstimatedDurationMinutes}} min</span>
                         <span><i class="fas fa-wrench"></i> Violations: {{len $phase.Violations}}</span>
                     </div>
@@ -652,7 +686,7 @@ const htmlTemplate = `<!DOCTYPE html>
         </div>
 
         <footer>
-            Generated by kantra-ai | Provider: {{.Plan.Metadata.Provider}}
+            Generated by kantra-ai | Provider: {{.Plan.Metadata.Provider}}{{if .Plan.Metadata.Heuristic}} (heuristic, no AI call){{end}}
         </footer>
     </div>
 
@@ -0,0 +1,94 @@
+// Package runid namespaces the artifacts of a run (plan, state, reports,
+// review file and branches) so repeated experiments don't overwrite each other.
+package runid
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// validID matches run IDs that are safe in file names and branch names
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Generate returns a run ID based on the current time
+func Generate() string {
+	return time.Now().Format("20060102-150405")
+}
+
+// Resolve validates a run ID. An empty ID means artifacts are not
+// namespaced and is returned unchanged.
+func Resolve(id string) (string, error) {
+	switch {
+	case id == "":
+		return "", nil
+	case len(id) > 64 || !validID.MatchString(id) || strings.Contains(id, ".."):
+		return "", fmt.Errorf("invalid run ID %q: use up to 64 letters, digits, '.', '_' or '-'", id)
+	default:
+		return id, nil
+	}
+}
+
+// Path adds the run ID to a file or directory path, before the extension:
+// ".kantra-ai-state.yaml" becomes ".kantra-ai-state-<id>.yaml" and
+// ".kantra-ai-plan" becomes ".kantra-ai-plan-<id>". The path is returned
+// unchanged if id is empty.
+func Path(path, id string) string {
+	if id == "" || path == "" {
+		return path
+	}
+
+	dir, base := filepath.Split(path)
+	stem, ext := splitExt(base)
+	return dir + stem + "-" + id + ext
+}
+
+// splitExt splits a file name into its stem and extension. Dotfiles like
+// ".kantra-ai-plan" have no extension.
+func splitExt(base string) (string, string) {
+	ext := filepath.Ext(base)
+	if ext == base {
+		ext = ""
+	}
+	return strings.TrimSuffix(base, ext), ext
+}
+
+// Latest returns the run whose artifact was modified last: the run ID of
+// filepath.Join(Path(path, id), name), or "" for the artifact without a run
+// ID. name may be empty if path is the artifact itself. ok is false if no
+// run has the artifact.
+func Latest(path, name string) (id string, ok bool) {
+	var latest time.Time
+	consider := func(candidate string) {
+		info, err := os.Stat(filepath.Join(Path(path, candidate), name))
+		if err != nil || (ok && !info.ModTime().After(latest)) {
+			return
+		}
+		id, latest, ok = candidate, info.ModTime(), true
+	}
+
+	consider("")
+	dir, base := filepath.Split(path)
+	stem, ext := splitExt(base)
+	entries, _ := os.ReadDir(filepath.Clean(dir + "."))
+	for _, entry := range entries {
+		rest, hasStem := strings.CutPrefix(entry.Name(), stem+"-")
+		candidate, hasExt := strings.CutSuffix(rest, ext)
+		if hasStem && hasExt && validID.MatchString(candidate) {
+			consider(candidate)
+		}
+	}
+	return id, ok
+}
+
+// Branch adds the run ID to a branch name or branch prefix. The name is
+// returned unchanged if id is empty.
+func Branch(name, id string) string {
+	if id == "" || name == "" {
+		return name
+	}
+	return strings.TrimSuffix(name, "/") + "-" + id
+}
@@ -0,0 +1,90 @@
+package runid
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	id, err := Resolve("")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	id, err = Resolve("exp-1.a_b")
+	require.NoError(t, err)
+	assert.Equal(t, "exp-1.a_b", id)
+
+	assert.Regexp(t, `^\d{8}-\d{6}$`, Generate())
+
+	for _, invalid := range []string{"../x", "a/b", "-x", "has space", "a..b"} {
+		_, err := Resolve(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{".kantra-ai-state.yaml", ".kantra-ai-state-exp1.yaml"},
+		{".kantra-ai-plan", ".kantra-ai-plan-exp1"},
+		{"out/plan.html", "out/plan-exp1.html"},
+		{"/tmp/reports/results.html", "/tmp/reports/results-exp1.html"},
+		{".kantra-ai-plan/plan.yaml", ".kantra-ai-plan/plan-exp1.yaml"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Path(tt.path, "exp1"), tt.path)
+		assert.Contains(t, Path(tt.path, "exp1"), "exp1")
+	}
+
+	assert.Equal(t, ".kantra-ai-state.yaml", Path(".kantra-ai-state.yaml", ""))
+}
+
+func TestBranch(t *testing.T) {
+	assert.Equal(t, "kantra-ai/remediation-exp1", Branch("kantra-ai/remediation", "exp1"))
+	assert.Equal(t, "kantra-ai-exp1", Branch("kantra-ai/", "exp1"))
+	assert.Equal(t, "kantra-ai/remediation", Branch("kantra-ai/remediation", ""))
+}
+
+func TestLatest(t *testing.T) {
+	dir := t.TempDir()
+	planDir := filepath.Join(dir, ".kantra-ai-plan")
+
+	_, ok := Latest(planDir, "plan.yaml")
+	assert.False(t, ok, "no runs")
+
+	write := func(path string, age time.Duration) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("plan"), 0644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	write(filepath.Join(planDir, "plan.yaml"), 3*time.Hour)
+	write(filepath.Join(dir, ".kantra-ai-plan-exp1", "plan.yaml"), time.Hour)
+	write(filepath.Join(dir, ".kantra-ai-plan-exp2", "plan.yaml"), 2*time.Hour)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".kantra-ai-plan-empty"), 0755))
+
+	id, ok := Latest(planDir, "plan.yaml")
+	assert.True(t, ok)
+	assert.Equal(t, "exp1", id)
+
+	// The artifact without a run ID counts too
+	write(filepath.Join(planDir, "plan.yaml"), 0)
+	id, ok = Latest(planDir, "plan.yaml")
+	assert.True(t, ok)
+	assert.Empty(t, id)
+
+	// Files
+	statePath := filepath.Join(dir, ".kantra-ai-state.yaml")
+	write(filepath.Join(dir, ".kantra-ai-state-20260101-120000.yaml"), 0)
+	id, ok = Latest(statePath, "")
+	assert.True(t, ok)
+	assert.Equal(t, "20260101-120000", id)
+}
@@ -7,12 +7,22 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 )
 
+// quiet disables spinners and progress bars (see SetQuiet)
+var quiet bool
+
+// SetQuiet disables spinner and progress bar output, e.g. when stdout
+// carries machine-readable output
+func SetQuiet(q bool) {
+	quiet = q
+}
+
 // Color definitions for consistent output
 var (
 	Success = color.New(color.FgGreen).SprintFunc()
@@ -62,6 +72,9 @@ func PrintSection(text string) {
 
 // NewProgressBar creates a new progress bar with consistent styling
 func NewProgressBar(max int, description string) *progressbar.ProgressBar {
+	if quiet {
+		return progressbar.NewOptions(max, progressbar.OptionSetWriter(io.Discard))
+	}
 	return progressbar.NewOptions(max,
 		progressbar.OptionSetDescription(description),
 		progressbar.OptionSetWidth(40),
@@ -86,6 +99,7 @@ type Spinner struct {
 	index   int
 	done    chan bool
 	writer  io.Writer
+	started bool
 }
 
 // NewSpinner creates a new spinner with a message
@@ -100,6 +114,10 @@ func NewSpinner(message string) *Spinner {
 
 // Start begins the spinner animation
 func (s *Spinner) Start() {
+	if quiet {
+		return
+	}
+	s.started = true
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
@@ -121,6 +139,9 @@ func (s *Spinner) Start() {
 
 // Stop stops the spinner
 func (s *Spinner) Stop() {
+	if !s.started {
+		return
+	}
 	s.done <- true
 	close(s.done)
 }
@@ -167,6 +188,25 @@ func FormatDuration(d time.Duration) string {
 	return Dim(d.Round(time.Second).String())
 }
 
+// FormatDiff colors a unified diff for the terminal: file headers bold,
+// hunk headers cyan, removed lines red and added lines green
+func FormatDiff(diff string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = Bold(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = Info(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = Success(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = Error(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // FormatWarning returns a warning-colored string
 func FormatWarning(s string) string {
 	return Warning(s)
@@ -164,3 +164,15 @@ func TestRepeatEdgeCases(t *testing.T) {
 		assert.True(t, strings.Count(result, "a") == 1000)
 	})
 }
+
+func TestFormatDiff(t *testing.T) {
+	diff := "--- a/App.java\n+++ b/App.java\n@@ -1,2 +1,2 @@\n-import javax.servlet.*;\n+import jakarta.servlet.*;\n class App {}\n"
+	result := FormatDiff(diff)
+
+	// Every line is kept, in order
+	lines := strings.Split(result, "\n")
+	assert.Len(t, lines, 6)
+	for i, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		assert.Contains(t, lines[i], line)
+	}
+}
@@ -0,0 +1,197 @@
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// VerificationScope defines which tests a test verification runs
+type VerificationScope int
+
+const (
+	// ScopeAll runs the whole test suite
+	ScopeAll VerificationScope = iota
+	// ScopeAffected runs only the tests likely affected by the changed files
+	ScopeAffected
+)
+
+// String returns the verification scope's command-line name
+func (s VerificationScope) String() string {
+	if s == ScopeAffected {
+		return "affected"
+	}
+	return "all"
+}
+
+// ScopeNames returns the command-line names of the verification scopes
+func ScopeNames() []string {
+	return []string{ScopeAll.String(), ScopeAffected.String()}
+}
+
+// ParseVerificationScope parses a string into a VerificationScope
+func ParseVerificationScope(s string) (VerificationScope, error) {
+	switch s {
+	case "all", "":
+		return ScopeAll, nil
+	case "affected":
+		return ScopeAffected, nil
+	default:
+		return ScopeAll, fmt.Errorf("invalid verification scope: %s (valid: all, affected)", s)
+	}
+}
+
+// FileList is a list of changed files. It prints space-separated, so
+// {{.ChangedFiles}} can be used directly in a command.
+type FileList []string
+
+// String joins the files with spaces
+func (f FileList) String() string {
+	return strings.Join(f, " ")
+}
+
+// AffectedCommandData is the data available to Config.AffectedCommand
+type AffectedCommandData struct {
+	ChangedFiles FileList // Relative to the directory the command runs from
+}
+
+// parseAffectedCommand parses an affected-scope command template, rendering
+// it once so mistakes are reported before anything runs
+func parseAffectedCommand(text string) (*template.Template, error) {
+	tmpl, err := template.New("affected-command").Option("missingkey=error").Parse(text)
+	if err == nil {
+		err = tmpl.Execute(&bytes.Buffer{}, AffectedCommandData{ChangedFiles: FileList{"src/Example.java"}})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid affected verification command '%s': %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// ForChanges returns a verifier for changes to files, relative to the
+// verifier's working directory. With the affected scope it runs only the
+// tests covering those files when they can be determined; otherwise it is v.
+func (v *Verifier) ForChanges(files []string) *Verifier {
+	if v.config.Scope != ScopeAffected || len(files) == 0 {
+		return v
+	}
+
+	scoped := *v
+	scoped.changedFiles = files
+	return &scoped
+}
+
+// affectedTestCommand returns the command running the tests affected by the
+// changed files, or "" if the whole project must be verified: outside the
+// affected scope, for build verification, or when the tests can't be inferred.
+func (v *Verifier) affectedTestCommand() (string, error) {
+	if v.config.Scope != ScopeAffected || len(v.changedFiles) == 0 {
+		return "", nil
+	}
+
+	if v.affectedCommand != nil {
+		var buf bytes.Buffer
+		data := AffectedCommandData{ChangedFiles: FileList(v.changedFiles)}
+		if err := v.affectedCommand.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to render affected verification command: %w", err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	// A custom command can't be narrowed, and a build covers the whole project
+	if v.config.CustomCommand != "" || v.config.Type != VerificationTest {
+		return "", nil
+	}
+
+	switch v.projectType {
+	case ProjectGo:
+		return affectedGoCommand(v.changedFiles), nil
+	case ProjectMaven:
+		return affectedMavenCommand(v.changedFiles), nil
+	case ProjectGradle:
+		return affectedGradleCommand(v.changedFiles), nil
+	default:
+		return "", nil
+	}
+}
+
+// affectedGoCommand tests the packages containing the changed files, and the
+// packages below them
+func affectedGoCommand(files []string) string {
+	var packages []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			return "" // e.g. go.mod: test everything
+		}
+		pkg := "./" + filepath.ToSlash(filepath.Dir(file)) + "/..."
+		if pkg == "././..." {
+			pkg = "./..."
+		}
+		packages = appendUnique(packages, pkg)
+	}
+	return "go test " + strings.Join(packages, " ")
+}
+
+// affectedMavenCommand tests the test classes in the packages of the changed
+// files. Packages without tests are not an error.
+func affectedMavenCommand(files []string) string {
+	var patterns []string
+	for _, file := range files {
+		pkg, ok := jvmPackageDir(file)
+		if !ok {
+			return ""
+		}
+		patterns = appendUnique(patterns, pkg+"/*Test*")
+	}
+	return "mvn test -Dtest=" + strings.Join(patterns, ",") + " -Dsurefire.failIfNoSpecifiedTests=false"
+}
+
+// affectedGradleCommand tests the classes in the packages of the changed files
+func affectedGradleCommand(files []string) string {
+	var filters []string
+	for _, file := range files {
+		pkg, ok := jvmPackageDir(file)
+		if !ok {
+			return ""
+		}
+		filters = appendUnique(filters, "--tests "+strings.ReplaceAll(pkg, "/", ".")+".*")
+	}
+	return "gradle test " + strings.Join(filters, " ")
+}
+
+// jvmSourceRoots are the standard Maven/Gradle source directories
+var jvmSourceRoots = []string{"src/main/java/", "src/test/java/", "src/main/kotlin/", "src/test/kotlin/"}
+
+// jvmPackageDir returns the slash-separated package directory of a Java or
+// Kotlin source file under a standard source root, e.g.
+// "src/main/java/com/example/App.java" gives "com/example"
+func jvmPackageDir(file string) (string, bool) {
+	switch LanguageForFile(file) {
+	case "java", "kotlin":
+	default:
+		return "", false
+	}
+
+	path := "/" + filepath.ToSlash(file)
+	for _, root := range jvmSourceRoots {
+		if i := strings.LastIndex(path, "/"+root); i >= 0 {
+			dir := filepath.ToSlash(filepath.Dir(path[i+len(root)+1:]))
+			if dir == "." {
+				return "", false // Default package
+			}
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,161 @@
+package verifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVerificationScope(t *testing.T) {
+	scope, err := ParseVerificationScope("")
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAll, scope)
+
+	scope, err = ParseVerificationScope("affected")
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAffected, scope)
+
+	_, err = ParseVerificationScope("changed")
+	assert.Error(t, err)
+}
+
+func TestVerifier_ForChanges(t *testing.T) {
+	newVerifier := func(t *testing.T, buildFile string, config Config) *Verifier {
+		tmpDir := t.TempDir()
+		if buildFile != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, buildFile), []byte(""), 0644))
+		}
+		config.WorkingDir = tmpDir
+		if config.Type == VerificationNone {
+			config.Type = VerificationTest
+		}
+		v, err := NewVerifier(config)
+		require.NoError(t, err)
+		return v
+	}
+	command := func(t *testing.T, v *Verifier) string {
+		command, err := v.command()
+		require.NoError(t, err)
+		return command
+	}
+
+	tests := []struct {
+		name      string
+		buildFile string
+		config    Config
+		files     []string
+		expected  string
+	}{
+		{
+			name:      "go packages",
+			buildFile: "go.mod",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"pkg/auth/token.go", "pkg/auth/token_test.go", "cmd/main.go"},
+			expected:  "go test ./pkg/auth/... ./cmd/...",
+		},
+		{
+			name:      "go root package",
+			buildFile: "go.mod",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"main.go"},
+			expected:  "go test ./...",
+		},
+		{
+			name:      "go non-source change tests everything",
+			buildFile: "go.mod",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"pkg/auth/token.go", "go.mod"},
+			expected:  "go test ./...",
+		},
+		{
+			name:      "maven test classes in the changed packages",
+			buildFile: "pom.xml",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"src/main/java/com/example/auth/Token.java", "src/test/java/com/example/auth/TokenTest.java", "src/main/java/com/example/App.java"},
+			expected:  "mvn test -Dtest=com/example/auth/*Test*,com/example/*Test* -Dsurefire.failIfNoSpecifiedTests=false",
+		},
+		{
+			name:      "maven non-source change tests everything",
+			buildFile: "pom.xml",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"pom.xml"},
+			expected:  "mvn test",
+		},
+		{
+			name:      "gradle packages",
+			buildFile: "build.gradle",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"src/main/kotlin/com/example/App.kt"},
+			expected:  "gradle test --tests com.example.*",
+		},
+		{
+			name:      "npm can't be inferred",
+			buildFile: "package.json",
+			config:    Config{Scope: ScopeAffected},
+			files:     []string{"src/app.js"},
+			expected:  "npm test",
+		},
+		{
+			name:      "template",
+			buildFile: "package.json",
+			config:    Config{Scope: ScopeAffected, AffectedCommand: "npx jest --findRelatedTests {{.ChangedFiles}}"},
+			files:     []string{"src/app.js", "src/util.js"},
+			expected:  "npx jest --findRelatedTests src/app.js src/util.js",
+		},
+		{
+			name:     "template overrides a custom command",
+			config:   Config{Scope: ScopeAffected, CustomCommand: "make test", AffectedCommand: "make test FILES={{.ChangedFiles}}"},
+			files:    []string{"a.c"},
+			expected: "make test FILES=a.c",
+		},
+		{
+			name:     "custom command can't be narrowed",
+			config:   Config{Scope: ScopeAffected, CustomCommand: "make test"},
+			files:    []string{"a.go"},
+			expected: "make test",
+		},
+		{
+			name:      "build verification covers the whole project",
+			buildFile: "go.mod",
+			config:    Config{Type: VerificationBuild, Scope: ScopeAffected},
+			files:     []string{"pkg/auth/token.go"},
+			expected:  "go build ./...",
+		},
+		{
+			name:      "all scope",
+			buildFile: "go.mod",
+			config:    Config{Scope: ScopeAll, AffectedCommand: "go test {{.ChangedFiles}}"},
+			files:     []string{"pkg/auth/token.go"},
+			expected:  "go test ./...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVerifier(t, tt.buildFile, tt.config)
+			assert.Equal(t, tt.expected, command(t, v.ForChanges(tt.files)))
+		})
+	}
+
+	t.Run("no changed files runs everything", func(t *testing.T) {
+		v := newVerifier(t, "go.mod", Config{Scope: ScopeAffected})
+		assert.Equal(t, "go test ./...", command(t, v.ForChanges(nil)))
+	})
+
+	t.Run("the original verifier is unchanged", func(t *testing.T) {
+		v := newVerifier(t, "go.mod", Config{Scope: ScopeAffected})
+		v.ForChanges([]string{"pkg/a.go"})
+		assert.Equal(t, "go test ./...", command(t, v))
+	})
+}
+
+func TestNewVerifier_InvalidAffectedCommand(t *testing.T) {
+	for _, text := range []string{"test {{.ChangedFiles", "test {{.Missing}}"} {
+		_, err := NewVerifier(Config{WorkingDir: t.TempDir(), AffectedCommand: text})
+		require.Error(t, err, text)
+		assert.Contains(t, err.Error(), "invalid affected verification command")
+	}
+}
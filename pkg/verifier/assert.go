@@ -0,0 +1,114 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAssertTimeout bounds a single fix assertion run
+const DefaultAssertTimeout = 2 * time.Minute
+
+// FixAssertion is a per-fix success predicate: a shell command that is run for
+// each fixed file and must exit 0 for the fix to count as verified. Unlike
+// Verifier it checks one fix at a time (e.g. "the violation no longer
+// triggers"), which is usually much faster than a full build.
+//
+// The command may reference these placeholders, which are shell-quoted when
+// substituted:
+//
+//	{file}      path of the fixed file, relative to the working directory
+//	{abs_file}  absolute path of the fixed file
+//	{line}      line number of the fixed incident
+//	{violation} violation ID
+type FixAssertion struct {
+	Command    string
+	WorkingDir string
+	Timeout    time.Duration
+}
+
+// AssertTarget identifies the fix an assertion is evaluated for
+type AssertTarget struct {
+	File        string // Relative to the working directory
+	Line        int
+	ViolationID string
+}
+
+// NewFixAssertion creates a fix assertion for the given command template
+func NewFixAssertion(command, workingDir string) (*FixAssertion, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("fix assertion command is empty")
+	}
+	if workingDir == "" {
+		return nil, fmt.Errorf("working directory is required for fix assertions")
+	}
+	return &FixAssertion{
+		Command:    command,
+		WorkingDir: workingDir,
+		Timeout:    DefaultAssertTimeout,
+	}, nil
+}
+
+// Expand substitutes the placeholders in the command for the given target
+func (a *FixAssertion) Expand(target AssertTarget) string {
+	absFile := target.File
+	if !filepath.IsAbs(absFile) {
+		absFile = filepath.Join(a.WorkingDir, target.File)
+	}
+	replacer := strings.NewReplacer(
+		"{file}", shellQuote(target.File),
+		"{abs_file}", shellQuote(absFile),
+		"{line}", strconv.Itoa(target.Line),
+		"{violation}", shellQuote(target.ViolationID),
+	)
+	return replacer.Replace(a.Command)
+}
+
+// Check runs the assertion for the given target. A non-zero exit status is
+// reported as an unsuccessful Result, not as an error; an error is only
+// returned if the command could not be run at all.
+func (a *FixAssertion) Check(ctx context.Context, target AssertTarget) (*Result, error) {
+	start := time.Now()
+	command := a.Expand(target)
+
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAssertTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = a.WorkingDir
+
+	output, err := cmd.CombinedOutput()
+	result := &Result{
+		Command:   command,
+		Output:    string(output),
+		Duration:  time.Since(start),
+		Timestamp: start,
+	}
+
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to run fix assertion '%s': %w", command, err)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		result.Error = fmt.Errorf("fix assertion failed for %s: %w", target.File, err)
+		return result, nil
+	}
+
+	result.Success = true
+	return result, nil
+}
+
+// shellQuote quotes s for safe use as a single sh argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
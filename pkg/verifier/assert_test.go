@@ -0,0 +1,72 @@
+package verifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFixAssertion(t *testing.T) {
+	_, err := NewFixAssertion("  ", t.TempDir())
+	assert.Error(t, err)
+
+	_, err = NewFixAssertion("true", "")
+	assert.Error(t, err)
+
+	a, err := NewFixAssertion("true", t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAssertTimeout, a.Timeout)
+}
+
+func TestFixAssertion_Expand(t *testing.T) {
+	a := &FixAssertion{Command: "grep -q javax {file} && echo {line} {violation} {abs_file}", WorkingDir: "/src"}
+
+	got := a.Expand(AssertTarget{File: "src/it's.java", Line: 12, ViolationID: "javax-to-jakarta"})
+
+	assert.Equal(t, `grep -q javax 'src/it'\''s.java' && echo 12 'javax-to-jakarta' '/src/src/it'\''s.java'`, got)
+}
+
+func TestFixAssertion_Check(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "Main.java"),
+		[]byte("import jakarta.servlet.Servlet;\n"), 0644))
+
+	target := AssertTarget{File: "src/Main.java", Line: 1, ViolationID: "javax-to-jakarta"}
+
+	t.Run("passing assertion", func(t *testing.T) {
+		a, err := NewFixAssertion("! grep -q 'javax\\.' {file}", tmpDir)
+		require.NoError(t, err)
+
+		result, err := a.Check(context.Background(), target)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.NoError(t, result.Error)
+		assert.Equal(t, "! grep -q 'javax\\.' 'src/Main.java'", result.Command)
+	})
+
+	t.Run("failing assertion", func(t *testing.T) {
+		a, err := NewFixAssertion("grep -q 'javax\\.' {file}", tmpDir)
+		require.NoError(t, err)
+
+		result, err := a.Check(context.Background(), target)
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), "src/Main.java")
+	})
+
+	t.Run("output is captured", func(t *testing.T) {
+		a, err := NewFixAssertion("echo checking {violation}; exit 3", tmpDir)
+		require.NoError(t, err)
+
+		result, err := a.Check(context.Background(), target)
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Contains(t, result.Output, "checking javax-to-jakarta")
+	})
+}
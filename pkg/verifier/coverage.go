@@ -0,0 +1,312 @@
+package verifier
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Coverage is the line (or, for Go, statement) coverage from a coverage report
+type Coverage struct {
+	Covered int
+	Total   int
+}
+
+// Percent returns the coverage as a percentage (100 if there is nothing to cover)
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Covered) / float64(c.Total) * 100
+}
+
+// String formats the coverage, e.g. "81.3% (813/1000)"
+func (c Coverage) String() string {
+	return fmt.Sprintf("%.1f%% (%d/%d)", c.Percent(), c.Covered, c.Total)
+}
+
+// goCoverProfile is the file Go coverage verification writes, in the
+// session's temporary directory
+const goCoverProfile = "coverage.out"
+
+// Default coverage report locations, relative to the project directory
+const (
+	jacocoMavenReport  = "target/site/jacoco/jacoco.xml"
+	jacocoGradleReport = "build/reports/jacoco/test/jacocoTestReport.xml"
+)
+
+// getCoverageCommand returns the command running the tests with coverage
+// for the project type, or "" if it can't be determined
+func (v *Verifier) getCoverageCommand() string {
+	switch v.projectType {
+	case ProjectGo:
+		dir, err := v.session.tempDir()
+		if err != nil {
+			return ""
+		}
+		return "go test -coverprofile=" + filepath.Join(dir, goCoverProfile) + " ./..."
+	case ProjectMaven:
+		// The plugin goals work without configuring JaCoCo in the pom
+		return "mvn org.jacoco:jacoco-maven-plugin:prepare-agent test org.jacoco:jacoco-maven-plugin:report"
+	case ProjectGradle:
+		// Requires the jacoco plugin in the build
+		return "gradle test jacocoTestReport"
+	default:
+		return ""
+	}
+}
+
+// coverageReportPath returns the coverage report the verification command
+// writes: Config.CoverageReport, or the project type's default location
+func (v *Verifier) coverageReportPath() (string, error) {
+	report := v.config.CoverageReport
+	if report == "" {
+		switch v.projectType {
+		case ProjectGo:
+			if v.config.CustomCommand != "" {
+				return "", fmt.Errorf("a custom coverage command needs the path of the report it writes (--coverage-report)")
+			}
+			dir, err := v.session.tempDir()
+			if err != nil {
+				return "", err
+			}
+			report = filepath.Join(dir, goCoverProfile)
+		case ProjectMaven:
+			report = jacocoMavenReport
+		case ProjectGradle:
+			report = jacocoGradleReport
+		default:
+			return "", fmt.Errorf("no default coverage report for project type %s: set the report path (--coverage-report)", v.projectType)
+		}
+	}
+	if !filepath.IsAbs(report) {
+		report = filepath.Join(v.config.WorkingDir, report)
+	}
+	return report, nil
+}
+
+// MeasureBaseline records the coverage the fixes are compared against, from
+// Config.CoverageBaseline if set, otherwise by running the coverage command
+// on the unchanged project. It must be called before any fix is applied; it
+// does nothing for other verification types or if a baseline is recorded.
+func (v *Verifier) MeasureBaseline() error {
+	if v.config.Type != VerificationCoverage || v.session.baseline() != nil {
+		return nil
+	}
+
+	if v.config.CoverageBaseline != "" {
+		coverage, err := ParseCoverageReport(v.config.CoverageBaseline)
+		if err != nil {
+			return fmt.Errorf("failed to read coverage baseline: %w", err)
+		}
+		v.session.setBaseline(coverage)
+		return nil
+	}
+
+	result, err := v.run()
+	if err != nil {
+		return fmt.Errorf("failed to measure baseline coverage: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("failed to measure baseline coverage: the tests fail before any fix (%v)\n%s", result.Error, result.Output)
+	}
+	coverage, err := v.readCoverage()
+	if err != nil {
+		return fmt.Errorf("failed to measure baseline coverage: %w", err)
+	}
+	v.session.setBaseline(coverage)
+	return nil
+}
+
+// Baseline returns the baseline coverage, or nil if it hasn't been measured
+func (v *Verifier) Baseline() *Coverage {
+	return v.session.baseline()
+}
+
+// readCoverage parses the coverage report written by the last run
+func (v *Verifier) readCoverage() (Coverage, error) {
+	path, err := v.coverageReportPath()
+	if err != nil {
+		return Coverage{}, err
+	}
+	return ParseCoverageReport(path)
+}
+
+// checkCoverage compares the coverage after a successful test run with the
+// baseline, failing result if it dropped by more than the tolerance
+func (v *Verifier) checkCoverage(result *Result) error {
+	baseline := v.session.baseline()
+	if baseline == nil {
+		return fmt.Errorf("coverage verification has no baseline: it must be measured before fixes are applied")
+	}
+	coverage, err := v.readCoverage()
+	if err != nil {
+		return err
+	}
+
+	result.Coverage = &coverage
+	result.BaselineCoverage = baseline
+	if drop := baseline.Percent() - coverage.Percent(); drop > v.config.CoverageTolerance {
+		result.Success = false
+		result.Error = fmt.Errorf("coverage dropped from %s to %s, %.1f points more than the %.1f allowed",
+			baseline, coverage, drop-v.config.CoverageTolerance, v.config.CoverageTolerance)
+	}
+	return nil
+}
+
+// ParseCoverageReport parses a coverage report. The format is detected from
+// the content: a Go cover profile, JaCoCo XML, Cobertura XML or LCOV.
+func ParseCoverageReport(path string) (Coverage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Coverage{}, fmt.Errorf("failed to read coverage report: %w", err)
+	}
+
+	var coverage Coverage
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		coverage, err = parseGoCoverProfile(data)
+	case bytes.Contains(data, []byte("<report")):
+		coverage, err = parseJacocoReport(data)
+	case bytes.Contains(data, []byte("<coverage")):
+		coverage, err = parseCoberturaReport(data)
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		coverage, err = parseLcovReport(data)
+	default:
+		err = fmt.Errorf("unknown format (supported: Go cover profile, JaCoCo XML, Cobertura XML, LCOV)")
+	}
+	if err != nil {
+		return Coverage{}, fmt.Errorf("invalid coverage report %s: %w", path, err)
+	}
+	return coverage, nil
+}
+
+// parseGoCoverProfile parses `go test -coverprofile` output. Blocks listed
+// more than once (e.g. with -coverpkg) count once, covered if any run hit them.
+func parseGoCoverProfile(data []byte) (Coverage, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "mode:") {
+			continue
+		}
+		// file.go:startLine.startCol,endLine.endCol numStatements count
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return Coverage{}, fmt.Errorf("line %d: expected 'block statements count'", line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Coverage{}, fmt.Errorf("line %d: invalid statement count: %w", line, err)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return Coverage{}, fmt.Errorf("line %d: invalid hit count: %w", line, err)
+		}
+
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return Coverage{}, err
+	}
+
+	var coverage Coverage
+	for _, b := range blocks {
+		coverage.Total += b.statements
+		if b.covered {
+			coverage.Covered += b.statements
+		}
+	}
+	return coverage, nil
+}
+
+// xmlDecoder returns a decoder that doesn't fetch the DTDs the reports reference
+func xmlDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	return decoder
+}
+
+// parseJacocoReport parses a JaCoCo XML report, using the report-level LINE
+// counter (or INSTRUCTION, if lines weren't recorded)
+func parseJacocoReport(data []byte) (Coverage, error) {
+	var report struct {
+		Counters []struct {
+			Type    string `xml:"type,attr"`
+			Missed  int    `xml:"missed,attr"`
+			Covered int    `xml:"covered,attr"`
+		} `xml:"counter"`
+	}
+	if err := xmlDecoder(data).Decode(&report); err != nil {
+		return Coverage{}, err
+	}
+
+	for _, counterType := range []string{"LINE", "INSTRUCTION"} {
+		for _, counter := range report.Counters {
+			if counter.Type == counterType {
+				return Coverage{Covered: counter.Covered, Total: counter.Covered + counter.Missed}, nil
+			}
+		}
+	}
+	return Coverage{}, fmt.Errorf("no LINE or INSTRUCTION counter in the JaCoCo report")
+}
+
+// parseCoberturaReport parses a Cobertura XML report
+func parseCoberturaReport(data []byte) (Coverage, error) {
+	var report struct {
+		LinesValid   *int `xml:"lines-valid,attr"`
+		LinesCovered *int `xml:"lines-covered,attr"`
+	}
+	if err := xmlDecoder(data).Decode(&report); err != nil {
+		return Coverage{}, err
+	}
+	if report.LinesValid == nil || report.LinesCovered == nil {
+		return Coverage{}, fmt.Errorf("missing lines-valid or lines-covered in the Cobertura report")
+	}
+	return Coverage{Covered: *report.LinesCovered, Total: *report.LinesValid}, nil
+}
+
+// parseLcovReport parses an LCOV tracefile, summing the per-file line totals
+// (LF) and lines hit (LH)
+func parseLcovReport(data []byte) (Coverage, error) {
+	var coverage Coverage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		var target *int
+		switch {
+		case strings.HasPrefix(text, "LF:"):
+			target = &coverage.Total
+		case strings.HasPrefix(text, "LH:"):
+			target = &coverage.Covered
+		default:
+			continue
+		}
+		n, err := strconv.Atoi(text[3:])
+		if err != nil {
+			return Coverage{}, fmt.Errorf("line %d: invalid count: %w", line, err)
+		}
+		*target += n
+	}
+	if err := scanner.Err(); err != nil {
+		return Coverage{}, err
+	}
+	return coverage, nil
+}
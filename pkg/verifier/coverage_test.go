@@ -0,0 +1,290 @@
+package verifier
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goProfile = `mode: set
+example.com/app/auth.go:10.2,12.3 2 1
+example.com/app/auth.go:14.2,20.3 5 0
+example.com/app/token.go:5.2,9.3 3 1
+`
+
+// The second run of token.go (e.g. from -coverpkg) hit nothing
+const goProfileDuplicates = `mode: count
+example.com/app/token.go:5.2,9.3 3 0
+example.com/app/token.go:11.2,12.3 1 0
+example.com/app/token.go:5.2,9.3 3 4
+`
+
+const jacocoReport = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="app">
+  <package name="com/example">
+    <class name="com/example/App">
+      <counter type="LINE" missed="90" covered="10"/>
+    </class>
+    <counter type="LINE" missed="90" covered="10"/>
+  </package>
+  <counter type="INSTRUCTION" missed="400" covered="600"/>
+  <counter type="LINE" missed="20" covered="80"/>
+</report>
+`
+
+const coberturaReport = `<?xml version="1.0" ?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.75" lines-valid="200" lines-covered="150" version="7.4">
+  <packages/>
+</coverage>
+`
+
+const lcovReport = `TN:
+SF:src/auth.js
+LF:40
+LH:30
+end_of_record
+SF:src/token.js
+LF:10
+LH:10
+end_of_record
+`
+
+func writeReport(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseCoverageReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Coverage
+	}{
+		{name: "go cover profile", content: goProfile, expected: Coverage{Covered: 5, Total: 10}},
+		{name: "go cover profile with repeated blocks", content: goProfileDuplicates, expected: Coverage{Covered: 3, Total: 4}},
+		{name: "jacoco", content: jacocoReport, expected: Coverage{Covered: 80, Total: 100}},
+		{name: "cobertura", content: coberturaReport, expected: Coverage{Covered: 150, Total: 200}},
+		{name: "lcov", content: lcovReport, expected: Coverage{Covered: 40, Total: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coverage, err := ParseCoverageReport(writeReport(t, t.TempDir(), "report", tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, coverage)
+		})
+	}
+
+	t.Run("jacoco without line counters uses instructions", func(t *testing.T) {
+		report := `<report name="app"><counter type="INSTRUCTION" missed="25" covered="75"/></report>`
+		coverage, err := ParseCoverageReport(writeReport(t, t.TempDir(), "jacoco.xml", report))
+		require.NoError(t, err)
+		assert.Equal(t, 75.0, coverage.Percent())
+	})
+}
+
+func TestParseCoverageReport_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := ParseCoverageReport(filepath.Join(dir, "missing.out"))
+	assert.Error(t, err)
+
+	for name, content := range map[string]string{
+		"unknown.txt":   "PASS\nok  example.com/app\n",
+		"bad.out":       "mode: set\nexample.com/app/auth.go:10.2,12.3 two 1\n",
+		"jacoco.xml":    `<report name="app"><counter type="BRANCH" missed="1" covered="1"/></report>`,
+		"cobertura.xml": `<coverage line-rate="0.5"></coverage>`,
+		"lcov.info":     "TN:\nSF:a.js\nLF:x\n",
+	} {
+		_, err := ParseCoverageReport(writeReport(t, dir, name, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCoverage_Percent(t *testing.T) {
+	assert.Equal(t, 80.0, Coverage{Covered: 8, Total: 10}.Percent())
+	assert.Equal(t, 100.0, Coverage{}.Percent())
+	assert.Equal(t, "80.0% (8/10)", Coverage{Covered: 8, Total: 10}.String())
+}
+
+func TestVerifier_CoverageRegression(t *testing.T) {
+	// The verification command copies the coverage of the "current" code
+	// into the report the verifier reads
+	newVerifier := func(t *testing.T, dir string, tolerance float64) *Verifier {
+		v, err := NewVerifier(Config{
+			Type:              VerificationCoverage,
+			WorkingDir:        dir,
+			CustomCommand:     "cp current.info coverage.info",
+			CoverageReport:    "coverage.info",
+			CoverageTolerance: tolerance,
+		})
+		require.NoError(t, err)
+		return v
+	}
+	lcov := func(hit int) string {
+		return "SF:src/app.js\nLF:100\nLH:" + strconv.Itoa(hit) + "\nend_of_record\n"
+	}
+
+	t.Run("a drop below the baseline fails", func(t *testing.T) {
+		dir := t.TempDir()
+		writeReport(t, dir, "current.info", lcov(80))
+		v := newVerifier(t, dir, 0)
+		require.NoError(t, v.MeasureBaseline())
+		assert.Equal(t, &Coverage{Covered: 80, Total: 100}, v.Baseline())
+
+		writeReport(t, dir, "current.info", lcov(75))
+		result, err := v.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), "coverage dropped from 80.0% (80/100) to 75.0% (75/100)")
+		assert.Equal(t, 75.0, result.Coverage.Percent())
+		assert.Equal(t, 80.0, result.BaselineCoverage.Percent())
+	})
+
+	t.Run("a drop within the tolerance passes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeReport(t, dir, "current.info", lcov(80))
+		v := newVerifier(t, dir, 2)
+		require.NoError(t, v.MeasureBaseline())
+
+		writeReport(t, dir, "current.info", lcov(79))
+		result, err := v.Verify()
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 79.0, result.Coverage.Percent())
+
+		writeReport(t, dir, "current.info", lcov(77))
+		result, err = v.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+	})
+
+	t.Run("an increase passes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeReport(t, dir, "current.info", lcov(80))
+		v := newVerifier(t, dir, 0)
+		require.NoError(t, v.MeasureBaseline())
+
+		writeReport(t, dir, "current.info", lcov(90))
+		result, err := v.Verify()
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+	})
+
+	t.Run("baseline from a report file", func(t *testing.T) {
+		dir := t.TempDir()
+		baseline := writeReport(t, dir, "baseline.xml", jacocoReport) // 80%
+		writeReport(t, dir, "current.info", lcov(70))
+
+		v, err := NewVerifier(Config{
+			Type:             VerificationCoverage,
+			WorkingDir:       dir,
+			CustomCommand:    "cp current.info coverage.info",
+			CoverageReport:   "coverage.info",
+			CoverageBaseline: baseline,
+		})
+		require.NoError(t, err)
+		require.NoError(t, v.MeasureBaseline())
+
+		// Derived verifiers share the baseline
+		result, err := v.InModule("svc").ForChanges([]string{"svc/app.js"}).Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Equal(t, "cp current.info coverage.info", result.Command)
+	})
+
+	t.Run("failing tests fail before coverage is compared", func(t *testing.T) {
+		dir := t.TempDir()
+		writeReport(t, dir, "current.info", lcov(80))
+		v := newVerifier(t, dir, 0)
+		require.NoError(t, v.MeasureBaseline())
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "current.info")))
+		result, err := v.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Nil(t, result.Coverage)
+	})
+
+	t.Run("verifying without a baseline is an error", func(t *testing.T) {
+		dir := t.TempDir()
+		writeReport(t, dir, "current.info", lcov(80))
+		_, err := newVerifier(t, dir, 0).Verify()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no baseline")
+	})
+}
+
+func TestVerifier_MeasureBaselineErrors(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVerifier(Config{Type: VerificationCoverage, WorkingDir: dir, CustomCommand: "false", CoverageReport: "coverage.info"})
+	require.NoError(t, err)
+	err = v.MeasureBaseline()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tests fail before any fix")
+
+	// npm has no default report location
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644))
+	v, err = NewVerifier(Config{Type: VerificationCoverage, WorkingDir: dir, CustomCommand: "true"})
+	require.NoError(t, err)
+	err = v.MeasureBaseline()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--coverage-report")
+
+	_, err = NewVerifier(Config{Type: VerificationCoverage, WorkingDir: dir, CoverageTolerance: -1})
+	assert.Error(t, err)
+
+	// Other verification types have no baseline
+	v, err = NewVerifier(Config{Type: VerificationTest, WorkingDir: dir, CustomCommand: "false"})
+	require.NoError(t, err)
+	require.NoError(t, v.MeasureBaseline())
+	assert.Nil(t, v.Baseline())
+}
+
+func TestVerifier_CoverageCommand(t *testing.T) {
+	tests := []struct {
+		buildFile string
+		command   string
+		report    string
+	}{
+		{"pom.xml", "mvn org.jacoco:jacoco-maven-plugin:prepare-agent test org.jacoco:jacoco-maven-plugin:report", jacocoMavenReport},
+		{"build.gradle", "gradle test jacocoTestReport", jacocoGradleReport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.buildFile, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tt.buildFile), []byte(""), 0644))
+			v, err := NewVerifier(Config{Type: VerificationCoverage, WorkingDir: dir})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.command, v.getVerificationCommand())
+			report, err := v.coverageReportPath()
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.report), report)
+		})
+	}
+
+	t.Run("go writes its profile to the session directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644))
+		v, err := NewVerifier(Config{Type: VerificationCoverage, WorkingDir: dir})
+		require.NoError(t, err)
+
+		report, err := v.coverageReportPath()
+		require.NoError(t, err)
+		assert.Equal(t, "go test -coverprofile="+report+" ./...", v.getVerificationCommand())
+		assert.DirExists(t, filepath.Dir(report))
+
+		require.NoError(t, v.Close())
+		assert.NoDirExists(t, filepath.Dir(report))
+	})
+}
@@ -0,0 +1,194 @@
+package verifier
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Session keeps the build environment warm across the verifications of one
+// run. Gradle builds use the Gradle daemon and Maven builds use the Maven
+// daemon (mvnd) when it is installed, so the JVM and build model are reused
+// instead of started for every verification, and with Config.Offline Maven
+// and Gradle resolve dependencies from their local caches only. A session is
+// shared by a verifier and the verifiers derived from it; Close stops the
+// daemons it used. The session also holds the baseline coverage of coverage
+// verification.
+type Session struct {
+	offline bool
+
+	mu       sync.Mutex
+	daemons  []daemon  // Gradle and Maven daemons to stop on Close
+	coverage *Coverage // Baseline coverage (nil = not measured)
+	dir      string    // Temporary directory for reports, removed on Close
+}
+
+// daemon is a build tool daemon used from dir
+type daemon struct {
+	executable string
+	dir        string
+}
+
+// lookPath finds the Maven daemon; replaced in tests
+var lookPath = exec.LookPath
+
+func newSession(config Config) *Session {
+	return &Session{offline: config.Offline}
+}
+
+// prepare adjusts the fields of a verification command run from dir to use
+// the session's warm environment. A plain mvn runs as mvnd when mvnd is on
+// the PATH; Maven wrappers are left alone since they pin the Maven version.
+// Commands that don't run Maven or Gradle are returned unchanged.
+func (s *Session) prepare(parts []string, dir string) []string {
+	if s == nil || len(parts) == 0 {
+		return parts
+	}
+
+	var flags []string
+	switch {
+	case isMaven(parts[0]):
+		if tool(parts[0]) == "mvn" {
+			if _, err := lookPath("mvnd"); err == nil {
+				parts = append([]string{"mvnd"}, parts[1:]...)
+			}
+		}
+		if tool(parts[0]) == "mvnd" {
+			s.useDaemon(daemon{executable: parts[0], dir: dir})
+		}
+		if s.offline && !hasArg(parts, "-o", "--offline") {
+			flags = append(flags, "-o")
+		}
+	case isGradle(parts[0]):
+		if !hasArg(parts, "--daemon", "--no-daemon") {
+			flags = append(flags, "--daemon")
+		}
+		if s.offline && !hasArg(parts, "--offline") {
+			flags = append(flags, "--offline")
+		}
+		if !hasArg(parts, "--no-daemon") {
+			s.useDaemon(daemon{executable: parts[0], dir: dir})
+		}
+	}
+	if len(flags) == 0 {
+		return parts
+	}
+
+	prepared := make([]string, 0, len(parts)+len(flags))
+	prepared = append(prepared, parts[0])
+	prepared = append(prepared, flags...)
+	return append(prepared, parts[1:]...)
+}
+
+func (s *Session) useDaemon(d daemon) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, used := range s.daemons {
+		if used == d {
+			return
+		}
+	}
+	s.daemons = append(s.daemons, d)
+}
+
+func (s *Session) baseline() *Coverage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coverage
+}
+
+func (s *Session) setBaseline(coverage Coverage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coverage = &coverage
+}
+
+// tempDir returns the session's temporary directory, creating it on first use
+func (s *Session) tempDir() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "kantra-ai-verify-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create verification directory: %w", err)
+		}
+		s.dir = dir
+	}
+	return s.dir, nil
+}
+
+// Close stops the daemons the session's verifications used and removes its
+// temporary directory
+func (s *Session) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	daemons := s.daemons
+	s.daemons = nil
+	dir := s.dir
+	s.dir = ""
+	s.mu.Unlock()
+
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
+
+	var failed []string
+	for _, d := range daemons {
+		cmd := exec.Command(d.executable, "--stop")
+		cmd.Dir = d.dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s --stop in %s: %v\n%s", d.executable, d.dir, err, strings.TrimSpace(string(output))))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to stop build daemons:\n%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// tool is the name of the build tool an executable runs, without its
+// directory or Windows script extension (/opt/maven/bin/mvn.cmd -> mvn)
+func tool(executable string) string {
+	name := filepath.Base(executable)
+	for _, ext := range []string{".cmd", ".bat", ".exe"} {
+		if trimmed, ok := strings.CutSuffix(strings.ToLower(name), ext); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+func isMaven(executable string) bool {
+	switch tool(executable) {
+	case "mvn", "mvnw", "mvnd":
+		return true
+	default:
+		return false
+	}
+}
+
+func isGradle(executable string) bool {
+	switch tool(executable) {
+	case "gradle", "gradlew":
+		return true
+	default:
+		return false
+	}
+}
+
+func hasArg(parts []string, args ...string) bool {
+	for _, part := range parts[1:] {
+		for _, arg := range args {
+			if part == arg {
+				return true
+			}
+		}
+	}
+	return false
+}
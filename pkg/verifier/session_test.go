@@ -0,0 +1,124 @@
+package verifier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMavenDaemon makes lookPath report mvnd as installed or not
+func stubMavenDaemon(t *testing.T, installed bool) {
+	original := lookPath
+	lookPath = func(file string) (string, error) {
+		if installed && file == "mvnd" {
+			return "/usr/bin/mvnd", nil
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+func TestSession_Prepare(t *testing.T) {
+	tests := []struct {
+		name     string
+		offline  bool
+		mvnd     bool
+		command  string
+		expected string
+	}{
+		{name: "maven online", command: "mvn test", expected: "mvn test"},
+		{name: "maven offline", offline: true, command: "mvn test -Dtest=com/example/*Test*", expected: "mvn -o test -Dtest=com/example/*Test*"},
+		{name: "maven wrapper offline", offline: true, command: "./mvnw verify", expected: "./mvnw -o verify"},
+		{name: "maven already offline", offline: true, command: "mvn --offline test", expected: "mvn --offline test"},
+		{name: "maven uses mvnd when installed", mvnd: true, command: "mvn test", expected: "mvnd test"},
+		{name: "maven daemon offline", offline: true, mvnd: true, command: "/opt/maven/bin/mvn verify", expected: "mvnd -o verify"},
+		{name: "maven wrapper keeps its version", mvnd: true, command: "./mvnw test", expected: "./mvnw test"},
+		{name: "windows maven wrapper offline", offline: true, command: "mvnw.cmd verify", expected: "mvnw.cmd -o verify"},
+		{name: "gradle uses the daemon", command: "gradle test", expected: "gradle --daemon test"},
+		{name: "gradle offline", offline: true, command: "./gradlew build -x test", expected: "./gradlew --daemon --offline build -x test"},
+		{name: "gradle daemon disabled", command: "gradle --no-daemon test", expected: "gradle --no-daemon test"},
+		{name: "gradle wrapper by absolute path", command: "/project/gradlew test", expected: "/project/gradlew --daemon test"},
+		{name: "windows gradle wrapper", command: "gradlew.bat build", expected: "gradlew.bat --daemon build"},
+		{name: "other commands are unchanged", offline: true, command: "go test ./...", expected: "go test ./..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubMavenDaemon(t, tt.mvnd)
+			s := newSession(Config{Offline: tt.offline})
+			prepared := s.prepare(strings.Fields(tt.command), "/project")
+			assert.Equal(t, tt.expected, strings.Join(prepared, " "))
+		})
+	}
+
+	t.Run("daemons are recorded once per directory", func(t *testing.T) {
+		stubMavenDaemon(t, false)
+		s := newSession(Config{})
+		s.prepare([]string{"gradle", "test"}, "/a")
+		s.prepare([]string{"gradle", "build"}, "/a")
+		s.prepare([]string{"./gradlew", "test"}, "/b")
+		s.prepare([]string{"gradle", "--no-daemon", "test"}, "/c")
+		s.prepare([]string{"mvn", "test"}, "/d")
+		assert.Equal(t, []daemon{{executable: "gradle", dir: "/a"}, {executable: "./gradlew", dir: "/b"}}, s.daemons)
+	})
+
+	t.Run("maven daemons are recorded", func(t *testing.T) {
+		stubMavenDaemon(t, true)
+		s := newSession(Config{})
+		s.prepare([]string{"mvn", "test"}, "/a")
+		s.prepare([]string{"mvnd", "verify"}, "/a")
+		s.prepare([]string{"./mvnw", "test"}, "/b")
+		assert.Equal(t, []daemon{{executable: "mvnd", dir: "/a"}}, s.daemons)
+	})
+}
+
+func TestVerifier_Session(t *testing.T) {
+	// A fake gradle records its arguments
+	binDir := t.TempDir()
+	calls := filepath.Join(binDir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "gradle"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "build.gradle"), []byte(""), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "service"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "service", "build.gradle"), []byte(""), 0644))
+
+	v, err := NewVerifier(Config{Type: VerificationTest, WorkingDir: root, Offline: true})
+	require.NoError(t, err)
+
+	result, err := v.Verify()
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "gradle --daemon --offline test", result.Command)
+
+	// Derived verifiers share the session
+	_, err = v.InModule("service").Verify()
+	require.NoError(t, err)
+
+	require.NoError(t, v.Close())
+	output, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "--daemon --offline test\n--daemon --offline test\n--stop\n--stop\n", string(output))
+
+	// Nothing is left to stop
+	require.NoError(t, v.Close())
+	output, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(output), "\n"))
+}
+
+func TestSession_CloseReportsFailures(t *testing.T) {
+	s := newSession(Config{})
+	s.prepare([]string{"./gradlew", "test"}, t.TempDir()) // No wrapper to run
+
+	err := s.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to stop build daemons")
+}
@@ -1,11 +1,14 @@
 package verifier
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
 	"time"
 )
 
@@ -19,6 +22,9 @@ const (
 	VerificationBuild
 	// VerificationTest runs the test suite
 	VerificationTest
+	// VerificationCoverage runs the test suite with coverage and fails fixes
+	// that reduce coverage below the baseline
+	VerificationCoverage
 )
 
 // VerificationStrategy defines when to run verification
@@ -41,7 +47,59 @@ type Config struct {
 	CustomCommand  string // Optional custom verification command
 	Timeout        time.Duration
 	FailFast       bool // Stop on first verification failure
-	SkipOnDryRun   bool // Skip verification in dry-run mode
+	DryRun         bool // Running in dry-run mode; verification is skipped unless VerifyOnDryRun is set
+	VerifyOnDryRun bool // Verify the (unchanged) working tree even in dry-run mode
+
+	// LanguageCommands maps a language (see LanguageForFile) to the command
+	// verifying changes to files in that language, e.g. "java": "mvn verify".
+	// Languages without an entry use CustomCommand or auto-detection.
+	LanguageCommands map[string]string
+
+	// WarningExitCodes are non-zero exit codes treated as success with a
+	// warning, for tools that exit non-zero on warnings
+	WarningExitCodes []int
+
+	// WarningPattern is a regular expression; a failing verification whose
+	// output matches it is treated as success with a warning
+	WarningPattern string
+
+	// Scope selects whether test verification runs every test or only the
+	// tests affected by the changed files (see ForChanges)
+	Scope VerificationScope
+
+	// AffectedCommand is a text/template for the affected-scope command,
+	// e.g. "make test FILES={{.ChangedFiles}}", for projects where the
+	// affected tests can't be inferred
+	AffectedCommand string
+
+	// Offline runs Maven (-o) and Gradle (--offline) without checking remote
+	// repositories, using only the dependencies already in their local caches
+	Offline bool
+
+	// CoverageReport is the coverage report the coverage verification command
+	// writes (Go cover profile, JaCoCo or Cobertura XML, or LCOV). Relative
+	// paths are from WorkingDir; empty uses the project type's default.
+	CoverageReport string
+
+	// CoverageBaseline is a coverage report to compare fixes against. If
+	// empty, the baseline is measured before the first fix (see MeasureBaseline).
+	CoverageBaseline string
+
+	// CoverageTolerance is the drop in coverage, in percentage points,
+	// allowed before a fix fails coverage verification
+	CoverageTolerance float64
+}
+
+// SkipReason explains why verification will not run, or returns "" if it will
+func (c Config) SkipReason() string {
+	switch {
+	case c.Type == VerificationNone:
+		return "verification is disabled"
+	case c.DryRun && !c.VerifyOnDryRun:
+		return "dry-run mode makes no changes to verify (use --verify-on-dry-run to verify anyway)"
+	default:
+		return ""
+	}
 }
 
 // Result represents the outcome of a verification run
@@ -52,12 +110,27 @@ type Result struct {
 	Duration  time.Duration
 	Command   string
 	Timestamp time.Time
+
+	Skipped    bool   // Verification did not run; Success is true
+	SkipReason string // Why verification was skipped
+
+	Warning       bool   // The command failed but the failure was downgraded to a warning; Success is true
+	WarningReason string // Why the failure was downgraded
+
+	Coverage         *Coverage // Coverage after the fixes (coverage verification only)
+	BaselineCoverage *Coverage // Coverage the fixes were compared against
 }
 
 // Verifier runs build/test verification after fixes
 type Verifier struct {
-	config      Config
-	projectType ProjectType
+	config         Config
+	projectType    ProjectType
+	warningPattern *regexp.Regexp // Compiled Config.WarningPattern (nil = none)
+
+	affectedCommand *template.Template // Parsed Config.AffectedCommand (nil = none)
+	changedFiles    []string           // Files the affected scope verifies, relative to the working directory
+
+	session *Session // Build environment shared with the verifiers derived from this one
 }
 
 // ProjectType represents the type of project being verified
@@ -81,23 +154,179 @@ func NewVerifier(config Config) (*Verifier, error) {
 			"    --verify=test")
 	}
 
+	if config.CoverageTolerance < 0 {
+		return nil, fmt.Errorf("coverage tolerance must not be negative: %g", config.CoverageTolerance)
+	}
+
 	if config.Timeout == 0 {
 		config.Timeout = 10 * time.Minute // Default timeout
 	}
 
 	projectType := detectProjectType(config.WorkingDir)
 
+	var warningPattern *regexp.Regexp
+	if config.WarningPattern != "" {
+		var err error
+		warningPattern, err = regexp.Compile(config.WarningPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification warning pattern '%s': %w", config.WarningPattern, err)
+		}
+	}
+
+	var affectedCommand *template.Template
+	if config.AffectedCommand != "" {
+		var err error
+		affectedCommand, err = parseAffectedCommand(config.AffectedCommand)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Verifier{
-		config:      config,
-		projectType: projectType,
+		config:          config,
+		projectType:     projectType,
+		warningPattern:  warningPattern,
+		affectedCommand: affectedCommand,
+		session:         newSession(config),
 	}, nil
 }
 
-// Verify runs the configured verification
+// Close ends the verifier's session, stopping the build daemons its
+// verifications used. It also ends the session of the verifiers derived
+// from it (InModule, ForLanguage, ForChanges).
+func (v *Verifier) Close() error {
+	return v.session.Close()
+}
+
+// InModule returns a verifier for the module in dir, relative to the working
+// directory, with the project type detected there. A custom command isn't
+// module-aware, so with one the verifier keeps running from the working
+// directory, as does coverage verification, which is compared against the
+// baseline of the whole project.
+func (v *Verifier) InModule(dir string) *Verifier {
+	if v.config.CustomCommand != "" || v.config.Type == VerificationCoverage || dir == "" || dir == "." {
+		return v
+	}
+
+	module := *v
+	module.config.WorkingDir = filepath.Join(v.config.WorkingDir, dir)
+	module.projectType = detectProjectType(module.config.WorkingDir)
+	return &module
+}
+
+// ForLanguage returns a verifier for changes to files in language within the
+// module in dir. If a command is configured for the language it runs from
+// the module directory; otherwise this is the same as InModule(dir). Language
+// commands don't apply to coverage verification.
+func (v *Verifier) ForLanguage(language, dir string) *Verifier {
+	command, ok := v.config.LanguageCommands[language]
+	if !ok || v.config.Type == VerificationCoverage {
+		return v.InModule(dir)
+	}
+
+	scoped := *v
+	scoped.config.CustomCommand = command
+	scoped.config.WorkingDir = filepath.Join(v.config.WorkingDir, dir)
+	scoped.projectType = detectProjectType(scoped.config.WorkingDir)
+	return &scoped
+}
+
+// HasLanguageCommand reports whether a command is configured for language
+func (c Config) HasLanguageCommand(language string) bool {
+	_, ok := c.LanguageCommands[language]
+	return ok
+}
+
+// LanguageForFile detects the language of a file from its extension, for
+// selecting a LanguageCommands entry. It returns "" for unknown extensions.
+func LanguageForFile(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".java":
+		return "java"
+	case ".kt", ".kts":
+		return "kotlin"
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return "javascript"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".rb":
+		return "ruby"
+	case ".cs":
+		return "csharp"
+	case ".xml":
+		return "xml"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".properties":
+		return "properties"
+	default:
+		return ""
+	}
+}
+
+// ModuleDir returns the module containing file as a directory relative to
+// root: the closest directory with a build file (go.mod, pom.xml,
+// build.gradle or package.json). Files outside any nested module belong to root (".").
+func ModuleDir(root, file string) string {
+	rel := filepath.Clean(file)
+	if filepath.IsAbs(rel) {
+		r, err := filepath.Rel(root, rel)
+		if err != nil {
+			return "."
+		}
+		rel = r
+	}
+
+	for dir := filepath.Dir(rel); dir != "." && dir != string(filepath.Separator) && !strings.HasPrefix(dir, ".."); dir = filepath.Dir(dir) {
+		if detectProjectType(filepath.Join(root, dir)) != ProjectUnknown {
+			return dir
+		}
+	}
+	return "."
+}
+
+// SetDryRun updates whether the verifier runs in dry-run mode
+func (v *Verifier) SetDryRun(dryRun bool) {
+	v.config.DryRun = dryRun
+}
+
+// Verify runs the configured verification. In dry-run mode it returns a
+// skipped result without running anything unless VerifyOnDryRun is set.
+// Coverage verification also fails if coverage dropped below the baseline.
 func (v *Verifier) Verify() (*Result, error) {
+	if reason := v.config.SkipReason(); reason != "" {
+		return &Result{
+			Success:    true,
+			Skipped:    true,
+			SkipReason: reason,
+			Timestamp:  time.Now(),
+		}, nil
+	}
+
+	result, err := v.run()
+	if err != nil {
+		return nil, err
+	}
+	if result.Success && v.config.Type == VerificationCoverage {
+		if err := v.checkCoverage(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// run runs the verification command
+func (v *Verifier) run() (*Result, error) {
 	start := time.Now()
 
-	command := v.getVerificationCommand()
+	command, err := v.command()
+	if err != nil {
+		return nil, err
+	}
 	if command == "" {
 		return nil, fmt.Errorf("no verification command available for project type: %s\n\n"+
 			"Supported project types:\n"+
@@ -122,6 +351,8 @@ func (v *Verifier) Verify() (*Result, error) {
 	if len(parts) == 0 {
 		return nil, fmt.Errorf("invalid verification command: %s", command)
 	}
+	parts = v.session.prepare(parts, v.config.WorkingDir)
+	result.Command = strings.Join(parts, " ")
 
 	cmd := exec.Command(parts[0], parts[1:]...)
 	cmd.Dir = v.config.WorkingDir
@@ -132,6 +363,12 @@ func (v *Verifier) Verify() (*Result, error) {
 	result.Duration = time.Since(start)
 
 	if err != nil {
+		if reason := v.warningReason(err, result.Output); reason != "" {
+			result.Success = true
+			result.Warning = true
+			result.WarningReason = reason
+			return result, nil
+		}
 		result.Success = false
 		result.Error = fmt.Errorf("verification failed: %w", err)
 		return result, nil
@@ -141,6 +378,36 @@ func (v *Verifier) Verify() (*Result, error) {
 	return result, nil
 }
 
+// warningReason explains why a failed command counts as success with a
+// warning, or returns "" if it is a real failure
+func (v *Verifier) warningReason(err error, output string) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		for _, warningCode := range v.config.WarningExitCodes {
+			if code == warningCode {
+				return fmt.Sprintf("exit code %d is configured as a warning", code)
+			}
+		}
+	}
+
+	if v.warningPattern != nil {
+		if match := v.warningPattern.FindString(output); match != "" {
+			return fmt.Sprintf("output matches warning pattern: %s", match)
+		}
+	}
+	return ""
+}
+
+// command returns the command to run: the affected tests if they can be
+// determined, otherwise the verification command for the whole project
+func (v *Verifier) command() (string, error) {
+	if command, err := v.affectedTestCommand(); command != "" || err != nil {
+		return command, err
+	}
+	return v.getVerificationCommand(), nil
+}
+
 // getVerificationCommand returns the appropriate verification command
 func (v *Verifier) getVerificationCommand() string {
 	// Use custom command if provided
@@ -148,6 +415,10 @@ func (v *Verifier) getVerificationCommand() string {
 		return v.config.CustomCommand
 	}
 
+	if v.config.Type == VerificationCoverage {
+		return v.getCoverageCommand()
+	}
+
 	// Determine command based on project type and verification type
 	switch v.projectType {
 	case ProjectGo:
@@ -258,6 +529,42 @@ func (pt ProjectType) String() string {
 	}
 }
 
+// String returns the verification type's command-line name
+func (t VerificationType) String() string {
+	switch t {
+	case VerificationBuild:
+		return "build"
+	case VerificationTest:
+		return "test"
+	case VerificationCoverage:
+		return "coverage"
+	default:
+		return "none"
+	}
+}
+
+// String returns the verification strategy's command-line name
+func (s VerificationStrategy) String() string {
+	switch s {
+	case StrategyPerFix:
+		return "per-fix"
+	case StrategyPerViolation:
+		return "per-violation"
+	default:
+		return "at-end"
+	}
+}
+
+// TypeNames returns the command-line names of the verification types
+func TypeNames() []string {
+	return []string{VerificationBuild.String(), VerificationTest.String(), VerificationCoverage.String()}
+}
+
+// StrategyNames returns the command-line names of the verification strategies
+func StrategyNames() []string {
+	return []string{StrategyPerFix.String(), StrategyPerViolation.String(), StrategyAtEnd.String()}
+}
+
 // ParseVerificationType parses a string into a VerificationType
 func ParseVerificationType(s string) (VerificationType, error) {
 	switch strings.ToLower(s) {
@@ -265,10 +572,12 @@ func ParseVerificationType(s string) (VerificationType, error) {
 		return VerificationBuild, nil
 	case "test", "tests":
 		return VerificationTest, nil
+	case "coverage":
+		return VerificationCoverage, nil
 	case "none", "":
 		return VerificationNone, nil
 	default:
-		return VerificationNone, fmt.Errorf("invalid verification type: %s (valid: build, test, none)", s)
+		return VerificationNone, fmt.Errorf("invalid verification type: %s (valid: build, test, coverage, none)", s)
 	}
 }
 
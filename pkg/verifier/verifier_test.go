@@ -239,6 +239,81 @@ func TestVerifier_CustomCommand(t *testing.T) {
 	assert.Equal(t, "make test", got)
 }
 
+func TestModuleDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "orders", "src", "main"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "services", "orders", "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "web", "package.json"), []byte("{}"), 0644))
+
+	assert.Equal(t, filepath.Join("services", "orders"), ModuleDir(root, "services/orders/src/main/App.java"))
+	assert.Equal(t, filepath.Join("services", "orders"), ModuleDir(root, filepath.Join(root, "services/orders/pom.xml")))
+	assert.Equal(t, "web", ModuleDir(root, "web/index.js"))
+	assert.Equal(t, ".", ModuleDir(root, "shared/Util.java"))
+	assert.Equal(t, ".", ModuleDir(root, "README.md"))
+	assert.Equal(t, ".", ModuleDir(root, "../outside/File.java"))
+}
+
+func TestVerifier_InModule(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "web", "package.json"), []byte("{}"), 0644))
+
+	v, err := NewVerifier(Config{Type: VerificationTest, WorkingDir: root})
+	require.NoError(t, err)
+
+	web := v.InModule("web")
+	assert.Equal(t, filepath.Join(root, "web"), web.config.WorkingDir)
+	assert.Equal(t, "npm test", web.getVerificationCommand())
+	assert.Equal(t, "mvn test", v.getVerificationCommand(), "original verifier is unchanged")
+	assert.Same(t, v, v.InModule("."))
+
+	custom, err := NewVerifier(Config{Type: VerificationTest, WorkingDir: root, CustomCommand: "make test"})
+	require.NoError(t, err)
+	assert.Same(t, custom, custom.InModule("web"))
+}
+
+func TestVerifier_ForLanguage(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tools"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tools", "go.mod"), []byte("module tools\n"), 0644))
+
+	v, err := NewVerifier(Config{
+		Type:             VerificationBuild,
+		WorkingDir:       root,
+		CustomCommand:    "make check",
+		LanguageCommands: map[string]string{"java": "mvn verify", "go": "go vet ./..."},
+	})
+	require.NoError(t, err)
+
+	java := v.ForLanguage("java", ".")
+	assert.Equal(t, "mvn verify", java.getVerificationCommand())
+	assert.Equal(t, root, java.config.WorkingDir)
+
+	goTools := v.ForLanguage("go", "tools")
+	assert.Equal(t, "go vet ./...", goTools.getVerificationCommand())
+	assert.Equal(t, filepath.Join(root, "tools"), goTools.config.WorkingDir)
+
+	// No mapping: fall back to the custom command
+	assert.Same(t, v, v.ForLanguage("python", "tools"))
+	assert.Equal(t, "make check", v.getVerificationCommand())
+}
+
+func TestLanguageForFile(t *testing.T) {
+	assert.Equal(t, "java", LanguageForFile("src/main/java/App.java"))
+	assert.Equal(t, "go", LanguageForFile("cmd/main.go"))
+	assert.Equal(t, "typescript", LanguageForFile("web/App.TSX"))
+	assert.Equal(t, "yaml", LanguageForFile("config/app.yml"))
+	assert.Equal(t, "", LanguageForFile("Makefile"))
+
+	config := Config{LanguageCommands: map[string]string{"java": "mvn verify"}}
+	assert.True(t, config.HasLanguageCommand("java"))
+	assert.False(t, config.HasLanguageCommand(""))
+}
+
 func TestVerifier_Verify(t *testing.T) {
 	t.Run("successful verification", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -293,6 +368,116 @@ func TestVerifier_Verify(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no verification command available")
 	})
+
+	t.Run("dry-run skips verification", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		marker := filepath.Join(tmpDir, "ran")
+
+		config := Config{
+			Type:          VerificationBuild,
+			WorkingDir:    tmpDir,
+			CustomCommand: "touch " + marker,
+			DryRun:        true,
+		}
+
+		verifier, err := NewVerifier(config)
+		require.NoError(t, err)
+
+		result, err := verifier.Verify()
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.True(t, result.Skipped)
+		assert.Contains(t, result.SkipReason, "dry-run")
+		assert.NoFileExists(t, marker)
+	})
+
+	t.Run("dry-run override runs verification", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		marker := filepath.Join(tmpDir, "ran")
+
+		config := Config{
+			Type:           VerificationBuild,
+			WorkingDir:     tmpDir,
+			CustomCommand:  "touch " + marker,
+			DryRun:         true,
+			VerifyOnDryRun: true,
+		}
+
+		verifier, err := NewVerifier(config)
+		require.NoError(t, err)
+
+		result, err := verifier.Verify()
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.False(t, result.Skipped)
+		assert.FileExists(t, marker)
+	})
+}
+
+func TestVerifier_Verify_Warnings(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := "echo 'WARNING: deprecated API used'\nexit ${1:-2}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "build.sh"), []byte(script), 0755))
+
+	verify := func(config Config) *Result {
+		config.Type = VerificationBuild
+		config.WorkingDir = tmpDir
+		if config.CustomCommand == "" {
+			config.CustomCommand = "sh build.sh"
+		}
+		v, err := NewVerifier(config)
+		require.NoError(t, err)
+		result, err := v.Verify()
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("warning exit code is success with warning", func(t *testing.T) {
+		result := verify(Config{WarningExitCodes: []int{2}})
+		assert.True(t, result.Success)
+		assert.True(t, result.Warning)
+		assert.Contains(t, result.WarningReason, "exit code 2")
+		assert.Nil(t, result.Error)
+	})
+
+	t.Run("other exit codes still fail", func(t *testing.T) {
+		result := verify(Config{CustomCommand: "sh build.sh 1", WarningExitCodes: []int{2}})
+		assert.False(t, result.Success)
+		assert.False(t, result.Warning)
+		assert.Error(t, result.Error)
+	})
+
+	t.Run("output matching the warning pattern is success with warning", func(t *testing.T) {
+		result := verify(Config{WarningPattern: `WARNING: deprecated`})
+		assert.True(t, result.Success)
+		assert.True(t, result.Warning)
+		assert.Contains(t, result.WarningReason, "WARNING: deprecated")
+	})
+
+	t.Run("output not matching the pattern fails", func(t *testing.T) {
+		result := verify(Config{WarningPattern: `^\[WARN\]`})
+		assert.False(t, result.Success)
+		assert.False(t, result.Warning)
+	})
+
+	t.Run("successful commands have no warning", func(t *testing.T) {
+		result := verify(Config{CustomCommand: "sh build.sh 0", WarningExitCodes: []int{2}})
+		assert.True(t, result.Success)
+		assert.False(t, result.Warning)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := NewVerifier(Config{WorkingDir: tmpDir, WarningPattern: "("})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid verification warning pattern")
+	})
+}
+
+func TestConfig_SkipReason(t *testing.T) {
+	assert.Empty(t, Config{Type: VerificationBuild}.SkipReason())
+	assert.Empty(t, Config{Type: VerificationBuild, DryRun: true, VerifyOnDryRun: true}.SkipReason())
+	assert.Contains(t, Config{Type: VerificationBuild, DryRun: true}.SkipReason(), "dry-run")
+	assert.NotEmpty(t, Config{Type: VerificationNone}.SkipReason())
 }
 
 func TestProjectTypeString(t *testing.T) {
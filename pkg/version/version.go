@@ -0,0 +1,12 @@
+// Package version identifies this build of kantra-ai
+package version
+
+// Version is the release version, set at build time with
+// -ldflags "-X github.com/tsanders/kantra-ai/pkg/version.Version=v1.2.3"
+var Version = "dev"
+
+// UserAgent returns the default User-Agent sent with outbound HTTP requests,
+// to AI providers and the GitHub API: kantra-ai/<version>
+func UserAgent() string {
+	return "kantra-ai/" + Version
+}
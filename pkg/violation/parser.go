@@ -2,15 +2,126 @@ package violation
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// LoadAnalysis loads and parses a Konveyor output.yaml file.
-// It supports both native Kantra format (array of rulesets) and simplified format (violations array).
+// StdinPath is the analysis path that reads the analysis from standard input
+const StdinPath = "-"
+
+// stdin is the reader used for StdinPath (replaceable in tests)
+var stdin io.Reader = os.Stdin
+
+// categorySeverity ranks categories for merging; higher is more severe
+var categorySeverity = map[string]int{
+	"potential": 1,
+	"optional":  2,
+	"mandatory": 3,
+}
+
+// LoadAnalysis loads and parses Konveyor output.yaml files.
+// It supports both native Kantra format (array of rulesets) and simplified format (violations array),
+// as well as SARIF logs, detected by a .sarif/.sarif.json extension or by their content.
+//
+// analysisPath may be a comma-separated list of files or directories, and "-"
+// reads the analysis from stdin. Multiple analyses are merged with MergeAnalyses.
 func LoadAnalysis(analysisPath string) (*Analysis, error) {
+	paths := SplitAnalysisPaths(analysisPath)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no analysis path given")
+	}
+
+	analyses := make([]*Analysis, 0, len(paths))
+	readStdin := false
+	for _, path := range paths {
+		if path == StdinPath {
+			if readStdin {
+				return nil, fmt.Errorf("analysis can only be read from stdin ('-') once")
+			}
+			readStdin = true
+		}
+		analysis, err := loadAnalysisFile(path)
+		if err != nil {
+			return nil, err
+		}
+		analyses = append(analyses, analysis)
+	}
+
+	if len(analyses) == 1 {
+		return analyses[0], nil
+	}
+	return MergeAnalyses(analyses...), nil
+}
+
+// SplitAnalysisPaths splits a comma-separated --analysis value into paths
+func SplitAnalysisPaths(analysisPath string) []string {
+	var paths []string
+	for _, path := range strings.Split(analysisPath, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// MergeAnalyses merges violations from several analyses, e.g. the per-provider
+// output.yaml files Konveyor produces for multi-language applications.
+// Violations with the same rule ID are combined and their incidents deduplicated
+// by URI and line number. Conflicting categories resolve to the most severe
+// (mandatory > optional > potential). Violations keep the order they first appear in.
+func MergeAnalyses(analyses ...*Analysis) *Analysis {
+	merged := &Analysis{
+		Violations: []Violation{},
+	}
+	index := make(map[string]int)
+	seen := make(map[string]map[string]bool)
+
+	for _, analysis := range analyses {
+		if analysis == nil {
+			continue
+		}
+		for _, v := range analysis.Violations {
+			i, ok := index[v.ID]
+			if !ok {
+				i = len(merged.Violations)
+				index[v.ID] = i
+				seen[v.ID] = make(map[string]bool)
+				first := v
+				first.Incidents = make([]Incident, 0, len(v.Incidents))
+				merged.Violations = append(merged.Violations, first)
+			} else if categorySeverity[v.Category] > categorySeverity[merged.Violations[i].Category] {
+				merged.Violations[i].Category = v.Category
+			}
+
+			target := &merged.Violations[i]
+			for _, incident := range v.Incidents {
+				key := fmt.Sprintf("%s:%d", incident.URI, incident.LineNumber)
+				if seen[v.ID][key] {
+					continue
+				}
+				seen[v.ID][key] = true
+				target.Incidents = append(target.Incidents, incident)
+			}
+		}
+	}
+
+	return merged
+}
+
+// loadAnalysisFile loads a single analysis file, directory or stdin ("-")
+func loadAnalysisFile(analysisPath string) (*Analysis, error) {
+	if analysisPath == StdinPath {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read analysis from stdin: %w", err)
+		}
+		return parseAnalysis(data, "stdin")
+	}
+
 	// Check if path is a directory (contains output.yaml) or direct file path
 	path := analysisPath
 	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
@@ -23,12 +134,22 @@ func LoadAnalysis(analysisPath string) (*Analysis, error) {
 			"Please verify:\n"+
 			"  1. The file exists and path is correct\n"+
 			"  2. You have read permissions: chmod +r %s\n"+
-			"  3. The file is a Konveyor analysis output (output.yaml)\n\n"+
+			"  3. The file is a Konveyor analysis output (output.yaml) or a SARIF log\n\n"+
 			"To generate analysis output:\n"+
 			"  kantra analyze --input=./your-app --output=./analysis",
 			path, err, path)
 	}
 
+	return parseAnalysis(data, path)
+}
+
+// parseAnalysis parses analysis YAML or SARIF; source names the input in error messages
+func parseAnalysis(data []byte, source string) (*Analysis, error) {
+	// SARIF is JSON, which also parses as YAML, so it's detected first
+	if IsSARIFPath(source) || looksLikeSARIF(data) {
+		return parseSARIF(data, source)
+	}
+
 	// Try to parse as native Kantra format first (array of rulesets)
 	var nativeRulesets []NativeKantraRuleset
 	if err := yaml.Unmarshal(data, &nativeRulesets); err == nil && len(nativeRulesets) > 0 {
@@ -47,8 +168,9 @@ func LoadAnalysis(analysisPath string) (*Analysis, error) {
 			"  3. You're using a compatible version of Konveyor\n\n"+
 			"Supported formats:\n"+
 			"  1. Native Kantra format (array of rulesets)\n"+
-			"  2. Simplified format (violations array)",
-			path, err)
+			"  2. Simplified format (violations array)\n"+
+			"  3. SARIF 2.1.0 (.sarif or .sarif.json)",
+			source, err)
 	}
 
 	return &analysis, nil
@@ -3,6 +3,7 @@ package violation
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,6 +58,36 @@ func TestLoadAnalysis(t *testing.T) {
 	})
 }
 
+func TestLoadAnalysis_IncidentColumns(t *testing.T) {
+	data := `violations:
+  - id: javax-to-jakarta
+    category: mandatory
+    incidents:
+      - uri: file:///src/A.java
+        lineNumber: 3
+        column: 8
+      - uri: file:///src/B.java
+        lineNumber: 5
+        codeLocation:
+          startPosition: {line: 4, character: 11}
+          endPosition: {line: 4, character: 20}
+      - uri: file:///src/C.java
+        lineNumber: 9
+`
+	path := filepath.Join(t.TempDir(), "output.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	analysis, err := LoadAnalysis(path)
+	require.NoError(t, err)
+	require.Len(t, analysis.Violations, 1)
+	incidents := analysis.Violations[0].Incidents
+	require.Len(t, incidents, 3)
+
+	assert.Equal(t, 8, incidents[0].GetColumn())
+	assert.Equal(t, 12, incidents[1].GetColumn(), "codeLocation characters are zero-based")
+	assert.Equal(t, 0, incidents[2].GetColumn(), "column is 0 when absent")
+}
+
 func TestAnalysis_FilterViolations(t *testing.T) {
 	// Load test data
 	analysis, err := LoadAnalysis("testdata/valid_analysis.yaml")
@@ -121,3 +152,85 @@ func TestAnalysis_FilterViolations(t *testing.T) {
 		assert.Len(t, filtered, 0)
 	})
 }
+
+func TestLoadAnalysis_MultiplePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	javaPath := filepath.Join(tmpDir, "java.yaml")
+	require.NoError(t, os.WriteFile(javaPath, []byte(`violations:
+  - id: rule-1
+    category: optional
+    incidents:
+      - uri: file:///src/A.java
+        lineNumber: 10
+      - uri: file:///src/A.java
+        lineNumber: 20
+  - id: rule-2
+    category: potential
+    incidents:
+      - uri: file:///src/B.java
+        lineNumber: 1
+`), 0644))
+	goPath := filepath.Join(tmpDir, "go.yaml")
+	require.NoError(t, os.WriteFile(goPath, []byte(`violations:
+  - id: rule-1
+    category: mandatory
+    incidents:
+      - uri: file:///src/A.java
+        lineNumber: 10
+      - uri: file:///src/C.java
+        lineNumber: 5
+`), 0644))
+
+	analysis, err := LoadAnalysis(javaPath + ", " + goPath)
+	require.NoError(t, err)
+	require.Len(t, analysis.Violations, 2)
+
+	rule1 := analysis.Violations[0]
+	assert.Equal(t, "rule-1", rule1.ID)
+	assert.Equal(t, "mandatory", rule1.Category, "most severe category wins")
+	require.Len(t, rule1.Incidents, 3, "duplicate incident is dropped")
+	assert.Equal(t, "file:///src/A.java", rule1.Incidents[0].URI)
+	assert.Equal(t, 20, rule1.Incidents[1].LineNumber)
+	assert.Equal(t, "file:///src/C.java", rule1.Incidents[2].URI)
+
+	assert.Equal(t, "rule-2", analysis.Violations[1].ID)
+	assert.Equal(t, "potential", analysis.Violations[1].Category)
+
+	t.Run("missing file in list", func(t *testing.T) {
+		_, err := LoadAnalysis(javaPath + ",testdata/nonexistent.yaml")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read analysis file")
+	})
+}
+
+func TestLoadAnalysis_Stdin(t *testing.T) {
+	original := stdin
+	defer func() { stdin = original }()
+
+	stdin = strings.NewReader(`violations:
+  - id: rule-1
+    category: optional
+    incidents:
+      - uri: file:///src/A.java
+        lineNumber: 10
+`)
+	analysis, err := LoadAnalysis("-")
+	require.NoError(t, err)
+	require.Len(t, analysis.Violations, 1)
+	assert.Equal(t, "rule-1", analysis.Violations[0].ID)
+
+	_, err = LoadAnalysis("-,-")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "stdin")
+}
+
+func TestMergeAnalyses_CategorySeverity(t *testing.T) {
+	merged := MergeAnalyses(
+		&Analysis{Violations: []Violation{{ID: "r", Category: "potential"}}},
+		&Analysis{Violations: []Violation{{ID: "r", Category: "mandatory"}}},
+		&Analysis{Violations: []Violation{{ID: "r", Category: "optional"}}},
+		nil,
+	)
+	require.Len(t, merged.Violations, 1)
+	assert.Equal(t, "mandatory", merged.Violations[0].Category)
+}
@@ -0,0 +1,260 @@
+package violation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ContainerSourceDir is where kantra mounts the source in its container.
+// Incidents of a containerized analysis have paths below it.
+const ContainerSourceDir = "/opt/input/source"
+
+// PathFilter selects incidents by file path using glob patterns evaluated
+// relative to the input directory. Patterns use forward slashes and support
+// "**" to match any number of directories (e.g. "src/main/java/**").
+type PathFilter struct {
+	BaseDir string   // Directory patterns are relative to (--input)
+	Include []string // Keep only incidents matching one of these (empty = all)
+	Exclude []string // Drop incidents matching one of these
+
+	// Files, if non-nil, keeps only incidents in exactly these files
+	// (slash-separated, relative to BaseDir). An empty set matches nothing.
+	Files map[string]bool
+
+	// MaxDepth drops incidents in files nested deeper than this below
+	// BaseDir (0 = no limit). Depth counts like find -maxdepth: files
+	// directly in BaseDir have depth 1, "src/App.java" has depth 2.
+	MaxDepth int
+}
+
+// NewPathFilter creates a path filter and validates its patterns. files
+// restricts incidents to an explicit list of files (see ReadFileList); nil
+// means no restriction. maxDepth limits how deeply nested files may be (0 =
+// no limit). It returns nil if there is nothing to filter on.
+func NewPathFilter(baseDir string, include, exclude, files []string, maxDepth int) (*PathFilter, error) {
+	if maxDepth < 0 {
+		return nil, fmt.Errorf("invalid maximum path depth %d: must be positive (0 = no limit)", maxDepth)
+	}
+	if len(include) == 0 && len(exclude) == 0 && files == nil && maxDepth == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern '%s': %w", pattern, err)
+		}
+	}
+	filter := &PathFilter{
+		BaseDir:  baseDir,
+		Include:  include,
+		Exclude:  exclude,
+		MaxDepth: maxDepth,
+	}
+	if files != nil {
+		filter.Files = make(map[string]bool, len(files))
+		for _, file := range files {
+			filter.Files[filter.relativePath(file)] = true
+		}
+	}
+	return filter, nil
+}
+
+// ReadFileList reads newline-separated file paths, such as the output of
+// `git diff --name-only`. Blank lines are ignored. The result is non-nil even
+// if the list is empty.
+func ReadFileList(r io.Reader) ([]string, error) {
+	files := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
+	}
+	return files, nil
+}
+
+// IntersectFileLists returns the files of list that are also in other, both
+// relative to baseDir or absolute. A nil list means no restriction, so other
+// is returned. The result is non-nil even if no file is in both.
+func IntersectFileLists(baseDir string, list, other []string) []string {
+	if list == nil {
+		return other
+	}
+	normalize := &PathFilter{BaseDir: baseDir}
+	inOther := make(map[string]bool, len(other))
+	for _, file := range other {
+		inOther[normalize.relativePath(file)] = true
+	}
+	files := []string{}
+	for _, file := range list {
+		if inOther[normalize.relativePath(file)] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Match reports whether the file passes the filter
+func (f *PathFilter) Match(filePath string) bool {
+	rel := f.relativePath(filePath)
+
+	if f.Files != nil && !f.inFiles(rel) {
+		return false
+	}
+
+	if f.MaxDepth > 0 && PathDepth(rel) > f.MaxDepth {
+		return false
+	}
+
+	if len(f.Include) > 0 {
+		included := false
+		for _, pattern := range f.Include {
+			if MatchGlob(pattern, rel) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, pattern := range f.Exclude {
+		if MatchGlob(pattern, rel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inFiles reports whether rel is one of Files. A path outside BaseDir, such
+// as an absolute path from an analysis run elsewhere
+// (/workspace/app/src/App.java), matches a listed file it ends with
+// (src/App.java).
+func (f *PathFilter) inFiles(rel string) bool {
+	if f.Files[rel] {
+		return true
+	}
+	if !path.IsAbs(rel) {
+		return false
+	}
+	for file := range f.Files {
+		if strings.HasSuffix(rel, "/"+file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply drops incidents whose file doesn't pass the filter and removes
+// violations left without incidents. The input slice is not modified.
+func (f *PathFilter) Apply(violations []Violation) []Violation {
+	if f == nil {
+		return violations
+	}
+
+	var filtered []Violation
+	for _, v := range violations {
+		incidents := make([]Incident, 0, len(v.Incidents))
+		for _, incident := range v.Incidents {
+			if f.Match(incident.GetFilePath()) {
+				incidents = append(incidents, incident)
+			}
+		}
+		if len(incidents) == 0 {
+			continue
+		}
+		v.Incidents = incidents
+		filtered = append(filtered, v)
+	}
+	return filtered
+}
+
+// relativePath returns filePath relative to BaseDir in slash form. Paths
+// outside BaseDir are returned unchanged, except container paths, which
+// are made relative (see ContainerRelativePath).
+func (f *PathFilter) relativePath(filePath string) string {
+	if f.BaseDir != "" && filepath.IsAbs(filePath) {
+		if rel, err := filepath.Rel(f.BaseDir, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+			filePath = rel
+		}
+	}
+	filePath = filepath.ToSlash(filePath)
+	if rel, ok := ContainerRelativePath(filePath); ok {
+		return rel
+	}
+	return strings.TrimPrefix(filePath, "./")
+}
+
+// ContainerRelativePath returns a slash-separated path below
+// ContainerSourceDir relative to it, e.g. "src/App.java" for
+// /opt/input/source/src/App.java. It returns false for other paths.
+func ContainerRelativePath(filePath string) (string, bool) {
+	rel, ok := strings.CutPrefix(filePath, ContainerSourceDir+"/")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimLeft(rel, "/"), true
+}
+
+// PathDepth returns the number of segments of a slash-separated relative
+// path, e.g. 2 for "src/App.java"
+func PathDepth(rel string) int {
+	depth := 0
+	for _, segment := range strings.Split(rel, "/") {
+		if segment != "" && segment != "." {
+			depth++
+		}
+	}
+	return depth
+}
+
+// MatchGlob matches a slash-separated path against a glob pattern.
+// In addition to path.Match syntax, a "**" segment matches zero or more
+// directories, and a pattern ending in "/" matches everything below it.
+func MatchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches pattern segments against path segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive ** and try every possible split
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,221 @@
+package violation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"src/main/java/**", "src/main/java/com/example/App.java", true},
+		{"src/main/java/**", "src/test/java/com/example/AppTest.java", false},
+		{"src/main/java/", "src/main/java/App.java", true},
+		{"**/*Test.java", "src/test/java/com/example/AppTest.java", true},
+		{"**/*Test.java", "AppTest.java", true},
+		{"**/generated/**", "target/generated/sources/Foo.java", true},
+		{"*.java", "src/App.java", false},
+		{"src/*/App.java", "src/main/App.java", true},
+		{"./src/**", "src/App.java", true},
+		{"src/**/model/*.java", "src/main/java/model/User.java", true},
+		{"src/**/model/*.java", "src/model/User.java", true},
+		{"src/**/model/*.java", "src/main/java/model/sub/User.java", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestNewPathFilter(t *testing.T) {
+	filter, err := NewPathFilter("/app", nil, nil, nil, 0)
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = NewPathFilter("/app", []string{"src/[a-"}, nil, nil, 0)
+	assert.Error(t, err)
+
+	_, err = NewPathFilter("/app", nil, nil, nil, -1)
+	assert.Error(t, err)
+}
+
+func TestPathFilter_MaxDepth(t *testing.T) {
+	violations := []Violation{
+		{
+			ID: "v1",
+			Incidents: []Incident{
+				{URI: "file:///app/pom.xml", LineNumber: 1},
+				{URI: "file:///app/src/App.java", LineNumber: 2},
+				{URI: "file:///app/src/main/java/com/example/App.java", LineNumber: 3},
+			},
+		},
+		{
+			ID: "v2",
+			Incidents: []Incident{
+				{URI: "file:///app/target/generated-sources/annotations/com/example/Stub.java", LineNumber: 4},
+			},
+		},
+	}
+
+	filter, err := NewPathFilter("/app", nil, nil, nil, 2)
+	require.NoError(t, err)
+
+	filtered := filter.Apply(violations)
+	require.Len(t, filtered, 1, "violations with only deep incidents are removed")
+	assert.Equal(t, "v1", filtered[0].ID)
+	require.Len(t, filtered[0].Incidents, 2)
+	assert.Equal(t, 1, filtered[0].Incidents[0].LineNumber)
+	assert.Equal(t, 2, filtered[0].Incidents[1].LineNumber)
+
+	t.Run("combined with globs", func(t *testing.T) {
+		filter, err := NewPathFilter("/app", []string{"src/**"}, nil, nil, 5)
+		require.NoError(t, err)
+
+		filtered := filter.Apply(violations)
+		require.Len(t, filtered, 1)
+		require.Len(t, filtered[0].Incidents, 1)
+		assert.Equal(t, 2, filtered[0].Incidents[0].LineNumber)
+	})
+}
+
+func TestPathDepth(t *testing.T) {
+	assert.Equal(t, 1, PathDepth("pom.xml"))
+	assert.Equal(t, 2, PathDepth("src/App.java"))
+	assert.Equal(t, 2, PathDepth("./src/App.java"))
+	assert.Equal(t, 6, PathDepth("src/main/java/com/example/App.java"))
+}
+
+func TestPathFilter_Apply(t *testing.T) {
+	violations := []Violation{
+		{
+			ID: "v1",
+			Incidents: []Incident{
+				{URI: "file:///app/src/main/java/App.java", LineNumber: 1},
+				{URI: "file:///app/src/test/java/AppTest.java", LineNumber: 2},
+				{URI: "file:///app/src/main/java/generated/Stub.java", LineNumber: 3},
+			},
+		},
+		{
+			ID: "v2",
+			Incidents: []Incident{
+				{URI: "file:///app/src/test/java/OtherTest.java", LineNumber: 4},
+			},
+		},
+	}
+
+	filter, err := NewPathFilter("/app", []string{"src/main/java/**"}, []string{"**/generated/**"}, nil, 0)
+	require.NoError(t, err)
+
+	filtered := filter.Apply(violations)
+
+	require.Len(t, filtered, 1, "violations without matching incidents are removed")
+	assert.Equal(t, "v1", filtered[0].ID)
+	require.Len(t, filtered[0].Incidents, 1)
+	assert.Equal(t, 1, filtered[0].Incidents[0].LineNumber)
+
+	// The input is not modified
+	assert.Len(t, violations[0].Incidents, 3)
+
+	t.Run("exclude only", func(t *testing.T) {
+		filter, err := NewPathFilter("/app", nil, []string{"src/test/**"}, nil, 0)
+		require.NoError(t, err)
+
+		filtered := filter.Apply(violations)
+		require.Len(t, filtered, 1)
+		assert.Len(t, filtered[0].Incidents, 2)
+	})
+
+	t.Run("nil filter keeps everything", func(t *testing.T) {
+		var filter *PathFilter
+		assert.Equal(t, violations, filter.Apply(violations))
+	})
+
+	t.Run("file list from reader", func(t *testing.T) {
+		// As produced by `git diff --name-only`
+		files, err := ReadFileList(strings.NewReader("src/test/java/AppTest.java\n\n  ./src/main/java/generated/Stub.java \nREADME.md\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"src/test/java/AppTest.java", "./src/main/java/generated/Stub.java", "README.md"}, files)
+
+		filter, err := NewPathFilter("/app", nil, nil, files, 0)
+		require.NoError(t, err)
+
+		filtered := filter.Apply(violations)
+		require.Len(t, filtered, 1)
+		assert.Equal(t, "v1", filtered[0].ID)
+		require.Len(t, filtered[0].Incidents, 2)
+		assert.Equal(t, 2, filtered[0].Incidents[0].LineNumber)
+		assert.Equal(t, 3, filtered[0].Incidents[1].LineNumber)
+	})
+
+	t.Run("file list combines with globs", func(t *testing.T) {
+		files := []string{"src/main/java/App.java", "src/main/java/generated/Stub.java"}
+		filter, err := NewPathFilter("/app", nil, []string{"**/generated/**"}, files, 0)
+		require.NoError(t, err)
+
+		filtered := filter.Apply(violations)
+		require.Len(t, filtered, 1)
+		require.Len(t, filtered[0].Incidents, 1)
+		assert.Equal(t, 1, filtered[0].Incidents[0].LineNumber)
+	})
+
+	t.Run("empty file list matches nothing", func(t *testing.T) {
+		files, err := ReadFileList(strings.NewReader(""))
+		require.NoError(t, err)
+
+		filter, err := NewPathFilter("/app", nil, nil, files, 0)
+		require.NoError(t, err)
+		assert.Empty(t, filter.Apply(violations))
+	})
+
+	t.Run("file list matches paths from an analysis run elsewhere", func(t *testing.T) {
+		// Analyzed in a container at /app, fixed locally in /home/dev/app
+		filter, err := NewPathFilter("/home/dev/app", nil, nil, []string{"src/main/java/App.java", "java/OtherTest.java"}, 0)
+		require.NoError(t, err)
+
+		filtered := filter.Apply(violations)
+		require.Len(t, filtered, 2)
+		require.Len(t, filtered[0].Incidents, 1)
+		assert.Equal(t, 1, filtered[0].Incidents[0].LineNumber)
+		assert.Equal(t, "v2", filtered[1].ID)
+
+		assert.False(t, filter.Match("/app/src/main/java/MyApp.java"), "only whole path segments match")
+	})
+
+	t.Run("globs match container paths", func(t *testing.T) {
+		filter, err := NewPathFilter("/home/dev/app", []string{"src/**"}, []string{"src/test/**"}, nil, 2)
+		require.NoError(t, err)
+
+		assert.True(t, filter.Match("/opt/input/source/src/App.java"))
+		assert.False(t, filter.Match("/opt/input/source/src/test/AppTest.java"))
+		assert.False(t, filter.Match("/opt/input/source/src/main/java/App.java"), "depth counts from the container source directory")
+		assert.False(t, filter.Match("/opt/input/source/lib/App.java"))
+	})
+}
+
+func TestContainerRelativePath(t *testing.T) {
+	rel, ok := ContainerRelativePath("/opt/input/source/src/App.java")
+	assert.True(t, ok)
+	assert.Equal(t, "src/App.java", rel)
+
+	_, ok = ContainerRelativePath("/opt/input/sources/App.java")
+	assert.False(t, ok)
+	_, ok = ContainerRelativePath("src/App.java")
+	assert.False(t, ok)
+}
+
+func TestIntersectFileLists(t *testing.T) {
+	changed := []string{"src/App.java", "src/Util.java"}
+
+	assert.Equal(t, changed, IntersectFileLists("/app", nil, changed), "no list: no restriction")
+	assert.Equal(t, []string{"./src/App.java", "/app/src/Util.java"},
+		IntersectFileLists("/app", []string{"./src/App.java", "README.md", "/app/src/Util.java"}, changed))
+	assert.Equal(t, []string{}, IntersectFileLists("/app", []string{"README.md"}, changed))
+}
@@ -0,0 +1,286 @@
+package violation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SARIFDefaultEffort is the effort assigned to SARIF violations, which have
+// no effort estimate of their own
+const SARIFDefaultEffort = 1
+
+// sarifLevelCategory maps SARIF result levels to violation categories.
+// SARIF's default level is "warning".
+var sarifLevelCategory = map[string]string{
+	"error":   "mandatory",
+	"warning": "optional",
+	"note":    "potential",
+	"none":    "potential",
+}
+
+// sarifLog is the subset of a SARIF 2.1.0 log that kantra-ai reads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver sarifDriver `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	FullDescription      sarifMessage `json:"fullDescription"`
+	Help                 sarifMessage `json:"help"`
+	HelpURI              string       `json:"helpUri"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string `json:"ruleId"`
+	RuleIndex *int   `json:"ruleIndex"`
+	Rule      *struct {
+		ID    string `json:"id"`
+		Index *int   `json:"index"`
+	} `json:"rule"`
+	Kind         string          `json:"kind"`
+	Level        string          `json:"level"`
+	Message      sarifMessage    `json:"message"`
+	Locations    []sarifLocation `json:"locations"`
+	Suppressions []struct {
+		Status string `json:"status"`
+	} `json:"suppressions"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int          `json:"startLine"`
+			StartColumn int          `json:"startColumn"`
+			Snippet     sarifMessage `json:"snippet"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// IsSARIFPath reports whether a file name has a SARIF extension (.sarif or .sarif.json)
+func IsSARIFPath(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".sarif") || strings.HasSuffix(lower, ".sarif.json")
+}
+
+// looksLikeSARIF reports whether data is a JSON object with a SARIF version
+// or schema and a runs array
+func looksLikeSARIF(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+	var log sarifLog
+	if err := json.Unmarshal(trimmed, &log); err != nil {
+		return false
+	}
+	return log.Runs != nil && (strings.HasPrefix(log.Version, "2.") || strings.Contains(strings.ToLower(log.Schema), "sarif"))
+}
+
+// parseSARIF converts a SARIF log into an Analysis. Each rule becomes a
+// violation (rule ID → violation ID) and each result location an incident
+// (region → line and column, message → incident message). Categories come
+// from the result level (error → mandatory, warning → optional,
+// note/none → potential) and effort defaults to SARIFDefaultEffort.
+// Suppressed results, results without a file location and pass/notApplicable
+// results are skipped.
+func parseSARIF(data []byte, source string) (*Analysis, error) {
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse SARIF '%s': %w", source, err)
+	}
+	if log.Runs == nil {
+		return nil, fmt.Errorf("failed to parse SARIF '%s': no runs found", source)
+	}
+
+	analysis := &Analysis{
+		Violations: []Violation{},
+	}
+	index := make(map[string]int)
+
+	for _, run := range log.Runs {
+		driver := run.Tool.Driver
+		rules := make(map[string]*sarifRule, len(driver.Rules))
+		for i := range driver.Rules {
+			rules[driver.Rules[i].ID] = &driver.Rules[i]
+		}
+
+		for _, result := range run.Results {
+			if result.Kind == "pass" || result.Kind == "notApplicable" || isSuppressed(result) {
+				continue
+			}
+
+			ruleID, rule := resolveSARIFRule(result, driver, rules)
+			if ruleID == "" {
+				continue
+			}
+
+			incidents := sarifIncidents(result)
+			if len(incidents) == 0 {
+				continue
+			}
+
+			level := result.Level
+			if level == "" && rule != nil {
+				level = rule.DefaultConfiguration.Level
+			}
+			category, ok := sarifLevelCategory[level]
+			if !ok {
+				category = sarifLevelCategory["warning"]
+			}
+
+			i, seen := index[ruleID]
+			if !seen {
+				i = len(analysis.Violations)
+				index[ruleID] = i
+				analysis.Violations = append(analysis.Violations, newSARIFViolation(ruleID, rule, driver.Name, result.Message.Text, category))
+			} else if categorySeverity[category] > categorySeverity[analysis.Violations[i].Category] {
+				analysis.Violations[i].Category = category
+			}
+			analysis.Violations[i].Incidents = append(analysis.Violations[i].Incidents, incidents...)
+		}
+	}
+
+	return analysis, nil
+}
+
+// resolveSARIFRule finds a result's rule ID and, if the tool declared it, the rule
+func resolveSARIFRule(result sarifResult, driver sarifDriver, rules map[string]*sarifRule) (string, *sarifRule) {
+	ruleID := result.RuleID
+	ruleIndex := result.RuleIndex
+	if result.Rule != nil {
+		if ruleID == "" {
+			ruleID = result.Rule.ID
+		}
+		if ruleIndex == nil {
+			ruleIndex = result.Rule.Index
+		}
+	}
+
+	if ruleIndex != nil && *ruleIndex >= 0 && *ruleIndex < len(driver.Rules) {
+		rule := &driver.Rules[*ruleIndex]
+		if ruleID == "" {
+			ruleID = rule.ID
+		}
+		return ruleID, rule
+	}
+	return ruleID, rules[ruleID]
+}
+
+// newSARIFViolation creates the violation for a rule, described by the rule's
+// descriptions or, for undeclared rules, the first result's message
+func newSARIFViolation(ruleID string, rule *sarifRule, toolName, resultMessage, category string) Violation {
+	description := resultMessage
+	message := resultMessage
+	var links []string
+	if rule != nil {
+		description = firstNonEmpty(rule.ShortDescription.Text, rule.FullDescription.Text, resultMessage)
+		message = firstNonEmpty(rule.Help.Text, rule.FullDescription.Text, description)
+		if rule.HelpURI != "" {
+			links = []string{rule.HelpURI}
+		}
+	}
+
+	return Violation{
+		ID:          ruleID,
+		Description: description,
+		Category:    category,
+		Effort:      SARIFDefaultEffort,
+		RuleSet:     toolName,
+		Rule: Rule{
+			ID:       ruleID,
+			Message:  message,
+			RuleSet:  toolName,
+			Links:    links,
+			Category: category,
+		},
+		Incidents: []Incident{},
+	}
+}
+
+// sarifIncidents converts a result's file locations into incidents
+func sarifIncidents(result sarifResult) []Incident {
+	var incidents []Incident
+	for _, location := range result.Locations {
+		physical := location.PhysicalLocation
+		uri := sarifURI(physical.ArtifactLocation.URI)
+		if uri == "" {
+			continue
+		}
+		incidents = append(incidents, Incident{
+			URI:        uri,
+			Message:    result.Message.Text,
+			CodeSnip:   physical.Region.Snippet.Text,
+			LineNumber: physical.Region.StartLine,
+			Column:     physical.Region.StartColumn,
+		})
+	}
+	return incidents
+}
+
+// sarifURI normalizes a SARIF artifact URI: percent-encoding is decoded,
+// file URIs keep their file:// prefix and relative URIs stay relative to
+// the analyzed source root
+func sarifURI(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	switch u.Scheme {
+	case "file":
+		return "file://" + u.Path
+	case "":
+		return u.Path
+	default:
+		return ""
+	}
+}
+
+// isSuppressed reports whether a result has an active (not rejected) suppression
+func isSuppressed(result sarifResult) bool {
+	for _, s := range result.Suppressions {
+		if s.Status != "rejected" && s.Status != "underReview" {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package violation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAnalysis_SARIF(t *testing.T) {
+	analysis, err := LoadAnalysis("testdata/valid_analysis.sarif")
+	require.NoError(t, err)
+	require.Len(t, analysis.Violations, 3)
+
+	jakarta := analysis.Violations[0]
+	assert.Equal(t, "java.javax-to-jakarta", jakarta.ID)
+	assert.Equal(t, "Replace javax with jakarta", jakarta.Description)
+	assert.Equal(t, "mandatory", jakarta.Category) // rule default level: error
+	assert.Equal(t, SARIFDefaultEffort, jakarta.Effort)
+	assert.Equal(t, "semgrep", jakarta.RuleSet)
+	assert.Equal(t, "Jakarta EE 9 moved javax.* packages to jakarta.*", jakarta.Rule.Message)
+	assert.Equal(t, []string{"https://jakarta.ee/"}, jakarta.Rule.Links)
+
+	// Second result references the rule by index
+	require.Len(t, jakarta.Incidents, 2)
+	assert.Equal(t, "src/main/java/My App.java", jakarta.Incidents[0].URI)
+	assert.Equal(t, 5, jakarta.Incidents[0].LineNumber)
+	assert.Equal(t, 8, jakarta.Incidents[0].GetColumn())
+	assert.Equal(t, "javax.servlet import", jakarta.Incidents[0].Message)
+	assert.Equal(t, "import javax.servlet.http.HttpServlet;", jakarta.Incidents[0].CodeSnip)
+	assert.Equal(t, "/src/main/java/Entity.java", jakarta.Incidents[1].GetFilePath())
+
+	deprecated := analysis.Violations[1]
+	assert.Equal(t, "java.deprecated-api", deprecated.ID)
+	assert.Equal(t, "potential", deprecated.Category) // rule default level: note
+	assert.Len(t, deprecated.Incidents, 1, "suppressed result should be skipped")
+
+	undeclared := analysis.Violations[2]
+	assert.Equal(t, "custom.undeclared", undeclared.ID)
+	assert.Equal(t, "Undeclared rule finding", undeclared.Description)
+	assert.Equal(t, "optional", undeclared.Category)
+}
+
+func TestLoadAnalysis_SARIFDetectedByContent(t *testing.T) {
+	data, err := os.ReadFile("testdata/valid_analysis.sarif")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	analysis, err := LoadAnalysis(path)
+	require.NoError(t, err)
+	assert.Len(t, analysis.Violations, 3)
+}
+
+func TestLoadAnalysis_SARIFMergedWithKonveyor(t *testing.T) {
+	analysis, err := LoadAnalysis("testdata/valid_analysis.yaml,testdata/valid_analysis.sarif")
+	require.NoError(t, err)
+	assert.Len(t, analysis.Violations, 6)
+}
+
+func TestLoadAnalysis_InvalidSARIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.sarif")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	_, err := LoadAnalysis(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse SARIF")
+}
+
+func TestSARIFLevelCategory(t *testing.T) {
+	data := []byte(`{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "t"}}, "results": [
+		{"ruleId": "a", "level": "error", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "a.go"}, "region": {"startLine": 1}}}]},
+		{"ruleId": "b", "level": "note", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "b.go"}, "region": {"startLine": 1}}}]},
+		{"ruleId": "c", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "c.go"}, "region": {"startLine": 1}}}]},
+		{"ruleId": "c", "level": "error", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "c.go"}, "region": {"startLine": 9}}}]},
+		{"ruleId": "d", "kind": "pass", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "d.go"}, "region": {"startLine": 1}}}]}
+	]}]}`)
+
+	analysis, err := parseAnalysis(data, "stdin")
+	require.NoError(t, err)
+	require.Len(t, analysis.Violations, 3)
+	assert.Equal(t, "mandatory", analysis.Violations[0].Category)
+	assert.Equal(t, "potential", analysis.Violations[1].Category)
+	// No level defaults to warning; a more severe result raises the category
+	assert.Equal(t, "mandatory", analysis.Violations[2].Category)
+	assert.Len(t, analysis.Violations[2].Incidents, 2)
+}
@@ -1,5 +1,6 @@
 // Package violation provides types and utilities for working with Konveyor analysis results.
-// It handles loading and filtering violations from output.yaml files produced by Konveyor static analysis.
+// It handles loading and filtering violations from output.yaml files produced by Konveyor static analysis,
+// and from SARIF logs produced by other analyzers.
 package violation
 
 // Analysis represents the root structure of Konveyor's output.yaml file.
@@ -51,7 +52,22 @@ type Incident struct {
 	Message    string                 `yaml:"message"`             // Specific message for this incident
 	CodeSnip   string                 `yaml:"codeSnip"`            // Code snippet showing context around the violation
 	LineNumber int                    `yaml:"lineNumber"`          // Line number where violation occurs
+	Column     int                    `yaml:"column,omitempty"`    // 1-based column within the line (0 = unknown)
 	Variables  map[string]interface{} `yaml:"variables,omitempty"` // Template variables for this incident
+
+	CodeLocation *CodeLocation `yaml:"codeLocation,omitempty"` // Precise range, if the analyzer reported one
+}
+
+// CodeLocation is the source range of an incident as reported by the analyzer
+type CodeLocation struct {
+	StartPosition Position `yaml:"startPosition"`
+	EndPosition   Position `yaml:"endPosition"`
+}
+
+// Position is a zero-based line/character position (LSP convention)
+type Position struct {
+	Line      float64 `yaml:"line"`
+	Character float64 `yaml:"character"`
 }
 
 // Rule contains metadata about the rule that was violated
@@ -64,6 +80,18 @@ type Rule struct {
 	Category    string            `yaml:"category,omitempty"`
 }
 
+// GetColumn returns the 1-based column of the incident, or 0 if the analysis
+// didn't include one. An explicit column takes precedence over codeLocation.
+func (i *Incident) GetColumn() int {
+	if i.Column > 0 {
+		return i.Column
+	}
+	if i.CodeLocation != nil {
+		return int(i.CodeLocation.StartPosition.Character) + 1
+	}
+	return 0
+}
+
 // GetFilePath extracts the file path from a file:// URI
 func (i *Incident) GetFilePath() string {
 	// Remove file:// prefix
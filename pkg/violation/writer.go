@@ -0,0 +1,46 @@
+package violation
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SaveAnalysis writes violations to path in the simplified analysis format
+// (violations array), which LoadAnalysis reads back. This lets unprocessed
+// work be resumed later with --analysis.
+func SaveAnalysis(path string, violations []Violation) error {
+	if violations == nil {
+		violations = []Violation{}
+	}
+	data, err := yaml.Marshal(&Analysis{Violations: violations})
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write analysis file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// RemainingViolations returns the work left after processing stopped:
+// the incidents of violations[violationIndex] from incidentIndex on,
+// followed by all later violations. Violations left without incidents are dropped.
+func RemainingViolations(violations []Violation, violationIndex, incidentIndex int) []Violation {
+	var remaining []Violation
+	for i := violationIndex; i < len(violations); i++ {
+		v := violations[i]
+		if i == violationIndex && incidentIndex > 0 {
+			if incidentIndex >= len(v.Incidents) {
+				continue
+			}
+			v.Incidents = v.Incidents[incidentIndex:]
+		}
+		if len(v.Incidents) == 0 {
+			continue
+		}
+		remaining = append(remaining, v)
+	}
+	return remaining
+}
@@ -0,0 +1,50 @@
+package violation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAnalysis_RoundTrip(t *testing.T) {
+	analysis, err := LoadAnalysis("testdata/valid_analysis.yaml")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "deferred.yaml")
+	require.NoError(t, SaveAnalysis(path, analysis.Violations))
+
+	loaded, err := LoadAnalysis(path)
+	require.NoError(t, err)
+	assert.Equal(t, analysis.Violations, loaded.Violations)
+}
+
+func TestRemainingViolations(t *testing.T) {
+	violations := []Violation{
+		{ID: "v1", Incidents: []Incident{{LineNumber: 1}, {LineNumber: 2}, {LineNumber: 3}}},
+		{ID: "v2", Incidents: []Incident{{LineNumber: 10}}},
+		{ID: "v3", Incidents: []Incident{}},
+	}
+
+	t.Run("mid-violation", func(t *testing.T) {
+		remaining := RemainingViolations(violations, 0, 2)
+		require.Len(t, remaining, 2)
+		assert.Equal(t, "v1", remaining[0].ID)
+		assert.Equal(t, []Incident{{LineNumber: 3}}, remaining[0].Incidents)
+		assert.Equal(t, "v2", remaining[1].ID)
+		// The input is not modified
+		assert.Len(t, violations[0].Incidents, 3)
+	})
+
+	t.Run("after last incident of a violation", func(t *testing.T) {
+		remaining := RemainingViolations(violations, 0, 3)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "v2", remaining[0].ID)
+	})
+
+	t.Run("nothing left", func(t *testing.T) {
+		assert.Empty(t, RemainingViolations(violations, 1, 1))
+		assert.Empty(t, RemainingViolations(violations, 3, 0))
+	})
+}
@@ -1,16 +1,23 @@
 package web
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,7 +28,9 @@ import (
 	"github.com/tsanders/kantra-ai/pkg/gitutil"
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/provider"
+	"github.com/tsanders/kantra-ai/pkg/report"
 	"github.com/tsanders/kantra-ai/pkg/verifier"
+	"github.com/tsanders/kantra-ai/pkg/violation"
 )
 
 //go:embed static/*
@@ -42,7 +51,7 @@ type ExecutionSettings struct {
 
 	// Verification
 	RunVerification       bool   `json:"runVerification"`
-	VerificationType      string `json:"verificationType"`      // "build", "test"
+	VerificationType      string `json:"verificationType"`      // "build", "test", "coverage"
 	VerificationStrategy  string `json:"verificationStrategy"`  // "at-end", "per-phase", "per-violation"
 	FailFast              bool   `json:"failFast"`
 
@@ -57,6 +66,9 @@ type ExecutionSettings struct {
 	BatchEnabled       bool    `json:"batchEnabled"`
 	BatchSize          int     `json:"batchSize"`
 	Parallelism        int     `json:"parallelism"`
+
+	// Preview: generate fixes without writing files, commits, or PRs
+	DryRun bool `json:"dryRun"`
 }
 
 // ExecutionStatus tracks the current state of plan execution
@@ -70,9 +82,23 @@ type ExecutionStatus struct {
 	SuccessfulFixes  int         `json:"successful_fixes"`
 	FailedFixes      int         `json:"failed_fixes"`
 	TotalCost        float64     `json:"total_cost"`
+	PhaseID          string      `json:"phase_id,omitempty"` // Set when only one phase was run
+	DryRun           bool        `json:"dry_run,omitempty"`  // The run was a preview; no changes were made
 	Error            string      `json:"error,omitempty"`
+	ApproveOnly      bool        `json:"approve_only,omitempty"` // Execution is disabled; decisions can only be saved
+}
+
+// liveFix is a fix applied by a running execution. The executor only saves
+// state after each phase, so the live report adds these to the saved state.
+type liveFix struct {
+	violationID string
+	incidentKey string
+	cost        float64
 }
 
+// DefaultAddr is the address the web UI listens on unless configured otherwise.
+const DefaultAddr = "localhost:8080"
+
 // PlanServer serves the web-based interactive plan approval UI.
 type PlanServer struct {
 	plan             *planfile.Plan
@@ -82,13 +108,22 @@ type PlanServer struct {
 	addr             string
 	clients          map[*websocket.Conn]bool
 	clientsMutex     sync.RWMutex
+	updates          updateBuffer // Recent updates, replayed to reconnecting clients; guarded by clientsMutex
 	server           *http.Server
 	executing        bool
 	executionMutex   sync.Mutex
 	executionCtx     context.Context
 	executionCancel  context.CancelFunc
 	executionSettings *ExecutionSettings
+	executionPhaseID string // Run only this phase ("" = all approved phases)
 	executionStatus  ExecutionStatus
+	liveFixes        []liveFix // Fixes applied by the current execution, for the live report
+	statePath        string // Execution state file ("" = executor default)
+	prConfig         gitutil.PRConfig // PR settings of executions that create PRs
+	reviewTimeout    time.Duration
+	reviewTimer      *time.Timer
+	token            string
+	approveOnly      bool // Execution endpoints are disabled; saving is the only action
 }
 
 // NewPlanServer creates a new web server for interactive plan approval.
@@ -98,8 +133,9 @@ func NewPlanServer(plan *planfile.Plan, planPath string, inputPath string, prov
 		planPath:  planPath,
 		inputPath: inputPath,
 		provider:  prov,
-		addr:      "localhost:8080",
+		addr:      DefaultAddr,
 		clients:   make(map[*websocket.Conn]bool),
+		updates:   newUpdateBuffer(updateBufferSize),
 		executionStatus: ExecutionStatus{
 			State:   "idle",
 			Message: "No execution in progress",
@@ -107,46 +143,113 @@ func NewPlanServer(plan *planfile.Plan, planPath string, inputPath string, prov
 	}
 }
 
+// SetAddr sets the host:port the server listens on (e.g. "0.0.0.0:9090" inside
+// a container). An empty address keeps DefaultAddr.
+func (s *PlanServer) SetAddr(addr string) {
+	if addr != "" {
+		s.addr = addr
+	}
+}
+
+// Addr returns the address the server listens on. After Start has bound the
+// listener this is the actual address, including any ephemeral port.
+func (s *PlanServer) Addr() string {
+	return s.addr
+}
+
+// SetToken sets the access token required by the /api and /ws endpoints.
+// If no token is set, a random one is generated when the server starts.
+func (s *PlanServer) SetToken(token string) {
+	s.token = token
+}
+
+// Token returns the access token required by the /api and /ws endpoints.
+func (s *PlanServer) Token() string {
+	return s.token
+}
+
+// SetReviewTimeout sets how long the server waits for a reviewer to start
+// execution. When the timeout elapses without execution being started, the
+// currently approved (non-deferred) phases are executed automatically.
+// Zero disables the timeout.
+func (s *PlanServer) SetReviewTimeout(timeout time.Duration) {
+	s.reviewTimeout = timeout
+}
+
+// SetApproveOnly disables the execution endpoints, so the UI only records
+// approve/defer decisions in the plan file for execution elsewhere (e.g.
+// in CI with 'kantra-ai execute'). The review timeout is ignored.
+func (s *PlanServer) SetApproveOnly(approveOnly bool) {
+	s.approveOnly = approveOnly
+	s.executionStatus.ApproveOnly = approveOnly
+	if approveOnly {
+		s.executionStatus.Message = "Execution is disabled (approve-only mode)"
+	}
+}
+
+// SetStatePath sets where execution state is written. The executor's
+// default (relative to the working directory) is used if unset.
+func (s *PlanServer) SetStatePath(path string) {
+	s.statePath = path
+}
+
+// SetPRConfig sets the PR settings of executions that create PRs, such as
+// the base branch, User-Agent and delay between GitHub operations. The PR
+// strategy and comment threshold come from the execution settings, and a
+// branch name and GitHub token (GITHUB_TOKEN) are filled in if unset.
+func (s *PlanServer) SetPRConfig(config gitutil.PRConfig) {
+	s.prConfig = config
+}
+
+// executionPRConfig is the PR configuration of an execution with settings
+func (s *PlanServer) executionPRConfig(strategy gitutil.PRStrategy, settings *ExecutionSettings) gitutil.PRConfig {
+	config := s.prConfig
+	config.Strategy = strategy
+	config.CommentThreshold = settings.PRCommentThreshold
+	if config.BranchPrefix == "" {
+		config.BranchPrefix = fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
+	}
+	if config.GitHubToken == "" {
+		config.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	}
+	return config
+}
+
 // Start starts the web server and optionally opens the browser.
 func (s *PlanServer) Start(ctx context.Context, openBrowser bool) error {
-	// Create router
-	mux := http.NewServeMux()
-
-	// Static files
-	mux.Handle("/static/", http.FileServer(http.FS(staticFiles)))
+	if s.token == "" {
+		token, err := generateToken()
+		if err != nil {
+			return err
+		}
+		s.token = token
+	}
 
-	// API endpoints
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/api/plan", s.handleGetPlan)
-	mux.HandleFunc("/api/phase/approve", s.handleApprovePhase)
-	mux.HandleFunc("/api/phase/defer", s.handleDeferPhase)
-	mux.HandleFunc("/api/plan/save", s.handleSavePlan)
-	mux.HandleFunc("/api/execute/start", s.handleExecuteStart)
-	mux.HandleFunc("/api/execute/cancel", s.handleExecuteCancel)
-	mux.HandleFunc("/api/execute/status", s.handleExecuteStatus)
-	mux.HandleFunc("/ws", s.handleWebSocket)
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
 
 	// Create server
 	s.server = &http.Server{
 		Addr:    s.addr,
-		Handler: mux,
-	}
-
-	// Check if port is available
-	if !s.isPortAvailable() {
-		return fmt.Errorf("port %s is already in use", s.addr)
+		Handler: s.routes(),
 	}
 
-	fmt.Printf("\n🌐 Starting web interface at http://%s\n", s.addr)
+	url := browserURL(s.addr) + "/?token=" + neturl.QueryEscape(s.token)
+	fmt.Printf("\n🌐 Starting web interface at %s\n", url)
 
 	if openBrowser {
-		go s.openBrowserDelayed("http://" + s.addr)
+		go s.openBrowserDelayed(url)
 	}
 
+	s.startReviewTimer()
+	defer s.stopReviewTimer()
+
 	// Start server
 	errChan := make(chan error, 1)
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
@@ -160,6 +263,62 @@ func (s *PlanServer) Start(ctx context.Context, openBrowser bool) error {
 	}
 }
 
+// routes builds the HTTP handler. The page and static assets are public;
+// everything under /api and /ws requires the access token.
+func (s *PlanServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Static files
+	mux.Handle("/static/", http.FileServer(http.FS(staticFiles)))
+
+	// API endpoints
+	mux.HandleFunc("/", s.handleIndex)
+	mux.Handle("/api/plan", s.requireToken(s.handleGetPlan))
+	mux.Handle("/api/phase/approve", s.requireToken(s.handleApprovePhase))
+	mux.Handle("/api/phase/defer", s.requireToken(s.handleDeferPhase))
+	mux.Handle("/api/phase/reorder", s.requireToken(s.handleReorderPhase))
+	mux.Handle("/api/plan/save", s.requireToken(s.handleSavePlan))
+	if s.approveOnly {
+		mux.Handle("/api/execute/start", s.requireToken(s.handleExecutionDisabled))
+		mux.Handle("/api/execute/cancel", s.requireToken(s.handleExecutionDisabled))
+	} else {
+		mux.Handle("/api/execute/start", s.requireToken(s.handleExecuteStart))
+		mux.Handle("/api/execute/cancel", s.requireToken(s.handleExecuteCancel))
+	}
+	mux.Handle("/api/execute/status", s.requireToken(s.handleExecuteStatus))
+	mux.Handle("/api/report", s.requireToken(s.handleReport))
+	mux.Handle("/ws", s.requireToken(s.handleWebSocket))
+
+	return mux
+}
+
+// requireToken rejects requests that don't carry the access token, either as
+// an "Authorization: Bearer" header or, for WebSocket connections that can't
+// set headers, a "token" query parameter.
+func (s *PlanServer) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			provided = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// generateToken returns a random hex-encoded access token.
+func generateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate web access token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // Shutdown gracefully shuts down the web server.
 func (s *PlanServer) Shutdown(ctx context.Context) error {
 	if s.server == nil {
@@ -172,6 +331,117 @@ func (s *PlanServer) Shutdown(ctx context.Context) error {
 	return s.server.Shutdown(shutdownCtx)
 }
 
+// startReviewTimer arms the review timeout, if one is configured.
+func (s *PlanServer) startReviewTimer() {
+	if s.reviewTimeout <= 0 || s.approveOnly {
+		return
+	}
+
+	s.executionMutex.Lock()
+	defer s.executionMutex.Unlock()
+	s.reviewTimer = time.AfterFunc(s.reviewTimeout, s.autoExecute)
+	fmt.Printf("⏱  Approved phases will be executed automatically in %s unless execution is started manually\n", s.reviewTimeout)
+}
+
+// stopReviewTimer disarms the review timeout.
+func (s *PlanServer) stopReviewTimer() {
+	s.executionMutex.Lock()
+	defer s.executionMutex.Unlock()
+	if s.reviewTimer != nil {
+		s.reviewTimer.Stop()
+		s.reviewTimer = nil
+	}
+}
+
+// autoExecute starts execution of the approved phases once the review
+// timeout has elapsed. It does nothing if execution was already started.
+func (s *PlanServer) autoExecute() {
+	s.executionMutex.Lock()
+	s.reviewTimer = nil
+	if s.executing {
+		s.executionMutex.Unlock()
+		return
+	}
+
+	var approved []string
+	for _, phase := range s.plan.Phases {
+		if !phase.Deferred {
+			approved = append(approved, phase.ID)
+		}
+	}
+	if len(approved) == 0 {
+		s.executionMutex.Unlock()
+		s.BroadcastUpdate(ExecutionUpdate{
+			Type: "info",
+			Data: map[string]string{
+				"message": "Review timeout reached but no phases are approved; nothing to execute",
+			},
+		})
+		return
+	}
+
+	s.executing = true
+	s.executionSettings = nil // Use default settings
+	s.executionPhaseID = ""
+	s.executionMutex.Unlock()
+
+	// The executor reads the plan from disk, so persist approvals made in the UI
+	if err := planfile.SavePlan(s.plan, s.planPath); err != nil {
+		s.setExecutionError(fmt.Sprintf("Failed to save plan before auto-execution: %v", err))
+		s.executionMutex.Lock()
+		s.executing = false
+		s.executionMutex.Unlock()
+		return
+	}
+
+	message := fmt.Sprintf("Review timeout of %s reached; executing %d approved phase(s)", s.reviewTimeout, len(approved))
+	fmt.Printf("\n⏱  %s\n", message)
+	s.BroadcastUpdate(ExecutionUpdate{
+		Type: "auto_execute",
+		Data: map[string]interface{}{
+			"message": message,
+			"phases":  approved,
+		},
+	})
+
+	go s.executePhases()
+}
+
+// listen binds the configured address. If its port is busy, an ephemeral port
+// on the same host is used instead. s.addr is updated to the address actually
+// bound.
+func (s *PlanServer) listen() (net.Listener, error) {
+	addr := s.addr
+	if !s.isPortAvailable() {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid web address %s: %w", s.addr, err)
+		}
+		addr = net.JoinHostPort(host, "0")
+		fmt.Printf("⚠️  %s is already in use, using an ephemeral port instead\n", s.addr)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.addr = listener.Addr().String()
+	return listener, nil
+}
+
+// browserURL returns the URL for reaching addr from this machine. Wildcard
+// hosts such as 0.0.0.0 are not browsable, so localhost is used for them.
+func browserURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
 // isPortAvailable checks if the configured port is available.
 func (s *PlanServer) isPortAvailable() bool {
 	ln, err := net.Listen("tcp", s.addr)
@@ -186,7 +456,7 @@ func (s *PlanServer) isPortAvailable() bool {
 func (s *PlanServer) openBrowserDelayed(url string) {
 	time.Sleep(500 * time.Millisecond)
 	if err := openBrowser(url); err != nil {
-		log.Printf("Failed to open browser: %v", err)
+		slog.Warn("failed to open browser", "error", err)
 	}
 }
 
@@ -224,7 +494,7 @@ func (s *PlanServer) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if _, err := w.Write(data); err != nil {
 		// Log error but can't send response as headers are already written
-		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+		slog.Error("failed to write response", "error", err)
 	}
 }
 
@@ -237,7 +507,7 @@ func (s *PlanServer) handleGetPlan(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(s.plan); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding plan: %v\n", err)
+		slog.Error("failed to encode plan", "error", err)
 	}
 }
 
@@ -273,7 +543,7 @@ func (s *PlanServer) handleApprovePhase(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{"status": "approved"}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
@@ -309,7 +579,60 @@ func (s *PlanServer) handleDeferPhase(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{"status": "deferred"}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		slog.Error("failed to encode response", "error", err)
+	}
+}
+
+// handleReorderPhase changes the execution order of phases. The request
+// either moves one phase ({"phase_id": "...", "order": 2}, 1-based) or gives
+// the full order ({"phase_ids": [...]}). Phase Order fields are renumbered and
+// the new order is broadcast so other open tabs stay in sync. The change is
+// persisted by /api/plan/save.
+func (s *PlanServer) handleReorderPhase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PhaseID  string   `json:"phase_id"`
+		Order    int      `json:"order"`
+		PhaseIDs []string `json:"phase_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch {
+	case len(req.PhaseIDs) > 0:
+		err = s.plan.ReorderPhases(req.PhaseIDs)
+	case req.PhaseID != "":
+		err = s.plan.MovePhase(req.PhaseID, req.Order)
+	default:
+		err = fmt.Errorf("either phase_id and order, or phase_ids is required")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	phaseIDs := make([]string, len(s.plan.Phases))
+	for i, phase := range s.plan.Phases {
+		phaseIDs[i] = phase.ID
+	}
+
+	s.BroadcastUpdate(ExecutionUpdate{
+		Type: "phases_reordered",
+		Data: map[string]interface{}{
+			"phase_ids": phaseIDs,
+		},
+	})
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"status": "reordered", "phase_ids": phaseIDs}); err != nil {
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
@@ -325,12 +648,24 @@ func (s *PlanServer) handleSavePlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := map[string]string{"status": "saved", "path": s.planPath}
+	if s.approveOnly {
+		message := fmt.Sprintf("Decisions saved to %s; run 'kantra-ai execute --plan %s' to apply them", s.planPath, s.planPath)
+		response["message"] = message
+		fmt.Printf("\n💾 %s\n", message)
+	}
+
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(map[string]string{"status": "saved", "path": s.planPath}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
+// handleExecutionDisabled rejects execution requests in approve-only mode.
+func (s *PlanServer) handleExecutionDisabled(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "Execution is disabled in approve-only mode; save the plan and run 'kantra-ai execute' to apply it", http.StatusForbidden)
+}
+
 // handleExecuteStart starts plan execution.
 func (s *PlanServer) handleExecuteStart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -341,6 +676,7 @@ func (s *PlanServer) handleExecuteStart(w http.ResponseWriter, r *http.Request)
 	// Parse settings from request body
 	var reqBody struct {
 		Settings ExecutionSettings `json:"settings"`
+		PhaseID  string            `json:"phase_id,omitempty"` // Optional: run only this phase, like --phase
 	}
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
 		// If parsing fails, use default settings (for backward compatibility)
@@ -358,6 +694,18 @@ func (s *PlanServer) handleExecuteStart(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	if reqBody.PhaseID != "" {
+		phase, err := s.plan.GetPhaseByID(reqBody.PhaseID)
+		if err != nil {
+			http.Error(w, "Phase not found", http.StatusNotFound)
+			return
+		}
+		if phase.Deferred {
+			http.Error(w, "Phase is deferred; approve it before executing", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Check if already executing
 	s.executionMutex.Lock()
 	if s.executing {
@@ -367,17 +715,29 @@ func (s *PlanServer) handleExecuteStart(w http.ResponseWriter, r *http.Request)
 	}
 	s.executing = true
 	s.executionSettings = &reqBody.Settings
+	s.executionPhaseID = reqBody.PhaseID
+	if s.reviewTimer != nil {
+		// A reviewer acted; don't auto-execute
+		s.reviewTimer.Stop()
+		s.reviewTimer = nil
+	}
 	s.executionMutex.Unlock()
 
 	// Start execution in background
 	go s.executePhases()
 
+	message := "Execution started"
+	if reqBody.PhaseID != "" {
+		message = fmt.Sprintf("Execution of phase %s started", reqBody.PhaseID)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{
-		"status":  "started",
-		"message": "Execution started",
+		"status":   "started",
+		"message":  message,
+		"phase_id": reqBody.PhaseID,
 	}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
@@ -427,7 +787,7 @@ func (s *PlanServer) handleExecuteCancel(w http.ResponseWriter, r *http.Request)
 		"status":  "cancelled",
 		"message": "Execution cancelled",
 	}); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
@@ -444,19 +804,93 @@ func (s *PlanServer) handleExecuteStatus(w http.ResponseWriter, r *http.Request)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(status); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		slog.Error("failed to encode response", "error", err)
+	}
+}
+
+// handleReport renders the results report from the current execution
+// progress, so an up-to-date report can be downloaded while a long
+// execution is still running.
+func (s *PlanServer) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := s.currentState()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load execution state: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.RenderResultsHTML(&buf, report.BuildResults(s.plan, state, s.inputPath)); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to render report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="kantra-ai-report.html"`)
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.Error("failed to write report", "error", err)
+	}
+}
+
+// currentState returns the saved execution state with the fixes applied by
+// the running execution since it was last saved
+func (s *PlanServer) currentState() (*planfile.ExecutionState, error) {
+	statePath := s.statePath
+	if statePath == "" {
+		statePath = executor.DefaultStatePath
+	}
+	state, err := planfile.LoadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = planfile.NewState(s.planPath, len(s.plan.Phases))
 	}
+
+	s.executionMutex.Lock()
+	fixes := append([]liveFix(nil), s.liveFixes...)
+	s.executionMutex.Unlock()
+
+	for _, fix := range fixes {
+		if !state.IsIncidentCompleted(fix.violationID, fix.incidentKey) {
+			state.RecordIncidentFix(fix.violationID, fix.incidentKey, fix.cost)
+		}
+	}
+	return state, nil
 }
 
-// handleWebSocket handles WebSocket connections for live updates.
+// handleWebSocket handles WebSocket connections for live updates. A client
+// reconnecting after a dropped connection passes the seq of the last update
+// it received as the "cursor" query parameter, and is first sent the updates
+// it missed. If they are no longer buffered it gets a "resync" update instead.
 func (s *PlanServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	var cursor uint64
+	reconnecting := r.URL.Query().Has("cursor")
+	if reconnecting {
+		var err error
+		cursor, err = strconv.ParseUint(r.URL.Query().Get("cursor"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
+		slog.Error("websocket upgrade failed", "error", err)
 		return
 	}
 
+	// Replaying and registering under the lock means no update is missed or
+	// sent twice between the two
 	s.clientsMutex.Lock()
+	if reconnecting {
+		s.replayUpdates(conn, cursor)
+	}
 	s.clients[conn] = true
 	s.clientsMutex.Unlock()
 
@@ -478,30 +912,108 @@ func (s *PlanServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}()
 }
 
+// replayUpdates sends conn the buffered updates after cursor. The caller
+// must hold clientsMutex.
+func (s *PlanServer) replayUpdates(conn *websocket.Conn, cursor uint64) {
+	missed, ok := s.updates.since(cursor)
+	if !ok {
+		// Too far behind, or the cursor is from before a server restart
+		data, err := json.Marshal(ExecutionUpdate{
+			Type: "resync",
+			Seq:  s.updates.lastSeq,
+			Data: map[string]interface{}{
+				"message": "Some updates were missed while disconnected; reload the page for the full activity log",
+			},
+		})
+		if err != nil {
+			slog.Error("failed to marshal update", "error", err)
+			return
+		}
+		missed = [][]byte{data}
+	}
+
+	for _, data := range missed {
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			slog.Error("failed to replay update to client", "error", err)
+			return
+		}
+	}
+}
+
 // BroadcastUpdate sends an update to all connected WebSocket clients.
+// ExecutionUpdates are numbered and buffered, so clients that reconnect can
+// catch up on the ones they missed.
 func (s *PlanServer) BroadcastUpdate(msg interface{}) {
+	// Writes to the connections are serialized, as websocket requires
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	update, buffered := msg.(ExecutionUpdate)
+	if buffered {
+		update.Seq = s.updates.lastSeq + 1
+		msg = update
+	}
 	data, err := json.Marshal(msg)
 	if err != nil {
-		log.Printf("Failed to marshal update: %v", err)
+		slog.Error("failed to marshal update", "error", err)
 		return
 	}
-
-	s.clientsMutex.RLock()
-	defer s.clientsMutex.RUnlock()
+	if buffered {
+		s.updates.add(data)
+	}
 
 	for client := range s.clients {
 		if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
-			log.Printf("Failed to send update to client: %v", err)
+			slog.Error("failed to send update to client", "error", err)
 		}
 	}
 }
 
 // ExecutionUpdate represents a WebSocket update message.
 type ExecutionUpdate struct {
-	Type string      `json:"type"` // "progress", "incident", "complete", "error"
+	Type string      `json:"type"` // "progress", "incident", "complete", "error", "resync"
+	Seq  uint64      `json:"seq"`  // Position in the update stream, set by BroadcastUpdate
 	Data interface{} `json:"data"`
 }
 
+// updateBufferSize is how many recent updates are kept for replay
+const updateBufferSize = 1000
+
+// updateBuffer is a ring buffer of the most recent marshaled updates. The
+// update with seq n (numbered from 1) is stored at index (n-1) % capacity.
+type updateBuffer struct {
+	updates [][]byte
+	lastSeq uint64 // Seq of the newest update (0 = none yet)
+}
+
+func newUpdateBuffer(capacity int) updateBuffer {
+	return updateBuffer{updates: make([][]byte, capacity)}
+}
+
+// add buffers the update with seq lastSeq+1, dropping the oldest when full
+func (b *updateBuffer) add(data []byte) {
+	b.updates[b.lastSeq%uint64(len(b.updates))] = data
+	b.lastSeq++
+}
+
+// since returns the updates after cursor, oldest first. It returns false if
+// some of them are no longer buffered or cursor is ahead of the buffer.
+func (b *updateBuffer) since(cursor uint64) ([][]byte, bool) {
+	if cursor > b.lastSeq {
+		return nil, false
+	}
+	capacity := uint64(len(b.updates))
+	if b.lastSeq-cursor > capacity {
+		return nil, false
+	}
+
+	missed := make([][]byte, 0, b.lastSeq-cursor)
+	for seq := cursor + 1; seq <= b.lastSeq; seq++ {
+		missed = append(missed, b.updates[(seq-1)%capacity])
+	}
+	return missed, true
+}
+
 // mapConfidenceAction converts web UI action string to confidence.Action.
 // Web UI uses: "skip", "prompt", "attempt"
 // Backend uses: "skip", "manual-review-file", "warn-and-apply"
@@ -562,12 +1074,21 @@ func (s *PlanServer) executePhases() {
 
 	// Initialize execution status
 	s.executionMutex.Lock()
+	phaseID := s.executionPhaseID
+	totalPhases := len(s.plan.Phases)
+	startMessage := "Execution started"
+	if phaseID != "" {
+		totalPhases = 1
+		startMessage = fmt.Sprintf("Execution of phase %s started", phaseID)
+	}
 	s.executionStatus = ExecutionStatus{
 		State:       "running",
-		Message:     "Execution started",
+		Message:     startMessage,
 		StartTime:   time.Now(),
-		TotalPhases: len(s.plan.Phases),
+		TotalPhases: totalPhases,
+		PhaseID:     phaseID,
 	}
+	s.liveFixes = nil
 	s.executionMutex.Unlock()
 
 	// Create execution context
@@ -601,6 +1122,10 @@ func (s *PlanServer) executePhases() {
 		Parallelism:  settings.Parallelism,
 		GroupByFile:  true, // Always enabled for optimal token usage
 	}
+	if batchConfig.Parallelism > 0 {
+		// Stay within the provider's rate limits (0 leaves the executor's default)
+		batchConfig.Parallelism = provider.ClampParallelism(s.provider.Name(), batchConfig.Parallelism)
+	}
 
 	// Build confidence config from settings
 	confidenceConfig := confidence.DefaultConfig()
@@ -633,12 +1158,12 @@ func (s *PlanServer) executePhases() {
 
 			verifyStrat := mapVerificationStrategy(settings.VerificationStrategy)
 
+			// The executor applies its dry-run setting to the tracker
 			verifyConfig := verifier.Config{
-				Type:         verifyType,
-				Strategy:     verifyStrat,
-				WorkingDir:   s.inputPath,
-				FailFast:     settings.FailFast,
-				SkipOnDryRun: false,
+				Type:       verifyType,
+				Strategy:   verifyStrat,
+				WorkingDir: s.inputPath,
+				FailFast:   settings.FailFast,
 			}
 
 			verifiedTracker, err = gitutil.NewVerifiedCommitTracker(strategy, s.inputPath, s.provider.Name(), verifyConfig)
@@ -653,28 +1178,15 @@ func (s *PlanServer) executePhases() {
 		}
 	}
 
-	// Initialize PR tracker if enabled
-	if settings.CreatePR && settings.PRStrategy != "" {
+	// Initialize PR tracker if enabled (a preview never opens PRs)
+	if settings.CreatePR && settings.PRStrategy != "" && !settings.DryRun {
 		parsedPRStrategy, err := gitutil.ParsePRStrategy(settings.PRStrategy)
 		if err != nil {
 			s.setExecutionError(fmt.Sprintf("Invalid PR strategy: %v", err))
 			return
 		}
 
-		// Get GitHub token from environment
-		githubToken := os.Getenv("GITHUB_TOKEN")
-
-		// Generate branch name
-		branchName := fmt.Sprintf("kantra-ai/remediation-%d", time.Now().Unix())
-
-		// Create PR config
-		prConfig := gitutil.PRConfig{
-			Strategy:           parsedPRStrategy,
-			BranchPrefix:       branchName,
-			GitHubToken:        githubToken,
-			CommentThreshold:   settings.PRCommentThreshold,
-		}
-
+		prConfig := s.executionPRConfig(parsedPRStrategy, settings)
 		prTracker, err = gitutil.NewPRTracker(prConfig, s.inputPath, s.provider.Name(), progress)
 		if err != nil {
 			s.setExecutionError(fmt.Sprintf("Failed to initialize PR tracker: %v", err))
@@ -685,10 +1197,12 @@ func (s *PlanServer) executePhases() {
 	// Create executor config
 	execConfig := executor.Config{
 		PlanPath:            s.planPath,
+		StatePath:           s.statePath,
 		InputPath:           s.inputPath,
 		Provider:            s.provider,
 		Progress:            progress,
-		DryRun:              false,
+		PhaseID:             phaseID,
+		DryRun:              settings.DryRun,
 		GitCommit:           settings.CommitStrategy,
 		CreatePR:            settings.CreatePR,
 		PRStrategy:          settings.PRStrategy,
@@ -706,14 +1220,22 @@ func (s *PlanServer) executePhases() {
 		return
 	}
 
+	startProgress := "Starting execution..."
+	if settings.DryRun {
+		startProgress = "Starting preview (dry run, no changes will be made)..."
+		s.executionMutex.Lock()
+		s.executionStatus.DryRun = true
+		s.executionMutex.Unlock()
+	}
+
 	// Send initial progress update
 	s.BroadcastUpdate(ExecutionUpdate{
 		Type: "progress",
 		Data: map[string]interface{}{
 			"phase":      0,
-			"total":      len(s.plan.Phases),
+			"total":      totalPhases,
 			"percentage": 0,
-			"message":    "Starting execution...",
+			"message":    startProgress,
 		},
 	})
 
@@ -724,16 +1246,21 @@ func (s *PlanServer) executePhases() {
 		if s.executionCtx.Err() == context.Canceled {
 			// Status already set by handleExecuteCancel
 		} else {
-			s.executionMutex.Lock()
-			s.executionStatus = ExecutionStatus{
-				State:           "failed",
-				Message:         "Execution failed",
-				Error:           err.Error(),
-				EndTime:         time.Now(),
-				SuccessfulFixes: result.SuccessfulFixes,
-				FailedFixes:     result.FailedFixes,
-				TotalCost:       result.TotalCost,
+			status := ExecutionStatus{
+				State:   "failed",
+				Message: "Execution failed",
+				Error:   err.Error(),
+				EndTime: time.Now(),
+				PhaseID: phaseID,
 			}
+			// result is nil if execution failed before any phase ran
+			if result != nil {
+				status.SuccessfulFixes = result.SuccessfulFixes
+				status.FailedFixes = result.FailedFixes
+				status.TotalCost = result.TotalCost
+			}
+			s.executionMutex.Lock()
+			s.executionStatus = status
 			s.executionMutex.Unlock()
 		}
 
@@ -747,15 +1274,30 @@ func (s *PlanServer) executePhases() {
 		return
 	}
 
+	completeMessage := "Execution completed successfully"
+	if phaseID != "" {
+		completeMessage = fmt.Sprintf("Phase %s completed successfully (other phases were not run)", phaseID)
+	}
+	completeType := "complete"
+	if settings.DryRun {
+		completeType = "preview_complete"
+		completeMessage = "Preview completed - no changes were made"
+		if phaseID != "" {
+			completeMessage = fmt.Sprintf("Preview of phase %s completed - no changes were made", phaseID)
+		}
+	}
+
 	// Update status to completed
 	s.executionMutex.Lock()
 	s.executionStatus = ExecutionStatus{
 		State:           "completed",
-		Message:         "Execution completed successfully",
+		Message:         completeMessage,
+		PhaseID:         phaseID,
 		EndTime:         time.Now(),
 		StartTime:       s.executionStatus.StartTime, // Preserve start time
-		TotalPhases:     result.TotalPhases,
-		CurrentPhase:    result.TotalPhases,
+		TotalPhases:     totalPhases,
+		CurrentPhase:    totalPhases,
+		DryRun:          settings.DryRun,
 		SuccessfulFixes: result.SuccessfulFixes,
 		FailedFixes:     result.FailedFixes,
 		TotalCost:       result.TotalCost,
@@ -764,7 +1306,7 @@ func (s *PlanServer) executePhases() {
 
 	// Send completion message
 	s.BroadcastUpdate(ExecutionUpdate{
-		Type: "complete",
+		Type: completeType,
 		Data: map[string]interface{}{
 			"total_phases":     result.TotalPhases,
 			"executed_phases":  result.ExecutedPhases,
@@ -776,6 +1318,9 @@ func (s *PlanServer) executePhases() {
 			"total_tokens":     result.TotalTokens,
 			"commits":          result.Commits,
 			"prs":              result.PRs,
+			"phase_id":         phaseID,
+			"message":          completeMessage,
+			"dry_run":          settings.DryRun,
 		},
 	})
 }
@@ -814,6 +1359,7 @@ func (w *WebSocketProgressWriter) StartPhase(phaseName string) {
 	// Update execution status with current phase
 	w.server.executionMutex.Lock()
 	w.server.executionStatus.CurrentPhase = w.phaseIndex
+	total := w.server.executionStatus.TotalPhases
 	w.server.executionMutex.Unlock()
 
 	w.server.BroadcastUpdate(ExecutionUpdate{
@@ -821,7 +1367,7 @@ func (w *WebSocketProgressWriter) StartPhase(phaseName string) {
 		Data: map[string]interface{}{
 			"phase_name":  phaseName,
 			"phase_index": w.phaseIndex,
-			"total":       len(w.server.plan.Phases),
+			"total":       total,
 		},
 	})
 }
@@ -836,6 +1382,35 @@ func (w *WebSocketProgressWriter) EndPhase() {
 	})
 }
 
+var _ executor.IncidentReporter = (*WebSocketProgressWriter)(nil)
+
+// maxIncidentDiffLines limits the diff sent with each incident update
+const maxIncidentDiffLines = 200
+
+// ReportIncident implements executor.IncidentReporter and broadcasts each
+// applied fix with a diff so the UI can show changes as they happen.
+func (w *WebSocketProgressWriter) ReportIncident(v violation.Violation, incident violation.Incident, result *fixer.FixResult) {
+	w.server.executionMutex.Lock()
+	w.server.liveFixes = append(w.server.liveFixes, liveFix{
+		violationID: v.ID,
+		incidentKey: planfile.IncidentKey(incident),
+		cost:        result.Cost,
+	})
+	w.server.executionMutex.Unlock()
+
+	w.server.BroadcastUpdate(ExecutionUpdate{
+		Type: "incident",
+		Data: map[string]interface{}{
+			"violation_id": v.ID,
+			"file":         result.FilePath,
+			"line":         incident.LineNumber,
+			"confidence":   result.Confidence,
+			"explanation":  result.Explanation,
+			"diff":         fixer.TruncateDiff(result.Diff(fixer.DefaultDiffContext), maxIncidentDiffLines),
+		},
+	})
+}
+
 // Printf implements gitutil.ProgressWriter interface
 func (w *WebSocketProgressWriter) Printf(format string, args ...interface{}) {
 	w.Info(format, args...)
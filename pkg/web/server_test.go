@@ -3,6 +3,8 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -14,6 +16,9 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tsanders/kantra-ai/pkg/fixer"
+	"github.com/tsanders/kantra-ai/pkg/gitutil"
 	"github.com/tsanders/kantra-ai/pkg/planfile"
 	"github.com/tsanders/kantra-ai/pkg/provider"
 	"github.com/tsanders/kantra-ai/pkg/violation"
@@ -69,7 +74,7 @@ func TestNewPlanServer(t *testing.T) {
 	assert.Equal(t, "/tmp/plan.yaml", server.planPath)
 	assert.Equal(t, "/tmp/input", server.inputPath)
 	assert.Equal(t, mockProvider, server.provider)
-	assert.Equal(t, "localhost:8080", server.addr)
+	assert.Equal(t, DefaultAddr, server.addr)
 	assert.NotNil(t, server.clients)
 	assert.False(t, server.executing)
 }
@@ -160,6 +165,85 @@ func TestHandleDeferPhase(t *testing.T) {
 	assert.Equal(t, "deferred", response["status"])
 }
 
+func TestHandleReorderPhase(t *testing.T) {
+	newServer := func() *PlanServer {
+		plan := createTestPlan()
+		for _, id := range []string{"phase-2", "phase-3"} {
+			phase := plan.Phases[0]
+			phase.ID = id
+			phase.Order = len(plan.Phases) + 1
+			plan.Phases = append(plan.Phases, phase)
+		}
+		return NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	}
+	reorder := func(server *PlanServer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/phase/reorder", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.handleReorderPhase(w, req)
+		return w
+	}
+	phaseIDs := func(server *PlanServer) []string {
+		var ids []string
+		for _, phase := range server.plan.Phases {
+			ids = append(ids, phase.ID)
+		}
+		return ids
+	}
+
+	t.Run("move a single phase", func(t *testing.T) {
+		server := newServer()
+
+		w := reorder(server, `{"phase_id":"phase-3","order":1}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"phase-3", "phase-1", "phase-2"}, phaseIDs(server))
+		for i, phase := range server.plan.Phases {
+			assert.Equal(t, i+1, phase.Order)
+		}
+	})
+
+	t.Run("full order", func(t *testing.T) {
+		server := newServer()
+
+		w := reorder(server, `{"phase_ids":["phase-2","phase-3","phase-1"]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"phase-2", "phase-3", "phase-1"}, phaseIDs(server))
+		assert.Equal(t, 3, server.plan.Phases[2].Order)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		server := newServer()
+
+		assert.Equal(t, http.StatusBadRequest, reorder(server, `{"phase_id":"phase-1","order":9}`).Code)
+		assert.Equal(t, http.StatusBadRequest, reorder(server, `{"phase_id":"missing","order":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, reorder(server, `{"phase_ids":["phase-1"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, reorder(server, `{}`).Code)
+		assert.Equal(t, []string{"phase-1", "phase-2", "phase-3"}, phaseIDs(server))
+	})
+
+	t.Run("broadcasts the new order", func(t *testing.T) {
+		server := newServer()
+
+		httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+		defer httpServer.Close()
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+		assert.NoError(t, err)
+		defer ws.Close()
+		time.Sleep(50 * time.Millisecond)
+
+		reorder(server, `{"phase_id":"phase-1","order":3}`)
+
+		assert.NoError(t, ws.SetReadDeadline(time.Now().Add(1*time.Second)))
+		var update ExecutionUpdate
+		assert.NoError(t, ws.ReadJSON(&update))
+		assert.Equal(t, "phases_reordered", update.Type)
+		data := update.Data.(map[string]interface{})
+		assert.Equal(t, []interface{}{"phase-2", "phase-3", "phase-1"}, data["phase_ids"])
+	})
+}
+
 func TestHandleSavePlan(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "web-test-*")
 	assert.NoError(t, err)
@@ -202,6 +286,7 @@ func TestHandleExecuteStart(t *testing.T) {
 	mockProvider.On("Name").Return("test-provider").Maybe()
 
 	server := NewPlanServer(plan, planPath, tmpDir, mockProvider)
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
 
 	req := httptest.NewRequest(http.MethodPost, "/api/execute/start", nil)
 	w := httptest.NewRecorder()
@@ -359,6 +444,111 @@ func TestBroadcastUpdate(t *testing.T) {
 	assert.Equal(t, "Hello WebSocket", data["message"])
 }
 
+func TestWebSocket_ReconnectReplaysMissedUpdates(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	readUpdate := func(t *testing.T, ws *websocket.Conn) ExecutionUpdate {
+		t.Helper()
+		require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+		_, message, err := ws.ReadMessage()
+		require.NoError(t, err)
+		var update ExecutionUpdate
+		require.NoError(t, json.Unmarshal(message, &update))
+		return update
+	}
+	info := func(message string) ExecutionUpdate {
+		return ExecutionUpdate{Type: "info", Data: map[string]string{"message": message}}
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	server.BroadcastUpdate(info("first"))
+	received := readUpdate(t, ws)
+	assert.Equal(t, uint64(1), received.Seq)
+
+	// Disconnect, and miss two updates
+	ws.Close()
+	time.Sleep(50 * time.Millisecond)
+	server.BroadcastUpdate(info("second"))
+	server.BroadcastUpdate(info("third"))
+
+	// Reconnecting from the last update received replays the missed ones
+	ws, _, err = websocket.DefaultDialer.Dial(fmt.Sprintf("%s?cursor=%d", wsURL, received.Seq), nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	second := readUpdate(t, ws)
+	assert.Equal(t, uint64(2), second.Seq)
+	assert.Equal(t, "second", second.Data.(map[string]interface{})["message"])
+	third := readUpdate(t, ws)
+	assert.Equal(t, uint64(3), third.Seq)
+	assert.Equal(t, "third", third.Data.(map[string]interface{})["message"])
+
+	// Then live updates continue
+	time.Sleep(50 * time.Millisecond)
+	server.BroadcastUpdate(info("fourth"))
+	assert.Equal(t, uint64(4), readUpdate(t, ws).Seq)
+}
+
+func TestWebSocket_ReconnectAfterBufferOverflow(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	server.updates = newUpdateBuffer(2)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	for i := 0; i < 5; i++ {
+		server.BroadcastUpdate(ExecutionUpdate{Type: "info", Data: map[string]string{"message": "update"}})
+	}
+
+	for _, cursor := range []string{"1", "9"} {
+		ws, _, err := websocket.DefaultDialer.Dial(wsURL+"?cursor="+cursor, nil)
+		require.NoError(t, err)
+		require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+		_, message, err := ws.ReadMessage()
+		require.NoError(t, err)
+		ws.Close()
+
+		var update ExecutionUpdate
+		require.NoError(t, json.Unmarshal(message, &update))
+		assert.Equal(t, "resync", update.Type, cursor)
+		assert.Equal(t, uint64(5), update.Seq, cursor)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?cursor=abc", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestUpdateBuffer(t *testing.T) {
+	b := newUpdateBuffer(3)
+	missed, ok := b.since(0)
+	assert.True(t, ok)
+	assert.Empty(t, missed)
+
+	for _, data := range []string{"1", "2", "3", "4"} {
+		b.add([]byte(data))
+	}
+	assert.Equal(t, uint64(4), b.lastSeq)
+
+	missed, ok = b.since(1)
+	require.True(t, ok)
+	assert.Equal(t, [][]byte{[]byte("2"), []byte("3"), []byte("4")}, missed)
+	missed, ok = b.since(4)
+	assert.True(t, ok)
+	assert.Empty(t, missed)
+
+	_, ok = b.since(0) // Update 1 was dropped
+	assert.False(t, ok)
+	_, ok = b.since(5)
+	assert.False(t, ok)
+}
+
 func TestWebSocketProgressWriter_Info(t *testing.T) {
 	plan := createTestPlan()
 	server := NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
@@ -395,6 +585,44 @@ func TestWebSocketProgressWriter_Info(t *testing.T) {
 	assert.Equal(t, "Test info: value", data["message"])
 }
 
+func TestWebSocketProgressWriter_ReportIncident(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer ws.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	writer := &WebSocketProgressWriter{server: server}
+	writer.ReportIncident(
+		violation.Violation{ID: "javax-to-jakarta"},
+		violation.Incident{URI: "file:///src/Main.java", LineNumber: 3},
+		&fixer.FixResult{
+			FilePath:        "src/Main.java",
+			Success:         true,
+			Confidence:      0.92,
+			OriginalContent: "package a;\n\nimport javax.servlet.Servlet;\n",
+			FixedContent:    "package a;\n\nimport jakarta.servlet.Servlet;\n",
+		},
+	)
+
+	assert.NoError(t, ws.SetReadDeadline(time.Now().Add(1*time.Second)))
+	var update ExecutionUpdate
+	assert.NoError(t, ws.ReadJSON(&update))
+	assert.Equal(t, "incident", update.Type)
+
+	data := update.Data.(map[string]interface{})
+	assert.Equal(t, "javax-to-jakarta", data["violation_id"])
+	assert.Equal(t, "src/Main.java", data["file"])
+	assert.Equal(t, float64(3), data["line"])
+	assert.Equal(t, 0.92, data["confidence"])
+	assert.Contains(t, data["diff"], "-import javax.servlet.Servlet;\n+import jakarta.servlet.Servlet;\n")
+}
+
 func TestWebSocketProgressWriter_Error(t *testing.T) {
 	plan := createTestPlan()
 	server := NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
@@ -497,6 +725,30 @@ func TestWebSocketProgressWriter_EndPhase(t *testing.T) {
 	assert.Equal(t, float64(2), data["phase_index"])
 }
 
+func TestExecutionPRConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	server.SetPRConfig(gitutil.PRConfig{
+		BaseBranch:      "release",
+		UserAgent:       "acme-bot/1.0",
+		OperationDelay:  3 * time.Second,
+		MaxBranchLength: 40,
+		DiffFormat:      gitutil.DiffFormatNone,
+	})
+
+	config := server.executionPRConfig(gitutil.PRStrategyPerPhase, &ExecutionSettings{PRCommentThreshold: 0.7})
+
+	assert.Equal(t, gitutil.PRStrategyPerPhase, config.Strategy)
+	assert.Equal(t, 0.7, config.CommentThreshold)
+	assert.Equal(t, "release", config.BaseBranch)
+	assert.Equal(t, "acme-bot/1.0", config.UserAgent)
+	assert.Equal(t, 3*time.Second, config.OperationDelay)
+	assert.Equal(t, 40, config.MaxBranchLength)
+	assert.Equal(t, gitutil.DiffFormatNone, config.DiffFormat)
+	assert.True(t, strings.HasPrefix(config.BranchPrefix, "kantra-ai/remediation-"))
+	assert.Equal(t, "env-token", config.GitHubToken)
+}
+
 func TestIsPortAvailable(t *testing.T) {
 	plan := createTestPlan()
 	server := NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
@@ -508,6 +760,149 @@ func TestIsPortAvailable(t *testing.T) {
 	_ = available
 }
 
+func TestListen_UsesConfiguredAddr(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	server.SetAddr("127.0.0.1:0")
+
+	listener, err := server.listen()
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	assert.Equal(t, listener.Addr().String(), server.Addr())
+	assert.NotEqual(t, "127.0.0.1:0", server.Addr())
+}
+
+func TestListen_FallsBackToEphemeralPort(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer busy.Close()
+
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	server.SetAddr(busy.Addr().String())
+
+	listener, err := server.listen()
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	assert.NotEqual(t, busy.Addr().String(), server.Addr())
+	assert.True(t, strings.HasPrefix(server.Addr(), "127.0.0.1:"))
+}
+
+func TestBrowserURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:9090", browserURL("0.0.0.0:9090"))
+	assert.Equal(t, "http://localhost:9090", browserURL(":9090"))
+	assert.Equal(t, "http://localhost:9090", browserURL("[::]:9090"))
+	assert.Equal(t, "http://127.0.0.1:8080", browserURL("127.0.0.1:8080"))
+}
+
+func TestRequireToken(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+	server.SetToken("secret")
+
+	httpServer := httptest.NewServer(server.routes())
+	defer httpServer.Close()
+
+	get := func(path, auth string) int {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+path, nil)
+		assert.NoError(t, err)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/api/plan", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/api/plan", "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, get("/api/execute/status", ""))
+	assert.Equal(t, http.StatusOK, get("/api/plan", "Bearer secret"))
+	assert.Equal(t, http.StatusOK, get("/api/plan?token=secret", ""))
+
+	// The page itself is public so the browser can load it and read the token
+	assert.Equal(t, http.StatusOK, get("/", ""))
+
+	// WebSocket clients pass the token as a query parameter
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL+"?token=secret", nil)
+	assert.NoError(t, err)
+	if ws != nil {
+		ws.Close()
+	}
+}
+
+func TestApproveOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+
+	// The provider must never be asked for fixes
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	server := NewPlanServer(createTestPlan(), planPath, tmpDir, mockProvider)
+	server.SetToken("secret")
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+	server.SetApproveOnly(true)
+
+	httpServer := httptest.NewServer(server.routes())
+	defer httpServer.Close()
+
+	do := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	// Execution is disabled
+	resp := do(http.MethodPost, "/api/execute/start", "{}")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/execute/cancel", "").StatusCode)
+	server.executionMutex.Lock()
+	assert.False(t, server.executing)
+	server.executionMutex.Unlock()
+
+	var status ExecutionStatus
+	require.NoError(t, json.NewDecoder(do(http.MethodGet, "/api/execute/status", "").Body).Decode(&status))
+	assert.True(t, status.ApproveOnly)
+	assert.Equal(t, "idle", status.State)
+
+	// Decisions are saved to the plan file
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/phase/defer", `{"phase_id": "phase-1"}`).StatusCode)
+	resp = do(http.MethodPost, "/api/plan/save", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "saved", response["status"])
+	assert.Contains(t, response["message"], "run 'kantra-ai execute --plan "+planPath+"'")
+
+	saved, err := planfile.LoadPlan(planPath)
+	require.NoError(t, err)
+	assert.True(t, saved.Phases[0].Deferred)
+
+	mockProvider.AssertNotCalled(t, "FixBatch")
+}
+
+func TestGenerateToken(t *testing.T) {
+	a, err := generateToken()
+	assert.NoError(t, err)
+	b, err := generateToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, a, 32)
+	assert.NotEqual(t, a, b)
+}
+
 func TestHandleIndex(t *testing.T) {
 	plan := createTestPlan()
 	server := NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
@@ -579,3 +974,310 @@ func createTestPlan() *planfile.Plan {
 
 	return plan
 }
+
+func TestReviewTimeout_AutoExecutesApprovedPhases(t *testing.T) {
+	plan := createTestPlan()
+	deferred := plan.Phases[0]
+	deferred.ID = "phase-2"
+	deferred.Order = 2
+	deferred.Deferred = true
+	plan.Phases = append(plan.Phases, deferred)
+
+	tmpDir := t.TempDir()
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(nil, assert.AnError).Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(nil, assert.AnError).Maybe()
+
+	server := NewPlanServer(plan, planPath, tmpDir, mockProvider)
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+	server.SetReviewTimeout(50 * time.Millisecond)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer ws.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	server.startReviewTimer()
+	defer server.stopReviewTimer()
+
+	// The first message announces the auto-execution
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var update ExecutionUpdate
+	assert.NoError(t, ws.ReadJSON(&update))
+	assert.Equal(t, "auto_execute", update.Type)
+	data, ok := update.Data.(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"phase-1"}, data["phases"])
+
+	// Execution actually started
+	assert.Eventually(t, func() bool {
+		server.executionMutex.Lock()
+		defer server.executionMutex.Unlock()
+		return server.executionStatus.State != "idle"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Approvals were saved so the executor sees them
+	_, err = os.Stat(planPath)
+	assert.NoError(t, err)
+}
+
+func TestReviewTimeout_ManualStartCancelsTimer(t *testing.T) {
+	plan := createTestPlan()
+	tmpDir := t.TempDir()
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	server := NewPlanServer(plan, filepath.Join(tmpDir, "plan.yaml"), tmpDir, mockProvider)
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+	server.SetReviewTimeout(time.Hour)
+	server.startReviewTimer()
+
+	server.executionMutex.Lock()
+	assert.NotNil(t, server.reviewTimer)
+	server.executionMutex.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute/start", strings.NewReader(`{"settings": {}}`))
+	w := httptest.NewRecorder()
+	server.handleExecuteStart(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	server.executionMutex.Lock()
+	assert.Nil(t, server.reviewTimer)
+	server.executionMutex.Unlock()
+
+	// Let the background execution finish before the temp dir is removed
+	assert.Eventually(t, func() bool {
+		server.executionMutex.Lock()
+		defer server.executionMutex.Unlock()
+		return !server.executing
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestHandleExecuteStart_SinglePhase(t *testing.T) {
+	plan := createTestPlan()
+	second := plan.Phases[0]
+	second.ID = "phase-2"
+	second.Name = "Second Phase"
+	second.Order = 2
+	plan.Phases = append(plan.Phases, second)
+
+	tmpDir := t.TempDir()
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	assert.NoError(t, planfile.SavePlan(plan, planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixViolation", mock.Anything, mock.Anything).Return(nil, assert.AnError).Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(nil, assert.AnError).Maybe()
+
+	server := NewPlanServer(plan, planPath, tmpDir, mockProvider)
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer ws.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute/start", strings.NewReader(`{"settings": {}, "phase_id": "phase-2"}`))
+	w := httptest.NewRecorder()
+	server.handleExecuteStart(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "phase-2")
+
+	// Only the requested phase runs
+	var phaseNames []interface{}
+	var complete map[string]interface{}
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for complete == nil {
+		var update ExecutionUpdate
+		if !assert.NoError(t, ws.ReadJSON(&update)) {
+			break
+		}
+		data, _ := update.Data.(map[string]interface{})
+		switch update.Type {
+		case "phase_start":
+			phaseNames = append(phaseNames, data["phase_name"])
+			assert.Equal(t, float64(1), data["total"])
+		case "complete":
+			complete = data
+		}
+	}
+	assert.Equal(t, []interface{}{"Second Phase"}, phaseNames)
+	assert.Equal(t, "phase-2", complete["phase_id"])
+	assert.Contains(t, complete["message"], "phase-2")
+
+	assert.Eventually(t, func() bool {
+		server.executionMutex.Lock()
+		defer server.executionMutex.Unlock()
+		return !server.executing
+	}, 2*time.Second, 10*time.Millisecond)
+
+	server.executionMutex.Lock()
+	assert.Equal(t, "phase-2", server.executionStatus.PhaseID)
+	assert.Equal(t, 1, server.executionStatus.TotalPhases)
+	server.executionMutex.Unlock()
+}
+
+func TestHandleExecuteStart_InvalidPhase(t *testing.T) {
+	plan := createTestPlan()
+	deferred := plan.Phases[0]
+	deferred.ID = "phase-2"
+	deferred.Order = 2
+	deferred.Deferred = true
+	plan.Phases = append(plan.Phases, deferred)
+
+	server := NewPlanServer(plan, "/tmp/plan.yaml", "/tmp/input", new(MockProvider))
+
+	tests := []struct {
+		name     string
+		phaseID  string
+		wantCode int
+	}{
+		{name: "unknown phase", phaseID: "nope", wantCode: http.StatusNotFound},
+		{name: "deferred phase", phaseID: "phase-2", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"settings": {}, "phase_id": %q}`, tt.phaseID)
+			req := httptest.NewRequest(http.MethodPost, "/api/execute/start", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			server.handleExecuteStart(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.False(t, server.executing)
+		})
+	}
+}
+
+func TestExecutePhases_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	original := "public class Test {}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.java"), []byte(original), 0644))
+
+	plan := createTestPlan()
+	planPath := filepath.Join(tmpDir, "plan.yaml")
+	assert.NoError(t, planfile.SavePlan(plan, planPath))
+
+	mockProvider := new(MockProvider)
+	mockProvider.On("Name").Return("test-provider").Maybe()
+	mockProvider.On("FixBatch", mock.Anything, mock.Anything).Return(
+		&provider.BatchResponse{
+			Fixes: []provider.IncidentFix{
+				{IncidentURI: "file:///test.java:10", Success: true, FixedContent: "public class Fixed {}\n", Confidence: 0.9},
+				{IncidentURI: "file:///test.java:20", Success: true, FixedContent: "public class Fixed {}\n", Confidence: 0.9},
+			},
+			Success: true,
+		},
+		nil,
+	).Maybe()
+
+	server := NewPlanServer(plan, planPath, tmpDir, mockProvider)
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer ws.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute/start", strings.NewReader(`{"settings": {"dryRun": true, "batchEnabled": true}}`))
+	w := httptest.NewRecorder()
+	server.handleExecuteStart(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var complete *ExecutionUpdate
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for complete == nil {
+		var update ExecutionUpdate
+		if !assert.NoError(t, ws.ReadJSON(&update)) {
+			break
+		}
+		assert.NotEqual(t, "complete", update.Type, "a preview must not report a regular completion")
+		if update.Type == "preview_complete" {
+			complete = &update
+		}
+	}
+	if assert.NotNil(t, complete) {
+		data := complete.Data.(map[string]interface{})
+		assert.Equal(t, true, data["dry_run"])
+		assert.Contains(t, data["message"], "no changes")
+	}
+
+	assert.Eventually(t, func() bool {
+		server.executionMutex.Lock()
+		defer server.executionMutex.Unlock()
+		return !server.executing
+	}, 2*time.Second, 10*time.Millisecond)
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "test.java"))
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	server.executionMutex.Lock()
+	assert.True(t, server.executionStatus.DryRun)
+	server.executionMutex.Unlock()
+}
+
+func TestHandleReport_ReflectsCompletedFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	server := NewPlanServer(createTestPlan(), filepath.Join(tmpDir, "plan.yaml"), tmpDir, new(MockProvider))
+	server.SetStatePath(filepath.Join(tmpDir, "state.yaml"))
+
+	getReport := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/api/report", nil)
+		w := httptest.NewRecorder()
+		server.handleReport(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		return w.Body.String()
+	}
+
+	t.Run("before execution", func(t *testing.T) {
+		body := getReport()
+		assert.Contains(t, body, "test-violation-1")
+		assert.Contains(t, body, "0/2 incidents fixed")
+	})
+
+	t.Run("fixes applied mid-phase", func(t *testing.T) {
+		writer := &WebSocketProgressWriter{server: server}
+		writer.ReportIncident(
+			violation.Violation{ID: "test-violation-1"},
+			violation.Incident{URI: "file:///test.java", LineNumber: 10},
+			&fixer.FixResult{FilePath: "test.java", Success: true, Cost: 0.05},
+		)
+
+		assert.Contains(t, getReport(), "1/2 incidents fixed")
+	})
+
+	t.Run("saved state and live fixes are combined", func(t *testing.T) {
+		state := planfile.NewState(server.planPath, 1)
+		state.RecordIncidentFix("test-violation-1", "file:///test.java:10", 0.05)
+		state.RecordIncidentFix("test-violation-1", "file:///test.java:20", 0.05)
+		require.NoError(t, planfile.SaveState(state, server.statePath))
+
+		body := getReport()
+		assert.Contains(t, body, "2/2 incidents fixed")
+		assert.Contains(t, body, "$0.1000", "a live fix already in the saved state is counted once")
+	})
+}
+
+func TestHandleReport_MethodNotAllowed(t *testing.T) {
+	server := NewPlanServer(createTestPlan(), "/tmp/plan.yaml", t.TempDir(), new(MockProvider))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/report", nil)
+	w := httptest.NewRecorder()
+	server.handleReport(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
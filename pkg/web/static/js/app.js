@@ -2,6 +2,8 @@ class PlanApp {
     constructor() {
         this.plan = null;
         this.ws = null;
+        // Seq of the last update received, so a reconnect replays missed updates
+        this.lastSeq = 0;
         this.charts = {
             complexity: null,
             category: null,
@@ -9,13 +11,26 @@ class PlanApp {
         };
         this.executionStartTime = null;
         this.executionTimer = null;
+        // Set when the server runs with --approve-only: execution is disabled
+        this.approveOnly = false;
+        // Access token from the launch URL, required by the /api and /ws endpoints
+        this.token = new URLSearchParams(window.location.search).get('token') || '';
         this.init();
     }
 
+    api(path, options = {}) {
+        const headers = Object.assign({}, options.headers, {
+            'Authorization': `Bearer ${this.token}`
+        });
+        return fetch(path, Object.assign({}, options, { headers }));
+    }
+
     async init() {
         try {
             await this.loadPlan();
+            await this.loadMode();
             this.render();
+            this.applyMode();
             this.attachEventListeners();
             this.initializeSortable();
             this.connectWebSocket();
@@ -93,7 +108,7 @@ Esc             Close modals
     async loadPlan() {
         this.showLoading('Loading plan...');
         try {
-            const response = await fetch('/api/plan');
+            const response = await this.api('/api/plan');
             if (!response.ok) {
                 throw new Error(`Server returned ${response.status}: ${response.statusText}`);
             }
@@ -428,6 +443,9 @@ Esc             Close modals
                         <button class="btn btn-warning" onclick="app.deferPhase('${phase.ID}')">
                             <i class="fas fa-clock"></i> Defer
                         </button>
+                        <button class="btn btn-primary" onclick="app.executePhases('${phase.ID}')" ${phase.Deferred || this.approveOnly ? 'disabled' : ''}>
+                            <i class="fas fa-play"></i> Run Phase
+                        </button>
                         <button class="btn btn-info" onclick="app.toggleDetails('${phase.ID}')">
                             <i class="fas fa-chevron-down toggle-icon" id="toggle-icon-${phase.ID}"></i> Details
                         </button>
@@ -565,7 +583,7 @@ Esc             Close modals
 
     async approvePhase(phaseId) {
         try {
-            const response = await fetch('/api/phase/approve', {
+            const response = await this.api('/api/phase/approve', {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify({ phase_id: phaseId })
@@ -589,7 +607,7 @@ Esc             Close modals
 
     async deferPhase(phaseId) {
         try {
-            const response = await fetch('/api/phase/defer', {
+            const response = await this.api('/api/phase/defer', {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify({ phase_id: phaseId })
@@ -611,25 +629,89 @@ Esc             Close modals
         }
     }
 
+    async reorderPhases(phaseIds) {
+        try {
+            const response = await this.api('/api/phase/reorder', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ phase_ids: phaseIds })
+            });
+
+            if (!response.ok) {
+                throw new Error('Failed to reorder phases');
+            }
+        } catch (error) {
+            console.error('Error reordering phases:', error);
+            this.showError('Failed to reorder phases');
+        }
+    }
+
+    applyPhaseOrder(phaseIds) {
+        // Sync with a reorder made in another tab
+        const current = this.plan.Phases.map(p => p.ID);
+        if (current.join('\n') === phaseIds.join('\n')) return;
+
+        const byId = new Map(this.plan.Phases.map(p => [p.ID, p]));
+        this.plan.Phases = phaseIds.map((id, index) => {
+            const phase = byId.get(id);
+            phase.Order = index + 1;
+            return phase;
+        });
+        this.render();
+        this.initializeSortable();
+    }
+
+    async loadMode() {
+        const response = await this.api('/api/execute/status');
+        if (response.ok) {
+            const status = await response.json();
+            this.approveOnly = !!status.approve_only;
+        }
+    }
+
+    applyMode() {
+        if (!this.approveOnly) {
+            return;
+        }
+
+        // Saving the decisions is the only action
+        const executeBtn = document.getElementById('execute-btn');
+        if (executeBtn) {
+            executeBtn.classList.add('hidden');
+        }
+        const saveBtn = document.getElementById('save-btn');
+        if (saveBtn) {
+            saveBtn.classList.replace('btn-secondary', 'btn-primary');
+            saveBtn.innerHTML = '<i class="fas fa-save"></i> Save Decisions';
+        }
+        this.showInfo('Approve-only mode: save your decisions, then run kantra-ai execute to apply them');
+    }
+
     async savePlan() {
         try {
-            const response = await fetch('/api/plan/save', { method: 'POST' });
+            const response = await this.api('/api/plan/save', { method: 'POST' });
 
             if (!response.ok) {
                 throw new Error('Failed to save plan');
             }
 
             const result = await response.json();
-            this.showSuccess(`Plan saved to ${result.path}`);
+            this.showSuccess(result.message || `Plan saved to ${result.path}`);
         } catch (error) {
             console.error('Error saving plan:', error);
             this.showError('Failed to save plan');
         }
     }
 
-    async executePhases() {
-        // Show confirmation dialog with estimates
-        const approvedPhases = this.plan.Phases.filter(p => !p.Deferred);
+    async executePhases(phaseId = null) {
+        if (this.approveOnly) {
+            this.showWarning('Execution is disabled in approve-only mode; save your decisions and run kantra-ai execute');
+            return;
+        }
+
+        // Show confirmation dialog with estimates; a phase ID runs only that phase
+        this.pendingPhaseId = phaseId;
+        const approvedPhases = this.plan.Phases.filter(p => !p.Deferred && (!phaseId || p.ID === phaseId));
 
         if (approvedPhases.length === 0) {
             this.showWarning('No phases approved for execution');
@@ -651,6 +733,9 @@ Esc             Close modals
         document.getElementById('estimate-cost').textContent = this.formatCost(totalCost);
         document.getElementById('estimate-duration').textContent = `~${totalDuration} min`;
 
+        const settings = JSON.parse(localStorage.getItem('kantra-ai-settings') || '{}');
+        document.getElementById('confirm-dry-run').classList.toggle('hidden', !settings.dryRun);
+
         // Show confirmation modal
         document.getElementById('confirm-execution-modal').classList.remove('hidden');
     }
@@ -667,13 +752,14 @@ Esc             Close modals
             // Load settings to send with execution request
             const settings = JSON.parse(localStorage.getItem('kantra-ai-settings') || '{}');
 
-            const response = await fetch('/api/execute/start', {
+            const response = await this.api('/api/execute/start', {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
                 },
                 body: JSON.stringify({
-                    settings: settings
+                    settings: settings,
+                    phase_id: this.pendingPhaseId || undefined
                 })
             });
 
@@ -699,7 +785,7 @@ Esc             Close modals
         }
 
         try {
-            const response = await fetch('/api/execute/cancel', { method: 'POST' });
+            const response = await this.api('/api/execute/cancel', { method: 'POST' });
 
             if (!response.ok) {
                 throw new Error('Failed to cancel execution');
@@ -713,6 +799,12 @@ Esc             Close modals
         }
     }
 
+    // The report is regenerated from the current progress, so it can be
+    // downloaded while execution is still running
+    downloadReport() {
+        window.location.href = `/api/report?token=${encodeURIComponent(this.token)}`;
+    }
+
     startExecutionTimer() {
         this.executionStartTime = Date.now();
         this.updateExecutionTimer();
@@ -827,7 +919,10 @@ Esc             Close modals
 
     connectWebSocket() {
         const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-        const wsUrl = `${protocol}//${window.location.host}/ws`;
+        let wsUrl = `${protocol}//${window.location.host}/ws?token=${encodeURIComponent(this.token)}`;
+        if (this.lastSeq > 0) {
+            wsUrl += `&cursor=${this.lastSeq}`;
+        }
 
         this.ws = new WebSocket(wsUrl);
 
@@ -838,6 +933,12 @@ Esc             Close modals
         this.ws.onmessage = (event) => {
             try {
                 const update = JSON.parse(event.data);
+                if (update.seq) {
+                    if (update.type !== 'resync' && update.seq <= this.lastSeq) {
+                        return; // Already received before reconnecting
+                    }
+                    this.lastSeq = update.seq;
+                }
                 this.handleExecutionUpdate(update);
             } catch (error) {
                 console.error('Failed to parse WebSocket message:', error);
@@ -866,18 +967,31 @@ Esc             Close modals
             case 'phase_end':
                 this.handlePhaseEnd(update.data);
                 break;
+            case 'incident':
+                this.handleIncident(update.data);
+                break;
+            case 'phases_reordered':
+                this.applyPhaseOrder(update.data.phase_ids);
+                break;
             case 'info':
                 this.addActivityMessage(update.data.message, 'info');
                 break;
             case 'error':
                 this.addActivityMessage(update.data.message, 'error');
                 break;
+            case 'resync':
+                this.addActivityMessage(update.data.message, 'error');
+                break;
             case 'cancelled':
                 this.handleExecutionCancelled(update.data);
                 break;
             case 'complete':
                 this.showExecutionSummary(update.data);
                 break;
+            case 'preview_complete':
+                this.showExecutionSummary(update.data);
+                this.addActivityMessage('Preview only: no changes were made', 'info');
+                break;
             default:
                 console.log('Unknown update type:', update.type);
         }
@@ -928,6 +1042,25 @@ Esc             Close modals
         }
     }
 
+    handleIncident(data) {
+        const confidence = data.confidence > 0 ? ` (confidence ${Math.round(data.confidence * 100)}%)` : '';
+        const entry = this.addActivityMessage(`Fixed ${data.file}:${data.line} [${data.violation_id}]${confidence}`, 'success');
+        if (!entry || !data.diff) return;
+
+        const lines = data.diff.split('\n').map(line => {
+            let cls = '';
+            if (line.startsWith('@@')) cls = 'diff-hunk';
+            else if (line.startsWith('+') && !line.startsWith('+++')) cls = 'diff-add';
+            else if (line.startsWith('-') && !line.startsWith('---')) cls = 'diff-del';
+            return `<span class="${cls}">${this.escapeHtml(line)}</span>`;
+        });
+
+        const details = document.createElement('details');
+        details.className = 'activity-diff';
+        details.innerHTML = `<summary>Show diff</summary><pre>${lines.join('\n')}</pre>`;
+        entry.querySelector('.activity-message').appendChild(details);
+    }
+
     addActivityMessage(message, type = 'info') {
         const activityFeed = document.getElementById('execution-activity');
         if (!activityFeed) return;
@@ -961,6 +1094,8 @@ Esc             Close modals
         while (activityFeed.children.length > 100) {
             activityFeed.removeChild(activityFeed.lastChild);
         }
+
+        return entry;
     }
 
     showExecutionSummary(data) {
@@ -981,7 +1116,9 @@ Esc             Close modals
         summaryEl.classList.remove('hidden');
         summaryEl.innerHTML = `
             <div class="execution-complete">
-                <h3>✓ Execution Complete</h3>
+                <h3>✓ ${data.dry_run ? 'Preview' : (data.phase_id ? `Phase ${this.escapeHtml(data.phase_id)}` : 'Execution')} Complete</h3>
+                ${data.dry_run ? '<p class="execution-scope preview-notice">Dry run: no changes were made to your code.</p>' : ''}
+                ${data.phase_id ? '<p class="execution-scope">Only this phase was run; other approved phases were not executed.</p>' : ''}
                 <div class="summary-stats">
                     <div class="stat-card">
                         <div class="stat-value">${data.completed_phases || 0}</div>
@@ -1179,6 +1316,7 @@ Esc             Close modals
 
                     this.render();
                     this.initializeSortable(); // Reinitialize after render
+                    this.reorderPhases(this.plan.Phases.map(p => p.ID));
                 }
             }
         });
@@ -1440,6 +1578,8 @@ Esc             Close modals
         // Load from localStorage or use defaults
         const settings = JSON.parse(localStorage.getItem('kantra-ai-settings') || '{}');
 
+        document.getElementById('setting-dry-run').checked = settings.dryRun || false;
+
         document.getElementById('setting-confidence-enabled').checked = settings.confidenceEnabled !== false;
         document.getElementById('setting-confidence-threshold').value = settings.confidenceThreshold || 70;
         document.getElementById('confidence-value').textContent = (settings.confidenceThreshold || 70) + '%';
@@ -1463,6 +1603,8 @@ Esc             Close modals
 
     saveSettings() {
         const settings = {
+            dryRun: document.getElementById('setting-dry-run').checked,
+
             confidenceEnabled: document.getElementById('setting-confidence-enabled').checked,
             confidenceThreshold: parseInt(document.getElementById('setting-confidence-threshold').value),
             lowConfidenceAction: document.getElementById('setting-low-confidence-action').value,